@@ -0,0 +1,115 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=imagebasedupgradepreflights,scope=Cluster,shortName=ibupf
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Seed Image",type="string",JSONPath=".spec.seedImageRef.image"
+// +kubebuilder:printcolumn:name="Result",type="string",JSONPath=".status.conditions[?(@.type=='Completed')].reason"
+// +operator-sdk:csv:customresourcedefinitions:displayName="Image-based Upgrade Preflight",resources={{Namespace, v1}}
+
+// ImageBasedUpgradePreflight runs the checks of an image-based upgrade against a target seed image on demand, without
+// starting the upgrade. The checks are run again whenever the spec is updated.
+type ImageBasedUpgradePreflight struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ImageBasedUpgradePreflightSpec   `json:"spec,omitempty"`
+	Status ImageBasedUpgradePreflightStatus `json:"status,omitempty"`
+}
+
+// ImageBasedUpgradePreflightSpec defines the upgrade to check, with the same semantics as the fields of the
+// ImageBasedUpgrade spec
+type ImageBasedUpgradePreflightSpec struct {
+	// SeedImageRef defines the target seed image
+	// +kubebuilder:validation:Required
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Seed Image Reference"
+	SeedImageRef SeedImageRef `json:"seedImageRef"`
+	// OADPContent defines the list of ConfigMap resources that contain the OADP Backup and Restore CRs.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OADP Content"
+	OADPContent []ConfigMapRef `json:"oadpContent,omitempty"`
+	// ExtraManifests defines the list of ConfigMap resources that contain the extra manifests, which are rendered
+	// with a dry-run.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Extra Manifests"
+	ExtraManifests []ConfigMapRef `json:"extraManifests,omitempty"`
+	// DiskSpaceValidation defines the validation of the disk space required by the new stateroot and the precached
+	// images.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Disk Space Validation"
+	DiskSpaceValidation *DiskSpaceValidation `json:"diskSpaceValidation,omitempty"`
+}
+
+// PreflightCheckResult defines the outcome of a preflight check
+type PreflightCheckResult string
+
+// PreflightCheckResults defines the string values for the outcomes of the preflight checks
+var PreflightCheckResults = struct {
+	Passed  PreflightCheckResult
+	Warning PreflightCheckResult
+	Failed  PreflightCheckResult
+	Skipped PreflightCheckResult
+}{
+	Passed:  "Passed",
+	Warning: "Warning",
+	Failed:  "Failed",
+	Skipped: "Skipped",
+}
+
+// PreflightCheck reports the outcome of a preflight check
+type PreflightCheck struct {
+	// Name The name of the check, one of SeedImageVersion, SeedImage, DiskSpace, OADP, ExtraManifests or Certificates
+	Name string `json:"name"`
+	// Result One of Passed, Warning, Failed or Skipped
+	Result PreflightCheckResult `json:"result"`
+	// Message The details of the outcome
+	Message string `json:"message,omitempty"`
+}
+
+// ImageBasedUpgradePreflightStatus defines the observed state of ImageBasedUpgradePreflight
+type ImageBasedUpgradePreflightStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Status"
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// StartTime A timestamp indicating the checks have started
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime A timestamp indicating the checks have completed
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+	// Checks The outcome of each check
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Checks"
+	Checks []PreflightCheck `json:"checks,omitempty"`
+	// Conditions The Completed condition is set once the checks have run, with the Passed reason if none of them
+	// failed, and the Failed reason otherwise
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ImageBasedUpgradePreflightList contains a list of ImageBasedUpgradePreflight
+type ImageBasedUpgradePreflightList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImageBasedUpgradePreflight `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImageBasedUpgradePreflight{}, &ImageBasedUpgradePreflightList{})
+}
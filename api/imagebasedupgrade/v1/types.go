@@ -17,6 +17,9 @@ limitations under the License.
 package v1
 
 import (
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,11 +31,29 @@ import (
 // +kubebuilder:printcolumn:name="Desired Stage",type="string",JSONPath=".spec.stage"
 // +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.conditions[-1:].reason"
 // +kubebuilder:printcolumn:name="Details",type="string",JSONPath=".status.conditions[-1:].message"
+// +kubebuilder:printcolumn:name="Rollout",type="string",JSONPath=".status.rolloutStatus",priority=1
 // +kubebuilder:validation:XValidation:message="ibu is a singleton, metadata.name must be 'upgrade'", rule="self.metadata.name == 'upgrade'"
 // +kubebuilder:validation:XValidation:message="can not change spec.seedImageRef while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.seedImageRef) && has(self.spec.seedImageRef) && oldSelf.spec.seedImageRef==self.spec.seedImageRef || !has(self.spec.seedImageRef) && !has(oldSelf.spec.seedImageRef)"
 // +kubebuilder:validation:XValidation:message="can not change spec.oadpContent while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.oadpContent) && has(self.spec.oadpContent) && oldSelf.spec.oadpContent==self.spec.oadpContent || !has(self.spec.oadpContent) && !has(oldSelf.spec.oadpContent)"
 // +kubebuilder:validation:XValidation:message="can not change spec.extraManifests while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.extraManifests) && has(self.spec.extraManifests) && oldSelf.spec.extraManifests==self.spec.extraManifests || !has(self.spec.extraManifests) && !has(oldSelf.spec.extraManifests)"
 // +kubebuilder:validation:XValidation:message="can not change spec.autoRollbackOnFailure while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.autoRollbackOnFailure) && has(self.spec.autoRollbackOnFailure) && oldSelf.spec.autoRollbackOnFailure==self.spec.autoRollbackOnFailure || !has(self.spec.autoRollbackOnFailure) && !has(oldSelf.spec.autoRollbackOnFailure)"
+// +kubebuilder:validation:XValidation:message="can not change spec.precache while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.precache) && has(self.spec.precache) && oldSelf.spec.precache==self.spec.precache || !has(self.spec.precache) && !has(oldSelf.spec.precache)"
+// +kubebuilder:validation:XValidation:message="can not change spec.validateOnly while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.validateOnly) && has(self.spec.validateOnly) && oldSelf.spec.validateOnly==self.spec.validateOnly || !has(self.spec.validateOnly) && !has(oldSelf.spec.validateOnly)"
+// +kubebuilder:validation:XValidation:message="can not change spec.healthChecks while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.healthChecks) && has(self.spec.healthChecks) && oldSelf.spec.healthChecks==self.spec.healthChecks || !has(self.spec.healthChecks) && !has(oldSelf.spec.healthChecks)"
+// +kubebuilder:validation:XValidation:message="can not change spec.healthCheckConfig while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.healthCheckConfig) && has(self.spec.healthCheckConfig) && oldSelf.spec.healthCheckConfig==self.spec.healthCheckConfig || !has(self.spec.healthCheckConfig) && !has(oldSelf.spec.healthCheckConfig)"
+// +kubebuilder:validation:XValidation:message="can not change spec.mirrorRegistryConfig while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.mirrorRegistryConfig) && has(self.spec.mirrorRegistryConfig) && oldSelf.spec.mirrorRegistryConfig==self.spec.mirrorRegistryConfig || !has(self.spec.mirrorRegistryConfig) && !has(oldSelf.spec.mirrorRegistryConfig)"
+// +kubebuilder:validation:XValidation:message="can not change spec.diskSpaceValidation while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.diskSpaceValidation) && has(self.spec.diskSpaceValidation) && oldSelf.spec.diskSpaceValidation==self.spec.diskSpaceValidation || !has(self.spec.diskSpaceValidation) && !has(oldSelf.spec.diskSpaceValidation)"
+// +kubebuilder:validation:XValidation:message="can not change spec.oadpConfig while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.oadpConfig) && has(self.spec.oadpConfig) && oldSelf.spec.oadpConfig==self.spec.oadpConfig || !has(self.spec.oadpConfig) && !has(oldSelf.spec.oadpConfig)"
+// +kubebuilder:validation:XValidation:message="can not change spec.staterootSetup while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.staterootSetup) && has(self.spec.staterootSetup) && oldSelf.spec.staterootSetup==self.spec.staterootSetup || !has(self.spec.staterootSetup) && !has(oldSelf.spec.staterootSetup)"
+// +kubebuilder:validation:XValidation:message="can not change spec.staterootRetention while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.staterootRetention) && has(self.spec.staterootRetention) && oldSelf.spec.staterootRetention==self.spec.staterootRetention || !has(self.spec.staterootRetention) && !has(oldSelf.spec.staterootRetention)"
+// +kubebuilder:validation:XValidation:message="can not change spec.nodeMetadata while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.nodeMetadata) && has(self.spec.nodeMetadata) && oldSelf.spec.nodeMetadata==self.spec.nodeMetadata || !has(self.spec.nodeMetadata) && !has(oldSelf.spec.nodeMetadata)"
+// +kubebuilder:validation:XValidation:message="can not change spec.preservedPaths while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.preservedPaths) && has(self.spec.preservedPaths) && oldSelf.spec.preservedPaths==self.spec.preservedPaths || !has(self.spec.preservedPaths) && !has(oldSelf.spec.preservedPaths)"
+// +kubebuilder:validation:XValidation:message="can not change spec.rollbackRetentionHours while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.rollbackRetentionHours) && has(self.spec.rollbackRetentionHours) && oldSelf.spec.rollbackRetentionHours==self.spec.rollbackRetentionHours || !has(self.spec.rollbackRetentionHours) && !has(oldSelf.spec.rollbackRetentionHours)"
+// +kubebuilder:validation:XValidation:message="can not change spec.hooks while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.hooks) && has(self.spec.hooks) && oldSelf.spec.hooks==self.spec.hooks || !has(self.spec.hooks) && !has(oldSelf.spec.hooks)"
+// +kubebuilder:validation:XValidation:message="can not change spec.stallDetection while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.stallDetection) && has(self.spec.stallDetection) && oldSelf.spec.stallDetection==self.spec.stallDetection || !has(self.spec.stallDetection) && !has(oldSelf.spec.stallDetection)"
+// +kubebuilder:validation:XValidation:message="can not change spec.validateSeedContent while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.validateSeedContent) && has(self.spec.validateSeedContent) && oldSelf.spec.validateSeedContent==self.spec.validateSeedContent || !has(self.spec.validateSeedContent) && !has(oldSelf.spec.validateSeedContent)"
+// +kubebuilder:validation:XValidation:message="can not change spec.certificateRegeneration while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.certificateRegeneration) && has(self.spec.certificateRegeneration) && oldSelf.spec.certificateRegeneration==self.spec.certificateRegeneration || !has(self.spec.certificateRegeneration) && !has(oldSelf.spec.certificateRegeneration)"
+// +kubebuilder:validation:XValidation:message="can not change spec.additionalTrustedCABundles while ibu is in progress", rule="!has(oldSelf.status) || oldSelf.status.conditions.exists(c, c.type=='Idle' && c.status=='True') || self.spec.stage=='Idle' || has(oldSelf.spec.additionalTrustedCABundles) && has(self.spec.additionalTrustedCABundles) && oldSelf.spec.additionalTrustedCABundles==self.spec.additionalTrustedCABundles || !has(self.spec.additionalTrustedCABundles) && !has(oldSelf.spec.additionalTrustedCABundles)"
 // +kubebuilder:validation:XValidation:message="the stage transition is not permitted. Please refer to status.validNextStages for valid transitions. If status.validNextStages is not present, it indicates that no transitions are currently allowed", rule="!has(oldSelf.status) || has(oldSelf.status.validNextStages) && self.spec.stage in oldSelf.status.validNextStages || has(oldSelf.spec.stage) && has(self.spec.stage) && oldSelf.spec.stage==self.spec.stage"
 // +operator-sdk:csv:customresourcedefinitions:displayName="Image-based Cluster Upgrade",resources={{Namespace, v1},{Deployment,apps/v1}}
 
@@ -71,6 +92,9 @@ type ImageBasedUpgradeSpec struct {
 	// OADPContent defines the list of ConfigMap resources that contain the OADP Backup and Restore CRs.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OADP Content"
 	OADPContent []ConfigMapRef `json:"oadpContent,omitempty"`
+	// OADPConfig defines the time limits and retries of the OADP backups and restores done during the Upgrade stage
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OADP Config"
+	OADPConfig *OADPConfig `json:"oadpConfig,omitempty"`
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Extra Manifests"
 	// ExtraManifests defines the list of ConfigMap resources that contain the user-specific extra manifests to be
 	// applied during the upgrade post-pivot stage.
@@ -78,21 +102,452 @@ type ImageBasedUpgradeSpec struct {
 	ExtraManifests []ConfigMapRef `json:"extraManifests,omitempty"`
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Auto Rollback On Failure"
 	AutoRollbackOnFailure *AutoRollbackOnFailure `json:"autoRollbackOnFailure,omitempty"`
+	// ValidateOnly runs the Prep stage as a dry run. The seed image, cluster compatibility, disk space and OADP
+	// configuration are validated and the results reported in the Prep conditions, but no stateroot is created and
+	// no images are precached. The only valid transition once the validation completes is back to Idle.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Validate Only",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	ValidateOnly bool `json:"validateOnly,omitempty"`
+	// ValidateSeedContent validates the content of the seed image once it is pulled by the Prep stage, before the new
+	// stateroot is set up: the metadata files, the content manifest, the ostree commit, the certificate expirations
+	// and the version compatibility, as lca-cli seed validate does. With validateOnly, the seed image is pulled for the
+	// validation and removed once validated.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Validate Seed Content",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	ValidateSeedContent bool `json:"validateSeedContent,omitempty"`
+	// Precache defines tuning options for the image precaching done during the Prep stage
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Precache"
+	Precache *PrecacheConfig `json:"precache,omitempty"`
+	// HealthChecks defines the list of ConfigMap resources that contain user-defined health checks. The checks are run
+	// after the pivot, once the cluster health checks have passed, and must pass before the upgrade is completed.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Health Checks"
+	HealthChecks []ConfigMapRef `json:"healthChecks,omitempty"`
+	// PreservedPaths defines the list of ConfigMap resources that contain additional /etc and /var paths to back up
+	// before the pivot and restore on the new stateroot, beyond the paths preserved by the upgrade itself.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Preserved Paths"
+	PreservedPaths []ConfigMapRef `json:"preservedPaths,omitempty"`
+	// HealthCheckConfig defines the selection of the cluster health checks run before and during the stages.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Health Check Config"
+	HealthCheckConfig *HealthCheckConfig `json:"healthCheckConfig,omitempty"`
+	// MirrorRegistryConfig defines the mirror registries and credentials applied on the target stateroot during the
+	// post-pivot reconfiguration, in addition to the mirror configuration of the cluster.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Mirror Registry Config"
+	MirrorRegistryConfig *MirrorRegistryConfig `json:"mirrorRegistryConfig,omitempty"`
+	// DiskSpaceValidation defines the validation of the disk space required by the new stateroot and the precached
+	// images, done before the Prep stage sets up the stateroot. If not defined, the validation is enabled with the
+	// default threshold.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Disk Space Validation"
+	DiskSpaceValidation *DiskSpaceValidation `json:"diskSpaceValidation,omitempty"`
+	// StaterootSetup defines the resources and scheduling priorities of the stateroot setup job, which pulls the seed
+	// image and sets up the new stateroot during the Prep stage. If not defined, the job runs with the resources of
+	// the LCA manager container and the default scheduling priorities.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Stateroot Setup"
+	StaterootSetup *WorkloadResources `json:"staterootSetup,omitempty"`
+	// StaterootRetention defines which unbooted stateroots are kept once an upgrade is finalized, the others being
+	// pruned along with their unused precached images. If not defined, all the unbooted stateroots are removed when
+	// the upgrade is finalized or aborted.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Stateroot Retention"
+	StaterootRetention *StaterootRetention `json:"staterootRetention,omitempty"`
+	// NodeMetadata defines which of the user-applied labels, annotations and taints of the node are preserved across
+	// the upgrade, being reapplied during the post-pivot reconfiguration. If not defined, all of them are preserved.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Metadata"
+	NodeMetadata *NodeMetadata `json:"nodeMetadata,omitempty"`
+	// RollbackRetentionHours defines the number of hours, counted from the completion of the upgrade, during which the
+	// upgrade can not be finalized, so that the original stateroot is kept available for rollback. If not defined, the
+	// upgrade can be finalized as soon as it is completed.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Rollback Retention Hours",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	RollbackRetentionHours int `json:"rollbackRetentionHours,omitempty"`
+	// Hooks defines the user-defined Jobs run before a stage starts or once its work is done, before the stage is
+	// completed, such as quiescing an application before the pivot or re-registering the cluster with an element
+	// management system after the upgrade. The hooks of a stage are run one at a time, in the listed order.
+	// +kubebuilder:validation:MaxItems=32
+	// +kubebuilder:validation:XValidation:message="hook names must be unique",rule="self.all(h, self.exists_one(o, o.name == h.name))"
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Hooks"
+	Hooks []StageHook `json:"hooks,omitempty"`
+	// StallDetection defines when the stage in progress is reported as stalled, by the Progressing condition set to
+	// False with the Stalled reason and by the lca_ibu_stage_stalled metric. If not defined, a stage is stalled once in
+	// progress for three times its expected duration.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Stall Detection"
+	StallDetection *StallDetection `json:"stallDetection,omitempty"`
+	// CertificateRegeneration defines the parameters of the regeneration of the platform certificates by recert during
+	// the post-pivot reconfiguration. If not defined, the certificates are regenerated with the signers of the cluster
+	// and the expiration of the seed certificates extended.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Certificate Regeneration"
+	CertificateRegeneration *CertificateRegeneration `json:"certificateRegeneration,omitempty"`
+	// AdditionalTrustedCABundles defines the list of ConfigMap resources, holding a PEM CA bundle under the
+	// ca-bundle.crt key, that are trusted by the upgraded cluster from the post-pivot reconfiguration. The CA bundles
+	// are added to the host trust store before recert runs, and to the trustedCA ConfigMap of the cluster Proxy,
+	// enabling the images to be pulled and the hub to be reached through TLS-intercepting infrastructure.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Additional Trusted CA Bundles"
+	AdditionalTrustedCABundles []ConfigMapRef `json:"additionalTrustedCABundles,omitempty"`
+	// MaintenanceWindow defines the time window during which the transitions into the Upgrade and Rollback stages are
+	// executed. A transition requested outside the window is held, with the WaitingForWindow reason, and executed once
+	// the window opens. A stage started within the window runs to completion. If not defined, the transitions are
+	// executed as soon as they are requested.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Maintenance Window"
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+}
+
+// MaintenanceWindowRecurrence is how often the maintenance window opens
+type MaintenanceWindowRecurrence string
+
+// MaintenanceWindowRecurrences defines the recurrences of the maintenance window
+var MaintenanceWindowRecurrences = struct {
+	None   MaintenanceWindowRecurrence
+	Daily  MaintenanceWindowRecurrence
+	Weekly MaintenanceWindowRecurrence
+}{
+	None:   "None",
+	Daily:  "Daily",
+	Weekly: "Weekly",
 }
 
+// MaintenanceWindow defines the opening and the duration of the window, and how often it opens again
+// +kubebuilder:validation:XValidation:message="duration must be at most 24h for a Daily window",rule="!has(self.recurrence) || self.recurrence != 'Daily' || duration(self.duration) <= duration('24h')"
+// +kubebuilder:validation:XValidation:message="duration must be at most 168h for a Weekly window",rule="!has(self.recurrence) || self.recurrence != 'Weekly' || duration(self.duration) <= duration('168h')"
+type MaintenanceWindow struct {
+	// Start defines the time at which the window opens for the first time, in RFC 3339 format, e.g.
+	// 2025-06-01T02:00:00Z.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Start",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Start metav1.Time `json:"start"`
+	// Duration defines how long the window stays open once opened, e.g. 4h.
+	// +kubebuilder:validation:XValidation:message="duration must be positive",rule="duration(self) > duration('0s')"
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Duration",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Duration metav1.Duration `json:"duration"`
+	// Recurrence defines how often the window opens again from its start: every 24 hours with Daily, every 7 days with
+	// Weekly. If not defined or set to None, the window opens once.
+	// +kubebuilder:validation:Enum=None;Daily;Weekly
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Recurrence",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:None","urn:alm:descriptor:com.tectonic.ui:select:Daily","urn:alm:descriptor:com.tectonic.ui:select:Weekly"}
+	Recurrence MaintenanceWindowRecurrence `json:"recurrence,omitempty"`
+}
+
+// CertificateRegeneration defines the validity of the regenerated certificates, the custom CAs signing them and the
+// certificates preserved as is
+type CertificateRegeneration struct {
+	// ValidityDays defines the number of days the regenerated certificates are valid for. If not defined or set to 0,
+	// the expiration of the seed certificates is extended.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3650
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	ValidityDays int `json:"validityDays,omitempty"`
+	// CustomCAs defines the CAs replacing signers of the cluster, the certificates they sign being regenerated and
+	// signed by the custom CA instead.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:XValidation:message="signer CNs must be unique",rule="self.all(c, self.exists_one(o, o.signerCN == c.signerCN))"
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom CAs"
+	CustomCAs []CustomCA `json:"customCAs,omitempty"`
+	// PreservedCertificates defines the references to secrets of type kubernetes.io/tls holding certificates that are
+	// used as is instead of being regenerated, matched by their subject common name.
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Preserved Certificates"
+	PreservedCertificates []SecretRef `json:"preservedCertificates,omitempty"`
+}
+
+// CustomCA defines a CA replacing a signer of the cluster
+type CustomCA struct {
+	// SignerCN defines the common name of the signer of the cluster replaced by the CA, e.g. kube-apiserver-lb-signer.
+	// +kubebuilder:validation:MinLength=1
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Signer CN",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	SignerCN string `json:"signerCN"`
+	// SecretRef defines the reference to a secret of type kubernetes.io/tls holding the certificate and the private key
+	// of the CA.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Reference"
+	SecretRef SecretRef `json:"secretRef"`
+}
+
+// StallDetection defines the threshold past which the stage in progress is stalled
+type StallDetection struct {
+	// ThresholdPercent defines the percentage of the expected duration of the stage past which the stage is stalled.
+	// The expected duration is the average of the previous durations of the stage on the node, or the default
+	// duration of the stage if it never completed on the node. If not defined or set to 0, the default value of 300
+	// is used.
+	// +kubebuilder:validation:Maximum=10000
+	// +kubebuilder:validation:XValidation:message="thresholdPercent must be 0 or at least 100",rule="self == 0 || self >= 100"
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	ThresholdPercent int `json:"thresholdPercent,omitempty"`
+}
+
+// HookTrigger is when a hook runs in its stage
+type HookTrigger string
+
+const (
+	// HookBefore runs the hook before the work of the stage starts
+	HookBefore HookTrigger = "Before"
+	// HookAfter runs the hook once the work of the stage is done, before the stage is completed
+	HookAfter HookTrigger = "After"
+)
+
+// HookFailurePolicy is what happens to the stage when a hook fails or times out
+type HookFailurePolicy string
+
+const (
+	// HookFailurePolicyAbort fails the stage
+	HookFailurePolicyAbort HookFailurePolicy = "Abort"
+	// HookFailurePolicyContinue records the failure and carries on with the stage
+	HookFailurePolicyContinue HookFailurePolicy = "Continue"
+)
+
+// StageHook defines a user-defined Job run before or after a stage
+type StageHook struct {
+	// Name identifies the hook in the status, and names its Job lca-hook-<name>
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	// +kubebuilder:validation:MaxLength=54
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+	// Stage is the stage the hook is attached to
+	// +kubebuilder:validation:Enum=Prep;Upgrade;Rollback
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Stage ImageBasedUpgradeStage `json:"stage"`
+	// When the hook runs, Before the work of the stage starts or After it is done
+	// +kubebuilder:validation:Enum=Before;After
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	When HookTrigger `json:"when"`
+	// ConfigMapRef references the ConfigMap defining the hook, either as a batch/v1 Job manifest under the job key, or
+	// as a shell script under the script key, run with the container image under the image key. The Job runs in the
+	// namespace of the ConfigMap.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ConfigMap Reference"
+	ConfigMapRef ConfigMapRef `json:"configMapRef"`
+	// TimeoutSeconds is how long the Job of the hook is given to succeed
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=600
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy is what happens to the stage when the hook fails or times out. Abort fails the stage, while
+	// Continue records the failure in the status and carries on with the stage
+	// +kubebuilder:validation:Enum=Abort;Continue
+	// +kubebuilder:default=Abort
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Abort","urn:alm:descriptor:com.tectonic.ui:select:Continue"}
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// NodeMetadata defines the node labels, annotations and taints preserved across the upgrade. A key ending with "*"
+// matches all the keys starting with the rest of it. The annotations and taints of the kubernetes.io, k8s.io,
+// openshift.io and k8s.ovn.org domains are managed by the platform, and are never preserved.
+type NodeMetadata struct {
+	// Include defines the keys of the labels, annotations and taints that are preserved. If not defined, all of them
+	// are preserved.
+	// +optional
+	// +listType=atomic
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Include"
+	Include []string `json:"include,omitempty"`
+	// Exclude defines the keys of the labels, annotations and taints that are not preserved, taking precedence over
+	// the Include.
+	// +optional
+	// +listType=atomic
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exclude"
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// StaterootRetention defines the retention policy of the unbooted stateroots. A stateroot is kept as long as one of
+// the rules keeps it. The stateroot of the upgrade is never kept once the upgrade is aborted or rolled back.
+type StaterootRetention struct {
+	// KeepLast defines the number of the most recently deployed unbooted stateroots to keep.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	KeepLast int `json:"keepLast,omitempty"`
+	// KeepDays defines the number of days an unbooted stateroot is kept after it was deployed.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	KeepDays int `json:"keepDays,omitempty"`
+}
+
+// WorkloadResources defines the resources and scheduling priorities of a Prep stage workload, so that it does not
+// starve the latency-sensitive workloads running on the node
+type WorkloadResources struct {
+	// Resources defines the CPU and memory requests and limits of the workload container.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resources",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// NicePriority defines the niceness of the workload processes, from -20 (most favorable) to 19 (least
+	// favorable). If not defined, the default value of 0 is used.
+	// +kubebuilder:validation:Minimum=-20
+	// +kubebuilder:validation:Maximum=19
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	NicePriority *int `json:"nicePriority,omitempty"`
+	// IoNiceClass defines the I/O scheduling class of the workload processes: 0 for none, 1 for realtime, 2 for
+	// best-effort and 3 for idle. If not defined, the default best-effort class is used.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	IoNiceClass *int `json:"ioNiceClass,omitempty"`
+	// IoNicePriority defines the I/O scheduling priority of the workload processes within the realtime and best-effort
+	// classes, from 0 (highest) to 7 (lowest). If not defined, the default value of 4 is used.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=7
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	IoNicePriority *int `json:"ioNicePriority,omitempty"`
+}
+
+// HealthCheckConfig defines the selection of the cluster health checks
+type HealthCheckConfig struct {
+	// ExcludedClusterOperators lists the names of the ClusterOperators, e.g. ones intentionally disabled, that are
+	// not required to be available, and neither progressing nor degraded, by the cluster health checks.
+	// +kubebuilder:validation:MaxItems=64
+	// +listType=set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Excluded Cluster Operators"
+	ExcludedClusterOperators []string `json:"excludedClusterOperators,omitempty"`
+}
+
+// DiskSpaceValidation defines the thresholds of the disk space validation
+type DiskSpaceValidation struct {
+	// Disabled skips the disk space validation.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Disabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Disabled bool `json:"disabled,omitempty"`
+	// MinFreePercent defines the percentage of each filesystem that must remain available once the space required by
+	// the new stateroot and the precached images is used. If not defined or set to 0, the default value of 10 is used.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=90
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	MinFreePercent int `json:"minFreePercent,omitempty"`
+}
+
+// MirrorRegistryConfig defines the mirror registry configuration of the target stateroot
+type MirrorRegistryConfig struct {
+	// ImageDigestMirrors defines the mirrors applied as an ImageDigestMirrorSet on the target stateroot.
+	// +optional
+	// +listType=atomic
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Digest Mirrors"
+	ImageDigestMirrors []configv1.ImageDigestMirrors `json:"imageDigestMirrors,omitempty"`
+	// RepositoryDigestMirrors defines the mirrors applied as an ImageContentSourcePolicy on the target stateroot.
+	// +optional
+	// +listType=atomic
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Repository Digest Mirrors"
+	RepositoryDigestMirrors []operatorv1alpha1.RepositoryDigestMirrors `json:"repositoryDigestMirrors,omitempty"`
+	// CredentialsSecretRef defines the reference to a secret of type kubernetes.io/dockerconfigjson holding the
+	// credentials of the mirror registries. The credentials are merged into the pull secret of the target stateroot.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credentials Secret Reference"
+	CredentialsSecretRef *SecretRef `json:"credentialsSecretRef,omitempty"`
+}
+
+// PrecacheConfig defines tuning options for the image precaching job
+type PrecacheConfig struct {
+	// MaxConcurrentPulls defines the number of images pulled in parallel. If not defined or set to 0, the default
+	// value of 10 is used.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	MaxConcurrentPulls int `json:"maxConcurrentPulls,omitempty"`
+	// PullRetries defines the number of attempts made to pull an image before it is marked as failed. If not
+	// defined or set to 0, the default value of 5 is used.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	PullRetries int `json:"pullRetries,omitempty"`
+	// PullTimeoutSeconds defines the time limit in seconds for each attempt to pull an image, every attempt getting the
+	// full time limit. If not defined or set to 0, pull attempts are not time limited.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	PullTimeoutSeconds int `json:"pullTimeoutSeconds,omitempty"`
+	// WorkloadResources defines the resources and scheduling priorities of the precaching job. If not defined, the job
+	// requests 10m of CPU and 512Mi of memory, without limits.
+	WorkloadResources `json:",inline"`
+}
+
+// OADPConfig defines the tuning options of the OADP backups and restores
+type OADPConfig struct {
+	// BackupTimeoutSeconds defines the time limit in seconds for all the backups to complete, counted from the start
+	// of the backup phase. If not defined or set to 0, the backups are not time limited.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	BackupTimeoutSeconds int `json:"backupTimeoutSeconds,omitempty"`
+	// BackupRetries defines the number of times a failed backup is recreated before the upgrade is marked as failed.
+	// If not defined or set to 0, failed backups are not retried.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	BackupRetries int `json:"backupRetries,omitempty"`
+	// RestoreTimeoutSeconds defines the time limit in seconds for all the restores to complete, counted from the
+	// start of the restore phase. If not defined or set to 0, the restores are not time limited.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	RestoreTimeoutSeconds int `json:"restoreTimeoutSeconds,omitempty"`
+	// RestoreRetries defines the number of times a failed restore is recreated before the upgrade is marked as
+	// failed. If not defined or set to 0, failed restores are not retried.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	RestoreRetries int `json:"restoreRetries,omitempty"`
+	// PersistentVolumes defines how the content of the LVMS persistent volumes used by the workloads is carried over
+	// the upgrade. With Retain, the logical volumes are kept on the disk and bound again to the restored claims, LCA
+	// completing the backups of the claims with their PersistentVolumes and LogicalVolumes. With DataMover, the volumes
+	// are snapshotted and moved to the backup storage by the OADP Data Mover, then restored from it, the original
+	// logical volumes being left untouched for a rollback. If not defined, the backup and restore CRs are used as-is.
+	// +kubebuilder:validation:Enum=Retain;DataMover
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Persistent Volumes"
+	PersistentVolumes PersistentVolumesPolicy `json:"persistentVolumes,omitempty"`
+}
+
+// PersistentVolumesPolicy is how the content of the persistent volumes is carried over the upgrade
+type PersistentVolumesPolicy string
+
+const (
+	// PersistentVolumesRetain keeps the logical volumes on the disk and binds them again to the restored claims
+	PersistentVolumesRetain PersistentVolumesPolicy = "Retain"
+	// PersistentVolumesDataMover moves the snapshots of the volumes to the backup storage and restores them from it
+	PersistentVolumesDataMover PersistentVolumesPolicy = "DataMover"
+)
+
 // SeedImageRef defines the seed image and OCP version for the upgrade
 type SeedImageRef struct {
 	// Version defines the target platform version. The value must match the version of the seed image.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	Version string `json:"version,omitempty"`
-	// Image defines the full pull-spec of the seed container image to use.
+	// Image defines the full pull-spec of the seed container image to use. A seed image delivered as a file on the
+	// host is referenced as oci:<directory>[:<reference>]@<digest> for an OCI layout directory, or as
+	// oci-archive:<file>@<digest> for an OCI archive, the digest of the image manifest being verified before the seed
+	// image is loaded.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	// +kubebuilder:validation:MinLength=1
 	// +kubebuilder:validation:Pattern="^([a-z0-9]+://)?[\\S]+$"
 	Image string `json:"image,omitempty"`
-	// PullSecretRef defines the reference to a secret with credentials to pull container images.
+	// PullSecretRef defines the reference to a secret with credentials to pull container images. The credentials are
+	// used in place of the cluster pull-secret to pull the seed image, and are merged with the cluster pull-secret for
+	// the precaching job, without modifying the cluster pull-secret.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pull Secret Reference"
 	PullSecretRef *PullSecretRef `json:"pullSecretRef,omitempty"`
+	// SignatureVerification defines the policy used to verify the sigstore signature of the seed image before it is
+	// pulled during the Prep stage. If not defined, the signature is not verified.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Signature Verification"
+	SignatureVerification *SignatureVerification `json:"signatureVerification,omitempty"`
+	// DecryptionKeySecretRef defines the reference to a secret holding the private key, in PEM format under the
+	// seedDecryptionKey key, used to decrypt the layers of an encrypted seed image.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Decryption Key Secret Reference"
+	DecryptionKeySecretRef *SecretRef `json:"decryptionKeySecretRef,omitempty"`
+}
+
+// SignatureVerification defines how the sigstore signature of the seed image is verified. Exactly one of
+// publicKeySecretRef or keyless must be set.
+// +kubebuilder:validation:XValidation:message="exactly one of publicKeySecretRef or keyless must be set",rule="has(self.publicKeySecretRef) != has(self.keyless)"
+type SignatureVerification struct {
+	// PublicKeySecretRef defines the reference to a secret holding the public key, in PEM format under the
+	// cosign.pub key, the seed image must be signed with.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Public Key Secret Reference"
+	PublicKeySecretRef *SecretRef `json:"publicKeySecretRef,omitempty"`
+	// Keyless defines the identity the seed image must be signed by, using a keyless signature issued by Fulcio and
+	// recorded in Rekor.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Keyless"
+	Keyless *KeylessSignatureVerification `json:"keyless,omitempty"`
+}
+
+// KeylessSignatureVerification defines the trust roots and signer identity of a keyless signature
+type KeylessSignatureVerification struct {
+	// TrustRootSecretRef defines the reference to a secret holding the Fulcio CA certificates, in PEM format under
+	// the fulcio.crt key, and the Rekor public key, in PEM format under the rekor.pub key.
+	// +kubebuilder:validation:Required
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Trust Root Secret Reference"
+	TrustRootSecretRef SecretRef `json:"trustRootSecretRef"`
+	// OIDCIssuer defines the OIDC issuer that must have authenticated the signer.
+	// +kubebuilder:validation:MinLength=1
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OIDC Issuer",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	OIDCIssuer string `json:"oidcIssuer"`
+	// SubjectEmail defines the email address the signing certificate must be issued to.
+	// +kubebuilder:validation:MinLength=1
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Subject Email",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	SubjectEmail string `json:"subjectEmail"`
+}
+
+// SecretRef defines a reference to a secret in the lifecycle agent namespace
+type SecretRef struct {
+	// +kubebuilder:validation:Required
+	// +required
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
 }
 
 // AutoRollbackOnFailure defines automatic rollback settings if the upgrade fails or if the upgrade does not
@@ -103,6 +558,21 @@ type AutoRollbackOnFailure struct {
 	// +kubebuilder:validation:Minimum=0
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
 	InitMonitorTimeoutSeconds int `json:"initMonitorTimeoutSeconds,omitempty"` // LCA Init Monitor watchdog timeout, in seconds. Value = 0 is treated as "use default" when writing config file in Prep stage
+	// InitMonitor enables the automatic rollback triggered by the LCA Init Monitor watchdog when the upgrade does not
+	// complete before the timeout expires. If not defined, the auto-rollback-on-failure.lca.openshift.io/init-monitor
+	// annotation is honored, otherwise it is enabled.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Init Monitor",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	InitMonitor *bool `json:"initMonitor,omitempty"`
+	// PostRebootConfig enables the automatic rollback when the reconfiguration of the cluster fails upon the first
+	// reboot into the new stateroot. If not defined, the auto-rollback-on-failure.lca.openshift.io/post-reboot-config
+	// annotation is honored, otherwise it is enabled.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Post Reboot Config",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	PostRebootConfig *bool `json:"postRebootConfig,omitempty"`
+	// UpgradeCompletion enables the automatic rollback when the Lifecycle Agent fails to complete the upgrade, such as
+	// failing health checks after the pivot. If not defined, the auto-rollback-on-failure.lca.openshift.io/upgrade-completion
+	// annotation is honored, otherwise it is enabled.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Upgrade Completion",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	UpgradeCompletion *bool `json:"upgradeCompletion,omitempty"`
 }
 
 // ConfigMapRef defines a reference to a config map
@@ -139,6 +609,185 @@ type ImageBasedUpgradeStatus struct {
 	// History stores timing info of different IBU stages and their important phases
 	// +optional
 	History []*History `json:"history,omitempty"`
+	// Progress reports the granular progress of the stage currently being processed
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Progress"
+	Progress *StageProgress `json:"progress,omitempty"`
+	// Precache reports the progress of the image precaching done during the Prep stage
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Precache"
+	Precache *PrecacheStatus `json:"precache,omitempty"`
+	// UpgradeHistory records the outcome of every stage transition. Unlike the History, it is retained across the
+	// transitions to Idle, up to the most recent 20 entries
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Upgrade History"
+	UpgradeHistory []UpgradeHistoryEntry `json:"upgradeHistory,omitempty"`
+	// Estimate projects the completion of the stage currently being processed, and of the overall upgrade, from the
+	// durations of the same stages in the previous upgrades of this cluster
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Estimate"
+	Estimate *CompletionEstimate `json:"estimate,omitempty"`
+	// OADP reports the progress of the OADP backups and restores referenced by the spec.oadpContent, in the order of
+	// their apply-wave
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="OADP"
+	OADP *OADPStatus `json:"oadp,omitempty"`
+	// SeedImageInfo reports the metadata of the seed image referenced by the spec.seedImageRef, inspected without
+	// pulling the image
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Seed Image Info"
+	SeedImageInfo *SeedImageInfo `json:"seedImageInfo,omitempty"`
+	// AuditLogPath is the path, on the node, of the append-only audit trail of the upgrade actions. It can be
+	// retrieved with lca-cli audit export
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Audit Log Path"
+	AuditLogPath string `json:"auditLogPath,omitempty"`
+	// RolloutStatus summarizes the desired stage as <stage>:<state>:<percent>[:<error>], the state being one of Idle,
+	// InProgress, Completed, Failed or Blocked. It is meant to be aggregated across a fleet, e.g. through a
+	// ManagedClusterView, without interpreting the conditions of each cluster
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Rollout Status"
+	RolloutStatus string `json:"rolloutStatus,omitempty"`
+	// Hooks reports the outcome of the spec.hooks run so far, in the order they were started
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Hooks"
+	Hooks []HookStatus `json:"hooks,omitempty"`
+	// AutoRollbackDeadline reports when the LCA Init Monitor watchdog rolls back the upgrade if it is not completed by
+	// then, including the extension set by the auto-rollback-on-failure.lca.openshift.io/init-monitor-deadline-extension
+	// annotation. It is only reported after the pivot, while the watchdog is running.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Auto Rollback Deadline"
+	AutoRollbackDeadline *metav1.Time `json:"autoRollbackDeadline,omitempty"`
+}
+
+// HookStatus reports the outcome of a user-defined hook
+type HookStatus struct {
+	// Name The name of the hook
+	Name string `json:"name"`
+	// Stage The stage the hook is attached to
+	Stage ImageBasedUpgradeStage `json:"stage"`
+	// When Whether the hook runs Before or After the work of the stage
+	When HookTrigger `json:"when"`
+	// Phase One of Pending, Running, Succeeded, Failed or TimedOut
+	Phase string `json:"phase"`
+	// Message Details on the failure of the hook
+	Message string `json:"message,omitempty"`
+	// StartedAt When the Job of the hook was created
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+	// CompletedAt When the hook succeeded, failed or timed out
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// SeedImageInfo reports the metadata of a seed image
+type SeedImageInfo struct {
+	// Image The seed image pull-spec that was inspected
+	Image string `json:"image"`
+	// Digest The digest of the seed image
+	Digest string `json:"digest,omitempty"`
+	// CreationTime When the seed image was built
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+	// OCPVersion The OCP version of the seed cluster
+	OCPVersion string `json:"ocpVersion,omitempty"`
+	// BaseOSVersion The RHCOS version of the seed cluster
+	BaseOSVersion string `json:"baseOSVersion,omitempty"`
+	// Components The operators, as the names of their ClusterServiceVersions, installed on the seed cluster
+	Components []string `json:"components,omitempty"`
+}
+
+// OADPStatus reports the progress of the OADP backups and restores of the upgrade
+type OADPStatus struct {
+	// Backups The progress of the Backup CRs, taken before the pivot
+	Backups []OADPResourceStatus `json:"backups,omitempty"`
+	// Restores The progress of the Restore CRs, applied after the pivot
+	Restores []OADPResourceStatus `json:"restores,omitempty"`
+}
+
+// OADPResourceStatus reports the progress of an OADP Backup or Restore CR
+type OADPResourceStatus struct {
+	// Name The name of the CR
+	Name string `json:"name"`
+	// Namespace The namespace of the CR
+	Namespace string `json:"namespace,omitempty"`
+	// Wave The position, starting from 1, of the apply-wave group the CR is processed in
+	Wave int `json:"wave"`
+	// Phase One of Pending, InProgress, Completed, Failed or Skipped
+	Phase string `json:"phase"`
+	// Optional Whether the CR is annotated with lca.openshift.io/optional, in which case its failure does not fail
+	// the upgrade
+	Optional bool `json:"optional,omitempty"`
+}
+
+// CompletionEstimate reports when the current stage and the overall upgrade are expected to complete
+type CompletionEstimate struct {
+	// Stage The stage this estimate refers to
+	Stage ImageBasedUpgradeStage `json:"stage,omitempty"`
+	// StageCompletionTime A projection of when the Stage will complete, based on the average duration of the Stage
+	StageCompletionTime metav1.Time `json:"stageCompletionTime,omitempty"`
+	// UpgradeCompletionTime A projection of when the Upgrade stage will complete, adding the average duration of the
+	// Upgrade stage while in the Prep stage. This is not available during a Rollback
+	UpgradeCompletionTime metav1.Time `json:"upgradeCompletionTime,omitempty"`
+	// Samples The number of previous Stage durations the estimate is based on
+	Samples int `json:"samples,omitempty"`
+}
+
+// UpgradeHistoryEntry records a stage transition
+type UpgradeHistoryEntry struct {
+	// Stage The desired stage
+	Stage ImageBasedUpgradeStage `json:"stage"`
+	// SeedImage The seed image used when the stage started
+	SeedImage string `json:"seedImage,omitempty"`
+	// SeedVersion The seed image version used when the stage started
+	SeedVersion string `json:"seedVersion,omitempty"`
+	// StartTime A timestamp indicating the stage has started
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime A timestamp indicating the stage has ended, successfully or not
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+	// Outcome The result of the stage, one of Completed, Failed or Aborted, once the stage has ended
+	Outcome string `json:"outcome,omitempty"`
+	// Message The reason of the failure, or whether the upgrade was finalized or aborted for the Idle stage
+	Message string `json:"message,omitempty"`
+}
+
+// PrecacheStatus reports the progress of the image precaching job
+type PrecacheStatus struct {
+	// Total The number of images to be precached
+	Total int `json:"total,omitempty"`
+	// Pulled The number of images successfully precached
+	Pulled int `json:"pulled,omitempty"`
+	// Failed The number of images that could not be precached
+	Failed int `json:"failed,omitempty"`
+	// PulledBytes The total size of the images successfully precached
+	PulledBytes int64 `json:"pulledBytes,omitempty"`
+	// CurrentImages The images currently being pulled
+	CurrentImages []string `json:"currentImages,omitempty"`
+	// FailedPulls The images that could not be precached, along with the reason for the failure
+	FailedPulls []FailedPull `json:"failedPulls,omitempty"`
+}
+
+// FailedPull defines an image that could not be precached
+type FailedPull struct {
+	// Image The pull-spec of the image
+	Image string `json:"image"`
+	// Reason The error returned by the last pull attempt
+	Reason string `json:"reason,omitempty"`
+}
+
+// StageProgress reports how far along the current stage is
+type StageProgress struct {
+	// Stage The stage this progress refers to
+	Stage ImageBasedUpgradeStage `json:"stage,omitempty"`
+	// CurrentStep A short description of the step currently being performed
+	CurrentStep string `json:"currentStep,omitempty"`
+	// PercentComplete An estimate of how much of the stage has been completed
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	PercentComplete int `json:"percentComplete,omitempty"`
+	// StartTime A timestamp indicating the Stage has started
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// EstimatedCompletionTime A projection of when the Stage will complete, based on the elapsed time and percent complete.
+	// This is only available once some progress has been made
+	EstimatedCompletionTime metav1.Time `json:"estimatedCompletionTime,omitempty"`
 }
 
 type History struct {
@@ -21,6 +21,9 @@ limitations under the License.
 package v1
 
 import (
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/operator/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -28,6 +31,21 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoRollbackOnFailure) DeepCopyInto(out *AutoRollbackOnFailure) {
 	*out = *in
+	if in.InitMonitor != nil {
+		in, out := &in.InitMonitor, &out.InitMonitor
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PostRebootConfig != nil {
+		in, out := &in.PostRebootConfig, &out.PostRebootConfig
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UpgradeCompletion != nil {
+		in, out := &in.UpgradeCompletion, &out.UpgradeCompletion
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoRollbackOnFailure.
@@ -40,6 +58,48 @@ func (in *AutoRollbackOnFailure) DeepCopy() *AutoRollbackOnFailure {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRegeneration) DeepCopyInto(out *CertificateRegeneration) {
+	*out = *in
+	if in.CustomCAs != nil {
+		in, out := &in.CustomCAs, &out.CustomCAs
+		*out = make([]CustomCA, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreservedCertificates != nil {
+		in, out := &in.PreservedCertificates, &out.PreservedCertificates
+		*out = make([]SecretRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRegeneration.
+func (in *CertificateRegeneration) DeepCopy() *CertificateRegeneration {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRegeneration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompletionEstimate) DeepCopyInto(out *CompletionEstimate) {
+	*out = *in
+	in.StageCompletionTime.DeepCopyInto(&out.StageCompletionTime)
+	in.UpgradeCompletionTime.DeepCopyInto(&out.UpgradeCompletionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompletionEstimate.
+func (in *CompletionEstimate) DeepCopy() *CompletionEstimate {
+	if in == nil {
+		return nil
+	}
+	out := new(CompletionEstimate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
 	*out = *in
@@ -55,6 +115,72 @@ func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomCA) DeepCopyInto(out *CustomCA) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomCA.
+func (in *CustomCA) DeepCopy() *CustomCA {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomCA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskSpaceValidation) DeepCopyInto(out *DiskSpaceValidation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSpaceValidation.
+func (in *DiskSpaceValidation) DeepCopy() *DiskSpaceValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskSpaceValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedPull) DeepCopyInto(out *FailedPull) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedPull.
+func (in *FailedPull) DeepCopy() *FailedPull {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedPull)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckConfig) DeepCopyInto(out *HealthCheckConfig) {
+	*out = *in
+	if in.ExcludedClusterOperators != nil {
+		in, out := &in.ExcludedClusterOperators, &out.ExcludedClusterOperators
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckConfig.
+func (in *HealthCheckConfig) DeepCopy() *HealthCheckConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *History) DeepCopyInto(out *History) {
 	*out = *in
@@ -83,6 +209,26 @@ func (in *History) DeepCopy() *History {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageBasedUpgrade) DeepCopyInto(out *ImageBasedUpgrade) {
 	*out = *in
@@ -142,6 +288,125 @@ func (in *ImageBasedUpgradeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageBasedUpgradePreflight) DeepCopyInto(out *ImageBasedUpgradePreflight) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageBasedUpgradePreflight.
+func (in *ImageBasedUpgradePreflight) DeepCopy() *ImageBasedUpgradePreflight {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageBasedUpgradePreflight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImageBasedUpgradePreflight) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageBasedUpgradePreflightList) DeepCopyInto(out *ImageBasedUpgradePreflightList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ImageBasedUpgradePreflight, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageBasedUpgradePreflightList.
+func (in *ImageBasedUpgradePreflightList) DeepCopy() *ImageBasedUpgradePreflightList {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageBasedUpgradePreflightList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImageBasedUpgradePreflightList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageBasedUpgradePreflightSpec) DeepCopyInto(out *ImageBasedUpgradePreflightSpec) {
+	*out = *in
+	in.SeedImageRef.DeepCopyInto(&out.SeedImageRef)
+	if in.OADPContent != nil {
+		in, out := &in.OADPContent, &out.OADPContent
+		*out = make([]ConfigMapRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraManifests != nil {
+		in, out := &in.ExtraManifests, &out.ExtraManifests
+		*out = make([]ConfigMapRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiskSpaceValidation != nil {
+		in, out := &in.DiskSpaceValidation, &out.DiskSpaceValidation
+		*out = new(DiskSpaceValidation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageBasedUpgradePreflightSpec.
+func (in *ImageBasedUpgradePreflightSpec) DeepCopy() *ImageBasedUpgradePreflightSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageBasedUpgradePreflightSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageBasedUpgradePreflightStatus) DeepCopyInto(out *ImageBasedUpgradePreflightStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]PreflightCheck, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageBasedUpgradePreflightStatus.
+func (in *ImageBasedUpgradePreflightStatus) DeepCopy() *ImageBasedUpgradePreflightStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageBasedUpgradePreflightStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageBasedUpgradeSpec) DeepCopyInto(out *ImageBasedUpgradeSpec) {
 	*out = *in
@@ -151,6 +416,11 @@ func (in *ImageBasedUpgradeSpec) DeepCopyInto(out *ImageBasedUpgradeSpec) {
 		*out = make([]ConfigMapRef, len(*in))
 		copy(*out, *in)
 	}
+	if in.OADPConfig != nil {
+		in, out := &in.OADPConfig, &out.OADPConfig
+		*out = new(OADPConfig)
+		**out = **in
+	}
 	if in.ExtraManifests != nil {
 		in, out := &in.ExtraManifests, &out.ExtraManifests
 		*out = make([]ConfigMapRef, len(*in))
@@ -159,8 +429,78 @@ func (in *ImageBasedUpgradeSpec) DeepCopyInto(out *ImageBasedUpgradeSpec) {
 	if in.AutoRollbackOnFailure != nil {
 		in, out := &in.AutoRollbackOnFailure, &out.AutoRollbackOnFailure
 		*out = new(AutoRollbackOnFailure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Precache != nil {
+		in, out := &in.Precache, &out.Precache
+		*out = new(PrecacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthChecks != nil {
+		in, out := &in.HealthChecks, &out.HealthChecks
+		*out = make([]ConfigMapRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreservedPaths != nil {
+		in, out := &in.PreservedPaths, &out.PreservedPaths
+		*out = make([]ConfigMapRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthCheckConfig != nil {
+		in, out := &in.HealthCheckConfig, &out.HealthCheckConfig
+		*out = new(HealthCheckConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MirrorRegistryConfig != nil {
+		in, out := &in.MirrorRegistryConfig, &out.MirrorRegistryConfig
+		*out = new(MirrorRegistryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskSpaceValidation != nil {
+		in, out := &in.DiskSpaceValidation, &out.DiskSpaceValidation
+		*out = new(DiskSpaceValidation)
+		**out = **in
+	}
+	if in.StaterootSetup != nil {
+		in, out := &in.StaterootSetup, &out.StaterootSetup
+		*out = new(WorkloadResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StaterootRetention != nil {
+		in, out := &in.StaterootRetention, &out.StaterootRetention
+		*out = new(StaterootRetention)
 		**out = **in
 	}
+	if in.NodeMetadata != nil {
+		in, out := &in.NodeMetadata, &out.NodeMetadata
+		*out = new(NodeMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]StageHook, len(*in))
+		copy(*out, *in)
+	}
+	if in.StallDetection != nil {
+		in, out := &in.StallDetection, &out.StallDetection
+		*out = new(StallDetection)
+		**out = **in
+	}
+	if in.CertificateRegeneration != nil {
+		in, out := &in.CertificateRegeneration, &out.CertificateRegeneration
+		*out = new(CertificateRegeneration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalTrustedCABundles != nil {
+		in, out := &in.AdditionalTrustedCABundles, &out.AdditionalTrustedCABundles
+		*out = make([]ConfigMapRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageBasedUpgradeSpec.
@@ -200,6 +540,49 @@ func (in *ImageBasedUpgradeStatus) DeepCopyInto(out *ImageBasedUpgradeStatus) {
 			}
 		}
 	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(StageProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Precache != nil {
+		in, out := &in.Precache, &out.Precache
+		*out = new(PrecacheStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeHistory != nil {
+		in, out := &in.UpgradeHistory, &out.UpgradeHistory
+		*out = make([]UpgradeHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Estimate != nil {
+		in, out := &in.Estimate, &out.Estimate
+		*out = new(CompletionEstimate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OADP != nil {
+		in, out := &in.OADP, &out.OADP
+		*out = new(OADPStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SeedImageInfo != nil {
+		in, out := &in.SeedImageInfo, &out.SeedImageInfo
+		*out = new(SeedImageInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]HookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AutoRollbackDeadline != nil {
+		in, out := &in.AutoRollbackDeadline, &out.AutoRollbackDeadline
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageBasedUpgradeStatus.
@@ -212,6 +595,153 @@ func (in *ImageBasedUpgradeStatus) DeepCopy() *ImageBasedUpgradeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeylessSignatureVerification) DeepCopyInto(out *KeylessSignatureVerification) {
+	*out = *in
+	out.TrustRootSecretRef = in.TrustRootSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeylessSignatureVerification.
+func (in *KeylessSignatureVerification) DeepCopy() *KeylessSignatureVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(KeylessSignatureVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorRegistryConfig) DeepCopyInto(out *MirrorRegistryConfig) {
+	*out = *in
+	if in.ImageDigestMirrors != nil {
+		in, out := &in.ImageDigestMirrors, &out.ImageDigestMirrors
+		*out = make([]configv1.ImageDigestMirrors, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RepositoryDigestMirrors != nil {
+		in, out := &in.RepositoryDigestMirrors, &out.RepositoryDigestMirrors
+		*out = make([]v1alpha1.RepositoryDigestMirrors, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorRegistryConfig.
+func (in *MirrorRegistryConfig) DeepCopy() *MirrorRegistryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorRegistryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMetadata) DeepCopyInto(out *NodeMetadata) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetadata.
+func (in *NodeMetadata) DeepCopy() *NodeMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OADPConfig) DeepCopyInto(out *OADPConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OADPConfig.
+func (in *OADPConfig) DeepCopy() *OADPConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OADPConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OADPResourceStatus) DeepCopyInto(out *OADPResourceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OADPResourceStatus.
+func (in *OADPResourceStatus) DeepCopy() *OADPResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OADPResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OADPStatus) DeepCopyInto(out *OADPStatus) {
+	*out = *in
+	if in.Backups != nil {
+		in, out := &in.Backups, &out.Backups
+		*out = make([]OADPResourceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Restores != nil {
+		in, out := &in.Restores, &out.Restores
+		*out = make([]OADPResourceStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OADPStatus.
+func (in *OADPStatus) DeepCopy() *OADPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OADPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Phase) DeepCopyInto(out *Phase) {
 	*out = *in
@@ -229,6 +759,62 @@ func (in *Phase) DeepCopy() *Phase {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrecacheConfig) DeepCopyInto(out *PrecacheConfig) {
+	*out = *in
+	in.WorkloadResources.DeepCopyInto(&out.WorkloadResources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrecacheConfig.
+func (in *PrecacheConfig) DeepCopy() *PrecacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PrecacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrecacheStatus) DeepCopyInto(out *PrecacheStatus) {
+	*out = *in
+	if in.CurrentImages != nil {
+		in, out := &in.CurrentImages, &out.CurrentImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailedPulls != nil {
+		in, out := &in.FailedPulls, &out.FailedPulls
+		*out = make([]FailedPull, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrecacheStatus.
+func (in *PrecacheStatus) DeepCopy() *PrecacheStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrecacheStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreflightCheck) DeepCopyInto(out *PreflightCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheck.
+func (in *PreflightCheck) DeepCopy() *PreflightCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(PreflightCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullSecretRef) DeepCopyInto(out *PullSecretRef) {
 	*out = *in
@@ -244,6 +830,45 @@ func (in *PullSecretRef) DeepCopy() *PullSecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRef) DeepCopyInto(out *SecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRef.
+func (in *SecretRef) DeepCopy() *SecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedImageInfo) DeepCopyInto(out *SeedImageInfo) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedImageInfo.
+func (in *SeedImageInfo) DeepCopy() *SeedImageInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedImageInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SeedImageRef) DeepCopyInto(out *SeedImageRef) {
 	*out = *in
@@ -252,6 +877,16 @@ func (in *SeedImageRef) DeepCopyInto(out *SeedImageRef) {
 		*out = new(PullSecretRef)
 		**out = **in
 	}
+	if in.SignatureVerification != nil {
+		in, out := &in.SignatureVerification, &out.SignatureVerification
+		*out = new(SignatureVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DecryptionKeySecretRef != nil {
+		in, out := &in.DecryptionKeySecretRef, &out.DecryptionKeySecretRef
+		*out = new(SecretRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedImageRef.
@@ -263,3 +898,143 @@ func (in *SeedImageRef) DeepCopy() *SeedImageRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureVerification) DeepCopyInto(out *SignatureVerification) {
+	*out = *in
+	if in.PublicKeySecretRef != nil {
+		in, out := &in.PublicKeySecretRef, &out.PublicKeySecretRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.Keyless != nil {
+		in, out := &in.Keyless, &out.Keyless
+		*out = new(KeylessSignatureVerification)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureVerification.
+func (in *SignatureVerification) DeepCopy() *SignatureVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageHook) DeepCopyInto(out *StageHook) {
+	*out = *in
+	out.ConfigMapRef = in.ConfigMapRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageHook.
+func (in *StageHook) DeepCopy() *StageHook {
+	if in == nil {
+		return nil
+	}
+	out := new(StageHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageProgress) DeepCopyInto(out *StageProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EstimatedCompletionTime.DeepCopyInto(&out.EstimatedCompletionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageProgress.
+func (in *StageProgress) DeepCopy() *StageProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(StageProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StallDetection) DeepCopyInto(out *StallDetection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StallDetection.
+func (in *StallDetection) DeepCopy() *StallDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(StallDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaterootRetention) DeepCopyInto(out *StaterootRetention) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaterootRetention.
+func (in *StaterootRetention) DeepCopy() *StaterootRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(StaterootRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeHistoryEntry) DeepCopyInto(out *UpgradeHistoryEntry) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeHistoryEntry.
+func (in *UpgradeHistoryEntry) DeepCopy() *UpgradeHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadResources) DeepCopyInto(out *WorkloadResources) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NicePriority != nil {
+		in, out := &in.NicePriority, &out.NicePriority
+		*out = new(int)
+		**out = **in
+	}
+	if in.IoNiceClass != nil {
+		in, out := &in.IoNiceClass, &out.IoNiceClass
+		*out = new(int)
+		**out = **in
+	}
+	if in.IoNicePriority != nil {
+		in, out := &in.IoNicePriority, &out.IoNicePriority
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadResources.
+func (in *WorkloadResources) DeepCopy() *WorkloadResources {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadResources)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -41,6 +41,8 @@ type SeedGenerator struct {
 }
 
 // SeedGeneratorSpec defines the desired state of SeedGenerator
+// +kubebuilder:validation:XValidation:message="seedImage must be referenced by tag when a schedule is set",rule="!has(self.schedule) || !self.seedImage.contains('@')"
+// +kubebuilder:validation:XValidation:message="seedImage must be referenced by tag when multiArch is set",rule="!has(self.multiArch) || !self.multiArch || !self.seedImage.contains('@')"
 type SeedGeneratorSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Seed Image",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	// +kubebuilder:validation:MinLength=1
@@ -53,6 +55,126 @@ type SeedGeneratorSpec struct {
 	// +kubebuilder:validation:Pattern="^([a-z0-9]+://)?[\\S]+$"
 	// RecertImage defines the full pull-spec of the recert container image to use.
 	RecertImage string `json:"recertImage,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Base Seed Image",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern="^([a-z0-9]+://)?[\\S]+$"
+	// BaseSeedImage defines the full pull-spec of a full seed container image, generated from a seed cluster of the same
+	// configuration, on top of which a layered seed image is created. A layered seed image only includes the ostree
+	// changes since its base seed image, which must remain available in the registry for the upgrades.
+	// +optional
+	BaseSeedImage string `json:"baseSeedImage,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exclusions"
+	// Exclusions defines the site-specific or sensitive content to strip from the seed image.
+	// +optional
+	Exclusions *SeedExclusions `json:"exclusions,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Compression"
+	// Compression defines how the content of the seed image is compressed, trading CPU time on the seed SNO for a
+	// smaller seed image. Defaults to gzip at its default level.
+	// +optional
+	Compression *SeedCompression `json:"compression,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Etcd"
+	// Etcd defines the etcd content of the seed image, trading the data carried over from the seed cluster for a
+	// smaller seed image. Defaults to the full etcd data.
+	// +optional
+	Etcd *SeedEtcd `json:"etcd,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// Schedule defines, in the cron format (e.g. "0 3 * * 6"), when the seed image is re-generated once completed. A
+	// scheduled re-generation only happens if the seed cluster was updated to another version since the last generated
+	// seed image, and pushes the seed image tagged with the generation time as a suffix (e.g. seed:4.16-20241012T030000Z).
+	// +kubebuilder:validation:MinLength=9
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Multi-Architecture",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// MultiArch pushes the seed image tagged with the architecture of the seed SNO as a suffix (e.g. seed:4.16-arm64),
+	// and adds it to the manifest list of the seedImage pull-spec, replacing the seed image of the same architecture.
+	// The seed images generated from seed SNOs of different architectures then share the seedImage pull-spec, the
+	// Prep stage of the upgrade selecting the seed image of the architecture of the node.
+	// +optional
+	MultiArch bool `json:"multiArch,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Retention"
+	// Retention defines how many generated seed images are kept.
+	// +optional
+	Retention *SeedImageRetention `json:"retention,omitempty"`
+}
+
+// SeedCompression defines the compression of the archives of the seed image. The compression is detected when the
+// seed image is extracted, so that the seed images compressed with any algorithm can be used for the upgrades.
+// +kubebuilder:validation:XValidation:message="the gzip compression level must be between 1 and 9",rule="self.algorithm != 'gzip' || !has(self.level) || self.level <= 9"
+type SeedCompression struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Algorithm",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:gzip","urn:alm:descriptor:com.tectonic.ui:select:zstd"}
+	// Algorithm is the compression algorithm, zstd being faster to decompress and giving smaller seed images.
+	// +kubebuilder:validation:Enum=gzip;zstd
+	// +kubebuilder:default=gzip
+	Algorithm string `json:"algorithm"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Level",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	// Level is the compression level, from 1 (fastest) to 9 for gzip and to 19 for zstd (smallest). Defaults to the
+	// default level of the algorithm.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=19
+	// +optional
+	Level *int `json:"level,omitempty"`
+}
+
+// SeedEtcd defines the etcd content of the seed image. The etcd content is recorded in the seed image metadata.
+type SeedEtcd struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Content",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Full","urn:alm:descriptor:com.tectonic.ui:select:Trimmed","urn:alm:descriptor:com.tectonic.ui:select:Excluded"}
+	// Content is Full to carry the etcd data dir as-is, Trimmed to delete the events of the seed cluster and compact
+	// and defragment the etcd database first, or Excluded to leave the etcd data dir out of the seed image and only
+	// carry a snapshot of the trimmed database, from which the etcd data dir is re-initialized when the new stateroot
+	// is set up on the target cluster.
+	// +kubebuilder:validation:Enum=Full;Trimmed;Excluded
+	// +kubebuilder:default=Full
+	Content string `json:"content"`
+}
+
+// SeedImageRetention defines the retention policy of the generated seed images
+type SeedImageRetention struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Keep Images",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	// KeepImages is the number of most recently generated seed images recorded in the status. The older seed images
+	// pushed by a scheduled re-generation are deleted from the registry, while the seed image of the seedImage
+	// pull-spec itself is never deleted.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	KeepImages int `json:"keepImages"`
+}
+
+// SeedExclusions defines the content excluded from the seed image. The applied exclusions are recorded in the seed
+// image metadata.
+type SeedExclusions struct {
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Excluded Paths"
+	// Paths lists the files or directories, under /var or /etc, to leave out of the seed image. Shell-style
+	// wildcards (e.g. /var/opt/site/*) are supported.
+	// +kubebuilder:validation:XValidation:message="paths must be under /var or /etc and must not contain single quotes",rule="self.all(p, (p.startsWith('/var/') || p.startsWith('/etc/')) && !p.contains(\"'\"))"
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:MaxLength=1024
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Excluded Secret Name Patterns"
+	// SecretNamePatterns lists the <namespace>/<name pattern> of the secrets to delete from the seed cluster before the
+	// seed image is created, the name pattern being shell-style (e.g. site-config/site-*-tls). The namespace cannot be
+	// a pattern nor a openshift-*, kube-* or default namespace, and the name pattern cannot be wildcards only.
+	// +kubebuilder:validation:XValidation:message="patterns must be <namespace>/<name pattern>, outside of the openshift-*, kube-* and default namespaces, with a name pattern that is not wildcards only",rule="self.all(p, p.matches('^[a-z0-9]([-a-z0-9]*[a-z0-9])?/[^/]*[^/*?][^/]*$') && !p.startsWith('openshift-') && !p.startsWith('kube-') && !p.startsWith('default/'))"
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:MaxLength=317
+	// +optional
+	SecretNamePatterns []string `json:"secretNamePatterns,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Excluded Namespaces"
+	// Namespaces lists the namespaces to delete from the seed cluster before the seed image is created.
+	// +kubebuilder:validation:XValidation:message="openshift-*, kube-* and default namespaces cannot be excluded",rule="self.all(n, !n.startsWith('openshift-') && !n.startsWith('kube-') && n != 'default')"
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:MaxLength=63
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
 }
 
 // SeedGeneratorStatus defines the observed state of SeedGenerator
@@ -63,6 +185,30 @@ type SeedGeneratorStatus struct {
 	CompletedAt        metav1.Time `json:"completedAt,omitempty"`
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Schedule Time"
+	// LastScheduleTime is when the spec.schedule last triggered, whether or not the seed image was re-generated
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Generated Images"
+	// GeneratedImages are the generated seed images, the most recent last
+	GeneratedImages []GeneratedSeedImage `json:"generatedImages,omitempty"`
+}
+
+// GeneratedSeedImage records a seed image pushed by the SeedGenerator
+type GeneratedSeedImage struct {
+	// Image is the pull-spec of the seed image
+	Image string `json:"image"`
+	// Digest is the digest of the pushed seed image
+	Digest string `json:"digest,omitempty"`
+	// Version is the OCP version of the seed cluster when the seed image was generated
+	Version string `json:"version,omitempty"`
+	// Release is the OCP release image of the seed cluster included in the seed image
+	Release string `json:"release,omitempty"`
+	// Size is the compressed size, in bytes, of the seed image layers
+	Size int64 `json:"size,omitempty"`
+	// ContentHash is the digest of the content manifest of the seed image, listing the digests of the files it includes
+	ContentHash string `json:"contentHash,omitempty"`
+	// GeneratedAt is when the seed image generation completed
+	GeneratedAt metav1.Time `json:"generatedAt"`
 }
 
 //+kubebuilder:object:root=true
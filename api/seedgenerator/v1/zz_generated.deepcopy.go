@@ -25,12 +25,93 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedSeedImage) DeepCopyInto(out *GeneratedSeedImage) {
+	*out = *in
+	in.GeneratedAt.DeepCopyInto(&out.GeneratedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedSeedImage.
+func (in *GeneratedSeedImage) DeepCopy() *GeneratedSeedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedSeedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedCompression) DeepCopyInto(out *SeedCompression) {
+	*out = *in
+	if in.Level != nil {
+		in, out := &in.Level, &out.Level
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedCompression.
+func (in *SeedCompression) DeepCopy() *SeedCompression {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedCompression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedEtcd) DeepCopyInto(out *SeedEtcd) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedEtcd.
+func (in *SeedEtcd) DeepCopy() *SeedEtcd {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedEtcd)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedExclusions) DeepCopyInto(out *SeedExclusions) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretNamePatterns != nil {
+		in, out := &in.SecretNamePatterns, &out.SecretNamePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedExclusions.
+func (in *SeedExclusions) DeepCopy() *SeedExclusions {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedExclusions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SeedGenerator) DeepCopyInto(out *SeedGenerator) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -87,6 +168,26 @@ func (in *SeedGeneratorList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SeedGeneratorSpec) DeepCopyInto(out *SeedGeneratorSpec) {
 	*out = *in
+	if in.Exclusions != nil {
+		in, out := &in.Exclusions, &out.Exclusions
+		*out = new(SeedExclusions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Compression != nil {
+		in, out := &in.Compression, &out.Compression
+		*out = new(SeedCompression)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Etcd != nil {
+		in, out := &in.Etcd, &out.Etcd
+		*out = new(SeedEtcd)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(SeedImageRetention)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedGeneratorSpec.
@@ -111,6 +212,17 @@ func (in *SeedGeneratorStatus) DeepCopyInto(out *SeedGeneratorStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.GeneratedImages != nil {
+		in, out := &in.GeneratedImages, &out.GeneratedImages
+		*out = make([]GeneratedSeedImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedGeneratorStatus.
@@ -122,3 +234,18 @@ func (in *SeedGeneratorStatus) DeepCopy() *SeedGeneratorStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedImageRetention) DeepCopyInto(out *SeedImageRetention) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedImageRetention.
+func (in *SeedImageRetention) DeepCopy() *SeedImageRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedImageRetention)
+	in.DeepCopyInto(out)
+	return out
+}
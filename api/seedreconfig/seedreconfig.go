@@ -164,10 +164,103 @@ type SeedReconfiguration struct {
 	// In IBI case data will be taken from the user provided configuration.
 	ChronyConfig string `json:"chrony_config,omitempty"`
 
+	// NTPServers is the list of the NTP servers the node synchronizes its
+	// clock with, replacing the server and pool entries of ChronyConfig. The
+	// time sources of a seed generated in a lab are frequently not reachable
+	// from the site, which breaks the validation of the certificates once the
+	// clock drifts. If ChronyConfig is empty, the servers are set in the
+	// default chrony configuration of RHCOS. In IBI case data will be taken
+	// from the user provided configuration.
+	// +optional
+	NTPServers []string `json:"ntp_servers,omitempty"`
+
+	// Timezone is the IANA time zone of the node, e.g. America/New_York, set
+	// as the system timezone. If empty, the timezone of the seed is kept. In
+	// IBU case data will be taken from the /etc/localtime link of the upgraded
+	// cluster node. In IBI case data will be taken from the user provided
+	// configuration.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
 	AdditionalTrustBundle AdditionalTrustBundle `json:"additionalTrustBundle,omitempty"`
 
+	// AdditionalCABundles is the list of the extra PEM CA bundles trusted by
+	// the cluster, e.g. the CA of a TLS-intercepting proxy of the site. The
+	// CA bundles are added to the host trust store before recert runs, so
+	// that the images can be pulled right after the pivot, and to the trustedCA
+	// configmap of the cluster Proxy CR once the cluster is up. In IBU case
+	// data will be taken from the configmaps referenced by the IBU
+	// spec.additionalTrustedCABundles. In IBI case data will be taken from the
+	// user provided configuration.
+	// +optional
+	AdditionalCABundles []PEM `json:"additional_ca_bundles,omitempty"`
+
 	// The desired node labels for the SNO node.
 	NodeLabels map[string]string `json:"node_labels,omitempty"`
+
+	// The annotations set on the SNO node once the cluster is up. In IBU case
+	// data will be taken from the node of the upgraded cluster, per the IBU
+	// spec.nodeMetadata.
+	NodeAnnotations map[string]string `json:"node_annotations,omitempty"`
+
+	// The taints added to the SNO node once the cluster is up. In IBU case
+	// data will be taken from the node of the upgraded cluster, per the IBU
+	// spec.nodeMetadata.
+	NodeTaints []NodeTaint `json:"node_taints,omitempty"`
+
+	// ClusterNetworks is the list of the cluster network CIDRs of the cluster.
+	// Equivalent to install-config.yaml's clusterNetwork. The cluster network
+	// of the seed cannot be changed, so this is only used when a single-stack
+	// seed is converted to dual-stack, i.e. when NodeIPs includes an IP of a
+	// second family, or when an IPv4 or dual-stack seed is converted to
+	// IPv6-only, i.e. when NodeIPs only includes an IPv6 IP. The entries of the
+	// added family are then added, or replace the ones of the seed in case of an
+	// IPv6-only conversion, in the cluster network configuration once the
+	// cluster is up. In IBU case data will be taken from the upgraded cluster
+	// Network CR.
+	// +optional
+	ClusterNetworks []ClusterNetworkEntry `json:"cluster_networks,omitempty"`
+
+	// ServiceNetworks is the list of the service network CIDRs of the cluster.
+	// Equivalent to install-config.yaml's serviceNetwork. Like ClusterNetworks,
+	// this is only used when a single-stack seed is converted to dual-stack or
+	// a seed is converted to IPv6-only.
+	// +optional
+	ServiceNetworks []string `json:"service_networks,omitempty"`
+
+	// WorkerNodes is the list of the names of the additional worker nodes
+	// attached to the SNO. The worker nodes are not reconfigured from the seed,
+	// they are rolled out by the Machine Config Operator once the upgrade of the
+	// control plane node has completed, and the upgrade waits for them to rejoin
+	// the cluster. In IBU case data will be taken from the upgraded cluster
+	// nodes.
+	// +optional
+	WorkerNodes []string `json:"worker_nodes,omitempty"`
+
+	// CertificateRegeneration defines the parameters of the regeneration of
+	// the cluster certificates by recert. If empty, the certificates are
+	// regenerated with the signers of KubeconfigCryptoRetention and the
+	// expiration of the seed certificates extended. In IBU case data will be
+	// taken from the IBU spec.certificateRegeneration, the referenced secrets
+	// being read from the upgraded cluster. In IBI case data will be taken
+	// from the user provided configuration.
+	// +optional
+	CertificateRegeneration CertificateRegeneration `json:"certificate_regeneration,omitempty"`
+}
+
+// ClusterNetworkEntry defines a cluster network CIDR and the size of the subnet allocated to the node.
+type NodeTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+type ClusterNetworkEntry struct {
+	CIDR string `json:"cidr"`
+
+	// HostPrefix is the prefix length of the subnet allocated to the node. If
+	// not set, it defaults to 23 for IPv4 and 64 for IPv6.
+	HostPrefix uint32 `json:"host_prefix,omitempty"`
 }
 
 type KubeConfigCryptoRetention struct {
@@ -202,6 +295,35 @@ type IngresssCrypto struct {
 	IngressCertificateCN string `json:"ingress_certificate_cn,omitempty"`
 }
 
+type CertificateRegeneration struct {
+	// ValidityDays is the number of days the regenerated certificates are
+	// valid for, counted from the recert run. If 0, the expiration of the seed
+	// certificates is extended instead.
+	ValidityDays int `json:"validity_days,omitempty"`
+
+	// CustomCAs are the CAs replacing the seed signers of the same common
+	// name. The certificates signed by a seed signer are regenerated and
+	// signed by the custom CA replacing it.
+	CustomCAs []CustomCA `json:"custom_cas,omitempty"`
+
+	// PreservedCertificates are the certificates used as is by recert instead
+	// of being regenerated, matched by their subject common name.
+	PreservedCertificates []CertificateKeyPair `json:"preserved_certificates,omitempty"`
+}
+
+type CustomCA struct {
+	// SignerCN is the common name of the seed signer replaced by the CA, e.g.
+	// kube-apiserver-lb-signer.
+	SignerCN string `json:"signer_cn"`
+
+	CertificateKeyPair
+}
+
+type CertificateKeyPair struct {
+	Certificate PEM `json:"certificate"`
+	PrivateKey  PEM `json:"private_key"`
+}
+
 // Proxy defines the proxy settings for the cluster.
 // At least one of HTTPProxy or HTTPSProxy is required.
 // Aims to be the same as https://github.com/openshift/installer/blob/ad59622147974f2d2d62bcdeaf342ae4f87ed84f/pkg/types/installconfig.go#L454-L468
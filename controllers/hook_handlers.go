@@ -0,0 +1,265 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/hooks"
+)
+
+// stageHooks returns the spec.hooks of the stage triggered at when, in the listed order
+func stageHooks(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage, when ibuv1.HookTrigger) []ibuv1.StageHook {
+	var matching []ibuv1.StageHook
+	for _, hook := range ibu.Spec.Hooks {
+		if hook.Stage == stage && hook.When == when {
+			matching = append(matching, hook)
+		}
+	}
+	return matching
+}
+
+func getHookStatus(ibu *ibuv1.ImageBasedUpgrade, name string) *ibuv1.HookStatus {
+	for i := range ibu.Status.Hooks {
+		if ibu.Status.Hooks[i].Name == name {
+			return &ibu.Status.Hooks[i]
+		}
+	}
+	return nil
+}
+
+// afterHooksStarted returns true if an After hook of the stage is recorded, meaning the work of the stage is done
+func afterHooksStarted(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage) bool {
+	for _, hook := range stageHooks(ibu, stage, ibuv1.HookAfter) {
+		if getHookStatus(ibu, hook.Name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// runStageHooks runs the hooks of the stage triggered at when, one at a time. It returns true once all of them are
+// done, along with an error if a hook with the Abort failure policy failed or timed out.
+func (r *ImageBasedUpgradeReconciler) runStageHooks(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade,
+	stage ibuv1.ImageBasedUpgradeStage, when ibuv1.HookTrigger) (bool, error) {
+	for _, hook := range stageHooks(ibu, stage, when) {
+		status := getHookStatus(ibu, hook.Name)
+		if status == nil {
+			// The hook is recorded before its Job is created, so that a failure to create it is retried
+			ibu.Status.Hooks = append(ibu.Status.Hooks, ibuv1.HookStatus{
+				Name:  hook.Name,
+				Stage: hook.Stage,
+				When:  hook.When,
+				Phase: hooks.PhasePending,
+			})
+			status = &ibu.Status.Hooks[len(ibu.Status.Hooks)-1]
+		}
+
+		switch status.Phase {
+		case hooks.PhasePending:
+			// The outcome of a hook failing to start is handled on the next reconcile
+			return false, r.startHook(ctx, ibu, hook, status)
+		case hooks.PhaseRunning:
+			if err := r.checkHook(ctx, hook, status); err != nil {
+				return false, err
+			}
+			if !hooks.IsTerminal(status.Phase) {
+				return false, nil
+			}
+			r.reportHookOutcome(ibu, hook, status)
+		}
+
+		if status.Phase != hooks.PhaseSucceeded && hook.FailurePolicy != ibuv1.HookFailurePolicyContinue {
+			return true, fmt.Errorf("%s hook %s of the %s stage %s: %s", hook.When, hook.Name, stage, status.Phase, status.Message)
+		}
+	}
+	return true, nil
+}
+
+// startHook creates the Job of the hook, once any Job left by a previous upgrade is deleted. The hook is set to Failed
+// if its Job cannot be rendered.
+func (r *ImageBasedUpgradeReconciler) startHook(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, hook ibuv1.StageHook,
+	status *ibuv1.HookStatus) error {
+	cm, err := common.GetConfigMap(ctx, r.Client, hook.ConfigMapRef)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get the configMap of hook %s: %w", hook.Name, err)
+	}
+	var job *batchv1.Job
+	if err == nil {
+		job, err = hooks.BuildJob(hook, cm)
+	}
+	if err != nil {
+		now := metav1.Now()
+		status.Phase = hooks.PhaseFailed
+		status.Message = err.Error()
+		status.StartedAt = now
+		status.CompletedAt = &now
+		r.reportHookOutcome(ibu, hook, status)
+		return nil
+	}
+
+	// The Jobs are only cached in the LCA namespace, while the hook Jobs run in the namespace of their configMap
+	existing := &batchv1.Job{}
+	if err := r.NoncachedClient.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, existing); err == nil {
+		r.Log.Info("Deleting the Job of a previous run of the hook", "hook", hook.Name, "job", job.Name)
+		if err := r.Client.Delete(ctx, existing, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete the previous Job of hook %s: %w", hook.Name, err)
+		}
+		return nil
+	} else if !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get the Job of hook %s: %w", hook.Name, err)
+	}
+
+	r.Log.Info("Starting hook", "hook", hook.Name, "stage", hook.Stage, "when", hook.When, "job", job.Name, "namespace", job.Namespace)
+	if err := r.Client.Create(ctx, job); err != nil {
+		return fmt.Errorf("failed to create the Job of hook %s: %w", hook.Name, err)
+	}
+	status.Phase = hooks.PhaseRunning
+	status.StartedAt = metav1.Now()
+	return nil
+}
+
+// checkHook updates the status of a running hook from its Job, timing it out once past its timeout
+func (r *ImageBasedUpgradeReconciler) checkHook(ctx context.Context, hook ibuv1.StageHook, status *ibuv1.HookStatus) error {
+	job := &batchv1.Job{}
+	if err := r.NoncachedClient.Get(ctx, types.NamespacedName{Name: hooks.JobName(hook), Namespace: hook.ConfigMapRef.Namespace}, job); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the Job of hook %s: %w", hook.Name, err)
+		}
+		status.Phase, status.Message = hooks.PhaseFailed, "the Job of the hook was deleted"
+	} else {
+		status.Phase, status.Message = hooks.JobPhase(job)
+	}
+
+	if status.Phase == hooks.PhaseRunning && time.Since(status.StartedAt.Time) >= time.Duration(hooks.Timeout(hook))*time.Second {
+		status.Phase = hooks.PhaseTimedOut
+		if err := r.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8serrors.IsNotFound(err) {
+			r.Log.Error(err, "failed to delete the Job of the timed out hook", "hook", hook.Name)
+		}
+	}
+	if status.Phase == hooks.PhaseTimedOut {
+		status.Message = fmt.Sprintf("the Job of the hook did not complete within %ds", hooks.Timeout(hook))
+	}
+	if hooks.IsTerminal(status.Phase) {
+		status.CompletedAt = &metav1.Time{Time: time.Now()}
+	}
+	return nil
+}
+
+func (r *ImageBasedUpgradeReconciler) reportHookOutcome(ibu *ibuv1.ImageBasedUpgrade, hook ibuv1.StageHook, status *ibuv1.HookStatus) {
+	if status.Phase == hooks.PhaseSucceeded {
+		r.Log.Info("Hook succeeded", "hook", hook.Name)
+		return
+	}
+	r.Log.Info("Hook did not succeed", "hook", hook.Name, "phase", status.Phase, "message", status.Message,
+		"failurePolicy", hook.FailurePolicy)
+	utils.EmitEvent(r.Recorder, ibu, corev1.EventTypeWarning, utils.EventReasonHookFailed,
+		fmt.Sprintf("%s hook %s of the %s stage %s: %s", hook.When, hook.Name, hook.Stage, status.Phase, status.Message))
+}
+
+// handleBeforeHooks runs the Before hooks of the stage, returning true once they are done and the work of the stage
+// can start
+func (r *ImageBasedUpgradeReconciler) handleBeforeHooks(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade,
+	stage ibuv1.ImageBasedUpgradeStage) (bool, ctrl.Result, error) {
+	done, err := r.runStageHooks(ctx, ibu, stage, ibuv1.HookBefore)
+	if done && err != nil {
+		setStageFailed(ibu, stage, err.Error())
+		return false, doNotRequeue(), nil
+	}
+	if err != nil {
+		nextReconcile, err := requeueWithError(err)
+		return false, nextReconcile, err
+	}
+	if !done {
+		setStageInProgress(ibu, stage, "Running the hooks before the stage")
+		return false, requeueWithShortInterval(), nil
+	}
+	return true, doNotRequeue(), nil
+}
+
+// handleAfterHooks runs the After hooks of the stage once its work is done, holding the completion of the stage until
+// they are done
+func (r *ImageBasedUpgradeReconciler) handleAfterHooks(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade,
+	stage ibuv1.ImageBasedUpgradeStage) (ctrl.Result, error) {
+	done, err := r.runStageHooks(ctx, ibu, stage, ibuv1.HookAfter)
+	if done && err != nil {
+		setStageFailed(ibu, stage, err.Error())
+		return doNotRequeue(), nil
+	}
+	if err != nil || !done {
+		// The stage is only completed once the After hooks are done
+		meta.RemoveStatusCondition(&ibu.Status.Conditions, string(utils.GetCompletedConditionType(stage)))
+		setStageInProgress(ibu, stage, "Running the hooks after the stage")
+		if err != nil {
+			return requeueWithError(err)
+		}
+		return requeueWithShortInterval(), nil
+	}
+	if !utils.IsStageCompleted(ibu, stage) {
+		r.completeStage(ibu, stage)
+	}
+	return doNotRequeue(), nil
+}
+
+func (r *ImageBasedUpgradeReconciler) completeStage(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage) {
+	switch stage {
+	case ibuv1.Stages.Prep:
+		_, _ = prepSuccessDoNotRequeue(r.Log, ibu)
+	case ibuv1.Stages.Upgrade:
+		utils.SetUpgradeStatusCompleted(ibu)
+		utils.SetStageProgress(ibu, "Upgrade completed", 100)
+	case ibuv1.Stages.Rollback:
+		utils.SetRollbackStatusCompleted(ibu)
+		utils.SetStageProgress(ibu, "Rollback completed", 100)
+	}
+}
+
+func setStageInProgress(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage, msg string) {
+	switch stage {
+	case ibuv1.Stages.Prep:
+		utils.SetPrepStatusInProgress(ibu, msg)
+	case ibuv1.Stages.Upgrade:
+		utils.SetUpgradeStatusInProgress(ibu, msg)
+	case ibuv1.Stages.Rollback:
+		utils.SetRollbackStatusInProgress(ibu, msg)
+	}
+}
+
+func setStageFailed(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage, msg string) {
+	switch stage {
+	case ibuv1.Stages.Prep:
+		utils.SetPrepStatusFailed(ibu, msg)
+	case ibuv1.Stages.Upgrade:
+		utils.SetUpgradeStatusFailed(ibu, msg)
+	case ibuv1.Stages.Rollback:
+		utils.SetRollbackStatusFailed(ibu, msg)
+	}
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/hooks"
+)
+
+func hookTestIBU(hookList ...ibuv1.StageHook) *ibuv1.ImageBasedUpgrade {
+	return &ibuv1.ImageBasedUpgrade{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.IBUName},
+		Spec:       ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep, Hooks: hookList},
+	}
+}
+
+func hookTestJobStatus(t *testing.T, r *ImageBasedUpgradeReconciler, hook ibuv1.StageHook, condType batchv1.JobConditionType, reason string) {
+	job := &batchv1.Job{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: hooks.JobName(hook), Namespace: hook.ConfigMapRef.Namespace}, job))
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{Type: condType, Status: corev1.ConditionTrue, Reason: reason})
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), job))
+}
+
+func TestRunStageHooks(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain-app", Namespace: "app"},
+		Data:       map[string]string{hooks.ScriptKey: "oc scale deploy/app --replicas=0", hooks.ImageKey: "quay.io/cli:latest"},
+	}
+	before := ibuv1.StageHook{
+		Name: "drain", Stage: ibuv1.Stages.Prep, When: ibuv1.HookBefore,
+		ConfigMapRef: ibuv1.ConfigMapRef{Name: "drain-app", Namespace: "app"}, TimeoutSeconds: 60,
+		FailurePolicy: ibuv1.HookFailurePolicyAbort,
+	}
+	missing := ibuv1.StageHook{
+		Name: "notify", Stage: ibuv1.Stages.Prep, When: ibuv1.HookBefore,
+		ConfigMapRef: ibuv1.ConfigMapRef{Name: "missing", Namespace: "app"}, TimeoutSeconds: 60,
+		FailurePolicy: ibuv1.HookFailurePolicyContinue,
+	}
+	after := ibuv1.StageHook{
+		Name: "resume", Stage: ibuv1.Stages.Prep, When: ibuv1.HookAfter,
+		ConfigMapRef: ibuv1.ConfigMapRef{Name: "drain-app", Namespace: "app"}, TimeoutSeconds: 60,
+		FailurePolicy: ibuv1.HookFailurePolicyAbort,
+	}
+
+	t.Run("hooks run in order, and a failure with the Continue policy is ignored", func(t *testing.T) {
+		c, _ := getFakeClientFromObjects(cm)
+		r := &ImageBasedUpgradeReconciler{Client: c, NoncachedClient: c, Log: logr.Discard(), Recorder: record.NewFakeRecorder(10)}
+		ibu := hookTestIBU(missing, before, after)
+
+		// The ConfigMap of the first hook does not exist
+		done, err := r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.NoError(t, err)
+		assert.False(t, done)
+		assert.Equal(t, hooks.PhaseFailed, ibu.Status.Hooks[0].Phase)
+
+		// The second hook is started
+		done, err = r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.NoError(t, err)
+		assert.False(t, done)
+		assert.Len(t, ibu.Status.Hooks, 2)
+		assert.Equal(t, hooks.PhaseRunning, ibu.Status.Hooks[1].Phase)
+		assert.False(t, ibu.Status.Hooks[1].StartedAt.IsZero())
+
+		done, err = r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.NoError(t, err)
+		assert.False(t, done)
+
+		hookTestJobStatus(t, r, before, batchv1.JobComplete, "")
+		done, err = r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.NoError(t, err)
+		assert.True(t, done)
+		assert.Equal(t, hooks.PhaseSucceeded, ibu.Status.Hooks[1].Phase)
+		assert.NotNil(t, ibu.Status.Hooks[1].CompletedAt)
+		// The After hook is left alone
+		assert.Len(t, ibu.Status.Hooks, 2)
+	})
+
+	t.Run("a failure with the Abort policy fails the stage", func(t *testing.T) {
+		c, _ := getFakeClientFromObjects(cm)
+		r := &ImageBasedUpgradeReconciler{Client: c, NoncachedClient: c, Log: logr.Discard(), Recorder: record.NewFakeRecorder(10)}
+		ibu := hookTestIBU(before)
+
+		proceed, _, err := r.handleBeforeHooks(context.TODO(), ibu, ibuv1.Stages.Prep)
+		assert.NoError(t, err)
+		assert.False(t, proceed)
+		assert.True(t, utils.IsStageInProgress(ibu, ibuv1.Stages.Prep))
+
+		hookTestJobStatus(t, r, before, batchv1.JobFailed, "BackoffLimitExceeded")
+		proceed, _, err = r.handleBeforeHooks(context.TODO(), ibu, ibuv1.Stages.Prep)
+		assert.NoError(t, err)
+		assert.False(t, proceed)
+		assert.True(t, utils.IsStageFailed(ibu, ibuv1.Stages.Prep))
+		assert.Equal(t, hooks.PhaseFailed, ibu.Status.Hooks[0].Phase)
+	})
+
+	t.Run("a hook running past its timeout is timed out and its Job deleted", func(t *testing.T) {
+		c, _ := getFakeClientFromObjects(cm)
+		r := &ImageBasedUpgradeReconciler{Client: c, NoncachedClient: c, Log: logr.Discard(), Recorder: record.NewFakeRecorder(10)}
+		ibu := hookTestIBU(before)
+
+		done, err := r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.NoError(t, err)
+		assert.False(t, done)
+		ibu.Status.Hooks[0].StartedAt = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+
+		done, err = r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.ErrorContains(t, err, "TimedOut")
+		assert.True(t, done)
+		assert.Equal(t, hooks.PhaseTimedOut, ibu.Status.Hooks[0].Phase)
+		err = c.Get(context.TODO(), types.NamespacedName{Name: hooks.JobName(before), Namespace: "app"}, &batchv1.Job{})
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+
+	t.Run("the Job left by a previous upgrade is deleted before the hook starts", func(t *testing.T) {
+		stale := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: hooks.JobName(before), Namespace: "app"}}
+		c, _ := getFakeClientFromObjects(cm, stale)
+		r := &ImageBasedUpgradeReconciler{Client: c, NoncachedClient: c, Log: logr.Discard(), Recorder: record.NewFakeRecorder(10)}
+		ibu := hookTestIBU(before)
+
+		_, err := r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.NoError(t, err)
+		assert.Equal(t, hooks.PhasePending, ibu.Status.Hooks[0].Phase)
+		_, err = r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+		assert.NoError(t, err)
+		assert.Equal(t, hooks.PhaseRunning, ibu.Status.Hooks[0].Phase)
+	})
+
+	t.Run("the stage is only completed once the After hooks are done", func(t *testing.T) {
+		c, _ := getFakeClientFromObjects(cm)
+		r := &ImageBasedUpgradeReconciler{Client: c, NoncachedClient: c, Log: logr.Discard(), Recorder: record.NewFakeRecorder(10)}
+		ibu := hookTestIBU(after)
+		utils.SetPrepStatusCompleted(ibu, "Prep stage completed successfully")
+
+		_, err := r.handleAfterHooks(context.TODO(), ibu, ibuv1.Stages.Prep)
+		assert.NoError(t, err)
+		assert.False(t, utils.IsStageCompleted(ibu, ibuv1.Stages.Prep))
+		assert.True(t, utils.IsStageInProgress(ibu, ibuv1.Stages.Prep))
+		assert.True(t, afterHooksStarted(ibu, ibuv1.Stages.Prep))
+
+		hookTestJobStatus(t, r, after, batchv1.JobComplete, "")
+		_, err = r.handleAfterHooks(context.TODO(), ibu, ibuv1.Stages.Prep)
+		assert.NoError(t, err)
+		assert.True(t, utils.IsStageCompleted(ibu, ibuv1.Stages.Prep))
+		assert.False(t, utils.IsStageInProgress(ibu, ibuv1.Stages.Prep))
+	})
+}
+
+// managerCachedClient reads the Jobs through a cache built with the cache options of the manager, as the client of the
+// manager does. The other objects are read, and all of them written, through the fake client.
+type managerCachedClient struct {
+	client.Client
+	jobCache cache.Cache
+}
+
+func (c *managerCachedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*batchv1.Job); ok {
+		//nolint:wrapcheck
+		return c.jobCache.Get(ctx, key, obj, opts...)
+	}
+	//nolint:wrapcheck
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func TestRunStageHooksWithManagerCache(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain-app", Namespace: "app"},
+		Data:       map[string]string{hooks.ScriptKey: "oc scale deploy/app --replicas=0", hooks.ImageKey: "quay.io/cli:latest"},
+	}
+	hook := ibuv1.StageHook{
+		Name: "drain", Stage: ibuv1.Stages.Prep, When: ibuv1.HookBefore,
+		ConfigMapRef: ibuv1.ConfigMapRef{Name: "drain-app", Namespace: "app"}, TimeoutSeconds: 60,
+		FailurePolicy: ibuv1.HookFailurePolicyAbort,
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(batchv1.SchemeGroupVersion.WithKind("Job"), meta.RESTScopeNamespace)
+	opts := utils.ManagerCacheOptions()
+	opts.Scheme, opts.Mapper = testscheme, mapper
+	// The cache is never started, the Jobs of the namespaces it is not restricted to being rejected at once
+	jobCache, err := cache.New(&rest.Config{Host: "https://127.0.0.1:1"}, opts)
+	assert.NoError(t, err)
+
+	c, _ := getFakeClientFromObjects(cm)
+	r := &ImageBasedUpgradeReconciler{
+		Client: &managerCachedClient{Client: c, jobCache: jobCache}, NoncachedClient: c,
+		Log: logr.Discard(), Recorder: record.NewFakeRecorder(10),
+	}
+	ibu := hookTestIBU(hook)
+
+	// The hook Jobs are not cached
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: hooks.JobName(hook), Namespace: "app"}, &batchv1.Job{})
+	assert.ErrorContains(t, err, "unknown namespace for the cache")
+
+	done, err := r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, hooks.PhaseRunning, ibu.Status.Hooks[0].Phase)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: hooks.JobName(hook), Namespace: "app"}, job))
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue})
+	assert.NoError(t, c.Status().Update(context.TODO(), job))
+	done, err = r.runStageHooks(context.TODO(), ibu, ibuv1.Stages.Prep, ibuv1.HookBefore)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, hooks.PhaseSucceeded, ibu.Status.Hooks[0].Phase)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -27,9 +28,13 @@ import (
 
 	"github.com/samber/lo"
 
+	"github.com/openshift-kni/lifecycle-agent/internal/audit"
 	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/checkpoint"
+	"github.com/openshift-kni/lifecycle-agent/internal/diagnostics"
 	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
 	"github.com/openshift-kni/lifecycle-agent/internal/imagemgmt"
+	"github.com/openshift-kni/lifecycle-agent/internal/notifier"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
 	kbatch "k8s.io/api/batch/v1"
 
@@ -39,6 +44,7 @@ import (
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/internal/precache"
+	"github.com/openshift-kni/lifecycle-agent/internal/progress"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
 
@@ -76,6 +82,11 @@ type ImageBasedUpgradeReconciler struct {
 	OstreeClient    ostreeclient.IClient
 	Ops             ops.Ops
 	RebootClient    reboot.RebootIntf
+	Progress        *progress.Recorder
+	Audit           *audit.Log
+	Notifier        *notifier.Notifier
+	Checkpoints     *checkpoint.Store
+	Diagnostics     *diagnostics.Collector
 	Mux             *sync.Mutex
 	Clientset       *kubernetes.Clientset
 
@@ -84,6 +95,9 @@ type ImageBasedUpgradeReconciler struct {
 
 	// Cluster data retrieved once, during init
 	ContainerStorageMountpointTarget string
+
+	// The last failed inspection of the seed image while Idle
+	seedImageInspectFailure *seedImageInspectFailure
 }
 
 func doNotRequeue() ctrl.Result {
@@ -101,20 +115,20 @@ func requeueImmediately() ctrl.Result {
 }
 
 func requeueWithShortInterval() ctrl.Result {
-	return requeueWithCustomInterval(30 * time.Second)
+	return requeueWithCustomInterval(tuning.ShortInterval)
 }
 
 func requeueWithMediumInterval() ctrl.Result {
-	return requeueWithCustomInterval(1 * time.Minute)
+	return requeueWithCustomInterval(tuning.MediumInterval)
 }
 
 //nolint:unused
 func requeueWithLongInterval() ctrl.Result {
-	return requeueWithCustomInterval(5 * time.Minute)
+	return requeueWithCustomInterval(tuning.LongInterval)
 }
 
 func requeueWithHealthCheckInterval() ctrl.Result {
-	return requeueWithCustomInterval(20 * time.Second)
+	return requeueWithCustomInterval(tuning.HealthCheckInterval)
 }
 
 func requeueWithCustomInterval(interval time.Duration) ctrl.Result {
@@ -126,6 +140,7 @@ func requeueWithCustomInterval(interval time.Duration) ctrl.Result {
 //+kubebuilder:rbac:groups=lca.openshift.io,resources=imagebasedupgrades/finalizers,verbs=update
 //+kubebuilder:rbac:groups=lca.openshift.io,resources=ipconfigs,verbs=get;list;watch
 //+kubebuilder:rbac:groups=lca.openshift.io,resources=ipconfigs/status,verbs=get
+//+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigpools,verbs=get;list;watch;patch
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
@@ -145,6 +160,9 @@ func (r *ImageBasedUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	r.Log.Info("Start reconciling IBU", "name", req.NamespacedName)
+	// Report a long-running reconcile as busy, rather than hung, to the health endpoints
+	r.Progress.StartReconcile()
+	defer r.Progress.EndReconcile()
 	defer func() {
 		if nextReconcile.RequeueAfter > 0 {
 			r.Log.Info("Finish reconciling IBU", "name", req.NamespacedName, "requeueAfter", nextReconcile.RequeueAfter.Seconds())
@@ -177,7 +195,45 @@ func (r *ImageBasedUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	r.Log.Info("Loaded IBU", "name", req.NamespacedName, "version", ibu.GetResourceVersion(), "desired stage", ibu.Spec.Stage)
 
+	// Emit the events for the condition changes made by this reconcile
+	conditionsBefore := slices.Clone(ibu.Status.Conditions)
+	defer func() {
+		utils.EmitConditionEvents(r.Recorder, ibu, conditionsBefore, ibu.Status.Conditions)
+	}()
+
+	// Keep the local progress API up to date, including across the API server unavailability
+	defer r.Progress.Record(ibu)
+
+	// Keep the audit trail of the stage transitions
+	if r.Audit != nil {
+		ibu.Status.AuditLogPath = common.AuditLogFile
+	}
+	defer func() {
+		if auditErr := r.Audit.RecordConditionChanges(string(ibu.Spec.Stage), conditionsBefore, ibu.Status.Conditions); auditErr != nil {
+			r.Log.Error(auditErr, "failed to record the stage transitions in the audit log")
+		}
+	}()
+
+	// Publish the stage transitions to the external sink, if configured, once the status is updated with them
+	conditionsUpdated := conditionsBefore
+	updateStatus := func() error {
+		updateErr := utils.UpdateIBUStatus(ctx, r.Client, ibu)
+		if updateErr == nil {
+			conditionsUpdated = slices.Clone(ibu.Status.Conditions)
+		}
+		return updateErr
+	}
+	defer func() {
+		if notifyErr := r.Notifier.NotifyConditionChanges(ctx, ibu, conditionsBefore, conditionsUpdated); notifyErr != nil {
+			r.Log.Error(notifyErr, "failed to publish the stage events")
+		}
+	}()
+
 	nextReconcile, err = r.gateIBUByIPConfig(ctx, ibu)
+	if err == nil {
+		// The gating updates the status whenever it changes the conditions
+		conditionsUpdated = slices.Clone(ibu.Status.Conditions)
+	}
 	if err != nil || nextReconcile.RequeueAfter > 0 {
 		return
 	}
@@ -191,12 +247,21 @@ func (r *ImageBasedUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	// Make sure "next stage" list is initialized
 	ibu.Status.ValidNextStages = getValidNextStageList(ibu, isAfterPivot)
 
+	// Hold the transitions into the Upgrade and Rollback stages until the maintenance window opens
+	var windowInterval time.Duration
+	utils.ClearWaitingForWindow(ibu)
 	if isTransitionRequested(ibu) {
-		if validateStageTransition(ibu, isAfterPivot) {
+		var held bool
+		if held, windowInterval = holdForMaintenanceWindow(ibu, isAfterPivot, time.Now()); held {
+			if err = updateStatus(); err != nil {
+				r.Log.Error(err, "failed to update IBU CR status")
+				return
+			}
+		} else if validateStageTransition(ibu, isAfterPivot) {
 			// The transition has occurred, regenerate the list of valid next stages
 			ibu.Status.ValidNextStages = getValidNextStageList(ibu, isAfterPivot)
 			// Update status
-			if err = utils.UpdateIBUStatus(ctx, r.Client, ibu); err != nil {
+			if err = updateStatus(); err != nil {
 				r.Log.Error(err, "failed to update IBU CR status")
 				return
 			}
@@ -206,10 +271,11 @@ func (r *ImageBasedUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	inProgressStage := utils.GetInProgressStage(ibu)
 	if inProgressStage != "" {
 		nextReconcile, err = r.handleStage(ctx, ibu, inProgressStage)
+		r.collectDiagnosticsOnFailure(ctx, ibu, conditionsBefore)
 		if err != nil {
 			ibu.Status.ValidNextStages = getValidNextStageList(ibu, isAfterPivot)
 			// Note: the status update error must have a different var name other than err
-			if updateErr := utils.UpdateIBUStatus(ctx, r.Client, ibu); updateErr != nil {
+			if updateErr := updateStatus(); updateErr != nil {
 				r.Log.Error(err, "failed to update IBU CR status")
 			}
 			return
@@ -217,9 +283,20 @@ func (r *ImageBasedUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	ibu.Status.ValidNextStages = getValidNextStageList(ibu, isAfterPivot)
+	r.updateRollbackAvailableCondition(ibu, isAfterPivot)
+	r.updateAutoRollbackDeadline(ibu, isAfterPivot)
+	if interval := requeueForRollbackAvailability(ibu, time.Now()); interval > 0 && nextReconcile.RequeueAfter == 0 {
+		nextReconcile = requeueWithCustomInterval(interval)
+	}
+	if interval := r.updateProgressingCondition(ibu); interval > 0 && nextReconcile.RequeueAfter == 0 {
+		nextReconcile = requeueWithCustomInterval(interval)
+	}
+	if windowInterval > 0 && (nextReconcile.RequeueAfter == 0 || windowInterval < nextReconcile.RequeueAfter) {
+		nextReconcile = requeueWithCustomInterval(windowInterval)
+	}
 
 	// Update status
-	if err = utils.UpdateIBUStatus(ctx, r.Client, ibu); err != nil {
+	if err = updateStatus(); err != nil {
 		r.Log.Error(err, "failed to update IBU CR status")
 	}
 
@@ -282,6 +359,21 @@ func (r *ImageBasedUpgradeReconciler) gateIBUByIPConfig(
 	return requeueWithShortInterval(), nil
 }
 
+// holdForMaintenanceWindow returns true when the requested transition into the Upgrade or Rollback stage is held, as
+// the maintenance window is closed, along with the interval after which the window opens, or 0 if it never opens
+// again. An invalid transition is not held, for it to be reported as such.
+func holdForMaintenanceWindow(ibu *ibuv1.ImageBasedUpgrade, isAfterPivot bool, now time.Time) (bool, time.Duration) {
+	if ibu.Spec.MaintenanceWindow == nil ||
+		(ibu.Spec.Stage != ibuv1.Stages.Upgrade && ibu.Spec.Stage != ibuv1.Stages.Rollback) ||
+		!lo.Contains(getValidNextStageList(ibu, isAfterPivot), ibu.Spec.Stage) {
+		return false, 0
+	}
+	if open, _ := utils.MaintenanceWindowState(ibu.Spec.MaintenanceWindow, now); open {
+		return false, 0
+	}
+	return true, utils.SetWaitingForWindow(ibu, now)
+}
+
 func getValidNextStageList(ibu *ibuv1.ImageBasedUpgrade, isAfterPivot bool) []ibuv1.ImageBasedUpgradeStage {
 	inProgressStage := utils.GetInProgressStage(ibu)
 	if inProgressStage == ibuv1.Stages.Idle || inProgressStage == ibuv1.Stages.Rollback || utils.IsStageFailed(ibu, ibuv1.Stages.Rollback) {
@@ -306,8 +398,16 @@ func getValidNextStageList(ibu *ibuv1.ImageBasedUpgrade, isAfterPivot bool) []ib
 		return []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle}
 	}
 	if utils.IsStageCompleted(ibu, ibuv1.Stages.Upgrade) {
+		if isRollbackRetentionOpen(ibu, time.Now()) {
+			// the original stateroot is kept available for rollback until the retention window closes
+			return []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Rollback}
+		}
 		return []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle, ibuv1.Stages.Rollback}
 	}
+	if utils.IsPrepValidated(ibu) {
+		// a validate-only Prep never creates the stateroot, so there is nothing to upgrade to
+		return []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle}
+	}
 	if utils.IsStageCompleted(ibu, ibuv1.Stages.Prep) {
 		return []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle, ibuv1.Stages.Upgrade}
 	}
@@ -349,6 +449,20 @@ func (r *ImageBasedUpgradeReconciler) handleStage(ctx context.Context, ibu *ibuv
 	utils.StartStageHistory(r.Client, r.Log, ibu)
 	// .status.history is reset as long as the desired stage is Idle
 	utils.ResetHistory(r.Client, r.Log, ibu)
+	// .status.progress, .status.precache and .status.hooks are cleared as long as the desired stage is Idle
+	utils.ResetStageProgress(ibu)
+	defer r.updateCompletionEstimate(ibu)
+
+	if stage != ibuv1.Stages.Idle {
+		// The work of the stage is done once its After hooks are recorded
+		if afterHooksStarted(ibu, stage) {
+			return r.handleAfterHooks(ctx, ibu, stage)
+		}
+		var proceed bool
+		if proceed, nextReconcile, err = r.handleBeforeHooks(ctx, ibu, stage); !proceed {
+			return
+		}
+	}
 
 	switch stage {
 	case ibuv1.Stages.Idle:
@@ -360,12 +474,84 @@ func (r *ImageBasedUpgradeReconciler) handleStage(ctx context.Context, ibu *ibuv
 	case ibuv1.Stages.Rollback:
 		nextReconcile, err = r.handleRollback(ctx, ibu)
 	}
+
+	if err == nil && stage != ibuv1.Stages.Idle && utils.IsStageCompleted(ibu, stage) && len(stageHooks(ibu, stage, ibuv1.HookAfter)) > 0 {
+		nextReconcile, err = r.handleAfterHooks(ctx, ibu, stage)
+	}
 	return
 }
 
+// stageDurationsFile is a var in order to override it in unit tests
+var stageDurationsFile = common.PathOutsideChroot(utils.StageDurationsFilePath)
+
+// updateCompletionEstimate records the duration of the stage once completed, and estimates the completion of the stage
+// in progress. The estimate is best effort, so any error is only logged.
+func (r *ImageBasedUpgradeReconciler) updateCompletionEstimate(ibu *ibuv1.ImageBasedUpgrade) {
+	durations, err := utils.ReadStageDurations(stageDurationsFile)
+	if err != nil {
+		r.Log.Error(err, "failed to read the stage durations, no estimate is reported")
+		ibu.Status.Estimate = nil
+		return
+	}
+	if durations.Record(ibu) {
+		if err := utils.WriteStageDurations(stageDurationsFile, durations); err != nil {
+			r.Log.Error(err, "failed to record the stage duration")
+		}
+	}
+	utils.SetCompletionEstimate(ibu, durations)
+}
+
+// updateProgressingCondition reports whether the stage in progress is stalled, and returns the interval after which it
+// is. The stall detection is best effort, so a failure to read the stage durations falls back to the default durations.
+func (r *ImageBasedUpgradeReconciler) updateProgressingCondition(ibu *ibuv1.ImageBasedUpgrade) time.Duration {
+	durations, err := utils.ReadStageDurations(stageDurationsFile)
+	if err != nil {
+		r.Log.Error(err, "failed to read the stage durations, the default durations are expected")
+		durations = utils.StageDurations{}
+	}
+	return utils.SetProgressingCondition(ibu, durations, time.Now())
+}
+
+// collectDiagnosticsOnFailure collects the diagnostics bundle when the Upgrade or Rollback has just failed, a rollback
+// requested by the user not being a failure, and references it in the DiagnosticsCollected condition
+func (r *ImageBasedUpgradeReconciler) collectDiagnosticsOnFailure(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade,
+	conditionsBefore []metav1.Condition) {
+	if r.Diagnostics == nil {
+		return
+	}
+	for _, stage := range []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Upgrade, ibuv1.Stages.Rollback} {
+		conditionType := string(utils.GetCompletedConditionType(stage))
+		completed := meta.FindStatusCondition(ibu.Status.Conditions, conditionType)
+		if completed == nil || completed.Reason != string(utils.ConditionReasons.Failed) ||
+			completed.Message == utils.RollbackRequested {
+			continue
+		}
+		if before := meta.FindStatusCondition(conditionsBefore, conditionType); before != nil &&
+			before.Reason == string(utils.ConditionReasons.Failed) {
+			continue
+		}
+
+		r.Log.Info("Collecting the diagnostics bundle", "stage", stage)
+		path, err := r.Diagnostics.Collect(ctx, ibu, stage)
+		if err != nil {
+			r.Log.Error(err, "failed to collect the diagnostics bundle", "stage", stage)
+			utils.SetDiagnosticsCollectionFailed(ibu, stage, err.Error())
+			return
+		}
+		r.Log.Info("Collected the diagnostics bundle", "stage", stage, "path", path)
+		utils.SetDiagnosticsCollected(ibu, stage, path)
+		return
+	}
+}
+
 func (r *ImageBasedUpgradeReconciler) handleAbortOrFinalize(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (nextReconcile ctrl.Result, err error) {
 	idleCondition := meta.FindStatusCondition(ibu.Status.Conditions, string(utils.ConditionTypes.Idle))
-	if idleCondition == nil || idleCondition.Status == metav1.ConditionTrue {
+	if idleCondition == nil {
+		return
+	}
+	if idleCondition.Status == metav1.ConditionTrue {
+		// Report the seed image that is targeted, for it to be verified before the Prep stage starts
+		r.updateSeedImageInfo(ctx, ibu)
 		return
 	}
 	switch idleCondition.Reason {
@@ -451,6 +637,9 @@ func validateStageTransition(ibu *ibuv1.ImageBasedUpgrade, isAfterPivot bool) bo
 			msg := "Abort or finalize not allowed"
 			if utils.IsStageFailed(ibu, ibuv1.Stages.Rollback) {
 				msg = "Transition to Idle not allowed - Rollback failed"
+			} else if isRollbackRetentionOpen(ibu, time.Now()) {
+				msg = fmt.Sprintf("Finalize not allowed - the rollback retention window is open until %s",
+					rollbackRetentionEnd(ibu).UTC().Format(time.RFC3339))
 			} else if isAfterPivot {
 				if utils.IsStageInProgress(ibu, ibuv1.Stages.Rollback) {
 					msg = "Transition to Idle not allowed - Rollback is in progress"
@@ -489,6 +678,12 @@ func (r *ImageBasedUpgradeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 					return true
 				}
 
+				// trigger reconcile upon adding, updating or removing the init monitor deadline extension annotation
+				if e.ObjectOld.GetAnnotations()[common.AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation] !=
+					e.ObjectNew.GetAnnotations()[common.AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation] {
+					return true
+				}
+
 				// trigger reconcile upon adding or updating TriggerReconcileAnnotation
 				oldValue, oldExist := e.ObjectOld.GetAnnotations()[utils.TriggerReconcileAnnotation]
 				newValue, newExist := e.ObjectNew.GetAnnotations()[utils.TriggerReconcileAnnotation]
@@ -522,6 +717,6 @@ func (r *ImageBasedUpgradeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			},
 		})).
 		Owns(&kbatch.Job{}). // note: job resource watched is restricted further using cache.Options during NewManager
-		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1, RateLimiter: failureRateLimiter()}).
 		Complete(r)
 }
@@ -18,17 +18,27 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	ipcv1 "github.com/openshift-kni/lifecycle-agent/api/ipconfig/v1"
 	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/diagnostics"
+	"github.com/openshift-kni/lifecycle-agent/internal/notifier"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,6 +47,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -898,12 +909,71 @@ func TestImageBasedUpgradeReconciler_Reconcile(t *testing.T) {
 	}
 }
 
+func TestImageBasedUpgradeReconciler_ReconcileNotifiesOnceStatusUpdated(t *testing.T) {
+	var published []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event notifier.CloudEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		published = append(published, event.Data.Condition)
+	}))
+	defer server.Close()
+
+	objs := []client.Object{
+		&ibuv1.ImageBasedUpgrade{
+			ObjectMeta: v1.ObjectMeta{Name: utils.IBUName, UID: "5b6f"},
+			Spec:       ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Idle},
+		},
+		&ipcv1.IPConfig{
+			ObjectMeta: v1.ObjectMeta{Name: common.IPConfigName},
+			Spec:       ipcv1.IPConfigSpec{Stage: ipcv1.IPStages.Idle},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: notifier.ConfigMapName, Namespace: common.LcaNamespace},
+			Data: map[string]string{notifier.SinkKey: notifier.SinkHTTP, notifier.URLKey: server.URL,
+				notifier.SourceKey: "/sites/site-1"},
+		},
+	}
+	statusUpdateErr := fmt.Errorf("conflict")
+	fakeClient := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).WithStatusSubresource(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if statusUpdateErr != nil {
+					return statusUpdateErr
+				}
+				return c.SubResource(subResource).Update(ctx, obj, opts...)
+			},
+		}).Build()
+
+	mockClient := rpmostreeclient.NewMockIClient(gomock.NewController(t))
+	mockClient.EXPECT().IsStaterootBooted("rhcos_").Return(false, nil).Times(2)
+	r := &ImageBasedUpgradeReconciler{
+		Client:          fakeClient,
+		NoncachedClient: fakeClient,
+		Log:             logr.Discard(),
+		Scheme:          fakeClient.Scheme(),
+		RPMOstreeClient: mockClient,
+		Notifier:        notifier.NewNotifier(fakeClient, logr.Discard()),
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: utils.IBUName}}
+
+	// The condition changes that failed to be persisted are not published
+	_, err := r.Reconcile(context.TODO(), request)
+	assert.ErrorIs(t, err, statusUpdateErr)
+	assert.Empty(t, published)
+
+	statusUpdateErr = nil
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Contains(t, published, string(utils.ConditionTypes.Idle))
+}
+
 func Test_getValidNextStageList(t *testing.T) {
 	tests := []struct {
-		name          string
-		isAfterPivot  bool
-		conditions    []Condition
-		wantStageList []ibuv1.ImageBasedUpgradeStage
+		name                   string
+		isAfterPivot           bool
+		rollbackRetentionHours int
+		conditions             []Condition
+		wantStageList          []ibuv1.ImageBasedUpgradeStage
 	}{
 		{
 			name:          "prep in progress",
@@ -915,6 +985,11 @@ func Test_getValidNextStageList(t *testing.T) {
 			conditions:    []Condition{{utils.ConditionTypes.PrepCompleted, metav1.ConditionTrue, ""}},
 			wantStageList: []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle, ibuv1.Stages.Upgrade},
 		},
+		{
+			name:          "prep validated",
+			conditions:    []Condition{{utils.ConditionTypes.PrepCompleted, metav1.ConditionTrue, utils.ConditionReasons.Validated}},
+			wantStageList: []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle},
+		},
 		{
 			name: "prep failed",
 			conditions: []Condition{{utils.ConditionTypes.PrepCompleted, metav1.ConditionFalse, ""},
@@ -926,6 +1001,13 @@ func Test_getValidNextStageList(t *testing.T) {
 			conditions:    []Condition{{utils.ConditionTypes.UpgradeCompleted, metav1.ConditionTrue, ""}},
 			wantStageList: []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle, ibuv1.Stages.Rollback},
 		},
+		{
+			name:                   "upgrade completed within the rollback retention window",
+			isAfterPivot:           true,
+			rollbackRetentionHours: 24,
+			conditions:             []Condition{{utils.ConditionTypes.UpgradeCompleted, metav1.ConditionTrue, ""}},
+			wantStageList:          []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Rollback},
+		},
 		{
 			name:         "upgrade failed before pivot",
 			isAfterPivot: false,
@@ -1034,6 +1116,7 @@ func Test_getValidNextStageList(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ibu := &ibuv1.ImageBasedUpgrade{}
+			ibu.Spec.RollbackRetentionHours = tt.rollbackRetentionHours
 			for _, condition := range tt.conditions {
 				utils.SetStatusCondition(&ibu.Status.Conditions, condition.Type, condition.Reason, condition.Status, "", 1)
 			}
@@ -1044,6 +1127,241 @@ func Test_getValidNextStageList(t *testing.T) {
 	}
 }
 
+func TestUpdateRollbackAvailableCondition(t *testing.T) {
+	tests := []struct {
+		name                   string
+		isAfterPivot           bool
+		stage                  ibuv1.ImageBasedUpgradeStage
+		rollbackRetentionHours int
+		expiration             time.Time
+		staterootErr           error
+		wantCondition          *Condition
+		wantMessage            string
+		wantRequeue            bool
+	}{
+		{
+			name:  "before pivot",
+			stage: ibuv1.Stages.Upgrade,
+		},
+		{
+			name:         "finalizing",
+			isAfterPivot: true,
+			stage:        ibuv1.Stages.Idle,
+		},
+		{
+			name:          "rollback available",
+			isAfterPivot:  true,
+			stage:         ibuv1.Stages.Upgrade,
+			expiration:    time.Now().Add(time.Hour),
+			wantCondition: &Condition{utils.ConditionTypes.RollbackAvailable, metav1.ConditionTrue, utils.ConditionReasons.Available},
+			wantMessage:   "Rollback to the rhcos_4.14 stateroot is available until",
+			wantRequeue:   true,
+		},
+		{
+			name:                   "rollback available within the retention window",
+			isAfterPivot:           true,
+			stage:                  ibuv1.Stages.Upgrade,
+			rollbackRetentionHours: 2,
+			wantCondition:          &Condition{utils.ConditionTypes.RollbackAvailable, metav1.ConditionTrue, utils.ConditionReasons.Available},
+			wantMessage:            "the upgrade can not be finalized before",
+			wantRequeue:            true,
+		},
+		{
+			name:          "original stateroot removed",
+			isAfterPivot:  true,
+			stage:         ibuv1.Stages.Upgrade,
+			staterootErr:  fmt.Errorf("failed to find unbooted stateroot"),
+			wantCondition: &Condition{utils.ConditionTypes.RollbackAvailable, metav1.ConditionFalse, utils.ConditionReasons.StaterootRemoved},
+			wantMessage:   "The original stateroot is no longer available",
+		},
+		{
+			name:          "certificates expired",
+			isAfterPivot:  true,
+			stage:         ibuv1.Stages.Upgrade,
+			expiration:    time.Now().Add(-time.Hour),
+			wantCondition: &Condition{utils.ConditionTypes.RollbackAvailable, metav1.ConditionFalse, utils.ConditionReasons.CertificatesExpired},
+			wantMessage:   "requires the manual recovery of its control plane certificates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockClient := rpmostreeclient.NewMockIClient(ctrl)
+			mockClient.EXPECT().GetUnbootedStaterootName().Return("rhcos_4.14", tt.staterootErr).AnyTimes()
+			r := &ImageBasedUpgradeReconciler{Log: logr.Discard(), RPMOstreeClient: mockClient}
+
+			ibu := &ibuv1.ImageBasedUpgrade{}
+			ibu.Spec.Stage = tt.stage
+			ibu.Spec.RollbackRetentionHours = tt.rollbackRetentionHours
+			ibu.Status.RollbackAvailabilityExpiration = metav1.NewTime(tt.expiration)
+			utils.SetStatusCondition(&ibu.Status.Conditions, utils.ConditionTypes.UpgradeCompleted, utils.ConditionReasons.Completed, metav1.ConditionTrue, "", 1)
+			utils.SetStatusCondition(&ibu.Status.Conditions, utils.ConditionTypes.RollbackAvailable, utils.ConditionReasons.Available, metav1.ConditionTrue, "", 1)
+
+			r.updateRollbackAvailableCondition(ibu, tt.isAfterPivot)
+			condition := meta.FindStatusCondition(ibu.Status.Conditions, string(utils.ConditionTypes.RollbackAvailable))
+			if tt.wantCondition == nil {
+				assert.Nil(t, condition)
+			} else if assert.NotNil(t, condition) {
+				assert.Equal(t, string(tt.wantCondition.Status), string(condition.Status))
+				assert.Equal(t, string(tt.wantCondition.Reason), condition.Reason)
+				assert.Contains(t, condition.Message, tt.wantMessage)
+			}
+
+			requeue := requeueForRollbackAvailability(ibu, time.Now())
+			assert.Equal(t, tt.wantRequeue, requeue > 0)
+			assert.LessOrEqual(t, requeue, 2*time.Hour)
+		})
+	}
+}
+
+func TestCollectDiagnosticsOnFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		before        func(ibu *ibuv1.ImageBasedUpgrade)
+		after         func(ibu *ibuv1.ImageBasedUpgrade)
+		wantCollected bool
+		wantMessage   string
+	}{
+		{
+			name: "upgrade in progress",
+			after: func(ibu *ibuv1.ImageBasedUpgrade) {
+				utils.SetUpgradeStatusInProgress(ibu, "In progress")
+			},
+		},
+		{
+			name: "upgrade failed",
+			after: func(ibu *ibuv1.ImageBasedUpgrade) {
+				utils.SetUpgradeStatusFailed(ibu, "failed to restore")
+			},
+			wantCollected: true,
+			wantMessage:   "Diagnostics bundle of the Upgrade failure collected on the node at /var/lib/lca/diagnostics/lca-diagnostics-upgrade-",
+		},
+		{
+			name: "upgrade already failed",
+			before: func(ibu *ibuv1.ImageBasedUpgrade) {
+				utils.SetUpgradeStatusFailed(ibu, "failed to restore")
+			},
+			after: func(ibu *ibuv1.ImageBasedUpgrade) {
+				utils.SetUpgradeStatusFailed(ibu, "failed to restore")
+			},
+		},
+		{
+			name:  "rollback requested",
+			after: utils.SetUpgradeStatusRollbackRequested,
+		},
+		{
+			name: "rollback failed",
+			after: func(ibu *ibuv1.ImageBasedUpgrade) {
+				utils.SetUpgradeStatusRollbackRequested(ibu)
+				utils.SetRollbackStatusFailed(ibu, "failed to reboot")
+			},
+			wantCollected: true,
+			wantMessage:   "Diagnostics bundle of the Rollback failure collected on the node at /var/lib/lca/diagnostics/lca-diagnostics-rollback-",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockController := gomock.NewController(t)
+			mockExecutor := ops.NewMockExecute(mockController)
+			mockExecutor.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("", nil).AnyTimes()
+			c, _ := getFakeClientFromObjects()
+			r := &ImageBasedUpgradeReconciler{
+				Log:         logr.Discard(),
+				Diagnostics: &diagnostics.Collector{Client: c, Executor: mockExecutor, Log: logr.Discard(), Dir: t.TempDir()},
+			}
+
+			ibu := &ibuv1.ImageBasedUpgrade{}
+			if tt.before != nil {
+				tt.before(ibu)
+			}
+			conditionsBefore := slices.Clone(ibu.Status.Conditions)
+			tt.after(ibu)
+
+			r.collectDiagnosticsOnFailure(context.Background(), ibu, conditionsBefore)
+			condition := meta.FindStatusCondition(ibu.Status.Conditions, string(utils.ConditionTypes.DiagnosticsCollected))
+			if !tt.wantCollected {
+				assert.Nil(t, condition)
+			} else if assert.NotNil(t, condition) {
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				assert.Contains(t, condition.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestHoldForMaintenanceWindow(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	closedWindow := &ibuv1.MaintenanceWindow{
+		Start:      metav1.Time{Time: time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)},
+		Duration:   metav1.Duration{Duration: 4 * time.Hour},
+		Recurrence: ibuv1.MaintenanceWindowRecurrences.Daily,
+	}
+	openWindow := closedWindow.DeepCopy()
+	openWindow.Duration = metav1.Duration{Duration: 12 * time.Hour}
+
+	tests := []struct {
+		name         string
+		stage        ibuv1.ImageBasedUpgradeStage
+		isAfterPivot bool
+		window       *ibuv1.MaintenanceWindow
+		wantHeld     bool
+		wantInterval time.Duration
+	}{
+		{
+			name:  "no maintenance window",
+			stage: ibuv1.Stages.Upgrade,
+		},
+		{
+			name:         "upgrade held until the window opens",
+			stage:        ibuv1.Stages.Upgrade,
+			window:       closedWindow,
+			wantHeld:     true,
+			wantInterval: 14 * time.Hour,
+		},
+		{
+			name:   "upgrade within the window",
+			stage:  ibuv1.Stages.Upgrade,
+			window: openWindow,
+		},
+		{
+			name:         "rollback held until the window opens",
+			stage:        ibuv1.Stages.Rollback,
+			isAfterPivot: true,
+			window:       closedWindow,
+			wantHeld:     true,
+			wantInterval: 14 * time.Hour,
+		},
+		{
+			name:   "finalize not held",
+			stage:  ibuv1.Stages.Idle,
+			window: closedWindow,
+		},
+		{
+			name:         "invalid transition not held",
+			stage:        ibuv1.Stages.Rollback,
+			isAfterPivot: false,
+			window:       closedWindow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ibu := &ibuv1.ImageBasedUpgrade{}
+			ibu.Spec.Stage = tt.stage
+			ibu.Spec.MaintenanceWindow = tt.window
+			utils.SetStatusCondition(&ibu.Status.Conditions, utils.ConditionTypes.Idle, utils.ConditionReasons.InProgress, metav1.ConditionFalse, "", 1)
+			utils.SetPrepStatusCompleted(ibu, utils.PrepCompleted)
+			if tt.isAfterPivot {
+				utils.SetUpgradeStatusCompleted(ibu)
+			}
+
+			held, interval := holdForMaintenanceWindow(ibu, tt.isAfterPivot, now)
+			assert.Equal(t, tt.wantHeld, held)
+			assert.Equal(t, tt.wantInterval, interval)
+			assert.Equal(t, tt.wantHeld, utils.IsWaitingForWindow(ibu, tt.stage))
+		})
+	}
+}
+
 func TestImageBasedUpgradeReconciler_gateIBUByIPConfig(t *testing.T) {
 	t.Run("ipconfig not found => requeues soon (no status update)", func(t *testing.T) {
 		ibuObj := &ibuv1.ImageBasedUpgrade{
@@ -20,14 +20,18 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/openshift-kni/lifecycle-agent/internal/precache"
 	"github.com/openshift-kni/lifecycle-agent/internal/prep"
 
 	"github.com/go-logr/logr"
 	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/internal/progress"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+	"github.com/samber/lo"
 
 	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
 
@@ -48,13 +52,28 @@ func (r *ImageBasedUpgradeReconciler) resetStatusFields(ibu *ibuv1.ImageBasedUpg
 	utils.ResetStatusConditions(&ibu.Status.Conditions, ibu.Generation)
 }
 
-//nolint:unparam
 func (r *ImageBasedUpgradeReconciler) handleAbort(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
 	r.Log.Info("Starting handleAbort")
 
+	// The jobs of an in-progress Prep are stopped first, so that the partial stateroot and the container storage are
+	// no longer written to once they are cleaned up
+	if stopped, err := r.stopPrepJobs(ctx); err != nil {
+		return requeueWithShortInterval(), err
+	} else if !stopped {
+		utils.SetIdleStatusInProgress(ibu, utils.ConditionReasons.Aborting, utils.Aborting+": waiting for the Prep jobs to terminate")
+		return requeueWithShortInterval(), nil
+	}
+
 	if successful, errMsg := r.cleanup(ctx, ibu); successful {
 		r.Log.Info("Finished handleAbort successfully")
 		r.resetStatusFields(ibu)
+		utils.SetStatusCondition(&ibu.Status.Conditions,
+			utils.ConditionTypes.Idle,
+			utils.ConditionReasons.AbortCompleted,
+			metav1.ConditionTrue,
+			"Abort completed",
+			ibu.Generation,
+		)
 		return doNotRequeue(), nil
 	} else {
 		utils.SetStatusCondition(&ibu.Status.Conditions,
@@ -69,6 +88,29 @@ func (r *ImageBasedUpgradeReconciler) handleAbort(ctx context.Context, ibu *ibuv
 	return requeueWithLongInterval(), nil
 }
 
+// stopPrepJobs deletes the stateroot setup and precache jobs. It returns false as long as the pods of the jobs are
+// terminating, so that the abort is requeued instead of blocking on them.
+func (r *ImageBasedUpgradeReconciler) stopPrepJobs(ctx context.Context) (bool, error) {
+	if err := prep.StopStaterootSetupJob(ctx, r.Client, r.Log); err != nil {
+		return false, fmt.Errorf("failed to stop the stateroot setup job: %w", err)
+	}
+	if err := r.Precache.Cleanup(ctx); err != nil {
+		return false, fmt.Errorf("failed to stop the precache job: %w", err)
+	}
+
+	for _, jobName := range []string{prep.StaterootSetupJobName, precache.LcaPrecacheResourceName} {
+		pods, err := common.CountJobPods(ctx, r.Client, jobName)
+		if err != nil {
+			return false, fmt.Errorf("failed to check whether the Prep jobs are stopped: %w", err)
+		}
+		if pods > 0 {
+			r.Log.Info("Waiting for the job pods to terminate", "job", jobName, "pods", pods)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (r *ImageBasedUpgradeReconciler) handleFinalizeFailure(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
 	if done, err := r.checkManualCleanup(ctx, ibu); err != nil {
 		return requeueWithShortInterval(), err
@@ -108,7 +150,9 @@ func (r *ImageBasedUpgradeReconciler) handleFinalize(ctx context.Context, ibu *i
 	r.Log.Info("Starting handleFinalize")
 
 	r.Log.Info("Running health check for finalize (Idle) stage")
-	if err := healthcheck.HealthChecks(ctx, r.NoncachedClient, r.Log); err != nil {
+	err := healthcheck.HealthChecks(ctx, r.NoncachedClient, r.Log, healthCheckOptions(ibu)...)
+	r.Progress.RecordHealthCheck(progress.PlatformHealthCheck, err)
+	if err != nil {
 		msg := fmt.Sprintf("Waiting for system to stabilize before finalize (idle) stage can continue: %s", err.Error())
 		r.Log.Info(msg)
 		utils.SetStatusCondition(&ibu.Status.Conditions,
@@ -153,7 +197,7 @@ func (r *ImageBasedUpgradeReconciler) cleanup(ctx context.Context, ibu *ibuv1.Im
 	}
 
 	r.Log.Info("Cleaning up stateroot")
-	if err := r.cleanupStateroot(ctx); err != nil {
+	if err := r.cleanupStateroot(ctx, ibu); err != nil {
 		handleError(err, "failed to cleanup stateroot")
 	}
 
@@ -172,6 +216,11 @@ func (r *ImageBasedUpgradeReconciler) cleanup(ctx context.Context, ibu *ibuv1.Im
 		handleError(err, "failed to cleanup OADP resources")
 	}
 
+	r.Log.Info("Cleaning up the stage checkpoints")
+	if err := r.Checkpoints.Clear(); err != nil {
+		handleError(err, "failed to cleanup the stage checkpoints")
+	}
+
 	r.Log.Info("Cleaning up IBU files")
 	if err := cleanupIBUFiles(); err != nil {
 		handleError(err, "failed to cleanup ibu files.")
@@ -206,14 +255,22 @@ func (r *ImageBasedUpgradeReconciler) cleanupOADPResources(ctx context.Context)
 	return nil
 }
 
-func (r *ImageBasedUpgradeReconciler) cleanupStateroot(ctx context.Context) error {
+func (r *ImageBasedUpgradeReconciler) cleanupStateroot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) error {
 	r.Log.Info("Cleaning up cluster stateroot resources")
 	if err := prep.DeleteStaterootSetupJob(ctx, r.Client, r.Log); err != nil {
 		return fmt.Errorf("failed to cleanup cluster stateroot resources: %w", err)
 	}
 
+	retained, err := retainedStateroots(r.RPMOstreeClient, ibu, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to apply the stateroot retention: %w", err)
+	}
+	if len(retained) > 0 {
+		r.Log.Info("Keeping unbooted stateroots per the stateroot retention", "stateroots", lo.Keys(retained))
+	}
+
 	r.Log.Info("Cleaning up unbooted stateroot resources")
-	if err := CleanupUnbootedStateroots(r.Log, r.Ops, r.OstreeClient, r.RPMOstreeClient); err != nil {
+	if err := cleanupUnbootedStateroots(r.Log, r.Ops, r.OstreeClient, r.RPMOstreeClient, retained); err != nil {
 		return fmt.Errorf("failed to clean up host stateroot resources: %w", err)
 	}
 
@@ -232,6 +289,12 @@ func cleanupIBUFiles() error {
 }
 
 func CleanupUnbootedStateroots(log logr.Logger, ops ops.Ops, ostreeClient ostreeclient.IClient, rpmOstreeClient rpmostreeclient.IClient) error {
+	return cleanupUnbootedStateroots(log, ops, ostreeClient, rpmOstreeClient, nil)
+}
+
+// cleanupUnbootedStateroots removes the unbooted stateroots, except for the retained ones
+func cleanupUnbootedStateroots(log logr.Logger, ops ops.Ops, ostreeClient ostreeclient.IClient, rpmOstreeClient rpmostreeclient.IClient,
+	retained map[string]bool) error {
 	status, err := rpmOstreeClient.QueryStatus()
 	if err != nil {
 		return fmt.Errorf("failed to query status with rpmostree: %w", err)
@@ -246,6 +309,9 @@ func CleanupUnbootedStateroots(log logr.Logger, ops ops.Ops, ostreeClient ostree
 			bootedStateroot = deployment.OSName
 			continue
 		}
+		if retained[deployment.OSName] {
+			continue
+		}
 		staterootsToRemove = append(staterootsToRemove, deployment.OSName)
 	}
 
@@ -273,7 +339,7 @@ func CleanupUnbootedStateroots(log logr.Logger, ops ops.Ops, ostreeClient ostree
 	}
 	for _, fileInfo := range files {
 		if fileInfo.IsDir() {
-			if fileInfo.Name() == bootedStateroot {
+			if fileInfo.Name() == bootedStateroot || retained[fileInfo.Name()] {
 				continue
 			}
 			err := osRemoveAll(getStaterootPath(fileInfo.Name()))
@@ -291,6 +357,11 @@ func CleanupUnbootedStateroots(log logr.Logger, ops ops.Ops, ostreeClient ostree
 	return fmt.Errorf("failed to remove %d stateroots", failures)
 }
 
+// RemoveUnbootedStateroot removes the stateroot, e.g. partially set up by an interrupted stateroot setup job
+func RemoveUnbootedStateroot(stateroot string, ops ops.Ops, ostreeClient ostreeclient.IClient, rpmOstreeClient rpmostreeclient.IClient) error {
+	return cleanupUnbootedStateroot(stateroot, ops, ostreeClient, rpmOstreeClient)
+}
+
 func cleanupUnbootedStateroot(stateroot string, ops ops.Ops, ostreeClient ostreeclient.IClient, rpmOstreeClient rpmostreeclient.IClient) error {
 	status, err := rpmOstreeClient.QueryStatus()
 	if err != nil {
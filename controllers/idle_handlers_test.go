@@ -25,11 +25,16 @@ import (
 	"github.com/go-logr/logr"
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/internal/precache"
+	"github.com/openshift-kni/lifecycle-agent/internal/prep"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	kbatch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
 	corev1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -303,3 +308,54 @@ func TestImageBasedUpgradeReconciler_cleanupUnbootedStateroots(t *testing.T) {
 		})
 	}
 }
+
+func TestImageBasedUpgradeReconciler_stopPrepJobs(t *testing.T) {
+	tests := []struct {
+		name         string
+		objs         []client.Object
+		expectedStop bool
+	}{
+		{
+			name:         "no prep job running",
+			expectedStop: true,
+		},
+		{
+			name: "stateroot setup pod terminating",
+			objs: []client.Object{
+				&kbatch.Job{ObjectMeta: corev1.ObjectMeta{Name: prep.StaterootSetupJobName, Namespace: common.LcaNamespace}},
+				&v1.Pod{ObjectMeta: corev1.ObjectMeta{Name: "stateroot-pod", Namespace: common.LcaNamespace,
+					Labels: map[string]string{"job-name": prep.StaterootSetupJobName}}},
+			},
+			expectedStop: false,
+		},
+		{
+			name: "precache pod terminating",
+			objs: []client.Object{
+				&kbatch.Job{ObjectMeta: corev1.ObjectMeta{Name: precache.LcaPrecacheResourceName, Namespace: common.LcaNamespace}},
+				&v1.Pod{ObjectMeta: corev1.ObjectMeta{Name: "precache-pod", Namespace: common.LcaNamespace,
+					Labels: map[string]string{"job-name": precache.LcaPrecacheResourceName}}},
+			},
+			expectedStop: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := getFakeClientFromObjects(tt.objs...)
+			assert.NoError(t, err)
+			r := &ImageBasedUpgradeReconciler{
+				Client:   c,
+				Log:      logr.Discard(),
+				Precache: &precache.PHandler{Client: c, Log: logr.Discard()},
+			}
+
+			stopped, err := r.stopPrepJobs(context.TODO())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStop, stopped)
+
+			// the jobs are deleted whether or not their pods are gone
+			jobs := &kbatch.JobList{}
+			assert.NoError(t, c.List(context.TODO(), jobs))
+			assert.Empty(t, jobs.Items)
+		})
+	}
+}
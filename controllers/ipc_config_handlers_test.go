@@ -15,6 +15,7 @@ import (
 	ipcv1 "github.com/openshift-kni/lifecycle-agent/api/ipconfig/v1"
 	controllerutils "github.com/openshift-kni/lifecycle-agent/controllers/utils"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
@@ -274,7 +275,7 @@ func TestIPCConfigTwoPhaseHandler_PrePivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			return errors.New("not healthy")
 		}
 
@@ -322,7 +323,7 @@ func TestIPCConfigTwoPhaseHandler_PrePivot(t *testing.T) {
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
 		called := false
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			called = true
 			return errors.New("not healthy")
 		}
@@ -362,7 +363,9 @@ func TestIPCConfigTwoPhaseHandler_PrePivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockOps.EXPECT().
 			CopyFile(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -405,7 +408,9 @@ func TestIPCConfigTwoPhaseHandler_PrePivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockOps.EXPECT().
 			CopyFile(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -476,7 +481,9 @@ func TestIPCConfigTwoPhaseHandler_PrePivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockOps.EXPECT().CopyFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
@@ -587,7 +594,9 @@ func TestIPCConfigTwoPhaseHandler_PrePivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockOps.EXPECT().CopyFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 		mockOps.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
@@ -650,7 +659,7 @@ func TestIPCConfigTwoPhaseHandler_PostPivot(t *testing.T) {
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
 		called := false
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			called = true
 			return errors.New("not healthy")
 		}
@@ -693,7 +702,7 @@ func TestIPCConfigTwoPhaseHandler_PostPivot(t *testing.T) {
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
 		called := false
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			called = true
 			return nil
 		}
@@ -787,7 +796,9 @@ func TestIPCConfigTwoPhaseHandler_PostPivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return errors.New("not healthy") }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return errors.New("not healthy")
+		}
 
 		mockReboot.EXPECT().DisableInitMonitor().Return(nil).Times(1)
 
@@ -852,7 +863,9 @@ func TestIPCConfigTwoPhaseHandler_PostPivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockReboot.EXPECT().DisableInitMonitor().Return(nil).Times(1)
 
@@ -901,7 +914,9 @@ func TestIPCConfigTwoPhaseHandler_PostPivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockReboot.EXPECT().DisableInitMonitor().Return(errors.New("disable failed")).Times(1)
 
@@ -943,7 +958,9 @@ func TestIPCConfigTwoPhaseHandler_PostPivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockReboot.EXPECT().DisableInitMonitor().Return(nil).Times(1)
 
@@ -1013,7 +1030,9 @@ func TestIPCConfigTwoPhaseHandler_PostPivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return errors.New("not healthy") }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return errors.New("not healthy")
+		}
 
 		mockReboot.EXPECT().DisableInitMonitor().Return(nil).Times(1)
 
@@ -19,6 +19,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -440,6 +441,7 @@ func (r *IPConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return false
 			},
 		})).
+		WithOptions(controller.Options{RateLimiter: failureRateLimiter()}).
 		Complete(r)
 }
 
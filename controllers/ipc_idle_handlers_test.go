@@ -13,6 +13,7 @@ import (
 	ipcv1 "github.com/openshift-kni/lifecycle-agent/api/ipconfig/v1"
 	controllerutils "github.com/openshift-kni/lifecycle-agent/controllers/utils"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
@@ -190,7 +191,7 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			t.Fatalf("CheckHealth should not be called when stage validation fails")
 			return nil
 		}
@@ -210,7 +211,7 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 		assert.NoError(t, k8sClient.Status().Update(ctx, updated))
 
 		called := false
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			called = true
 			return errors.New("not healthy")
 		}
@@ -281,7 +282,9 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return errors.New("not healthy") }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return errors.New("not healthy")
+		}
 
 		res, err := h.Handle(ctx, ipc)
 		assert.NoError(t, err)
@@ -317,7 +320,7 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
 		called := false
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			called = true
 			return errors.New("not healthy")
 		}
@@ -359,7 +362,9 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockOps.EXPECT().RemountSysroot().Return(errors.New("remount failed")).Times(1)
 
@@ -396,7 +401,9 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		mockOps.EXPECT().RemountSysroot().Return(nil).Times(1)
 		mockRpm.EXPECT().QueryStatus().Return(nil, errors.New("rpm error")).Times(1)
@@ -434,7 +441,9 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		status := &rpmostreeclient.Status{
 			Deployments: []rpmostreeclient.Deployment{{OSName: "rhcos", Booted: true}},
@@ -499,7 +508,9 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		status := &rpmostreeclient.Status{
 			Deployments: []rpmostreeclient.Deployment{{OSName: "rhcos", Booted: true}},
@@ -560,7 +571,9 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return errors.New("still unhealthy") }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return errors.New("still unhealthy")
+		}
 
 		res, err := h.Handle(ctx, ipc)
 		assert.NoError(t, err)
@@ -604,7 +617,7 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			t.Fatalf("CheckHealth should not be called when manual cleanup update fails")
 			return nil
 		}
@@ -651,7 +664,7 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			t.Fatalf("CheckHealth should not be called when idle handler exits early")
 			return nil
 		}
@@ -698,7 +711,7 @@ func TestIPCIdleStageHandler_Handle(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			t.Fatalf("CheckHealth should not be called when manual-cleanup status update fails")
 			return nil
 		}
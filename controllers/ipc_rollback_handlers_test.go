@@ -12,6 +12,7 @@ import (
 	v1 "github.com/openshift-kni/lifecycle-agent/api/ipconfig/v1"
 	controllerutils "github.com/openshift-kni/lifecycle-agent/controllers/utils"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
 	"github.com/stretchr/testify/assert"
@@ -337,7 +338,7 @@ func TestIPCRollbackTwoPhaseHandler_PostPivot(t *testing.T) {
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
 		called := false
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			called = true
 			return errors.New("not healthy")
 		}
@@ -374,7 +375,7 @@ func TestIPCRollbackTwoPhaseHandler_PostPivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 			return errors.New("not healthy")
 		}
 
@@ -409,7 +410,9 @@ func TestIPCRollbackTwoPhaseHandler_PostPivot(t *testing.T) {
 
 		oldHC := CheckHealth
 		defer func() { CheckHealth = oldHC }()
-		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error { return nil }
+		CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
+			return nil
+		}
 
 		res, err := h.PostPivot(context.Background(), ipc, logger)
 		assert.NoError(t, err)
@@ -0,0 +1,284 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
+)
+
+// Names of the preflight checks, in the order they are run
+const (
+	PreflightCheckSeedImageVersion = "SeedImageVersion"
+	PreflightCheckSeedImage        = "SeedImage"
+	PreflightCheckDiskSpace        = "DiskSpace"
+	PreflightCheckOADP             = "OADP"
+	PreflightCheckExtraManifests   = "ExtraManifests"
+	PreflightCheckCertificates     = "Certificates"
+)
+
+// preflightCertificateValidity is how long the control plane certificates must remain valid for an upgrade to be
+// started without a warning
+const preflightCertificateValidity = 24 * time.Hour
+
+// getCertificatesExpiration returns when the control plane certificates of the stateroot expire
+var getCertificatesExpiration = common.GetRollbackAvailabilityExpiration
+
+// ImageBasedUpgradePreflightReconciler reconciles an ImageBasedUpgradePreflight object
+type ImageBasedUpgradePreflightReconciler struct {
+	client.Client
+	NoncachedClient client.Reader
+	Log             logr.Logger
+	Scheme          *runtime.Scheme
+	Mux             *sync.Mutex
+
+	// Checks runs the checks shared with the Prep stage of the upgrade
+	Checks *ImageBasedUpgradeReconciler
+}
+
+//+kubebuilder:rbac:groups=lca.openshift.io,resources=imagebasedupgradepreflights,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=lca.openshift.io,resources=imagebasedupgradepreflights/status,verbs=get;update;patch
+
+// Reconcile runs the preflight checks once per generation of the ImageBasedUpgradePreflight and records their
+// outcome in the status
+func (r *ImageBasedUpgradePreflightReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.Mux != nil {
+		r.Mux.Lock()
+		defer r.Mux.Unlock()
+	}
+
+	preflight := &ibuv1.ImageBasedUpgradePreflight{}
+	if err := r.NoncachedClient.Get(ctx, req.NamespacedName, preflight); err != nil {
+		if errors.IsNotFound(err) {
+			return doNotRequeue(), nil
+		}
+		return requeueWithError(fmt.Errorf("failed to get ImageBasedUpgradePreflight: %w", err))
+	}
+
+	completed := meta.FindStatusCondition(preflight.Status.Conditions, string(utils.PreflightConditionTypes.Completed))
+	if completed != nil && completed.Status == metav1.ConditionTrue && preflight.Status.ObservedGeneration == preflight.Generation {
+		r.Log.Info("Preflight checks already completed", "name", req.Name, "result", completed.Reason)
+		return doNotRequeue(), nil
+	}
+
+	r.Log.Info("Running preflight checks", "name", req.Name, "seedImage", preflight.Spec.SeedImageRef.Image)
+	preflight.Status.ObservedGeneration = preflight.Generation
+	preflight.Status.StartTime = metav1.Now()
+	preflight.Status.CompletionTime = metav1.Time{}
+	preflight.Status.Checks = nil
+	utils.SetStatusCondition(&preflight.Status.Conditions, utils.PreflightConditionTypes.Completed,
+		utils.PreflightConditionReasons.InProgress, metav1.ConditionFalse, "Running the preflight checks", preflight.Generation)
+	if err := r.Status().Update(ctx, preflight); err != nil {
+		return requeueWithError(fmt.Errorf("failed to update ImageBasedUpgradePreflight status: %w", err))
+	}
+
+	preflight.Status.Checks = r.runPreflightChecks(ctx, preflightIBU(preflight))
+	preflight.Status.CompletionTime = metav1.Now()
+	setPreflightCompleted(preflight)
+	if err := r.Status().Update(ctx, preflight); err != nil {
+		return requeueWithError(fmt.Errorf("failed to update ImageBasedUpgradePreflight status: %w", err))
+	}
+
+	r.Log.Info("Preflight checks completed", "name", req.Name, "checks", preflight.Status.Checks)
+	return doNotRequeue(), nil
+}
+
+// preflightIBU returns the upgrade described by the preflight, which the checks shared with the Prep stage run against
+func preflightIBU(preflight *ibuv1.ImageBasedUpgradePreflight) *ibuv1.ImageBasedUpgrade {
+	return &ibuv1.ImageBasedUpgrade{
+		Spec: ibuv1.ImageBasedUpgradeSpec{
+			SeedImageRef:        preflight.Spec.SeedImageRef,
+			OADPContent:         preflight.Spec.OADPContent,
+			ExtraManifests:      preflight.Spec.ExtraManifests,
+			DiskSpaceValidation: preflight.Spec.DiskSpaceValidation,
+		},
+	}
+}
+
+// setPreflightCompleted sets the Completed condition, with the Failed reason if any of the checks failed
+func setPreflightCompleted(preflight *ibuv1.ImageBasedUpgradePreflight) {
+	reason := utils.PreflightConditionReasons.Passed
+	msg := "All preflight checks passed"
+	var failed, warnings []string
+	for _, check := range preflight.Status.Checks {
+		switch check.Result {
+		case ibuv1.PreflightCheckResults.Failed:
+			failed = append(failed, check.Name)
+		case ibuv1.PreflightCheckResults.Warning:
+			warnings = append(warnings, check.Name)
+		}
+	}
+	if len(failed) > 0 {
+		reason = utils.PreflightConditionReasons.Failed
+		msg = fmt.Sprintf("Preflight checks failed: %v", failed)
+	} else if len(warnings) > 0 {
+		msg = fmt.Sprintf("Preflight checks passed with warnings: %v", warnings)
+	}
+	utils.SetStatusCondition(&preflight.Status.Conditions, utils.PreflightConditionTypes.Completed, reason,
+		metav1.ConditionTrue, msg, preflight.Generation)
+}
+
+func preflightCheck(name string, result ibuv1.PreflightCheckResult, msg string) ibuv1.PreflightCheck {
+	return ibuv1.PreflightCheck{Name: name, Result: result, Message: msg}
+}
+
+func preflightCheckFromError(name string, err error, passedMsg string) ibuv1.PreflightCheck {
+	if err != nil {
+		return preflightCheck(name, ibuv1.PreflightCheckResults.Failed, err.Error())
+	}
+	return preflightCheck(name, ibuv1.PreflightCheckResults.Passed, passedMsg)
+}
+
+// runPreflightChecks runs all the checks, regardless of the failures, so that all the issues are reported at once
+func (r *ImageBasedUpgradePreflightReconciler) runPreflightChecks(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) []ibuv1.PreflightCheck {
+	checks := []ibuv1.PreflightCheck{
+		preflightCheckFromError(PreflightCheckSeedImageVersion, r.Checks.validateSeedOcpVersion(ibu.Spec.SeedImageRef.Version),
+			"The seed image version is higher than the cluster version"),
+	}
+
+	seedImage, err := r.checkSeedImage(ctx, ibu)
+	checks = append(checks, preflightCheckFromError(PreflightCheckSeedImage, err,
+		"The seed image is compatible with the cluster"))
+
+	switch {
+	case ibu.Spec.DiskSpaceValidation != nil && ibu.Spec.DiskSpaceValidation.Disabled:
+		checks = append(checks, preflightCheck(PreflightCheckDiskSpace, ibuv1.PreflightCheckResults.Skipped,
+			"The disk space validation is disabled"))
+	case seedImage == nil:
+		checks = append(checks, preflightCheck(PreflightCheckDiskSpace, ibuv1.PreflightCheckResults.Skipped,
+			"The seed image could not be inspected"))
+	default:
+		checks = append(checks, preflightCheckFromError(PreflightCheckDiskSpace, r.Checks.validateDiskSpace(ibu, seedImage),
+			"There is enough disk space for the new stateroot and the precached images"))
+	}
+
+	return append(checks,
+		r.checkOADP(ctx, ibu),
+		r.checkExtraManifests(ctx, ibu),
+		r.checkCertificates(),
+	)
+}
+
+// checkSeedImage inspects the seed image and validates its configuration against the cluster, returning the seed
+// image metadata if it could be inspected
+func (r *ImageBasedUpgradePreflightReconciler) checkSeedImage(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (*seedimage.Inspect, error) {
+	// The seed image pull-secret is written to the IBU workspace
+	if err := initIBUWorkspaceDir(); err != nil {
+		return nil, fmt.Errorf("failed to initialize IBU workspace: %w", err)
+	}
+
+	seedImage, err := r.Checks.inspectSeedImage(ctx, ibu)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed image labels: %w", err)
+	}
+	if err := r.Checks.validateSeedImageConfig(ctx, seedImage.Labels); err != nil {
+		return seedImage, err
+	}
+	if err := r.Checks.validateSeedImageArchitecture(ctx, seedImage); err != nil {
+		return seedImage, err
+	}
+	return seedImage, nil
+}
+
+// checkOADP validates the OADP configmaps and checks that the backup storage locations are reachable
+func (r *ImageBasedUpgradePreflightReconciler) checkOADP(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) ibuv1.PreflightCheck {
+	if len(ibu.Spec.OADPContent) == 0 {
+		return preflightCheck(PreflightCheckOADP, ibuv1.PreflightCheckResults.Skipped, "No OADP content is set")
+	}
+
+	if err := r.Checks.BackupRestore.CheckOadpOperatorAvailability(ctx); err != nil {
+		return preflightCheckFromError(PreflightCheckOADP, fmt.Errorf("failed to check oadp operator availability: %w", err), "")
+	}
+	if err := r.Checks.BackupRestore.ValidateOadpConfigmaps(ctx, ibu.Spec.OADPContent); err != nil {
+		return preflightCheckFromError(PreflightCheckOADP, fmt.Errorf("failed to validate oadp configMap: %w", err), "")
+	}
+	if err := healthcheck.AreBackupStorageLocationsAvailable(ctx, r.NoncachedClient, r.Log); err != nil {
+		return preflightCheckFromError(PreflightCheckOADP, fmt.Errorf("backup storage locations are not reachable: %w", err), "")
+	}
+	return preflightCheck(PreflightCheckOADP, ibuv1.PreflightCheckResults.Passed,
+		"The OADP content is valid and the backup storage locations are available")
+}
+
+// checkExtraManifests renders the extra manifests with a dry-run
+func (r *ImageBasedUpgradePreflightReconciler) checkExtraManifests(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) ibuv1.PreflightCheck {
+	if len(ibu.Spec.ExtraManifests) == 0 {
+		return preflightCheck(PreflightCheckExtraManifests, ibuv1.PreflightCheckResults.Skipped, "No extra manifests are set")
+	}
+
+	warn, err := r.Checks.ExtraManifest.ValidateExtraManifestConfigmaps(ctx, ibu.Spec.ExtraManifests)
+	if err != nil {
+		return preflightCheckFromError(PreflightCheckExtraManifests, fmt.Errorf("failed to validate extramanifest cms: %w", err), "")
+	}
+	if warn != "" {
+		return preflightCheck(PreflightCheckExtraManifests, ibuv1.PreflightCheckResults.Warning, warn)
+	}
+	return preflightCheck(PreflightCheckExtraManifests, ibuv1.PreflightCheckResults.Passed,
+		"The extra manifests were rendered with a dry-run")
+}
+
+// checkCertificates checks that the control plane certificates remain valid for the duration of the upgrade
+func (r *ImageBasedUpgradePreflightReconciler) checkCertificates() ibuv1.PreflightCheck {
+	stateroot, err := r.Checks.RPMOstreeClient.GetCurrentStaterootName()
+	if err != nil {
+		return preflightCheckFromError(PreflightCheckCertificates, fmt.Errorf("failed to get the booted stateroot: %w", err), "")
+	}
+	expiry, err := getCertificatesExpiration(stateroot, r.Log)
+	if err != nil {
+		return preflightCheckFromError(PreflightCheckCertificates, err, "")
+	}
+
+	now := time.Now()
+	expiryTime := expiry.UTC().Format(time.RFC3339)
+	switch {
+	case expiry.Before(now):
+		return preflightCheck(PreflightCheckCertificates, ibuv1.PreflightCheckResults.Failed,
+			fmt.Sprintf("The control plane certificates expired at %s", expiryTime))
+	case expiry.Before(now.Add(preflightCertificateValidity)):
+		return preflightCheck(PreflightCheckCertificates, ibuv1.PreflightCheckResults.Warning,
+			fmt.Sprintf("The control plane certificates expire at %s, the upgrade must be completed before then", expiryTime))
+	}
+	return preflightCheck(PreflightCheckCertificates, ibuv1.PreflightCheckResults.Passed,
+		fmt.Sprintf("The control plane certificates are valid until %s", expiryTime))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ImageBasedUpgradePreflightReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	//nolint:wrapcheck
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ibuv1.ImageBasedUpgradePreflight{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1, RateLimiter: failureRateLimiter()}).
+		Complete(r)
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	mock_backuprestore "github.com/openshift-kni/lifecycle-agent/internal/backuprestore/mocks"
+	mock_extramanifest "github.com/openshift-kni/lifecycle-agent/internal/extramanifest/mocks"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+)
+
+func TestImageBasedUpgradePreflightReconciler_Reconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, ibuv1.AddToScheme(s))
+	assert.NoError(t, configv1.AddToScheme(s))
+	assert.NoError(t, velerov1.AddToScheme(s))
+
+	version := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.15.0"}},
+	}
+	bsl := &velerov1.BackupStorageLocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: backuprestore.OadpNs},
+		Status:     velerov1.BackupStorageLocationStatus{Phase: velerov1.BackupStorageLocationPhaseAvailable},
+	}
+
+	testcases := []struct {
+		name             string
+		spec             ibuv1.ImageBasedUpgradePreflightSpec
+		certExpiry       time.Duration
+		extraManifestErr error
+		extraManifestMsg string
+		expectChecks     map[string]ibuv1.PreflightCheckResult
+		expectReason     utils.ConditionReason
+	}{
+		{
+			name: "failed seed image and skipped optional checks",
+			spec: ibuv1.ImageBasedUpgradePreflightSpec{
+				SeedImageRef: ibuv1.SeedImageRef{Image: "quay.io/seed:4.16", Version: "4.16.0"},
+			},
+			certExpiry: 30 * 24 * time.Hour,
+			expectChecks: map[string]ibuv1.PreflightCheckResult{
+				PreflightCheckSeedImageVersion: ibuv1.PreflightCheckResults.Passed,
+				PreflightCheckSeedImage:        ibuv1.PreflightCheckResults.Failed,
+				PreflightCheckDiskSpace:        ibuv1.PreflightCheckResults.Skipped,
+				PreflightCheckOADP:             ibuv1.PreflightCheckResults.Skipped,
+				PreflightCheckExtraManifests:   ibuv1.PreflightCheckResults.Skipped,
+				PreflightCheckCertificates:     ibuv1.PreflightCheckResults.Passed,
+			},
+			expectReason: utils.PreflightConditionReasons.Failed,
+		},
+		{
+			name: "warnings do not fail the preflight",
+			spec: ibuv1.ImageBasedUpgradePreflightSpec{
+				SeedImageRef:        ibuv1.SeedImageRef{Image: "quay.io/seed:4.14", Version: "4.14.0"},
+				OADPContent:         []ibuv1.ConfigMapRef{{Name: "oadp", Namespace: backuprestore.OadpNs}},
+				ExtraManifests:      []ibuv1.ConfigMapRef{{Name: "extra", Namespace: "default"}},
+				DiskSpaceValidation: &ibuv1.DiskSpaceValidation{Disabled: true},
+			},
+			certExpiry:       time.Hour,
+			extraManifestMsg: "the CRD of a manifest is missing",
+			expectChecks: map[string]ibuv1.PreflightCheckResult{
+				PreflightCheckSeedImageVersion: ibuv1.PreflightCheckResults.Failed,
+				PreflightCheckSeedImage:        ibuv1.PreflightCheckResults.Failed,
+				PreflightCheckDiskSpace:        ibuv1.PreflightCheckResults.Skipped,
+				PreflightCheckOADP:             ibuv1.PreflightCheckResults.Passed,
+				PreflightCheckExtraManifests:   ibuv1.PreflightCheckResults.Warning,
+				PreflightCheckCertificates:     ibuv1.PreflightCheckResults.Warning,
+			},
+			expectReason: utils.PreflightConditionReasons.Failed,
+		},
+		{
+			name: "expired certificates and invalid extra manifests",
+			spec: ibuv1.ImageBasedUpgradePreflightSpec{
+				SeedImageRef:   ibuv1.SeedImageRef{Image: "quay.io/seed:4.16", Version: "4.16.0"},
+				ExtraManifests: []ibuv1.ConfigMapRef{{Name: "extra", Namespace: "default"}},
+			},
+			certExpiry:       -time.Hour,
+			extraManifestErr: errors.New("invalid manifest"),
+			expectChecks: map[string]ibuv1.PreflightCheckResult{
+				PreflightCheckExtraManifests: ibuv1.PreflightCheckResults.Failed,
+				PreflightCheckCertificates:   ibuv1.PreflightCheckResults.Failed,
+			},
+			expectReason: utils.PreflightConditionReasons.Failed,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			mockExecutor := ops.NewMockExecute(mockController)
+			mockRPMOstree := rpmostreeclient.NewMockIClient(mockController)
+			mockBackupRestore := mock_backuprestore.NewMockBackuperRestorer(mockController)
+			mockExtraManifest := mock_extramanifest.NewMockEManifestHandler(mockController)
+
+			mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).Return("", errors.New("unauthorized")).AnyTimes()
+			mockRPMOstree.EXPECT().GetCurrentStaterootName().Return("rhcos", nil)
+			if len(tc.spec.OADPContent) > 0 {
+				mockBackupRestore.EXPECT().CheckOadpOperatorAvailability(gomock.Any()).Return(nil)
+				mockBackupRestore.EXPECT().ValidateOadpConfigmaps(gomock.Any(), tc.spec.OADPContent).Return(nil)
+			}
+			if len(tc.spec.ExtraManifests) > 0 {
+				mockExtraManifest.EXPECT().ValidateExtraManifestConfigmaps(gomock.Any(), tc.spec.ExtraManifests).
+					Return(tc.extraManifestMsg, tc.extraManifestErr)
+			}
+
+			origGetCertificatesExpiration := getCertificatesExpiration
+			defer func() { getCertificatesExpiration = origGetCertificatesExpiration }()
+			getCertificatesExpiration = func(stateroot string, _ logr.Logger) (time.Time, error) {
+				assert.Equal(t, "rhcos", stateroot)
+				return time.Now().Add(tc.certExpiry), nil
+			}
+
+			preflight := &ibuv1.ImageBasedUpgradePreflight{
+				ObjectMeta: metav1.ObjectMeta{Name: "preflight", Generation: 1},
+				Spec:       tc.spec,
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(version, bsl, preflight).
+				WithStatusSubresource(preflight).Build()
+
+			r := &ImageBasedUpgradePreflightReconciler{
+				Client:          c,
+				NoncachedClient: c,
+				Log:             logr.Discard(),
+				Scheme:          s,
+				Checks: &ImageBasedUpgradeReconciler{
+					Client:          c,
+					NoncachedClient: c,
+					Log:             logr.Discard(),
+					Executor:        mockExecutor,
+					RPMOstreeClient: mockRPMOstree,
+					BackupRestore:   mockBackupRestore,
+					ExtraManifest:   mockExtraManifest,
+				},
+			}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: preflight.Name}}
+			result, err := r.Reconcile(context.Background(), req)
+			assert.NoError(t, err)
+			assert.Equal(t, doNotRequeue(), result)
+
+			got := &ibuv1.ImageBasedUpgradePreflight{}
+			assert.NoError(t, c.Get(context.Background(), req.NamespacedName, got))
+			assert.Len(t, got.Status.Checks, 6)
+			for _, check := range got.Status.Checks {
+				if expected, ok := tc.expectChecks[check.Name]; ok {
+					assert.Equal(t, expected, check.Result, "%s: %s", check.Name, check.Message)
+				}
+			}
+			completed := meta.FindStatusCondition(got.Status.Conditions, string(utils.PreflightConditionTypes.Completed))
+			if assert.NotNil(t, completed) {
+				assert.Equal(t, metav1.ConditionTrue, completed.Status)
+				assert.Equal(t, string(tc.expectReason), completed.Reason)
+			}
+			assert.Equal(t, got.Generation, got.Status.ObservedGeneration)
+			assert.False(t, got.Status.CompletionTime.IsZero())
+
+			// The checks are not run again for the same generation
+			_, err = r.Reconcile(context.Background(), req)
+			assert.NoError(t, err)
+		})
+	}
+}
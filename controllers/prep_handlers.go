@@ -18,12 +18,13 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
@@ -39,57 +40,193 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/openshift-kni/lifecycle-agent/internal/clusterconfig"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
+	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
+	"github.com/openshift-kni/lifecycle-agent/internal/imagemgmt"
 	"github.com/openshift-kni/lifecycle-agent/internal/precache"
 	"github.com/openshift-kni/lifecycle-agent/internal/prep"
+	"github.com/openshift-kni/lifecycle-agent/internal/preservedpaths"
+	"github.com/openshift-kni/lifecycle-agent/internal/progress"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
 	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+// precachePullSecretFile holds the registry credentials of the precaching job when the seed image has its own pull-secret
+var precachePullSecretFile = filepath.Join(utils.IBUWorkspacePath, "precache-pull-secret")
+
 func GetSeedImage(c client.Client, ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, log logr.Logger, ops ops.Execute) error {
 	// Use cluster wide pull-secret by default
 	pullSecretFilename := common.ImageRegistryAuthFile
 
 	if ibu.Spec.SeedImageRef.PullSecretRef != nil {
-		var pullSecret string
-		pullSecret, err := lcautils.GetSecretData(ctx, ibu.Spec.SeedImageRef.PullSecretRef.Name,
-			common.LcaNamespace, corev1.DockerConfigJsonKey, c)
-		if err != nil {
-			err = fmt.Errorf("failed to retrieve pull-secret from secret %s, err: %w", ibu.Spec.SeedImageRef.PullSecretRef.Name, err)
+		var err error
+		if pullSecretFilename, err = writeSeedPullSecret(ctx, c, ibu); err != nil {
 			return err
 		}
+		defer os.Remove(common.PathOutsideChroot(pullSecretFilename))
+	}
+
+	pullArgs := []string{"--authfile", pullSecretFilename}
+
+	// Encrypted seed image layers are decrypted when pulled
+	if ibu.Spec.SeedImageRef.DecryptionKeySecretRef != nil {
+		decryptionKey, err := lcautils.GetSecretData(ctx, ibu.Spec.SeedImageRef.DecryptionKeySecretRef.Name,
+			common.LcaNamespace, prep.SeedDecryptionKeySecretKey, c)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve seed image decryption key from secret %s, err: %w", ibu.Spec.SeedImageRef.DecryptionKeySecretRef.Name, err)
+		}
+
+		decryptionKeyFilename := filepath.Join(utils.IBUWorkspacePath, "seed-decryption-key")
+		if err := os.WriteFile(common.PathOutsideChroot(decryptionKeyFilename), []byte(decryptionKey), 0o600); err != nil {
+			return fmt.Errorf("failed to write seed image decryption key to file %s, err: %w", decryptionKeyFilename, err)
+		}
+		defer os.Remove(common.PathOutsideChroot(decryptionKeyFilename))
+		pullArgs = append(pullArgs, "--decryption-key", decryptionKeyFilename)
+	}
 
-		pullSecretFilename = filepath.Join(utils.IBUWorkspacePath, "seed-pull-secret")
-		if err = os.WriteFile(common.PathOutsideChroot(pullSecretFilename), []byte(pullSecret), 0o600); err != nil {
-			err = fmt.Errorf("failed to write seed image pull-secret to file %s, err: %w", pullSecretFilename, err)
+	source, err := seedimage.ParseLocalSource(ibu.Spec.SeedImageRef.Image)
+	if err != nil {
+		return fmt.Errorf("invalid seed image: %w", err)
+	}
+	if source != nil {
+		if err := source.Load(ops, pullArgs); err != nil {
+			return fmt.Errorf("failed to load seed image: %w", err)
+		}
+		log.Info("Successfully verified and loaded local seed image", "image", ibu.Spec.SeedImageRef.Image,
+			"storageImage", source.StorageImage())
+	} else if ibu.Spec.SeedImageRef.SignatureVerification != nil {
+		if err := pullVerifiedSeedImage(c, ctx, ibu, log, ops, pullArgs); err != nil {
 			return err
 		}
-		defer os.Remove(common.PathOutsideChroot(pullSecretFilename))
+		log.Info("Successfully verified and pulled seed image", "image", ibu.Spec.SeedImageRef.Image)
+	} else {
+		if _, err := ops.Execute("podman", append(append([]string{"pull"}, pullArgs...), ibu.Spec.SeedImageRef.Image)...); err != nil {
+			return fmt.Errorf("failed to pull image: %w", err)
+		}
+		log.Info("Successfully pulled seed image", "image", ibu.Spec.SeedImageRef.Image)
 	}
 
-	if _, err := ops.Execute("podman", "pull", "--authfile", pullSecretFilename, ibu.Spec.SeedImageRef.Image); err != nil {
-		return fmt.Errorf("failed to pull image: %w", err)
+	return pullBaseSeedImage(log, ops, seedimage.StorageImage(ibu.Spec.SeedImageRef.Image), pullArgs)
+}
+
+// writeSeedPullSecret writes the seed image pull-secret, from the spec.seedImageRef.pullSecretRef secret, to the IBU
+// workspace, returning its path on the host. The caller is responsible for removing the file.
+func writeSeedPullSecret(ctx context.Context, c client.Client, ibu *ibuv1.ImageBasedUpgrade) (string, error) {
+	pullSecret, err := lcautils.GetSecretData(ctx, ibu.Spec.SeedImageRef.PullSecretRef.Name,
+		common.LcaNamespace, corev1.DockerConfigJsonKey, c)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve pull-secret from secret %s, err: %w", ibu.Spec.SeedImageRef.PullSecretRef.Name, err)
+	}
+
+	// The workspace may not exist yet, e.g. when the seed image is inspected while Idle
+	if err := os.MkdirAll(common.PathOutsideChroot(utils.IBUWorkspacePath), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create the IBU workspace %s: %w", utils.IBUWorkspacePath, err)
 	}
-	log.Info("Successfully pulled seed image", "image", ibu.Spec.SeedImageRef.Image)
+	pullSecretFilename := filepath.Join(utils.IBUWorkspacePath, "seed-pull-secret")
+	if err = os.WriteFile(common.PathOutsideChroot(pullSecretFilename), []byte(pullSecret), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write seed image pull-secret to file %s, err: %w", pullSecretFilename, err)
+	}
+	return pullSecretFilename, nil
+}
 
+// writePrecachePullSecret writes the cluster pull-secret, merged with the seed image pull-secret from the
+// spec.seedImageRef.pullSecretRef secret, to the IBU workspace, returning its path on the host. The seed cluster
+// images may be mirrored in the seed image registry, so the precaching job needs both the credentials of the release
+// registries and of the seed image registry. The global pull-secret is left as is, and the file is removed along with
+// the IBU workspace.
+func writePrecachePullSecret(ctx context.Context, c client.Client, ibu *ibuv1.ImageBasedUpgrade) (string, error) {
+	clusterPullSecret, err := lcautils.GetSecretData(ctx, common.PullSecretName, common.OpenshiftConfigNamespace,
+		corev1.DockerConfigJsonKey, c)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve the cluster pull-secret: %w", err)
+	}
+	seedPullSecret, err := lcautils.GetSecretData(ctx, ibu.Spec.SeedImageRef.PullSecretRef.Name,
+		common.LcaNamespace, corev1.DockerConfigJsonKey, c)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve pull-secret from secret %s, err: %w", ibu.Spec.SeedImageRef.PullSecretRef.Name, err)
+	}
+	merged, err := clusterconfig.MergePullSecrets(clusterPullSecret, seedPullSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge the seed image pull-secret from secret %s: %w", ibu.Spec.SeedImageRef.PullSecretRef.Name, err)
+	}
+
+	if err := os.WriteFile(common.PathOutsideChroot(precachePullSecretFile), []byte(merged), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write precache pull-secret to file %s, err: %w", precachePullSecretFile, err)
+	}
+	return precachePullSecretFile, nil
+}
+
+// pullBaseSeedImage pulls the base seed image of a layered seed image, with the same credentials as the seed image, as
+// the ostree repo of the new stateroot is assembled from both
+func pullBaseSeedImage(log logr.Logger, ops ops.Execute, seedImage string, pullArgs []string) error {
+	inspectRaw, err := ops.Execute("podman", "image", "inspect", "--format", "json", seedImage)
+	if err != nil {
+		return fmt.Errorf("failed to inspect seed image: %w", err)
+	}
+	baseSeedImage, err := prep.ParseBaseSeedImage(inspectRaw)
+	if err != nil {
+		return fmt.Errorf("failed to get the base seed image: %w", err)
+	}
+	if baseSeedImage == "" {
+		return nil
+	}
+
+	if _, err := ops.Execute("podman", append(append([]string{"pull"}, pullArgs...), baseSeedImage)...); err != nil {
+		return fmt.Errorf("failed to pull the base seed image %s: %w", baseSeedImage, err)
+	}
+	log.Info("Successfully pulled the base seed image of the layered seed image", "image", baseSeedImage)
 	return nil
 }
 
-// validateSeedImageConfig retrieves the labels for the seed image without downloading the image itself, then validates
-// the config data from the labels
-func (r *ImageBasedUpgradeReconciler) validateSeedImageConfig(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) error {
-	labels, err := r.getLabelsForSeedImage(ctx, ibu)
+// pullVerifiedSeedImage pulls the seed image into the container storage with skopeo, enforcing a signature policy
+// that requires a valid sigstore signature for the seed image repository. When the signature is rejected, the reason
+// is recorded in prep.SeedSignatureFailureFile.
+func pullVerifiedSeedImage(c client.Client, ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, log logr.Logger, ops ops.Execute, pullArgs []string) error {
+	failureFile := common.PathOutsideChroot(prep.SeedSignatureFailureFile)
+	if err := os.Remove(failureFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", failureFile, err)
+	}
+
+	policy, err := prep.GetSeedSignaturePolicy(ctx, c, ibu)
 	if err != nil {
-		return fmt.Errorf("failed to get seed image labels: %w", err)
+		return fmt.Errorf("failed to get seed image signature policy: %w", err)
 	}
 
+	policyFile, registriesDir, err := prep.WriteSeedSignatureConfig(policy, utils.IBUWorkspacePath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(common.PathOutsideChroot(policyFile))
+	defer os.RemoveAll(common.PathOutsideChroot(registriesDir))
+
+	image := strings.TrimPrefix(ibu.Spec.SeedImageRef.Image, "docker://")
+	log.Info("Pulling seed image with signature verification", "image", image)
+	copyArgs := []string{"--policy", policyFile, "--registries.d", registriesDir, "copy", "--retry-times", "3"}
+	copyArgs = append(copyArgs, pullArgs...)
+	if _, err := ops.Execute("skopeo", append(copyArgs, "docker://"+image, "containers-storage:"+image)...); err != nil {
+		if prep.IsSignatureRejectedError(err) {
+			if writeErr := os.WriteFile(failureFile, []byte(err.Error()), 0o600); writeErr != nil {
+				log.Error(writeErr, "failed to record seed image signature verification failure")
+			}
+			return fmt.Errorf("seed image signature verification failed: %w", err)
+		}
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+
+	return nil
+}
+
+// validateSeedImageConfig validates the config data from the labels of the seed image
+func (r *ImageBasedUpgradeReconciler) validateSeedImageConfig(ctx context.Context, labels map[string]string) error {
 	r.Log.Info("Checking seed image version compatibility")
 	if err := checkSeedImageVersionCompatibility(labels); err != nil {
 		return fmt.Errorf("checking seed image compatibility: %w", err)
 	}
 
-	seedInfo, err := getSeedConfigFromLabel(labels)
+	seedInfo, err := seedimage.SeedClusterInfoFromLabels(labels)
 	if err != nil {
 		return fmt.Errorf("failed to get seed cluster info from label: %w", err)
 	}
@@ -115,9 +252,11 @@ func (r *ImageBasedUpgradeReconciler) validateSeedImageConfig(ctx context.Contex
 		return fmt.Errorf("failed to check if cluster has proxy: %w", err)
 	}
 
-	r.Log.Info("Checking seed image proxy compatibility")
-	if err := checkSeedImageProxyCompatibility(seedHasProxy, clusterHasProxy); err != nil {
-		return fmt.Errorf("checking seed image compatibility: %w", err)
+	// A proxy added or removed by the upgrade is reconfigured after the pivot
+	proxyReconfigured := seedHasProxy != clusterHasProxy
+	if proxyReconfigured {
+		r.Log.Info("Seed image and cluster proxy configurations differ, the proxy is reconfigured after the pivot",
+			"seedHasProxy", seedHasProxy, "clusterHasProxy", clusterHasProxy)
 	}
 
 	clusterHasFIPS, err := lcautils.HasFIPS(ctx, r.Client)
@@ -137,7 +276,8 @@ func (r *ImageBasedUpgradeReconciler) validateSeedImageConfig(ctx context.Contex
 
 	//nolint:staticcheck //lint:ignore SA9003 // else branch intentionally left empty
 	if seedInfo != nil && seedInfo.AdditionalTrustBundle != nil {
-		if err := checkSeedImageAdditionalTrustBundleCompatibility(*seedInfo.AdditionalTrustBundle, hasUserCaBundle, proxyConfigmapName); err != nil {
+		if err := checkSeedImageAdditionalTrustBundleCompatibility(*seedInfo.AdditionalTrustBundle, hasUserCaBundle, proxyConfigmapName,
+			proxyReconfigured); err != nil {
 			return fmt.Errorf("checking seed image additional trust bundle compatibility: %w", err)
 		}
 	} else {
@@ -155,67 +295,161 @@ func (r *ImageBasedUpgradeReconciler) validateSeedImageConfig(ctx context.Contex
 		}
 	}
 
+	// Older seed images do not record their node IPs, in which case a mismatch fails the post-pivot reconfiguration
+	if seedInfo != nil && len(seedInfo.NodeIPs) > 0 {
+		clusterIPs, err := lcautils.GetNodeInternalIPs(ctx, r.Client)
+		if err != nil {
+			return fmt.Errorf("failed to get the cluster node IPs: %w", err)
+		}
+		r.Log.Info("Checking seed image IP family compatibility")
+		if err := checkSeedImageIPFamilyCompatibility(seedInfo.NodeIPs, clusterIPs); err != nil {
+			return fmt.Errorf("checking seed image compatibility: %w", err)
+		}
+		if common.IsIPv6OnlyConversion(seedInfo.NodeIPs, clusterIPs) {
+			r.Log.Info("The seed image has IPv4 node IPs, the cluster is converted to IPv6-only after the pivot",
+				"seedNodeIPs", seedInfo.NodeIPs, "clusterNodeIPs", clusterIPs)
+		}
+	}
+
+	r.Log.Info("Checking seed image container storage configuration")
+	if err := r.checkSeedImageContainerStorageConfig(seedInfo); err != nil {
+		return fmt.Errorf("checking seed image container storage configuration: %w", err)
+	}
+
 	return nil
 }
 
-func getSeedConfigFromLabel(labels map[string]string) (*seedclusterinfo.SeedClusterInfo, error) {
-	seedFormatLabelValue, ok := labels[common.SeedClusterInfoOCILabel]
-	if !ok {
-		return nil, nil
+// checkSeedImageContainerStorageConfig checks that the container storage configuration of the seed can be migrated to
+// the one of the cluster, which is applied to the new stateroot as the precached images are stored with it
+func (r *ImageBasedUpgradeReconciler) checkSeedImageContainerStorageConfig(seedInfo *seedclusterinfo.SeedClusterInfo) error {
+	clusterStorage, err := prep.ReadContainerStorageConfig(common.PathOutsideChroot(prep.StorageConfFile))
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster container storage configuration: %w", err)
 	}
-
-	var seedInfo seedclusterinfo.SeedClusterInfo
-	if err := json.Unmarshal([]byte(seedFormatLabelValue), &seedInfo); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal seed cluster info: %w", err)
+	migration, err := prep.PlanContainerStorageMigration(clusterStorage, common.ContainerStoragePath)
+	if err != nil {
+		return fmt.Errorf("container storage cannot be migrated: %w", err)
 	}
 
-	return &seedInfo, nil
+	// Older seed images do not record their container storage configuration, which is then only reconciled when the
+	// new stateroot is set up
+	if seedInfo != nil && seedInfo.ContainerStorage != nil {
+		if differences := prep.ContainerStorageDifferences(seedInfo.ContainerStorage, &migration.Config); len(differences) > 0 {
+			r.Log.Info("Seed image and cluster container storage configurations differ, the configuration of the cluster is set in the new stateroot",
+				"changes", differences)
+		}
+	}
+	if migration.RelocateFrom != "" {
+		r.Log.Info("The cluster graph root is not on the shared container storage, the precached images are relocated",
+			"from", migration.RelocateFrom, "to", migration.Config.GraphRoot)
+	}
+	return nil
 }
 
-// getLabelsForSeedImage uses skopeo inspect to retrieve the labels for the seed image without downloading the image itself
-func (r *ImageBasedUpgradeReconciler) getLabelsForSeedImage(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (map[string]string, error) {
+// inspectSeedImage uses skopeo inspect to retrieve the metadata and layers of the seed image without downloading the
+// image itself, with the pull-secret of the seed image if any
+func (r *ImageBasedUpgradeReconciler) inspectSeedImage(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (*seedimage.Inspect, error) {
 	// Use cluster wide pull-secret by default
 	pullSecretFilename := common.ImageRegistryAuthFile
 
 	if ibu.Spec.SeedImageRef.PullSecretRef != nil {
-		var pullSecret string
-		pullSecret, err := lcautils.GetSecretData(ctx, ibu.Spec.SeedImageRef.PullSecretRef.Name,
-			common.LcaNamespace, corev1.DockerConfigJsonKey, r.Client)
-		if err != nil {
-			err = fmt.Errorf("failed to retrieve pull-secret from secret %s, err: %w", ibu.Spec.SeedImageRef.PullSecretRef.Name, err)
+		var err error
+		if pullSecretFilename, err = writeSeedPullSecret(ctx, r.Client, ibu); err != nil {
 			return nil, err
 		}
+		defer os.Remove(common.PathOutsideChroot(pullSecretFilename))
+	}
 
-		pullSecretFilename = filepath.Join(utils.IBUWorkspacePath, "seed-pull-secret")
-		if err = os.WriteFile(common.PathOutsideChroot(pullSecretFilename), []byte(pullSecret), 0o600); err != nil {
-			err = fmt.Errorf("failed to write seed image pull-secret to file %s, err: %w", pullSecretFilename, err)
-			return nil, err
+	//nolint:wrapcheck
+	return seedimage.InspectImage(r.Executor, ibu.Spec.SeedImageRef.Image, pullSecretFilename)
+}
+
+// Backoff of the seed image inspection retries while Idle
+const (
+	seedImageInspectInitialBackoff = 30 * time.Second
+	seedImageInspectMaxBackoff     = 10 * time.Minute
+)
+
+// seedImageInspectFailure records the failed inspections of a seed image, so that the blocking inspection is not
+// retried on every reconcile
+type seedImageInspectFailure struct {
+	image      string
+	backoff    time.Duration
+	retryAfter time.Time
+}
+
+// updateSeedImageInfo reports the metadata of the seed image in the IBU status, inspecting the seed image once per
+// seedImageRef. The metadata is only informative, so an inspection failure is logged and the inspection is retried
+// with an exponential backoff, or as soon as the seedImageRef changes.
+func (r *ImageBasedUpgradeReconciler) updateSeedImageInfo(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) {
+	image := ibu.Spec.SeedImageRef.Image
+	if image == "" {
+		ibu.Status.SeedImageInfo = nil
+		r.seedImageInspectFailure = nil
+		return
+	}
+	if ibu.Status.SeedImageInfo != nil && ibu.Status.SeedImageInfo.Image == image {
+		return
+	}
+	failure := r.seedImageInspectFailure
+	if failure != nil && failure.image == image && time.Now().Before(failure.retryAfter) {
+		return
+	}
+
+	seedImage, err := r.inspectSeedImage(ctx, ibu)
+	if err != nil {
+		if failure == nil || failure.image != image {
+			failure = &seedImageInspectFailure{image: image, backoff: seedImageInspectInitialBackoff}
+		} else {
+			failure.backoff = min(2*failure.backoff, seedImageInspectMaxBackoff)
 		}
-		defer os.Remove(common.PathOutsideChroot(pullSecretFilename))
+		failure.retryAfter = time.Now().Add(failure.backoff)
+		r.seedImageInspectFailure = failure
+		r.Log.Error(err, "failed to inspect the seed image", "image", image, "backoff", failure.backoff)
+		ibu.Status.SeedImageInfo = nil
+		return
 	}
+	r.seedImageInspectFailure = nil
+	r.setSeedImageInfo(ibu, seedImage)
+}
 
-	inspectArgs := []string{
-		"inspect",
-		"--retry-times", "10",
-		"--authfile", pullSecretFilename,
-		"--format", "json",
-		"docker://" + ibu.Spec.SeedImageRef.Image,
+// setSeedImageInfo reports the metadata of the inspected seed image in the IBU status
+func (r *ImageBasedUpgradeReconciler) setSeedImageInfo(ibu *ibuv1.ImageBasedUpgrade, seedImage *seedimage.Inspect) {
+	info, err := seedImage.Info(ibu.Spec.SeedImageRef.Image)
+	if err != nil {
+		r.Log.Error(err, "failed to get the seed image info", "image", ibu.Spec.SeedImageRef.Image)
+		ibu.Status.SeedImageInfo = nil
+		return
 	}
+	r.Log.Info("Seed image inspected", "image", info.Image, "digest", info.Digest, "ocpVersion", info.OCPVersion)
+	ibu.Status.SeedImageInfo = info
+}
 
-	var inspect struct {
-		Labels map[string]string `json:"Labels"`
+// validateDiskSpace checks that there is enough disk space for the new stateroot and the precached images, based on
+// the size of the seed image and of the seed cluster container images
+func (r *ImageBasedUpgradeReconciler) validateDiskSpace(ibu *ibuv1.ImageBasedUpgrade, seedImage *seedimage.Inspect) error {
+	if ibu.Spec.DiskSpaceValidation != nil && ibu.Spec.DiskSpaceValidation.Disabled {
+		r.Log.Info("Disk space validation is disabled")
+		return nil
 	}
 
-	// TODO: use the context when execute supports it
-	if inspectRaw, err := r.Executor.Execute("skopeo", inspectArgs...); err != nil || inspectRaw == "" {
-		return nil, fmt.Errorf("failed to inspect image: %w", err)
-	} else {
-		if err := json.Unmarshal([]byte(inspectRaw), &inspect); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal image inspect output: %w", err)
-		}
+	seedInfo, err := seedimage.SeedClusterInfoFromLabels(seedImage.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to get seed cluster info from label: %w", err)
+	}
+	var containerImagesSize int64
+	if seedInfo != nil {
+		// Older images may not record the size of the container images, in which case only the seed image size is
+		// accounted for
+		containerImagesSize = seedInfo.ContainerImagesSize
 	}
 
-	return inspect.Labels, nil
+	requirements := prep.GetDiskSpaceRequirements(seedImage.Size(), containerImagesSize)
+	r.Log.Info("Checking disk space", "requirements", requirements)
+	if err := prep.ValidateDiskSpace(requirements, ibu.Spec.DiskSpaceValidation); err != nil {
+		return fmt.Errorf("disk space validation failed: %w", err)
+	}
+	return nil
 }
 
 // checkSeedImageVersionCompatibility checks if the seed image is compatible with the
@@ -246,31 +480,14 @@ func checkSeedImageVersionCompatibility(labels map[string]string) error {
 	return nil
 }
 
-// checkSeedImageProxyCompatibility checks for proxy configuration
-// compatibility of the seed image vs the current cluster. If the seed image
-// has a proxy and the cluster being upgraded doesn't, we cannot proceed as
-// recert does not support proxy rename under those conditions. Similarly, we
-// cannot proceed if the cluster being upgraded has a proxy but the seed image
-// doesn't.
-func checkSeedImageProxyCompatibility(seedHasProxy, hasProxy bool) error {
-	if seedHasProxy && !hasProxy {
-		return fmt.Errorf("seed image has a proxy but the cluster being upgraded does not, this combination is not supported")
-	}
-
-	if !seedHasProxy && hasProxy {
-		return fmt.Errorf("seed image does not have a proxy but the cluster being upgraded does, this combination is not supported")
-	}
-
-	return nil
-}
-
-// checkSeedImageAdditionalTrustBundleCompatibility checks for proxy
-// configuration compatibility of the seed image vs the current cluster. If the
-// seed image has a proxy and the cluster being upgraded doesn't, we cannot
-// proceed as recert does not support proxy rename under those conditions.
-// Similarly, we cannot proceed if the cluster being upgraded has a proxy but
-// the seed image doesn't.
-func checkSeedImageAdditionalTrustBundleCompatibility(seedAdditionalTrustBundle seedclusterinfo.AdditionalTrustBundle, hasUserCaBundle bool, proxyConfigmapName string) error {
+// checkSeedImageAdditionalTrustBundleCompatibility checks for additional trust
+// bundle configuration compatibility of the seed image vs the current cluster.
+// Recert does not support adding or removing the user-ca-bundle configmap, so
+// both must have it or not. The Proxy trustedCA configmap name must match too,
+// unless the proxy is reconfigured after the pivot, in which case its
+// trustedCA is set along with it.
+func checkSeedImageAdditionalTrustBundleCompatibility(seedAdditionalTrustBundle seedclusterinfo.AdditionalTrustBundle, hasUserCaBundle bool, proxyConfigmapName string,
+	proxyReconfigured bool) error {
 	if seedAdditionalTrustBundle.HasUserCaBundle && !hasUserCaBundle {
 		return fmt.Errorf("seed image has an %s/%s configmap but the cluster being upgraded does not, this combination is not supported",
 			common.OpenshiftConfigNamespace, common.ClusterAdditionalTrustBundleName)
@@ -281,7 +498,7 @@ func checkSeedImageAdditionalTrustBundleCompatibility(seedAdditionalTrustBundle
 			common.OpenshiftConfigNamespace, common.ClusterAdditionalTrustBundleName)
 	}
 
-	if seedAdditionalTrustBundle.ProxyConfigmapName != proxyConfigmapName {
+	if !proxyReconfigured && seedAdditionalTrustBundle.ProxyConfigmapName != proxyConfigmapName {
 		return fmt.Errorf("seed image's Proxy trustedCA configmap name %q (oc get proxy -oyaml) mismatches cluster's name %q, this combination is not supported",
 			seedAdditionalTrustBundle.ProxyConfigmapName, proxyConfigmapName)
 	}
@@ -315,6 +532,59 @@ func checkSeedImageFIPSCompatibility(seedHasFIPS, hasFIPS bool) error {
 	return nil
 }
 
+// checkSeedImageIPFamilyCompatibility checks that the IP families of the seed image can be reconfigured to the ones
+// of the cluster being upgraded. Besides keeping the same families, a single-stack seed can be converted to
+// dual-stack, and an IPv4 or dual-stack seed can be converted to IPv6-only. Removing the IPv6 family of a seed is
+// not supported.
+func checkSeedImageIPFamilyCompatibility(seedIPs, clusterIPs []string) error {
+	seedHasIPv4, seedHasIPv6 := common.DetectClusterIPFamilies(seedIPs)
+	clusterHasIPv4, clusterHasIPv6 := common.DetectClusterIPFamilies(clusterIPs)
+
+	switch {
+	case seedHasIPv4 == clusterHasIPv4 && seedHasIPv6 == clusterHasIPv6:
+		return nil
+	case clusterHasIPv4 && clusterHasIPv6 && len(seedIPs) == 1:
+		return nil
+	case common.IsIPv6OnlyConversion(seedIPs, clusterIPs):
+		return nil
+	}
+	return fmt.Errorf("seed image IP families %s mismatch the cluster being upgraded IP families %s, this combination is not supported",
+		ipFamiliesString(seedHasIPv4, seedHasIPv6), ipFamiliesString(clusterHasIPv4, clusterHasIPv6))
+}
+
+// validateSeedImageArchitecture checks that the seed image, selected from its manifest list if any, has the
+// architecture of the node
+func (r *ImageBasedUpgradeReconciler) validateSeedImageArchitecture(ctx context.Context, seedImage *seedimage.Inspect) error {
+	// The architecture may not be reported by the registry, in which case a mismatch fails the new stateroot setup
+	if seedImage.Architecture == "" {
+		return nil
+	}
+	nodeArch, err := lcautils.GetNodeArchitecture(ctx, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to get the node architecture: %w", err)
+	}
+	r.Log.Info("Checking seed image architecture compatibility", "seedArchitecture", seedImage.Architecture, "nodeArchitecture", nodeArch)
+	return checkSeedImageArchitecture(seedImage.Architecture, nodeArch)
+}
+
+func checkSeedImageArchitecture(seedArch, nodeArch string) error {
+	if nodeArch == "" || seedArch == nodeArch {
+		return nil
+	}
+	return fmt.Errorf("seed image architecture %s mismatches the node architecture %s", seedArch, nodeArch)
+}
+
+func ipFamiliesString(hasIPv4, hasIPv6 bool) string {
+	var families []string
+	if hasIPv4 {
+		families = append(families, common.IPv4FamilyName)
+	}
+	if hasIPv6 {
+		families = append(families, common.IPv6FamilyName)
+	}
+	return fmt.Sprintf("%v", families)
+}
+
 // validateSeedOcpVersion rejects upgrade request if seed image version is not higher than current cluster (target) OCP version
 func (r *ImageBasedUpgradeReconciler) validateSeedOcpVersion(seedOcpVersion string) error {
 	// get target OCP version
@@ -366,11 +636,13 @@ func (r *ImageBasedUpgradeReconciler) getPodEnvVars(ctx context.Context) (envVar
 	return
 }
 
-func (r *ImageBasedUpgradeReconciler) launchPrecaching(ctx context.Context, imageListFile string, ibu *ibuv1.ImageBasedUpgrade) error {
+// getPrecachingList returns the images to precache, i.e. the images of the seed with the registry of the cluster when
+// the seed registry is overridden
+func (r *ImageBasedUpgradeReconciler) getPrecachingList(ctx context.Context, imageListFile string, ibu *ibuv1.ImageBasedUpgrade) ([]string, error) {
 	r.Log.Info("Getting release registry name")
 	clusterRegistry, err := lcautils.GetReleaseRegistry(ctx, r.Client)
 	if err != nil {
-		return fmt.Errorf("failed to get cluster registry: %w", err)
+		return nil, fmt.Errorf("failed to get cluster registry: %w", err)
 	}
 	r.Log.Info("Got release registry name", "clusterRegistry", clusterRegistry)
 
@@ -378,7 +650,7 @@ func (r *ImageBasedUpgradeReconciler) launchPrecaching(ctx context.Context, imag
 	seedInfo, err := seedclusterinfo.ReadSeedClusterInfoFromFile(
 		common.PathOutsideChroot(getSeedManifestPath(common.GetDesiredStaterootName(ibu))))
 	if err != nil {
-		return fmt.Errorf("failed to read seed info: %w", err)
+		return nil, fmt.Errorf("failed to read seed info: %w", err)
 	}
 	// TODO: if seedInfo.hasProxy we also require that the LCA deployment contain "NO_PROXY" + "HTTP_PROXY" + "HTTPS_PROXY" as env vars. Produce a warning and/or document this.
 	r.Log.Info("Collected seed info for precache", "seed info", fmt.Sprintf("%+v", seedInfo))
@@ -386,22 +658,30 @@ func (r *ImageBasedUpgradeReconciler) launchPrecaching(ctx context.Context, imag
 	r.Log.Info("Getting mirror registry source registries from cluster")
 	mirrorRegistrySources, err := lcautils.GetMirrorRegistrySourceRegistries(ctx, r.Client)
 	if err != nil {
-		return fmt.Errorf("failed to get mirror registry source registries from cluster %w", err)
+		return nil, fmt.Errorf("failed to get mirror registry source registries from cluster %w", err)
 	}
 
 	r.Log.Info("Checking whether to override seed registry")
 	shouldOverrideRegistry, err := lcautils.ShouldOverrideSeedRegistry(seedInfo.MirrorRegistryConfigured, seedInfo.ReleaseRegistry, mirrorRegistrySources)
 	if err != nil {
-		return fmt.Errorf("failed to check ShouldOverrideSeedRegistry %w", err)
+		return nil, fmt.Errorf("failed to check ShouldOverrideSeedRegistry %w", err)
 	}
 	r.Log.Info("Override registry status", "shouldOverrideRegistry", shouldOverrideRegistry)
 
 	r.Log.Info("Reading precache list from seed image")
 	imageList, err := prep.ReadPrecachingList(imageListFile, clusterRegistry, seedInfo.ReleaseRegistry, shouldOverrideRegistry)
 	if err != nil {
-		return fmt.Errorf("failed to read pre-caching image file: %s, %w", common.PathOutsideChroot(imageListFile), err)
+		return nil, fmt.Errorf("failed to read pre-caching image file: %s, %w", common.PathOutsideChroot(imageListFile), err)
 	}
 	r.Log.Info("Got pre-cache image list from seed image", "count", len(imageList))
+	return imageList, nil
+}
+
+func (r *ImageBasedUpgradeReconciler) launchPrecaching(ctx context.Context, imageListFile string, ibu *ibuv1.ImageBasedUpgrade) error {
+	imageList, err := r.getPrecachingList(ctx, imageListFile, ibu)
+	if err != nil {
+		return err
+	}
 
 	r.Log.Info("Getting env variables from lca manager for precache job")
 	envVars, err := r.getPodEnvVars(ctx)
@@ -410,7 +690,39 @@ func (r *ImageBasedUpgradeReconciler) launchPrecaching(ctx context.Context, imag
 	}
 
 	r.Log.Info("Creating pre-cache config and resources")
-	config := precache.NewConfig(imageList, envVars)
+	var precacheArgs []any
+	if ibu.Spec.Precache != nil {
+		if ibu.Spec.Precache.MaxConcurrentPulls > 0 {
+			precacheArgs = append(precacheArgs, "NumConcurrentPulls", ibu.Spec.Precache.MaxConcurrentPulls)
+		}
+		if ibu.Spec.Precache.PullRetries > 0 {
+			precacheArgs = append(precacheArgs, "NumPullRetries", ibu.Spec.Precache.PullRetries)
+		}
+		if ibu.Spec.Precache.PullTimeoutSeconds > 0 {
+			precacheArgs = append(precacheArgs, "PullTimeoutSeconds", ibu.Spec.Precache.PullTimeoutSeconds)
+		}
+		if ibu.Spec.Precache.NicePriority != nil {
+			precacheArgs = append(precacheArgs, "NicePriority", *ibu.Spec.Precache.NicePriority)
+		}
+		if ibu.Spec.Precache.IoNiceClass != nil {
+			precacheArgs = append(precacheArgs, "IoNiceClass", *ibu.Spec.Precache.IoNiceClass)
+		}
+		if ibu.Spec.Precache.IoNicePriority != nil {
+			precacheArgs = append(precacheArgs, "IoNicePriority", *ibu.Spec.Precache.IoNicePriority)
+		}
+		if ibu.Spec.Precache.Resources != nil {
+			precacheArgs = append(precacheArgs, "Resources", ibu.Spec.Precache.Resources)
+		}
+	}
+	if ibu.Spec.SeedImageRef.PullSecretRef != nil {
+		r.Log.Info("Using the seed image pull-secret for pre-caching", "secret", ibu.Spec.SeedImageRef.PullSecretRef.Name)
+		pullSecretFile, err := writePrecachePullSecret(ctx, r.Client, ibu)
+		if err != nil {
+			return err
+		}
+		precacheArgs = append(precacheArgs, "PullSecretFile", pullSecretFile)
+	}
+	config := precache.NewConfig(imageList, envVars, precacheArgs...)
 	if err := r.Precache.CreateJobAndConfigMap(ctx, config, ibu); err != nil {
 		return fmt.Errorf("failed to create precaching job: %w", err)
 	}
@@ -461,6 +773,67 @@ func (r *ImageBasedUpgradeReconciler) validateIBUSpec(ctx context.Context, ibu *
 		}
 	}
 
+	// Validate the local source of the seed image if it is delivered as a file on the host
+	source, err := seedimage.ParseLocalSource(ibu.Spec.SeedImageRef.Image)
+	if err != nil {
+		return fmt.Errorf("invalid seed image: %w", err)
+	}
+	if source != nil && ibu.Spec.SeedImageRef.SignatureVerification != nil {
+		return fmt.Errorf("signature verification is not supported with the local seed image %s, its digest is verified instead",
+			ibu.Spec.SeedImageRef.Image)
+	}
+
+	// Validate the seed image signature verification keys if the verification is enabled
+	if ibu.Spec.SeedImageRef.SignatureVerification != nil {
+		if _, err := prep.GetSeedSignaturePolicy(ctx, r.Client, ibu); err != nil {
+			return fmt.Errorf("failed to validate seed image signature verification: %w", err)
+		}
+	}
+
+	// Validate the seed image decryption key if the seed image is encrypted
+	if ibu.Spec.SeedImageRef.DecryptionKeySecretRef != nil {
+		if _, err := lcautils.GetSecretData(ctx, ibu.Spec.SeedImageRef.DecryptionKeySecretRef.Name,
+			common.LcaNamespace, prep.SeedDecryptionKeySecretKey, r.Client); err != nil {
+			return fmt.Errorf("failed to get seed image decryption key: %w", err)
+		}
+	}
+
+	// Validate the mirror registry credentials if they are provided
+	if mirrorConfig := ibu.Spec.MirrorRegistryConfig; mirrorConfig != nil && mirrorConfig.CredentialsSecretRef != nil {
+		credentials, err := lcautils.GetSecretData(ctx, mirrorConfig.CredentialsSecretRef.Name,
+			common.LcaNamespace, corev1.DockerConfigJsonKey, r.Client)
+		if err != nil {
+			return fmt.Errorf("failed to get mirror registry credentials: %w", err)
+		}
+		if _, err := clusterconfig.MergePullSecrets("{}", credentials); err != nil {
+			return fmt.Errorf("failed to validate mirror registry credentials: %w", err)
+		}
+	}
+
+	// Validate the custom CAs and preserved certificates of the certificate regeneration if they are provided
+	if _, err := clusterconfig.GetCertificateRegeneration(ctx, r.Client, ibu.Spec.CertificateRegeneration); err != nil {
+		return fmt.Errorf("failed to validate certificate regeneration: %w", err)
+	}
+
+	// Validate the additional trusted CA bundles if they are provided
+	if _, err := clusterconfig.GetAdditionalCABundles(ctx, r.Client, ibu.Spec.AdditionalTrustedCABundles); err != nil {
+		return fmt.Errorf("failed to validate additional trusted CA bundles: %w", err)
+	}
+
+	// Validate the user-defined health checks configmaps if they are provided
+	if len(ibu.Spec.HealthChecks) != 0 {
+		if err := healthcheck.ValidateCustomHealthCheckConfigmaps(ctx, r.Client, ibu.Spec.HealthChecks); err != nil {
+			return fmt.Errorf("failed to validate health checks cms: %w", err)
+		}
+	}
+
+	// Validate the preserved paths configmaps if they are provided
+	if len(ibu.Spec.PreservedPaths) != 0 {
+		if err := preservedpaths.ValidatePreservedPathsConfigmaps(ctx, r.Client, ibu.Spec.PreservedPaths); err != nil {
+			return fmt.Errorf("failed to validate preserved paths cms: %w", err)
+		}
+	}
+
 	// Validate the manifests from policies if related annotations are specified
 	var validationAnns = map[string]string{}
 	if count, exists := ibu.GetAnnotations()[extramanifest.TargetOcpVersionManifestCountAnnotation]; exists {
@@ -497,10 +870,16 @@ func initIBUWorkspaceDir() error {
 
 // Cleanup container storage, if needed
 func (r *ImageBasedUpgradeReconciler) containerStorageCleanup(ibu *ibuv1.ImageBasedUpgrade) error {
+	return cleanupContainerStorage(r.Log, r.ImageMgmtClient, ibu)
+}
+
+// cleanupContainerStorage removes the unused images when the container storage disk usage exceeds the threshold set
+// by the IBU annotations
+func cleanupContainerStorage(log logr.Logger, imageMgmtClient imagemgmt.ImageMgmtIntf, ibu *ibuv1.ImageBasedUpgrade) error {
 	// Check whether image cleanup is disabled using annotation
 	if val, exists := ibu.GetAnnotations()[common.ImageCleanupOnPrepAnnotation]; exists {
 		if val == common.ImageCleanupDisabledValue {
-			r.Log.Info("Automatic image cleanup is disabled")
+			log.Info("Automatic image cleanup is disabled")
 			return nil
 		}
 	}
@@ -511,28 +890,89 @@ func (r *ImageBasedUpgradeReconciler) containerStorageCleanup(ibu *ibuv1.ImageBa
 	if val, exists := ibu.GetAnnotations()[common.ContainerStorageUsageThresholdPercentAnnotation]; exists {
 		var err error
 		if thresholdPercent, err = strconv.Atoi(val); err != nil {
-			r.Log.Error(err, "Failed to parse threshold value from annotation", "value", val)
-			r.Log.Info("Automatic image cleanup is disabled, due to failure to parse threshold annotation")
+			log.Error(err, "Failed to parse threshold value from annotation", "value", val)
+			log.Info("Automatic image cleanup is disabled, due to failure to parse threshold annotation")
 			return nil
 		}
 	}
 
 	// Check container storage disk usage
-	if exceeded, err := r.ImageMgmtClient.CheckDiskUsageAgainstThreshold(thresholdPercent); err != nil {
+	if exceeded, err := imageMgmtClient.CheckDiskUsageAgainstThreshold(thresholdPercent); err != nil {
 		return fmt.Errorf("failed to check container storage disk usage: %w", err)
 	} else if !exceeded {
 		// Container storage disk usage is within threshold
 		return nil
 	}
 
-	r.Log.Info("Performing automatic image cleanup to reduce container storage disk usage to be within threshold")
-	if err := r.ImageMgmtClient.CleanupUnusedImages(thresholdPercent); err != nil {
+	log.Info("Performing automatic image cleanup to reduce container storage disk usage to be within threshold")
+	if err := imageMgmtClient.CleanupUnusedImages(thresholdPercent); err != nil {
 		return fmt.Errorf("failed during image cleanup: %w", err)
 	}
 
 	return nil
 }
 
+// prepValidateOnly completes a validate-only Prep. All the spec and seed image validations have passed by the time
+// this is called, so only the disk space and container storage disk usage are checked. No image cleanup is done in
+// this mode, unless the seed image content is validated: the stateroot setup job is then launched to pull and
+// validate the seed image, once the container storage is cleaned up and the disk space validated as for a Prep, and
+// the Prep completes with the job.
+func (r *ImageBasedUpgradeReconciler) prepValidateOnly(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, seedImage *seedimage.Inspect) (ctrl.Result, error) {
+	if ibu.Spec.ValidateSeedContent {
+		r.Log.Info("Checking container storage disk space")
+		if err := r.containerStorageCleanup(ibu); err != nil {
+			return requeueWithError(fmt.Errorf("failed container storage cleanup: %w", err))
+		}
+
+		r.Log.Info("Validating disk space")
+		if err := r.validateDiskSpace(ibu, seedImage); err != nil {
+			return prepFailDoNotRequeue(r.Log, err.Error(), ibu)
+		}
+
+		r.Log.Info("Launching a new stateroot job to validate the seed image content")
+		if _, err := prep.LaunchStaterootSetupJob(ctx, r.Client, ibu, r.Scheme, r.Log); err != nil {
+			return requeueWithError(fmt.Errorf("failed launch stateroot job: %w", err))
+		}
+		utils.SetStageProgress(ibu, "Validating seed image content", 50)
+		return prepInProgressRequeue(r.Log, "Successfully launched a new job for the seed image content validation", ibu)
+	}
+
+	msg := "Prep validation completed successfully"
+
+	thresholdPercent := common.ContainerStorageUsageThresholdPercentDefault
+	if val, exists := ibu.GetAnnotations()[common.ContainerStorageUsageThresholdPercentAnnotation]; exists {
+		if override, err := strconv.Atoi(val); err == nil {
+			thresholdPercent = override
+		}
+	}
+
+	r.Log.Info("Checking container storage disk space")
+	exceeded, err := r.ImageMgmtClient.CheckDiskUsageAgainstThreshold(thresholdPercent)
+	if err != nil {
+		return prepFailDoNotRequeue(r.Log, fmt.Sprintf("failed to check container storage disk usage: %s", err.Error()), ibu)
+	}
+	if exceeded {
+		msg = fmt.Sprintf("%s. Container storage disk usage exceeds %d%%, unused images will be removed when Prep runs", msg, thresholdPercent)
+	} else if err := r.validateDiskSpace(ibu, seedImage); err != nil {
+		// The space freed by the image cleanup is unknown, so the disk space is only validated when no cleanup is needed
+		return prepFailDoNotRequeue(r.Log, err.Error(), ibu)
+	}
+	return r.completePrepValidateOnly(ibu, msg)
+}
+
+// completePrepValidateOnly marks a validate-only Prep as completed
+func (r *ImageBasedUpgradeReconciler) completePrepValidateOnly(ibu *ibuv1.ImageBasedUpgrade, msg string) (ctrl.Result, error) {
+	if _, exists := ibu.GetAnnotations()[extramanifest.ValidationWarningAnnotation]; exists {
+		msg = fmt.Sprintf("%s. Please check the annotation '%s' for extramanifests validation warning details", msg, extramanifest.ValidationWarningAnnotation)
+	}
+
+	r.Log.Info(msg)
+	utils.StopStageHistory(r.Client, r.Log, ibu)
+	utils.SetPrepStatusValidated(ibu, msg)
+	utils.SetStageProgress(ibu, "Prep validation completed", 100)
+	return doNotRequeue(), nil
+}
+
 // Used to start and end phases in the Prep stage. Each of them must be used in exactly two places
 var (
 	PrepPhaseStateroot = "Stateroot"
@@ -542,10 +982,13 @@ var (
 // handlePrep the main func to run prep stage
 func (r *ImageBasedUpgradeReconciler) handlePrep(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
 	r.Log.Info("Running health check for Prep")
-	if err := CheckHealth(ctx, r.NoncachedClient, r.Log.WithName("HealthCheck")); err != nil {
+	err := CheckHealth(ctx, r.NoncachedClient, r.Log.WithName("HealthCheck"), healthCheckOptions(ibu)...)
+	r.Progress.RecordHealthCheck(progress.PlatformHealthCheck, err)
+	if err != nil {
 		msg := fmt.Sprintf("Waiting for system to stabilize before Prep stage can continue: %s", err.Error())
 		r.Log.Info(msg)
 		utils.SetPrepStatusInProgress(ibu, msg)
+		utils.SetStageProgress(ibu, "Waiting for system to stabilize", 0)
 		return requeueWithHealthCheckInterval(), nil
 	}
 	r.Log.Info("Cluster is healthy")
@@ -571,21 +1014,40 @@ func (r *ImageBasedUpgradeReconciler) handlePrep(ctx context.Context, ibu *ibuv1
 
 			// Validate config information from the seed image labels, prior to launching the job and downloading the image
 			r.Log.Info("Validating seed information")
-			if err := r.validateSeedImageConfig(ctx, ibu); err != nil {
+			seedImage, err := r.inspectSeedImage(ctx, ibu)
+			if err != nil {
+				return prepFailDoNotRequeue(r.Log, fmt.Sprintf("failed to validate seed image info: failed to get seed image labels: %s", err.Error()), ibu)
+			}
+			r.setSeedImageInfo(ibu, seedImage)
+			if err := r.validateSeedImageConfig(ctx, seedImage.Labels); err != nil {
 				return prepFailDoNotRequeue(r.Log, fmt.Sprintf("failed to validate seed image info: %s", err.Error()), ibu)
 			}
+			if err := r.validateSeedImageArchitecture(ctx, seedImage); err != nil {
+				return prepFailDoNotRequeue(r.Log, fmt.Sprintf("failed to validate seed image info: %s", err.Error()), ibu)
+			}
+
+			if ibu.Spec.ValidateOnly {
+				return r.prepValidateOnly(ctx, ibu, seedImage)
+			}
 
 			r.Log.Info("Checking container storage disk space")
 			if err := r.containerStorageCleanup(ibu); err != nil {
 				return requeueWithError(fmt.Errorf("failed container storage cleanup: %w", err))
 			}
 
+			// The disk space is validated once unused images are removed from the container storage
+			r.Log.Info("Validating disk space")
+			if err := r.validateDiskSpace(ibu, seedImage); err != nil {
+				return prepFailDoNotRequeue(r.Log, err.Error(), ibu)
+			}
+
 			r.Log.Info("Launching a new stateroot job")
 			if _, err := prep.LaunchStaterootSetupJob(ctx, r.Client, ibu, r.Scheme, r.Log); err != nil {
 				return requeueWithError(fmt.Errorf("failed launch stateroot job: %w", err))
 			}
 			// start prep stage stateroot phase timing
 			utils.StartPhase(r.Client, r.Log, ibu, PrepPhaseStateroot)
+			utils.SetStageProgress(ibu, "Setting up stateroot", 10)
 			return prepInProgressRequeue(r.Log, fmt.Sprintf("Successfully launched a new job for stateroot setup. %s", getJobMetadataString(staterootSetupJob)), ibu)
 		}
 		return requeueWithError(fmt.Errorf("failed to get stateroot setup job: %w", err))
@@ -603,10 +1065,22 @@ func (r *ImageBasedUpgradeReconciler) handlePrep(ctx context.Context, ibu *ibuv1
 	switch staterootSetupFinishedType {
 	case "":
 		common.LogPodLogs(staterootSetupJob, r.Log, r.Clientset)
+		utils.SetStageProgress(ibu, "Setting up stateroot", 20)
 		return prepInProgressRequeue(r.Log, fmt.Sprintf("Stateroot setup job in progress. %s", getJobMetadataString(staterootSetupJob)), ibu)
 	case kbatch.JobFailed:
+		if reason, err := os.ReadFile(common.PathOutsideChroot(prep.SeedSignatureFailureFile)); err == nil {
+			return prepFailDoNotRequeue(r.Log, fmt.Sprintf("seed image signature verification failed: %s", string(reason)), ibu)
+		}
+		report := &seedimage.Report{}
+		if err := lcautils.ReadYamlOrJSONFile(common.PathOutsideChroot(prep.SeedValidationReportFile), report); err == nil && !report.Passed {
+			return prepFailDoNotRequeue(r.Log, fmt.Sprintf("seed image content validation failed: %s", report.Failures()), ibu)
+		}
 		return prepFailDoNotRequeue(r.Log, fmt.Sprintf("stateroot setup job failed to complete. %s", getJobMetadataString(staterootSetupJob)), ibu)
 	case kbatch.JobComplete:
+		// the stateroot setup job of a validate-only Prep only pulls and validates the seed image
+		if ibu.Spec.ValidateOnly {
+			return r.completePrepValidateOnly(ibu, "Prep validation completed successfully. The seed image content is validated")
+		}
 		// stop prep stage stateroot phase timing
 		utils.StopPhase(r.Client, r.Log, ibu, PrepPhaseStateroot)
 		r.Log.Info("Stateroot job completed successfully", "completion time", staterootSetupJob.Status.CompletionTime, "total time", staterootSetupJob.Status.CompletionTime.Sub(staterootSetupJob.Status.StartTime.Time))
@@ -622,6 +1096,7 @@ func (r *ImageBasedUpgradeReconciler) handlePrep(ctx context.Context, ibu *ibuv1
 			}
 			// start prep stage precache phase timing
 			utils.StartPhase(r.Client, r.Log, ibu, PrepPhasePrecache)
+			utils.SetStageProgress(ibu, "Precaching images", 50)
 			return prepInProgressRequeue(r.Log, fmt.Sprintf("Successfully launched a new job precache. %s", getJobMetadataString(precacheJob)), ibu)
 		}
 		return requeueWithError(fmt.Errorf("failed to get precache job: %w", err))
@@ -634,25 +1109,108 @@ func (r *ImageBasedUpgradeReconciler) handlePrep(ctx context.Context, ibu *ibuv1
 		return prepFailDoNotRequeue(r.Log, fmt.Sprintf("precache job is marked to be deleted, this not allowed. %s", getJobMetadataString(precacheJob)), ibu)
 	}
 
+	// refresh the precaching progress reported in the status
+	if precacheStatus := precache.GetPrecacheStatus(); precacheStatus != nil {
+		ibu.Status.Precache = precacheStatus
+	}
+
 	// check .status
 	_, precacheFinishedType := common.IsJobFinished(precacheJob)
 	switch precacheFinishedType {
 	case "":
 		common.LogPodLogs(precacheJob, r.Log, r.Clientset) // pod logs
+		utils.SetStageProgress(ibu, "Precaching images", getPrecacheStageProgressPercent(ibu.Status.Precache))
 		return prepInProgressRequeue(r.Log, fmt.Sprintf("Precache job in progress. %s. %s", getJobMetadataString(precacheJob), precache.GetPrecacheStatusFileContent()), ibu)
 	case kbatch.JobFailed:
 		return prepFailDoNotRequeue(r.Log, fmt.Sprintf("precache job failed to complete. %s", getJobMetadataString(precacheJob)), ibu)
 	case kbatch.JobComplete:
 		// stop prep stage precache phase timing
 		utils.StopPhase(r.Client, r.Log, ibu, PrepPhasePrecache)
+		if precacheStatus := ibu.Status.Precache; precacheStatus != nil && precacheStatus.Failed > 0 {
+			utils.EmitEvent(r.Recorder, ibu, corev1.EventTypeWarning, utils.EventReasonPrecacheFailed,
+				fmt.Sprintf("%d of %d images could not be precached", precacheStatus.Failed, precacheStatus.Total))
+		}
 		r.Log.Info("Precache job completed successfully", "completion time", precacheJob.Status.CompletionTime, "total time", precacheJob.Status.CompletionTime.Sub(precacheJob.Status.StartTime.Time))
 	}
 
+	// The precached images are copied once, the copy being run again from scratch if interrupted
+	tracker := r.Checkpoints.For(string(ibuv1.Stages.Prep), common.GetDesiredStaterootName(ibu))
+	if err := tracker.Run("relocate_precached_images", func() error {
+		return prep.RelocatePrecachedImages(r.Log, r.Executor)
+	}); err != nil {
+		return prepFailDoNotRequeue(r.Log, fmt.Sprintf("failed to relocate the precached images: %s", err.Error()), ibu)
+	}
+
+	if err := r.verifyPrecachedImages(ctx, ibu); err != nil {
+		return prepFailDoNotRequeue(r.Log, fmt.Sprintf("failed to verify the precached images: %s", err.Error()), ibu)
+	}
+
 	r.Log.Info("All jobs completed successfully")
 	utils.StopStageHistory(r.Client, r.Log, ibu) // stop prep history timing
 	return prepSuccessDoNotRequeue(r.Log, ibu)
 }
 
+// maxReportedMissingImages bounds the number of missing images listed in the Prep failure message
+const maxReportedMissingImages = 5
+
+// verifyPrecachedImages checks that every image of the seed is in the container storage of the new stateroot, with
+// the digest it is referenced by, so that an image lost to a registry failure fails Prep rather than the upgrade
+// after the pivot. With best-effort precaching, the missing images are only reported.
+func (r *ImageBasedUpgradeReconciler) verifyPrecachedImages(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) error {
+	imageList, err := r.getPrecachingList(ctx, common.ContainersListFilePath, ibu)
+	if err != nil {
+		return err
+	}
+
+	deploymentDir, err := r.OstreeClient.GetDeploymentDir(common.GetDesiredStaterootName(ibu))
+	if err != nil {
+		return fmt.Errorf("failed to get deployment dir: %w", err)
+	}
+	storageConfig, err := prep.ReadContainerStorageConfig(common.PathOutsideChroot(filepath.Join(deploymentDir, prep.StorageConfFile)))
+	if err != nil {
+		return fmt.Errorf("failed to get the container storage configuration of the new stateroot: %w", err)
+	}
+
+	r.Log.Info("Verifying the precached images", "count", len(imageList), "graphroot", storageConfig.GraphRoot)
+	missing, err := prep.MissingPrecachedImages(r.Executor, storageConfig, imageList)
+	if err != nil {
+		return fmt.Errorf("failed to check the container storage: %w", err)
+	}
+	return r.reportMissingPrecachedImages(ibu, missing, len(imageList))
+}
+
+// reportMissingPrecachedImages returns an error listing the missing precached images, if any. With best-effort
+// precaching, they are reported in a warning Event instead, the images being pulled again after the pivot.
+func (r *ImageBasedUpgradeReconciler) reportMissingPrecachedImages(ibu *ibuv1.ImageBasedUpgrade, missing []string, total int) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	r.Log.Info("Precached images missing", "images", missing)
+	reported := strings.Join(missing, ", ")
+	if len(missing) > maxReportedMissingImages {
+		reported = fmt.Sprintf("%s and %d more", strings.Join(missing[:maxReportedMissingImages], ", "),
+			len(missing)-maxReportedMissingImages)
+	}
+	msg := fmt.Sprintf("%d of %d images are missing from the container storage of the new stateroot: %s",
+		len(missing), total, reported)
+	if precache.IsBestEffort() {
+		r.Log.Info("Ignoring the missing precached images, as precaching is set to best-effort")
+		utils.EmitEvent(r.Recorder, ibu, corev1.EventTypeWarning, utils.EventReasonPrecacheFailed, msg)
+		return nil
+	}
+	return errors.New(msg)
+}
+
+// getPrecacheStageProgressPercent maps the precaching progress onto the portion of the Prep stage used for precaching
+func getPrecacheStageProgressPercent(status *ibuv1.PrecacheStatus) int {
+	const precacheStart, precacheEnd = 50, 99
+	if status == nil || status.Total == 0 {
+		return precacheStart
+	}
+	done := status.Pulled + status.Failed
+	return precacheStart + (precacheEnd-precacheStart)*done/status.Total
+}
+
 // prepInProgressRequeue helper function to stop everything when fail detected
 func prepFailDoNotRequeue(log logr.Logger, msg string, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
 	log.Error(fmt.Errorf("prep stage failed"), msg)
@@ -669,6 +1227,7 @@ func prepSuccessDoNotRequeue(log logr.Logger, ibu *ibuv1.ImageBasedUpgrade) (ctr
 
 	log.Info(msg)
 	utils.SetPrepStatusCompleted(ibu, msg)
+	utils.SetStageProgress(ibu, "Prep completed", 100)
 	return doNotRequeue(), nil
 }
 
@@ -1,14 +1,31 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/imagemgmt"
+	"github.com/openshift-kni/lifecycle-agent/internal/precache"
+	"github.com/openshift-kni/lifecycle-agent/internal/prep"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	appsv1 "k8s.io/api/apps/v1"
+	kbatch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -71,3 +88,219 @@ func TestImageBasedUpgradeReconciler_validateSeedOcpVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestImageBasedUpgradeReconciler_prepValidateOnly(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		threshold     int
+		exceeded      bool
+		checkErr      error
+		wantReason    utils.ConditionReason
+		wantStatus    metav1.ConditionStatus
+		wantMsgSuffix string
+	}{
+		{
+			name:          "validation completes when disk usage is within threshold",
+			threshold:     common.ContainerStorageUsageThresholdPercentDefault,
+			wantReason:    utils.ConditionReasons.Validated,
+			wantStatus:    metav1.ConditionTrue,
+			wantMsgSuffix: "Prep validation completed successfully",
+		},
+		{
+			name:          "validation reports disk usage exceeding overridden threshold",
+			annotations:   map[string]string{common.ContainerStorageUsageThresholdPercentAnnotation: "30"},
+			threshold:     30,
+			exceeded:      true,
+			wantReason:    utils.ConditionReasons.Validated,
+			wantStatus:    metav1.ConditionTrue,
+			wantMsgSuffix: "Container storage disk usage exceeds 30%, unused images will be removed when Prep runs",
+		},
+		{
+			name:       "validation fails when disk usage cannot be checked",
+			threshold:  common.ContainerStorageUsageThresholdPercentDefault,
+			checkErr:   fmt.Errorf("df failed"),
+			wantReason: utils.ConditionReasons.Failed,
+			wantStatus: metav1.ConditionFalse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockImageMgmt := imagemgmt.NewMockImageMgmtIntf(mockCtrl)
+			mockImageMgmt.EXPECT().CheckDiskUsageAgainstThreshold(tt.threshold).Return(tt.exceeded, tt.checkErr)
+
+			r := &ImageBasedUpgradeReconciler{
+				Log:             logr.Logger{},
+				ImageMgmtClient: mockImageMgmt,
+			}
+			ibu := &ibuv1.ImageBasedUpgrade{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep, ValidateOnly: true,
+					DiskSpaceValidation: &ibuv1.DiskSpaceValidation{Disabled: true}},
+			}
+
+			result, err := r.prepValidateOnly(context.Background(), ibu, &seedimage.Inspect{})
+			assert.NoError(t, err)
+			assert.Equal(t, doNotRequeue(), result)
+
+			condition := utils.GetCompletedCondition(ibu, ibuv1.Stages.Prep)
+			assert.NotNil(t, condition)
+			assert.Equal(t, string(tt.wantReason), condition.Reason)
+			assert.Equal(t, tt.wantStatus, condition.Status)
+			assert.True(t, strings.HasSuffix(condition.Message, tt.wantMsgSuffix))
+			assert.Equal(t, tt.checkErr == nil, utils.IsPrepValidated(ibu))
+		})
+	}
+}
+
+func TestImageBasedUpgradeReconciler_prepValidateOnlySeedContent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockImageMgmt := imagemgmt.NewMockImageMgmtIntf(mockCtrl)
+	// The seed image is pulled, so the unused images are removed first
+	mockImageMgmt.EXPECT().CheckDiskUsageAgainstThreshold(common.ContainerStorageUsageThresholdPercentDefault).Return(true, nil)
+	mockImageMgmt.EXPECT().CleanupUnusedImages(common.ContainerStorageUsageThresholdPercentDefault).Return(nil)
+
+	lcaDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "lifecycle-agent-controller-manager", Namespace: common.LcaNamespace},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "manager", Image: "quay.io/lca:latest"}},
+		}}},
+	}
+	c, _ := getFakeClientFromObjects(lcaDeployment)
+	r := &ImageBasedUpgradeReconciler{
+		Client:          c,
+		Scheme:          testscheme,
+		Log:             logr.Discard(),
+		ImageMgmtClient: mockImageMgmt,
+	}
+	ibu := &ibuv1.ImageBasedUpgrade{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.IBUName},
+		Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep, ValidateOnly: true, ValidateSeedContent: true,
+			DiskSpaceValidation: &ibuv1.DiskSpaceValidation{Disabled: true}},
+	}
+
+	_, err := r.prepValidateOnly(context.Background(), ibu, &seedimage.Inspect{})
+	assert.NoError(t, err)
+	assert.True(t, utils.IsStageInProgress(ibu, ibuv1.Stages.Prep))
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: prep.StaterootSetupJobName, Namespace: common.LcaNamespace}, &kbatch.Job{}))
+}
+
+func TestCheckSeedImageIPFamilyCompatibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		seedIPs    []string
+		clusterIPs []string
+		wantErrMsg string
+	}{
+		{name: "same ipv4 family", seedIPs: []string{"192.168.1.10"}, clusterIPs: []string{"10.0.0.2"}},
+		{name: "same dual-stack families", seedIPs: []string{"192.168.1.10", "2001:db8::10"}, clusterIPs: []string{"10.0.0.2", "2001:db8::2"}},
+		{name: "single-stack to dual-stack", seedIPs: []string{"192.168.1.10"}, clusterIPs: []string{"10.0.0.2", "2001:db8::2"}},
+		{name: "ipv4 to ipv6-only", seedIPs: []string{"192.168.1.10"}, clusterIPs: []string{"2001:db8::2"}},
+		{name: "dual-stack to ipv6-only", seedIPs: []string{"192.168.1.10", "2001:db8::10"}, clusterIPs: []string{"2001:db8::2"}},
+		{
+			name:       "ipv6 to ipv4-only",
+			seedIPs:    []string{"2001:db8::10"},
+			clusterIPs: []string{"10.0.0.2"},
+			wantErrMsg: "seed image IP families [ipv6] mismatch the cluster being upgraded IP families [ipv4]",
+		},
+		{
+			name:       "dual-stack to ipv4-only",
+			seedIPs:    []string{"192.168.1.10", "2001:db8::10"},
+			clusterIPs: []string{"10.0.0.2"},
+			wantErrMsg: "seed image IP families [ipv4 ipv6] mismatch the cluster being upgraded IP families [ipv4]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSeedImageIPFamilyCompatibility(tt.seedIPs, tt.clusterIPs)
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckSeedImageArchitecture(t *testing.T) {
+	assert.NoError(t, checkSeedImageArchitecture("arm64", "arm64"))
+	assert.NoError(t, checkSeedImageArchitecture("amd64", ""))
+	assert.ErrorContains(t, checkSeedImageArchitecture("amd64", "arm64"),
+		"seed image architecture amd64 mismatches the node architecture arm64")
+}
+
+func TestGetPrecacheStageProgressPercent(t *testing.T) {
+	assert.Equal(t, 50, getPrecacheStageProgressPercent(nil))
+	assert.Equal(t, 50, getPrecacheStageProgressPercent(&ibuv1.PrecacheStatus{}))
+	assert.Equal(t, 74, getPrecacheStageProgressPercent(&ibuv1.PrecacheStatus{Total: 10, Pulled: 4, Failed: 1}))
+	assert.Equal(t, 99, getPrecacheStageProgressPercent(&ibuv1.PrecacheStatus{Total: 10, Pulled: 10}))
+}
+
+func TestImageBasedUpgradeReconciler_updateSeedImageInfo(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockExecutor := ops.NewMockExecute(mockCtrl)
+
+	r := &ImageBasedUpgradeReconciler{
+		Log:      logr.Discard(),
+		Executor: mockExecutor,
+	}
+	ibu := &ibuv1.ImageBasedUpgrade{
+		Spec: ibuv1.ImageBasedUpgradeSpec{SeedImageRef: ibuv1.SeedImageRef{Image: "quay.io/seed:4.16", Version: "4.16.0"}},
+	}
+
+	// The seed image is inspected once per seedImageRef
+	mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).
+		Return(`{"Digest": "sha256:0123456789abcdef", "Labels": {"com.openshift.lifecycle-agent.seed_cluster_info": "{\"seed_cluster_ocp_version\":\"4.16.0\"}"}}`, nil)
+	r.updateSeedImageInfo(context.Background(), ibu)
+	r.updateSeedImageInfo(context.Background(), ibu)
+	assert.Equal(t, &ibuv1.SeedImageInfo{Image: "quay.io/seed:4.16", Digest: "sha256:0123456789abcdef", OCPVersion: "4.16.0"},
+		ibu.Status.SeedImageInfo)
+
+	// A failed inspection clears the seed image info of the previous seedImageRef
+	ibu.Spec.SeedImageRef.Image = "quay.io/seed:4.17"
+	mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).Return("", fmt.Errorf("unauthorized"))
+	r.updateSeedImageInfo(context.Background(), ibu)
+	assert.Nil(t, ibu.Status.SeedImageInfo)
+
+	// The failed inspection is not retried before its backoff expires
+	r.updateSeedImageInfo(context.Background(), ibu)
+	assert.Equal(t, seedImageInspectInitialBackoff, r.seedImageInspectFailure.backoff)
+
+	// The backoff doubles on each failed retry
+	r.seedImageInspectFailure.retryAfter = time.Now()
+	mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).Return("", fmt.Errorf("unauthorized"))
+	r.updateSeedImageInfo(context.Background(), ibu)
+	assert.Equal(t, 2*seedImageInspectInitialBackoff, r.seedImageInspectFailure.backoff)
+
+	// A new seedImageRef is inspected at once
+	ibu.Spec.SeedImageRef.Image = "quay.io/seed:4.16"
+	mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).
+		Return(`{"Digest": "sha256:0123456789abcdef", "Labels": {"com.openshift.lifecycle-agent.seed_cluster_info": "{\"seed_cluster_ocp_version\":\"4.16.0\"}"}}`, nil)
+	r.updateSeedImageInfo(context.Background(), ibu)
+	assert.NotNil(t, ibu.Status.SeedImageInfo)
+	assert.Nil(t, r.seedImageInspectFailure)
+}
+
+func TestImageBasedUpgradeReconciler_reportMissingPrecachedImages(t *testing.T) {
+	ibu := &ibuv1.ImageBasedUpgrade{ObjectMeta: metav1.ObjectMeta{Name: utils.IBUName}}
+	missing := []string{"quay.io/a@sha256:1", "quay.io/b@sha256:2", "quay.io/c@sha256:3", "quay.io/d@sha256:4",
+		"quay.io/e@sha256:5", "quay.io/f@sha256:6"}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &ImageBasedUpgradeReconciler{Log: logr.Discard(), Recorder: recorder}
+	assert.NoError(t, r.reportMissingPrecachedImages(ibu, nil, 10))
+	assert.EqualError(t, r.reportMissingPrecachedImages(ibu, missing, 10),
+		"6 of 10 images are missing from the container storage of the new stateroot: "+
+			"quay.io/a@sha256:1, quay.io/b@sha256:2, quay.io/c@sha256:3, quay.io/d@sha256:4, quay.io/e@sha256:5 and 1 more")
+	assert.Empty(t, recorder.Events)
+
+	// The missing images are only reported with best-effort precaching
+	t.Setenv(precache.EnvPrecacheBestEffort, "TRUE")
+	assert.NoError(t, r.reportMissingPrecachedImages(ibu, missing[:1], 10))
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "1 of 10 images are missing from the container storage of the new stateroot: quay.io/a@sha256:1")
+}
@@ -23,12 +23,18 @@ import (
 	"time"
 
 	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/audit"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/diagnostics"
+	"github.com/openshift-kni/lifecycle-agent/internal/progress"
+	lcaibu "github.com/openshift-kni/lifecycle-agent/lca-cli/ibu"
 	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func (r *ImageBasedUpgradeReconciler) getRollbackAvailabilityExpiration() (time.Time, error) {
@@ -45,9 +51,94 @@ func (r *ImageBasedUpgradeReconciler) getRollbackAvailabilityExpiration() (time.
 	return expiry, nil
 }
 
+// rollbackRetentionEnd returns the end of the rollback retention window of the completed upgrade, or the zero time if
+// the upgrade is not completed or no window is defined
+func rollbackRetentionEnd(ibu *ibuv1.ImageBasedUpgrade) time.Time {
+	if ibu.Spec.RollbackRetentionHours <= 0 {
+		return time.Time{}
+	}
+	completed := meta.FindStatusCondition(ibu.Status.Conditions, string(utils.ConditionTypes.UpgradeCompleted))
+	if completed == nil || completed.Status != metav1.ConditionTrue {
+		return time.Time{}
+	}
+	return completed.LastTransitionTime.Add(time.Duration(ibu.Spec.RollbackRetentionHours) * time.Hour)
+}
+
+// isRollbackRetentionOpen returns true while the upgrade is completed and can not be finalized yet, for the original
+// stateroot to be kept available for rollback
+func isRollbackRetentionOpen(ibu *ibuv1.ImageBasedUpgrade, now time.Time) bool {
+	end := rollbackRetentionEnd(ibu)
+	return !end.IsZero() && now.Before(end)
+}
+
+// updateRollbackAvailableCondition reports whether the upgrade can be rolled back to the original stateroot, and until
+// when. The condition is only set after the pivot, until the upgrade is finalized or rolled back.
+func (r *ImageBasedUpgradeReconciler) updateRollbackAvailableCondition(ibu *ibuv1.ImageBasedUpgrade, isAfterPivot bool) {
+	if !isAfterPivot || ibu.Spec.Stage != ibuv1.Stages.Upgrade {
+		meta.RemoveStatusCondition(&ibu.Status.Conditions, string(utils.ConditionTypes.RollbackAvailable))
+		return
+	}
+
+	stateroot, err := r.RPMOstreeClient.GetUnbootedStaterootName()
+	if err != nil {
+		utils.SetStatusCondition(&ibu.Status.Conditions,
+			utils.ConditionTypes.RollbackAvailable,
+			utils.ConditionReasons.StaterootRemoved,
+			metav1.ConditionFalse,
+			fmt.Sprintf("The original stateroot is no longer available: %s", err.Error()),
+			ibu.Generation,
+		)
+		return
+	}
+
+	expiry := ibu.Status.RollbackAvailabilityExpiration
+	if !expiry.IsZero() && time.Now().After(expiry.Time) {
+		utils.SetStatusCondition(&ibu.Status.Conditions,
+			utils.ConditionTypes.RollbackAvailable,
+			utils.ConditionReasons.CertificatesExpired,
+			metav1.ConditionFalse,
+			fmt.Sprintf("Rolling back to the %s stateroot requires the manual recovery of its control plane certificates, expired since %s",
+				stateroot, expiry.UTC().Format(time.RFC3339)),
+			ibu.Generation,
+		)
+		return
+	}
+
+	msg := fmt.Sprintf("Rollback to the %s stateroot is available", stateroot)
+	if !expiry.IsZero() {
+		msg += fmt.Sprintf(" until %s", expiry.UTC().Format(time.RFC3339))
+	}
+	if end := rollbackRetentionEnd(ibu); time.Now().Before(end) {
+		msg += fmt.Sprintf(", the upgrade can not be finalized before %s", end.UTC().Format(time.RFC3339))
+	}
+	utils.SetStatusCondition(&ibu.Status.Conditions,
+		utils.ConditionTypes.RollbackAvailable,
+		utils.ConditionReasons.Available,
+		metav1.ConditionTrue,
+		msg,
+		ibu.Generation,
+	)
+}
+
+// requeueForRollbackAvailability returns the interval after which the RollbackAvailable condition and the valid next
+// stages change, the rollback retention window closing or the control plane certificates expiring, or 0 if none
+func requeueForRollbackAvailability(ibu *ibuv1.ImageBasedUpgrade, now time.Time) time.Duration {
+	if !meta.IsStatusConditionTrue(ibu.Status.Conditions, string(utils.ConditionTypes.RollbackAvailable)) {
+		return 0
+	}
+	var interval time.Duration
+	for _, next := range []time.Time{rollbackRetentionEnd(ibu), ibu.Status.RollbackAvailabilityExpiration.Time} {
+		if next.After(now) && (interval == 0 || next.Sub(now) < interval) {
+			interval = next.Sub(now)
+		}
+	}
+	return interval
+}
+
 //nolint:unparam
 func (r *ImageBasedUpgradeReconciler) startRollback(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
 	utils.SetRollbackStatusInProgress(ibu, "Initiating rollback")
+	utils.SetStageProgress(ibu, "Initiating rollback", 10)
 
 	stateroot, err := r.RPMOstreeClient.GetUnbootedStaterootName()
 	if err != nil {
@@ -94,6 +185,7 @@ func (r *ImageBasedUpgradeReconciler) startRollback(ctx context.Context, ibu *ib
 
 	// Update in-progress message
 	utils.SetRollbackStatusInProgress(ibu, "Completing rollback")
+	utils.SetStageProgress(ibu, "Completing rollback", 40)
 	if updateErr := utils.UpdateIBUStatus(ctx, r.Client, ibu); updateErr != nil {
 		r.Log.Error(updateErr, "failed to update IBU CR status")
 	}
@@ -106,10 +198,26 @@ func (r *ImageBasedUpgradeReconciler) startRollback(ctx context.Context, ibu *ib
 		return doNotRequeue(), nil
 	}
 
+	// Carry the audit trail back, so that the events recorded on the new stateroot are kept after the rollback
+	if err := r.Audit.Record(audit.Command, string(ibuv1.Stages.Rollback),
+		fmt.Sprintf("Set the %s stateroot as the default deployment and rebooted the node", stateroot)); err != nil {
+		r.Log.Error(err, "failed to record the audit event")
+	}
+	if err := r.Audit.CopyLog(common.PathOutsideChroot(filepath.Join(common.GetStaterootPath(stateroot), common.AuditLogFile))); err != nil {
+		r.Log.Error(err, "failed to export the audit log to the old state root")
+	}
+	if r.Diagnostics != nil {
+		if err := r.Diagnostics.CopyBundles(common.PathOutsideChroot(filepath.Join(common.GetStaterootPath(stateroot), diagnostics.Dir))); err != nil {
+			r.Log.Error(err, "failed to export the diagnostics bundles to the old state root")
+		}
+	}
+
 	// Write an event to indicate reboot attempt
 	r.Recorder.Event(ibu, corev1.EventTypeNormal, "Reboot", "System will now reboot for rollback")
+	r.Progress.SetState(progress.StateAwaitingReboot)
 	err = r.RebootClient.RebootToNewStateRoot("rollback")
 	if err != nil {
+		r.Progress.SetState(progress.StateReconciling)
 		r.Log.Error(err, "")
 		utils.SetRollbackStatusFailed(ibu, err.Error())
 		return doNotRequeue(), nil
@@ -119,7 +227,9 @@ func (r *ImageBasedUpgradeReconciler) startRollback(ctx context.Context, ibu *ib
 }
 
 func (r *ImageBasedUpgradeReconciler) finishRollback(ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
+	r.reportManualRollback(ibu)
 	utils.SetRollbackStatusCompleted(ibu)
+	utils.SetStageProgress(ibu, "Rollback completed", 100)
 
 	return doNotRequeue(), nil
 }
@@ -141,3 +251,27 @@ func (r *ImageBasedUpgradeReconciler) handleRollback(ctx context.Context, ibu *i
 		return r.startRollback(ctx, ibu)
 	}
 }
+
+// reportManualRollback records an Event for a rollback initiated from the node with lca-cli, while the API server was
+// possibly unavailable, and removes its record
+func (r *ImageBasedUpgradeReconciler) reportManualRollback(ibu *ibuv1.ImageBasedUpgrade) {
+	record, err := lcaibu.ReadRollbackRecord()
+	if err != nil {
+		r.Log.Error(err, "failed to read manual rollback record")
+		return
+	}
+	if record == nil {
+		return
+	}
+
+	msg := fmt.Sprintf("Rollback from stateroot %s was initiated from the node at %s", record.Stateroot, record.Time.UTC().Format(time.RFC3339))
+	if record.Reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, record.Reason)
+	}
+	r.Log.Info(msg)
+	utils.EmitEvent(r.Recorder, ibu, corev1.EventTypeNormal, utils.EventReasonManualRollback, msg)
+
+	if err := lcaibu.RemoveRollbackRecord(); err != nil {
+		r.Log.Error(err, "failed to remove manual rollback record")
+	}
+}
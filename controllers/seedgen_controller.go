@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
+	"github.com/openshift-kni/lifecycle-agent/internal/mcdrift"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	commonUtils "github.com/openshift-kni/lifecycle-agent/utils"
@@ -43,6 +45,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -81,6 +84,8 @@ type SeedGeneratorReconciler struct {
 var (
 	lcaImage            string
 	seedgenAuthFile     = filepath.Join(utils.SeedgenWorkspacePath, "auth.json")
+	seedgenEncKeyFile   = filepath.Join(utils.SeedgenWorkspacePath, "encryption-key.pem")
+	seedgenDigestFile   = filepath.Join(utils.SeedgenWorkspacePath, "seed-image.digest")
 	imagerContainerName = "lca_image_builder"
 )
 
@@ -396,6 +401,102 @@ func (r *SeedGeneratorReconciler) cleanupClusterResources(ctx context.Context) e
 	return nil
 }
 
+// cleanupExcludedResources deletes the secrets and namespaces excluded by the seedgen spec.exclusions, so that they are
+// not carried by the seed image. The secrets are only listed in the namespace of their pattern, which is never a
+// platform namespace such as the LCA one, whose secrets are needed to generate the seed image.
+func (r *SeedGeneratorReconciler) cleanupExcludedResources(ctx context.Context, seedgen *seedgenv1.SeedGenerator) error {
+	exclusions := seedgen.Spec.Exclusions
+	if exclusions == nil {
+		return nil
+	}
+
+	deleteOpts := []client.DeleteOption{
+		client.PropagationPolicy(metav1.DeletePropagationForeground),
+	}
+
+	for _, pattern := range exclusions.SecretNamePatterns {
+		namespace, namePattern, err := parseSecretNamePattern(pattern)
+		if err != nil {
+			return err
+		}
+		secrets := &corev1.SecretList{}
+		if err := r.Client.List(ctx, secrets, client.InNamespace(namespace)); err != nil {
+			return fmt.Errorf("failed to list secrets in namespace %s: %w", namespace, err)
+		}
+		for i := range secrets.Items {
+			secret := &secrets.Items[i]
+			if matched, _ := filepath.Match(namePattern, secret.Name); !matched {
+				continue
+			}
+			r.Log.Info(fmt.Sprintf("Deleting excluded secret %s/%s", secret.Namespace, secret.Name))
+			if err := r.Client.Delete(ctx, secret, deleteOpts...); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete secret %s/%s: %w", secret.Namespace, secret.Name, err)
+			}
+		}
+	}
+
+	if len(exclusions.Namespaces) == 0 {
+		return nil
+	}
+
+	for _, nsName := range exclusions.Namespaces {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nsName,
+			}}
+		r.Log.Info(fmt.Sprintf("Deleting excluded namespace %s", nsName))
+		if err := r.Client.Delete(ctx, ns, deleteOpts...); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete namespace %s: %w", nsName, err)
+		}
+	}
+
+	r.Log.Info("Waiting until excluded namespaces are deleted")
+	err := wait.PollUntilContextTimeout(ctx, 10*time.Second, 15*time.Minute, true, func(ctx context.Context) (bool, error) {
+		for _, nsName := range exclusions.Namespaces {
+			err := r.Client.Get(ctx, types.NamespacedName{Name: nsName}, &corev1.Namespace{})
+			if !k8serrors.IsNotFound(err) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("timed out waiting for excluded namespace deletion: %w", err)
+	}
+
+	return nil
+}
+
+// bracketExpression matches the [...] expressions of a shell-style pattern
+var bracketExpression = regexp.MustCompile(`\[[^\]]*\]`)
+
+// isSystemNamespace returns whether the namespace is one of the platform namespaces, whose secrets are never excluded
+func isSystemNamespace(namespace string) bool {
+	return strings.HasPrefix(namespace, "openshift-") || strings.HasPrefix(namespace, "kube-") || namespace == "default"
+}
+
+// parseSecretNamePattern splits an excluded secret pattern into its namespace and its name pattern, rejecting the
+// patterns that would match the secrets of any namespace or any secret of a namespace
+func parseSecretNamePattern(pattern string) (string, string, error) {
+	namespace, namePattern, found := strings.Cut(pattern, "/")
+	if !found || strings.Contains(namePattern, "/") {
+		return "", "", fmt.Errorf("invalid secret name pattern %s: must be <namespace>/<name pattern>", pattern)
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", "", fmt.Errorf("invalid secret name pattern %s: invalid namespace: %s", pattern, strings.Join(errs, ", "))
+	}
+	if isSystemNamespace(namespace) {
+		return "", "", fmt.Errorf("invalid secret name pattern %s: the secrets of the %s namespace cannot be excluded", pattern, namespace)
+	}
+	if _, err := filepath.Match(namePattern, ""); err != nil {
+		return "", "", fmt.Errorf("invalid secret name pattern %s: %w", pattern, err)
+	}
+	if strings.Trim(bracketExpression.ReplaceAllString(namePattern, ""), "*?") == "" {
+		return "", "", fmt.Errorf("invalid secret name pattern %s: the name pattern must not be wildcards only", pattern)
+	}
+	return namespace, namePattern, nil
+}
+
 // Get the LCA image ref
 // TODO: Is there a better way to access the image ref?
 func (r *SeedGeneratorReconciler) getLcaImage(ctx context.Context) (image string, err error) {
@@ -480,14 +581,52 @@ func (r *SeedGeneratorReconciler) launchImager(seedgen *seedgenv1.SeedGenerator)
 		lcaImage,
 		"create",
 		"--authfile", seedgenAuthFile,
-		"--image", seedgen.Spec.SeedImage,
+		"--image", pushedSeedImage(seedgen),
 		"--recert-image", recertImage,
+		"--digestfile", seedgenDigestFile,
 	}
 
 	if skipRecert {
 		imagerCmdArgs = append(imagerCmdArgs, "--skip-recert-validation")
 	}
 
+	if seedgen.Spec.MultiArch {
+		imagerCmdArgs = append(imagerCmdArgs, "--manifest-list", seedImageForRun(seedgen))
+	}
+
+	if seedgen.Spec.BaseSeedImage != "" {
+		imagerCmdArgs = append(imagerCmdArgs, "--base-seed-image", seedgen.Spec.BaseSeedImage)
+	}
+
+	if compression := seedgen.Spec.Compression; compression != nil {
+		imagerCmdArgs = append(imagerCmdArgs, "--compression", compression.Algorithm)
+		if compression.Level != nil {
+			imagerCmdArgs = append(imagerCmdArgs, "--compression-level", strconv.Itoa(*compression.Level))
+		}
+	}
+
+	if seedgen.Spec.Etcd != nil {
+		imagerCmdArgs = append(imagerCmdArgs, "--etcd-content", seedgen.Spec.Etcd.Content)
+	}
+
+	if exclusions := seedgen.Spec.Exclusions; exclusions != nil {
+		for _, p := range exclusions.Paths {
+			imagerCmdArgs = append(imagerCmdArgs, "--exclude-path", p)
+		}
+		for _, pattern := range exclusions.SecretNamePatterns {
+			imagerCmdArgs = append(imagerCmdArgs, "--exclude-secret", pattern)
+		}
+		for _, ns := range exclusions.Namespaces {
+			imagerCmdArgs = append(imagerCmdArgs, "--exclude-namespace", ns)
+		}
+	}
+
+	// The encryption key file is only written when the seedgen secret provides one
+	if _, err := os.Stat(common.PathOutsideChroot(seedgenEncKeyFile)); err == nil {
+		r.Log.Info("Seed image layers will be encrypted")
+		imagerCmdArgs = append(imagerCmdArgs, "--encryption-key", "jwe:"+seedgenEncKeyFile)
+	}
+
 	// In order to have the imager container both survive the LCA pod shutdown and have continued network access
 	// after all other pods are shutdown, we're using systemd-run to launch it as a transient service-unit
 	systemdRunOpts := []string{
@@ -570,7 +709,7 @@ func (r *SeedGeneratorReconciler) checkImagerStatus() error {
 }
 
 // Check whether the system can be used for seed generation
-func (r *SeedGeneratorReconciler) validateSystem(ctx context.Context) (msg string) {
+func (r *SeedGeneratorReconciler) validateSystem(ctx context.Context, seedgen *seedgenv1.SeedGenerator) (msg string) {
 	// Check that the "ostree admin set-default" feature is available
 	if !ostreeclient.NewClient(r.Executor, false).IsOstreeAdminSetDefaultFeatureEnabled() {
 		msg = "Rejected: Installed release does not support \"ostree admin set-default\" feature"
@@ -666,9 +805,61 @@ func (r *SeedGeneratorReconciler) validateSystem(ctx context.Context) (msg strin
 		}
 	}
 
+	// Ensure the excluded secrets are scoped, as they are deleted from the seed SNO
+	if exclusions := seedgen.Spec.Exclusions; exclusions != nil {
+		for _, pattern := range exclusions.SecretNamePatterns {
+			if _, _, err := parseSecretNamePattern(pattern); err != nil {
+				msg = fmt.Sprintf("Rejected: %s", err.Error())
+				return
+			}
+		}
+	}
+
+	// Ensure the MachineConfig state of the seed SNO is rendered and applied as-is, as a drift would be propagated to
+	// every target cluster
+	msg = r.checkMachineConfigDrift(ctx, seedgen)
 	return
 }
 
+// maxReportedDrifts bounds the number of MachineConfig drifts listed in the rejection message
+const maxReportedDrifts = 5
+
+// checkMachineConfigDrift rejects the seed generation when a MachineConfig update is pending or when files of the
+// applied MachineConfig were modified outside of the MCO. The modified files only raise a warning if the SeedGenerator
+// is annotated to allow the drift.
+func (r *SeedGeneratorReconciler) checkMachineConfigDrift(ctx context.Context, seedgen *seedgenv1.SeedGenerator) string {
+	pending, err := mcdrift.PendingUpdates(ctx, r.Client)
+	if err != nil {
+		return fmt.Sprintf("Failure occurred during check for pending MachineConfig updates in system validation: %v", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Sprintf("Rejected: MachineConfig updates must be completed prior to seed generation: %s", reportDrifts(pending))
+	}
+
+	modified, err := mcdrift.ModifiedFiles(common.PathOutsideChroot(common.MCDCurrentConfig), common.Host)
+	if err != nil {
+		return fmt.Sprintf("Failure occurred during check for MachineConfig drift in system validation: %v", err)
+	}
+	if len(modified) == 0 {
+		return ""
+	}
+	r.Log.Info("Files of the MachineConfig modified outside of the MCO", "files", modified)
+	if _, allowed := seedgen.GetAnnotations()[utils.AllowMachineConfigDriftAnnotation]; allowed {
+		utils.EmitEvent(r.Recorder, seedgen, corev1.EventTypeWarning, utils.EventReasonMachineConfigDrift,
+			fmt.Sprintf("Files of the MachineConfig modified outside of the MCO are included in the seed image: %s", reportDrifts(modified)))
+		return ""
+	}
+	return fmt.Sprintf("Rejected: Files of the MachineConfig must not be modified outside of the MCO, or annotate with %s to allow it: %s",
+		utils.AllowMachineConfigDriftAnnotation, reportDrifts(modified))
+}
+
+func reportDrifts(drifts []string) string {
+	if len(drifts) > maxReportedDrifts {
+		return fmt.Sprintf("%s and %d more", strings.Join(drifts[:maxReportedDrifts], ", "), len(drifts)-maxReportedDrifts)
+	}
+	return strings.Join(drifts, ", ")
+}
+
 func (r *SeedGeneratorReconciler) restoreSeedgenCRIfNeeded(ctx context.Context, seedgen *seedgenv1.SeedGenerator) error {
 	r.Log.Info("Restoring seedgen CR in DB")
 
@@ -806,6 +997,14 @@ func (r *SeedGeneratorReconciler) generateSeedImage(ctx context.Context, seedgen
 		return
 	}
 
+	if encryptionKey, exists := seedGenSecret.Data[utils.SeedGenSecretEncryptionKey]; exists {
+		if err := os.WriteFile(common.PathOutsideChroot(seedgenEncKeyFile), encryptionKey, 0o600); err != nil {
+			rc = fmt.Errorf("failed to write %s: %w", seedgenEncKeyFile, err)
+			setSeedGenStatusFailed(seedgen, rc.Error())
+			return
+		}
+	}
+
 	// Get the cluster's pull-secret
 	originalPullSecretData, err := lcautils.GetSecretData(ctx, common.PullSecretName, common.OpenshiftConfigNamespace, corev1.DockerConfigJsonKey, r.Client)
 	if err != nil {
@@ -835,6 +1034,12 @@ func (r *SeedGeneratorReconciler) generateSeedImage(ctx context.Context, seedgen
 		return
 	}
 
+	if err := r.cleanupExcludedResources(ctx, seedgen); err != nil {
+		rc = fmt.Errorf("failed to cleanup excluded resources: %w", err)
+		setSeedGenStatusFailed(seedgen, rc.Error())
+		return
+	}
+
 	// TODO: Can this be done cleanly via client? The client.DeleteAllOf seems to require a specified namespace, so maybe loop over the namespaces
 	r.Log.Info("Cleaning completed and failed pods")
 	kubeconfigArg := fmt.Sprintf("--kubeconfig=%s", common.KubeconfigFile)
@@ -927,12 +1132,15 @@ func (r *SeedGeneratorReconciler) generateSeedImage(ctx context.Context, seedgen
 }
 
 // finishSeedgen runs after the imager container completes and restores kubelet, once the LCA operator restarts
-func (r *SeedGeneratorReconciler) finishSeedgen() error {
+func (r *SeedGeneratorReconciler) finishSeedgen(ctx context.Context, seedgen *seedgenv1.SeedGenerator) error {
 	// Check exit status of lca_cli container
 	if err := r.checkImagerStatus(); err != nil {
 		return fmt.Errorf("imager container status check failed: %w", err)
 	}
 
+	// The digest and registry credentials are read from the workspace, so record the image before wiping it
+	r.recordGeneratedImage(ctx, seedgen)
+
 	if err := r.wipeExistingWorkspace(); err != nil {
 		return fmt.Errorf("failed to wipe workspace: %w", err)
 	}
@@ -994,11 +1202,18 @@ func (r *SeedGeneratorReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return
 	case phases.PhaseCompleted:
 		r.Log.Info("Seed Generation is completed")
-		return
+		if seedgen.Spec.Schedule == "" {
+			return
+		}
+		nextReconcile = r.scheduleSeedRegeneration(ctx, seedgen)
 	case phases.PhaseInitial:
-		// Run the system validation
+		rejection := validateSchedule(seedgen)
+		if rejection == "" {
+			// Run the system validation
+			rejection = r.validateSystem(ctx, seedgen)
+		}
 		// nolint: gocritic
-		if rejection := r.validateSystem(ctx); len(rejection) > 0 {
+		if len(rejection) > 0 {
 			setSeedGenStatusFailed(seedgen, rejection)
 			r.Log.Info(fmt.Sprintf("Seed generation rejected: system validation failed: %s", rejection))
 
@@ -1012,7 +1227,7 @@ func (r *SeedGeneratorReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		setSeedGenStatusInProgress(seedgen, msgWaitingForStable)
 		nextReconcile = requeueImmediately()
 	case phases.PhaseGenerating:
-		r.Log.Info(fmt.Sprintf("Generating seed image: %s", seedgen.Spec.SeedImage))
+		r.Log.Info(fmt.Sprintf("Generating seed image: %s", seedImageForRun(seedgen)))
 		if nextReconcile, err = r.generateSeedImage(ctx, seedgen); err != nil {
 			_ = r.wipeExistingWorkspace()
 
@@ -1026,7 +1241,7 @@ func (r *SeedGeneratorReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			r.Log.Error(err, "failed to update seedgen CR status")
 		}
 
-		if err = r.finishSeedgen(); err != nil {
+		if err = r.finishSeedgen(ctx, seedgen); err != nil {
 			r.Log.Error(err, "Seed generation failed")
 			setSeedGenStatusFailed(seedgen, fmt.Sprintf("Seed generation failed: %s", err))
 		} else {
@@ -1083,11 +1298,19 @@ func setSeedGenStatusCompleted(seedgen *seedgenv1.SeedGenerator) {
 }
 
 func (r *SeedGeneratorReconciler) updateStatus(ctx context.Context, seedgen *seedgenv1.SeedGenerator) error {
+	// The stored conditions are compared with the updated ones to emit the events of the changes
+	var conditionsBefore []metav1.Condition
+	stored := &seedgenv1.SeedGenerator{}
+	if err := r.Get(ctx, types.NamespacedName{Name: seedgen.Name}, stored); err == nil {
+		conditionsBefore = stored.Status.Conditions
+	}
+
 	seedgen.Status.ObservedGeneration = seedgen.ObjectMeta.Generation
 	if err := r.Status().Update(ctx, seedgen); err != nil {
 		return fmt.Errorf("failed to update seedgen status: %w", err)
 	}
 
+	utils.EmitConditionEvents(r.Recorder, seedgen, conditionsBefore, seedgen.Status.Conditions)
 	return nil
 }
 
@@ -1103,6 +1326,6 @@ func (r *SeedGeneratorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			GenericFunc: func(ge event.GenericEvent) bool { return false },
 			DeleteFunc:  func(de event.DeleteEvent) bool { return false },
 		})).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1, RateLimiter: failureRateLimiter()}).
 		Complete(r)
 }
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	seedgenv1 "github.com/openshift-kni/lifecycle-agent/api/seedgenerator/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+func TestParseSecretNamePattern(t *testing.T) {
+	testcases := []struct {
+		pattern       string
+		namespace     string
+		namePattern   string
+		expectedError string
+	}{
+		{pattern: "site-config/site-*-tls", namespace: "site-config", namePattern: "site-*-tls"},
+		{pattern: "site-config/[ab]-tls", namespace: "site-config", namePattern: "[ab]-tls"},
+		{pattern: "site-*-tls", expectedError: "must be <namespace>/<name pattern>"},
+		{pattern: "site-config/certs/tls", expectedError: "must be <namespace>/<name pattern>"},
+		{pattern: "*/site-tls", expectedError: "invalid namespace"},
+		{pattern: "openshift-lifecycle-agent/site-tls", expectedError: "the secrets of the openshift-lifecycle-agent namespace cannot be excluded"},
+		{pattern: "kube-system/site-tls", expectedError: "cannot be excluded"},
+		{pattern: "default/site-tls", expectedError: "cannot be excluded"},
+		{pattern: "site-config/*", expectedError: "the name pattern must not be wildcards only"},
+		{pattern: "site-config/[a-z]*", expectedError: "the name pattern must not be wildcards only"},
+		{pattern: "site-config/", expectedError: "the name pattern must not be wildcards only"},
+		{pattern: "site-config/site-[", expectedError: "syntax error in pattern"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			namespace, namePattern, err := parseSecretNamePattern(tc.pattern)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.namespace, namespace)
+			assert.Equal(t, tc.namePattern, namePattern)
+		})
+	}
+}
+
+func TestCleanupExcludedSecrets(t *testing.T) {
+	newSecret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		newSecret("site-config", "site-a-tls"),
+		newSecret("site-config", "site-config"),
+		newSecret("apps", "site-a-tls"),
+		newSecret(common.LcaNamespace, "site-a-tls"),
+	).Build()
+	r := &SeedGeneratorReconciler{Client: c, Log: logr.Discard()}
+
+	seedgen := &seedgenv1.SeedGenerator{Spec: seedgenv1.SeedGeneratorSpec{
+		Exclusions: &seedgenv1.SeedExclusions{SecretNamePatterns: []string{"site-config/site-*-tls"}},
+	}}
+	assert.NoError(t, r.cleanupExcludedResources(context.Background(), seedgen))
+
+	secrets := &corev1.SecretList{}
+	assert.NoError(t, c.List(context.Background(), secrets))
+	var remaining []string
+	for _, secret := range secrets.Items {
+		remaining = append(remaining, client.ObjectKeyFromObject(&secret).String())
+	}
+	assert.ElementsMatch(t, []string{"site-config/site-config", "apps/site-a-tls", common.LcaNamespace + "/site-a-tls"}, remaining)
+
+	// An unscoped pattern deletes nothing
+	seedgen.Spec.Exclusions.SecretNamePatterns = []string{"*"}
+	assert.ErrorContains(t, r.cleanupExcludedResources(context.Background(), seedgen), "must be <namespace>/<name pattern>")
+}
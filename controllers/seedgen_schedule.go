@@ -0,0 +1,221 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	seedgenv1 "github.com/openshift-kni/lifecycle-agent/api/seedgenerator/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
+)
+
+// defaultKeepSeedImages is the number of generated seed images recorded in the status when no retention is set
+const defaultKeepSeedImages = 10
+
+// scheduledImageTimeFormat is the format of the generation time suffixed to the tag of the scheduled seed images
+const scheduledImageTimeFormat = "20060102T150405Z"
+
+// validateSchedule returns a rejection message if the spec.schedule is not a valid cron expression
+func validateSchedule(seedgen *seedgenv1.SeedGenerator) string {
+	if seedgen.Spec.Schedule == "" {
+		return ""
+	}
+	if _, err := cron.ParseStandard(seedgen.Spec.Schedule); err != nil {
+		return fmt.Sprintf("Rejected: invalid schedule %q: %s", seedgen.Spec.Schedule, err)
+	}
+	return ""
+}
+
+// suffixTag suffixes the tag of the seed image, defaulting to the latest tag
+func suffixTag(seedImage, suffix string) string {
+	if strings.Contains(seedImage[strings.LastIndex(seedImage, "/")+1:], ":") {
+		return seedImage + "-" + suffix
+	}
+	return seedImage + ":latest-" + suffix
+}
+
+// scheduledSeedImage suffixes the tag of the seed image with the schedule time, defaulting to the latest tag
+func scheduledSeedImage(seedImage string, scheduleTime time.Time) string {
+	return suffixTag(seedImage, scheduleTime.UTC().Format(scheduledImageTimeFormat))
+}
+
+// seedImageVariant suffixes the tag of the seed image with the architecture of the seed SNO, defaulting to the latest
+// tag, for the seed image to be added to the manifest list of the un-suffixed pull-spec
+func seedImageVariant(seedImage string) string {
+	return suffixTag(seedImage, runtime.GOARCH)
+}
+
+// seedImageForRun returns the pull-spec of the seed image generated by the current run. The first run pushes the
+// spec.seedImage, while the runs triggered by the spec.schedule push it tagged with their schedule time.
+func seedImageForRun(seedgen *seedgenv1.SeedGenerator) string {
+	if seedgen.Spec.Schedule == "" || seedgen.Status.LastScheduleTime == nil {
+		return seedgen.Spec.SeedImage
+	}
+	return scheduledSeedImage(seedgen.Spec.SeedImage, seedgen.Status.LastScheduleTime.Time)
+}
+
+// pushedSeedImage returns the pull-spec of the seed image pushed by the current run, i.e. its architecture variant
+// when the spec.multiArch is set, the pull-spec of the run being the manifest list then
+func pushedSeedImage(seedgen *seedgenv1.SeedGenerator) string {
+	if seedgen.Spec.MultiArch {
+		return seedImageVariant(seedImageForRun(seedgen))
+	}
+	return seedImageForRun(seedgen)
+}
+
+// nextScheduleTime returns when the spec.schedule next triggers, after it last triggered or after the seed image was
+// last generated
+func nextScheduleTime(seedgen *seedgenv1.SeedGenerator) (time.Time, error) {
+	schedule, err := cron.ParseStandard(seedgen.Spec.Schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %q: %w", seedgen.Spec.Schedule, err)
+	}
+
+	from := seedgen.CreationTimestamp.Time
+	if last := lastGeneratedImage(seedgen); last != nil {
+		from = last.GeneratedAt.Time
+	}
+	if seedgen.Status.LastScheduleTime != nil && seedgen.Status.LastScheduleTime.After(from) {
+		from = seedgen.Status.LastScheduleTime.Time
+	}
+	return schedule.Next(from), nil
+}
+
+func lastGeneratedImage(seedgen *seedgenv1.SeedGenerator) *seedgenv1.GeneratedSeedImage {
+	if len(seedgen.Status.GeneratedImages) == 0 {
+		return nil
+	}
+	return &seedgen.Status.GeneratedImages[len(seedgen.Status.GeneratedImages)-1]
+}
+
+// addGeneratedImage records the generated seed image in the status, returning the images dropped by the retention
+func addGeneratedImage(seedgen *seedgenv1.SeedGenerator, image seedgenv1.GeneratedSeedImage) []seedgenv1.GeneratedSeedImage {
+	keep := defaultKeepSeedImages
+	if seedgen.Spec.Retention != nil {
+		keep = seedgen.Spec.Retention.KeepImages
+	}
+
+	images := append(seedgen.Status.GeneratedImages, image)
+	var dropped []seedgenv1.GeneratedSeedImage
+	if extra := len(images) - keep; extra > 0 {
+		dropped = images[:extra]
+		images = images[extra:]
+	}
+	seedgen.Status.GeneratedImages = images
+	return dropped
+}
+
+func (r *SeedGeneratorReconciler) getClusterRelease(ctx context.Context) (configv1.Release, error) {
+	clusterVersion := &configv1.ClusterVersion{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: "version"}, clusterVersion); err != nil {
+		return configv1.Release{}, fmt.Errorf("failed to get ClusterVersion: %w", err)
+	}
+	return clusterVersion.Status.Desired, nil
+}
+
+func (r *SeedGeneratorReconciler) getClusterVersion(ctx context.Context) (string, error) {
+	release, err := r.getClusterRelease(ctx)
+	return release.Version, err
+}
+
+// recordGeneratedImage records the seed image pushed by the imager in the status, emits an Event summarizing it, and
+// applies the retention policy. As the seed image was successfully pushed, failures are only logged.
+func (r *SeedGeneratorReconciler) recordGeneratedImage(ctx context.Context, seedgen *seedgenv1.SeedGenerator) {
+	image := seedgenv1.GeneratedSeedImage{
+		Image:       pushedSeedImage(seedgen),
+		GeneratedAt: metav1.Now(),
+	}
+	if digest, err := os.ReadFile(common.PathOutsideChroot(seedgenDigestFile)); err != nil {
+		r.Log.Error(err, "Failed to read the digest of the seed image", "image", image.Image)
+	} else {
+		image.Digest = strings.TrimSpace(string(digest))
+	}
+	if release, err := r.getClusterRelease(ctx); err != nil {
+		r.Log.Error(err, "Failed to get the version of the seed cluster")
+	} else {
+		image.Version = release.Version
+		image.Release = release.Image
+	}
+	// The size and content hash are read back from the registry, as pushed
+	if inspect, err := seedimage.InspectImage(r.Executor, image.Image, seedgenAuthFile); err != nil {
+		r.Log.Error(err, "Failed to inspect the pushed seed image", "image", image.Image)
+	} else {
+		image.Size = inspect.Size()
+		image.ContentHash = inspect.ContentHash()
+	}
+	utils.EmitEvent(r.Recorder, seedgen, corev1.EventTypeNormal, utils.EventReasonSeedImagePushed,
+		fmt.Sprintf("Seed image %s pushed: digest %s, size %d bytes, version %s, content hash %s",
+			image.Image, image.Digest, image.Size, image.Version, image.ContentHash))
+
+	for _, dropped := range addGeneratedImage(seedgen, image) {
+		// Only the seed images pushed by the schedule are deleted, when a retention is set. The manifest lists of the
+		// dropped architecture variants are left in the registry, as they may list the seed images of other seed SNOs.
+		if seedgen.Spec.Retention == nil || dropped.Image == seedgen.Spec.SeedImage ||
+			dropped.Image == seedImageVariant(seedgen.Spec.SeedImage) {
+			continue
+		}
+		r.Log.Info("Deleting seed image from the registry, as per the retention", "image", dropped.Image)
+		if _, err := r.Executor.Execute("skopeo", "delete", "--authfile", seedgenAuthFile, "docker://"+dropped.Image); err != nil {
+			r.Log.Error(err, "Failed to delete seed image from the registry", "image", dropped.Image)
+		}
+	}
+}
+
+// scheduleSeedRegeneration requeues the completed seedgen until the spec.schedule triggers. Once triggered, the seed
+// image is re-generated if the seed cluster was updated since the last generated seed image, by resetting the status
+// conditions of the seedgen. The caller is responsible for persisting the status.
+func (r *SeedGeneratorReconciler) scheduleSeedRegeneration(ctx context.Context, seedgen *seedgenv1.SeedGenerator) ctrl.Result {
+	next, err := nextScheduleTime(seedgen)
+	if err != nil {
+		r.Log.Error(err, "Seed image re-generation is not scheduled")
+		return doNotRequeue()
+	}
+	if now := time.Now(); now.Before(next) {
+		r.Log.Info("Seed image re-generation is scheduled", "next", next.UTC().Format(time.RFC3339))
+		return requeueWithCustomInterval(next.Sub(now))
+	}
+
+	version, err := r.getClusterVersion(ctx)
+	if err != nil {
+		r.Log.Error(err, "Failed to check for seed cluster updates")
+		return requeueWithShortInterval()
+	}
+	seedgen.Status.LastScheduleTime = &metav1.Time{Time: time.Now()}
+
+	if last := lastGeneratedImage(seedgen); last != nil && last.Version == version {
+		r.Log.Info("Skipping scheduled seed image re-generation, as the seed cluster was not updated", "version", version)
+		return requeueImmediately()
+	}
+
+	r.Log.Info("Re-generating seed image as scheduled", "version", version, "image", seedImageForRun(seedgen))
+	seedgen.Status.Conditions = nil
+	return requeueImmediately()
+}
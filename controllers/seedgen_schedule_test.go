@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	seedgenv1 "github.com/openshift-kni/lifecycle-agent/api/seedgenerator/v1"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+func TestScheduledSeedImage(t *testing.T) {
+	scheduleTime := time.Date(2024, 10, 12, 3, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		name      string
+		seedImage string
+		expect    string
+	}{
+		{
+			name:      "tagged image",
+			seedImage: "quay.io/org/seed:4.16",
+			expect:    "quay.io/org/seed:4.16-20241012T030000Z",
+		},
+		{
+			name:      "untagged image",
+			seedImage: "quay.io/org/seed",
+			expect:    "quay.io/org/seed:latest-20241012T030000Z",
+		},
+		{
+			name:      "registry with port",
+			seedImage: "registry.example.com:5000/seed",
+			expect:    "registry.example.com:5000/seed:latest-20241012T030000Z",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, scheduledSeedImage(tc.seedImage, scheduleTime))
+		})
+	}
+}
+
+func TestPushedSeedImage(t *testing.T) {
+	scheduleTime := metav1.NewTime(time.Date(2024, 10, 12, 3, 0, 0, 0, time.UTC))
+	testcases := []struct {
+		name   string
+		spec   seedgenv1.SeedGeneratorSpec
+		status seedgenv1.SeedGeneratorStatus
+		expect string
+	}{
+		{
+			name:   "single architecture",
+			spec:   seedgenv1.SeedGeneratorSpec{SeedImage: "quay.io/org/seed:4.16"},
+			expect: "quay.io/org/seed:4.16",
+		},
+		{
+			name:   "multi-architecture",
+			spec:   seedgenv1.SeedGeneratorSpec{SeedImage: "quay.io/org/seed:4.16", MultiArch: true},
+			expect: "quay.io/org/seed:4.16-" + runtime.GOARCH,
+		},
+		{
+			name:   "untagged multi-architecture",
+			spec:   seedgenv1.SeedGeneratorSpec{SeedImage: "quay.io/org/seed", MultiArch: true},
+			expect: "quay.io/org/seed:latest-" + runtime.GOARCH,
+		},
+		{
+			name:   "scheduled multi-architecture",
+			spec:   seedgenv1.SeedGeneratorSpec{SeedImage: "quay.io/org/seed:4.16", MultiArch: true, Schedule: "0 3 * * 6"},
+			status: seedgenv1.SeedGeneratorStatus{LastScheduleTime: &scheduleTime},
+			expect: "quay.io/org/seed:4.16-20241012T030000Z-" + runtime.GOARCH,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			seedgen := &seedgenv1.SeedGenerator{Spec: tc.spec, Status: tc.status}
+			assert.Equal(t, tc.expect, pushedSeedImage(seedgen))
+		})
+	}
+}
+
+func TestNextScheduleTime(t *testing.T) {
+	created := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+	seedgen := &seedgenv1.SeedGenerator{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: created}},
+		Spec:       seedgenv1.SeedGeneratorSpec{SeedImage: "quay.io/org/seed:4.16", Schedule: "0 3 * * *"},
+	}
+
+	next, err := nextScheduleTime(seedgen)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 11, 3, 0, 0, 0, time.UTC), next.UTC())
+	assert.Equal(t, "quay.io/org/seed:4.16", seedImageForRun(seedgen), "first run pushes the seed image")
+
+	seedgen.Status.GeneratedImages = []seedgenv1.GeneratedSeedImage{
+		{Image: "quay.io/org/seed:4.16", GeneratedAt: metav1.Time{Time: time.Date(2024, 10, 11, 5, 0, 0, 0, time.UTC)}},
+	}
+	next, err = nextScheduleTime(seedgen)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 12, 3, 0, 0, 0, time.UTC), next.UTC())
+
+	seedgen.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2024, 10, 12, 3, 0, 1, 0, time.UTC)}
+	next, err = nextScheduleTime(seedgen)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 13, 3, 0, 0, 0, time.UTC), next.UTC())
+	assert.Equal(t, "quay.io/org/seed:4.16-20241012T030001Z", seedImageForRun(seedgen))
+
+	seedgen.Spec.Schedule = "every day"
+	_, err = nextScheduleTime(seedgen)
+	assert.Error(t, err)
+	assert.NotEmpty(t, validateSchedule(seedgen))
+}
+
+func TestAddGeneratedImage(t *testing.T) {
+	seedgen := &seedgenv1.SeedGenerator{
+		Spec: seedgenv1.SeedGeneratorSpec{Retention: &seedgenv1.SeedImageRetention{KeepImages: 2}},
+	}
+	assert.Empty(t, addGeneratedImage(seedgen, seedgenv1.GeneratedSeedImage{Image: "seed:1"}))
+	assert.Empty(t, addGeneratedImage(seedgen, seedgenv1.GeneratedSeedImage{Image: "seed:2"}))
+	assert.Equal(t, []seedgenv1.GeneratedSeedImage{{Image: "seed:1"}}, addGeneratedImage(seedgen, seedgenv1.GeneratedSeedImage{Image: "seed:3"}))
+	assert.Equal(t, []seedgenv1.GeneratedSeedImage{{Image: "seed:2"}, {Image: "seed:3"}}, seedgen.Status.GeneratedImages)
+
+	seedgen.Spec.Retention = nil
+	for range defaultKeepSeedImages {
+		addGeneratedImage(seedgen, seedgenv1.GeneratedSeedImage{Image: "seed:n"})
+	}
+	assert.Len(t, seedgen.Status.GeneratedImages, defaultKeepSeedImages)
+}
+
+func TestRecordGeneratedImage(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(configv1.GroupVersion, &configv1.ClusterVersion{})
+	version := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Status: configv1.ClusterVersionStatus{
+			Desired: configv1.Release{Version: "4.16.0", Image: "quay.io/openshift-release-dev/ocp-release@sha256:0123"},
+		},
+	}
+	recorder := record.NewFakeRecorder(1)
+	r := &SeedGeneratorReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(s).WithObjects(version).Build(),
+		Log:      logr.Discard(),
+		Recorder: recorder,
+		Executor: mockExecutor,
+	}
+	seedgen := &seedgenv1.SeedGenerator{Spec: seedgenv1.SeedGeneratorSpec{SeedImage: "quay.io/org/seed:4.16"}}
+
+	mockExecutor.EXPECT().Execute("skopeo", "inspect", "--retry-times", "10", "--authfile", seedgenAuthFile,
+		"--format", "json", "docker://quay.io/org/seed:4.16").
+		Return(`{"Labels": {"com.openshift.lifecycle-agent.seed_content_hash": "sha256:fedc"}, "LayersData": [{"Size": 100}, {"Size": 50}]}`, nil)
+	r.recordGeneratedImage(context.Background(), seedgen)
+
+	if assert.Len(t, seedgen.Status.GeneratedImages, 1) {
+		image := seedgen.Status.GeneratedImages[0]
+		assert.Equal(t, "quay.io/org/seed:4.16", image.Image)
+		assert.Equal(t, "4.16.0", image.Version)
+		assert.Equal(t, "quay.io/openshift-release-dev/ocp-release@sha256:0123", image.Release)
+		assert.Equal(t, int64(150), image.Size)
+		assert.Equal(t, "sha256:fedc", image.ContentHash)
+	}
+	assert.Contains(t, <-recorder.Events, "SeedImagePushed Seed image quay.io/org/seed:4.16 pushed")
+}
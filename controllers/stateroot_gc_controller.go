@@ -0,0 +1,234 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	corev1 "k8s.io/api/core/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	ipcv1 "github.com/openshift-kni/lifecycle-agent/api/ipconfig/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/imagemgmt"
+	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+)
+
+// staterootGCInterval is how often the stateroot retention is applied, for the stateroots to be pruned once they are
+// older than the KeepDays of the retention
+const staterootGCInterval = time.Hour
+
+// staterootDeploymentTime returns when the stateroot was deployed, i.e. when its directory was created by the Prep stage
+var staterootDeploymentTime = func(stateroot string) (time.Time, error) {
+	info, err := osStat(common.PathOutsideChroot(common.GetStaterootPath(stateroot)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get the deployment time of stateroot %s: %w", stateroot, err)
+	}
+	return info.ModTime(), nil
+}
+
+// staterootInfo is an unbooted stateroot considered by the stateroot retention
+type staterootInfo struct {
+	Name       string
+	DeployedAt time.Time
+}
+
+// selectRetainedStateroots returns the stateroots kept by the retention: the KeepLast most recently deployed ones, and
+// the ones deployed within the last KeepDays
+func selectRetainedStateroots(stateroots []staterootInfo, retention *ibuv1.StaterootRetention, now time.Time) map[string]bool {
+	sorted := append([]staterootInfo(nil), stateroots...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DeployedAt.After(sorted[j].DeployedAt) })
+
+	retained := map[string]bool{}
+	for i, stateroot := range sorted {
+		if i < retention.KeepLast ||
+			retention.KeepDays > 0 && now.Sub(stateroot.DeployedAt) < time.Duration(retention.KeepDays)*24*time.Hour {
+			retained[stateroot.Name] = true
+		}
+	}
+	return retained
+}
+
+// retainedStateroots returns the unbooted stateroots kept by the stateroot retention of the IBU, if any. The stateroot
+// of the upgrade is never kept when it is not booted, i.e. once the upgrade is aborted or rolled back.
+func retainedStateroots(rpmOstreeClient rpmostreeclient.IClient, ibu *ibuv1.ImageBasedUpgrade, now time.Time) (map[string]bool, error) {
+	retention := ibu.Spec.StaterootRetention
+	if retention == nil {
+		return nil, nil
+	}
+
+	status, err := rpmOstreeClient.QueryStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status with rpmostree: %w", err)
+	}
+
+	considered := map[string]bool{common.GetStaterootName(ibu.Spec.SeedImageRef.Version): true}
+	for _, deployment := range status.Deployments {
+		if deployment.Booted {
+			considered[deployment.OSName] = true
+		}
+	}
+
+	var stateroots []staterootInfo
+	for _, deployment := range status.Deployments {
+		if considered[deployment.OSName] {
+			continue
+		}
+		considered[deployment.OSName] = true
+
+		deployedAt, err := staterootDeploymentTime(deployment.OSName)
+		if err != nil {
+			return nil, err
+		}
+		stateroots = append(stateroots, staterootInfo{Name: deployment.OSName, DeployedAt: deployedAt})
+	}
+
+	return selectRetainedStateroots(stateroots, retention, now), nil
+}
+
+// StaterootGCReconciler prunes the unbooted stateroots, and the precached images they no longer use, per the stateroot
+// retention of the ImageBasedUpgrade, between upgrades
+type StaterootGCReconciler struct {
+	client.Client
+	NoncachedClient client.Reader
+	Log             logr.Logger
+	Mux             *sync.Mutex
+	Ops             ops.Ops
+	OstreeClient    ostreeclient.IClient
+	RPMOstreeClient rpmostreeclient.IClient
+	ImageMgmtClient imagemgmt.ImageMgmtIntf
+	Recorder        record.EventRecorder
+}
+
+// Reconcile applies the stateroot retention once the IBU is back to Idle, and periodically afterwards
+func (r *StaterootGCReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.Mux != nil {
+		r.Mux.Lock()
+		defer r.Mux.Unlock()
+	}
+
+	ibu := &ibuv1.ImageBasedUpgrade{}
+	if err := r.NoncachedClient.Get(ctx, req.NamespacedName, ibu); err != nil {
+		if errors.IsNotFound(err) {
+			return doNotRequeue(), nil
+		}
+		return requeueWithError(fmt.Errorf("failed to get ImageBasedUpgrade: %w", err))
+	}
+	if ibu.Spec.StaterootRetention == nil {
+		return doNotRequeue(), nil
+	}
+
+	// The Prep stage and the IP configuration deploy new stateroots, which are only pruned by their own cleanup
+	if ibu.Spec.Stage != ibuv1.Stages.Idle || !utils.IsIdleConditionTrue(ibu.Status.Conditions) {
+		r.Log.Info("Upgrade in progress, the stateroot retention is applied once it is finalized or aborted")
+		return doNotRequeue(), nil
+	}
+	ipc := &ipcv1.IPConfig{}
+	if err := r.NoncachedClient.Get(ctx, types.NamespacedName{Name: common.IPConfigName}, ipc); err == nil {
+		if ipc.Spec.Stage != ipcv1.IPStages.Idle {
+			r.Log.Info("IP configuration in progress, the stateroot retention is applied once it is back to Idle")
+			return requeueWithLongInterval(), nil
+		}
+	} else if !errors.IsNotFound(err) {
+		return requeueWithError(fmt.Errorf("failed to get IPConfig: %w", err))
+	}
+
+	pruned, err := r.pruneStateroots(ibu)
+	if err != nil {
+		return requeueWithError(err)
+	}
+	if len(pruned) > 0 {
+		utils.EmitEvent(r.Recorder, ibu, corev1.EventTypeNormal, utils.EventReasonStaterootPruned,
+			fmt.Sprintf("Pruned the unbooted stateroots %s per the stateroot retention", strings.Join(pruned, ", ")))
+
+		r.Log.Info("Removing the unused precached images of the pruned stateroots")
+		if err := cleanupContainerStorage(r.Log, r.ImageMgmtClient, ibu); err != nil {
+			return requeueWithError(fmt.Errorf("failed container storage cleanup: %w", err))
+		}
+	}
+
+	return requeueWithCustomInterval(staterootGCInterval), nil
+}
+
+// pruneStateroots removes the unbooted stateroots that are not kept by the retention, returning their names
+func (r *StaterootGCReconciler) pruneStateroots(ibu *ibuv1.ImageBasedUpgrade) ([]string, error) {
+	retained, err := retainedStateroots(r.RPMOstreeClient, ibu, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply the stateroot retention: %w", err)
+	}
+
+	status, err := r.RPMOstreeClient.QueryStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status with rpmostree: %w", err)
+	}
+	booted := map[string]bool{}
+	for _, deployment := range status.Deployments {
+		if deployment.Booted {
+			booted[deployment.OSName] = true
+		}
+	}
+	var pruned []string
+	for _, deployment := range status.Deployments {
+		if !booted[deployment.OSName] && !retained[deployment.OSName] && !lo.Contains(pruned, deployment.OSName) {
+			pruned = append(pruned, deployment.OSName)
+		}
+	}
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+
+	r.Log.Info("Pruning unbooted stateroots per the stateroot retention", "stateroots", pruned)
+	if err := cleanupUnbootedStateroots(r.Log, r.Ops, r.OstreeClient, r.RPMOstreeClient, retained); err != nil {
+		return nil, fmt.Errorf("failed to prune the unbooted stateroots: %w", err)
+	}
+	return pruned, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *StaterootGCReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	//nolint:wrapcheck
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("stateroot-gc").
+		For(&ibuv1.ImageBasedUpgrade{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return e.Object.GetName() == utils.IBUName },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return e.ObjectNew.GetName() == utils.IBUName },
+			DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+			GenericFunc: func(e event.GenericEvent) bool { return false },
+		})).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1, RateLimiter: failureRateLimiter()}).
+		Complete(r)
+}
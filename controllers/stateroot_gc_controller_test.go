@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/imagemgmt"
+	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+)
+
+func TestSelectRetainedStateroots(t *testing.T) {
+	now := time.Now()
+	stateroots := []staterootInfo{
+		{Name: "rhcos", DeployedAt: now.Add(-30 * 24 * time.Hour)},
+		{Name: "rhcos_4.15.0", DeployedAt: now.Add(-2 * time.Hour)},
+		{Name: "rhcos_4.14.0", DeployedAt: now.Add(-10 * 24 * time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		retention ibuv1.StaterootRetention
+		expected  map[string]bool
+	}{
+		{
+			name:     "nothing kept",
+			expected: map[string]bool{},
+		},
+		{
+			name:      "keep last",
+			retention: ibuv1.StaterootRetention{KeepLast: 2},
+			expected:  map[string]bool{"rhcos_4.15.0": true, "rhcos_4.14.0": true},
+		},
+		{
+			name:      "keep days",
+			retention: ibuv1.StaterootRetention{KeepDays: 1},
+			expected:  map[string]bool{"rhcos_4.15.0": true},
+		},
+		{
+			name:      "kept by either rule",
+			retention: ibuv1.StaterootRetention{KeepLast: 1, KeepDays: 15},
+			expected:  map[string]bool{"rhcos_4.15.0": true, "rhcos_4.14.0": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, selectRetainedStateroots(stateroots, &tt.retention, now))
+		})
+	}
+}
+
+func TestStaterootGCReconciler_Reconcile(t *testing.T) {
+	deployments := []rpmostreeclient.Deployment{
+		{OSName: "rhcos_4.16.0", Booted: true},
+		{OSName: "rhcos_4.15.0"},
+		{OSName: "rhcos"},
+	}
+	deployedAt := map[string]time.Time{
+		"rhcos_4.15.0": time.Now().Add(-24 * time.Hour),
+		"rhcos":        time.Now().Add(-48 * time.Hour),
+	}
+
+	origStaterootDeploymentTime, origOsStat, origOsReadDir := staterootDeploymentTime, osStat, osReadDir
+	defer func() {
+		staterootDeploymentTime, osStat, osReadDir = origStaterootDeploymentTime, origOsStat, origOsReadDir
+	}()
+	staterootDeploymentTime = func(stateroot string) (time.Time, error) { return deployedAt[stateroot], nil }
+	osStat = func(name string) (os.FileInfo, error) { return os.Stat(".") }
+	osReadDir = func(name string) ([]os.DirEntry, error) { return []os.DirEntry{}, nil }
+
+	tests := []struct {
+		name           string
+		stage          ibuv1.ImageBasedUpgradeStage
+		conditions     []metav1.Condition
+		retention      *ibuv1.StaterootRetention
+		expectedPruned []string
+		expectedResult ctrl.Result
+	}{
+		{
+			name:           "no retention",
+			stage:          ibuv1.Stages.Idle,
+			conditions:     []metav1.Condition{{Type: string(utils.ConditionTypes.Idle), Status: metav1.ConditionTrue}},
+			expectedResult: doNotRequeue(),
+		},
+		{
+			name:           "upgrade in progress",
+			stage:          ibuv1.Stages.Prep,
+			retention:      &ibuv1.StaterootRetention{KeepLast: 1},
+			expectedResult: doNotRequeue(),
+		},
+		{
+			name:           "prune the oldest stateroot",
+			stage:          ibuv1.Stages.Idle,
+			conditions:     []metav1.Condition{{Type: string(utils.ConditionTypes.Idle), Status: metav1.ConditionTrue}},
+			retention:      &ibuv1.StaterootRetention{KeepLast: 1},
+			expectedPruned: []string{"rhcos"},
+			expectedResult: requeueWithCustomInterval(staterootGCInterval),
+		},
+		{
+			name:           "all stateroots retained",
+			stage:          ibuv1.Stages.Idle,
+			conditions:     []metav1.Condition{{Type: string(utils.ConditionTypes.Idle), Status: metav1.ConditionTrue}},
+			retention:      &ibuv1.StaterootRetention{KeepDays: 7},
+			expectedResult: requeueWithCustomInterval(staterootGCInterval),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			mockOstree := ostreeclient.NewMockIClient(mockController)
+			mockRPMOstree := rpmostreeclient.NewMockIClient(mockController)
+			mockOps := ops.NewMockOps(mockController)
+			mockImageMgmt := imagemgmt.NewMockImageMgmtIntf(mockController)
+
+			mockRPMOstree.EXPECT().QueryStatus().Return(&rpmostreeclient.Status{Deployments: deployments}, nil).AnyTimes()
+			if len(tt.expectedPruned) > 0 {
+				for _, stateroot := range tt.expectedPruned {
+					for i, deployment := range deployments {
+						if deployment.OSName == stateroot {
+							mockOstree.EXPECT().Undeploy(i)
+						}
+					}
+					mockOps.EXPECT().RunBashInHostNamespace("unshare", "-m", "/bin/sh", "-c",
+						fmt.Sprintf("\"mount -o remount,rw /sysroot && rm -rf /ostree/deploy/%s\"", stateroot))
+				}
+				mockRPMOstree.EXPECT().RpmOstreeCleanup().Return(nil)
+				mockImageMgmt.EXPECT().CheckDiskUsageAgainstThreshold(common.ContainerStorageUsageThresholdPercentDefault).Return(false, nil)
+			}
+
+			ibu := &ibuv1.ImageBasedUpgrade{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.IBUName},
+				Spec: ibuv1.ImageBasedUpgradeSpec{
+					Stage:              tt.stage,
+					SeedImageRef:       ibuv1.SeedImageRef{Version: "4.16.0"},
+					StaterootRetention: tt.retention,
+				},
+				Status: ibuv1.ImageBasedUpgradeStatus{Conditions: tt.conditions},
+			}
+			c, err := getFakeClientFromObjects(ibu)
+			assert.NoError(t, err)
+
+			r := &StaterootGCReconciler{
+				Client:          c,
+				NoncachedClient: c,
+				Log:             logr.Discard(),
+				Ops:             mockOps,
+				OstreeClient:    mockOstree,
+				RPMOstreeClient: mockRPMOstree,
+				ImageMgmtClient: mockImageMgmt,
+			}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: utils.IBUName}})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
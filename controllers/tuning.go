@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Environment variables of the LCA manager tuning the reconcile throttling. The intervals are in the Go duration format,
+// e.g. 90s or 2m.
+const (
+	EnvRequeueShortInterval       = "LCA_REQUEUE_SHORT_INTERVAL"
+	EnvRequeueMediumInterval      = "LCA_REQUEUE_MEDIUM_INTERVAL"
+	EnvRequeueLongInterval        = "LCA_REQUEUE_LONG_INTERVAL"
+	EnvRequeueHealthCheckInterval = "LCA_REQUEUE_HEALTH_CHECK_INTERVAL"
+	EnvFailureBackoffBaseDelay    = "LCA_FAILURE_BACKOFF_BASE_DELAY"
+	EnvFailureBackoffMaxDelay     = "LCA_FAILURE_BACKOFF_MAX_DELAY"
+	EnvClientQPS                  = "LCA_CLIENT_QPS"
+	EnvClientBurst                = "LCA_CLIENT_BURST"
+)
+
+// Tuning defines how often the reconcilers requeue and query the API, which can be relaxed for the LCA to compete
+// less with the workloads of a resource-starved SNO
+type Tuning struct {
+	// ShortInterval, MediumInterval and LongInterval are the intervals the reconcilers requeue at while waiting on a
+	// job or a resource
+	ShortInterval  time.Duration
+	MediumInterval time.Duration
+	LongInterval   time.Duration
+	// HealthCheckInterval is the interval the cluster health checks are retried at
+	HealthCheckInterval time.Duration
+	// BackoffBaseDelay and BackoffMaxDelay bound the exponential backoff of a reconcile failing repeatedly
+	BackoffBaseDelay time.Duration
+	BackoffMaxDelay  time.Duration
+	// ClientQPS and ClientBurst are the rate limits of the API clients, the client defaults being kept if 0
+	ClientQPS   float32
+	ClientBurst int
+}
+
+// DefaultTuning is the reconcile throttling used unless tuned
+var DefaultTuning = Tuning{
+	ShortInterval:       30 * time.Second,
+	MediumInterval:      time.Minute,
+	LongInterval:        5 * time.Minute,
+	HealthCheckInterval: 20 * time.Second,
+	BackoffBaseDelay:    5 * time.Millisecond,
+	BackoffMaxDelay:     1000 * time.Second,
+}
+
+// tuning is the reconcile throttling of the reconcilers, set once before the manager is started
+var tuning = DefaultTuning
+
+// SetTuning sets the reconcile throttling of the reconcilers. It must be called before the reconcilers are set up.
+func SetTuning(t Tuning) {
+	tuning = t
+}
+
+// TuningFromEnv returns the default reconcile throttling, overridden by the tuning environment variables
+func TuningFromEnv() (Tuning, error) {
+	t := DefaultTuning
+	for env, interval := range map[string]*time.Duration{
+		EnvRequeueShortInterval:       &t.ShortInterval,
+		EnvRequeueMediumInterval:      &t.MediumInterval,
+		EnvRequeueLongInterval:        &t.LongInterval,
+		EnvRequeueHealthCheckInterval: &t.HealthCheckInterval,
+		EnvFailureBackoffBaseDelay:    &t.BackoffBaseDelay,
+		EnvFailureBackoffMaxDelay:     &t.BackoffMaxDelay,
+	} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return t, fmt.Errorf("invalid %s %q, expecting a positive duration", env, value)
+		}
+		*interval = d
+	}
+	if t.BackoffMaxDelay < t.BackoffBaseDelay {
+		return t, fmt.Errorf("%s %s is lower than %s %s", EnvFailureBackoffMaxDelay, t.BackoffMaxDelay,
+			EnvFailureBackoffBaseDelay, t.BackoffBaseDelay)
+	}
+
+	if value := os.Getenv(EnvClientQPS); value != "" {
+		qps, err := strconv.ParseFloat(value, 32)
+		if err != nil || qps <= 0 {
+			return t, fmt.Errorf("invalid %s %q, expecting a positive number", EnvClientQPS, value)
+		}
+		t.ClientQPS = float32(qps)
+	}
+	if value := os.Getenv(EnvClientBurst); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst <= 0 {
+			return t, fmt.Errorf("invalid %s %q, expecting a positive integer", EnvClientBurst, value)
+		}
+		t.ClientBurst = burst
+	}
+	return t, nil
+}
+
+// ApplyClientRateLimits sets the rate limits of the API clients created from the config, if tuned
+func (t Tuning) ApplyClientRateLimits(cfg *rest.Config) {
+	if t.ClientQPS > 0 {
+		cfg.QPS = t.ClientQPS
+	}
+	if t.ClientBurst > 0 {
+		cfg.Burst = t.ClientBurst
+	}
+}
+
+// failureRateLimiter returns the rate limiter of the reconcile requests, backing off exponentially on the repeated
+// failures of a request. The overall rate limit of the controller-runtime default is left out, as the reconcilers
+// handle singleton CRs.
+func failureRateLimiter() workqueue.TypedRateLimiter[reconcile.Request] {
+	return workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](tuning.BackoffBaseDelay, tuning.BackoffMaxDelay)
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestTuningFromEnv(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		expected      Tuning
+		expectedError string
+	}{
+		{
+			name:     "defaults",
+			expected: DefaultTuning,
+		},
+		{
+			name: "tuned",
+			env: map[string]string{
+				EnvRequeueShortInterval:    "2m",
+				EnvFailureBackoffBaseDelay: "10s",
+				EnvFailureBackoffMaxDelay:  "10m",
+				EnvClientQPS:               "2.5",
+				EnvClientBurst:             "5",
+			},
+			expected: func() Tuning {
+				tuning := DefaultTuning
+				tuning.ShortInterval = 2 * time.Minute
+				tuning.BackoffBaseDelay = 10 * time.Second
+				tuning.BackoffMaxDelay = 10 * time.Minute
+				tuning.ClientQPS = 2.5
+				tuning.ClientBurst = 5
+				return tuning
+			}(),
+		},
+		{
+			name:          "invalid interval",
+			env:           map[string]string{EnvRequeueMediumInterval: "60"},
+			expectedError: "invalid " + EnvRequeueMediumInterval,
+		},
+		{
+			name:          "backoff max delay lower than the base delay",
+			env:           map[string]string{EnvFailureBackoffBaseDelay: "5m", EnvFailureBackoffMaxDelay: "1m"},
+			expectedError: EnvFailureBackoffMaxDelay + " 1m0s is lower than",
+		},
+		{
+			name:          "invalid burst",
+			env:           map[string]string{EnvClientBurst: "0"},
+			expectedError: "invalid " + EnvClientBurst,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			tuning, err := TuningFromEnv()
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tuning)
+		})
+	}
+}
+
+func TestTuningRequeue(t *testing.T) {
+	defer SetTuning(DefaultTuning)
+
+	tuned := DefaultTuning
+	tuned.ShortInterval = 3 * time.Minute
+	tuned.ClientQPS = 2
+	SetTuning(tuned)
+	assert.Equal(t, 3*time.Minute, requeueWithShortInterval().RequeueAfter)
+	assert.Equal(t, DefaultTuning.MediumInterval, requeueWithMediumInterval().RequeueAfter)
+
+	cfg := &rest.Config{QPS: 20, Burst: 30}
+	tuned.ApplyClientRateLimits(cfg)
+	assert.Equal(t, float32(2), cfg.QPS)
+	assert.Equal(t, 30, cfg.Burst)
+}
@@ -21,22 +21,33 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/audit"
 	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/checkpoint"
 	"github.com/openshift-kni/lifecycle-agent/internal/clusterconfig"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
 	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/internal/preservedpaths"
+	"github.com/openshift-kni/lifecycle-agent/internal/progress"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
 	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	"github.com/samber/lo"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -45,7 +56,7 @@ import (
 type (
 	UpgradeHandler interface {
 		HandleBackup(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error)
-		HandleRestore(ctx context.Context) (ctrl.Result, error)
+		HandleRestore(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error)
 		PostPivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error)
 		PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error)
 	}
@@ -63,13 +74,17 @@ type (
 		RPMOstreeClient rpmostreeclient.IClient
 		OstreeClient    ostreeclient.IClient
 		RebootClient    reboot.RebootIntf
+		Progress        *progress.Recorder
+		Audit           *audit.Log
+		Checkpoints     *checkpoint.Store
 	}
 )
 
 // Used to start and end phases in the Upgrade stage. Each of them must be used in exactly two places
 var (
-	UpgradePhasePrepivot  = "PrePivot"
-	UpgradePhasePostpivot = "PostPivot"
+	UpgradePhasePrepivot           = "PrePivot"
+	UpgradePhasePostpivot          = "PostPivot"
+	UpgradePhaseCustomHealthChecks = "CustomHealthChecks"
 )
 
 // handleUpgrade orchestrate main upgrade steps and update status as needed
@@ -131,19 +146,24 @@ func (u *UpgHandler) PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade)
 	}
 
 	u.Log.Info("Running health check for Upgrade (pre-pivot)")
-	if err := CheckHealth(ctx, u.NoncachedClient, u.Log); err != nil {
+	err := CheckHealth(ctx, u.NoncachedClient, u.Log, healthCheckOptions(ibu)...)
+	u.Progress.RecordHealthCheck(progress.PlatformHealthCheck, err)
+	if err != nil {
 		msg := fmt.Sprintf("Waiting for system to stabilize before Upgrade (pre-pivot) stage can continue: %s", err.Error())
 		u.Log.Info(msg)
 		utils.SetUpgradeStatusInProgress(ibu, msg)
+		utils.SetStageProgress(ibu, "Waiting for system to stabilize (pre-pivot)", 0)
 		return requeueWithHealthCheckInterval(), nil
 	}
 
 	utils.SetUpgradeStatusInProgress(ibu, "Backing up Application Data")
+	utils.SetStageProgress(ibu, "Backing up Application Data", 5)
 	if updateErr := utils.UpdateIBUStatus(ctx, u.Client, ibu); updateErr != nil {
 		u.Log.Error(updateErr, "failed to update IBU CR status")
 	}
 
 	u.Log.Info("Handling backups with OADP operator")
+	utils.StartPhase(u.Client, u.Log, ibu, utils.OADPPhaseBackup)
 	ctrlResult, err := u.HandleBackup(ctx, ibu)
 	if err != nil {
 		if backuprestore.IsBRFailedValidationError(err) ||
@@ -160,6 +180,10 @@ func (u *UpgHandler) PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade)
 		utils.SetUpgradeStatusInProgress(ibu, "Backup of Application Data is in progress")
 		return ctrlResult, nil
 	}
+	utils.StopPhase(u.Client, u.Log, ibu, utils.OADPPhaseBackup)
+
+	// The rest of the pre-pivot steps run within this reconcile, up to the reboot
+	u.Progress.SetState(progress.StatePreparingPivot)
 
 	u.Log.Info("Remounting sysroot")
 	if err := u.Ops.RemountSysroot(); err != nil {
@@ -170,12 +194,24 @@ func (u *UpgHandler) PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade)
 	staterootPath := getStaterootPath(stateroot)
 	staterootVarPath := getStaterootVarPath(stateroot)
 
+	// The steps completed before a restart of LCA or a reboot of the node, up to the pivot, are not run again, while
+	// the output of an interrupted export step is removed before it runs again
+	tracker := u.Checkpoints.For(string(ibuv1.Stages.Upgrade), stateroot)
+	if step := tracker.Resumed(); step != "" {
+		u.Log.Info("Resuming the Upgrade stage (pre-pivot)", "after", step)
+		utils.EmitEvent(u.Recorder, ibu, v1.EventTypeNormal, utils.EventReasonResumed,
+			fmt.Sprintf("Resuming the Upgrade stage after the %s step", step))
+	}
+
 	utils.SetUpgradeStatusInProgress(ibu, "Exporting Application Configuration")
+	utils.SetStageProgress(ibu, "Exporting Application Configuration", 20)
 	if updateErr := utils.UpdateIBUStatus(ctx, u.Client, ibu); updateErr != nil {
 		u.Log.Error(updateErr, "failed to update IBU CR status")
 	}
 
-	if err := u.exportOadpConfigurationAndRestore(ctx, ibu, staterootVarPath); err != nil {
+	if err := tracker.RunWithUnwind("export_oadp_configuration", func() error {
+		return u.exportOadpConfigurationAndRestore(ctx, ibu, staterootVarPath)
+	}, removeExportDirs(staterootVarPath, backuprestore.OadpPath)); err != nil {
 		if backuprestore.IsBRFailedError(err) || backuprestore.IsBRFailedValidationError(err) {
 			u.Log.Error(err, "Failed to export OADP configuration and restores")
 			utils.SetUpgradeStatusFailed(ibu, err.Error())
@@ -185,12 +221,15 @@ func (u *UpgHandler) PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade)
 	}
 
 	utils.SetUpgradeStatusInProgress(ibu, "Exporting Policy and Config Manifests")
+	utils.SetStageProgress(ibu, "Exporting Policy and Config Manifests", 25)
 	if updateErr := utils.UpdateIBUStatus(ctx, u.Client, ibu); updateErr != nil {
 		u.Log.Error(updateErr, "failed to update IBU CR status")
 	}
 
 	u.Log.Info("Writing extra-manifests into new stateroot")
-	if err := u.extractAndExportExtraManifests(ctx, ibu, staterootVarPath); err != nil {
+	if err := tracker.RunWithUnwind("export_extra_manifests", func() error {
+		return u.extractAndExportExtraManifests(ctx, ibu, staterootVarPath)
+	}, removeExportDirs(staterootVarPath, extramanifest.CmManifestPath, extramanifest.PolicyManifestPath)); err != nil {
 		if extramanifest.IsEMFailedError(err) {
 			u.Log.Error(err, "Failed to export manifests")
 			utils.SetUpgradeStatusFailed(ibu, err.Error())
@@ -199,18 +238,44 @@ func (u *UpgHandler) PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade)
 		return requeueWithError(fmt.Errorf("error while exporting manifests: %w", err))
 	}
 
+	u.Log.Info("Writing user-defined health checks into new stateroot")
+	if err := tracker.RunWithUnwind("export_health_checks", func() error {
+		return healthcheck.ExportCustomHealthChecksToDir(ctx, u.Client, ibu.Spec.HealthChecks, staterootVarPath)
+	}, removeExportDirs(staterootVarPath, healthcheck.CustomHealthChecksPath)); err != nil {
+		return requeueWithError(fmt.Errorf("error while exporting user-defined health checks: %w", err))
+	}
+
+	u.Log.Info("Writing preserved paths into new stateroot")
+	if err := tracker.RunWithUnwind("export_preserved_paths", func() error {
+		return preservedpaths.ExportPreservedPathsToDir(ctx, u.Client, u.Ops, u.Log, ibu.Spec.PreservedPaths, staterootVarPath)
+	}, removeExportDirs(staterootVarPath, preservedpaths.PreservedPathsPath)); err != nil {
+		if preservedpaths.IsSizeLimitError(err) {
+			u.Log.Error(err, "Failed to export preserved paths")
+			utils.SetUpgradeStatusFailed(ibu, err.Error())
+			return doNotRequeue(), nil
+		}
+		return requeueWithError(fmt.Errorf("error while exporting preserved paths: %w", err))
+	}
+
 	utils.SetUpgradeStatusInProgress(ibu, "Exporting Cluster and LVM configuration")
+	utils.SetStageProgress(ibu, "Exporting Cluster and LVM configuration", 30)
 	if updateErr := utils.UpdateIBUStatus(ctx, u.Client, ibu); updateErr != nil {
 		u.Log.Error(updateErr, "failed to update IBU CR status")
 	}
 
 	u.Log.Info("Writing cluster-configuration into new stateroot")
-	if err := u.ClusterConfig.FetchClusterConfig(ctx, staterootVarPath); err != nil {
+	if err := tracker.RunWithUnwind("export_cluster_config", func() error {
+		return u.ClusterConfig.FetchClusterConfig(ctx, staterootVarPath, &ibu.Spec)
+	}, removeExportDirs(staterootVarPath, filepath.Join(common.OptOpenshift, common.ClusterConfigDir),
+		filepath.Join(common.OptOpenshift, common.NetworkDir))); err != nil {
 		return requeueWithError(fmt.Errorf("error while fetching cluster configuration: %w", err))
 	}
 
 	u.Log.Info("Writing lvm-configuration into new stateroot")
-	if err := u.ClusterConfig.FetchLvmConfig(ctx, staterootVarPath); err != nil {
+	// The LocalVolume manifests written along with the cluster configuration are overwritten when the step runs again
+	if err := tracker.RunWithUnwind("export_lvm_config", func() error {
+		return u.ClusterConfig.FetchLvmConfig(ctx, staterootVarPath)
+	}, removeExportDirs(staterootVarPath, filepath.Join(common.OptOpenshift, common.LvmConfigDir))); err != nil {
 		return requeueWithError(fmt.Errorf("error while fetching LVM configuration: %w", err))
 	}
 
@@ -219,25 +284,39 @@ func (u *UpgHandler) PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade)
 
 	// close pre-pivot phase timer
 	utils.StopPhase(u.Client, u.Log, ibu, UpgradePhasePrepivot)
+	utils.SetStageProgress(ibu, "Rebooting to new stateroot", 35)
 
+	// The IBU CR is exported again on resume, so that the new stateroot gets its latest status
 	u.Log.Info("Save the IBU CR to the new state root before pivot")
 	if err := exportIBUToNewStateroot(ibu, staterootPath); err != nil {
 		return requeueWithError(fmt.Errorf("error while exporting IBU CR to the new state root: %w", err))
 	}
 
+	u.recordAudit(audit.FileModified, fmt.Sprintf("Exported the cluster, application and upgrade configuration to the %s stateroot", stateroot))
+
 	u.Log.Info("Save a copy of the IBU in the current stateroot for rollback")
 	if err := exportForUncontrolledRollback(ibu); err != nil {
 		return requeueWithError(fmt.Errorf("error while exporting for uncontrolled rollback: %w", err))
 	}
 
-	if err := u.setDefaultDeploymentToNewStateroot(stateroot); err != nil {
+	if err := tracker.Run("set_default_deployment", func() error {
+		return u.setDefaultDeploymentToNewStateroot(stateroot)
+	}); err != nil {
 		return requeueWithError(fmt.Errorf("error while setting default deployment: %w", err))
 	}
+	u.recordAudit(audit.Command, fmt.Sprintf("Set the %s stateroot as the default deployment and rebooted the node", stateroot))
+
+	// Carry the audit trail over, so that it is kept after the pivot
+	if err := u.Audit.CopyLog(filepath.Join(staterootPath, common.AuditLogFile)); err != nil {
+		u.Log.Error(err, "failed to export the audit log to the new state root")
+	}
 
 	// Write an event to indicate reboot attempt
 	u.Recorder.Event(ibu, v1.EventTypeNormal, "Reboot", "System will now reboot for upgrade")
+	u.Progress.SetState(progress.StateAwaitingReboot)
 	err = u.RebootClient.RebootToNewStateRoot("upgrade")
 	if err != nil {
+		u.Progress.SetState(progress.StateReconciling)
 		u.Log.Error(err, "Failed to reboot to new stateroot")
 		utils.SetUpgradeStatusFailed(ibu, err.Error())
 		return doNotRequeue(), nil
@@ -245,6 +324,26 @@ func (u *UpgHandler) PrePivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade)
 	return doNotRequeue(), nil
 }
 
+// recordAudit appends an event of the Upgrade stage to the audit log. The audit trail must not block the upgrade, so any
+// error is only logged.
+func (u *UpgHandler) recordAudit(eventType, message string) {
+	if err := u.Audit.Record(eventType, string(ibuv1.Stages.Upgrade), message); err != nil {
+		u.Log.Error(err, "failed to record the audit event", "type", eventType)
+	}
+}
+
+// removeExportDirs returns the unwind of an export step, removing the dirs it writes in the new stateroot
+func removeExportDirs(staterootVarPath string, dirs ...string) func() error {
+	return func() error {
+		for _, dir := range dirs {
+			if err := os.RemoveAll(filepath.Join(staterootVarPath, dir)); err != nil {
+				return fmt.Errorf("failed to remove the partially exported %s: %w", dir, err)
+			}
+		}
+		return nil
+	}
+}
+
 // exportOadpConfigurationAndRestore exports OADP configuration and restore CRs to the new stateroot
 func (u *UpgHandler) exportOadpConfigurationAndRestore(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, ostreeVarDir string) error {
 	if len(ibu.Spec.OADPContent) == 0 {
@@ -315,6 +414,15 @@ func exportIBUToNewStateroot(ibu *ibuv1.ImageBasedUpgrade, staterootPath string)
 	if err := lcautils.MarshalToFile(ibu, filePath); err != nil {
 		return fmt.Errorf("error while saving IBU CR to the new state root: %w", err)
 	}
+
+	// Carry the stage durations over, so that the completion of the next upgrades can still be estimated
+	durations, err := utils.ReadStageDurations(stageDurationsFile)
+	if err != nil {
+		return fmt.Errorf("error while reading the stage durations: %w", err)
+	}
+	if err := utils.WriteStageDurations(filepath.Join(staterootPath, utils.StageDurationsFilePath), durations); err != nil {
+		return fmt.Errorf("error while saving the stage durations to the new state root: %w", err)
+	}
 	return nil
 }
 
@@ -341,16 +449,92 @@ var getStaterootVarPath = func(stateroot string) string {
 // CheckHealth helper func to call HealthChecks
 var CheckHealth = healthcheck.HealthChecks
 
-func (u *UpgHandler) autoRollbackIfEnabled(ibu *ibuv1.ImageBasedUpgrade, msg string) {
-	// Check whether auto-rollback is disabled using annotation
-	if val, exists := ibu.GetAnnotations()[common.AutoRollbackOnFailureUpgradeCompletionAnnotation]; exists {
-		if val == common.AutoRollbackDisableValue {
-			u.Log.Info("Auto-rollback upgrade completion is disabled")
-			return
+// CheckCustomHealth helper func to call CustomHealthChecks
+var CheckCustomHealth = healthcheck.CustomHealthChecks
+
+// healthCheckOptions returns the health check options selected in the IBU spec
+func healthCheckOptions(ibu *ibuv1.ImageBasedUpgrade) []healthcheck.Option {
+	if ibu.Spec.HealthCheckConfig == nil {
+		return nil
+	}
+	return []healthcheck.Option{healthcheck.WithExcludedClusterOperators(ibu.Spec.HealthCheckConfig.ExcludedClusterOperators...)}
+}
+
+// The files of the LCA Init Monitor watchdog deadline, vars in order to override them in unit tests
+var (
+	autoRollbackDeadlineFile          = common.PathOutsideChroot(common.IBUAutoRollbackDeadlineFile)
+	autoRollbackDeadlineExtensionFile = common.PathOutsideChroot(common.IBUAutoRollbackDeadlineExtensionFile)
+)
+
+// getAutoRollbackDeadlineExtension returns the extension of the LCA Init Monitor watchdog deadline requested by the
+// annotation, or 0 if not requested
+func getAutoRollbackDeadlineExtension(ibu *ibuv1.ImageBasedUpgrade) (time.Duration, error) {
+	value, exists := ibu.GetAnnotations()[common.AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation]
+	if !exists {
+		return 0, nil
+	}
+	extension, err := time.ParseDuration(value)
+	if err != nil || extension < 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q, it must be a positive duration such as 2h",
+			common.AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation, value)
+	}
+	return extension.Truncate(time.Second), nil
+}
+
+// updateAutoRollbackDeadline reports the deadline of the LCA Init Monitor watchdog while it is running after the pivot,
+// and extends it as requested by the annotation. The caller is responsible for persisting the status.
+func (r *ImageBasedUpgradeReconciler) updateAutoRollbackDeadline(ibu *ibuv1.ImageBasedUpgrade, isAfterPivot bool) {
+	ibu.Status.AutoRollbackDeadline = nil
+	if !isAfterPivot || ibu.Spec.Stage != ibuv1.Stages.Upgrade {
+		return
+	}
+
+	deadline, err := reboot.ReadAutoRollbackDeadline(autoRollbackDeadlineFile)
+	if err != nil {
+		r.Log.Error(err, "Unable to get the LCA init monitor deadline")
+		return
+	}
+	if deadline.IsZero() {
+		// The watchdog is not running
+		return
+	}
+
+	extension, err := reboot.ReadAutoRollbackDeadlineExtension(autoRollbackDeadlineExtensionFile)
+	if err != nil {
+		r.Log.Error(err, "Unable to get the LCA init monitor deadline extension")
+	}
+	requested, err := getAutoRollbackDeadlineExtension(ibu)
+	if err != nil {
+		r.Log.Error(err, "Unable to extend the LCA init monitor deadline")
+		utils.EmitEvent(r.Recorder, ibu, v1.EventTypeWarning, utils.EventReasonAutoRollbackDeadline, err.Error())
+	} else if requested != extension {
+		if err := reboot.WriteAutoRollbackDeadlineExtension(autoRollbackDeadlineExtensionFile, requested); err != nil {
+			r.Log.Error(err, "Unable to extend the LCA init monitor deadline")
+		} else {
+			extension = requested
+			msg := fmt.Sprintf("LCA init monitor deadline extended by %s, to %s", extension,
+				deadline.Add(extension).UTC().Format(time.RFC3339))
+			r.Log.Info(msg)
+			utils.EmitEvent(r.Recorder, ibu, v1.EventTypeNormal, utils.EventReasonAutoRollbackDeadline, msg)
 		}
 	}
 
+	ibu.Status.AutoRollbackDeadline = &metav1.Time{Time: deadline.Add(extension)}
+}
+
+func (u *UpgHandler) autoRollbackIfEnabled(ibu *ibuv1.ImageBasedUpgrade, msg string) {
+	// Check whether auto-rollback is disabled using spec or annotation
+	var upgradeCompletion *bool
+	if ibu.Spec.AutoRollbackOnFailure != nil {
+		upgradeCompletion = ibu.Spec.AutoRollbackOnFailure.UpgradeCompletion
+	}
+	if !common.IsAutoRollbackEnabled(upgradeCompletion, ibu.GetAnnotations(), common.AutoRollbackOnFailureUpgradeCompletionAnnotation) {
+		u.Log.Info("Auto-rollback upgrade completion is disabled")
+		return
+	}
+
 	u.Log.Info("Automatically rolling back due to failure")
+	utils.EmitEvent(u.Recorder, ibu, v1.EventTypeWarning, utils.EventReasonAutoRollback, msg)
 
 	if err := u.RebootClient.InitiateRollback(msg); err != nil {
 		u.Log.Info(fmt.Sprintf("Unable to auto rollback: %s", err))
@@ -365,17 +549,70 @@ func (u *UpgHandler) autoRollbackIfEnabled(ibu *ibuv1.ImageBasedUpgrade, msg str
 //
 // Note: All decisions, including reconciles and failures, should be made within this function.
 // The caller will simply return what this function returns.
+// resumeWorkerNodesRollout unpauses the worker MachineConfigPool, paused by the post-pivot of the control plane node, so
+// that the additional worker nodes attached to the SNO are rebooted into the new release. It returns the worker nodes
+// recorded on the pool, if any.
+func resumeWorkerNodesRollout(ctx context.Context, c client.Client, log logr.Logger) ([]string, error) {
+	if c == nil {
+		// In UT code
+		return nil, nil
+	}
+
+	mcp := &mcv1.MachineConfigPool{}
+	if err := c.Get(ctx, client.ObjectKey{Name: common.WorkerMachineConfigPool}, mcp); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s machineConfigPool: %w", common.WorkerMachineConfigPool, err)
+	}
+	nodes, ok := mcp.GetAnnotations()[common.UpgradeWorkerNodesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	if mcp.Spec.Paused {
+		log.Info("Resuming the rollout of the worker nodes", "nodes", nodes)
+		patch := client.MergeFrom(mcp.DeepCopy())
+		mcp.Spec.Paused = false
+		if err := c.Patch(ctx, mcp, patch); err != nil {
+			return nil, fmt.Errorf("failed to resume %s machineConfigPool: %w", common.WorkerMachineConfigPool, err)
+		}
+	}
+	return strings.Split(nodes, ","), nil
+}
+
+// completeWorkerNodesRollout removes the worker nodes recorded on the worker MachineConfigPool once they rejoined the
+// cluster
+func completeWorkerNodesRollout(ctx context.Context, c client.Client) error {
+	mcp := &mcv1.MachineConfigPool{}
+	if err := c.Get(ctx, client.ObjectKey{Name: common.WorkerMachineConfigPool}, mcp); err != nil {
+		return fmt.Errorf("failed to get %s machineConfigPool: %w", common.WorkerMachineConfigPool, err)
+	}
+	patch := client.MergeFrom(mcp.DeepCopy())
+	delete(mcp.Annotations, common.UpgradeWorkerNodesAnnotation)
+	if err := c.Patch(ctx, mcp, patch); err != nil {
+		return fmt.Errorf("failed to update %s machineConfigPool: %w", common.WorkerMachineConfigPool, err)
+	}
+	return nil
+}
+
 func (u *UpgHandler) PostPivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
 	// start post-pivot phase timer
 	utils.StartPhase(u.Client, u.Log, ibu, UpgradePhasePostpivot)
 
 	u.Log.Info("Starting health check for different components")
-	if err := CheckHealth(ctx, u.NoncachedClient, u.Log); err != nil {
+	// The rollout of the additional worker nodes, if any, is held until the upgrade of the control plane completes
+	// The klusterlet restored along with the cluster configuration must have re-registered with the hub
+	err := CheckHealth(ctx, u.NoncachedClient, u.Log,
+		append(healthCheckOptions(ibu), healthcheck.WithControlPlaneOnly(), healthcheck.WithKlusterletRegistration())...)
+	u.Progress.RecordHealthCheck(progress.PlatformHealthCheck, err)
+	if err != nil {
 		utils.SetUpgradeStatusInProgress(ibu, fmt.Sprintf("Waiting for system to stabilize: %s", err.Error()))
+		utils.SetStageProgress(ibu, "Waiting for system to stabilize (post-pivot)", 50)
 		return requeueWithHealthCheckInterval(), nil
 	}
 
-	err := u.BackupRestore.EnsureOadpConfiguration(ctx)
+	err = u.BackupRestore.EnsureOadpConfiguration(ctx)
 	if err != nil {
 		if backuprestore.IsBRStorageBackendUnavailableError(err) {
 			u.Log.Error(err, "Failed to ensure OADP configuration")
@@ -389,6 +626,7 @@ func (u *UpgHandler) PostPivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade
 
 	// Applying extra manifests
 	utils.SetUpgradeStatusInProgress(ibu, "Applying Policy Manifests")
+	utils.SetStageProgress(ibu, "Applying Policy Manifests", 60)
 	if updateErr := utils.UpdateIBUStatus(ctx, u.Client, ibu); updateErr != nil {
 		u.Log.Error(updateErr, "failed to update IBU CR status")
 	}
@@ -406,6 +644,7 @@ func (u *UpgHandler) PostPivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade
 	}
 
 	utils.SetUpgradeStatusInProgress(ibu, "Applying Config Manifests")
+	utils.SetStageProgress(ibu, "Applying Config Manifests", 70)
 	if updateErr := utils.UpdateIBUStatus(ctx, u.Client, ibu); updateErr != nil {
 		u.Log.Error(updateErr, "failed to update IBU CR status")
 	}
@@ -424,11 +663,13 @@ func (u *UpgHandler) PostPivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade
 
 	// Handling restores with OADP operator
 	utils.SetUpgradeStatusInProgress(ibu, "Restoring Application Data")
+	utils.SetStageProgress(ibu, "Restoring Application Data", 80)
 	if updateErr := utils.UpdateIBUStatus(ctx, u.Client, ibu); updateErr != nil {
 		u.Log.Error(updateErr, "failed to update IBU CR status")
 	}
 
-	result, err := u.HandleRestore(ctx)
+	utils.StartPhase(u.Client, u.Log, ibu, utils.OADPPhaseRestore)
+	result, err := u.HandleRestore(ctx, ibu)
 	if err != nil {
 		// Restore failed
 		if backuprestore.IsBRFailedError(err) {
@@ -445,12 +686,54 @@ func (u *UpgHandler) PostPivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade
 		utils.SetUpgradeStatusInProgress(ibu, "Restore of Application Data is in progress")
 		return result, nil
 	}
+	utils.StopPhase(u.Client, u.Log, ibu, utils.OADPPhaseRestore)
+
+	u.Log.Info("Starting user-defined health checks")
+	// The timeouts of the checks are counted from the first attempt, falling back to now if it was not recorded
+	utils.StartPhase(u.Client, u.Log, ibu, UpgradePhaseCustomHealthChecks)
+	waitingSince := utils.GetPhaseStartTime(ibu, UpgradePhaseCustomHealthChecks).Time
+	if waitingSince.IsZero() {
+		waitingSince = time.Now()
+	}
+	err = CheckCustomHealth(ctx, u.NoncachedClient, u.Log, common.PathOutsideChroot(healthcheck.CustomHealthChecksPath), waitingSince)
+	u.Progress.RecordHealthCheck(progress.CustomHealthCheck, err)
+	if err != nil {
+		if healthcheck.IsTimeoutError(err) {
+			u.Log.Error(err, "User-defined health checks timed out")
+			utils.SetUpgradeStatusFailed(ibu, err.Error())
+			u.autoRollbackIfEnabled(ibu, fmt.Sprintf("Rollback due to user-defined health checks timeout: %s", err))
+			return doNotRequeue(), nil
+		}
+		utils.SetUpgradeStatusInProgress(ibu, fmt.Sprintf("Waiting for user-defined health checks: %s", err.Error()))
+		utils.SetStageProgress(ibu, "Running user-defined health checks", 90)
+		return requeueWithHealthCheckInterval(), nil
+	}
+	utils.StopPhase(u.Client, u.Log, ibu, UpgradePhaseCustomHealthChecks)
 
 	if err := u.RebootClient.DisableInitMonitor(); err != nil {
 		// Don't fail the upgrade on failure here, just log it
 		u.Log.Error(err, "Unable to disable LCA init monitor")
 	}
 
+	workerNodes, err := resumeWorkerNodesRollout(ctx, u.Client, u.Log)
+	if err != nil {
+		utils.SetUpgradeStatusInProgress(ibu, fmt.Sprintf("Resuming the rollout of the worker nodes: Failure occurred: %s", err))
+		return requeueWithError(err)
+	}
+	if len(workerNodes) > 0 {
+		err = CheckHealth(ctx, u.NoncachedClient, u.Log, append(healthCheckOptions(ibu), healthcheck.WithWorkerNodes(workerNodes...))...)
+		u.Progress.RecordHealthCheck(progress.PlatformHealthCheck, err)
+		if err != nil {
+			utils.SetUpgradeStatusInProgress(ibu, fmt.Sprintf("Waiting for the worker nodes to rejoin the cluster: %s", err.Error()))
+			utils.SetStageProgress(ibu, "Waiting for the worker nodes to rejoin the cluster", 95)
+			return requeueWithHealthCheckInterval(), nil
+		}
+		if err := completeWorkerNodesRollout(ctx, u.Client); err != nil {
+			return requeueWithError(err)
+		}
+		u.Log.Info("Worker nodes rejoined the cluster", "nodes", workerNodes)
+	}
+
 	// stop post-pivot phase timer
 	utils.StopPhase(u.Client, u.Log, ibu, UpgradePhasePostpivot)
 	// stop Upgrade stage timer
@@ -458,6 +741,7 @@ func (u *UpgHandler) PostPivot(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade
 
 	u.Log.Info("Done handleUpgrade")
 	utils.SetUpgradeStatusCompleted(ibu)
+	utils.SetStageProgress(ibu, "Upgrade completed", 100)
 	return doNotRequeue(), nil
 }
 
@@ -477,6 +761,13 @@ func (u *UpgHandler) HandleBackup(ctx context.Context, ibu *ibuv1.ImageBasedUpgr
 		return requeueWithError(fmt.Errorf("failed to patch LVMS PVs with Retain as persistentVolumeReclaimPolicy: %w", err))
 	}
 
+	oadpConfig := getOADPConfig(ibu)
+	if err := u.BackupRestore.SetPersistentVolumeBackups(ctx, sortedBackupGroups, oadpConfig.PersistentVolumes); err != nil {
+		return requeueWithError(fmt.Errorf("failed to complete the backups of the LVMS persistent volumes: %w", err))
+	}
+	statuses := newOADPResourceStatuses(sortedBackupGroups)
+	defer func() { oadpStatus(ibu).Backups = statuses }()
+
 	// trigger and track each group
 	for index, backups := range sortedBackupGroups {
 		u.Log.Info("Processing backup", "groupIndex", index+1, "totalGroups", len(sortedBackupGroups))
@@ -490,6 +781,9 @@ func (u *UpgHandler) HandleBackup(ctx context.Context, ibu *ibuv1.ImageBasedUpgr
 		if err != nil {
 			return requeueWithError(fmt.Errorf("error while starting or tracking backup: %w", err))
 		}
+		setOADPResourcePhase(statuses, index+1, backupTracker.ProgressingBackups, oadpPhaseInProgress)
+		setOADPResourcePhase(statuses, index+1, backupTracker.SucceededBackups, oadpPhaseCompleted)
+		setOADPResourcePhase(statuses, index+1, backupTracker.FailedBackups, oadpPhaseFailed)
 
 		// The current backup group has done, work on the next group
 		if len(backupTracker.SucceededBackups) == len(backups) {
@@ -498,7 +792,29 @@ func (u *UpgHandler) HandleBackup(ctx context.Context, ibu *ibuv1.ImageBasedUpgr
 
 		// Backup CRs failed
 		if len(backupTracker.FailedBackups) > 0 {
-			errMsg := fmt.Sprintf("Failed backup CRs: %s", strings.Join(backupTracker.FailedBackups, ","))
+			if oadpConfig.BackupRetries > 0 {
+				retried, err := u.BackupRestore.RetryFailedBackups(ctx, backups, backupTracker.FailedBackups, oadpConfig.BackupRetries)
+				if err != nil {
+					return requeueWithError(fmt.Errorf("error while retrying failed backups: %w", err))
+				}
+				if retried {
+					return requeueWithShortInterval(), nil
+				}
+			}
+			if required := requiredFailures(backups, backupTracker.FailedBackups); len(required) > 0 {
+				errMsg := fmt.Sprintf("Failed backup CRs: %s", strings.Join(required, ","))
+				return requeueWithError(backuprestore.NewBRFailedError("Backup", errMsg))
+			}
+			// Only optional backups failed, their restores are skipped
+			if len(backupTracker.SucceededBackups)+len(backupTracker.FailedBackups) == len(backups) {
+				u.Log.Info("Optional backups failed, proceeding", "backups", backupTracker.FailedBackups)
+				continue
+			}
+		}
+
+		if oadpTimeoutExceeded(ibu, utils.OADPPhaseBackup, oadpConfig.BackupTimeoutSeconds) {
+			errMsg := fmt.Sprintf("Backup CRs not completed within %ds: %s", oadpConfig.BackupTimeoutSeconds,
+				strings.Join(slices.Concat(backupTracker.ProgressingBackups, backupTracker.PendingBackups), ","))
 			return requeueWithError(backuprestore.NewBRFailedError("Backup", errMsg))
 		}
 
@@ -512,10 +828,18 @@ func (u *UpgHandler) HandleBackup(ctx context.Context, ibu *ibuv1.ImageBasedUpgr
 	}
 
 	u.Log.Info("All backups succeeded")
+	var backupNames []string
+	for _, backups := range sortedBackupGroups {
+		for _, backup := range backups {
+			backupNames = append(backupNames, backup.GetName())
+		}
+	}
+	u.recordAudit(audit.Backup, fmt.Sprintf("OADP backups completed: %s", strings.Join(backupNames, ",")))
 	return doNotRequeue(), nil
 }
 
-func (u *UpgHandler) HandleRestore(ctx context.Context) (ctrl.Result, error) {
+// HandleRestore manages restore flow and returns with possible requeue
+func (u *UpgHandler) HandleRestore(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade) (ctrl.Result, error) {
 	u.Log.Info("Handling restores with OADP operator")
 	// Load restore CRs from files
 	sortedRestoreGroups, err := u.BackupRestore.LoadRestoresFromOadpRestorePath()
@@ -528,12 +852,28 @@ func (u *UpgHandler) HandleRestore(ctx context.Context) (ctrl.Result, error) {
 		return doNotRequeue(), nil
 	}
 
+	oadpConfig := getOADPConfig(ibu)
+	backuprestore.SetPersistentVolumeRestores(sortedRestoreGroups, oadpConfig.PersistentVolumes)
+	statuses := newOADPResourceStatuses(sortedRestoreGroups)
+	defer func() { oadpStatus(ibu).Restores = statuses }()
+
 	for index, restores := range sortedRestoreGroups {
 		u.Log.Info("Processing restore", "groupIndex", index+1, "totalGroups", len(sortedRestoreGroups))
+
+		// The restores of the optional backups that failed are skipped
+		restores, skipped := skipRestoresOfFailedBackups(ibu, restores)
+		setOADPResourcePhase(statuses, index+1, skipped, oadpPhaseSkipped)
+		if len(restores) == 0 {
+			continue
+		}
+
 		restoreTracker, err := u.BackupRestore.StartOrTrackRestore(ctx, restores)
 		if err != nil {
 			return requeueWithError(fmt.Errorf("error while starting or tracking restore: %w", err))
 		}
+		setOADPResourcePhase(statuses, index+1, restoreTracker.ProgressingRestores, oadpPhaseInProgress)
+		setOADPResourcePhase(statuses, index+1, restoreTracker.SucceededRestores, oadpPhaseCompleted)
+		setOADPResourcePhase(statuses, index+1, restoreTracker.FailedRestores, oadpPhaseFailed)
 
 		// The current restore group has done, work on the next group
 		if len(restoreTracker.SucceededRestores) == len(restores) {
@@ -542,7 +882,29 @@ func (u *UpgHandler) HandleRestore(ctx context.Context) (ctrl.Result, error) {
 
 		// Restore CRs failed
 		if len(restoreTracker.FailedRestores) > 0 {
-			errMsg := fmt.Sprintf("Failed restore CRs: %s", strings.Join(restoreTracker.FailedRestores, ","))
+			if oadpConfig.RestoreRetries > 0 {
+				retried, err := u.BackupRestore.RetryFailedRestores(ctx, restores, restoreTracker.FailedRestores, oadpConfig.RestoreRetries)
+				if err != nil {
+					return requeueWithError(fmt.Errorf("error while retrying failed restores: %w", err))
+				}
+				if retried {
+					return requeueWithShortInterval(), nil
+				}
+			}
+			if required := requiredFailures(restores, restoreTracker.FailedRestores); len(required) > 0 {
+				errMsg := fmt.Sprintf("Failed restore CRs: %s", strings.Join(required, ","))
+				return requeueWithError(backuprestore.NewBRFailedError("Restore", errMsg))
+			}
+			if len(restoreTracker.SucceededRestores)+len(restoreTracker.FailedRestores) == len(restores) {
+				u.Log.Info("Optional restores failed, proceeding", "restores", restoreTracker.FailedRestores)
+				continue
+			}
+		}
+
+		if oadpTimeoutExceeded(ibu, utils.OADPPhaseRestore, oadpConfig.RestoreTimeoutSeconds) {
+			notCompleted := slices.Concat(restoreTracker.ProgressingRestores, restoreTracker.PendingRestores, restoreTracker.MissingBackups)
+			errMsg := fmt.Sprintf("Restore CRs not completed within %ds: %s", oadpConfig.RestoreTimeoutSeconds,
+				strings.Join(notCompleted, ","))
 			return requeueWithError(backuprestore.NewBRFailedError("Restore", errMsg))
 		}
 
@@ -567,3 +929,89 @@ func (u *UpgHandler) HandleRestore(ctx context.Context) (ctrl.Result, error) {
 
 	return doNotRequeue(), nil
 }
+
+func getOADPConfig(ibu *ibuv1.ImageBasedUpgrade) ibuv1.OADPConfig {
+	if ibu.Spec.OADPConfig == nil {
+		return ibuv1.OADPConfig{}
+	}
+	return *ibu.Spec.OADPConfig
+}
+
+// Phases of the OADP CRs reported in the IBU status
+const (
+	oadpPhasePending    = "Pending"
+	oadpPhaseInProgress = "InProgress"
+	oadpPhaseCompleted  = "Completed"
+	oadpPhaseFailed     = "Failed"
+	oadpPhaseSkipped    = "Skipped"
+)
+
+func oadpStatus(ibu *ibuv1.ImageBasedUpgrade) *ibuv1.OADPStatus {
+	if ibu.Status.OADP == nil {
+		ibu.Status.OADP = &ibuv1.OADPStatus{}
+	}
+	return ibu.Status.OADP
+}
+
+// newOADPResourceStatuses lists the OADP CRs of the apply-wave groups as pending
+func newOADPResourceStatuses[T metav1.Object](groups [][]T) []ibuv1.OADPResourceStatus {
+	var statuses []ibuv1.OADPResourceStatus
+	for index, group := range groups {
+		for _, obj := range group {
+			statuses = append(statuses, ibuv1.OADPResourceStatus{
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+				Wave:      index + 1,
+				Phase:     oadpPhasePending,
+				Optional:  backuprestore.IsOptional(obj),
+			})
+		}
+	}
+	return statuses
+}
+
+func setOADPResourcePhase(statuses []ibuv1.OADPResourceStatus, wave int, names []string, phase string) {
+	for i := range statuses {
+		if statuses[i].Wave == wave && slices.Contains(names, statuses[i].Name) {
+			statuses[i].Phase = phase
+		}
+	}
+}
+
+// requiredFailures returns the failed CRs of the group that are not optional
+func requiredFailures[T metav1.Object](group []T, failed []string) []string {
+	return lo.Reject(failed, func(name string, _ int) bool {
+		obj, found := lo.Find(group, func(obj T) bool { return obj.GetName() == name })
+		return found && backuprestore.IsOptional(obj)
+	})
+}
+
+// skipRestoresOfFailedBackups filters out the restores of the optional backups that failed, as recorded in the IBU
+// status, returning the remaining restores and the names of the skipped ones
+func skipRestoresOfFailedBackups(ibu *ibuv1.ImageBasedUpgrade, restores []*velerov1.Restore) ([]*velerov1.Restore, []string) {
+	if ibu.Status.OADP == nil {
+		return restores, nil
+	}
+
+	var skipped []string
+	remaining := lo.Reject(restores, func(restore *velerov1.Restore, _ int) bool {
+		failed := lo.ContainsBy(ibu.Status.OADP.Backups, func(backup ibuv1.OADPResourceStatus) bool {
+			return backup.Optional && backup.Phase == oadpPhaseFailed &&
+				backup.Name == restore.Spec.BackupName && backup.Namespace == restore.GetNamespace()
+		})
+		if failed {
+			skipped = append(skipped, restore.GetName())
+		}
+		return failed
+	})
+	return remaining, skipped
+}
+
+// oadpTimeoutExceeded reports whether the OADP phase has been running for longer than the timeout, 0 meaning no limit
+func oadpTimeoutExceeded(ibu *ibuv1.ImageBasedUpgrade, phase string, timeoutSeconds int) bool {
+	if timeoutSeconds == 0 {
+		return false
+	}
+	start := utils.GetPhaseStartTime(ibu, phase)
+	return !start.IsZero() && time.Since(start.Time) > time.Duration(timeoutSeconds)*time.Second
+}
@@ -7,27 +7,34 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
 	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
 	mock_backuprestore "github.com/openshift-kni/lifecycle-agent/internal/backuprestore/mocks"
+	"github.com/openshift-kni/lifecycle-agent/internal/checkpoint"
 	mock_clusterconfig "github.com/openshift-kni/lifecycle-agent/internal/clusterconfig/mocks"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
 	mock_extramanifest "github.com/openshift-kni/lifecycle-agent/internal/extramanifest/mocks"
+	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	"go.uber.org/mock/gomock"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/yaml"
 )
 
@@ -138,6 +145,7 @@ func TestImageBasedUpgradeReconciler_handleBackup(t *testing.T) {
 
 			mockBackuprestore.EXPECT().GetSortedBackupsFromConfigmap(gomock.Any(), gomock.Any()).Return(tt.inputVelero, nil)
 			mockBackuprestore.EXPECT().PatchPVsReclaimPolicy(gomock.Any()).Return(nil)
+			mockBackuprestore.EXPECT().SetPersistentVolumeBackups(gomock.Any(), tt.inputVelero, ibuv1.PersistentVolumesPolicy("")).Return(nil)
 
 			for _, track := range tt.trackers {
 				mockBackuprestore.EXPECT().CleanupStaleBackups(gomock.Any(), gomock.Any()).Return(nil)
@@ -306,7 +314,7 @@ func TestImageBasedUpgradeReconciler_handleRestore(t *testing.T) {
 				Log:             logr.Logger{},
 				BackupRestore:   mockBackuprestore,
 			}
-			got, err := uph.HandleRestore(context.Background())
+			got, err := uph.HandleRestore(context.Background(), &ibuv1.ImageBasedUpgrade{})
 			if !tt.wantErr(t, err, fmt.Sprintf("handleRestore(%v, %v)", context.Background(), &ibuv1.ImageBasedUpgrade{})) {
 				return
 			}
@@ -316,6 +324,155 @@ func TestImageBasedUpgradeReconciler_handleRestore(t *testing.T) {
 	}
 }
 
+func TestImageBasedUpgradeReconciler_handleRestoreOADPConfig(t *testing.T) {
+	mockController := gomock.NewController(t)
+	mockBackuprestore := mock_backuprestore.NewMockBackuperRestorer(mockController)
+	defer func() {
+		mockController.Finish()
+	}()
+
+	newIBU := func(config *ibuv1.OADPConfig, phaseStart time.Time) *ibuv1.ImageBasedUpgrade {
+		return &ibuv1.ImageBasedUpgrade{
+			Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade, OADPConfig: config},
+			Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{{
+				Stage:  ibuv1.Stages.Upgrade,
+				Phases: []*ibuv1.Phase{{Phase: utils.OADPPhaseRestore, StartTime: metav1.NewTime(phaseStart)}},
+			}}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		ibu        *ibuv1.ImageBasedUpgrade
+		tracker    *backuprestore.RestoreTracker
+		retried    *bool
+		wantCtlRes controllerruntime.Result
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "failed restore retried",
+			ibu:        newIBU(&ibuv1.OADPConfig{RestoreRetries: 2}, time.Now()),
+			tracker:    &backuprestore.RestoreTracker{FailedRestores: []string{"name-failed"}},
+			retried:    BoolPointer(true),
+			wantCtlRes: requeueWithShortInterval(),
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "restore retries exhausted",
+			ibu:        newIBU(&ibuv1.OADPConfig{RestoreRetries: 2}, time.Now()),
+			tracker:    &backuprestore.RestoreTracker{FailedRestores: []string{"name-failed"}},
+			retried:    BoolPointer(false),
+			wantCtlRes: doNotRequeue(),
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "restore timed out",
+			ibu:        newIBU(&ibuv1.OADPConfig{RestoreTimeoutSeconds: 60}, time.Now().Add(-2*time.Minute)),
+			tracker:    &backuprestore.RestoreTracker{ProgressingRestores: []string{"name-progressing"}},
+			wantCtlRes: doNotRequeue(),
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "restore within timeout",
+			ibu:        newIBU(&ibuv1.OADPConfig{RestoreTimeoutSeconds: 600}, time.Now().Add(-2*time.Minute)),
+			tracker:    &backuprestore.RestoreTracker{ProgressingRestores: []string{"name-progressing"}},
+			wantCtlRes: requeueWithShortInterval(),
+			wantErr:    assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBackuprestore.EXPECT().LoadRestoresFromOadpRestorePath().Return([][]*velerov1.Restore{{&velerov1.Restore{}}}, nil).Times(1)
+			mockBackuprestore.EXPECT().StartOrTrackRestore(gomock.Any(), gomock.Any()).Return(tt.tracker, nil).Times(1)
+			if tt.retried != nil {
+				mockBackuprestore.EXPECT().RetryFailedRestores(gomock.Any(), gomock.Any(), tt.tracker.FailedRestores, 2).Return(*tt.retried, nil).Times(1)
+			}
+
+			uph := &UpgHandler{
+				Log:           logr.Logger{},
+				BackupRestore: mockBackuprestore,
+			}
+			got, err := uph.HandleRestore(context.Background(), tt.ibu)
+			if !tt.wantErr(t, err, "handleRestore") {
+				return
+			}
+			if err != nil {
+				assert.True(t, backuprestore.IsBRFailedError(err))
+			}
+			assert.Equalf(t, tt.wantCtlRes.RequeueAfter, got.RequeueAfter, "ctl interval: handleRestore")
+		})
+	}
+}
+
+func TestImageBasedUpgradeReconciler_optionalOADPResources(t *testing.T) {
+	mockController := gomock.NewController(t)
+	mockBackuprestore := mock_backuprestore.NewMockBackuperRestorer(mockController)
+	defer func() {
+		mockController.Finish()
+	}()
+
+	optional := map[string]string{backuprestore.OptionalAnn: "true"}
+	uph := &UpgHandler{
+		Log:           logr.Logger{},
+		BackupRestore: mockBackuprestore,
+	}
+	ibu := &ibuv1.ImageBasedUpgrade{}
+
+	// An optional backup failing does not fail the upgrade
+	backups := [][]*velerov1.Backup{
+		{
+			{ObjectMeta: metav1.ObjectMeta{Name: "platform", Namespace: "openshift-adp"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "openshift-adp", Annotations: optional}},
+		},
+		{{ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "openshift-adp"}}},
+	}
+	mockBackuprestore.EXPECT().GetSortedBackupsFromConfigmap(gomock.Any(), gomock.Any()).Return(backups, nil)
+	mockBackuprestore.EXPECT().PatchPVsReclaimPolicy(gomock.Any()).Return(nil)
+	mockBackuprestore.EXPECT().SetPersistentVolumeBackups(gomock.Any(), backups, gomock.Any()).Return(nil)
+	mockBackuprestore.EXPECT().CleanupStaleBackups(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	gomock.InOrder(
+		mockBackuprestore.EXPECT().StartOrTrackBackup(gomock.Any(), backups[0]).
+			Return(&backuprestore.BackupTracker{SucceededBackups: []string{"platform"}, FailedBackups: []string{"metrics"}}, nil),
+		mockBackuprestore.EXPECT().StartOrTrackBackup(gomock.Any(), backups[1]).
+			Return(&backuprestore.BackupTracker{ProgressingBackups: []string{"apps"}}, nil),
+	)
+
+	got, err := uph.HandleBackup(context.Background(), ibu)
+	assert.NoError(t, err)
+	assert.Equal(t, requeueWithShortInterval().RequeueAfter, got.RequeueAfter)
+	assert.Equal(t, []ibuv1.OADPResourceStatus{
+		{Name: "platform", Namespace: "openshift-adp", Wave: 1, Phase: oadpPhaseCompleted},
+		{Name: "metrics", Namespace: "openshift-adp", Wave: 1, Phase: oadpPhaseFailed, Optional: true},
+		{Name: "apps", Namespace: "openshift-adp", Wave: 2, Phase: oadpPhaseInProgress},
+	}, ibu.Status.OADP.Backups)
+
+	// The restore of the failed optional backup is skipped, and an optional restore failing does not fail the upgrade
+	restores := [][]*velerov1.Restore{
+		{
+			{ObjectMeta: metav1.ObjectMeta{Name: "platform", Namespace: "openshift-adp"}, Spec: velerov1.RestoreSpec{BackupName: "platform"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "openshift-adp"}, Spec: velerov1.RestoreSpec{BackupName: "metrics"}},
+		},
+		{{ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "openshift-adp", Annotations: optional}, Spec: velerov1.RestoreSpec{BackupName: "apps"}}},
+	}
+	mockBackuprestore.EXPECT().LoadRestoresFromOadpRestorePath().Return(restores, nil)
+	gomock.InOrder(
+		mockBackuprestore.EXPECT().StartOrTrackRestore(gomock.Any(), restores[0][:1]).
+			Return(&backuprestore.RestoreTracker{SucceededRestores: []string{"platform"}}, nil),
+		mockBackuprestore.EXPECT().StartOrTrackRestore(gomock.Any(), restores[1]).
+			Return(&backuprestore.RestoreTracker{FailedRestores: []string{"apps"}}, nil),
+	)
+	mockBackuprestore.EXPECT().RestorePVsReclaimPolicy(gomock.Any()).Return(nil)
+
+	got, err = uph.HandleRestore(context.Background(), ibu)
+	assert.NoError(t, err)
+	assert.Equal(t, doNotRequeue().RequeueAfter, got.RequeueAfter)
+	assert.Equal(t, []ibuv1.OADPResourceStatus{
+		{Name: "platform", Namespace: "openshift-adp", Wave: 1, Phase: oadpPhaseCompleted},
+		{Name: "metrics", Namespace: "openshift-adp", Wave: 1, Phase: oadpPhaseSkipped},
+		{Name: "apps", Namespace: "openshift-adp", Wave: 2, Phase: oadpPhaseFailed, Optional: true},
+	}, ibu.Status.OADP.Restores)
+}
+
 func TestImageBasedUpgradeReconciler_prePivot(t *testing.T) {
 
 	var (
@@ -748,6 +905,7 @@ func TestImageBasedUpgradeReconciler_prePivot(t *testing.T) {
 			}
 			if tt.getPatchPVsReclaimPolicyReturn != nil {
 				mockBackuprestore.EXPECT().PatchPVsReclaimPolicy(gomock.Any()).Return(tt.getPatchPVsReclaimPolicyReturn())
+				mockBackuprestore.EXPECT().SetPersistentVolumeBackups(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 			}
 			if tt.getCleanupStaleBackupsReturn != nil {
 				mockBackuprestore.EXPECT().CleanupStaleBackups(gomock.Any(), gomock.Any()).Return(tt.getCleanupStaleBackupsReturn())
@@ -773,7 +931,7 @@ func TestImageBasedUpgradeReconciler_prePivot(t *testing.T) {
 				mockExtramanifest.EXPECT().ExportExtraManifestToDir(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.exportExtraManifestToDirReturn()).Times(1)
 			}
 			if tt.fetchClusterConfigReturn != nil {
-				mockClusterconfig.EXPECT().FetchClusterConfig(gomock.Any(), gomock.Any()).Return(tt.fetchClusterConfigReturn()).Times(1)
+				mockClusterconfig.EXPECT().FetchClusterConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.fetchClusterConfigReturn()).Times(1)
 			}
 			if tt.fetchLvmConfigReturn != nil {
 				mockClusterconfig.EXPECT().FetchLvmConfig(gomock.Any(), gomock.Any()).Return(tt.fetchLvmConfigReturn()).Times(1)
@@ -783,7 +941,7 @@ func TestImageBasedUpgradeReconciler_prePivot(t *testing.T) {
 			defer func() {
 				CheckHealth = oldHC
 			}()
-			CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger) error {
+			CheckHealth = func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 				return tt.healthCheckError
 			}
 
@@ -882,6 +1040,26 @@ func TestImageBasedUpgradeReconciler_prePivot(t *testing.T) {
 	}
 }
 
+func TestRemoveExportDirs(t *testing.T) {
+	staterootVarPath := t.TempDir()
+	manifests := filepath.Join(staterootVarPath, extramanifest.CmManifestPath, "group1")
+	assert.NoError(t, os.MkdirAll(manifests, 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(manifests, "1_configmap.yaml"), []byte("partial"), 0o600))
+	checks := filepath.Join(staterootVarPath, healthcheck.CustomHealthChecksPath)
+	assert.NoError(t, os.MkdirAll(checks, 0o700))
+
+	// An interrupted export step is unwound before it runs again, leaving the output of the other steps
+	tracker := checkpoint.NewStore(filepath.Join(t.TempDir(), "checkpoints.json"), logr.Discard()).For("Upgrade", "rhcos_4.16.1")
+	unwind := removeExportDirs(staterootVarPath, extramanifest.CmManifestPath, extramanifest.PolicyManifestPath)
+	assert.Error(t, tracker.RunWithUnwind("export_extra_manifests", func() error { return fmt.Errorf("interrupted") }, unwind))
+	assert.DirExists(t, manifests)
+	assert.NoError(t, tracker.RunWithUnwind("export_extra_manifests", func() error {
+		assert.NoDirExists(t, filepath.Join(staterootVarPath, extramanifest.CmManifestPath))
+		return nil
+	}, unwind))
+	assert.DirExists(t, checks)
+}
+
 func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 	var (
 		mockController    = gomock.NewController(t)
@@ -908,7 +1086,7 @@ func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 		args                              args
 		want                              controllerruntime.Result
 		wantErr                           assert.ErrorAssertionFunc
-		checkHealthReturn                 func(ctx context.Context, c client.Reader, l logr.Logger) error
+		checkHealthReturn                 func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error
 		applyExtraManifestsReturn         func() error
 		applyPolicyManifestsReturn        func() error
 		ensureOadpConfigurationReturn     func() error
@@ -922,7 +1100,7 @@ func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 		{
 			name: "healthchecks return error",
 			args: args{ibu: &ibuv1.ImageBasedUpgrade{}},
-			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger) error {
+			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 				return fmt.Errorf("any error from hc")
 			},
 			wantConditions: []metav1.Condition{
@@ -939,7 +1117,7 @@ func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 		{
 			name: "extraManifests return error",
 			args: args{ibu: &ibuv1.ImageBasedUpgrade{}},
-			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger) error {
+			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 				return nil
 			},
 			ensureOadpConfigurationReturn: func() error {
@@ -973,7 +1151,7 @@ func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 		{
 			name: "RestoreOadpConfigurations return error",
 			args: args{ibu: &ibuv1.ImageBasedUpgrade{}},
-			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger) error {
+			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 				return nil
 			},
 			ensureOadpConfigurationReturn: func() error {
@@ -1001,7 +1179,7 @@ func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 		{
 			name: "handleRestore with restore error",
 			args: args{ibu: &ibuv1.ImageBasedUpgrade{}},
-			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger) error {
+			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 				return nil
 			},
 			applyPolicyManifestsReturn: func() error {
@@ -1041,7 +1219,7 @@ func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 		{
 			name: "upgrade completed",
 			args: args{ibu: &ibuv1.ImageBasedUpgrade{}},
-			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger) error {
+			checkHealthReturn: func(ctx context.Context, c client.Reader, l logr.Logger, opts ...healthcheck.Option) error {
 				return nil
 			},
 			applyPolicyManifestsReturn: func() error {
@@ -1136,3 +1314,128 @@ func TestImageBasedUpgradeReconciler_postPivot(t *testing.T) {
 		})
 	}
 }
+
+func TestResumeWorkerNodesRollout(t *testing.T) {
+	localScheme := runtime.NewScheme()
+	_ = mcv1.AddToScheme(localScheme)
+
+	// No worker pool
+	c := fake.NewClientBuilder().WithScheme(localScheme).Build()
+	nodes, err := resumeWorkerNodesRollout(context.TODO(), c, logr.Discard())
+	assert.NoError(t, err)
+	assert.Empty(t, nodes)
+
+	// Worker pool not paused by the post-pivot
+	c = fake.NewClientBuilder().WithScheme(localScheme).WithObjects(&mcv1.MachineConfigPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+	}).Build()
+	nodes, err = resumeWorkerNodesRollout(context.TODO(), c, logr.Discard())
+	assert.NoError(t, err)
+	assert.Empty(t, nodes)
+
+	c = fake.NewClientBuilder().WithScheme(localScheme).WithObjects(&mcv1.MachineConfigPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "worker",
+			Annotations: map[string]string{common.UpgradeWorkerNodesAnnotation: "worker-0,worker-1"},
+		},
+		Spec: mcv1.MachineConfigPoolSpec{Paused: true},
+	}).Build()
+	nodes, err = resumeWorkerNodesRollout(context.TODO(), c, logr.Discard())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"worker-0", "worker-1"}, nodes)
+
+	mcp := &mcv1.MachineConfigPool{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Name: "worker"}, mcp))
+	assert.False(t, mcp.Spec.Paused)
+
+	assert.NoError(t, completeWorkerNodesRollout(context.TODO(), c))
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Name: "worker"}, mcp))
+	assert.NotContains(t, mcp.Annotations, common.UpgradeWorkerNodesAnnotation)
+}
+
+func TestUpdateAutoRollbackDeadline(t *testing.T) {
+	deadline := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name              string
+		isAfterPivot      bool
+		watchdogRunning   bool
+		annotation        string
+		recordedExtension time.Duration
+		wantDeadline      *time.Time
+		wantExtension     time.Duration
+	}{
+		{
+			name:            "before pivot",
+			watchdogRunning: true,
+		},
+		{
+			name:         "watchdog not running",
+			isAfterPivot: true,
+		},
+		{
+			name:            "deadline not extended",
+			isAfterPivot:    true,
+			watchdogRunning: true,
+			wantDeadline:    &deadline,
+		},
+		{
+			name:            "deadline extended",
+			isAfterPivot:    true,
+			watchdogRunning: true,
+			annotation:      "2h",
+			wantDeadline:    lo.ToPtr(deadline.Add(2 * time.Hour)),
+			wantExtension:   2 * time.Hour,
+		},
+		{
+			name:              "extension removed",
+			isAfterPivot:      true,
+			watchdogRunning:   true,
+			recordedExtension: time.Hour,
+			wantDeadline:      &deadline,
+		},
+		{
+			name:              "invalid extension",
+			isAfterPivot:      true,
+			watchdogRunning:   true,
+			annotation:        "-1h",
+			recordedExtension: time.Hour,
+			wantDeadline:      lo.ToPtr(deadline.Add(time.Hour)),
+			wantExtension:     time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			autoRollbackDeadlineFile = filepath.Join(dir, "autorollback_deadline.json")
+			autoRollbackDeadlineExtensionFile = filepath.Join(dir, "autorollback_deadline_extension.json")
+			defer func() {
+				autoRollbackDeadlineFile = common.PathOutsideChroot(common.IBUAutoRollbackDeadlineFile)
+				autoRollbackDeadlineExtensionFile = common.PathOutsideChroot(common.IBUAutoRollbackDeadlineExtensionFile)
+			}()
+			if tt.watchdogRunning {
+				assert.NoError(t, reboot.WriteAutoRollbackDeadline(autoRollbackDeadlineFile, deadline))
+			}
+			if tt.recordedExtension > 0 {
+				assert.NoError(t, reboot.WriteAutoRollbackDeadlineExtension(autoRollbackDeadlineExtensionFile, tt.recordedExtension))
+			}
+
+			ibu := &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade}}
+			if tt.annotation != "" {
+				ibu.SetAnnotations(map[string]string{common.AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation: tt.annotation})
+			}
+			r := &ImageBasedUpgradeReconciler{Log: logr.Discard(), Recorder: record.NewFakeRecorder(10)}
+			r.updateAutoRollbackDeadline(ibu, tt.isAfterPivot)
+
+			if tt.wantDeadline == nil {
+				assert.Nil(t, ibu.Status.AutoRollbackDeadline)
+				return
+			}
+			if assert.NotNil(t, ibu.Status.AutoRollbackDeadline) {
+				assert.True(t, tt.wantDeadline.Equal(ibu.Status.AutoRollbackDeadline.Time), ibu.Status.AutoRollbackDeadline.String())
+			}
+			extension, err := reboot.ReadAutoRollbackDeadlineExtension(autoRollbackDeadlineExtensionFile)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantExtension, extension)
+		})
+	}
+}
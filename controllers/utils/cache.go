@@ -0,0 +1,23 @@
+package utils
+
+import (
+	kbatchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+// ManagerCacheOptions returns the cache options of the manager. The Jobs are only cached in the LCA namespace, so the
+// Jobs of other namespaces, such as the hook Jobs, must be read with the non-cached client.
+// See https://github.com/kubernetes-sigs/controller-runtime/blob/main/designs/cache_options.md
+func ManagerCacheOptions() cache.Options {
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&kbatchv1.Job{}: { // cache all job resources in LCA ns
+				Namespaces: map[string]cache.Config{
+					common.LcaNamespace: {}},
+			},
+		},
+	}
+}
@@ -28,18 +28,25 @@ var ConditionTypes = struct {
 	SeedGenCompleted   ConditionType
 	ConfigInProgress   ConditionType
 	ConfigCompleted    ConditionType
+	RollbackAvailable  ConditionType
+	Progressing        ConditionType
+	// DiagnosticsCollected references the diagnostics bundle collected on the node when the Upgrade or Rollback fails
+	DiagnosticsCollected ConditionType
 }{
-	Idle:               "Idle",
-	PrepInProgress:     "PrepInProgress",
-	PrepCompleted:      "PrepCompleted",
-	UpgradeInProgress:  "UpgradeInProgress",
-	UpgradeCompleted:   "UpgradeCompleted",
-	RollbackInProgress: "RollbackInProgress",
-	RollbackCompleted:  "RollbackCompleted",
-	SeedGenInProgress:  "SeedGenInProgress",
-	SeedGenCompleted:   "SeedGenCompleted",
-	ConfigInProgress:   "ConfigInProgress",
-	ConfigCompleted:    "ConfigCompleted",
+	Idle:                 "Idle",
+	PrepInProgress:       "PrepInProgress",
+	PrepCompleted:        "PrepCompleted",
+	UpgradeInProgress:    "UpgradeInProgress",
+	UpgradeCompleted:     "UpgradeCompleted",
+	RollbackInProgress:   "RollbackInProgress",
+	RollbackCompleted:    "RollbackCompleted",
+	SeedGenInProgress:    "SeedGenInProgress",
+	SeedGenCompleted:     "SeedGenCompleted",
+	ConfigInProgress:     "ConfigInProgress",
+	ConfigCompleted:      "ConfigCompleted",
+	RollbackAvailable:    "RollbackAvailable",
+	Progressing:          "Progressing",
+	DiagnosticsCollected: "DiagnosticsCollected",
 }
 
 var SeedGenConditionTypes = struct {
@@ -71,7 +78,13 @@ var ConditionReasons = struct {
 	FinalizeCompleted       ConditionReason
 	FinalizeFailed          ConditionReason
 	InvalidTransition       ConditionReason
+	Validated               ConditionReason
 	Blocked                 ConditionReason
+	Available               ConditionReason
+	StaterootRemoved        ConditionReason
+	CertificatesExpired     ConditionReason
+	Stalled                 ConditionReason
+	WaitingForWindow        ConditionReason
 }{
 	Idle:                    "Idle",
 	ConfigurationInProgress: "ConfigurationInProgress",
@@ -86,9 +99,19 @@ var ConditionReasons = struct {
 	FinalizeCompleted:       "FinalizeCompleted",
 	FinalizeFailed:          "FinalizeFailed",
 	InvalidTransition:       "InvalidTransition",
+	Validated:               "Validated",
 	// Blocked condition reason is used to specify IPC or IBU is blocked by each other.
 	// They are not allowed to run their flows simultaneously due to conflicts.
 	Blocked: "Blocked",
+	// Available, StaterootRemoved and CertificatesExpired are the reasons of the RollbackAvailable condition
+	Available:           "Available",
+	StaterootRemoved:    "StaterootRemoved",
+	CertificatesExpired: "CertificatesExpired",
+	// Stalled is the reason of the Progressing condition once the stage is in progress for longer than expected
+	Stalled: "Stalled",
+	// WaitingForWindow is the reason of the in progress condition of a stage whose transition is held until the
+	// maintenance window opens
+	WaitingForWindow: "WaitingForWindow",
 }
 
 // Common condition messages
@@ -102,6 +125,7 @@ const (
 	Aborting                 = "Aborting"
 	PrepCompleted            = "Prep completed"
 	PrepFailed               = "Prep failed"
+	PrepValidated            = "Prep validation completed"
 	UpgradeCompleted         = "Upgrade completed"
 	UpgradeFailed            = "Upgrade failed"
 	RollbackCompleted        = "Rollback completed"
@@ -121,6 +145,22 @@ var SeedGenConditionReasons = struct {
 	InProgress: "InProgress",
 }
 
+var PreflightConditionTypes = struct {
+	Completed ConditionType
+}{
+	Completed: "Completed",
+}
+
+var PreflightConditionReasons = struct {
+	InProgress ConditionReason
+	Passed     ConditionReason
+	Failed     ConditionReason
+}{
+	InProgress: "InProgress",
+	Passed:     "Passed",
+	Failed:     "Failed",
+}
+
 // SetStatusCondition is a convenience wrapper for meta.SetStatusCondition that takes in the types defined here and converts them to strings
 func SetStatusCondition(existingConditions *[]metav1.Condition, conditionType ConditionType, conditionReason ConditionReason, conditionStatus metav1.ConditionStatus, message string, generation int64) {
 	conditions := *existingConditions
@@ -389,6 +429,26 @@ func SetUpgradeStatusFailed(ibu *ibuv1.ImageBasedUpgrade, msg string) {
 		ibu.Generation)
 }
 
+// SetDiagnosticsCollected references the diagnostics bundle collected on the failure of the stage
+func SetDiagnosticsCollected(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage, path string) {
+	SetStatusCondition(&ibu.Status.Conditions,
+		ConditionTypes.DiagnosticsCollected,
+		ConditionReasons.Completed,
+		metav1.ConditionTrue,
+		fmt.Sprintf("Diagnostics bundle of the %s failure collected on the node at %s", stage, path),
+		ibu.Generation)
+}
+
+// SetDiagnosticsCollectionFailed reports that the diagnostics bundle could not be collected on the failure of the stage
+func SetDiagnosticsCollectionFailed(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage, msg string) {
+	SetStatusCondition(&ibu.Status.Conditions,
+		ConditionTypes.DiagnosticsCollected,
+		ConditionReasons.Failed,
+		metav1.ConditionFalse,
+		fmt.Sprintf("Failed to collect the diagnostics bundle of the %s failure: %s", stage, msg),
+		ibu.Generation)
+}
+
 // SetUpgradeStatusInProgress updates the upgrade status to in progress with message
 func SetUpgradeStatusInProgress(ibu *ibuv1.ImageBasedUpgrade, msg string) {
 	SetStatusCondition(&ibu.Status.Conditions,
@@ -473,6 +533,28 @@ func SetPrepStatusCompleted(ibu *ibuv1.ImageBasedUpgrade, msg string) {
 		ibu.Generation)
 }
 
+// SetPrepStatusValidated updates the prep status to completed for a validate-only Prep
+func SetPrepStatusValidated(ibu *ibuv1.ImageBasedUpgrade, msg string) {
+	SetStatusCondition(&ibu.Status.Conditions,
+		GetInProgressConditionType(ibuv1.Stages.Prep),
+		ConditionReasons.Validated,
+		metav1.ConditionFalse,
+		PrepValidated,
+		ibu.Generation)
+	SetStatusCondition(&ibu.Status.Conditions,
+		GetCompletedConditionType(ibuv1.Stages.Prep),
+		ConditionReasons.Validated,
+		metav1.ConditionTrue,
+		msg,
+		ibu.Generation)
+}
+
+// IsPrepValidated checks if the Prep stage completed as a validate-only dry run
+func IsPrepValidated(ibu *ibuv1.ImageBasedUpgrade) bool {
+	condition := GetCompletedCondition(ibu, ibuv1.Stages.Prep)
+	return condition != nil && condition.Status == metav1.ConditionTrue && condition.Reason == string(ConditionReasons.Validated)
+}
+
 // SetRollbackStatusFailed updates the Rollback status to failed with message
 func SetRollbackStatusFailed(ibu *ibuv1.ImageBasedUpgrade, msg string) {
 	SetStatusCondition(&ibu.Status.Conditions,
@@ -574,6 +656,10 @@ func UpdateIBUStatus(ctx context.Context, c client.Client, ibu *ibuv1.ImageBased
 		}
 	}
 
+	RecordUpgradeHistory(ibu)
+	RecordRolloutStatus(ibu)
+	RecordIBUMetrics(ibu)
+
 	if err := c.Status().Update(ctx, ibu); err != nil {
 		return fmt.Errorf("failed to update IBU status: %w", err)
 	}
@@ -12,6 +12,8 @@ const (
 	// IBUName defines the valid name of the CR for the controller to reconcile
 	IBUName     string = "upgrade"
 	IBUFilePath string = common.LCAConfigDir + "/ibu.json"
+	// StageDurationsFilePath records the durations of the completed stages, used to estimate the completion of the next ones
+	StageDurationsFilePath string = common.LCAConfigDir + "/stage-durations.json"
 
 	ManualCleanupAnnotation                                    string = "lca.openshift.io/manual-cleanup-done"
 	TriggerReconcileAnnotation                                 string = "lca.openshift.io/trigger-reconcile"
@@ -20,11 +22,18 @@ const (
 	RecertCachedImageAnnotation                                string = "lca.openshift.io/recert-image-cached"
 	SkipIPConfigPreConfigurationClusterHealthChecksAnnotation  string = "lca.openshift.io/ipconfig-skip-pre-configuration-cluster-health-checks"
 	SkipIPConfigPostConfigurationClusterHealthChecksAnnotation string = "lca.openshift.io/ipconfig-skip-post-configuration-cluster-health-checks"
+	// AllowMachineConfigDriftAnnotation lets the seed generation proceed, with a warning, when files of the MachineConfig
+	// were modified outside of the MCO on the seed SNO
+	AllowMachineConfigDriftAnnotation string = "lca.openshift.io/allow-machineconfig-drift"
 
 	// SeedGenName defines the valid name of the CR for the controller to reconcile
 	SeedGenName          string = "seedimage"
 	SeedGenSecretName    string = "seedgen"
 	SeedgenWorkspacePath string = common.LCAConfigDir + "/ibu-seedgen-orch" // The LCAConfigDir folder is excluded from the var.tgz backup in seed image creation
+
+	// SeedGenSecretEncryptionKey is the optional key of the seedgen secret holding the public key used to encrypt
+	// the seed image layers
+	SeedGenSecretEncryptionKey string = "seedEncryptionKey"
 )
 
 var (
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaxStageDurationSamples is the number of durations retained per stage in the stage durations file
+const MaxStageDurationSamples = 10
+
+// StageDuration records how long a completed stage took
+type StageDuration struct {
+	CompletionTime  metav1.Time `json:"completionTime"`
+	DurationSeconds int64       `json:"durationSeconds"`
+}
+
+// StageDurations are the durations of the completed stages, the most recent last
+type StageDurations map[ibuv1.ImageBasedUpgradeStage][]StageDuration
+
+// ReadStageDurations reads the stage durations from the given file. No durations are returned if the file does not
+// exist yet
+func ReadStageDurations(filePath string) (StageDurations, error) {
+	durations := StageDurations{}
+	data, err := os.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return durations, nil
+		}
+		return nil, fmt.Errorf("failed to read stage durations from %s: %w", filePath, err)
+	}
+	if err := json.Unmarshal(data, &durations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stage durations from %s: %w", filePath, err)
+	}
+	return durations, nil
+}
+
+// WriteStageDurations writes the stage durations to the given file
+func WriteStageDurations(filePath string, durations StageDurations) error {
+	data, err := json.Marshal(durations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stage durations: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write stage durations to %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// Record adds the duration of the desired stage from the .status.history once the stage has completed. A validate-only
+// Prep is not recorded, as it is not representative of a full Prep. It returns false if there is nothing new to record.
+func (d StageDurations) Record(ibu *ibuv1.ImageBasedUpgrade) bool {
+	if ibu.Spec.Stage == ibuv1.Stages.Idle || IsPrepValidated(ibu) {
+		return false
+	}
+
+	history := getStageHistory(ibu)
+	if history == nil || history.StartTime.IsZero() || history.CompletionTime.IsZero() {
+		return false
+	}
+
+	samples := d[ibu.Spec.Stage]
+	if len(samples) > 0 && samples[len(samples)-1].CompletionTime.Equal(&history.CompletionTime) {
+		return false // already recorded
+	}
+
+	samples = append(samples, StageDuration{
+		CompletionTime:  history.CompletionTime,
+		DurationSeconds: int64(history.CompletionTime.Sub(history.StartTime.Time).Seconds()),
+	})
+	if extra := len(samples) - MaxStageDurationSamples; extra > 0 {
+		samples = samples[extra:]
+	}
+	d[ibu.Spec.Stage] = samples
+	return true
+}
+
+// Average returns the average duration of the stage, or zero if it has never completed
+func (d StageDurations) Average(stage ibuv1.ImageBasedUpgradeStage) time.Duration {
+	samples := d[stage]
+	if len(samples) == 0 {
+		return 0
+	}
+	var total int64
+	for _, sample := range samples {
+		total += sample.DurationSeconds
+	}
+	return time.Duration(total/int64(len(samples))) * time.Second
+}
+
+// SetCompletionEstimate sets the .status.estimate of the desired stage while it is in progress, from the average of
+// its previous durations. The estimate is cleared once the stage has completed, or if there is no previous duration.
+// No estimate is made for a validate-only Prep.
+// The caller is responsible for persisting the status.
+func SetCompletionEstimate(ibu *ibuv1.ImageBasedUpgrade, durations StageDurations) {
+	ibu.Status.Estimate = nil
+
+	if ibu.Spec.Stage == ibuv1.Stages.Idle || ibu.Spec.Stage == ibuv1.Stages.Prep && ibu.Spec.ValidateOnly {
+		return
+	}
+	history := getStageHistory(ibu)
+	if history == nil || history.StartTime.IsZero() || !history.CompletionTime.IsZero() {
+		return
+	}
+	average := durations.Average(ibu.Spec.Stage)
+	if average == 0 {
+		return
+	}
+
+	estimate := &ibuv1.CompletionEstimate{
+		Stage:               ibu.Spec.Stage,
+		StageCompletionTime: metav1.Time{Time: history.StartTime.Add(average)},
+		Samples:             len(durations[ibu.Spec.Stage]),
+	}
+	switch ibu.Spec.Stage {
+	case ibuv1.Stages.Prep:
+		if upgradeAverage := durations.Average(ibuv1.Stages.Upgrade); upgradeAverage > 0 {
+			estimate.UpgradeCompletionTime = metav1.Time{Time: estimate.StageCompletionTime.Add(upgradeAverage)}
+		}
+	case ibuv1.Stages.Upgrade:
+		estimate.UpgradeCompletionTime = estimate.StageCompletionTime
+	}
+	ibu.Status.Estimate = estimate
+}
+
+func getStageHistory(ibu *ibuv1.ImageBasedUpgrade) *ibuv1.History {
+	for _, h := range ibu.Status.History {
+		if h.Stage == ibu.Spec.Stage {
+			return h
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStageDurationsRecord(t *testing.T) {
+	startTime := metav1.Time{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+	completionTime := metav1.Time{Time: startTime.Add(30 * time.Minute)}
+	ibu := &ibuv1.ImageBasedUpgrade{
+		Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep},
+		Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{
+			{Stage: ibuv1.Stages.Prep, StartTime: startTime},
+		}},
+	}
+
+	durations := StageDurations{}
+	assert.False(t, durations.Record(ibu), "stage in progress is not recorded")
+
+	ibu.Status.History[0].CompletionTime = completionTime
+	assert.True(t, durations.Record(ibu))
+	assert.False(t, durations.Record(ibu), "stage is recorded once")
+	assert.Equal(t, StageDurations{
+		ibuv1.Stages.Prep: {{CompletionTime: completionTime, DurationSeconds: 1800}},
+	}, durations)
+
+	for i := range MaxStageDurationSamples {
+		ibu.Status.History[0].CompletionTime = metav1.Time{Time: completionTime.Add(time.Duration(i+1) * time.Minute)}
+		durations.Record(ibu)
+	}
+	assert.Len(t, durations[ibuv1.Stages.Prep], MaxStageDurationSamples)
+	assert.Equal(t, int64(1860), durations[ibuv1.Stages.Prep][0].DurationSeconds)
+
+	filePath := filepath.Join(t.TempDir(), "stage-durations.json")
+	read, err := ReadStageDurations(filePath)
+	assert.NoError(t, err)
+	assert.Empty(t, read, "missing file returns no durations")
+	assert.NoError(t, WriteStageDurations(filePath, durations))
+	read, err = ReadStageDurations(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, len(durations[ibuv1.Stages.Prep]), len(read[ibuv1.Stages.Prep]))
+}
+
+func TestSetCompletionEstimate(t *testing.T) {
+	startTime := metav1.Time{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+	durations := StageDurations{
+		ibuv1.Stages.Prep:    {{DurationSeconds: 1200}, {DurationSeconds: 1800}},
+		ibuv1.Stages.Upgrade: {{DurationSeconds: 3600}},
+	}
+
+	tests := []struct {
+		name        string
+		ibu         *ibuv1.ImageBasedUpgrade
+		durations   StageDurations
+		expectation *ibuv1.CompletionEstimate
+	}{
+		{
+			name:      "no estimate when desired stage is Idle",
+			ibu:       &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Idle}},
+			durations: durations,
+		},
+		{
+			name: "prep estimate includes the upgrade",
+			ibu: &ibuv1.ImageBasedUpgrade{
+				Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep},
+				Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{
+					{Stage: ibuv1.Stages.Prep, StartTime: startTime},
+				}},
+			},
+			durations: durations,
+			expectation: &ibuv1.CompletionEstimate{
+				Stage:                 ibuv1.Stages.Prep,
+				StageCompletionTime:   metav1.Time{Time: startTime.Add(25 * time.Minute)},
+				UpgradeCompletionTime: metav1.Time{Time: startTime.Add(85 * time.Minute)},
+				Samples:               2,
+			},
+		},
+		{
+			name: "upgrade estimate",
+			ibu: &ibuv1.ImageBasedUpgrade{
+				Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade},
+				Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{
+					{Stage: ibuv1.Stages.Upgrade, StartTime: startTime},
+				}},
+			},
+			durations: durations,
+			expectation: &ibuv1.CompletionEstimate{
+				Stage:                 ibuv1.Stages.Upgrade,
+				StageCompletionTime:   metav1.Time{Time: startTime.Add(60 * time.Minute)},
+				UpgradeCompletionTime: metav1.Time{Time: startTime.Add(60 * time.Minute)},
+				Samples:               1,
+			},
+		},
+		{
+			name: "no estimate without previous duration",
+			ibu: &ibuv1.ImageBasedUpgrade{
+				Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Rollback},
+				Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{
+					{Stage: ibuv1.Stages.Rollback, StartTime: startTime},
+				}},
+			},
+			durations: durations,
+		},
+		{
+			name: "estimate cleared once the stage completed",
+			ibu: &ibuv1.ImageBasedUpgrade{
+				Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep},
+				Status: ibuv1.ImageBasedUpgradeStatus{
+					History: []*ibuv1.History{
+						{Stage: ibuv1.Stages.Prep, StartTime: startTime, CompletionTime: metav1.Time{Time: startTime.Add(time.Hour)}},
+					},
+					Estimate: &ibuv1.CompletionEstimate{Stage: ibuv1.Stages.Prep},
+				},
+			},
+			durations: durations,
+		},
+		{
+			name: "no estimate for a validate-only prep",
+			ibu: &ibuv1.ImageBasedUpgrade{
+				Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep, ValidateOnly: true},
+				Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{
+					{Stage: ibuv1.Stages.Prep, StartTime: startTime},
+				}},
+			},
+			durations: durations,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetCompletionEstimate(tt.ibu, tt.durations)
+			assert.Equal(t, tt.expectation, tt.ibu.Status.Estimate)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Event reasons that are not derived from a condition
+const (
+	EventReasonPrecacheFailed  = "PrecacheFailed"
+	EventReasonAutoRollback    = "AutoRollback"
+	EventReasonManualRollback  = "ManualRollback"
+	EventReasonStaterootPruned = "StaterootPruned"
+	EventReasonSeedImagePushed = "SeedImagePushed"
+	EventReasonHookFailed      = "HookFailed"
+	// EventReasonAutoRollbackDeadline reports the extension of the LCA Init Monitor watchdog deadline
+	EventReasonAutoRollbackDeadline = "AutoRollbackDeadline"
+	// EventReasonMachineConfigDrift reports the files of the MachineConfig modified outside of the MCO on the seed SNO
+	EventReasonMachineConfigDrift = "MachineConfigDrift"
+	// EventReasonResumed reports a stage resumed from its checkpoints, after a restart of LCA or a reboot of the node
+	EventReasonResumed = "Resumed"
+)
+
+// failureReasons are the condition reasons reported as Warning events
+var failureReasons = map[string]bool{
+	string(ConditionReasons.Failed):              true,
+	string(ConditionReasons.TimedOut):            true,
+	string(ConditionReasons.AbortFailed):         true,
+	string(ConditionReasons.FinalizeFailed):      true,
+	string(ConditionReasons.InvalidTransition):   true,
+	string(ConditionReasons.StaterootRemoved):    true,
+	string(ConditionReasons.CertificatesExpired): true,
+	string(ConditionReasons.Stalled):             true,
+}
+
+// IsFailureReason returns whether the condition reason reports a failure
+func IsFailureReason(reason string) bool {
+	return failureReasons[reason]
+}
+
+// EmitConditionEvents records an Event on the object for every condition whose status or reason changed, so that the
+// stage transitions and failures are kept as a timeline rather than only the latest condition
+func EmitConditionEvents(recorder record.EventRecorder, obj runtime.Object, before, after []metav1.Condition) {
+	if recorder == nil {
+		return
+	}
+
+	for _, condition := range after {
+		previous := meta.FindStatusCondition(before, condition.Type)
+		if previous != nil && previous.Status == condition.Status && previous.Reason == condition.Reason {
+			continue
+		}
+
+		eventType := corev1.EventTypeNormal
+		if IsFailureReason(condition.Reason) {
+			eventType = corev1.EventTypeWarning
+		}
+		recorder.Event(obj, eventType, condition.Reason, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+	}
+}
+
+// EmitEvent records an Event on the object, unless no recorder is set
+func EmitEvent(recorder record.EventRecorder, obj runtime.Object, eventType, reason, message string) {
+	if recorder == nil {
+		return
+	}
+	recorder.Event(obj, eventType, reason, message)
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"testing"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEmitConditionEvents(t *testing.T) {
+	ibu := &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep}}
+	SetPrepStatusInProgress(ibu, "Stateroot setup job in progress")
+	before := append([]metav1.Condition{}, ibu.Status.Conditions...)
+
+	tests := []struct {
+		name     string
+		update   func(ibu *ibuv1.ImageBasedUpgrade)
+		expected []string
+	}{
+		{
+			name: "message change only",
+			update: func(ibu *ibuv1.ImageBasedUpgrade) {
+				SetPrepStatusInProgress(ibu, "Precache job in progress")
+			},
+			expected: []string{},
+		},
+		{
+			name: "stage failure",
+			update: func(ibu *ibuv1.ImageBasedUpgrade) {
+				SetPrepStatusFailed(ibu, "precache job failed to complete")
+			},
+			expected: []string{
+				"Warning Failed PrepInProgress: precache job failed to complete",
+				"Warning Failed PrepCompleted: Prep failed",
+			},
+		},
+		{
+			name: "stage completion",
+			update: func(ibu *ibuv1.ImageBasedUpgrade) {
+				SetPrepStatusCompleted(ibu, PrepCompleted)
+			},
+			expected: []string{
+				"Normal Completed PrepInProgress: Prep completed",
+				"Normal Completed PrepCompleted: Prep completed",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := ibu.DeepCopy()
+			tt.update(updated)
+
+			recorder := record.NewFakeRecorder(10)
+			EmitConditionEvents(recorder, updated, before, updated.Status.Conditions)
+			close(recorder.Events)
+
+			events := []string{}
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			assert.ElementsMatch(t, tt.expected, events)
+		})
+	}
+}
+
+func TestEmitEventWithoutRecorder(t *testing.T) {
+	assert.NotPanics(t, func() {
+		EmitEvent(nil, &ibuv1.ImageBasedUpgrade{}, "Warning", EventReasonAutoRollback, "rollback")
+		EmitConditionEvents(nil, &ibuv1.ImageBasedUpgrade{}, nil, []metav1.Condition{{Type: "Idle"}})
+	})
+}
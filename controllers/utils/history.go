@@ -7,6 +7,7 @@ import (
 	"github.com/go-logr/logr"
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	ipcv1 "github.com/openshift-kni/lifecycle-agent/api/ipconfig/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -103,6 +104,20 @@ func StopPhase(client client.Client, log logr.Logger, ibu *ibuv1.ImageBasedUpgra
 	}
 }
 
+// GetPhaseStartTime returns the start time of a phase of the current stage, or a zero time if the phase is not started
+func GetPhaseStartTime(ibu *ibuv1.ImageBasedUpgrade, phase string) metav1.Time {
+	for _, h := range ibu.Status.History {
+		if h.Stage == ibu.Spec.Stage {
+			for _, p := range h.Phases {
+				if p.Phase == phase {
+					return p.StartTime
+				}
+			}
+		}
+	}
+	return metav1.Time{}
+}
+
 // A helper function to return the current time. This also used to override time during tests
 var getMetav1Now = func() metav1.Time {
 	return metav1.Time{Time: time.Now()}
@@ -114,6 +129,116 @@ func updateStatus(client client.Client, log logr.Logger, ibu *ibuv1.ImageBasedUp
 	}
 }
 
+// MaxUpgradeHistoryEntries is the number of entries retained in .status.upgradeHistory
+const MaxUpgradeHistoryEntries = 20
+
+// Outcomes of the .status.upgradeHistory entries
+const (
+	UpgradeHistoryCompleted = "Completed"
+	UpgradeHistoryFailed    = "Failed"
+	UpgradeHistoryAborted   = "Aborted"
+)
+
+// RecordUpgradeHistory updates the .status.upgradeHistory from the conditions of the desired stage. A new entry is
+// opened as the stage starts and is closed with its outcome as the stage ends. An entry still open when another stage
+// starts is closed as Aborted. The caller is responsible for persisting the status.
+func RecordUpgradeHistory(ibu *ibuv1.ImageBasedUpgrade) {
+	stage := ibu.Spec.Stage
+	outcome, message := getStageOutcome(ibu, stage)
+	if outcome == "" {
+		return
+	}
+
+	var last *ibuv1.UpgradeHistoryEntry
+	if len(ibu.Status.UpgradeHistory) > 0 {
+		last = &ibu.Status.UpgradeHistory[len(ibu.Status.UpgradeHistory)-1]
+	}
+	now := getMetav1Now()
+
+	inProgress := outcome == string(ConditionReasons.InProgress)
+	lastOpen := last != nil && last.CompletionTime.IsZero()
+
+	if last != nil && last.Stage == stage {
+		if lastOpen && !inProgress {
+			closeUpgradeHistoryEntry(last, outcome, message, now)
+		}
+		if lastOpen || !inProgress {
+			// the stage is still in progress, or its end has already been recorded
+			return
+		}
+	}
+
+	if lastOpen {
+		closeUpgradeHistoryEntry(last, UpgradeHistoryAborted, "", now)
+	}
+
+	if stage == ibuv1.Stages.Idle && !inProgress {
+		// Idle is only recorded when finalizing or aborting
+		return
+	}
+
+	entry := ibuv1.UpgradeHistoryEntry{
+		Stage:       stage,
+		SeedImage:   ibu.Spec.SeedImageRef.Image,
+		SeedVersion: ibu.Spec.SeedImageRef.Version,
+		StartTime:   now,
+		Message:     message,
+	}
+	if !inProgress {
+		closeUpgradeHistoryEntry(&entry, outcome, message, now)
+	}
+	ibu.Status.UpgradeHistory = append(ibu.Status.UpgradeHistory, entry)
+
+	if extra := len(ibu.Status.UpgradeHistory) - MaxUpgradeHistoryEntries; extra > 0 {
+		ibu.Status.UpgradeHistory = ibu.Status.UpgradeHistory[extra:]
+	}
+}
+
+func closeUpgradeHistoryEntry(entry *ibuv1.UpgradeHistoryEntry, outcome, message string, now metav1.Time) {
+	entry.CompletionTime = now
+	entry.Outcome = outcome
+	// keep whether the upgrade was finalized or aborted for the successful Idle entries
+	if entry.Stage != ibuv1.Stages.Idle || outcome == UpgradeHistoryFailed {
+		entry.Message = message
+	}
+}
+
+// getStageOutcome returns InProgress, Completed or Failed according to the conditions of the stage, along with the
+// related message. An empty outcome is returned for a stage that has not started.
+func getStageOutcome(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage) (string, string) {
+	if stage == ibuv1.Stages.Idle {
+		idle := meta.FindStatusCondition(ibu.Status.Conditions, string(ConditionTypes.Idle))
+		if idle == nil {
+			return "", ""
+		}
+		switch idle.Reason {
+		case string(ConditionReasons.Aborting), string(ConditionReasons.Finalizing):
+			return string(ConditionReasons.InProgress), idle.Message
+		case string(ConditionReasons.AbortFailed), string(ConditionReasons.FinalizeFailed):
+			return UpgradeHistoryFailed, idle.Message
+		}
+		if idle.Status == metav1.ConditionTrue {
+			return UpgradeHistoryCompleted, idle.Message
+		}
+		return "", ""
+	}
+
+	if completed := GetCompletedCondition(ibu, stage); completed != nil {
+		if completed.Status == metav1.ConditionTrue {
+			return UpgradeHistoryCompleted, completed.Message
+		}
+		message := completed.Message
+		if inProgress := GetInProgressCondition(ibu, stage); inProgress != nil && inProgress.Message != "" {
+			message = inProgress.Message
+		}
+		return UpgradeHistoryFailed, message
+	}
+	if inProgress := GetInProgressCondition(ibu, stage); inProgress != nil && inProgress.Status == metav1.ConditionTrue {
+		return string(ConditionReasons.InProgress), inProgress.Message
+	}
+	return "", ""
+}
+
 // ResetIPHistory resets the IPConfig .status.history by setting the list to empty when stage is Idle
 func ResetIPHistory(client client.Client, log logr.Logger, ipc *ipcv1.IPConfig) {
 	if ipc.Spec.Stage == ipcv1.IPStages.Idle {
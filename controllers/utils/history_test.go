@@ -710,3 +710,72 @@ func TestStopIPStageHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordUpgradeHistory(t *testing.T) {
+	// override time
+	currentTime := metav1.Now()
+	getMetav1Now = func() metav1.Time {
+		return currentTime
+	}
+	seedImageRef := ibuv1.SeedImageRef{Image: "quay.io/seed:4.16.1", Version: "4.16.1"}
+
+	ibu := &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Idle, SeedImageRef: seedImageRef}}
+	ResetStatusConditions(&ibu.Status.Conditions, ibu.Generation)
+	RecordUpgradeHistory(ibu)
+	assert.Empty(t, ibu.Status.UpgradeHistory, "steady Idle is not recorded")
+
+	ibu.Spec.Stage = ibuv1.Stages.Prep
+	SetPrepStatusInProgress(ibu, InProgress)
+	RecordUpgradeHistory(ibu)
+	RecordUpgradeHistory(ibu)
+	assert.Equal(t, []ibuv1.UpgradeHistoryEntry{
+		{Stage: ibuv1.Stages.Prep, SeedImage: seedImageRef.Image, SeedVersion: seedImageRef.Version, StartTime: currentTime, Message: InProgress},
+	}, ibu.Status.UpgradeHistory)
+
+	SetPrepStatusCompleted(ibu, PrepCompleted)
+	RecordUpgradeHistory(ibu)
+	assert.Equal(t, UpgradeHistoryCompleted, ibu.Status.UpgradeHistory[0].Outcome)
+	assert.Equal(t, currentTime, ibu.Status.UpgradeHistory[0].CompletionTime)
+
+	ibu.Spec.Stage = ibuv1.Stages.Upgrade
+	SetUpgradeStatusInProgress(ibu, InProgress)
+	RecordUpgradeHistory(ibu)
+	SetUpgradeStatusFailed(ibu, "restore failed")
+	RecordUpgradeHistory(ibu)
+	RecordUpgradeHistory(ibu)
+	assert.Len(t, ibu.Status.UpgradeHistory, 2)
+	assert.Equal(t, UpgradeHistoryFailed, ibu.Status.UpgradeHistory[1].Outcome)
+	assert.Equal(t, "restore failed", ibu.Status.UpgradeHistory[1].Message)
+
+	ibu.Spec.Stage = ibuv1.Stages.Idle
+	SetIdleStatusInProgress(ibu, ConditionReasons.Aborting, Aborting)
+	RecordUpgradeHistory(ibu)
+	ResetStatusConditions(&ibu.Status.Conditions, ibu.Generation)
+	RecordUpgradeHistory(ibu)
+	assert.Len(t, ibu.Status.UpgradeHistory, 3)
+	assert.Equal(t, ibuv1.UpgradeHistoryEntry{
+		Stage: ibuv1.Stages.Idle, SeedImage: seedImageRef.Image, SeedVersion: seedImageRef.Version,
+		StartTime: currentTime, CompletionTime: currentTime, Outcome: UpgradeHistoryCompleted, Message: Aborting,
+	}, ibu.Status.UpgradeHistory[2])
+
+	// a stage left while in progress is recorded as aborted
+	ibu.Spec.Stage = ibuv1.Stages.Prep
+	SetPrepStatusInProgress(ibu, InProgress)
+	RecordUpgradeHistory(ibu)
+	ibu.Spec.Stage = ibuv1.Stages.Idle
+	SetIdleStatusInProgress(ibu, ConditionReasons.Aborting, Aborting)
+	RecordUpgradeHistory(ibu)
+	assert.Len(t, ibu.Status.UpgradeHistory, 5)
+	assert.Equal(t, UpgradeHistoryAborted, ibu.Status.UpgradeHistory[3].Outcome)
+
+	// the history is bounded
+	for i := 0; i < MaxUpgradeHistoryEntries; i++ {
+		ibu.Status.Conditions = nil
+		ibu.Spec.Stage = ibuv1.Stages.Prep
+		SetPrepStatusInProgress(ibu, InProgress)
+		RecordUpgradeHistory(ibu)
+		SetPrepStatusFailed(ibu, "failed")
+		RecordUpgradeHistory(ibu)
+	}
+	assert.Len(t, ibu.Status.UpgradeHistory, MaxUpgradeHistoryEntries)
+}
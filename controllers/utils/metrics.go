@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// History phases tracking the OADP backup and restore of the Upgrade stage
+const (
+	OADPPhaseBackup  = "Backup"
+	OADPPhaseRestore = "Restore"
+)
+
+// The IBU metrics are derived from the CR status, which survives the LCA restarts across the pivot, and are exposed
+// on the controller-runtime metrics endpoint
+var (
+	ibuStage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lca_ibu_stage",
+		Help: "The desired stage of the IBU, set to 1 for the current stage and 0 otherwise.",
+	}, []string{"stage"})
+
+	ibuStageDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lca_ibu_stage_duration_seconds",
+		Help: "The duration of the last successful completion of the IBU stage.",
+	}, []string{"stage"})
+
+	ibuOADPDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lca_ibu_oadp_duration_seconds",
+		Help: "The duration of the last successful OADP backup or restore of application data.",
+	}, []string{"operation"})
+
+	ibuPrecacheImages = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lca_ibu_precache_images",
+		Help: "The number of images to precache (total), successfully precached (pulled) or that could not be precached (failed).",
+	}, []string{"state"})
+
+	ibuPrecachePulledBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "lca_ibu_precache_pulled_bytes",
+		Help: "The total size of the images successfully precached.",
+	})
+
+	ibuStageStalled = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lca_ibu_stage_stalled",
+		Help: "Set to 1 when the IBU stage is in progress for longer than the stall threshold of its expected duration, and 0 otherwise.",
+	}, []string{"stage"})
+
+	ibuFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lca_ibu_failures_total",
+		Help: "The number of IBU stage failures.",
+	}, []string{"stage", "reason"})
+
+	// countedFailures records the transition time of the failures already counted for each stage, as the status is
+	// recorded on every reconcile
+	countedFailures   = map[ibuv1.ImageBasedUpgradeStage]metav1.Time{}
+	countedFailuresMu sync.Mutex
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ibuStage,
+		ibuStageDuration,
+		ibuOADPDuration,
+		ibuPrecacheImages,
+		ibuPrecachePulledBytes,
+		ibuStageStalled,
+		ibuFailures,
+	)
+}
+
+// RecordIBUMetrics updates the IBU metrics from the CR status
+func RecordIBUMetrics(ibu *ibuv1.ImageBasedUpgrade) {
+	stages := []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Idle, ibuv1.Stages.Prep, ibuv1.Stages.Upgrade, ibuv1.Stages.Rollback}
+	for _, stage := range stages {
+		value := 0.0
+		if ibu.Spec.Stage == stage {
+			value = 1
+		}
+		ibuStage.WithLabelValues(string(stage)).Set(value)
+		stalled := 0.0
+		if ibu.Spec.Stage == stage && IsStageStalled(ibu) {
+			stalled = 1
+		}
+		ibuStageStalled.WithLabelValues(string(stage)).Set(stalled)
+		recordFailure(ibu, stage)
+	}
+
+	for _, h := range ibu.Status.History {
+		if !h.StartTime.IsZero() && !h.CompletionTime.IsZero() {
+			ibuStageDuration.WithLabelValues(string(h.Stage)).Set(h.CompletionTime.Sub(h.StartTime.Time).Seconds())
+		}
+		for _, p := range h.Phases {
+			if p.Phase != OADPPhaseBackup && p.Phase != OADPPhaseRestore {
+				continue
+			}
+			if !p.StartTime.IsZero() && !p.CompletionTime.IsZero() {
+				ibuOADPDuration.WithLabelValues(p.Phase).Set(p.CompletionTime.Sub(p.StartTime.Time).Seconds())
+			}
+		}
+	}
+
+	if precache := ibu.Status.Precache; precache != nil {
+		ibuPrecacheImages.WithLabelValues("total").Set(float64(precache.Total))
+		ibuPrecacheImages.WithLabelValues("pulled").Set(float64(precache.Pulled))
+		ibuPrecacheImages.WithLabelValues("failed").Set(float64(precache.Failed))
+		ibuPrecachePulledBytes.Set(float64(precache.PulledBytes))
+	}
+}
+
+// recordFailure counts the failure of the stage, once per failed condition transition
+func recordFailure(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage) {
+	condition := GetCompletedCondition(ibu, stage)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		return
+	}
+
+	reason := condition.Reason
+	switch ConditionReason(reason) {
+	case ConditionReasons.Failed:
+		if condition.Message == RollbackRequested {
+			reason = "RollbackRequested"
+		}
+	case ConditionReasons.TimedOut, ConditionReasons.AbortFailed, ConditionReasons.FinalizeFailed:
+	default:
+		return
+	}
+
+	countedFailuresMu.Lock()
+	defer countedFailuresMu.Unlock()
+	if last, ok := countedFailures[stage]; ok && last.Equal(&condition.LastTransitionTime) {
+		return
+	}
+	countedFailures[stage] = condition.LastTransitionTime
+	ibuFailures.WithLabelValues(string(stage), reason).Inc()
+}
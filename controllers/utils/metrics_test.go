@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordIBUMetrics(t *testing.T) {
+	start := metav1.Now()
+	after := func(d time.Duration) metav1.Time { return metav1.Time{Time: start.Add(d)} }
+
+	ibu := &ibuv1.ImageBasedUpgrade{
+		Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade},
+		Status: ibuv1.ImageBasedUpgradeStatus{
+			History: []*ibuv1.History{
+				{Stage: ibuv1.Stages.Prep, StartTime: start, CompletionTime: after(10 * time.Minute)},
+				{Stage: ibuv1.Stages.Upgrade, StartTime: after(15 * time.Minute), Phases: []*ibuv1.Phase{
+					{Phase: OADPPhaseBackup, StartTime: after(15 * time.Minute), CompletionTime: after(17 * time.Minute)},
+					{Phase: OADPPhaseRestore, StartTime: after(30 * time.Minute)},
+				}},
+			},
+			Precache: &ibuv1.PrecacheStatus{Total: 10, Pulled: 8, Failed: 2, PulledBytes: 1024},
+		},
+	}
+	SetUpgradeStatusFailed(ibu, "restore failed")
+
+	RecordIBUMetrics(ibu)
+
+	assert.Equal(t, 1.0, metricValue(t, ibuStage.WithLabelValues("Upgrade")))
+	assert.Equal(t, 0.0, metricValue(t, ibuStage.WithLabelValues("Prep")))
+	assert.Equal(t, 600.0, metricValue(t, ibuStageDuration.WithLabelValues("Prep")))
+	assert.Equal(t, 120.0, metricValue(t, ibuOADPDuration.WithLabelValues(OADPPhaseBackup)))
+	assert.Equal(t, 0.0, metricValue(t, ibuOADPDuration.WithLabelValues(OADPPhaseRestore)))
+	assert.Equal(t, 8.0, metricValue(t, ibuPrecacheImages.WithLabelValues("pulled")))
+	assert.Equal(t, 2.0, metricValue(t, ibuPrecacheImages.WithLabelValues("failed")))
+	assert.Equal(t, 1024.0, metricValue(t, ibuPrecachePulledBytes))
+	assert.Equal(t, 1.0, metricValue(t, ibuFailures.WithLabelValues("Upgrade", "Failed")))
+
+	// the same failure is only counted once
+	RecordIBUMetrics(ibu)
+	assert.Equal(t, 1.0, metricValue(t, ibuFailures.WithLabelValues("Upgrade", "Failed")))
+
+	// a new failure is counted
+	ibu.Status.Conditions = nil
+	SetPrepStatusFailed(ibu, "prep failed")
+	RecordIBUMetrics(ibu)
+	assert.Equal(t, 1.0, metricValue(t, ibuFailures.WithLabelValues("Prep", "Failed")))
+}
+
+func TestRecordIBUMetricsStalled(t *testing.T) {
+	ibu := &ibuv1.ImageBasedUpgrade{
+		Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep},
+		Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{
+			{Stage: ibuv1.Stages.Prep, StartTime: metav1.Time{Time: time.Now().Add(-4 * time.Hour)}},
+		}},
+	}
+	SetPrepStatusInProgress(ibu, "in progress")
+
+	SetProgressingCondition(ibu, StageDurations{}, time.Now())
+	RecordIBUMetrics(ibu)
+	assert.Equal(t, 1.0, metricValue(t, ibuStageStalled.WithLabelValues("Prep")))
+	assert.Equal(t, 0.0, metricValue(t, ibuStageStalled.WithLabelValues("Upgrade")))
+
+	ibu.Status.History[0].StartTime = metav1.Now()
+	SetProgressingCondition(ibu, StageDurations{}, time.Now())
+	RecordIBUMetrics(ibu)
+	assert.Equal(t, 0.0, metricValue(t, ibuStageStalled.WithLabelValues("Prep")))
+}
+
+func metricValue(t *testing.T, m prometheus.Metric) float64 {
+	metric := &dto.Metric{}
+	assert.NoError(t, m.Write(metric))
+	if metric.Counter != nil {
+		return metric.Counter.GetValue()
+	}
+	return metric.Gauge.GetValue()
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetStageProgress records the step currently being performed for the desired stage, along with an estimate
+// of how much of the stage is complete. The progress is restarted whenever the desired stage changes, and the
+// estimated completion time is extrapolated from the time elapsed since the stage started.
+// The caller is responsible for persisting the status.
+func SetStageProgress(ibu *ibuv1.ImageBasedUpgrade, step string, percent int) {
+	if ibu.Spec.Stage == ibuv1.Stages.Idle {
+		return
+	}
+
+	percent = max(0, min(percent, 100))
+
+	now := getMetav1Now()
+	progress := ibu.Status.Progress
+	if progress == nil || progress.Stage != ibu.Spec.Stage {
+		progress = &ibuv1.StageProgress{
+			Stage:     ibu.Spec.Stage,
+			StartTime: now,
+		}
+		ibu.Status.Progress = progress
+	}
+
+	progress.CurrentStep = step
+	progress.PercentComplete = percent
+
+	switch {
+	case percent == 100:
+		progress.EstimatedCompletionTime = now
+	case percent > 0:
+		elapsed := now.Sub(progress.StartTime.Time)
+		total := time.Duration(float64(elapsed) * 100 / float64(percent))
+		progress.EstimatedCompletionTime = metav1.Time{Time: progress.StartTime.Add(total)}
+	default:
+		progress.EstimatedCompletionTime = metav1.Time{}
+	}
+}
+
+// ResetStageProgress clears the .status.progress, .status.precache and .status.hooks as long as the desired stage is Idle
+func ResetStageProgress(ibu *ibuv1.ImageBasedUpgrade) {
+	if ibu.Spec.Stage == ibuv1.Stages.Idle {
+		ibu.Status.Progress = nil
+		ibu.Status.Precache = nil
+		ibu.Status.Hooks = nil
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetStageProgress(t *testing.T) {
+	// override time
+	currentTime := metav1.Now()
+	getMetav1Now = func() metav1.Time {
+		return currentTime
+	}
+	startTime := metav1.Time{Time: currentTime.Add(-10 * time.Minute)}
+
+	type args struct {
+		ibu     *ibuv1.ImageBasedUpgrade
+		step    string
+		percent int
+	}
+	tests := []struct {
+		name        string
+		args        args
+		expectation *ibuv1.StageProgress
+	}{
+		{
+			name: "progress is not tracked when desired stage is Idle",
+			args: args{
+				ibu: &ibuv1.ImageBasedUpgrade{
+					Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Idle},
+				},
+				step:    "Finalizing",
+				percent: 10,
+			},
+			expectation: nil,
+		},
+		{
+			name: "progress starts when first set for a stage",
+			args: args{
+				ibu: &ibuv1.ImageBasedUpgrade{
+					Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep},
+				},
+				step:    "Waiting for system to stabilize",
+				percent: 0,
+			},
+			expectation: &ibuv1.StageProgress{
+				Stage:       ibuv1.Stages.Prep,
+				CurrentStep: "Waiting for system to stabilize",
+				StartTime:   currentTime,
+			},
+		},
+		{
+			name: "estimated completion is extrapolated from elapsed time",
+			args: args{
+				ibu: &ibuv1.ImageBasedUpgrade{
+					Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep},
+					Status: ibuv1.ImageBasedUpgradeStatus{
+						Progress: &ibuv1.StageProgress{
+							Stage:       ibuv1.Stages.Prep,
+							CurrentStep: "Setting up stateroot",
+							StartTime:   startTime,
+						},
+					},
+				},
+				step:    "Precaching images",
+				percent: 50,
+			},
+			expectation: &ibuv1.StageProgress{
+				Stage:                   ibuv1.Stages.Prep,
+				CurrentStep:             "Precaching images",
+				PercentComplete:         50,
+				StartTime:               startTime,
+				EstimatedCompletionTime: metav1.Time{Time: startTime.Add(20 * time.Minute)},
+			},
+		},
+		{
+			name: "progress restarts when the desired stage changes",
+			args: args{
+				ibu: &ibuv1.ImageBasedUpgrade{
+					Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade},
+					Status: ibuv1.ImageBasedUpgradeStatus{
+						Progress: &ibuv1.StageProgress{
+							Stage:           ibuv1.Stages.Prep,
+							CurrentStep:     "Prep completed",
+							PercentComplete: 100,
+							StartTime:       startTime,
+						},
+					},
+				},
+				step:    "Backing up Application Data",
+				percent: 5,
+			},
+			expectation: &ibuv1.StageProgress{
+				Stage:                   ibuv1.Stages.Upgrade,
+				CurrentStep:             "Backing up Application Data",
+				PercentComplete:         5,
+				StartTime:               currentTime,
+				EstimatedCompletionTime: currentTime,
+			},
+		},
+		{
+			name: "percent complete is capped at 100",
+			args: args{
+				ibu: &ibuv1.ImageBasedUpgrade{
+					Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Rollback},
+					Status: ibuv1.ImageBasedUpgradeStatus{
+						Progress: &ibuv1.StageProgress{
+							Stage:     ibuv1.Stages.Rollback,
+							StartTime: startTime,
+						},
+					},
+				},
+				step:    "Rollback completed",
+				percent: 150,
+			},
+			expectation: &ibuv1.StageProgress{
+				Stage:                   ibuv1.Stages.Rollback,
+				CurrentStep:             "Rollback completed",
+				PercentComplete:         100,
+				StartTime:               startTime,
+				EstimatedCompletionTime: currentTime,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetStageProgress(tt.args.ibu, tt.args.step, tt.args.percent)
+			assert.Equal(t, tt.expectation, tt.args.ibu.Status.Progress)
+		})
+	}
+}
+
+func TestResetStageProgress(t *testing.T) {
+	progress := &ibuv1.StageProgress{Stage: ibuv1.Stages.Upgrade, PercentComplete: 100}
+
+	ibu := &ibuv1.ImageBasedUpgrade{
+		Spec:   ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade},
+		Status: ibuv1.ImageBasedUpgradeStatus{Progress: progress},
+	}
+	ResetStageProgress(ibu)
+	assert.Equal(t, progress, ibu.Status.Progress)
+
+	ibu.Spec.Stage = ibuv1.Stages.Idle
+	ResetStageProgress(ibu)
+	assert.Nil(t, ibu.Status.Progress)
+}
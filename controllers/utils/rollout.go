@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+)
+
+// States of the .status.rolloutStatus
+const (
+	RolloutStateIdle       = "Idle"
+	RolloutStateInProgress = "InProgress"
+	RolloutStateCompleted  = "Completed"
+	RolloutStateFailed     = "Failed"
+	RolloutStateBlocked    = "Blocked"
+)
+
+// maxRolloutStatusMessageLength truncates the error of the .status.rolloutStatus to keep it compact
+const maxRolloutStatusMessageLength = 200
+
+// RecordRolloutStatus encodes the desired stage, its state and percent complete, and its error if any, into the
+// .status.rolloutStatus as <stage>:<state>:<percent>[:<error>], e.g. Upgrade:Failed:50:<message>. The field is meant
+// to be aggregated across a fleet, e.g. with a ManagedClusterView, without interpreting the conditions of each cluster.
+// The caller is responsible for persisting the status.
+func RecordRolloutStatus(ibu *ibuv1.ImageBasedUpgrade) {
+	stage := ibu.Spec.Stage
+	if stage == "" {
+		return
+	}
+
+	percent := 0
+	if ibu.Status.Progress != nil && ibu.Status.Progress.Stage == stage {
+		percent = ibu.Status.Progress.PercentComplete
+	}
+
+	var state, message string
+	outcome, outcomeMessage := getStageOutcome(ibu, stage)
+	switch {
+	case IsIBUStatusBlocked(ibu, stage):
+		state = RolloutStateBlocked
+		message = GetInProgressCondition(ibu, stage).Message
+	case stage == ibuv1.Stages.Idle && (outcome == UpgradeHistoryCompleted || outcome == ""):
+		// the IBU is at rest, finalizing and aborting being reported as InProgress
+		state, percent = RolloutStateIdle, 0
+	case outcome == UpgradeHistoryCompleted:
+		state, percent = RolloutStateCompleted, 100
+	case outcome == UpgradeHistoryFailed:
+		state, message = RolloutStateFailed, outcomeMessage
+	case outcome != "":
+		state = RolloutStateInProgress
+	default:
+		// the stage has not been started yet
+		state = RolloutStateInProgress
+	}
+
+	rolloutStatus := fmt.Sprintf("%s:%s:%d", stage, state, percent)
+	if message != "" {
+		message = strings.Join(strings.Fields(message), " ")
+		if len(message) > maxRolloutStatusMessageLength {
+			message = message[:maxRolloutStatusMessageLength-3] + "..."
+		}
+		rolloutStatus += ":" + message
+	}
+	ibu.Status.RolloutStatus = rolloutStatus
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordRolloutStatus(t *testing.T) {
+	newIBU := func(stage ibuv1.ImageBasedUpgradeStage) *ibuv1.ImageBasedUpgrade {
+		return &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{Stage: stage}}
+	}
+
+	ibu := newIBU(ibuv1.Stages.Idle)
+	SetStatusCondition(&ibu.Status.Conditions, ConditionTypes.Idle, ConditionReasons.Idle, metav1.ConditionTrue, "Idle", 1)
+	RecordRolloutStatus(ibu)
+	assert.Equal(t, "Idle:Idle:0", ibu.Status.RolloutStatus)
+
+	SetIdleStatusInProgress(ibu, ConditionReasons.Finalizing, "Finalizing")
+	RecordRolloutStatus(ibu)
+	assert.Equal(t, "Idle:InProgress:0", ibu.Status.RolloutStatus)
+
+	ibu = newIBU(ibuv1.Stages.Prep)
+	RecordRolloutStatus(ibu)
+	assert.Equal(t, "Prep:InProgress:0", ibu.Status.RolloutStatus)
+
+	SetPrepStatusInProgress(ibu, "Pulling the seed image")
+	SetStageProgress(ibu, "Pulling the seed image", 30)
+	RecordRolloutStatus(ibu)
+	assert.Equal(t, "Prep:InProgress:30", ibu.Status.RolloutStatus)
+
+	SetPrepStatusCompleted(ibu, "Prep completed")
+	RecordRolloutStatus(ibu)
+	assert.Equal(t, "Prep:Completed:100", ibu.Status.RolloutStatus)
+
+	ibu = newIBU(ibuv1.Stages.Upgrade)
+	SetUpgradeStatusInProgress(ibu, "Applying Policy Manifests")
+	SetStageProgress(ibu, "Applying Policy Manifests", 60)
+	SetUpgradeStatusFailed(ibu, "failed to apply\nthe policy manifests")
+	RecordRolloutStatus(ibu)
+	assert.Equal(t, "Upgrade:Failed:60:failed to apply the policy manifests", ibu.Status.RolloutStatus)
+
+	ibu = newIBU(ibuv1.Stages.Upgrade)
+	SetIBUStatusBlocked(ibu, strings.Repeat("x", 300))
+	RecordRolloutStatus(ibu)
+	assert.Equal(t, "Upgrade:Blocked:0:"+strings.Repeat("x", 197)+"...", ibu.Status.RolloutStatus)
+}
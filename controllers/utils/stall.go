@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultStallThresholdPercent is the percentage of the expected duration of a stage past which it is stalled, unless
+// set by .spec.stallDetection.thresholdPercent
+const DefaultStallThresholdPercent = 300
+
+// defaultStageDurations are the expected durations of the stages that never completed on the node
+var defaultStageDurations = map[ibuv1.ImageBasedUpgradeStage]time.Duration{
+	ibuv1.Stages.Prep:     time.Hour,
+	ibuv1.Stages.Upgrade:  time.Hour,
+	ibuv1.Stages.Rollback: 30 * time.Minute,
+}
+
+// ExpectedStageDuration returns the average of the previous durations of the stage, or its default duration if it
+// never completed on the node
+func ExpectedStageDuration(stage ibuv1.ImageBasedUpgradeStage, durations StageDurations) time.Duration {
+	if average := durations.Average(stage); average > 0 {
+		return average
+	}
+	return defaultStageDurations[stage]
+}
+
+// SetProgressingCondition sets the Progressing condition while the desired stage is in progress. The condition is set
+// to False with the Stalled reason once the stage is in progress for longer than the stall threshold percentage of its
+// expected duration, and removed when no Prep, Upgrade or Rollback is in progress.
+// It returns the interval after which the stage is stalled, for the condition to be updated then, or 0 if none.
+// The caller is responsible for persisting the status.
+func SetProgressingCondition(ibu *ibuv1.ImageBasedUpgrade, durations StageDurations, now time.Time) time.Duration {
+	stage := ibu.Spec.Stage
+	history := getStageHistory(ibu)
+	if stage == ibuv1.Stages.Idle || !IsStageInProgress(ibu, stage) ||
+		history == nil || history.StartTime.IsZero() || !history.CompletionTime.IsZero() {
+		meta.RemoveStatusCondition(&ibu.Status.Conditions, string(ConditionTypes.Progressing))
+		return 0
+	}
+
+	threshold := DefaultStallThresholdPercent
+	if ibu.Spec.StallDetection != nil && ibu.Spec.StallDetection.ThresholdPercent > 0 {
+		threshold = ibu.Spec.StallDetection.ThresholdPercent
+	}
+	expected := ExpectedStageDuration(stage, durations)
+	stalledAt := history.StartTime.Add(expected * time.Duration(threshold) / 100)
+
+	if !now.Before(stalledAt) {
+		SetStatusCondition(&ibu.Status.Conditions,
+			ConditionTypes.Progressing,
+			ConditionReasons.Stalled,
+			metav1.ConditionFalse,
+			fmt.Sprintf("%s stage in progress since %s, over %d%% of its expected duration of %s",
+				stage, history.StartTime.UTC().Format(time.RFC3339), threshold, expected),
+			ibu.Generation,
+		)
+		return 0
+	}
+
+	SetStatusCondition(&ibu.Status.Conditions,
+		ConditionTypes.Progressing,
+		ConditionReasons.InProgress,
+		metav1.ConditionTrue,
+		fmt.Sprintf("%s stage expected to complete by %s, stalled if not completed by %s", stage,
+			history.StartTime.Add(expected).UTC().Format(time.RFC3339), stalledAt.UTC().Format(time.RFC3339)),
+		ibu.Generation,
+	)
+	return stalledAt.Sub(now)
+}
+
+// IsStageStalled returns true if the desired stage is reported as stalled by the Progressing condition
+func IsStageStalled(ibu *ibuv1.ImageBasedUpgrade) bool {
+	condition := meta.FindStatusCondition(ibu.Status.Conditions, string(ConditionTypes.Progressing))
+	return condition != nil && condition.Status == metav1.ConditionFalse && condition.Reason == string(ConditionReasons.Stalled)
+}
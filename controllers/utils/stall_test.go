@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetProgressingCondition(t *testing.T) {
+	startTime := metav1.Time{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+	durations := StageDurations{
+		ibuv1.Stages.Prep: {{DurationSeconds: 1200}, {DurationSeconds: 1800}},
+	}
+	newIBU := func(stage ibuv1.ImageBasedUpgradeStage) *ibuv1.ImageBasedUpgrade {
+		ibu := &ibuv1.ImageBasedUpgrade{
+			Spec: ibuv1.ImageBasedUpgradeSpec{Stage: stage},
+			Status: ibuv1.ImageBasedUpgradeStatus{History: []*ibuv1.History{
+				{Stage: stage, StartTime: startTime},
+			}},
+		}
+		switch stage {
+		case ibuv1.Stages.Prep:
+			SetPrepStatusInProgress(ibu, "in progress")
+		case ibuv1.Stages.Upgrade:
+			SetUpgradeStatusInProgress(ibu, "in progress")
+		}
+		return ibu
+	}
+
+	tests := []struct {
+		name            string
+		ibu             *ibuv1.ImageBasedUpgrade
+		now             time.Time
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  ConditionReason
+		expectedRequeue time.Duration
+	}{
+		{
+			name:            "prep in progress within the average duration threshold",
+			ibu:             newIBU(ibuv1.Stages.Prep),
+			now:             startTime.Add(time.Hour),
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  ConditionReasons.InProgress,
+			expectedRequeue: 15 * time.Minute,
+		},
+		{
+			name:           "prep in progress past the average duration threshold",
+			ibu:            newIBU(ibuv1.Stages.Prep),
+			now:            startTime.Add(75 * time.Minute),
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ConditionReasons.Stalled,
+		},
+		{
+			name: "custom threshold",
+			ibu: func() *ibuv1.ImageBasedUpgrade {
+				ibu := newIBU(ibuv1.Stages.Prep)
+				ibu.Spec.StallDetection = &ibuv1.StallDetection{ThresholdPercent: 200}
+				return ibu
+			}(),
+			now:            startTime.Add(time.Hour),
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ConditionReasons.Stalled,
+		},
+		{
+			name:            "upgrade without previous duration uses the default duration",
+			ibu:             newIBU(ibuv1.Stages.Upgrade),
+			now:             startTime.Add(2 * time.Hour),
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  ConditionReasons.InProgress,
+			expectedRequeue: time.Hour,
+		},
+		{
+			name: "completed stage",
+			ibu: func() *ibuv1.ImageBasedUpgrade {
+				ibu := newIBU(ibuv1.Stages.Prep)
+				ibu.Status.History[0].CompletionTime = metav1.Time{Time: startTime.Add(5 * time.Hour)}
+				SetPrepStatusCompleted(ibu, "completed")
+				return ibu
+			}(),
+			now: startTime.Add(5 * time.Hour),
+		},
+		{
+			name: "idle",
+			ibu:  newIBU(ibuv1.Stages.Idle),
+			now:  startTime.Add(5 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requeue := SetProgressingCondition(tt.ibu, durations, tt.now)
+			assert.Equal(t, tt.expectedRequeue, requeue)
+			condition := meta.FindStatusCondition(tt.ibu.Status.Conditions, string(ConditionTypes.Progressing))
+			if tt.expectedStatus == "" {
+				assert.Nil(t, condition)
+				assert.False(t, IsStageStalled(tt.ibu))
+				return
+			}
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, tt.expectedStatus, condition.Status)
+				assert.Equal(t, string(tt.expectedReason), condition.Reason)
+			}
+			assert.Equal(t, tt.expectedReason == ConditionReasons.Stalled, IsStageStalled(tt.ibu))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maintenanceWindowPeriods are the intervals between the openings of a recurring window
+var maintenanceWindowPeriods = map[ibuv1.MaintenanceWindowRecurrence]time.Duration{
+	ibuv1.MaintenanceWindowRecurrences.Daily:  24 * time.Hour,
+	ibuv1.MaintenanceWindowRecurrences.Weekly: 7 * 24 * time.Hour,
+}
+
+// MaintenanceWindowState returns whether the maintenance window is open at the given time, along with the time it
+// closes if open, or else the time it opens next, the zero time if it never opens again
+func MaintenanceWindowState(window *ibuv1.MaintenanceWindow, now time.Time) (bool, time.Time) {
+	start := window.Start.Time
+	if now.Before(start) {
+		return false, start
+	}
+	period := maintenanceWindowPeriods[window.Recurrence]
+	if period > 0 {
+		// The last opening of the window
+		start = start.Add(now.Sub(start) / period * period)
+	}
+	if end := start.Add(window.Duration.Duration); now.Before(end) {
+		return true, end
+	}
+	if period == 0 {
+		return false, time.Time{}
+	}
+	return false, start.Add(period)
+}
+
+// SetWaitingForWindow holds the transition into the desired stage until the maintenance window opens, by setting its in
+// progress condition to False with the WaitingForWindow reason. It returns the interval after which the window opens,
+// or 0 if it never opens again. The caller is responsible for persisting the status.
+func SetWaitingForWindow(ibu *ibuv1.ImageBasedUpgrade, now time.Time) time.Duration {
+	_, next := MaintenanceWindowState(ibu.Spec.MaintenanceWindow, now)
+	msg := fmt.Sprintf("Waiting for the maintenance window opening at %s", next.UTC().Format(time.RFC3339))
+	if next.IsZero() {
+		msg = fmt.Sprintf("The maintenance window closed at %s and does not open again, update spec.maintenanceWindow",
+			ibu.Spec.MaintenanceWindow.Start.Add(ibu.Spec.MaintenanceWindow.Duration.Duration).UTC().Format(time.RFC3339))
+	}
+	SetStatusCondition(&ibu.Status.Conditions,
+		GetInProgressConditionType(ibu.Spec.Stage),
+		ConditionReasons.WaitingForWindow,
+		metav1.ConditionFalse,
+		msg,
+		ibu.Generation,
+	)
+	if next.IsZero() {
+		return 0
+	}
+	return next.Sub(now)
+}
+
+// ClearWaitingForWindow removes the held transitions into the stages other than the desired one, as the transition
+// is no longer requested
+func ClearWaitingForWindow(ibu *ibuv1.ImageBasedUpgrade) {
+	for _, stage := range []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Upgrade, ibuv1.Stages.Rollback} {
+		if stage != ibu.Spec.Stage && IsWaitingForWindow(ibu, stage) {
+			meta.RemoveStatusCondition(&ibu.Status.Conditions, string(GetInProgressConditionType(stage)))
+		}
+	}
+}
+
+// IsWaitingForWindow checks if the transition into the given stage is held until the maintenance window opens
+func IsWaitingForWindow(ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage) bool {
+	condition := GetInProgressCondition(ibu, stage)
+	return condition != nil && condition.Reason == string(ConditionReasons.WaitingForWindow)
+}
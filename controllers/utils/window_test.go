@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaintenanceWindowState(t *testing.T) {
+	start := time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+	newWindow := func(recurrence ibuv1.MaintenanceWindowRecurrence) *ibuv1.MaintenanceWindow {
+		return &ibuv1.MaintenanceWindow{
+			Start:      metav1.Time{Time: start},
+			Duration:   metav1.Duration{Duration: 4 * time.Hour},
+			Recurrence: recurrence,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		window       *ibuv1.MaintenanceWindow
+		now          time.Time
+		expectedOpen bool
+		expectedNext time.Time
+	}{
+		{
+			name:         "before the first opening",
+			window:       newWindow(""),
+			now:          start.Add(-time.Hour),
+			expectedNext: start,
+		},
+		{
+			name:         "open",
+			window:       newWindow(ibuv1.MaintenanceWindowRecurrences.None),
+			now:          start.Add(time.Hour),
+			expectedOpen: true,
+			expectedNext: start.Add(4 * time.Hour),
+		},
+		{
+			name:   "closed for good",
+			window: newWindow(ibuv1.MaintenanceWindowRecurrences.None),
+			now:    start.Add(4 * time.Hour),
+		},
+		{
+			name:         "daily window open again",
+			window:       newWindow(ibuv1.MaintenanceWindowRecurrences.Daily),
+			now:          start.Add(72*time.Hour + 3*time.Hour),
+			expectedOpen: true,
+			expectedNext: start.Add(76 * time.Hour),
+		},
+		{
+			name:         "daily window closed",
+			window:       newWindow(ibuv1.MaintenanceWindowRecurrences.Daily),
+			now:          start.Add(72*time.Hour + 5*time.Hour),
+			expectedNext: start.Add(96 * time.Hour),
+		},
+		{
+			name:         "weekly window closed",
+			window:       newWindow(ibuv1.MaintenanceWindowRecurrences.Weekly),
+			now:          start.Add(24 * time.Hour),
+			expectedNext: start.Add(7 * 24 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, next := MaintenanceWindowState(tt.window, tt.now)
+			assert.Equal(t, tt.expectedOpen, open)
+			assert.Equal(t, tt.expectedNext, next)
+		})
+	}
+}
+
+func TestSetWaitingForWindow(t *testing.T) {
+	start := time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+	ibu := &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{
+		Stage: ibuv1.Stages.Upgrade,
+		MaintenanceWindow: &ibuv1.MaintenanceWindow{
+			Start:    metav1.Time{Time: start},
+			Duration: metav1.Duration{Duration: time.Hour},
+		},
+	}}
+
+	assert.Equal(t, 2*time.Hour, SetWaitingForWindow(ibu, start.Add(-2*time.Hour)))
+	assert.True(t, IsWaitingForWindow(ibu, ibuv1.Stages.Upgrade))
+	assert.False(t, IsStageInProgress(ibu, ibuv1.Stages.Upgrade))
+	condition := GetInProgressCondition(ibu, ibuv1.Stages.Upgrade)
+	assert.Equal(t, "Waiting for the maintenance window opening at 2025-06-01T02:00:00Z", condition.Message)
+
+	assert.Equal(t, time.Duration(0), SetWaitingForWindow(ibu, start.Add(2*time.Hour)))
+	condition = GetInProgressCondition(ibu, ibuv1.Stages.Upgrade)
+	assert.Equal(t, "The maintenance window closed at 2025-06-01T03:00:00Z and does not open again, update spec.maintenanceWindow",
+		condition.Message)
+
+	// The transition is no longer requested
+	ClearWaitingForWindow(ibu)
+	assert.True(t, IsWaitingForWindow(ibu, ibuv1.Stages.Upgrade))
+	ibu.Spec.Stage = ibuv1.Stages.Idle
+	ClearWaitingForWindow(ibu)
+	assert.Nil(t, GetInProgressCondition(ibu, ibuv1.Stages.Upgrade))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Sources of the audit events
+const (
+	SourceManager = "lca-manager"
+	SourceCLI     = "lca-cli"
+)
+
+// Types of the audit events
+const (
+	StageTransition = "StageTransition"
+	Command         = "Command"
+	FileModified    = "FileModified"
+	Backup          = "Backup"
+)
+
+// Event is an entry of the audit log
+type Event struct {
+	Time    metav1.Time `json:"time"`
+	Source  string      `json:"source"`
+	Type    string      `json:"type"`
+	Stage   string      `json:"stage,omitempty"`
+	Message string      `json:"message"`
+}
+
+// Log appends the audit events to a file on the host, one JSON event per line. Events are never rewritten nor
+// removed. All methods are no-ops on a nil Log.
+type Log struct {
+	file   string
+	source string
+	mu     sync.Mutex
+	// now is a var in order to override it in unit tests
+	now func() time.Time
+}
+
+// NewLog returns a Log appending the events of the source to the file
+func NewLog(file, source string) *Log {
+	return &Log{file: file, source: source, now: time.Now}
+}
+
+// File returns the path of the audit log
+func (l *Log) File() string {
+	if l == nil {
+		return ""
+	}
+	return l.file
+}
+
+// Record appends an event to the audit log
+func (l *Log) Record(eventType, stage, message string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	event := Event{
+		Time:    metav1.NewTime(l.now().UTC().Truncate(time.Second)),
+		Source:  l.source,
+		Type:    eventType,
+		Stage:   stage,
+		Message: message,
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.file), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for audit log %s: %w", l.file, err)
+	}
+	f, err := os.OpenFile(l.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", l.file, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log %s: %w", l.file, err)
+	}
+	return nil
+}
+
+// RecordConditionChanges appends a StageTransition event for each condition added or changed between before and after
+func (l *Log) RecordConditionChanges(stage string, before, after []metav1.Condition) error {
+	if l == nil {
+		return nil
+	}
+	var errs []error
+	for _, condition := range after {
+		previous := meta.FindStatusCondition(before, condition.Type)
+		if previous != nil && previous.Status == condition.Status && previous.Reason == condition.Reason {
+			continue
+		}
+		message := fmt.Sprintf("%s=%s (%s): %s", condition.Type, condition.Status, condition.Reason, condition.Message)
+		errs = append(errs, l.Record(StageTransition, stage, message))
+	}
+	return errors.Join(errs...)
+}
+
+// ReadEvents returns the events of the audit log recorded since the given time, or all of them if since is zero.
+// No events are returned if the audit log does not exist.
+func ReadEvents(file string, since time.Time) ([]Event, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Event{}, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log %s: %w", file, err)
+	}
+	defer f.Close()
+
+	events := []Event{}
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode line %d of audit log %s: %w", lineNumber, file, err)
+		}
+		if !since.IsZero() && event.Time.Time.Before(since) {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", file, err)
+	}
+	return events, nil
+}
+
+// CopyLog copies the audit log to another location, such as the new stateroot, so that the trail is kept across the
+// pivot. Nothing is copied if the audit log does not exist.
+func (l *Log) CopyLog(to string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	src, err := os.Open(l.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log %s: %w", l.file, err)
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(to), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for audit log %s: %w", to, err)
+	}
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log copy %s: %w", to, err)
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to copy audit log to %s: %w", to, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lca", "audit.log")
+	auditLog := NewLog(file, SourceManager)
+	start := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	auditLog.now = func() time.Time { return start }
+
+	// Nothing recorded yet
+	events, err := ReadEvents(file, time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	assert.NoError(t, auditLog.Record(Backup, "Upgrade", "OADP backups completed: acm-klusterlet"))
+	auditLog.now = func() time.Time { return start.Add(time.Hour) }
+	assert.NoError(t, auditLog.RecordConditionChanges("Upgrade",
+		[]metav1.Condition{
+			{Type: "PrepCompleted", Status: metav1.ConditionTrue, Reason: "Completed"},
+			{Type: "UpgradeInProgress", Status: metav1.ConditionTrue, Reason: "InProgress", Message: "Exporting"},
+		},
+		[]metav1.Condition{
+			{Type: "PrepCompleted", Status: metav1.ConditionTrue, Reason: "Completed"},
+			{Type: "UpgradeInProgress", Status: metav1.ConditionTrue, Reason: "InProgress", Message: "Rebooting"},
+			{Type: "UpgradeCompleted", Status: metav1.ConditionFalse, Reason: "InProgress", Message: "In progress"},
+		}))
+
+	// A restarted LCA appends to the same log
+	assert.NoError(t, NewLog(file, SourceCLI).Record(Command, "Upgrade", "lca-cli post-pivot started"))
+
+	events, err = ReadEvents(file, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 3) {
+		assert.True(t, start.Equal(events[0].Time.Time))
+		events[0].Time = metav1.Time{}
+		assert.Equal(t, Event{
+			Source:  SourceManager,
+			Type:    Backup,
+			Stage:   "Upgrade",
+			Message: "OADP backups completed: acm-klusterlet",
+		}, events[0])
+		assert.Equal(t, "UpgradeCompleted=False (InProgress): In progress", events[1].Message)
+		assert.Equal(t, SourceCLI, events[2].Source)
+	}
+
+	events, err = ReadEvents(file, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	// The log is carried over to the new stateroot
+	copied := filepath.Join(t.TempDir(), "var", "lib", "lca", "audit.log")
+	assert.NoError(t, auditLog.CopyLog(copied))
+	original, _ := os.ReadFile(file)
+	copiedContent, _ := os.ReadFile(copied)
+	assert.Equal(t, original, copiedContent)
+
+	assert.NoError(t, os.WriteFile(file, []byte("not json\n"), 0o600))
+	_, err = ReadEvents(file, time.Time{})
+	assert.ErrorContains(t, err, "failed to decode line 1 of audit log")
+
+	// A nil log is a no-op
+	var nilLog *Log
+	assert.NoError(t, nilLog.Record(Command, "", "ignored"))
+	assert.NoError(t, nilLog.RecordConditionChanges("", nil, []metav1.Condition{{Type: "Idle"}}))
+	assert.NoError(t, nilLog.CopyLog(copied))
+	assert.Empty(t, nilLog.File())
+}
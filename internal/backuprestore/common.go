@@ -64,6 +64,9 @@ const (
 	backupLabel    = "lca.openshift.io/backup"
 	clusterIDLabel = "config.openshift.io/clusterID" // label for backups applied by lifecycle agent
 
+	// OptionalAnn marks the Backup and Restore CRs whose failure does not fail the upgrade
+	OptionalAnn = "lca.openshift.io/optional"
+
 	OadpPath        = "/opt/OADP"
 	OadpRestorePath = OadpPath + "/veleroRestore"
 	OadpDpaPath     = OadpPath + "/dpa"
@@ -93,11 +96,14 @@ type BackuperRestorer interface {
 	CheckOadpOperatorAvailability(ctx context.Context) error
 	PatchPVsReclaimPolicy(ctx context.Context) error
 	RestorePVsReclaimPolicy(ctx context.Context) error
+	SetPersistentVolumeBackups(ctx context.Context, backupGroups [][]*velerov1.Backup, policy ibuv1.PersistentVolumesPolicy) error
 	EnsureOadpConfiguration(ctx context.Context) error
 	ExportOadpConfigurationToDir(ctx context.Context, toDir, oadpNamespace string) error
 	ExportRestoresToDir(ctx context.Context, configMaps []ibuv1.ConfigMapRef, toDir string) error
 	GetSortedBackupsFromConfigmap(ctx context.Context, content []ibuv1.ConfigMapRef) ([][]*velerov1.Backup, error)
 	LoadRestoresFromOadpRestorePath() ([][]*velerov1.Restore, error)
+	RetryFailedBackups(ctx context.Context, backups []*velerov1.Backup, failedBackups []string, maxRetries int) (bool, error)
+	RetryFailedRestores(ctx context.Context, restores []*velerov1.Restore, failedRestores []string, maxRetries int) (bool, error)
 	StartOrTrackBackup(ctx context.Context, backups []*velerov1.Backup) (*BackupTracker, error)
 	StartOrTrackRestore(ctx context.Context, restores []*velerov1.Restore) (*RestoreTracker, error)
 	ValidateOadpConfigmaps(ctx context.Context, content []ibuv1.ConfigMapRef) error
@@ -314,6 +320,11 @@ func setBackupLabel(backup *velerov1.Backup, newLabels map[string]string) {
 	backup.SetLabels(labels)
 }
 
+// IsOptional reports whether the Backup or Restore CR is annotated as optional
+func IsOptional(obj metav1.Object) bool {
+	return obj.GetAnnotations()[OptionalAnn] == "true"
+}
+
 func IsDPAReconciled(dpa *unstructured.Unstructured) bool {
 	if dpa.Object["status"] == nil {
 		return false
@@ -244,6 +244,50 @@ func (mr *MockBackuperRestorerMockRecorder) RestorePVsReclaimPolicy(ctx any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePVsReclaimPolicy", reflect.TypeOf((*MockBackuperRestorer)(nil).RestorePVsReclaimPolicy), ctx)
 }
 
+// RetryFailedBackups mocks base method.
+func (m *MockBackuperRestorer) RetryFailedBackups(ctx context.Context, backups []*v10.Backup, failedBackups []string, maxRetries int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryFailedBackups", ctx, backups, failedBackups, maxRetries)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryFailedBackups indicates an expected call of RetryFailedBackups.
+func (mr *MockBackuperRestorerMockRecorder) RetryFailedBackups(ctx, backups, failedBackups, maxRetries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryFailedBackups", reflect.TypeOf((*MockBackuperRestorer)(nil).RetryFailedBackups), ctx, backups, failedBackups, maxRetries)
+}
+
+// RetryFailedRestores mocks base method.
+func (m *MockBackuperRestorer) RetryFailedRestores(ctx context.Context, restores []*v10.Restore, failedRestores []string, maxRetries int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryFailedRestores", ctx, restores, failedRestores, maxRetries)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryFailedRestores indicates an expected call of RetryFailedRestores.
+func (mr *MockBackuperRestorerMockRecorder) RetryFailedRestores(ctx, restores, failedRestores, maxRetries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryFailedRestores", reflect.TypeOf((*MockBackuperRestorer)(nil).RetryFailedRestores), ctx, restores, failedRestores, maxRetries)
+}
+
+// SetPersistentVolumeBackups mocks base method.
+func (m *MockBackuperRestorer) SetPersistentVolumeBackups(ctx context.Context, backupGroups [][]*v10.Backup, policy v1.PersistentVolumesPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPersistentVolumeBackups", ctx, backupGroups, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPersistentVolumeBackups indicates an expected call of SetPersistentVolumeBackups.
+func (mr *MockBackuperRestorerMockRecorder) SetPersistentVolumeBackups(ctx, backupGroups, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPersistentVolumeBackups", reflect.TypeOf((*MockBackuperRestorer)(nil).SetPersistentVolumeBackups), ctx, backupGroups, policy)
+}
+
 // StartOrTrackBackup mocks base method.
 func (m *MockBackuperRestorer) StartOrTrackBackup(ctx context.Context, backups []*v10.Backup) (*backuprestore.BackupTracker, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,195 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuprestore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/samber/lo"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// retryAttemptAnn records on the Backup and Restore CRs recreated by LCA the number of retries done so far
+const retryAttemptAnn = "lca.openshift.io/retry-attempt"
+
+// Use vars for the deletion polling in order to override them in unit tests
+var (
+	restoreDeletionInterval = 1 * time.Second
+	restoreDeletionTimeout  = 5 * time.Minute
+)
+
+func getRetryAttempt(obj metav1.Object) int {
+	attempt, err := strconv.Atoi(obj.GetAnnotations()[retryAttemptAnn])
+	if err != nil {
+		return 0
+	}
+	return attempt
+}
+
+func setRetryAttempt(obj metav1.Object, attempt int) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[retryAttemptAnn] = strconv.Itoa(attempt)
+	obj.SetAnnotations(annotations)
+}
+
+// RetryFailedBackups deletes the failed backups from the object storage and recreates them, as long as none of them
+// has already been retried maxRetries times. It returns false if the backups are not retried.
+func (h *BRHandler) RetryFailedBackups(ctx context.Context, backups []*velerov1.Backup, failedBackups []string,
+	maxRetries int) (bool, error) {
+	clusterID, err := getClusterID(ctx, h.Client)
+	if err != nil {
+		return false, err
+	}
+
+	var toRetry []*velerov1.Backup
+	var existingBackups []velerov1.Backup
+	for _, backup := range backups {
+		if !lo.Contains(failedBackups, backup.Name) {
+			continue
+		}
+		existingBackup, err := getBackup(ctx, h.Client, backup.Name, backup.Namespace)
+		if err != nil {
+			return false, err
+		}
+		attempt := 0
+		if existingBackup != nil {
+			attempt = getRetryAttempt(existingBackup)
+			existingBackups = append(existingBackups, *existingBackup)
+		}
+		if attempt >= maxRetries {
+			h.Log.Info("Backup retries exhausted", "name", backup.Name, "retries", attempt)
+			return false, nil
+		}
+
+		retry := backup.DeepCopy()
+		retry.ResourceVersion = ""
+		setRetryAttempt(retry, attempt+1)
+		toRetry = append(toRetry, retry)
+	}
+	if len(toRetry) == 0 {
+		return false, nil
+	}
+
+	for _, backup := range existingBackups {
+		deleteBackupRequest := &velerov1.DeleteBackupRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      backup.Name,
+				Namespace: backup.Namespace,
+				Labels:    map[string]string{clusterIDLabel: clusterID},
+			},
+			Spec: velerov1.DeleteBackupRequestSpec{
+				BackupName: backup.Name,
+			},
+		}
+		if err := h.Create(ctx, deleteBackupRequest); err != nil {
+			return false, fmt.Errorf("could not apply DeleteBackupRequest CR: %w", err)
+		}
+		h.Log.Info("Failed Backup deletion request has been sent", "backup", backup.Name)
+	}
+	if err := h.ensureBackupsDeleted(ctx, existingBackups); err != nil {
+		return false, err
+	}
+
+	for _, backup := range toRetry {
+		if err := h.createNewBackupCr(ctx, backup); err != nil {
+			return false, err
+		}
+		h.Log.Info("Backup retried", "name", backup.Name, "attempt", getRetryAttempt(backup))
+	}
+	return true, nil
+}
+
+// RetryFailedRestores deletes the failed restores and recreates them, as long as none of them has already been retried
+// maxRetries times. It returns false if the restores are not retried.
+func (h *BRHandler) RetryFailedRestores(ctx context.Context, restores []*velerov1.Restore, failedRestores []string,
+	maxRetries int) (bool, error) {
+	var toRetry []*velerov1.Restore
+	var existingRestores []*velerov1.Restore
+	for _, restore := range restores {
+		if !lo.Contains(failedRestores, restore.Name) {
+			continue
+		}
+		existingRestore := &velerov1.Restore{}
+		attempt := 0
+		if err := h.Get(ctx, types.NamespacedName{Name: restore.Name, Namespace: restore.Namespace}, existingRestore); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return false, fmt.Errorf("failed to get restore: %w", err)
+			}
+		} else {
+			attempt = getRetryAttempt(existingRestore)
+			existingRestores = append(existingRestores, existingRestore)
+		}
+		if attempt >= maxRetries {
+			h.Log.Info("Restore retries exhausted", "name", restore.Name, "retries", attempt)
+			return false, nil
+		}
+
+		retry := restore.DeepCopy()
+		retry.ResourceVersion = ""
+		setRetryAttempt(retry, attempt+1)
+		toRetry = append(toRetry, retry)
+	}
+	if len(toRetry) == 0 {
+		return false, nil
+	}
+
+	for _, restore := range existingRestores {
+		if err := h.Delete(ctx, restore); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed to delete restore %s: %w", restore.Name, err)
+		}
+		h.Log.Info("Failed Restore deleted", "restore", restore.Name)
+	}
+	if err := h.waitForRestoresDeleted(ctx, existingRestores); err != nil {
+		return false, NewBRFailedError("Restore", fmt.Sprintf("failed to delete restores: %s", err.Error()))
+	}
+
+	for _, restore := range toRetry {
+		if err := h.Create(ctx, restore); err != nil {
+			return false, fmt.Errorf("failed to create restore: %w", err)
+		}
+		h.Log.Info("Restore retried", "name", restore.Name, "attempt", getRetryAttempt(restore))
+	}
+	return true, nil
+}
+
+func (h *BRHandler) waitForRestoresDeleted(ctx context.Context, restores []*velerov1.Restore) error {
+	return wait.PollUntilContextTimeout(ctx, restoreDeletionInterval, restoreDeletionTimeout, true, //nolint:wrapcheck
+		func(ctx context.Context) (bool, error) {
+			for _, restore := range restores {
+				if err := h.Get(ctx, types.NamespacedName{Name: restore.Name, Namespace: restore.Namespace}, &velerov1.Restore{}); err != nil {
+					if k8serrors.IsNotFound(err) {
+						continue
+					}
+					return false, nil
+				}
+				h.Log.Info("Waiting for Restore to be deleted", "restore", restore.Name)
+				return false, nil
+			}
+			return true, nil
+		})
+}
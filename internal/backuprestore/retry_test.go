@@ -0,0 +1,111 @@
+package backuprestore
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func withRetryAttempt[T metav1.Object](obj T, attempt int) T {
+	setRetryAttempt(obj, attempt)
+	return obj
+}
+
+func TestRetryFailedRestores(t *testing.T) {
+	testcases := []struct {
+		name             string
+		existingRestores []client.Object
+		maxRetries       int
+		expectedRetried  bool
+		expectedAttempt  string
+	}{
+		{
+			name:             "failed restore retried",
+			existingRestores: []client.Object{fakeRestoreCrWithStatus("restore1", "1", "backup1", velerov1.RestorePhaseFailed)},
+			maxRetries:       2,
+			expectedRetried:  true,
+			expectedAttempt:  "1",
+		},
+		{
+			name: "failed restore retried again",
+			existingRestores: []client.Object{
+				withRetryAttempt(fakeRestoreCrWithStatus("restore1", "1", "backup1", velerov1.RestorePhaseFailed), 1),
+			},
+			maxRetries:      2,
+			expectedRetried: true,
+			expectedAttempt: "2",
+		},
+		{
+			name: "retries exhausted",
+			existingRestores: []client.Object{
+				withRetryAttempt(fakeRestoreCrWithStatus("restore1", "1", "backup1", velerov1.RestorePhaseFailed), 2),
+			},
+			maxRetries:      2,
+			expectedRetried: false,
+			expectedAttempt: "2",
+		},
+		{
+			name:             "retries disabled",
+			existingRestores: []client.Object{fakeRestoreCrWithStatus("restore1", "1", "backup1", velerov1.RestorePhaseFailed)},
+			maxRetries:       0,
+			expectedRetried:  false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient, err := getFakeClientFromObjects(tc.existingRestores...)
+			assert.NoError(t, err)
+			handler := &BRHandler{
+				Client: fakeClient,
+				Log:    ctrl.Log.WithName("BackupRestore"),
+			}
+
+			restores := []*velerov1.Restore{
+				fakeRestoreCr("restore1", "1", "backup1"),
+				fakeRestoreCr("restore2", "1", "backup2"),
+			}
+			retried, err := handler.RetryFailedRestores(context.Background(), restores, []string{"restore1"}, tc.maxRetries)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRetried, retried)
+
+			restore := &velerov1.Restore{}
+			assert.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "restore1", Namespace: OadpNs}, restore))
+			assert.Equal(t, tc.expectedAttempt, restore.GetAnnotations()[retryAttemptAnn])
+			if tc.expectedRetried {
+				assert.Empty(t, restore.Status.Phase)
+			}
+			assert.Error(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "restore2", Namespace: OadpNs}, &velerov1.Restore{}))
+		})
+	}
+}
+
+func TestRetryFailedBackupsExhausted(t *testing.T) {
+	clusterVersion := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: configv1.ClusterID(testClusterID)},
+	}
+	fakeClient, err := getFakeClientFromObjects(clusterVersion,
+		withRetryAttempt(fakeBackupCrWithStatus("backup1", "1", "fakeResource1", velerov1.BackupPhaseFailed), 1))
+	assert.NoError(t, err)
+	handler := &BRHandler{
+		Client: fakeClient,
+		Log:    ctrl.Log.WithName("BackupRestore"),
+	}
+
+	backups := []*velerov1.Backup{fakeBackupCr("backup1", "1", "fakeResource1")}
+	retried, err := handler.RetryFailedBackups(context.Background(), backups, []string{"backup1"}, 1)
+	assert.NoError(t, err)
+	assert.False(t, retried)
+
+	deletionRequests := &velerov1.DeleteBackupRequestList{}
+	assert.NoError(t, fakeClient.List(context.Background(), deletionRequests))
+	assert.Empty(t, deletionRequests.Items)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuprestore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+)
+
+const (
+	// PersistentVolumesAnn is set on the Backup CRs completed by LCA to carry over the persistent volumes, to the
+	// policy they were completed for
+	PersistentVolumesAnn = "lca.openshift.io/persistent-volumes"
+
+	pvcResource           = "persistentvolumeclaims"
+	pvResource            = "persistentvolumes"
+	logicalVolumeResource = "logicalvolumes.topolvm.io"
+	logicalVolumeStatus   = "logicalvolumes"
+	allResources          = "*"
+)
+
+// SetPersistentVolumeBackups completes the Backup CRs that include the claims of LVMS persistent volumes, so that
+// the content of the volumes is carried over the upgrade as defined by the policy. The Backup CRs are left as-is if no
+// policy is defined.
+func (h *BRHandler) SetPersistentVolumeBackups(ctx context.Context, backupGroups [][]*velerov1.Backup,
+	policy ibuv1.PersistentVolumesPolicy) error {
+	if policy == "" {
+		return nil
+	}
+
+	namespaces, err := h.getLvmsClaimNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	if len(namespaces) == 0 {
+		h.Log.Info("No claims of LVMS persistent volumes found, the backups are left as-is")
+		return nil
+	}
+
+	for _, backups := range backupGroups {
+		for _, backup := range backups {
+			if !backupIncludesClaims(backup, namespaces) {
+				continue
+			}
+			h.Log.Info("Completing the backup to carry over the LVMS persistent volumes", "backup", backup.Name, "policy", policy)
+			setPersistentVolumeBackup(backup, policy)
+		}
+	}
+	return nil
+}
+
+// getLvmsClaimNamespaces returns the namespaces of the claims bound to LVMS persistent volumes
+func (h *BRHandler) getLvmsClaimNamespaces(ctx context.Context) ([]string, error) {
+	pvList := &corev1.PersistentVolumeList{}
+	if err := h.List(ctx, pvList); err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumes: %w", err)
+	}
+
+	var namespaces []string
+	for _, pv := range pvList.Items {
+		if pv.GetAnnotations()[topolvmAnnotation] != topolvmValue || pv.Spec.ClaimRef == nil {
+			continue
+		}
+		if !slices.Contains(namespaces, pv.Spec.ClaimRef.Namespace) {
+			namespaces = append(namespaces, pv.Spec.ClaimRef.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// backupIncludesClaims returns true if the backup includes the PersistentVolumeClaims of any of the namespaces
+func backupIncludesClaims(backup *velerov1.Backup, namespaces []string) bool {
+	resources := backup.Spec.IncludedNamespaceScopedResources
+	if len(backup.Spec.IncludedResources) > 0 {
+		resources = backup.Spec.IncludedResources
+	}
+	if len(resources) > 0 && !slices.Contains(resources, allResources) && !slices.Contains(resources, pvcResource) {
+		return false
+	}
+	if slices.Contains(backup.Spec.ExcludedResources, pvcResource) || slices.Contains(backup.Spec.ExcludedNamespaceScopedResources, pvcResource) {
+		return false
+	}
+
+	for _, ns := range namespaces {
+		if slices.Contains(backup.Spec.ExcludedNamespaces, ns) {
+			continue
+		}
+		if len(backup.Spec.IncludedNamespaces) == 0 || slices.Contains(backup.Spec.IncludedNamespaces, allResources) ||
+			slices.Contains(backup.Spec.IncludedNamespaces, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+// setPersistentVolumeBackup completes the backup for the policy, the fields set in the Backup CR being kept
+func setPersistentVolumeBackup(backup *velerov1.Backup, policy ibuv1.PersistentVolumesPolicy) {
+	switch policy {
+	case ibuv1.PersistentVolumesRetain:
+		// The resource filters of a backup are either the old-style or the scoped ones, which can not be mixed
+		if len(backup.Spec.IncludedResources) > 0 || len(backup.Spec.ExcludedResources) > 0 || backup.Spec.IncludeClusterResources != nil {
+			if len(backup.Spec.IncludedResources) > 0 {
+				backup.Spec.IncludedResources = appendMissingResources(backup.Spec.IncludedResources, pvResource, logicalVolumeResource)
+			}
+		} else {
+			backup.Spec.IncludedClusterScopedResources = appendMissingResources(backup.Spec.IncludedClusterScopedResources, pvResource, logicalVolumeResource)
+		}
+	case ibuv1.PersistentVolumesDataMover:
+		enabled := true
+		if backup.Spec.SnapshotVolumes == nil {
+			backup.Spec.SnapshotVolumes = &enabled
+		}
+		if backup.Spec.SnapshotMoveData == nil {
+			backup.Spec.SnapshotMoveData = &enabled
+		}
+	}
+
+	if backup.Annotations == nil {
+		backup.Annotations = map[string]string{}
+	}
+	backup.Annotations[PersistentVolumesAnn] = string(policy)
+}
+
+func appendMissingResources(resources []string, missing ...string) []string {
+	if slices.Contains(resources, allResources) {
+		return resources
+	}
+	for _, resource := range missing {
+		if !slices.Contains(resources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// SetPersistentVolumeRestores completes the Restore CRs so that the persistent volumes carried over the upgrade as
+// defined by the policy are restored. The Restore CRs are left as-is if no policy is defined, and the PersistentVolumes
+// of a Restore CR explicitly not restoring them are left alone.
+func SetPersistentVolumeRestores(restoreGroups [][]*velerov1.Restore, policy ibuv1.PersistentVolumesPolicy) {
+	if policy == "" {
+		return
+	}
+
+	for _, restores := range restoreGroups {
+		for _, restore := range restores {
+			if restore.Spec.RestorePVs != nil && !*restore.Spec.RestorePVs {
+				continue
+			}
+			enabled := true
+			restore.Spec.RestorePVs = &enabled
+
+			if policy != ibuv1.PersistentVolumesRetain {
+				continue
+			}
+			// The status of the LogicalVolumes holds the ID of the logical volume on the disk
+			if restore.Spec.RestoreStatus == nil {
+				restore.Spec.RestoreStatus = &velerov1.RestoreStatusSpec{}
+			}
+			restore.Spec.RestoreStatus.IncludedResources = appendMissingResources(restore.Spec.RestoreStatus.IncludedResources, logicalVolumeStatus)
+		}
+	}
+}
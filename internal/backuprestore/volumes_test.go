@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backuprestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+)
+
+func fakeLvmsPV(name, claimNamespace string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{topolvmAnnotation: topolvmValue}},
+		Spec: corev1.PersistentVolumeSpec{
+			ClaimRef: &corev1.ObjectReference{Name: name, Namespace: claimNamespace},
+		},
+	}
+}
+
+func TestSetPersistentVolumeBackups(t *testing.T) {
+	localPV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "local"},
+		Spec:       corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Name: "local", Namespace: "cache"}},
+	}
+	newBackups := func() [][]*velerov1.Backup {
+		enabled := true
+		return [][]*velerov1.Backup{
+			{{
+				// Platform backup without claims
+				ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+				Spec: velerov1.BackupSpec{
+					IncludedNamespaces:               []string{"openshift-adp"},
+					IncludedNamespaceScopedResources: []string{"configmaps"},
+				},
+			}},
+			{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "db"},
+					Spec: velerov1.BackupSpec{
+						IncludedNamespaces:               []string{"db"},
+						IncludedNamespaceScopedResources: []string{"persistentvolumeclaims", "statefulsets"},
+						IncludedClusterScopedResources:   []string{"persistentvolumes"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "legacy", Annotations: map[string]string{OptionalAnn: "true"}},
+					Spec: velerov1.BackupSpec{
+						IncludedNamespaces: []string{"*"},
+						ExcludedNamespaces: []string{"cache"},
+						IncludedResources:  []string{"persistentvolumeclaims", "deployments"},
+						SnapshotVolumes:    &enabled,
+					},
+				},
+				{
+					// The LVMS claims are in another namespace
+					ObjectMeta: metav1.ObjectMeta{Name: "cache"},
+					Spec:       velerov1.BackupSpec{IncludedNamespaces: []string{"cache"}},
+				},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(fakeLvmsPV("data", "db"), localPV).Build()
+	h := &BRHandler{Client: c, Log: ctrl.Log.WithName("BackupRestore")}
+
+	backups := newBackups()
+	assert.NoError(t, h.SetPersistentVolumeBackups(context.Background(), backups, ""))
+	assert.Equal(t, newBackups(), backups)
+
+	assert.NoError(t, h.SetPersistentVolumeBackups(context.Background(), backups, ibuv1.PersistentVolumesRetain))
+	assert.Equal(t, newBackups()[0], backups[0])
+	assert.Equal(t, []string{"persistentvolumes", "logicalvolumes.topolvm.io"}, backups[1][0].Spec.IncludedClusterScopedResources)
+	assert.Equal(t, "Retain", backups[1][0].Annotations[PersistentVolumesAnn])
+	assert.Equal(t, []string{"persistentvolumeclaims", "deployments", "persistentvolumes", "logicalvolumes.topolvm.io"},
+		backups[1][1].Spec.IncludedResources)
+	assert.Empty(t, backups[1][1].Spec.IncludedClusterScopedResources)
+	assert.Equal(t, newBackups()[1][2], backups[1][2])
+
+	backups = newBackups()
+	assert.NoError(t, h.SetPersistentVolumeBackups(context.Background(), backups, ibuv1.PersistentVolumesDataMover))
+	assert.True(t, *backups[1][0].Spec.SnapshotVolumes)
+	assert.True(t, *backups[1][0].Spec.SnapshotMoveData)
+	assert.Equal(t, []string{"persistentvolumes"}, backups[1][0].Spec.IncludedClusterScopedResources)
+	assert.True(t, *backups[1][1].Spec.SnapshotMoveData)
+	assert.Equal(t, "DataMover", backups[1][1].Annotations[PersistentVolumesAnn])
+	assert.Nil(t, backups[1][2].Spec.SnapshotMoveData)
+
+	// Without LVMS volumes, the backups are left as-is
+	c = fake.NewClientBuilder().WithScheme(testscheme).WithObjects(localPV).Build()
+	h = &BRHandler{Client: c, Log: ctrl.Log.WithName("BackupRestore")}
+	backups = newBackups()
+	assert.NoError(t, h.SetPersistentVolumeBackups(context.Background(), backups, ibuv1.PersistentVolumesRetain))
+	assert.Equal(t, newBackups(), backups)
+}
+
+func TestSetPersistentVolumeRestores(t *testing.T) {
+	disabled := false
+	newRestores := func() [][]*velerov1.Restore {
+		return [][]*velerov1.Restore{
+			{{ObjectMeta: metav1.ObjectMeta{Name: "platform"}, Spec: velerov1.RestoreSpec{RestorePVs: &disabled}}},
+			{{
+				ObjectMeta: metav1.ObjectMeta{Name: "db"},
+				Spec: velerov1.RestoreSpec{
+					BackupName:    "db",
+					RestoreStatus: &velerov1.RestoreStatusSpec{IncludedResources: []string{"statefulsets"}},
+				},
+			}},
+		}
+	}
+
+	restores := newRestores()
+	SetPersistentVolumeRestores(restores, "")
+	assert.Equal(t, newRestores(), restores)
+
+	SetPersistentVolumeRestores(restores, ibuv1.PersistentVolumesRetain)
+	assert.Equal(t, newRestores()[0], restores[0])
+	assert.True(t, *restores[1][0].Spec.RestorePVs)
+	assert.Equal(t, []string{"statefulsets", "logicalvolumes"}, restores[1][0].Spec.RestoreStatus.IncludedResources)
+
+	restores = newRestores()
+	SetPersistentVolumeRestores(restores, ibuv1.PersistentVolumesDataMover)
+	assert.True(t, *restores[1][0].Spec.RestorePVs)
+	assert.Equal(t, []string{"statefulsets"}, restores[1][0].Spec.RestoreStatus.IncludedResources)
+}
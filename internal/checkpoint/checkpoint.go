@@ -0,0 +1,264 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// States of the steps
+const (
+	StateStarted   = "Started"
+	StateCompleted = "Completed"
+)
+
+// Step is the checkpoint of a step of a stage
+type Step struct {
+	Name  string      `json:"name"`
+	State string      `json:"state"`
+	Time  metav1.Time `json:"time"`
+}
+
+// Checkpoints are the steps of the stage in progress, in the order they were started
+type Checkpoints struct {
+	Stage     string `json:"stage"`
+	Stateroot string `json:"stateroot"`
+	Steps     []Step `json:"steps,omitempty"`
+}
+
+// Store persists the checkpoints of the steps of the Prep and Upgrade stages in a file on the host, so that the stage
+// resumes after the last completed step when the node reboots or the agent restarts mid-stage. The checkpoints only
+// apply to the stage and stateroot they were recorded for, and are discarded once another stage or stateroot is
+// tracked. All methods are no-ops on a nil Store, the steps being always run.
+type Store struct {
+	file string
+	log  logr.Logger
+	mu   sync.Mutex
+	// seen are the stages and stateroots whose checkpoints were already resumed or recorded by this process
+	seen map[string]bool
+	// now is a var in order to override it in unit tests
+	now func() time.Time
+}
+
+// NewStore returns a Store persisting the checkpoints in the file
+func NewStore(file string, log logr.Logger) *Store {
+	return &Store{file: file, log: log, seen: make(map[string]bool), now: time.Now}
+}
+
+// Tracker runs the steps of a stage targeting a stateroot
+type Tracker struct {
+	store     *Store
+	stage     string
+	stateroot string
+}
+
+// For returns the Tracker of the steps of the stage targeting the stateroot
+func (s *Store) For(stage, stateroot string) *Tracker {
+	return &Tracker{store: s, stage: stage, stateroot: stateroot}
+}
+
+// Clear removes the checkpoints
+func (s *Store) Clear() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoints %s: %w", s.file, err)
+	}
+	clear(s.seen)
+	return nil
+}
+
+// Run runs the step unless it was completed by a previous attempt of the stage
+func (t *Tracker) Run(step string, do func() error) error {
+	return t.RunWithUnwind(step, do, nil)
+}
+
+// RunWithUnwind runs the step unless it was completed by a previous attempt of the stage. A step started by a
+// previous attempt but never completed, i.e. interrupted or failed, is unwound first with unwind, if any, as it is
+// not safe to run again over its partial changes.
+func (t *Tracker) RunWithUnwind(step string, do, unwind func() error) error {
+	if t.store == nil {
+		return do()
+	}
+
+	checkpoints, err := t.load()
+	if err != nil {
+		return err
+	}
+	if previous := checkpoints.find(step); previous != nil {
+		switch previous.State {
+		case StateCompleted:
+			t.store.log.Info("Step already completed, skipping", "stage", t.stage, "step", step, "completed", previous.Time)
+			return nil
+		case StateStarted:
+			if unwind != nil {
+				t.store.log.Info("Unwinding the interrupted step", "stage", t.stage, "step", step, "started", previous.Time)
+				if err := unwind(); err != nil {
+					return fmt.Errorf("failed to unwind the interrupted %s step: %w", step, err)
+				}
+			}
+		}
+	}
+
+	if err := t.record(checkpoints, step, StateStarted); err != nil {
+		return err
+	}
+	if err := do(); err != nil {
+		return err
+	}
+	return t.record(checkpoints, step, StateCompleted)
+}
+
+// Completed returns whether the step was completed by a previous attempt of the stage
+func (t *Tracker) Completed(step string) bool {
+	if t.store == nil {
+		return false
+	}
+	checkpoints, err := t.load()
+	if err != nil {
+		t.store.log.Error(err, "failed to load the checkpoints")
+		return false
+	}
+	previous := checkpoints.find(step)
+	return previous != nil && previous.State == StateCompleted
+}
+
+// LastCompleted returns the last step completed by a previous attempt of the stage, or an empty string if none
+func (t *Tracker) LastCompleted() string {
+	if t.store == nil {
+		return ""
+	}
+	checkpoints, err := t.load()
+	if err != nil {
+		t.store.log.Error(err, "failed to load the checkpoints")
+		return ""
+	}
+	for i := len(checkpoints.Steps) - 1; i >= 0; i-- {
+		if checkpoints.Steps[i].State == StateCompleted {
+			return checkpoints.Steps[i].Name
+		}
+	}
+	return ""
+}
+
+// Resumed returns the last step completed by a previous process, i.e. before LCA restarted or the node rebooted, or an
+// empty string if none. It is only returned the first time, and not once this process recorded a step of the stage.
+func (t *Tracker) Resumed() string {
+	if t.store == nil {
+		return ""
+	}
+	t.store.mu.Lock()
+	seen := t.store.seen[t.key()]
+	t.store.seen[t.key()] = true
+	t.store.mu.Unlock()
+	if seen {
+		return ""
+	}
+	return t.LastCompleted()
+}
+
+func (t *Tracker) key() string {
+	return t.stage + "/" + t.stateroot
+}
+
+// load returns the checkpoints of the stage and stateroot, empty if the file records another stage or stateroot
+func (t *Tracker) load() (*Checkpoints, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	checkpoints := &Checkpoints{}
+	content, err := os.ReadFile(t.store.file)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read checkpoints %s: %w", t.store.file, err)
+	default:
+		if err := json.Unmarshal(content, checkpoints); err != nil {
+			// A checkpoint file torn by a crash is discarded, the steps being run again
+			t.store.log.Error(err, "discarding the invalid checkpoints", "file", t.store.file)
+			checkpoints = &Checkpoints{}
+		}
+	}
+	if checkpoints.Stage != t.stage || checkpoints.Stateroot != t.stateroot {
+		checkpoints = &Checkpoints{Stage: t.stage, Stateroot: t.stateroot}
+	}
+	return checkpoints, nil
+}
+
+// record sets the state of the step and persists the checkpoints, replacing the file so that it is never torn
+func (t *Tracker) record(checkpoints *Checkpoints, step, state string) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	t.store.seen[t.key()] = true
+
+	now := metav1.NewTime(t.store.now().UTC().Truncate(time.Second))
+	if previous := checkpoints.find(step); previous != nil {
+		previous.State, previous.Time = state, now
+	} else {
+		checkpoints.Steps = append(checkpoints.Steps, Step{Name: step, State: state, Time: now})
+	}
+
+	content, err := json.Marshal(checkpoints)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoints: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.store.file), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for checkpoints %s: %w", t.store.file, err)
+	}
+	tmp := t.store.file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoints %s: %w", tmp, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write checkpoints %s: %w", tmp, err)
+	}
+	// The checkpoint must be on disk before the step goes on, as the node may be reset at any time
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync checkpoints %s: %w", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoints %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, t.store.file); err != nil {
+		return fmt.Errorf("failed to replace checkpoints %s: %w", t.store.file, err)
+	}
+	return nil
+}
+
+func (c *Checkpoints) find(step string) *Step {
+	for i := range c.Steps {
+		if c.Steps[i].Name == step {
+			return &c.Steps[i]
+		}
+	}
+	return nil
+}
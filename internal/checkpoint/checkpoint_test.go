@@ -0,0 +1,82 @@
+package checkpoint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lca", "workspace", "checkpoints.json")
+	var ran []string
+	step := func(name string, err error) func() error {
+		return func() error {
+			ran = append(ran, name)
+			return err
+		}
+	}
+
+	// First attempt of the stage, interrupted by a failure of the second step
+	tracker := NewStore(file, logr.Discard()).For("Upgrade", "rhcos_4.16.1")
+	assert.Empty(t, tracker.LastCompleted())
+	assert.Empty(t, tracker.Resumed())
+	assert.NoError(t, tracker.Run("export", step("export", nil)))
+	assert.EqualError(t, tracker.RunWithUnwind("deploy", step("deploy", fmt.Errorf("reset")), step("undeploy", nil)), "reset")
+	assert.Equal(t, []string{"export", "deploy"}, ran)
+	// The steps recorded by this process are not resumed
+	assert.Empty(t, tracker.Resumed())
+
+	// The restarted agent resumes after the completed step, unwinding the interrupted one
+	ran = nil
+	tracker = NewStore(file, logr.Discard()).For("Upgrade", "rhcos_4.16.1")
+	assert.Equal(t, "export", tracker.LastCompleted())
+	assert.Equal(t, "export", tracker.Resumed())
+	assert.Empty(t, tracker.Resumed())
+	assert.True(t, tracker.Completed("export"))
+	assert.False(t, tracker.Completed("deploy"))
+	assert.NoError(t, tracker.Run("export", step("export", nil)))
+	assert.NoError(t, tracker.RunWithUnwind("deploy", step("deploy", nil), step("undeploy", nil)))
+	assert.NoError(t, tracker.Run("reboot", step("reboot", nil)))
+	assert.Equal(t, []string{"undeploy", "deploy", "reboot"}, ran)
+	assert.Equal(t, "reboot", tracker.LastCompleted())
+
+	// A failed unwind does not run the step again
+	ran = nil
+	tracker = NewStore(file, logr.Discard()).For("Upgrade", "rhcos_4.16.1")
+	assert.Error(t, tracker.Run("verify", step("verify", fmt.Errorf("failed"))))
+	assert.ErrorContains(t, tracker.RunWithUnwind("verify", step("verify", nil), step("unverify", fmt.Errorf("busy"))),
+		"failed to unwind the interrupted verify step: busy")
+	assert.Equal(t, []string{"verify", "unverify"}, ran)
+
+	// The checkpoints of another stage or stateroot do not apply
+	ran = nil
+	assert.Empty(t, NewStore(file, logr.Discard()).For("Prep", "rhcos_4.16.1").LastCompleted())
+	tracker = NewStore(file, logr.Discard()).For("Upgrade", "rhcos_4.16.2")
+	assert.NoError(t, tracker.Run("export", step("export", nil)))
+	assert.Equal(t, []string{"export"}, ran)
+
+	// A torn checkpoint file is discarded
+	assert.NoError(t, os.WriteFile(file, []byte(`{"stage":"Upg`), 0o600))
+	assert.Empty(t, tracker.LastCompleted())
+
+	store := NewStore(file, logr.Discard())
+	assert.NoError(t, store.Clear())
+	assert.NoFileExists(t, file)
+	assert.NoError(t, store.Clear())
+	assert.Empty(t, store.For("Upgrade", "rhcos_4.16.2").Resumed())
+
+	// A nil store always runs the steps
+	ran = nil
+	var nilStore *Store
+	assert.NoError(t, nilStore.For("Upgrade", "rhcos_4.16.1").Run("export", step("export", nil)))
+	assert.NoError(t, nilStore.For("Upgrade", "rhcos_4.16.1").Run("export", step("export", nil)))
+	assert.Empty(t, nilStore.For("Upgrade", "rhcos_4.16.1").LastCompleted())
+	assert.Empty(t, nilStore.For("Upgrade", "rhcos_4.16.1").Resumed())
+	assert.False(t, nilStore.For("Upgrade", "rhcos_4.16.1").Completed("export"))
+	assert.NoError(t, nilStore.Clear())
+	assert.Equal(t, []string{"export", "export"}, ran)
+}
@@ -0,0 +1,56 @@
+package clusterconfig
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+// GetAdditionalCABundles returns the additional CA bundles of the seed reconfiguration, reading them from the
+// ca-bundle.crt key of the configmaps referenced by the IBU
+func GetAdditionalCABundles(ctx context.Context, c client.Client, configmapRefs []ibuv1.ConfigMapRef) ([]seedreconfig.PEM, error) {
+	var bundles []seedreconfig.PEM
+	for _, ref := range configmapRefs {
+		bundle, err := utils.GetConfigMapData(ctx, ref.Name, ref.Namespace, common.CaBundleDataKey, c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle from configmap %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		if err := validateCABundle(bundle); err != nil {
+			return nil, fmt.Errorf("invalid CA bundle in configmap %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		bundles = append(bundles, seedreconfig.PEM(bundle))
+	}
+	return bundles, nil
+}
+
+// validateCABundle checks that the bundle holds at least one PEM certificate, and only certificates
+func validateCABundle(bundle string) error {
+	rest := []byte(strings.TrimSpace(bundle))
+	if len(rest) == 0 {
+		return fmt.Errorf("no certificate found")
+	}
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return fmt.Errorf("invalid PEM block")
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block of type %s", block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		rest = []byte(strings.TrimSpace(string(rest)))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package clusterconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+func TestGetAdditionalCABundles(t *testing.T) {
+	proxyCA := string(newTLSSecret(t, "proxy-ca", "proxy-ca", true).Data[corev1.TLSCertKey])
+	hubCA := string(newTLSSecret(t, "hub-ca", "hub-ca", true).Data[corev1.TLSCertKey])
+	key := string(newTLSSecret(t, "key", "key", true).Data[corev1.TLSPrivateKeyKey])
+	newConfigMap := func(name, bundle string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "site"},
+			Data:       map[string]string{common.CaBundleDataKey: bundle},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(
+		newConfigMap("proxy-ca", proxyCA),
+		newConfigMap("bundle", proxyCA+"\n"+hubCA),
+		newConfigMap("empty", ""),
+		newConfigMap("private-key", proxyCA+key),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "no-key", Namespace: "site"}},
+	).Build()
+
+	tests := []struct {
+		name          string
+		refs          []ibuv1.ConfigMapRef
+		expected      []seedreconfig.PEM
+		expectedError string
+	}{
+		{
+			name: "not defined",
+		},
+		{
+			name:     "certificate and bundle",
+			refs:     []ibuv1.ConfigMapRef{{Name: "proxy-ca", Namespace: "site"}, {Name: "bundle", Namespace: "site"}},
+			expected: []seedreconfig.PEM{seedreconfig.PEM(proxyCA), seedreconfig.PEM(proxyCA + "\n" + hubCA)},
+		},
+		{
+			name:          "missing configmap",
+			refs:          []ibuv1.ConfigMapRef{{Name: "missing", Namespace: "site"}},
+			expectedError: "failed to get CA bundle from configmap site/missing",
+		},
+		{
+			name:          "missing key",
+			refs:          []ibuv1.ConfigMapRef{{Name: "no-key", Namespace: "site"}},
+			expectedError: "failed to get CA bundle from configmap site/no-key",
+		},
+		{
+			name:          "empty bundle",
+			refs:          []ibuv1.ConfigMapRef{{Name: "empty", Namespace: "site"}},
+			expectedError: "invalid CA bundle in configmap site/empty: no certificate found",
+		},
+		{
+			name:          "private key",
+			refs:          []ibuv1.ConfigMapRef{{Name: "private-key", Namespace: "site"}},
+			expectedError: "invalid CA bundle in configmap site/private-key: unexpected PEM block of type EC PRIVATE KEY",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundles, err := GetAdditionalCABundles(context.Background(), c, tt.refs)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, bundles)
+		})
+	}
+}
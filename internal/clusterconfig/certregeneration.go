@@ -0,0 +1,81 @@
+package clusterconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+// getCertificateKeyPair reads the certificate and the private key of the kubernetes.io/tls secret in the lifecycle
+// agent namespace, and returns the parsed leaf certificate along with them
+func getCertificateKeyPair(ctx context.Context, c client.Client, secretRef ibuv1.SecretRef) (
+	*seedreconfig.CertificateKeyPair, *x509.Certificate, error) {
+	certificate, err := utils.GetSecretData(ctx, secretRef.Name, common.LcaNamespace, corev1.TLSCertKey, c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get certificate from secret %s: %w", secretRef.Name, err)
+	}
+	privateKey, err := utils.GetSecretData(ctx, secretRef.Name, common.LcaNamespace, corev1.TLSPrivateKeyKey, c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get private key from secret %s: %w", secretRef.Name, err)
+	}
+
+	keyPair, err := tls.X509KeyPair([]byte(certificate), []byte(privateKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate and private key in secret %s: %w", secretRef.Name, err)
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate from secret %s: %w", secretRef.Name, err)
+	}
+	if leaf.Subject.CommonName == "" {
+		return nil, nil, fmt.Errorf("certificate in secret %s has no subject common name", secretRef.Name)
+	}
+
+	return &seedreconfig.CertificateKeyPair{
+		Certificate: seedreconfig.PEM(certificate),
+		PrivateKey:  seedreconfig.PEM(privateKey),
+	}, leaf, nil
+}
+
+// GetCertificateRegeneration returns the certificate regeneration parameters of the seed reconfiguration, reading the
+// custom CAs and the preserved certificates from the secrets referenced by the IBU
+func GetCertificateRegeneration(ctx context.Context, c client.Client,
+	certificateRegeneration *ibuv1.CertificateRegeneration) (seedreconfig.CertificateRegeneration, error) {
+	regeneration := seedreconfig.CertificateRegeneration{}
+	if certificateRegeneration == nil {
+		return regeneration, nil
+	}
+	regeneration.ValidityDays = certificateRegeneration.ValidityDays
+
+	for _, customCA := range certificateRegeneration.CustomCAs {
+		keyPair, leaf, err := getCertificateKeyPair(ctx, c, customCA.SecretRef)
+		if err != nil {
+			return regeneration, fmt.Errorf("failed to get custom CA for signer %s: %w", customCA.SignerCN, err)
+		}
+		if !leaf.IsCA {
+			return regeneration, fmt.Errorf("certificate in secret %s of the custom CA for signer %s is not a CA",
+				customCA.SecretRef.Name, customCA.SignerCN)
+		}
+		regeneration.CustomCAs = append(regeneration.CustomCAs,
+			seedreconfig.CustomCA{SignerCN: customCA.SignerCN, CertificateKeyPair: *keyPair})
+	}
+
+	for _, secretRef := range certificateRegeneration.PreservedCertificates {
+		keyPair, _, err := getCertificateKeyPair(ctx, c, secretRef)
+		if err != nil {
+			return regeneration, fmt.Errorf("failed to get preserved certificate: %w", err)
+		}
+		regeneration.PreservedCertificates = append(regeneration.PreservedCertificates, *keyPair)
+	}
+
+	return regeneration, nil
+}
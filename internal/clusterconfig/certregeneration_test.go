@@ -0,0 +1,120 @@
+package clusterconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+// newTLSSecret returns a kubernetes.io/tls secret holding a self-signed certificate with the common name
+func newTLSSecret(t *testing.T, name, commonName string, isCA bool) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: common.LcaNamespace},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		},
+	}
+}
+
+func TestGetCertificateRegeneration(t *testing.T) {
+	customCA := newTLSSecret(t, "custom-ca", "custom-lb-signer", true)
+	preserved := newTLSSecret(t, "preserved", "api.example.com", false)
+	mismatched := newTLSSecret(t, "mismatched", "api.example.com", false)
+	mismatched.Data[corev1.TLSPrivateKeyKey] = customCA.Data[corev1.TLSPrivateKeyKey]
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(customCA, preserved, mismatched).Build()
+
+	tests := []struct {
+		name          string
+		spec          *ibuv1.CertificateRegeneration
+		expected      seedreconfig.CertificateRegeneration
+		expectedError string
+	}{
+		{
+			name: "not defined",
+		},
+		{
+			name: "validity, custom CA and preserved certificate",
+			spec: &ibuv1.CertificateRegeneration{
+				ValidityDays: 365,
+				CustomCAs: []ibuv1.CustomCA{
+					{SignerCN: "kube-apiserver-lb-signer", SecretRef: ibuv1.SecretRef{Name: "custom-ca"}},
+				},
+				PreservedCertificates: []ibuv1.SecretRef{{Name: "preserved"}},
+			},
+			expected: seedreconfig.CertificateRegeneration{
+				ValidityDays: 365,
+				CustomCAs: []seedreconfig.CustomCA{{
+					SignerCN: "kube-apiserver-lb-signer",
+					CertificateKeyPair: seedreconfig.CertificateKeyPair{
+						Certificate: seedreconfig.PEM(customCA.Data[corev1.TLSCertKey]),
+						PrivateKey:  seedreconfig.PEM(customCA.Data[corev1.TLSPrivateKeyKey]),
+					},
+				}},
+				PreservedCertificates: []seedreconfig.CertificateKeyPair{{
+					Certificate: seedreconfig.PEM(preserved.Data[corev1.TLSCertKey]),
+					PrivateKey:  seedreconfig.PEM(preserved.Data[corev1.TLSPrivateKeyKey]),
+				}},
+			},
+		},
+		{
+			name: "custom CA not a CA",
+			spec: &ibuv1.CertificateRegeneration{CustomCAs: []ibuv1.CustomCA{
+				{SignerCN: "kube-apiserver-lb-signer", SecretRef: ibuv1.SecretRef{Name: "preserved"}},
+			}},
+			expectedError: "is not a CA",
+		},
+		{
+			name:          "missing secret",
+			spec:          &ibuv1.CertificateRegeneration{PreservedCertificates: []ibuv1.SecretRef{{Name: "missing"}}},
+			expectedError: "failed to get certificate from secret missing",
+		},
+		{
+			name:          "private key not matching the certificate",
+			spec:          &ibuv1.CertificateRegeneration{PreservedCertificates: []ibuv1.SecretRef{{Name: "mismatched"}}},
+			expectedError: "invalid certificate and private key in secret mismatched",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regeneration, err := GetCertificateRegeneration(context.Background(), c, tt.spec)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, regeneration)
+		})
+	}
+}
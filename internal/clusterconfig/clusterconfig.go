@@ -2,9 +2,11 @@ package clusterconfig
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/openshift/api/config/v1"
@@ -16,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/utils"
@@ -39,8 +42,15 @@ const (
 	idmsFileName  = "image-digest-mirror-set.json"
 	icspsFileName = "image-content-source-policy-list.json"
 
+	// mirrorRegistryConfigName is the name of the IDMS and ICSP created from spec.mirrorRegistryConfig
+	mirrorRegistryConfigName = "lca-mirror-registry-config"
+
 	// ssh authorized keys file created by mco from ssh machine configs
 	sshKeyFile = "/home/core/.ssh/authorized_keys.d/ignition"
+
+	// localtimeFile links to the time zone of the node in the zoneinfo database, UTC being used if it is missing
+	localtimeFile = "/etc/localtime"
+	zoneinfoDir   = "zoneinfo/"
 )
 
 var (
@@ -51,7 +61,7 @@ var (
 )
 
 type UpgradeClusterConfigGatherer interface {
-	FetchClusterConfig(ctx context.Context, ostreeVarDir string) error
+	FetchClusterConfig(ctx context.Context, ostreeVarDir string, spec *ibuv1.ImageBasedUpgradeSpec) error
 	FetchLvmConfig(ctx context.Context, ostreeVarDir string) error
 }
 
@@ -63,8 +73,12 @@ type UpgradeClusterConfigGather struct {
 }
 
 // FetchClusterConfig collects the current cluster's configuration and write it as JSON files into
-// given filesystem directory.
-func (r *UpgradeClusterConfigGather) FetchClusterConfig(ctx context.Context, ostreeVarDir string) error {
+// given filesystem directory. The mirror registry configuration of the IBU spec, if any, is added to the one of the
+// cluster, and the node labels, annotations and taints are preserved per its node metadata. The ACM klusterlet of a
+// managed cluster is preserved along with its hub registration. The additional trusted CA bundles are read from their
+// configmaps.
+func (r *UpgradeClusterConfigGather) FetchClusterConfig(ctx context.Context, ostreeVarDir string,
+	spec *ibuv1.ImageBasedUpgradeSpec) error {
 	r.Log.Info("Fetching cluster configuration")
 
 	clusterConfigPath, err := r.configDir(ostreeVarDir)
@@ -73,19 +87,27 @@ func (r *UpgradeClusterConfigGather) FetchClusterConfig(ctx context.Context, ost
 	}
 	manifestsDir := filepath.Join(clusterConfigPath, manifestDir)
 
-	if err := r.fetchIDMS(ctx, manifestsDir); err != nil {
+	mirrorRegistryConfig := spec.MirrorRegistryConfig
+	if mirrorRegistryConfig == nil {
+		mirrorRegistryConfig = &ibuv1.MirrorRegistryConfig{}
+	}
+
+	if err := r.fetchIDMS(ctx, manifestsDir, mirrorRegistryConfig.ImageDigestMirrors); err != nil {
 		return err
 	}
 
-	if err := r.fetchClusterInfo(ctx, clusterConfigPath); err != nil {
+	if err := r.fetchClusterInfo(ctx, clusterConfigPath, spec); err != nil {
 		return err
 	}
-	if err := r.fetchICSPs(ctx, manifestsDir); err != nil {
+	if err := r.fetchICSPs(ctx, manifestsDir, mirrorRegistryConfig.RepositoryDigestMirrors); err != nil {
 		return err
 	}
 	if err := r.fetchNetworkConfig(ostreeVarDir); err != nil {
 		return err
 	}
+	if err := r.fetchKlusterlet(ctx, manifestsDir); err != nil {
+		return err
+	}
 
 	r.Log.Info("Successfully fetched cluster configuration")
 	return nil
@@ -100,6 +122,58 @@ func (r *UpgradeClusterConfigGather) getPullSecret(ctx context.Context) (string,
 	return sd, nil
 }
 
+// getMirrorRegistryPullSecret merges the mirror registry credentials referenced by secretRef into the pull-secret
+func (r *UpgradeClusterConfigGather) getMirrorRegistryPullSecret(ctx context.Context, pullSecret string,
+	secretRef *ibuv1.SecretRef) (string, error) {
+	if secretRef == nil {
+		return pullSecret, nil
+	}
+
+	r.Log.Info("Fetching mirror registry credentials", "secret", secretRef.Name)
+	credentials, err := utils.GetSecretData(ctx, secretRef.Name, common.LcaNamespace, corev1.DockerConfigJsonKey, r.Client)
+	if err != nil {
+		return "", fmt.Errorf("failed to get mirror registry credentials: %w", err)
+	}
+	merged, err := MergePullSecrets(pullSecret, credentials)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge mirror registry credentials from secret %s: %w", secretRef.Name, err)
+	}
+	return merged, nil
+}
+
+// MergePullSecrets adds the registry auths of the additional docker config JSON to the pull-secret, replacing the
+// auths of the registries present in both
+func MergePullSecrets(pullSecret, additional string) (string, error) {
+	type dockerConfig struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+
+	merged := dockerConfig{}
+	if err := json.Unmarshal([]byte(pullSecret), &merged); err != nil {
+		return "", fmt.Errorf("failed to parse pull-secret: %w", err)
+	}
+	extra := dockerConfig{}
+	if err := json.Unmarshal([]byte(additional), &extra); err != nil {
+		return "", fmt.Errorf("failed to parse registry credentials: %w", err)
+	}
+	if len(extra.Auths) == 0 {
+		return "", fmt.Errorf("no registry auths found in registry credentials")
+	}
+
+	if merged.Auths == nil {
+		merged.Auths = map[string]json.RawMessage{}
+	}
+	for registry, auth := range extra.Auths {
+		merged.Auths[registry] = auth
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal pull-secret: %w", err)
+	}
+	return string(data), nil
+}
+
 func (r *UpgradeClusterConfigGather) getSSHPublicKey() (string, error) {
 	sshKey, err := os.ReadFile(filepath.Join(hostPath, sshKeyFile))
 	if err != nil {
@@ -125,6 +199,36 @@ func (r *UpgradeClusterConfigGather) getChronyConfig() (string, error) {
 	return string(chronyConfig), err
 }
 
+// getTimezone returns the time zone of the node from the /etc/localtime link, so that it is kept across the upgrade
+// rather than taken from the seed. An empty time zone, keeping the one of the seed, is returned when /etc/localtime is
+// not a link to the time zone database.
+func (r *UpgradeClusterConfigGather) getTimezone() (string, error) {
+	localtime := filepath.Join(hostPath, localtimeFile)
+	info, err := os.Lstat(localtime)
+	if os.IsNotExist(err) {
+		return "UTC", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat the %s of the node: %w", localtimeFile, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		r.Log.Info("Keeping the time zone of the seed, the localtime of the node is not a link", "file", localtimeFile)
+		return "", nil
+	}
+
+	target, err := os.Readlink(localtime)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the %s link of the node: %w", localtimeFile, err)
+	}
+	_, timezone, found := strings.Cut(target, zoneinfoDir)
+	if !found || timezone == "" {
+		r.Log.Info("Keeping the time zone of the seed, the localtime of the node is not in the time zone database",
+			"file", localtimeFile, "target", target)
+		return "", nil
+	}
+	return timezone, nil
+}
+
 func (r *UpgradeClusterConfigGather) getInfraID(ctx context.Context) (string, error) {
 	infra, err := utils.GetInfrastructure(ctx, r.Client)
 	if err != nil {
@@ -327,10 +431,16 @@ func SeedReconfigurationFromClusterInfo(
 			ProxyConfigmapBundle: additionalTrustBundle.ProxyConfigmapBundle,
 		},
 		NodeLabels: clusterInfo.NodeLabels,
+		ClusterNetworks: lo.Map(clusterInfo.ClusterNetworkEntries, func(entry v1.ClusterNetworkEntry, _ int) seedreconfig.ClusterNetworkEntry {
+			return seedreconfig.ClusterNetworkEntry{CIDR: entry.CIDR, HostPrefix: entry.HostPrefix}
+		}),
+		ServiceNetworks: clusterInfo.ServiceNetworks,
+		WorkerNodes:     clusterInfo.WorkerNodes,
 	}
 }
 
-func (r *UpgradeClusterConfigGather) fetchClusterInfo(ctx context.Context, clusterConfigPath string) error {
+func (r *UpgradeClusterConfigGather) fetchClusterInfo(ctx context.Context, clusterConfigPath string,
+	spec *ibuv1.ImageBasedUpgradeSpec) error {
 	r.Log.Info("Fetching ClusterInfo")
 
 	clusterInfo, err := utils.GetClusterInfo(ctx, r.Client)
@@ -357,6 +467,14 @@ func (r *UpgradeClusterConfigGather) fetchClusterInfo(ctx context.Context, clust
 	if err != nil {
 		return err
 	}
+	var mirrorRegistryCredentials *ibuv1.SecretRef
+	if spec.MirrorRegistryConfig != nil {
+		mirrorRegistryCredentials = spec.MirrorRegistryConfig.CredentialsSecretRef
+	}
+	pullSecret, err = r.getMirrorRegistryPullSecret(ctx, pullSecret, mirrorRegistryCredentials)
+	if err != nil {
+		return err
+	}
 
 	kubeadminPasswordHash, err := r.GetKubeadminPasswordHash(ctx)
 	if err != nil {
@@ -388,6 +506,21 @@ func (r *UpgradeClusterConfigGather) fetchClusterInfo(ctx context.Context, clust
 		return err
 	}
 
+	timezone, err := r.getTimezone()
+	if err != nil {
+		return err
+	}
+
+	regeneration, err := GetCertificateRegeneration(ctx, r.Client, spec.CertificateRegeneration)
+	if err != nil {
+		return fmt.Errorf("failed to get certificate regeneration: %w", err)
+	}
+
+	additionalCABundles, err := GetAdditionalCABundles(ctx, r.Client, spec.AdditionalTrustedCABundles)
+	if err != nil {
+		return fmt.Errorf("failed to get additional trusted CA bundles: %w", err)
+	}
+
 	seedReconfiguration := SeedReconfigurationFromClusterInfo(clusterInfo, seedReconfigurationKubeconfigRetention,
 		sshKey,
 		infraID,
@@ -400,6 +533,10 @@ func (r *UpgradeClusterConfigGather) fetchClusterInfo(ctx context.Context, clust
 		additionalTrustBundle,
 		serverSSHKeys,
 	)
+	setNodeMetadata(seedReconfiguration, clusterInfo, spec.NodeMetadata)
+	seedReconfiguration.Timezone = timezone
+	seedReconfiguration.CertificateRegeneration = regeneration
+	seedReconfiguration.AdditionalCABundles = additionalCABundles
 
 	filePath := filepath.Join(clusterConfigPath, common.SeedReconfigurationFileName)
 	r.Log.Info("Writing ClusterInfo to file", "path", filePath)
@@ -410,13 +547,28 @@ func (r *UpgradeClusterConfigGather) fetchClusterInfo(ctx context.Context, clust
 	return nil
 }
 
-func (r *UpgradeClusterConfigGather) fetchIDMS(ctx context.Context, manifestsDir string) error {
+func (r *UpgradeClusterConfigGather) fetchIDMS(ctx context.Context, manifestsDir string,
+	mirrors []v1.ImageDigestMirrors) error {
 	r.Log.Info("Fetching IDMS")
 	idms, err := r.getIDMSs(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch IDMS")
 	}
 
+	if len(mirrors) > 0 {
+		r.Log.Info("Adding the mirror registry config IDMS", "name", mirrorRegistryConfigName)
+		obj := v1.ImageDigestMirrorSet{
+			ObjectMeta: metav1.ObjectMeta{Name: mirrorRegistryConfigName},
+			Spec:       v1.ImageDigestMirrorSetSpec{ImageDigestMirrors: mirrors},
+		}
+		typeMeta, err := r.typeMetaForObject(&obj)
+		if err != nil {
+			return err
+		}
+		obj.TypeMeta = *typeMeta
+		idms.Items = append(idms.Items, obj)
+	}
+
 	if len(idms.Items) < 1 {
 		r.Log.Info("ImageDigestMirrorSetList is empty, skipping")
 		return nil
@@ -491,7 +643,8 @@ func (r *UpgradeClusterConfigGather) getIDMSs(ctx context.Context) (v1.ImageDige
 	return idmsList, nil
 }
 
-func (r *UpgradeClusterConfigGather) fetchICSPs(ctx context.Context, manifestsDir string) error {
+func (r *UpgradeClusterConfigGather) fetchICSPs(ctx context.Context, manifestsDir string,
+	mirrors []operatorv1alpha1.RepositoryDigestMirrors) error {
 	r.Log.Info("Fetching ICSPs")
 	iscpsList := &operatorv1alpha1.ImageContentSourcePolicyList{}
 	currentIcps := &operatorv1alpha1.ImageContentSourcePolicyList{}
@@ -499,6 +652,14 @@ func (r *UpgradeClusterConfigGather) fetchICSPs(ctx context.Context, manifestsDi
 		return fmt.Errorf("failed list ImageContentSourcePolicy: %w", err)
 	}
 
+	if len(mirrors) > 0 {
+		r.Log.Info("Adding the mirror registry config ICSP", "name", mirrorRegistryConfigName)
+		currentIcps.Items = append(currentIcps.Items, operatorv1alpha1.ImageContentSourcePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: mirrorRegistryConfigName},
+			Spec:       operatorv1alpha1.ImageContentSourcePolicySpec{RepositoryDigestMirrors: mirrors},
+		})
+	}
+
 	if len(currentIcps.Items) < 1 {
 		r.Log.Info("ImageContentPolicyList is empty, skipping")
 		return nil
@@ -46,6 +46,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/utils"
@@ -294,6 +295,10 @@ func TestClusterConfig(t *testing.T) {
 		deleteKubeadmin bool
 		expectedErr     bool
 		validateFunc    func(t *testing.T, tempDir string, err error, ucc UpgradeClusterConfigGather)
+
+		mirrorRegistryConfig *ibuv1.MirrorRegistryConfig
+		mirrorCredentials    client.Object
+		nodeMetadata         *ibuv1.NodeMetadata
 	}{
 		{
 			testCaseName:   "Validate success flow",
@@ -343,6 +348,40 @@ func TestClusterConfig(t *testing.T) {
 				assert.Equal(t, map[string]string{"test": "test", "node-role.kubernetes.io/master": ""}, seedReconfig.NodeLabels)
 			},
 		},
+		{
+			testCaseName:   "Validate node metadata preservation",
+			pullSecret:     defaultPullSecret,
+			clusterVersion: defaultClusterVersion,
+			idms:           defaultIDMS,
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"node-role.kubernetes.io/master": "", "test": "test", "excluded": "test"},
+					Annotations: map[string]string{
+						"machineconfiguration.openshift.io/currentConfig": "rendered-master-1",
+						"example.com/owner": "team-a",
+					},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+					{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "example.com/dedicated", Value: "ran", Effect: corev1.TaintEffectNoSchedule},
+				}},
+				Status: validMasterNode.Status,
+			},
+			proxy:        defaultProxy,
+			chronyConfig: "chrony config",
+			nodeMetadata: &ibuv1.NodeMetadata{Exclude: []string{"excluded"}},
+			validateFunc: func(t *testing.T, tempDir string, err error, ucc UpgradeClusterConfigGather) {
+				seedReconfig, err := getSeedReconfigFromUcc(ucc, tempDir)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				assert.Equal(t, map[string]string{"test": "test", "node-role.kubernetes.io/master": ""}, seedReconfig.NodeLabels)
+				assert.Equal(t, map[string]string{"example.com/owner": "team-a"}, seedReconfig.NodeAnnotations)
+				assert.Equal(t, []seedreconfig.NodeTaint{{Key: "example.com/dedicated", Value: "ran", Effect: "NoSchedule"}},
+					seedReconfig.NodeTaints)
+			},
+		},
 		{
 			testCaseName:   "Validate success flow without chrony",
 			pullSecret:     defaultPullSecret,
@@ -529,6 +568,74 @@ func TestClusterConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			testCaseName: "Validate mirror registry config",
+			pullSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: pullSecretName, Namespace: common.OpenshiftConfigNamespace},
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+					`{"auths":{"quay.io":{"auth":"cXVheQ=="},"mirror.local:5000":{"auth":"b2xk"}}}`)},
+			},
+			clusterVersion: defaultClusterVersion,
+			idms:           defaultIDMS,
+			node:           validMasterNode,
+			proxy:          defaultProxy,
+			mirrorRegistryConfig: &ibuv1.MirrorRegistryConfig{
+				ImageDigestMirrors:      []ocpV1.ImageDigestMirrors{{Source: "quay.io/openshift-release-dev", Mirrors: []ocpV1.ImageMirror{"mirror.local:5000/ocp"}}},
+				RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{{Source: "registry.redhat.io", Mirrors: []string{"mirror.local:5000/redhat"}}},
+				CredentialsSecretRef:    &ibuv1.SecretRef{Name: "mirror-credentials"},
+			},
+			mirrorCredentials: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "mirror-credentials", Namespace: common.LcaNamespace},
+				Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"mirror.local:5000":{"auth":"bmV3"}}}`)},
+			},
+			expectedErr: false,
+			validateFunc: func(t *testing.T, tempDir string, err error, ucc UpgradeClusterConfigGather) {
+				clusterConfigPath, err := ucc.configDir(tempDir)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				manifestsDir := filepath.Join(clusterConfigPath, manifestDir)
+
+				idms := &ocpV1.ImageDigestMirrorSetList{}
+				if err := utils.ReadYamlOrJSONFile(filepath.Join(manifestsDir, idmsFileName), idms); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if assert.Equal(t, 2, len(idms.Items)) {
+					assert.Equal(t, "any", idms.Items[0].Name)
+					assert.Equal(t, mirrorRegistryConfigName, idms.Items[1].Name)
+					assert.Equal(t, "quay.io/openshift-release-dev", idms.Items[1].Spec.ImageDigestMirrors[0].Source)
+				}
+
+				icsps := &operatorv1alpha1.ImageContentSourcePolicyList{}
+				if err := utils.ReadYamlOrJSONFile(filepath.Join(manifestsDir, icspsFileName), icsps); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if assert.Equal(t, 1, len(icsps.Items)) {
+					assert.Equal(t, mirrorRegistryConfigName, icsps.Items[0].Name)
+					assert.Equal(t, "registry.redhat.io", icsps.Items[0].Spec.RepositoryDigestMirrors[0].Source)
+				}
+
+				seedReconfig, err := getSeedReconfigFromUcc(ucc, tempDir)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				assert.JSONEq(t, `{"auths":{"quay.io":{"auth":"cXVheQ=="},"mirror.local:5000":{"auth":"bmV3"}}}`, seedReconfig.PullSecret)
+			},
+		},
+		{
+			testCaseName:   "mirror registry credentials not found, should fail",
+			pullSecret:     defaultPullSecret,
+			clusterVersion: defaultClusterVersion,
+			node:           validMasterNode,
+			proxy:          defaultProxy,
+			mirrorRegistryConfig: &ibuv1.MirrorRegistryConfig{
+				CredentialsSecretRef: &ibuv1.SecretRef{Name: "mirror-credentials"},
+			},
+			expectedErr: true,
+			validateFunc: func(t *testing.T, tempDir string, err error, ucc UpgradeClusterConfigGather) {
+				assert.Equal(t, true, strings.Contains(err.Error(), "mirror registry credentials"))
+			},
+		},
 	}
 
 	for _, testCase := range testcases {
@@ -569,6 +676,10 @@ func TestClusterConfig(t *testing.T) {
 				k8sResources = append(k8sResources, kcro)
 			}
 
+			if testCase.mirrorCredentials != nil {
+				k8sResources = append(k8sResources, testCase.mirrorCredentials)
+			}
+
 			if testCase.icsps != nil {
 				for _, icsp := range testCase.icsps {
 					k8sResources = append(k8sResources, icsp)
@@ -596,7 +707,8 @@ func TestClusterConfig(t *testing.T) {
 				Log:    logr.Discard(),
 				Scheme: fakeK8sClient.Scheme(),
 			}
-			err = ucc.FetchClusterConfig(context.TODO(), clusterConfigDir)
+			err = ucc.FetchClusterConfig(context.TODO(), clusterConfigDir, &ibuv1.ImageBasedUpgradeSpec{
+				MirrorRegistryConfig: testCase.mirrorRegistryConfig, NodeMetadata: testCase.nodeMetadata})
 			if !testCase.expectedErr && err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
@@ -748,3 +860,77 @@ func genSelfSignedKeyPair(cert *x509.Certificate) (*pem.Block, *ecdsa.PrivateKey
 
 	return &pem.Block{Type: "CERTIFICATE", Bytes: signedCert}, key, err
 }
+
+func TestMergePullSecrets(t *testing.T) {
+	testcases := []struct {
+		name        string
+		pullSecret  string
+		additional  string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:       "adds and replaces registry auths",
+			pullSecret: `{"auths":{"quay.io":{"auth":"cXVheQ=="},"mirror.local:5000":{"auth":"b2xk"}}}`,
+			additional: `{"auths":{"mirror.local:5000":{"auth":"bmV3"},"mirror.local:5001":{"auth":"b3RoZXI="}}}`,
+			expected:   `{"auths":{"quay.io":{"auth":"cXVheQ=="},"mirror.local:5000":{"auth":"bmV3"},"mirror.local:5001":{"auth":"b3RoZXI="}}}`,
+		},
+		{
+			name:       "pull-secret without auths",
+			pullSecret: `{}`,
+			additional: `{"auths":{"mirror.local:5000":{"auth":"bmV3"}}}`,
+			expected:   `{"auths":{"mirror.local:5000":{"auth":"bmV3"}}}`,
+		},
+		{
+			name:        "invalid registry credentials",
+			pullSecret:  `{"auths":{}}`,
+			additional:  "mirror-credentials",
+			expectedErr: true,
+		},
+		{
+			name:        "registry credentials without auths",
+			pullSecret:  `{"auths":{}}`,
+			additional:  `{"auths":{}}`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := MergePullSecrets(tc.pullSecret, tc.additional)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, merged)
+		})
+	}
+}
+
+func TestGetTimezone(t *testing.T) {
+	hostPath = t.TempDir()
+	ucc := UpgradeClusterConfigGather{Log: logr.Discard()}
+
+	timezone, err := ucc.getTimezone()
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", timezone)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(hostPath, "etc"), 0o700))
+	assert.NoError(t, os.Symlink("../usr/share/zoneinfo/America/New_York", filepath.Join(hostPath, localtimeFile)))
+	timezone, err = ucc.getTimezone()
+	assert.NoError(t, err)
+	assert.Equal(t, "America/New_York", timezone)
+
+	assert.NoError(t, os.Remove(filepath.Join(hostPath, localtimeFile)))
+	assert.NoError(t, os.Symlink("/etc/custom-localtime", filepath.Join(hostPath, localtimeFile)))
+	timezone, err = ucc.getTimezone()
+	assert.NoError(t, err)
+	assert.Empty(t, timezone)
+
+	// A regular localtime file keeps the time zone of the seed
+	assert.NoError(t, os.Remove(filepath.Join(hostPath, localtimeFile)))
+	assert.NoError(t, os.WriteFile(filepath.Join(hostPath, localtimeFile), []byte("TZif2"), 0o600))
+	timezone, err = ucc.getTimezone()
+	assert.NoError(t, err)
+	assert.Empty(t, timezone)
+}
@@ -0,0 +1,149 @@
+package clusterconfig
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+// +kubebuilder:rbac:groups=operator.open-cluster-management.io,resources=klusterlets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+
+const (
+	klusterletFileName = "klusterlet.json"
+
+	// serviceAccountNameAnnotation marks the secrets generated for a service account, which are not preserved
+	serviceAccountNameAnnotation = "kubernetes.io/service-account.name"
+)
+
+// klusterletObject is a resource of the ACM klusterlet, exported in the order it is applied in
+type klusterletObject struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+var (
+	klusterletGVK = schema.GroupVersionKind{Group: "operator.open-cluster-management.io", Version: "v1", Kind: "Klusterlet"}
+
+	// klusterletObjects are the resources of the klusterlet operator applied before its secrets
+	klusterletObjects = []klusterletObject{
+		{gvk: schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"},
+			name: common.KlusterletCRDName},
+		{gvk: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, name: common.KlusterletNamespace},
+		{gvk: schema.GroupVersionKind{Group: "scheduling.k8s.io", Version: "v1", Kind: "PriorityClass"},
+			name: "klusterlet-critical"},
+		{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+			name: "klusterlet"},
+		{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+			name: "open-cluster-management:klusterlet-admin-aggregate-clusterrole"},
+		{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+			name: "klusterlet"},
+		{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"},
+			namespace: common.KlusterletNamespace, name: "klusterlet"},
+	}
+
+	// klusterletOperatorObjects are the klusterlet operator and its Klusterlet CR, applied once its secrets are in place
+	klusterletOperatorObjects = []klusterletObject{
+		{gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			namespace: common.KlusterletNamespace, name: "klusterlet"},
+		{gvk: klusterletGVK, name: common.KlusterletName},
+	}
+)
+
+// fetchKlusterlet exports the ACM klusterlet, its registration secrets included, for the cluster to stay registered
+// with the hub without relying on the OADP backup of the klusterlet. Nothing is exported if the cluster is not managed.
+func (r *UpgradeClusterConfigGather) fetchKlusterlet(ctx context.Context, manifestsDir string) error {
+	klusterlet := &unstructured.Unstructured{}
+	klusterlet.SetGroupVersionKind(klusterletGVK)
+	if err := r.Get(ctx, types.NamespacedName{Name: common.KlusterletName}, klusterlet); err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			r.Log.Info("Klusterlet is not found, the cluster is not managed. Skipping")
+			return nil
+		}
+		return fmt.Errorf("failed to get the klusterlet: %w", err)
+	}
+
+	r.Log.Info("Fetching the klusterlet and its hub registration")
+	var items []any
+	for _, obj := range klusterletObjects {
+		item, err := r.getKlusterletObject(ctx, obj)
+		if err != nil {
+			return err
+		}
+		if item != nil {
+			items = append(items, item.Object)
+		}
+	}
+
+	secrets := &unstructured.UnstructuredList{}
+	secrets.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "SecretList"})
+	if err := r.List(ctx, secrets, client.InNamespace(common.KlusterletNamespace)); err != nil {
+		return fmt.Errorf("failed to list the klusterlet secrets: %w", err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if _, generated := secret.GetAnnotations()[serviceAccountNameAnnotation]; generated {
+			continue
+		}
+		cleanupExportedObject(secret)
+		items = append(items, secret.Object)
+	}
+
+	for _, obj := range klusterletOperatorObjects {
+		item, err := r.getKlusterletObject(ctx, obj)
+		if err != nil {
+			return err
+		}
+		if item != nil {
+			items = append(items, item.Object)
+		}
+	}
+
+	filePath := filepath.Join(manifestsDir, klusterletFileName)
+	r.Log.Info("Writing the klusterlet to file", "path", filePath, "objects", len(items))
+	list := map[string]any{"apiVersion": "v1", "kind": "List", "items": items}
+	if err := utils.MarshalToFile(list, filePath); err != nil {
+		return fmt.Errorf("failed to write the klusterlet to %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// getKlusterletObject returns the klusterlet resource ready to be applied, or nil if it does not exist, e.g. the
+// PriorityClass with older ACM versions
+func (r *UpgradeClusterConfigGather) getKlusterletObject(ctx context.Context, obj klusterletObject) (*unstructured.Unstructured, error) {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(obj.gvk)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: obj.namespace, Name: obj.name}, u); err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			r.Log.Info("Klusterlet resource not found. Skipping", "kind", obj.gvk.Kind, "name", obj.name)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s %s: %w", obj.gvk.Kind, obj.name, err)
+	}
+	cleanupExportedObject(u)
+	return u, nil
+}
+
+// cleanupExportedObject removes the fields set by the API server, for the object to be created anew
+func cleanupExportedObject(u *unstructured.Unstructured) {
+	u.SetUID("")
+	u.SetResourceVersion("")
+	u.SetGeneration(0)
+	u.SetManagedFields(nil)
+	u.SetOwnerReferences(nil)
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "status")
+}
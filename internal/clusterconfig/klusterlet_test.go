@@ -0,0 +1,74 @@
+package clusterconfig
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+func TestFetchKlusterlet(t *testing.T) {
+	klusterlet := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "operator.open-cluster-management.io/v1",
+		"kind":       "Klusterlet",
+		"metadata":   map[string]any{"name": common.KlusterletName},
+		"spec":       map[string]any{"clusterName": "spoke"},
+		"status":     map[string]any{"conditions": []any{}},
+	}}
+	objs := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: common.KlusterletNamespace}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "klusterlet"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "klusterlet", Namespace: common.KlusterletNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bootstrap-hub-kubeconfig", Namespace: common.KlusterletNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "hub-kubeconfig-secret", Namespace: common.KlusterletNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "klusterlet-token", Namespace: common.KlusterletNamespace,
+			Annotations: map[string]string{serviceAccountNameAnnotation: "klusterlet"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "klusterlet", Namespace: common.KlusterletNamespace}},
+	}
+
+	t.Run("unmanaged cluster", func(t *testing.T) {
+		manifestsDir := t.TempDir()
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(objs...).Build()
+		ucc := &UpgradeClusterConfigGather{Client: c, Log: logr.Discard(), Scheme: c.Scheme()}
+		assert.NoError(t, ucc.fetchKlusterlet(context.Background(), manifestsDir))
+		assert.NoFileExists(t, filepath.Join(manifestsDir, klusterletFileName))
+	})
+
+	t.Run("managed cluster", func(t *testing.T) {
+		manifestsDir := t.TempDir()
+		c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(append(objs, klusterlet)...).Build()
+		ucc := &UpgradeClusterConfigGather{Client: c, Log: logr.Discard(), Scheme: c.Scheme()}
+		assert.NoError(t, ucc.fetchKlusterlet(context.Background(), manifestsDir))
+
+		list := &unstructured.UnstructuredList{}
+		assert.NoError(t, utils.ReadYamlOrJSONFile(filepath.Join(manifestsDir, klusterletFileName), list))
+		var exported []string
+		for _, item := range list.Items {
+			exported = append(exported, item.GetKind()+"/"+item.GetName())
+			assert.Empty(t, item.GetResourceVersion())
+			_, hasStatus := item.Object["status"]
+			assert.False(t, hasStatus)
+		}
+		assert.Equal(t, []string{
+			"Namespace/" + common.KlusterletNamespace,
+			"ClusterRole/klusterlet",
+			"ServiceAccount/klusterlet",
+			"Secret/bootstrap-hub-kubeconfig",
+			"Secret/hub-kubeconfig-secret",
+			"Deployment/klusterlet",
+			"Klusterlet/" + common.KlusterletName,
+		}, exported)
+	})
+}
@@ -12,6 +12,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	v1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,17 +42,17 @@ func (m *MockUpgradeClusterConfigGatherer) EXPECT() *MockUpgradeClusterConfigGat
 }
 
 // FetchClusterConfig mocks base method.
-func (m *MockUpgradeClusterConfigGatherer) FetchClusterConfig(ctx context.Context, ostreeVarDir string) error {
+func (m *MockUpgradeClusterConfigGatherer) FetchClusterConfig(ctx context.Context, ostreeVarDir string, spec *v1.ImageBasedUpgradeSpec) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchClusterConfig", ctx, ostreeVarDir)
+	ret := m.ctrl.Call(m, "FetchClusterConfig", ctx, ostreeVarDir, spec)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // FetchClusterConfig indicates an expected call of FetchClusterConfig.
-func (mr *MockUpgradeClusterConfigGathererMockRecorder) FetchClusterConfig(ctx, ostreeVarDir any) *gomock.Call {
+func (mr *MockUpgradeClusterConfigGathererMockRecorder) FetchClusterConfig(ctx, ostreeVarDir, spec any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchClusterConfig", reflect.TypeOf((*MockUpgradeClusterConfigGatherer)(nil).FetchClusterConfig), ctx, ostreeVarDir)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchClusterConfig", reflect.TypeOf((*MockUpgradeClusterConfigGatherer)(nil).FetchClusterConfig), ctx, ostreeVarDir, spec)
 }
 
 // FetchLvmConfig mocks base method.
@@ -0,0 +1,88 @@
+package clusterconfig
+
+import (
+	"strings"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
+	"github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+// platformDomains are the domains of the node annotations and taints managed by the platform, which are set again on
+// the node of the new stateroot rather than being preserved across the upgrade
+var platformDomains = []string{"kubernetes.io", "k8s.io", "openshift.io", "k8s.ovn.org"}
+
+// isPlatformKey returns true when the key of a node annotation or taint is in one of the platform domains, or in one
+// of their subdomains
+func isPlatformKey(key string) bool {
+	domain, _, found := strings.Cut(key, "/")
+	if !found {
+		return false
+	}
+	for _, platformDomain := range platformDomains {
+		if domain == platformDomain || strings.HasSuffix(domain, "."+platformDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeMetadataKey returns true when the key matches one of the patterns, a pattern ending with "*" matching the
+// keys starting with the rest of it
+func matchesNodeMetadataKey(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if key == pattern {
+			return true
+		}
+		if prefix, isPrefix := strings.CutSuffix(pattern, "*"); isPrefix && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// preservesNodeMetadataKey returns true when the node label, annotation or taint with the key is preserved per the
+// node metadata of the IBU
+func preservesNodeMetadataKey(key string, nodeMetadata *ibuv1.NodeMetadata) bool {
+	if nodeMetadata == nil {
+		return true
+	}
+	if len(nodeMetadata.Include) > 0 && !matchesNodeMetadataKey(key, nodeMetadata.Include) {
+		return false
+	}
+	return !matchesNodeMetadataKey(key, nodeMetadata.Exclude)
+}
+
+// setNodeMetadata sets the node labels, annotations and taints of the cluster that are preserved across the upgrade
+// in the seed reconfiguration. The default node labels are filtered out by the post-pivot reconfiguration, while the
+// platform annotations and taints are filtered out here.
+func setNodeMetadata(seedReconfiguration *seedreconfig.SeedReconfiguration, clusterInfo *utils.ClusterInfo,
+	nodeMetadata *ibuv1.NodeMetadata) {
+	seedReconfiguration.NodeLabels = nil
+	for key, value := range clusterInfo.NodeLabels {
+		if preservesNodeMetadataKey(key, nodeMetadata) {
+			if seedReconfiguration.NodeLabels == nil {
+				seedReconfiguration.NodeLabels = map[string]string{}
+			}
+			seedReconfiguration.NodeLabels[key] = value
+		}
+	}
+
+	seedReconfiguration.NodeAnnotations = nil
+	for key, value := range clusterInfo.NodeAnnotations {
+		if !isPlatformKey(key) && preservesNodeMetadataKey(key, nodeMetadata) {
+			if seedReconfiguration.NodeAnnotations == nil {
+				seedReconfiguration.NodeAnnotations = map[string]string{}
+			}
+			seedReconfiguration.NodeAnnotations[key] = value
+		}
+	}
+
+	seedReconfiguration.NodeTaints = nil
+	for _, taint := range clusterInfo.NodeTaints {
+		if !isPlatformKey(taint.Key) && preservesNodeMetadataKey(taint.Key, nodeMetadata) {
+			seedReconfiguration.NodeTaints = append(seedReconfiguration.NodeTaints,
+				seedreconfig.NodeTaint{Key: taint.Key, Value: taint.Value, Effect: string(taint.Effect)})
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package clusterconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+)
+
+func TestIsPlatformKey(t *testing.T) {
+	assert.True(t, isPlatformKey("node.kubernetes.io/unschedulable"))
+	assert.True(t, isPlatformKey("machineconfiguration.openshift.io/currentConfig"))
+	assert.True(t, isPlatformKey("k8s.ovn.org/node-subnets"))
+	assert.True(t, isPlatformKey("kubernetes.io/hostname"))
+	assert.False(t, isPlatformKey("example.com/dedicated"))
+	assert.False(t, isPlatformKey("notkubernetes.io/key"))
+	assert.False(t, isPlatformKey("dedicated"))
+}
+
+func TestPreservesNodeMetadataKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodeMetadata *ibuv1.NodeMetadata
+		preserved    []string
+		notPreserved []string
+	}{
+		{
+			name:      "all preserved by default",
+			preserved: []string{"example.com/owner", "dedicated"},
+		},
+		{
+			name:         "include list",
+			nodeMetadata: &ibuv1.NodeMetadata{Include: []string{"example.com/*", "dedicated"}},
+			preserved:    []string{"example.com/owner", "example.com/dedicated", "dedicated"},
+			notPreserved: []string{"other.com/owner", "dedicated-ran"},
+		},
+		{
+			name:         "exclude taking precedence",
+			nodeMetadata: &ibuv1.NodeMetadata{Include: []string{"example.com/*"}, Exclude: []string{"example.com/owner"}},
+			preserved:    []string{"example.com/dedicated"},
+			notPreserved: []string{"example.com/owner", "dedicated"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range tt.preserved {
+				assert.True(t, preservesNodeMetadataKey(key, tt.nodeMetadata), key)
+			}
+			for _, key := range tt.notPreserved {
+				assert.False(t, preservesNodeMetadataKey(key, tt.nodeMetadata), key)
+			}
+		})
+	}
+}
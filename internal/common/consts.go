@@ -65,7 +65,10 @@ const (
 	KubeconfigCryptoDir               = "kubeconfig-crypto"
 	ClusterConfigDir                  = "cluster-configuration"
 	ContainersListFileName            = "containers.list"
+	SeedOstreeDeltaFileName           = "ostree.delta"
+	SeedBaseImageFileName             = "base-seed-image"
 	SeedClusterInfoFileName           = "manifest.json"
+	SeedContentManifestFileName       = "content-manifest.sha256"
 	SeedReconfigurationFileName       = "manifest.json"
 	ManifestsDir                      = "manifests"
 	ExtraManifestsDir                 = "extra-manifests"
@@ -73,6 +76,7 @@ const (
 	LvmConfigDir                      = "lvm-configuration"
 	LvmDevicesPath                    = "/etc/lvm/devices/system.devices"
 	CABundleFilePath                  = "/etc/pki/ca-trust/source/anchors/openshift-config-user-ca-bundle.crt"
+	AdditionalCABundlesFilePath       = "/etc/pki/ca-trust/source/anchors/lca-additional-ca-bundles.crt"
 
 	LCAConfigDir               = "/var/lib/lca"
 	LCAWorkspaceDir            = LCAConfigDir + "/workspace"
@@ -84,12 +88,29 @@ const (
 
 	IBUAutoRollbackConfigFile                       = LCAConfigDir + "/autorollback_config.json"
 	IBUAutoRollbackInitMonitorTimeoutDefaultSeconds = 1800 // 30 minutes
+	// IBUAutoRollbackDeadlineFile records the deadline of the LCA Init Monitor watchdog, written when the watchdog starts
+	IBUAutoRollbackDeadlineFile = LCAConfigDir + "/autorollback_deadline.json"
+	// IBUAutoRollbackDeadlineExtensionFile records the extension of the watchdog deadline, written by the controller from
+	// the AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation
+	IBUAutoRollbackDeadlineExtensionFile            = LCAConfigDir + "/autorollback_deadline_extension.json"
 	IBUInitMonitorService                           = "lca-init-monitor.service"
 	IBUInitMonitorServiceFile                       = "/etc/systemd/system/" + IBUInitMonitorService
 	IPCAutoRollbackInitMonitorTimeoutDefaultSeconds = 1800 // 30 minutes
 	IPCInitMonitorService                           = "lca-init-monitor.service"
 	IPConfigurationService                          = "ip-configuration.service"
 	IPCFilePath                                     = LCAConfigDir + "/ipc.json"
+	InstallationConfigurationService                = "installation-configuration.service"
+	// ProgressSnapshotFile persists the last known upgrade progress served by the local progress API
+	ProgressSnapshotFile = LCAConfigDir + "/progress.json"
+	// AuditLogFile is the append-only audit trail of the upgrade actions, carried over to the new stateroot
+	AuditLogFile = LCAConfigDir + "/audit.log"
+	// ContainerStorageMigrationFile records the relocation of the precached images to the graph root of the new stateroot
+	ContainerStorageMigrationFile = LCAWorkspaceDir + "/container-storage-migration.json"
+	// CheckpointFile records the steps of the Prep and Upgrade stages completed on the node, resumed after a reboot
+	// or a restart of the agent
+	CheckpointFile = LCAWorkspaceDir + "/checkpoints.json"
+	// ProgressSocketFile is the unix socket of the local progress API on the node
+	ProgressSocketFile = "/run/lifecycle-agent/progress.sock"
 	// InitMonitorModeFile configures which mode the init-monitor should operate in ("ibu" or "ipconfig")
 	InitMonitorModeFile = LCAWorkspaceDir + "/initmonitor_mode"
 	// AutoRollbackOnFailurePostRebootConfigAnnotation configure automatic rollback when the reconfiguration of the cluster fails upon the first reboot.
@@ -101,6 +122,10 @@ const (
 	// AutoRollbackOnFailureInitMonitorAnnotation configure automatic rollback LCA Init Monitor watchdog, which triggers auto-rollback if timeout occurs before upgrade completion
 	// Only acceptable value is AutoRollbackDisableValue. Any other value is treated as "Enabled".
 	AutoRollbackOnFailureInitMonitorAnnotation = "auto-rollback-on-failure.lca.openshift.io/init-monitor"
+	// AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation extends the deadline of the LCA Init Monitor watchdog at
+	// runtime, e.g. while the upgrade is being debugged. The value is a duration, such as 2h, added to the deadline set
+	// by the timeout when the watchdog started.
+	AutoRollbackOnFailureInitMonitorDeadlineExtensionAnnotation = "auto-rollback-on-failure.lca.openshift.io/init-monitor-deadline-extension"
 	// AutoRollbackOnFailureIPConfigRunAnnotation configure automatic rollback when the IP config run fails.
 	// Only acceptable value is AutoRollbackDisableValue. Any other value is treated as "Enabled".
 	AutoRollbackOnFailureIPConfigRunAnnotation = "auto-rollback-on-failure.lca.openshift.io/ip-config-run"
@@ -115,6 +140,11 @@ const (
 	ImageCleanupOnPrepAnnotation = "image-cleanup.lca.openshift.io/on-prep"
 	// ImageCleanupDisabledValue value to disable image cleanup
 	ImageCleanupDisabledValue = "Disabled"
+	// WorkerMachineConfigPool is the MachineConfigPool of the additional worker nodes attached to the SNO
+	WorkerMachineConfigPool = "worker"
+	// UpgradeWorkerNodesAnnotation is set on the worker MachineConfigPool, paused after the pivot, to the comma separated
+	// names of the worker nodes rolled out once the upgrade of the control plane node has completed
+	UpgradeWorkerNodesAnnotation = "lca.openshift.io/upgrade-worker-nodes"
 
 	LcaNamespace = "openshift-lifecycle-agent"
 	Host         = "/host"
@@ -139,6 +169,33 @@ const (
 
 	SeedClusterInfoOCILabel = "com.openshift.lifecycle-agent.seed_cluster_info"
 
+	// SeedBaseImageOCILabel is set on a layered seed image to the pull-spec of the seed image it is built on
+	SeedBaseImageOCILabel = "com.openshift.lifecycle-agent.seed_base_image"
+
+	// SeedCompressionGzip and SeedCompressionZstd are the compression algorithms of the seed image archives
+	SeedCompressionGzip = "gzip"
+	SeedCompressionZstd = "zstd"
+
+	// SeedEtcdFull, SeedEtcdTrimmed and SeedEtcdExcluded are the etcd contents of the seed image
+	SeedEtcdFull     = "Full"
+	SeedEtcdTrimmed  = "Trimmed"
+	SeedEtcdExcluded = "Excluded"
+
+	// EtcdSnapshotFileName and EtcdutlFileName are the etcd snapshot, and the etcdutl binary restoring it, carried by
+	// a seed image excluding the etcd data dir
+	EtcdSnapshotFileName = "etcd-snapshot.db"
+	EtcdutlFileName      = "etcdutl"
+
+	// KlusterletName, KlusterletNamespace and KlusterletCRDName are the Klusterlet CR of the ACM agent of a managed
+	// cluster, the namespace of its agents and its CRD
+	KlusterletName      = "klusterlet"
+	KlusterletNamespace = "open-cluster-management-agent"
+	KlusterletCRDName   = "klusterlets.operator.open-cluster-management.io"
+
+	// SeedContentHashOCILabel is set to the digest of the content manifest of the seed image, listing the digests of
+	// the files it includes
+	SeedContentHashOCILabel = "com.openshift.lifecycle-agent.seed_content_hash"
+
 	PullSecretName           = "pull-secret"
 	PullSecretEmptyData      = "{\"auths\":{\"registry.connect.redhat.com\":{\"username\":\"empty\",\"password\":\"empty\",\"auth\":\"ZW1wdHk6ZW1wdHk=\",\"email\":\"\"}}}" //nolint:gosec
 	OpenshiftConfigNamespace = "openshift-config"
@@ -148,6 +205,9 @@ const (
 
 	CaBundleDataKey                  = "ca-bundle.crt"
 	ClusterAdditionalTrustBundleName = "user-ca-bundle"
+	// AdditionalCABundlesConfigmapName is the trustedCA configmap of the Proxy CR holding the additional CA bundles of
+	// the seed reconfiguration, when the Proxy CR has none
+	AdditionalCABundlesConfigmapName = "lca-additional-ca-bundles"
 
 	IBIWorkspace = "var/tmp"
 
@@ -47,3 +47,14 @@ func DetectClusterIPFamilies(ips []string) (bool, bool) {
 
 	return clusterHasIPv4, clusterHasIPv6
 }
+
+// IsIPv6OnlyConversion reports whether a cluster with the seed IPs, of which at least one is IPv4, is reconfigured
+// with a single IPv6 IP, i.e. an IPv4 or dual-stack seed is converted to IPv6-only
+func IsIPv6OnlyConversion(seedIPs, reconfigIPs []string) bool {
+	if len(reconfigIPs) != 1 {
+		return false
+	}
+	seedHasIPv4, _ := DetectClusterIPFamilies(seedIPs)
+	reconfigHasIPv4, reconfigHasIPv6 := DetectClusterIPFamilies(reconfigIPs)
+	return seedHasIPv4 && !reconfigHasIPv4 && reconfigHasIPv6
+}
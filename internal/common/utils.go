@@ -133,6 +133,15 @@ func GetStaterootName(identifier string) string {
 	return fmt.Sprintf("rhcos_%s", strings.ReplaceAll(identifier, "-", "_"))
 }
 
+// IsAutoRollbackEnabled reports whether an automatic rollback check is enabled. The value set in the spec takes
+// precedence, otherwise the check is enabled unless the corresponding annotation is set to AutoRollbackDisableValue
+func IsAutoRollbackEnabled(specValue *bool, annotations map[string]string, annotation string) bool {
+	if specValue != nil {
+		return *specValue
+	}
+	return annotations[annotation] != AutoRollbackDisableValue
+}
+
 func RemoveDuplicates[T comparable](list []T) []T {
 	result := []T{}
 	mp := make(map[T]bool)
@@ -205,6 +214,15 @@ func IsJobFinished(job *kbatch.Job) (bool, kbatch.JobConditionType) {
 	return false, ""
 }
 
+// CountJobPods returns the number of pods of the job that still exist, including the terminating ones
+func CountJobPods(ctx context.Context, c client.Client, jobName string) (int, error) {
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(LcaNamespace), client.MatchingLabels{"job-name": jobName}); err != nil {
+		return 0, fmt.Errorf("failed to list the pods of job %s: %w", jobName, err)
+	}
+	return len(podList.Items), nil
+}
+
 func GenerateDeleteOptions() *client.DeleteOptions {
 	propagationPolicy := metav1.DeletePropagationForeground // delete only when dependents are deleted
 
@@ -19,3 +19,15 @@ func TestRemoveDuplicates(t *testing.T) {
 	resStr := RemoveDuplicates[string](strs)
 	assert.Equal(t, []string{"a/b/c/d", "a/b/c"}, resStr)
 }
+
+func TestIsAutoRollbackEnabled(t *testing.T) {
+	enabled, disabled := true, false
+	annotation := AutoRollbackOnFailureInitMonitorAnnotation
+	disabledAnnotations := map[string]string{annotation: AutoRollbackDisableValue}
+
+	assert.True(t, IsAutoRollbackEnabled(nil, nil, annotation))
+	assert.True(t, IsAutoRollbackEnabled(nil, map[string]string{annotation: "Enabled"}, annotation))
+	assert.False(t, IsAutoRollbackEnabled(nil, disabledAnnotations, annotation))
+	assert.False(t, IsAutoRollbackEnabled(&disabled, nil, annotation))
+	assert.True(t, IsAutoRollbackEnabled(&enabled, disabledAnnotations, annotation))
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const (
+	// Dir is the directory of the diagnostics bundles on the node
+	Dir = common.LCAConfigDir + "/diagnostics"
+
+	bundlePrefix = "lca-diagnostics-"
+	bundleSuffix = ".tar.gz"
+	// maxBundles is the number of bundles kept on the node, the oldest ones being removed
+	maxBundles = 3
+	// maxJournalLines is the number of the most recent journal lines collected per unit
+	maxJournalLines = 5000
+	// maxEntrySize bounds the size of each file of the bundle, the beginning of larger files being dropped
+	maxEntrySize = 16 << 20
+)
+
+// journalUnits are the systemd units of the upgrade and rollback, the lca-cli logs of the post-pivot reconfiguration
+// and of the init monitor being in their journal
+var journalUnits = []string{
+	"prepare-installation-configuration.service",
+	common.InstallationConfigurationService,
+	common.IBUInitMonitorService,
+	"kubelet.service",
+	"crio.service",
+}
+
+// Collector collects a bounded diagnostics bundle on the node when a stage fails, so that the logs needed to
+// troubleshoot the failure are gathered before they are rotated or lost to a rollback
+type Collector struct {
+	Client   client.Client
+	Executor ops.Execute
+	Log      logr.Logger
+	// Dir is the directory the bundles are written to, as seen by the collector
+	Dir string
+	// AuditLogFile is the audit log holding the stage transitions of the IBU, as seen by the collector
+	AuditLogFile string
+
+	// now is a var in order to override it in unit tests
+	now func() time.Time
+}
+
+type entry struct {
+	name    string
+	content []byte
+}
+
+// Collect writes a bundle of the journal of the upgrade units, of the lca-cli logs, of the ostree deployments, of the
+// OADP CRs and of the IBU along with its stage transitions, and returns the path of the bundle on the node. The
+// collection is best effort, the errors met being recorded in the errors.txt file of the bundle.
+func (c *Collector) Collect(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, stage ibuv1.ImageBasedUpgradeStage) (string, error) {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+
+	type source struct {
+		name    string
+		collect func() ([]byte, error)
+	}
+	var sources []source
+	for _, unit := range journalUnits {
+		sources = append(sources, source{"journal/" + unit + ".log", func() ([]byte, error) {
+			return c.execute("journalctl", "--no-pager", "-o", "short-iso", "-n", fmt.Sprint(maxJournalLines), "-u", unit)
+		}})
+	}
+	dpaList := &unstructured.UnstructuredList{}
+	dpaList.SetGroupVersionKind(backuprestore.DpaGvkList)
+	sources = append(sources,
+		source{"journal/lca-cli.log", func() ([]byte, error) {
+			return c.execute("journalctl", "--no-pager", "-o", "short-iso", "-n", fmt.Sprint(maxJournalLines), "_COMM=lca-cli")
+		}},
+		source{"ostree/rpm-ostree-status.json", func() ([]byte, error) { return c.execute("rpm-ostree", "status", "--json") }},
+		source{"ostree/ostree-admin-status.txt", func() ([]byte, error) { return c.execute("ostree", "admin", "status") }},
+		source{"oadp/backups.yaml", func() ([]byte, error) { return c.dumpList(ctx, &velerov1.BackupList{}) }},
+		source{"oadp/restores.yaml", func() ([]byte, error) { return c.dumpList(ctx, &velerov1.RestoreList{}) }},
+		source{"oadp/dataprotectionapplications.yaml", func() ([]byte, error) { return c.dumpList(ctx, dpaList) }},
+		source{"ibu.yaml", func() ([]byte, error) { return marshal(ibu) }},
+		source{"audit.log", func() ([]byte, error) { return readTail(c.AuditLogFile) }},
+	)
+
+	var entries []entry
+	var errs []string
+	for _, s := range sources {
+		content, err := s.collect()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", s.name, err))
+		}
+		if len(content) > 0 {
+			entries = append(entries, entry{name: s.name, content: content})
+		}
+	}
+
+	if len(errs) > 0 {
+		entries = append(entries, entry{name: "errors.txt", content: []byte(strings.Join(errs, "\n") + "\n")})
+	}
+
+	name := fmt.Sprintf("%s%s-%s%s", bundlePrefix, strings.ToLower(string(stage)),
+		now().UTC().Format("20060102T150405Z"), bundleSuffix)
+	if err := c.writeBundle(name, entries, now()); err != nil {
+		return "", err
+	}
+	c.pruneBundles()
+
+	return filepath.Join(Dir, name), nil
+}
+
+// execute runs the command on the host, returning its output bounded to the maximum entry size
+func (c *Collector) execute(command string, args ...string) ([]byte, error) {
+	output, err := c.Executor.Execute(command, args...)
+	if err != nil {
+		return []byte(output), fmt.Errorf("failed to run %s: %w", command, err)
+	}
+	return tail([]byte(output)), nil
+}
+
+// dumpList lists the CRs in the OADP namespace as YAML, nothing being dumped if OADP is not installed
+func (c *Collector) dumpList(ctx context.Context, list client.ObjectList) ([]byte, error) {
+	if err := c.Client.List(ctx, list, client.InNamespace(backuprestore.OadpNs)); err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return marshal(list)
+}
+
+// marshal returns the object as YAML, bounded to the maximum entry size
+func marshal(obj any) ([]byte, error) {
+	content, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	return tail(content), nil
+}
+
+// readTail reads the end of the file, up to the maximum entry size
+func readTail(file string) ([]byte, error) {
+	if file == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return tail(content), nil
+}
+
+func tail(content []byte) []byte {
+	if len(content) > maxEntrySize {
+		return content[len(content)-maxEntrySize:]
+	}
+	return content
+}
+
+// writeBundle writes the entries as a gzipped tar, through a temporary file so that a bundle is never left incomplete
+func (c *Collector) writeBundle(name string, entries []entry, modTime time.Time) (err error) {
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create diagnostics directory %s: %w", c.Dir, err)
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".bundle-")
+	if err != nil {
+		return fmt.Errorf("failed to create diagnostics bundle: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(tmp)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0o600, Size: int64(len(e.content)), ModTime: modTime}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s to diagnostics bundle: %w", e.name, err)
+		}
+		if _, err := tarWriter.Write(e.content); err != nil {
+			return fmt.Errorf("failed to write %s to diagnostics bundle: %w", e.name, err)
+		}
+	}
+	if err := errors.Join(tarWriter.Close(), gzipWriter.Close(), tmp.Close()); err != nil {
+		return fmt.Errorf("failed to write diagnostics bundle: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(c.Dir, name)); err != nil {
+		return fmt.Errorf("failed to write diagnostics bundle %s: %w", name, err)
+	}
+	return nil
+}
+
+// pruneBundles removes the oldest bundles, keeping the most recent ones
+func (c *Collector) pruneBundles() {
+	files, err := os.ReadDir(c.Dir)
+	if err != nil {
+		c.Log.Error(err, "failed to list diagnostics bundles")
+		return
+	}
+	var bundles []string
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), bundlePrefix) && strings.HasSuffix(file.Name(), bundleSuffix) {
+			bundles = append(bundles, file.Name())
+		}
+	}
+	// Sort by collection time, the stage being part of the name
+	slices.SortFunc(bundles, func(a, b string) int {
+		return strings.Compare(bundleTimestamp(a), bundleTimestamp(b))
+	})
+	for len(bundles) > maxBundles {
+		if err := os.Remove(filepath.Join(c.Dir, bundles[0])); err != nil {
+			c.Log.Error(err, "failed to remove diagnostics bundle", "bundle", bundles[0])
+		}
+		bundles = bundles[1:]
+	}
+}
+
+// CopyBundles copies the bundles to the directory, so that the bundles collected on the new stateroot are kept after a
+// rollback to the original stateroot
+func (c *Collector) CopyBundles(dir string) error {
+	files, err := os.ReadDir(c.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list diagnostics bundles: %w", err)
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), bundlePrefix) || !strings.HasSuffix(file.Name(), bundleSuffix) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(c.Dir, file.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read diagnostics bundle %s: %w", file.Name(), err)
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create diagnostics directory %s: %w", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file.Name()), content, 0o600); err != nil {
+			return fmt.Errorf("failed to copy diagnostics bundle %s: %w", file.Name(), err)
+		}
+	}
+	return nil
+}
+
+func bundleTimestamp(name string) string {
+	name = strings.TrimSuffix(name, bundleSuffix)
+	return name[strings.LastIndex(name, "-")+1:]
+}
@@ -0,0 +1,134 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+func readBundle(t *testing.T, path string) map[string]string {
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+
+	entries := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		content, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		entries[header.Name] = string(content)
+	}
+	return entries
+}
+
+func TestCollect(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+
+	testscheme := runtime.NewScheme()
+	assert.NoError(t, ibuv1.AddToScheme(testscheme))
+	assert.NoError(t, velerov1.AddToScheme(testscheme))
+	backup := &velerov1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "acm-klusterlet", Namespace: backuprestore.OadpNs}}
+	c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(backup).Build()
+
+	ibu := &ibuv1.ImageBasedUpgrade{ObjectMeta: metav1.ObjectMeta{Name: "upgrade"}}
+	dir := t.TempDir()
+	auditLogFile := filepath.Join(t.TempDir(), "audit.log")
+	assert.NoError(t, os.WriteFile(auditLogFile, []byte(`{"stage":"Upgrade"}`+"\n"), 0o600))
+
+	mockExecutor.EXPECT().Execute("journalctl", gomock.Any()).Return("journal\n", nil).AnyTimes()
+	mockExecutor.EXPECT().Execute("rpm-ostree", "status", "--json").Return(`{"deployments":[]}`, nil).Times(maxBundles + 1)
+	mockExecutor.EXPECT().Execute("ostree", "admin", "status").Return("", assert.AnError).Times(maxBundles + 1)
+
+	collector := &Collector{Client: c, Executor: mockExecutor, Log: logr.Discard(), Dir: dir, AuditLogFile: auditLogFile}
+	collectionTime := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < maxBundles+1; i++ {
+		collector.now = func() time.Time { return collectionTime.Add(time.Duration(i) * time.Hour) }
+		stage := ibuv1.Stages.Upgrade
+		if i%2 == 1 {
+			stage = ibuv1.Stages.Rollback
+		}
+		path, err := collector.Collect(context.Background(), ibu, stage)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(Dir, filepath.Base(path)), path)
+	}
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var bundles []string
+	for _, file := range files {
+		bundles = append(bundles, file.Name())
+	}
+	// The oldest bundle is pruned
+	assert.Equal(t, []string{
+		"lca-diagnostics-rollback-20250601T110000Z.tar.gz",
+		"lca-diagnostics-rollback-20250601T130000Z.tar.gz",
+		"lca-diagnostics-upgrade-20250601T120000Z.tar.gz",
+	}, bundles)
+
+	entries := readBundle(t, filepath.Join(dir, "lca-diagnostics-upgrade-20250601T120000Z.tar.gz"))
+	assert.Equal(t, "journal\n", entries["journal/kubelet.service.log"])
+	assert.Equal(t, "journal\n", entries["journal/lca-cli.log"])
+	assert.Equal(t, `{"deployments":[]}`, entries["ostree/rpm-ostree-status.json"])
+	assert.NotContains(t, entries, "ostree/ostree-admin-status.txt")
+	assert.Contains(t, entries["oadp/backups.yaml"], "name: acm-klusterlet")
+	assert.Contains(t, entries["oadp/restores.yaml"], "items: []")
+	assert.Contains(t, entries["oadp/dataprotectionapplications.yaml"], "kind: DataProtectionApplicationList")
+	assert.Contains(t, entries["ibu.yaml"], "name: upgrade")
+	assert.Equal(t, `{"stage":"Upgrade"}`+"\n", entries["audit.log"])
+	assert.Contains(t, entries["errors.txt"], "ostree/ostree-admin-status.txt: failed to run ostree")
+}
+
+func TestTail(t *testing.T) {
+	content := make([]byte, maxEntrySize+10)
+	content[len(content)-1] = 'x'
+	bounded := tail(content)
+	assert.Len(t, bounded, maxEntrySize)
+	assert.Equal(t, byte('x'), bounded[len(bounded)-1])
+	assert.Equal(t, []byte("short"), tail([]byte("short")))
+}
+
+func TestCopyBundles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "lca-diagnostics-upgrade-20250601T100000Z.tar.gz"), []byte("bundle"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".bundle-123"), []byte("incomplete"), 0o600))
+
+	destination := filepath.Join(t.TempDir(), "diagnostics")
+	collector := &Collector{Log: logr.Discard(), Dir: dir}
+	assert.NoError(t, collector.CopyBundles(destination))
+	files, err := os.ReadDir(destination)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	content, err := os.ReadFile(filepath.Join(destination, "lca-diagnostics-upgrade-20250601T100000Z.tar.gz"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bundle", string(content))
+
+	// Nothing was collected
+	collector.Dir = filepath.Join(t.TempDir(), "missing")
+	assert.NoError(t, collector.CopyBundles(destination))
+}
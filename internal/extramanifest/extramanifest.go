@@ -108,24 +108,30 @@ func IsEMFailedError(err error) bool {
 	return false
 }
 
-func ApplyExtraManifest(ctx context.Context, dc dynamic.Interface, restMapper meta.RESTMapper, manifest *unstructured.Unstructured, isDryRun bool) error {
+// getResourceInterface returns the dynamic client interface for the resource of the manifest
+func getResourceInterface(dc dynamic.Interface, restMapper meta.RESTMapper, manifest *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
 	// Mapping resource GVK to CVR
 	mapping, err := restMapper.RESTMapping(manifest.GroupVersionKind().GroupKind(), manifest.GroupVersionKind().Version)
 	if err != nil {
-		return fmt.Errorf("failed to get RESTMapping: %w", err)
+		return nil, fmt.Errorf("failed to get RESTMapping: %w", err)
 	}
 
-	var resource dynamic.ResourceInterface
 	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
 		// cluster-scoped resource
-		resource = dc.Resource(mapping.Resource)
-	} else {
-		// namespaced-scoped resource
-		manifestNs := manifest.GetNamespace()
-		if manifestNs == "" {
-			manifestNs = "default"
-		}
-		resource = dc.Resource(mapping.Resource).Namespace(manifestNs)
+		return dc.Resource(mapping.Resource), nil
+	}
+	// namespaced-scoped resource
+	manifestNs := manifest.GetNamespace()
+	if manifestNs == "" {
+		manifestNs = "default"
+	}
+	return dc.Resource(mapping.Resource).Namespace(manifestNs), nil
+}
+
+func ApplyExtraManifest(ctx context.Context, dc dynamic.Interface, restMapper meta.RESTMapper, manifest *unstructured.Unstructured, isDryRun bool) error {
+	resource, err := getResourceInterface(dc, restMapper, manifest)
+	if err != nil {
+		return err
 	}
 
 	// Check if the resource exists
@@ -366,6 +372,18 @@ func (h *EMHandler) extractManifestFromConfigmaps(configmaps []corev1.ConfigMap)
 				resource.SetUID("")
 				resource.SetResourceVersion("")
 
+				// The manifest is applied in the wave of its configmap, unless it sets its own
+				if wave, exists := cm.GetAnnotations()[common.ApplyWaveAnn]; exists {
+					if _, exists := resource.GetAnnotations()[common.ApplyWaveAnn]; !exists {
+						annotations := resource.GetAnnotations()
+						if annotations == nil {
+							annotations = make(map[string]string)
+						}
+						annotations[common.ApplyWaveAnn] = wave
+						resource.SetAnnotations(annotations)
+					}
+				}
+
 				manifests = append(manifests, &resource)
 			}
 		}
@@ -496,7 +514,8 @@ func (h *EMHandler) ValidateAndExtractManifestFromPolicies(ctx context.Context,
 	return sortedObjects, nil
 }
 
-// ApplyExtraManifests applies the extra manifests from the preserved extra manifests directory
+// ApplyExtraManifests applies the extra manifests from the preserved extra manifests directory. The manifests are
+// applied in waves, and each wave must be ready before the next one is applied
 func (h *EMHandler) ApplyExtraManifests(ctx context.Context, fromDir string) error {
 	manifests, err := utils.LoadGroupedManifestsFromPath(fromDir, &h.Log)
 	if err != nil {
@@ -507,7 +526,7 @@ func (h *EMHandler) ApplyExtraManifests(ctx context.Context, fromDir string) err
 		return nil
 	}
 
-	for _, group := range manifests {
+	for i, group := range manifests {
 		for _, manifest := range group {
 			h.Log.Info("Applying manifest", "kind", manifest.GetKind(), "name", manifest.GetName())
 			err = ApplyExtraManifest(ctx, h.DynamicClient, h.Client.RESTMapper(), manifest, false)
@@ -531,6 +550,13 @@ func (h *EMHandler) ApplyExtraManifests(ctx context.Context, fromDir string) err
 
 			h.Log.Info("Applied manifest", "name", manifest.GetName(), "namespace", manifest.GetNamespace())
 		}
+
+		// Nothing depends on the last wave
+		if i < len(manifests)-1 {
+			if err := h.waitForManifestsReady(ctx, group); err != nil {
+				return fmt.Errorf("wave %d of %d: %w", i+1, len(manifests), err)
+			}
+		}
 	}
 
 	// Remove the extra manifests directory
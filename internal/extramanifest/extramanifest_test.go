@@ -249,6 +249,53 @@ func TestExportExtraManifests(t *testing.T) {
 	}
 }
 
+func TestExportExtraManifestsConfigMapWave(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().Build()
+	toDir := t.TempDir()
+
+	unannotated := `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: sriov
+`
+	cms := []*corev1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "operators",
+				Namespace:   "default",
+				Annotations: map[string]string{common.ApplyWaveAnn: "2"},
+			},
+			Data: map[string]string{"sriovnetwork2.yaml": sriovnetwork2, "sriovnetwork1.yaml": sriovnetwork1},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "namespaces",
+				Namespace:   "default",
+				Annotations: map[string]string{common.ApplyWaveAnn: "0"},
+			},
+			Data: map[string]string{"namespace.yaml": unannotated},
+		},
+	}
+	for _, cm := range cms {
+		assert.NoError(t, fakeClient.Create(context.Background(), cm))
+	}
+
+	handler := &EMHandler{Client: fakeClient, Log: ctrl.Log.WithName("ExtraManifest")}
+	err := handler.ExportExtraManifestToDir(context.Background(),
+		[]ibuv1.ConfigMapRef{{Name: "operators", Namespace: "default"}, {Name: "namespaces", Namespace: "default"}}, toDir)
+	assert.NoError(t, err)
+
+	// The namespace inherits the wave of its configmap, while the networks keep their own
+	for _, expectedFile := range []string{
+		filepath.Join(toDir, CmManifestPath, "group1", "1_Namespace_sriov_.yaml"),
+		filepath.Join(toDir, CmManifestPath, "group2", "1_SriovNetwork_sriov-nw-mh_openshift-sriov-network-operator.yaml"),
+		filepath.Join(toDir, CmManifestPath, "group3", "1_SriovNetwork_sriov-nw-fh_openshift-sriov-network-operator-test.yaml"),
+	} {
+		assert.FileExists(t, expectedFile)
+	}
+}
+
 func TestValidateExtraManifestConfigmaps(t *testing.T) {
 	ns := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
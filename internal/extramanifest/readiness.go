@@ -0,0 +1,108 @@
+package extramanifest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Use vars for the readiness polling in order to override them in unit tests
+var (
+	waveReadinessInterval = 5 * time.Second
+	waveReadinessTimeout  = 10 * time.Minute
+)
+
+// waitForManifestsReady waits for all the applied manifests of a wave to be ready
+func (h *EMHandler) waitForManifestsReady(ctx context.Context, manifests []*unstructured.Unstructured) error {
+	var notReady []string
+	err := wait.PollUntilContextTimeout(ctx, waveReadinessInterval, waveReadinessTimeout, true, func(ctx context.Context) (bool, error) {
+		notReady = nil
+		for _, manifest := range manifests {
+			ready, err := h.isManifestReady(ctx, manifest)
+			if err != nil {
+				return false, err
+			}
+			if !ready {
+				notReady = append(notReady, fmt.Sprintf("%s[%s]", manifest.GetKind(), manifest.GetName()))
+			}
+		}
+		if len(notReady) > 0 {
+			h.Log.Info("Waiting for manifests to be ready", "manifests", notReady)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return NewEMFailedError(fmt.Sprintf("manifests not ready after %s: %s", waveReadinessTimeout, strings.Join(notReady, ", ")))
+		}
+		return fmt.Errorf("failed to check manifests readiness: %w", err)
+	}
+	return nil
+}
+
+func (h *EMHandler) isManifestReady(ctx context.Context, manifest *unstructured.Unstructured) (bool, error) {
+	resource, err := getResourceInterface(h.DynamicClient, h.Client.RESTMapper(), manifest)
+	if err != nil {
+		return false, err
+	}
+
+	obj, err := resource.Get(ctx, manifest.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get manifest %s called %s: %w", manifest.GetKind(), manifest.GetName(), err)
+	}
+	return isObjectReady(obj), nil
+}
+
+// isObjectReady reports whether the object is ready based on its status. Workloads must have their replicas
+// available, and other resources must have their Ready/Available/Established condition true, if they report any.
+// Resources without such status are ready once they exist.
+func isObjectReady(obj *unstructured.Unstructured) bool {
+	generation := obj.GetGeneration()
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found && observed < generation {
+		return false
+	}
+
+	switch obj.GetKind() {
+	case "Namespace":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return phase == "" || phase == "Active"
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		field := "availableReplicas"
+		if obj.GetKind() == "StatefulSet" {
+			field = "readyReplicas"
+		}
+		current, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+		return current >= replicas
+	case "DaemonSet":
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		available, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberAvailable")
+		return available >= desired
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch condition["type"] {
+		case "Ready", "Available", "Established":
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package extramanifest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsObjectReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      map[string]interface{}
+		expected bool
+	}{
+		{
+			name:     "no status",
+			obj:      map[string]interface{}{"kind": "ConfigMap"},
+			expected: true,
+		},
+		{
+			name:     "namespace terminating",
+			obj:      map[string]interface{}{"kind": "Namespace", "status": map[string]interface{}{"phase": "Terminating"}},
+			expected: false,
+		},
+		{
+			name: "deployment available",
+			obj: map[string]interface{}{"kind": "Deployment",
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"availableReplicas": int64(2)}},
+			expected: true,
+		},
+		{
+			name:     "deployment without available replicas",
+			obj:      map[string]interface{}{"kind": "Deployment"},
+			expected: false,
+		},
+		{
+			name: "daemonset partially available",
+			obj: map[string]interface{}{"kind": "DaemonSet",
+				"status": map[string]interface{}{"desiredNumberScheduled": int64(1), "numberAvailable": int64(0)}},
+			expected: false,
+		},
+		{
+			name: "stale observed generation",
+			obj: map[string]interface{}{"kind": "SriovNetwork",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1)}},
+			expected: false,
+		},
+		{
+			name: "crd established",
+			obj: map[string]interface{}{"kind": "CustomResourceDefinition",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+					map[string]interface{}{"type": "Established", "status": "True"},
+				}}},
+			expected: true,
+		},
+		{
+			name: "custom resource not ready",
+			obj: map[string]interface{}{"kind": "PerformanceProfile",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": "False"},
+				}}},
+			expected: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isObjectReady(&unstructured.Unstructured{Object: tc.obj}))
+		})
+	}
+}
+
+func TestApplyExtraManifestsWaves(t *testing.T) {
+	origInterval, origTimeout := waveReadinessInterval, waveReadinessTimeout
+	waveReadinessInterval, waveReadinessTimeout = 10*time.Millisecond, 50*time.Millisecond
+	defer func() { waveReadinessInterval, waveReadinessTimeout = origInterval, origTimeout }()
+
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: operator\n  namespace: default\n"
+
+	tests := []struct {
+		name        string
+		groups      map[string]string
+		expectedErr string
+	}{
+		{
+			name: "waves ready",
+			groups: map[string]string{
+				"group1/1_ConfigMap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first\n  namespace: default\n",
+				"group2/1_ConfigMap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: second\n  namespace: default\n",
+			},
+		},
+		{
+			name: "last wave is not waited for",
+			groups: map[string]string{
+				"group1/1_ConfigMap.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first\n  namespace: default\n",
+				"group2/1_Deployment.yaml": deployment,
+			},
+		},
+		{
+			name: "wave not ready",
+			groups: map[string]string{
+				"group1/1_Deployment.yaml": deployment,
+				"group2/1_ConfigMap.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: second\n  namespace: default\n",
+			},
+			expectedErr: "wave 1 of 2: manifests not ready after 50ms: Deployment[operator]",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fromDir := t.TempDir()
+			for file, content := range tc.groups {
+				assert.NoError(t, os.MkdirAll(filepath.Join(fromDir, filepath.Dir(file)), 0o700))
+				assert.NoError(t, os.WriteFile(filepath.Join(fromDir, file), []byte(content), 0o600))
+			}
+
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+			mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+			handler := &EMHandler{
+				Client:        fake.NewClientBuilder().WithRESTMapper(mapper).Build(),
+				DynamicClient: dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
+				Log:           ctrl.Log.WithName("ExtraManifest"),
+			}
+
+			err := handler.ApplyExtraManifests(context.Background(), fromDir)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.NoDirExists(t, fromDir)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedErr)
+			assert.True(t, IsEMFailedError(err))
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// CustomHealthChecksPath is the directory, relative to the new stateroot /var, where the user-defined health checks
+	// are exported before the pivot
+	CustomHealthChecksPath = "/opt/health-checks"
+	CustomHealthChecksFile = "health-checks.json"
+)
+
+// ResourceCheck defines a user-defined health check verifying the resources matching the apiVersion, kind,
+// namespace and name or label selector. If no conditions are listed, the check only verifies that at least one
+// matching resource exists. Otherwise, every matching resource must report all the listed conditions.
+// If a timeout is set, the check fails for good once it has not passed within the timeout.
+type ResourceCheck struct {
+	APIVersion     string           `json:"apiVersion"`
+	Kind           string           `json:"kind"`
+	Namespace      string           `json:"namespace,omitempty"`
+	Name           string           `json:"name,omitempty"`
+	LabelSelector  string           `json:"labelSelector,omitempty"`
+	Conditions     []ConditionCheck `json:"conditions,omitempty"`
+	TimeoutSeconds int              `json:"timeoutSeconds,omitempty"`
+}
+
+// TimeoutError is returned when one or more user-defined health checks have not passed within their timeout
+type TimeoutError struct {
+	ErrMessage string
+}
+
+func (e *TimeoutError) Error() string {
+	return e.ErrMessage
+}
+
+// IsTimeoutError returns true if the user-defined health checks have timed out
+func IsTimeoutError(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// ConditionCheck defines the expected status of a .status.conditions entry
+type ConditionCheck struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+func (r ResourceCheck) String() string {
+	id := r.Kind
+	if r.Namespace != "" {
+		id = fmt.Sprintf("%s %s", id, r.Namespace)
+	}
+	if r.Name != "" {
+		return fmt.Sprintf("%s/%s", id, r.Name)
+	}
+	if r.LabelSelector != "" {
+		return fmt.Sprintf("%s (%s)", id, r.LabelSelector)
+	}
+	return id
+}
+
+func (r ResourceCheck) validate() error {
+	if r.APIVersion == "" || r.Kind == "" {
+		return fmt.Errorf("apiVersion and kind are required")
+	}
+	if _, err := schema.ParseGroupVersion(r.APIVersion); err != nil {
+		return fmt.Errorf("invalid apiVersion %s: %w", r.APIVersion, err)
+	}
+	if r.Name != "" && r.LabelSelector != "" {
+		return fmt.Errorf("name and labelSelector are mutually exclusive")
+	}
+	if r.LabelSelector != "" {
+		if _, err := labels.Parse(r.LabelSelector); err != nil {
+			return fmt.Errorf("invalid labelSelector %s: %w", r.LabelSelector, err)
+		}
+	}
+	for _, cond := range r.Conditions {
+		if cond.Type == "" || cond.Status == "" {
+			return fmt.Errorf("condition type and status are required")
+		}
+	}
+	if r.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeoutSeconds must not be negative")
+	}
+	return nil
+}
+
+// ParseCustomHealthChecks extracts the user-defined health checks from the configmaps. Each data entry holds a
+// yaml list of ResourceCheck
+func ParseCustomHealthChecks(configmaps []corev1.ConfigMap) ([]ResourceCheck, error) {
+	var checks []ResourceCheck
+	var errs []string
+
+	for _, cm := range configmaps {
+		// sort the keys to keep the checks order stable
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			var entries []ResourceCheck
+			if err := yaml.UnmarshalStrict([]byte(cm.Data[key]), &entries); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to decode health checks in configMap %s/%s key %s: %s", cm.Namespace, cm.Name, key, err))
+				continue
+			}
+			for i, entry := range entries {
+				if err := entry.validate(); err != nil {
+					errs = append(errs, fmt.Sprintf("invalid health check %d in configMap %s/%s key %s: %s", i+1, cm.Namespace, cm.Name, key, err))
+					continue
+				}
+				checks = append(checks, entry)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return checks, nil
+}
+
+// ValidateCustomHealthCheckConfigmaps verifies that the health checks configmaps exist and hold valid health checks
+func ValidateCustomHealthCheckConfigmaps(ctx context.Context, c client.Client, content []ibuv1.ConfigMapRef) error {
+	configmaps, err := common.GetConfigMaps(ctx, c, content)
+	if err != nil {
+		return fmt.Errorf("failed to get health checks configMaps: %w", err)
+	}
+	if _, err := ParseCustomHealthChecks(configmaps); err != nil {
+		return fmt.Errorf("failed to parse health checks configMaps: %w", err)
+	}
+	return nil
+}
+
+// ExportCustomHealthChecksToDir writes the user-defined health checks from the configmaps to the given directory so
+// that they are available after the pivot
+func ExportCustomHealthChecksToDir(ctx context.Context, c client.Client, content []ibuv1.ConfigMapRef, toDir string) error {
+	if len(content) == 0 {
+		return nil
+	}
+
+	configmaps, err := common.GetConfigMaps(ctx, c, content)
+	if err != nil {
+		return fmt.Errorf("failed to get health checks configMaps: %w", err)
+	}
+	checks, err := ParseCustomHealthChecks(configmaps)
+	if err != nil {
+		return fmt.Errorf("failed to parse health checks configMaps: %w", err)
+	}
+
+	dir := filepath.Join(toDir, CustomHealthChecksPath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for health checks in %s: %w", dir, err)
+	}
+	if err := lcautils.MarshalToFile(checks, filepath.Join(dir, CustomHealthChecksFile)); err != nil {
+		return fmt.Errorf("failed to write health checks file: %w", err)
+	}
+	return nil
+}
+
+// CustomHealthChecks runs the user-defined health checks exported in the given directory. Nothing is checked if no
+// health checks were exported. The timeouts of the checks are counted from waitingSince, and a TimeoutError is
+// returned once any failing check has exceeded its timeout.
+func CustomHealthChecks(ctx context.Context, c client.Reader, l logr.Logger, fromDir string, waitingSince time.Time) error {
+	filename := filepath.Join(fromDir, CustomHealthChecksFile)
+	if _, err := os.Stat(filename); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat health checks file %s: %w", filename, err)
+	}
+
+	var checks []ResourceCheck
+	if err := lcautils.ReadYamlOrJSONFile(filename, &checks); err != nil {
+		return fmt.Errorf("failed to read health checks file %s: %w", filename, err)
+	}
+
+	var failures, timedOut []string
+	for _, check := range checks {
+		if err := runResourceCheck(ctx, c, check); err != nil {
+			l.Info("user-defined health check failure", "check", check.String(), "error", err.Error())
+			failures = append(failures, err.Error())
+			if timeout := time.Duration(check.TimeoutSeconds) * time.Second; timeout > 0 && time.Since(waitingSince) >= timeout {
+				timedOut = append(timedOut, fmt.Sprintf("%s (timeout %s)", err.Error(), timeout))
+			}
+		}
+	}
+
+	if len(timedOut) > 0 {
+		return &TimeoutError{
+			ErrMessage: fmt.Sprintf("one or more user-defined health checks timed out: %s", strings.Join(timedOut, "\n  - ")),
+		}
+	}
+	if len(failures) > 0 {
+		// nolint: staticcheck
+		return fmt.Errorf("one or more user-defined health checks failed: %s", strings.Join(failures, "\n  - "))
+	}
+
+	l.Info("User-defined health checks done", "count", len(checks))
+	return nil
+}
+
+func runResourceCheck(ctx context.Context, c client.Reader, check ResourceCheck) error {
+	gv, err := schema.ParseGroupVersion(check.APIVersion)
+	if err != nil {
+		return fmt.Errorf("%s: invalid apiVersion: %w", check, err)
+	}
+
+	var objs []unstructured.Unstructured
+	if check.Name != "" {
+		obj := unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gv.WithKind(check.Kind))
+		if err := c.Get(ctx, client.ObjectKey{Namespace: check.Namespace, Name: check.Name}, &obj); err != nil {
+			return fmt.Errorf("%s: %w", check, err)
+		}
+		objs = append(objs, obj)
+	} else {
+		list := unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gv.WithKind(check.Kind + "List"))
+		opts := []client.ListOption{}
+		if check.Namespace != "" {
+			opts = append(opts, client.InNamespace(check.Namespace))
+		}
+		if check.LabelSelector != "" {
+			selector, err := labels.Parse(check.LabelSelector)
+			if err != nil {
+				return fmt.Errorf("%s: invalid labelSelector: %w", check, err)
+			}
+			opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+		}
+		if err := c.List(ctx, &list, opts...); err != nil {
+			return fmt.Errorf("%s: %w", check, err)
+		}
+		objs = list.Items
+	}
+
+	if len(objs) == 0 {
+		return fmt.Errorf("%s: no matching resources found", check)
+	}
+
+	var notReady []string
+	for _, obj := range objs {
+		for _, cond := range check.Conditions {
+			if status := getConditionStatus(obj, cond.Type); status != cond.Status {
+				notReady = append(notReady, fmt.Sprintf("%s condition %s is %q, expected %q", obj.GetName(), cond.Type, status, cond.Status))
+			}
+		}
+	}
+	if len(notReady) > 0 {
+		return fmt.Errorf("%s: %s", check, strings.Join(notReady, ", "))
+	}
+	return nil
+}
+
+func getConditionStatus(obj unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cond["type"] == conditionType {
+			status, _ := cond["status"].(string)
+			return status
+		}
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package healthcheck
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseCustomHealthChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       map[string]string
+		expected   []ResourceCheck
+		wantErrMsg string
+	}{
+		{
+			name: "valid checks",
+			data: map[string]string{
+				"b-pods": `
+- apiVersion: v1
+  kind: Pod
+  namespace: cnf
+  labelSelector: app=du
+  conditions:
+  - type: Ready
+    status: "True"
+`,
+				"a-node": `
+- apiVersion: v1
+  kind: Node
+`,
+			},
+			expected: []ResourceCheck{
+				{APIVersion: "v1", Kind: "Node"},
+				{APIVersion: "v1", Kind: "Pod", Namespace: "cnf", LabelSelector: "app=du",
+					Conditions: []ConditionCheck{{Type: "Ready", Status: "True"}}},
+			},
+		},
+		{
+			name:       "unknown field",
+			data:       map[string]string{"checks": "- apiVersion: v1\n  kind: Pod\n  foo: bar\n"},
+			wantErrMsg: "failed to decode health checks in configMap cnf/checks key checks",
+		},
+		{
+			name:       "missing kind",
+			data:       map[string]string{"checks": "- apiVersion: v1\n"},
+			wantErrMsg: "invalid health check 1 in configMap cnf/checks key checks: apiVersion and kind are required",
+		},
+		{
+			name:       "name and label selector",
+			data:       map[string]string{"checks": "- apiVersion: v1\n  kind: Pod\n  name: du\n  labelSelector: app=du\n"},
+			wantErrMsg: "name and labelSelector are mutually exclusive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "cnf"}, Data: tt.data}
+			checks, err := ParseCustomHealthChecks([]v1.ConfigMap{cm})
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, checks)
+		})
+	}
+}
+
+func TestCustomHealthChecks(t *testing.T) {
+	readyPod := func(name string, ready v1.ConditionStatus) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cnf", Labels: map[string]string{"app": "du"}},
+			Status: v1.PodStatus{
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: ready}},
+			},
+		}
+	}
+	podsReady := ResourceCheck{APIVersion: "v1", Kind: "Pod", Namespace: "cnf", LabelSelector: "app=du",
+		Conditions: []ConditionCheck{{Type: "Ready", Status: "True"}}}
+
+	podsReadyWithTimeout := podsReady
+	podsReadyWithTimeout.TimeoutSeconds = 600
+
+	tests := []struct {
+		name         string
+		checks       []ResourceCheck
+		objects      []runtime.Object
+		waitingSince time.Time
+		wantErrMsg   string
+		wantTimeout  bool
+	}{
+		{
+			name:    "no checks exported",
+			objects: []runtime.Object{},
+		},
+		{
+			name:    "all pods ready",
+			checks:  []ResourceCheck{podsReady},
+			objects: []runtime.Object{readyPod("du-1", v1.ConditionTrue), readyPod("du-2", v1.ConditionTrue)},
+		},
+		{
+			name:       "one pod not ready",
+			checks:     []ResourceCheck{podsReady},
+			objects:    []runtime.Object{readyPod("du-1", v1.ConditionTrue), readyPod("du-2", v1.ConditionFalse)},
+			wantErrMsg: `Pod cnf (app=du): du-2 condition Ready is "False", expected "True"`,
+		},
+		{
+			name:         "failing check within its timeout",
+			checks:       []ResourceCheck{podsReadyWithTimeout},
+			objects:      []runtime.Object{readyPod("du-1", v1.ConditionFalse)},
+			waitingSince: time.Now().Add(-5 * time.Minute),
+			wantErrMsg:   "one or more user-defined health checks failed",
+		},
+		{
+			name:         "failing check past its timeout",
+			checks:       []ResourceCheck{podsReady, podsReadyWithTimeout},
+			objects:      []runtime.Object{readyPod("du-1", v1.ConditionFalse)},
+			waitingSince: time.Now().Add(-15 * time.Minute),
+			wantErrMsg:   "one or more user-defined health checks timed out",
+			wantTimeout:  true,
+		},
+		{
+			name:       "no matching resources",
+			checks:     []ResourceCheck{podsReady},
+			objects:    []runtime.Object{},
+			wantErrMsg: "Pod cnf (app=du): no matching resources found",
+		},
+		{
+			name:       "named resource not found",
+			checks:     []ResourceCheck{{APIVersion: "v1", Kind: "Pod", Namespace: "cnf", Name: "du-3"}},
+			objects:    []runtime.Object{readyPod("du-1", v1.ConditionTrue)},
+			wantErrMsg: "Pod cnf/du-3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.checks != nil {
+				assert.NoError(t, lcautils.MarshalToFile(tt.checks, filepath.Join(dir, CustomHealthChecksFile)))
+			}
+
+			c := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(tt.objects...).Build()
+			waitingSince := tt.waitingSince
+			if waitingSince.IsZero() {
+				waitingSince = time.Now()
+			}
+			err := CustomHealthChecks(context.Background(), c, logr.Discard(), dir, waitingSince)
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				assert.Equal(t, tt.wantTimeout, IsTimeoutError(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -3,18 +3,23 @@ package healthcheck
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	k8sv1 "k8s.io/api/certificates/v1"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	sriovv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	backuprestore "github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
 	configv1 "github.com/openshift/api/config/v1"
 	mcv1 "github.com/openshift/api/machineconfiguration/v1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
@@ -32,6 +37,7 @@ import (
 // +kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworknodestates,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=list;watch
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests,verbs=get;list;watch
+// +kubebuilder:rbac:groups=operator.open-cluster-management.io,resources=klusterlets,verbs=get;list;watch
 
 const (
 	NodeRoleControlPlane = "node-role.kubernetes.io/control-plane"
@@ -39,22 +45,78 @@ const (
 	NodeRoleWorker       = "node-role.kubernetes.io/worker"
 
 	SriovNetworkNodeStateNotPresentMsg = "no SriovNetworkNodeStates present"
+
+	// klusterletHubConnectionDegraded is the condition of the Klusterlet CR reporting the connection to the hub
+	klusterletHubConnectionDegraded = "HubConnectionDegraded"
 )
 
-func HealthChecks(ctx context.Context, c client.Reader, l logr.Logger) error {
+var klusterletGVK = schema.GroupVersionKind{Group: "operator.open-cluster-management.io", Version: "v1", Kind: "Klusterlet"}
+
+// Option tunes the cluster health checks
+type Option func(*options)
+
+type options struct {
+	excludedClusterOperators []string
+	controlPlaneOnly         bool
+	workerNodes              []string
+	klusterletRegistration   bool
+}
+
+// WithExcludedClusterOperators skips the given ClusterOperators, e.g. ones intentionally disabled, in the
+// ClusterOperator health check
+func WithExcludedClusterOperators(names ...string) Option {
+	return func(o *options) {
+		o.excludedClusterOperators = append(o.excludedClusterOperators, names...)
+	}
+}
+
+// WithControlPlaneOnly restricts the node and MachineConfigPool health checks to the control plane node, e.g. while the
+// rollout of the additional worker nodes attached to the SNO is held until the upgrade of the control plane completes
+func WithControlPlaneOnly() Option {
+	return func(o *options) {
+		o.controlPlaneOnly = true
+	}
+}
+
+// WithWorkerNodes waits for the given additional worker nodes to have rejoined the cluster, e.g. once they are rolled
+// out after the upgrade of the control plane node
+func WithWorkerNodes(names ...string) Option {
+	return func(o *options) {
+		o.workerNodes = append(o.workerNodes, names...)
+	}
+}
+
+// WithKlusterletRegistration waits for the ACM klusterlet of a managed cluster to be connected to the hub, e.g. once
+// it is restored after the pivot
+func WithKlusterletRegistration() Option {
+	return func(o *options) {
+		o.klusterletRegistration = true
+	}
+}
+
+func HealthChecks(ctx context.Context, c client.Reader, l logr.Logger, opts ...Option) error {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	var failures []string
 
 	clusterOperatorsReady := false
 	clusterServiceVersionsReady := false
 
-	if err := AreClusterOperatorsReady(ctx, c, l); err != nil {
+	if err := AreClusterOperatorsReady(ctx, c, l, o.excludedClusterOperators...); err != nil {
 		l.Info("co health check failure", "error", err.Error())
 		failures = append(failures, err.Error())
 	} else {
 		clusterOperatorsReady = true
 	}
 
-	if err := AreMachineConfigPoolsReady(ctx, c, l); err != nil {
+	var excludedPools []string
+	if o.controlPlaneOnly {
+		excludedPools = append(excludedPools, common.WorkerMachineConfigPool)
+	}
+	if err := AreMachineConfigPoolsReady(ctx, c, l, excludedPools...); err != nil {
 		l.Info("mcp health check failure", "error", err.Error())
 		failures = append(failures, err.Error())
 	}
@@ -64,6 +126,13 @@ func HealthChecks(ctx context.Context, c client.Reader, l logr.Logger) error {
 		failures = append(failures, err.Error())
 	}
 
+	if !o.controlPlaneOnly {
+		if err := AreWorkerNodesReady(ctx, c, l, o.workerNodes...); err != nil {
+			l.Info("worker node health check failure", "error", err.Error())
+			failures = append(failures, err.Error())
+		}
+	}
+
 	if err := AreClusterServiceVersionsReady(ctx, c, l); err != nil {
 		l.Info("csv health check failure", "error", err.Error())
 		failures = append(failures, err.Error())
@@ -81,6 +150,13 @@ func HealthChecks(ctx context.Context, c client.Reader, l logr.Logger) error {
 		failures = append(failures, err.Error())
 	}
 
+	if o.klusterletRegistration {
+		if err := IsKlusterletRegistered(ctx, c, l); err != nil {
+			l.Info("klusterlet health check failure", "error", err.Error())
+			failures = append(failures, err.Error())
+		}
+	}
+
 	if clusterOperatorsReady && clusterServiceVersionsReady {
 		// Only check SriovNetworkNodeState once cluster operators and CSVs are stable
 		if err := IsSriovNetworkNodeReady(ctx, c, l); err != nil {
@@ -220,7 +296,8 @@ func IsClusterVersionReady(ctx context.Context, c client.Reader, l logr.Logger)
 	return nil
 }
 
-func AreMachineConfigPoolsReady(ctx context.Context, c client.Reader, l logr.Logger) error {
+// AreMachineConfigPoolsReady checks that all the MachineConfigPools, but the excluded ones, have all their machines ready
+func AreMachineConfigPoolsReady(ctx context.Context, c client.Reader, l logr.Logger, excluded ...string) error {
 	machineConfigPoolList := mcv1.MachineConfigPoolList{}
 	err := c.List(ctx, &machineConfigPoolList)
 	if err != nil {
@@ -229,6 +306,9 @@ func AreMachineConfigPoolsReady(ctx context.Context, c client.Reader, l logr.Log
 
 	var notready []string
 	for _, mcp := range machineConfigPoolList.Items {
+		if slices.Contains(excluded, mcp.Name) {
+			continue
+		}
 		if mcp.Status.MachineCount != mcp.Status.ReadyMachineCount {
 			notready = append(notready, mcp.Name)
 			l.Info(fmt.Sprintf("mcp not ready: %s", mcp.Name))
@@ -243,7 +323,9 @@ func AreMachineConfigPoolsReady(ctx context.Context, c client.Reader, l logr.Log
 	return nil
 }
 
-func AreClusterOperatorsReady(ctx context.Context, c client.Reader, l logr.Logger) error {
+// AreClusterOperatorsReady checks that all the ClusterOperators, but the excluded ones, are available and neither
+// progressing nor degraded
+func AreClusterOperatorsReady(ctx context.Context, c client.Reader, l logr.Logger, excluded ...string) error {
 	clusterOperatorList := configv1.ClusterOperatorList{}
 	err := c.List(ctx, &clusterOperatorList)
 	if err != nil {
@@ -252,6 +334,10 @@ func AreClusterOperatorsReady(ctx context.Context, c client.Reader, l logr.Logge
 
 	var notready []string
 	for _, co := range clusterOperatorList.Items {
+		if slices.Contains(excluded, co.Name) {
+			l.Info(fmt.Sprintf("Skipping check of excluded co: %s", co.Name))
+			continue
+		}
 		// nolint: gocritic
 		if !getClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorAvailable) {
 			notready = append(notready, co.Name)
@@ -289,7 +375,10 @@ func IsNodeReady(ctx context.Context, c client.Reader, l logr.Logger) error {
 		return fmt.Errorf("failed to get infrastucture CR: %w", err)
 	}
 
-	if infra.Status.InfrastructureTopology != configv1.SingleReplicaTopologyMode {
+	// The infrastructure topology of a SNO with additional workers may be highly available, while its control plane
+	// topology remains single replica
+	if infra.Status.InfrastructureTopology != configv1.SingleReplicaTopologyMode &&
+		infra.Status.ControlPlaneTopology != configv1.SingleReplicaTopologyMode {
 		// This is likely a test environment, so skip the health check.
 		l.Info(fmt.Sprintf("Skipping Node check. InfrastructureTopology is %s. Expected %s", infra.Status.InfrastructureTopology, configv1.SingleReplicaTopologyMode))
 		return nil
@@ -301,9 +390,13 @@ func IsNodeReady(ctx context.Context, c client.Reader, l logr.Logger) error {
 		return fmt.Errorf("failed to get node list: %w", err)
 	}
 
-	// As we would only have one node currently, we don't need to build a list of not-ready nodes.
-	// Instead, we can return on first error.
+	// As we would only have one control plane node, we don't need to build a list of not-ready nodes.
+	// Instead, we can return on first error. The additional worker nodes are checked by AreWorkerNodesReady.
 	for _, node := range nodeList.Items {
+		if lcautils.IsWorkerNode(&node) {
+			continue
+		}
+
 		if !getNodeStatusCondition(node.Status.Conditions, corev1.NodeReady) {
 			msg := fmt.Sprintf("node is not yet ready: %s", node.Name)
 			l.Info(msg)
@@ -335,6 +428,53 @@ func IsNodeReady(ctx context.Context, c client.Reader, l logr.Logger) error {
 	return nil
 }
 
+// AreWorkerNodesReady checks that the additional worker nodes attached to the SNO, including the expected ones, have
+// rejoined the cluster: they are ready and run the kubelet version of the control plane node
+func AreWorkerNodesReady(ctx context.Context, c client.Reader, l logr.Logger, expected ...string) error {
+	nodeList := corev1.NodeList{}
+	if err := c.List(ctx, &nodeList); err != nil {
+		return fmt.Errorf("failed to get node list: %w", err)
+	}
+
+	var controlPlaneKubeletVersion string
+	var workers []corev1.Node
+	for _, node := range nodeList.Items {
+		if lcautils.IsWorkerNode(&node) {
+			workers = append(workers, node)
+		} else {
+			controlPlaneKubeletVersion = node.Status.NodeInfo.KubeletVersion
+		}
+	}
+
+	var notready []string
+	for _, name := range expected {
+		if !slices.ContainsFunc(workers, func(node corev1.Node) bool { return node.Name == name }) {
+			notready = append(notready, fmt.Sprintf("%s (not rejoined)", name))
+		}
+	}
+	for _, node := range workers {
+		switch {
+		case !getNodeStatusCondition(node.Status.Conditions, corev1.NodeReady):
+			notready = append(notready, fmt.Sprintf("%s (not ready)", node.Name))
+		case getNodeStatusCondition(node.Status.Conditions, corev1.NodeNetworkUnavailable):
+			notready = append(notready, fmt.Sprintf("%s (network unavailable)", node.Name))
+		case controlPlaneKubeletVersion != "" && node.Status.NodeInfo.KubeletVersion != controlPlaneKubeletVersion:
+			notready = append(notready, fmt.Sprintf("%s (kubelet %s, control plane kubelet %s)",
+				node.Name, node.Status.NodeInfo.KubeletVersion, controlPlaneKubeletVersion))
+		}
+	}
+
+	if len(notready) != 0 {
+		l.Info(fmt.Sprintf("worker nodes not ready: %s", strings.Join(notready, ", ")))
+		return fmt.Errorf("one or more worker nodes not yet ready: %s", strings.Join(notready, ", "))
+	}
+
+	if len(workers) > 0 {
+		l.Info("Worker nodes ready")
+	}
+	return nil
+}
+
 func getNodeStatusCondition(conditions []corev1.NodeCondition, conditionType corev1.NodeConditionType) bool {
 	for _, condition := range conditions {
 		if condition.Type == conditionType {
@@ -377,6 +517,44 @@ func IsSriovNetworkNodeReady(ctx context.Context, c client.Reader, l logr.Logger
 	return nil
 }
 
+// IsKlusterletRegistered checks that the ACM klusterlet, if any, is connected to the hub. The cluster is not managed if
+// the klusterlet does not exist, passing the health check.
+func IsKlusterletRegistered(ctx context.Context, c client.Reader, l logr.Logger) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(ctx, types.NamespacedName{Name: common.KlusterletCRDName}, crd); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to verify klusterlet CRD exists: %w", err)
+	}
+
+	klusterlet := &unstructured.Unstructured{}
+	klusterlet.SetGroupVersionKind(klusterletGVK)
+	if err := c.Get(ctx, types.NamespacedName{Name: common.KlusterletName}, klusterlet); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get klusterlet: %w", err)
+	}
+
+	conditions, _, err := unstructured.NestedSlice(klusterlet.Object, "status", "conditions")
+	if err != nil {
+		return fmt.Errorf("failed to get klusterlet conditions: %w", err)
+	}
+	for _, item := range conditions {
+		condition, ok := item.(map[string]any)
+		if !ok || condition["type"] != klusterletHubConnectionDegraded {
+			continue
+		}
+		if condition["status"] == string(metav1.ConditionFalse) {
+			l.Info("Klusterlet is registered with the hub")
+			return nil
+		}
+		return fmt.Errorf("klusterlet not connected to the hub: %v", condition["message"])
+	}
+	return fmt.Errorf("klusterlet not yet connected to the hub")
+}
+
 func AreCertificateSigningRequestsReady(ctx context.Context, c client.Reader, l logr.Logger) error {
 	csrList := k8sv1.CertificateSigningRequestList{}
 	if err := c.List(ctx, &csrList); err != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/go-logr/logr"
 	sriovv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	backuprestore "github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	configv1 "github.com/openshift/api/config/v1"
 	mcv1 "github.com/openshift/api/machineconfiguration/v1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
@@ -316,10 +317,11 @@ func Test_clusterOperatorsReady(t *testing.T) {
 		l logr.Logger
 	}
 	tests := []struct {
-		name    string
-		args    args
-		objects []runtime.Object
-		wantErr bool
+		name     string
+		args     args
+		objects  []runtime.Object
+		excluded []string
+		wantErr  bool
 	}{
 		{
 			name: "happy path",
@@ -377,11 +379,29 @@ func Test_clusterOperatorsReady(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "pass when the degraded co is excluded",
+			objects: []runtime.Object{
+				&configv1.ClusterOperator{
+					ObjectMeta: metav1.ObjectMeta{Name: "insights"},
+					Status: configv1.ClusterOperatorStatus{
+						Conditions: []configv1.ClusterOperatorStatusCondition{
+							{
+								Status: configv1.ConditionTrue,
+								Type:   configv1.OperatorDegraded,
+							},
+						},
+					},
+				},
+			},
+			excluded: []string{"insights"},
+			wantErr:  false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.args.c = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(tt.objects...).Build()
-			if err := AreClusterOperatorsReady(context.TODO(), tt.args.c, tt.args.l); (err != nil) != tt.wantErr {
+			if err := AreClusterOperatorsReady(context.TODO(), tt.args.c, tt.args.l, tt.excluded...); (err != nil) != tt.wantErr {
 				t.Errorf("AreClusterOperatorsReady() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -430,6 +450,87 @@ func Test_machineConfigPoolReady(t *testing.T) {
 	}
 }
 
+func Test_machineConfigPoolReadyExcluded(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(
+		&mcv1.MachineConfigPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "master"},
+			Status:     mcv1.MachineConfigPoolStatus{MachineCount: 1, ReadyMachineCount: 1},
+		},
+		&mcv1.MachineConfigPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Status:     mcv1.MachineConfigPoolStatus{MachineCount: 2, ReadyMachineCount: 0},
+		},
+	).Build()
+
+	assert.ErrorContains(t, AreMachineConfigPoolsReady(context.TODO(), c, logr.Discard()), "worker")
+	assert.NoError(t, AreMachineConfigPoolsReady(context.TODO(), c, logr.Discard(), "worker"))
+}
+
+func Test_workerNodesReady(t *testing.T) {
+	node := func(name, kubeletVersion string, ready bool, roles ...string) *v1.Node {
+		labels := map[string]string{}
+		for _, role := range roles {
+			labels[role] = ""
+		}
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+				NodeInfo:   v1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+			},
+		}
+	}
+	controlPlane := node("sno", "v1.29.5", true, NodeRoleControlPlane, NodeRoleMaster, NodeRoleWorker)
+
+	tests := []struct {
+		name       string
+		objects    []runtime.Object
+		expected   []string
+		wantErrMsg string
+	}{
+		{
+			name:    "no worker nodes",
+			objects: []runtime.Object{controlPlane},
+		},
+		{
+			name:     "worker nodes ready",
+			objects:  []runtime.Object{controlPlane, node("worker-0", "v1.29.5", true, NodeRoleWorker)},
+			expected: []string{"worker-0"},
+		},
+		{
+			name:       "worker node not rejoined",
+			objects:    []runtime.Object{controlPlane, node("worker-0", "v1.29.5", true, NodeRoleWorker)},
+			expected:   []string{"worker-0", "worker-1"},
+			wantErrMsg: "one or more worker nodes not yet ready: worker-1 (not rejoined)",
+		},
+		{
+			name:       "worker node not ready",
+			objects:    []runtime.Object{controlPlane, node("worker-0", "v1.29.5", false, NodeRoleWorker)},
+			wantErrMsg: "worker-0 (not ready)",
+		},
+		{
+			name:       "worker node not upgraded",
+			objects:    []runtime.Object{controlPlane, node("worker-0", "v1.28.9", true, NodeRoleWorker)},
+			wantErrMsg: "worker-0 (kubelet v1.28.9, control plane kubelet v1.29.5)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(tt.objects...).Build()
+			err := AreWorkerNodesReady(context.TODO(), c, logr.Discard(), tt.expected...)
+			if tt.wantErrMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErrMsg)
+		})
+	}
+}
+
 func Test_clusterVersionReady(t *testing.T) {
 	type args struct {
 		c client.Reader
@@ -1021,3 +1122,74 @@ func TestAreCertificateSigningRequestsReady(t *testing.T) {
 		})
 	}
 }
+
+func Test_klusterletRegistered(t *testing.T) {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: common.KlusterletCRDName,
+		},
+	}
+	klusterlet := func(status string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{
+			Object: map[string]any{
+				"kind":       klusterletGVK.Kind,
+				"apiVersion": klusterletGVK.Group + "/" + klusterletGVK.Version,
+				"metadata": map[string]any{
+					"name": common.KlusterletName,
+				},
+			},
+		}
+		if status != "" {
+			u.Object["status"] = map[string]any{
+				"conditions": []any{
+					map[string]any{
+						"type":    klusterletHubConnectionDegraded,
+						"status":  status,
+						"message": "Failed to connect to the hub",
+					},
+				},
+			}
+		}
+		return u
+	}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		wantErr bool
+	}{
+		{
+			name:    "happy path",
+			objects: []runtime.Object{crd, klusterlet("False")},
+			wantErr: false,
+		},
+		{
+			name:    "no klusterlet CRD",
+			objects: []runtime.Object{},
+			wantErr: false,
+		},
+		{
+			name:    "no klusterlet",
+			objects: []runtime.Object{crd},
+			wantErr: false,
+		},
+		{
+			name:    "klusterlet not connected to the hub",
+			objects: []runtime.Object{crd, klusterlet("True")},
+			wantErr: true,
+		},
+		{
+			name:    "klusterlet not yet reporting the hub connection",
+			objects: []runtime.Object{crd, klusterlet("")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(tt.objects...).Build()
+			if err := IsKlusterletRegistered(context.TODO(), c, logr.Discard()); (err != nil) != tt.wantErr {
+				t.Errorf("IsKlusterletRegistered() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
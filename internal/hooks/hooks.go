@@ -0,0 +1,169 @@
+package hooks
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+const (
+	// JobKey, ScriptKey and ImageKey are the keys of the hook ConfigMap. A hook is either a Job manifest, or a script
+	// run with an image.
+	JobKey    = "job"
+	ScriptKey = "script"
+	ImageKey  = "image"
+
+	// HookLabel is set on the Job of a hook to the name of the hook
+	HookLabel = "lca.openshift.io/hook"
+
+	// DefaultTimeoutSeconds is the timeout of a hook that does not set timeoutSeconds
+	DefaultTimeoutSeconds = 600
+
+	jobNamePrefix    = "lca-hook-"
+	scriptMountPath  = "/var/lib/lca-hook"
+	scriptVolumeName = "hook-script"
+)
+
+// Phases of a hook reported in the status
+const (
+	PhasePending   = "Pending"
+	PhaseRunning   = "Running"
+	PhaseSucceeded = "Succeeded"
+	PhaseFailed    = "Failed"
+	PhaseTimedOut  = "TimedOut"
+)
+
+// JobName returns the name of the Job of the hook
+func JobName(hook ibuv1.StageHook) string {
+	return jobNamePrefix + hook.Name
+}
+
+// Timeout returns the timeout of the hook in seconds
+func Timeout(hook ibuv1.StageHook) int {
+	if hook.TimeoutSeconds > 0 {
+		return hook.TimeoutSeconds
+	}
+	return DefaultTimeoutSeconds
+}
+
+// IsTerminal returns true if the hook phase is final
+func IsTerminal(phase string) bool {
+	return phase == PhaseSucceeded || phase == PhaseFailed || phase == PhaseTimedOut
+}
+
+// BuildJob renders the Job of the hook from its ConfigMap. The Job runs in the namespace of the ConfigMap, and is
+// given the timeout of the hook as its active deadline.
+func BuildJob(hook ibuv1.StageHook, cm *corev1.ConfigMap) (*batchv1.Job, error) {
+	manifest, hasJob := cm.Data[JobKey]
+	script, hasScript := cm.Data[ScriptKey]
+	if hasJob == hasScript {
+		return nil, fmt.Errorf("configMap %s/%s must define exactly one of the %s and %s keys", cm.Namespace, cm.Name, JobKey, ScriptKey)
+	}
+
+	job := &batchv1.Job{}
+	if hasJob {
+		if err := yaml.UnmarshalStrict([]byte(manifest), job); err != nil {
+			return nil, fmt.Errorf("failed to decode the Job of configMap %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+		if job.Kind != "" && job.Kind != "Job" {
+			return nil, fmt.Errorf("configMap %s/%s key %s must hold a Job, not a %s", cm.Namespace, cm.Name, JobKey, job.Kind)
+		}
+		if len(job.Spec.Template.Spec.Containers) == 0 {
+			return nil, fmt.Errorf("the Job of configMap %s/%s has no containers", cm.Namespace, cm.Name)
+		}
+		if job.Spec.Template.Spec.RestartPolicy == "" {
+			job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		}
+	} else {
+		image := cm.Data[ImageKey]
+		if image == "" {
+			return nil, fmt.Errorf("configMap %s/%s must define the %s key to run the %s", cm.Namespace, cm.Name, ImageKey, ScriptKey)
+		}
+		if script == "" {
+			return nil, fmt.Errorf("the %s of configMap %s/%s is empty", ScriptKey, cm.Namespace, cm.Name)
+		}
+		job = scriptJob(cm, image)
+	}
+
+	job.ObjectMeta = metav1.ObjectMeta{
+		Name:        JobName(hook),
+		Namespace:   cm.Namespace,
+		Labels:      job.Labels,
+		Annotations: job.Annotations,
+	}
+	if job.Labels == nil {
+		job.Labels = map[string]string{}
+	}
+	job.Labels[HookLabel] = hook.Name
+	deadline := int64(Timeout(hook))
+	job.Spec.ActiveDeadlineSeconds = &deadline
+	return job, nil
+}
+
+// scriptJob renders a Job running the script of the ConfigMap with the image
+func scriptJob(cm *corev1.ConfigMap, image string) *batchv1.Job {
+	var backoffLimit int32 = 0
+	var mode int32 = 0o555
+	return &batchv1.Job{
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						common.WorkloadManagementAnnotationKey: common.WorkloadManagementAnnotationValue,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "hook",
+							Image:   image,
+							Command: []string{"/bin/sh", scriptMountPath + "/" + ScriptKey},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: scriptVolumeName, MountPath: scriptMountPath, ReadOnly: true},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+					Volumes: []corev1.Volume{
+						{
+							Name: scriptVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+									Items:                []corev1.KeyToPath{{Key: ScriptKey, Path: ScriptKey}},
+									DefaultMode:          &mode,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// JobPhase returns the phase of the hook from the status of its Job, along with the failure message
+func JobPhase(job *batchv1.Job) (string, string) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return PhaseSucceeded, ""
+		case batchv1.JobFailed:
+			if cond.Reason == "DeadlineExceeded" {
+				return PhaseTimedOut, cond.Message
+			}
+			return PhaseFailed, fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
+		}
+	}
+	return PhaseRunning, ""
+}
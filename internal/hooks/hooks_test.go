@@ -0,0 +1,97 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+)
+
+const testJobManifest = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: ignored
+  labels:
+    app: backup
+spec:
+  template:
+    spec:
+      containers:
+      - name: backup
+        image: quay.io/backup:latest
+        command: ["/backup.sh"]
+`
+
+func TestBuildJob(t *testing.T) {
+	hook := ibuv1.StageHook{Name: "backup", TimeoutSeconds: 120}
+	newCM := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "app"}, Data: data}
+	}
+
+	job, err := BuildJob(hook, newCM(map[string]string{JobKey: testJobManifest}))
+	assert.NoError(t, err)
+	assert.Equal(t, "lca-hook-backup", job.Name)
+	assert.Equal(t, "app", job.Namespace)
+	assert.Equal(t, map[string]string{"app": "backup", HookLabel: "backup"}, job.Labels)
+	assert.Equal(t, int64(120), *job.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	assert.Equal(t, []string{"/backup.sh"}, job.Spec.Template.Spec.Containers[0].Command)
+
+	job, err = BuildJob(ibuv1.StageHook{Name: "script"}, newCM(map[string]string{ScriptKey: "echo done", ImageKey: "quay.io/cli:latest"}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(DefaultTimeoutSeconds), *job.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, "quay.io/cli:latest", job.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, []string{"/bin/sh", "/var/lib/lca-hook/script"}, job.Spec.Template.Spec.Containers[0].Command)
+	assert.Equal(t, "hook", job.Spec.Template.Spec.Volumes[0].ConfigMap.Name)
+
+	for name, data := range map[string]map[string]string{
+		"exactly one of":           {JobKey: testJobManifest, ScriptKey: "echo done"},
+		"must define the image":    {ScriptKey: "echo done"},
+		"is empty":                 {ScriptKey: "", ImageKey: "quay.io/cli:latest"},
+		"must hold a Job":          {JobKey: "kind: Pod\n"},
+		"has no containers":        {JobKey: "kind: Job\n"},
+		"failed to decode the Job": {JobKey: "spec: [\n"},
+	} {
+		_, err := BuildJob(hook, newCM(data))
+		assert.ErrorContains(t, err, name)
+	}
+	_, err = BuildJob(hook, newCM(nil))
+	assert.ErrorContains(t, err, "exactly one of")
+}
+
+func TestJobPhase(t *testing.T) {
+	testcases := []struct {
+		conditions []batchv1.JobCondition
+		phase      string
+		message    string
+	}{
+		{phase: PhaseRunning},
+		{
+			conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionFalse}},
+			phase:      PhaseRunning,
+		},
+		{
+			conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			phase:      PhaseSucceeded,
+		},
+		{
+			conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"}},
+			phase:      PhaseFailed,
+			message:    "BackoffLimitExceeded: Job has reached the specified backoff limit",
+		},
+		{
+			conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded", Message: "Job was active longer than specified deadline"}},
+			phase:      PhaseTimedOut,
+			message:    "Job was active longer than specified deadline",
+		},
+	}
+	for _, tc := range testcases {
+		phase, message := JobPhase(&batchv1.Job{Status: batchv1.JobStatus{Conditions: tc.conditions}})
+		assert.Equal(t, tc.phase, phase)
+		assert.Equal(t, tc.message, message)
+	}
+}
@@ -0,0 +1,204 @@
+package mcdrift
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ignconfig "github.com/coreos/ignition/v2/config"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Annotations set by the MCD on the node
+const (
+	currentConfigAnnotation = "machineconfiguration.openshift.io/currentConfig"
+	desiredConfigAnnotation = "machineconfiguration.openshift.io/desiredConfig"
+	stateAnnotation         = "machineconfiguration.openshift.io/state"
+	reasonAnnotation        = "machineconfiguration.openshift.io/reason"
+
+	stateDone = "Done"
+
+	// defaultFileMode is the mode of the files written by the MCD when the MachineConfig does not set one
+	defaultFileMode = 0o644
+	systemdUnitDir  = "/etc/systemd/system"
+)
+
+// PendingUpdates returns the MachineConfigPools whose rendered MachineConfig is not applied on all their machines, and
+// the nodes whose MCD is not done applying the desired MachineConfig
+func PendingUpdates(ctx context.Context, c client.Reader) ([]string, error) {
+	var pending []string
+
+	mcpList := &mcv1.MachineConfigPoolList{}
+	if err := c.List(ctx, mcpList); err != nil {
+		return nil, fmt.Errorf("failed to list MachineConfigPools: %w", err)
+	}
+	for _, mcp := range mcpList.Items {
+		switch {
+		case mcp.Status.Configuration.Name != mcp.Spec.Configuration.Name:
+			pending = append(pending, fmt.Sprintf("MachineConfigPool %s is updating from %s to %s",
+				mcp.Name, mcp.Status.Configuration.Name, mcp.Spec.Configuration.Name))
+		case mcp.Status.UpdatedMachineCount != mcp.Status.MachineCount:
+			pending = append(pending, fmt.Sprintf("MachineConfigPool %s has %d of %d machines updated",
+				mcp.Name, mcp.Status.UpdatedMachineCount, mcp.Status.MachineCount))
+		case mcp.Status.DegradedMachineCount > 0:
+			pending = append(pending, fmt.Sprintf("MachineConfigPool %s has %d degraded machines",
+				mcp.Name, mcp.Status.DegradedMachineCount))
+		}
+	}
+
+	nodeList := &corev1.NodeList{}
+	if err := c.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for _, node := range nodeList.Items {
+		annotations := node.GetAnnotations()
+		switch {
+		case annotations[currentConfigAnnotation] != annotations[desiredConfigAnnotation]:
+			pending = append(pending, fmt.Sprintf("node %s is updating from %s to %s",
+				node.Name, annotations[currentConfigAnnotation], annotations[desiredConfigAnnotation]))
+		case annotations[stateAnnotation] != stateDone:
+			msg := fmt.Sprintf("node %s MachineConfig state is %s", node.Name, annotations[stateAnnotation])
+			if reason := annotations[reasonAnnotation]; reason != "" {
+				msg += ": " + reason
+			}
+			pending = append(pending, msg)
+		}
+	}
+
+	return pending, nil
+}
+
+// ModifiedFiles returns the files and systemd units of the MachineConfig applied on the node, as recorded in the MCD
+// currentconfig file, whose content or mode under the root directory differs from the MachineConfig, i.e. that were
+// modified outside of the MCO
+func ModifiedFiles(currentConfigFile, root string) ([]string, error) {
+	data, err := os.ReadFile(currentConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read MCD currentconfig: %w", err)
+	}
+	var mc mcv1.MachineConfig
+	if err := json.Unmarshal(data, &mc); err != nil {
+		return nil, fmt.Errorf("unable to parse MCD currentconfig: %w", err)
+	}
+	if mc.Spec.Config.Raw == nil {
+		return nil, fmt.Errorf("unable to find config in MCD currentconfig")
+	}
+	ign, _, err := ignconfig.Parse(mc.Spec.Config.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse ignition config from MCD currentconfig: %w", err)
+	}
+
+	var modified []string
+	for _, file := range ign.Storage.Files {
+		if file.Contents.Source == nil || len(file.Append) > 0 {
+			continue
+		}
+		content, err := decodeSource(*file.Contents.Source, file.Contents.Compression)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode the content of %s: %w", file.Path, err)
+		}
+		if content == nil {
+			// Not a data URL, the content is fetched by ignition on the first boot only
+			continue
+		}
+		mode := defaultFileMode
+		if file.Mode != nil {
+			mode = *file.Mode
+		}
+		if reason := compare(filepath.Join(root, file.Path), content, fs.FileMode(mode)); reason != "" {
+			modified = append(modified, fmt.Sprintf("%s (%s)", file.Path, reason))
+		}
+	}
+
+	for _, unit := range ign.Systemd.Units {
+		unitPath := filepath.Join(systemdUnitDir, unit.Name)
+		if unit.Contents != nil && (unit.Mask == nil || !*unit.Mask) {
+			if reason := compare(filepath.Join(root, unitPath), []byte(*unit.Contents), defaultFileMode); reason != "" {
+				modified = append(modified, fmt.Sprintf("%s (%s)", unitPath, reason))
+			}
+		}
+		for _, dropin := range unit.Dropins {
+			if dropin.Contents == nil {
+				continue
+			}
+			dropinPath := filepath.Join(systemdUnitDir, unit.Name+".d", dropin.Name)
+			if reason := compare(filepath.Join(root, dropinPath), []byte(*dropin.Contents), defaultFileMode); reason != "" {
+				modified = append(modified, fmt.Sprintf("%s (%s)", dropinPath, reason))
+			}
+		}
+	}
+
+	return modified, nil
+}
+
+// compare returns how the file differs from the expected content and mode, or an empty string if it does not
+func compare(path string, content []byte, mode fs.FileMode) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "missing"
+		}
+		return err.Error()
+	}
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		return err.Error()
+	}
+	if !bytes.Equal(actual, content) {
+		return "content differs"
+	}
+	if info.Mode().Perm() != mode.Perm() {
+		return fmt.Sprintf("mode %#o instead of %#o", info.Mode().Perm(), mode.Perm())
+	}
+	return ""
+}
+
+// decodeSource returns the content of a data URL source, or nil if the source is not a data URL
+func decodeSource(source string, compression *string) ([]byte, error) {
+	data, ok := strings.CutPrefix(source, "data:")
+	if !ok {
+		return nil, nil
+	}
+	header, payload, found := strings.Cut(data, ",")
+	if !found {
+		return nil, fmt.Errorf("invalid data URL")
+	}
+
+	var content []byte
+	if strings.HasSuffix(header, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 data URL: %w", err)
+		}
+		content = decoded
+	} else {
+		decoded, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data URL: %w", err)
+		}
+		content = []byte(decoded)
+	}
+
+	if compression != nil && *compression == "gzip" {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip content: %w", err)
+		}
+		defer reader.Close()
+		if content, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("invalid gzip content: %w", err)
+		}
+	}
+	return content, nil
+}
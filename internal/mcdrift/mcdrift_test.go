@@ -0,0 +1,147 @@
+package mcdrift
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPendingUpdates(t *testing.T) {
+	testscheme := runtime.NewScheme()
+	assert.NoError(t, mcv1.AddToScheme(testscheme))
+	assert.NoError(t, corev1.AddToScheme(testscheme))
+
+	newPool := func(name, spec, status string, machines, updated, degraded int32) *mcv1.MachineConfigPool {
+		mcp := &mcv1.MachineConfigPool{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		mcp.Spec.Configuration.Name = spec
+		mcp.Status.Configuration.Name = status
+		mcp.Status.MachineCount = machines
+		mcp.Status.UpdatedMachineCount = updated
+		mcp.Status.DegradedMachineCount = degraded
+		return mcp
+	}
+	newNode := func(name, current, desired, state, reason string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{
+			currentConfigAnnotation: current,
+			desiredConfigAnnotation: desired,
+			stateAnnotation:         state,
+			reasonAnnotation:        reason,
+		}}}
+	}
+
+	tests := []struct {
+		name     string
+		objects  []client.Object
+		expected []string
+	}{
+		{
+			name: "rendered and applied",
+			objects: []client.Object{
+				newPool("master", "rendered-master-1", "rendered-master-1", 1, 1, 0),
+				newNode("sno", "rendered-master-1", "rendered-master-1", stateDone, ""),
+			},
+		},
+		{
+			name: "pending updates",
+			objects: []client.Object{
+				newPool("master", "rendered-master-2", "rendered-master-1", 1, 1, 0),
+				newPool("worker", "rendered-worker-1", "rendered-worker-1", 1, 0, 0),
+				newPool("infra", "rendered-infra-1", "rendered-infra-1", 1, 1, 1),
+				newNode("sno", "rendered-master-1", "rendered-master-2", "Working", ""),
+				newNode("worker-0", "rendered-worker-1", "rendered-worker-1", "Degraded", "unexpected on-disk state"),
+			},
+			expected: []string{
+				"MachineConfigPool infra has 1 degraded machines",
+				"MachineConfigPool master is updating from rendered-master-1 to rendered-master-2",
+				"MachineConfigPool worker has 0 of 1 machines updated",
+				"node sno is updating from rendered-master-1 to rendered-master-2",
+				"node worker-0 MachineConfig state is Degraded: unexpected on-disk state",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(tt.objects...).Build()
+			pending, err := PendingUpdates(context.Background(), c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, pending)
+		})
+	}
+}
+
+func TestModifiedFiles(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte("compressed content\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	currentConfig := `{
+  "apiVersion": "machineconfiguration.openshift.io/v1",
+  "kind": "MachineConfig",
+  "spec": {
+    "config": {
+      "ignition": {"version": "3.2.0"},
+      "storage": {
+        "files": [
+          {"path": "/etc/unchanged.conf", "mode": 420, "contents": {"source": "data:,unchanged%0A"}},
+          {"path": "/etc/compressed.conf", "mode": 384, "contents": {"source": "data:;base64,` +
+		base64.StdEncoding.EncodeToString(compressed.Bytes()) + `", "compression": "gzip"}},
+          {"path": "/etc/modified.conf", "contents": {"source": "data:,original%0A"}},
+          {"path": "/etc/chmod.conf", "mode": 420, "contents": {"source": "data:,mode%0A"}},
+          {"path": "/etc/deleted.conf", "contents": {"source": "data:,deleted%0A"}},
+          {"path": "/etc/remote.conf", "contents": {"source": "https://example.com/remote.conf"}}
+        ]
+      },
+      "systemd": {
+        "units": [
+          {"name": "site.service", "contents": "[Unit]\n", "dropins": [{"name": "10-site.conf", "contents": "[Service]\n"}]},
+          {"name": "masked.service", "mask": true, "contents": "[Unit]\n"}
+        ]
+      }
+    }
+  }
+}`
+	root := t.TempDir()
+	currentConfigFile := filepath.Join(t.TempDir(), "currentconfig")
+	assert.NoError(t, os.WriteFile(currentConfigFile, []byte(currentConfig), 0o600))
+	for path, file := range map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		"/etc/unchanged.conf":                             {"unchanged\n", 0o644},
+		"/etc/compressed.conf":                            {"compressed content\n", 0o600},
+		"/etc/modified.conf":                              {"modified\n", 0o644},
+		"/etc/chmod.conf":                                 {"mode\n", 0o600},
+		"/etc/systemd/system/site.service":                {"[Unit]\n", 0o644},
+		"/etc/systemd/system/site.service.d/10-site.conf": {"[Service]\nUser=root\n", 0o644},
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, path), []byte(file.content), file.mode))
+		assert.NoError(t, os.Chmod(filepath.Join(root, path), file.mode))
+	}
+
+	modified, err := ModifiedFiles(currentConfigFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/etc/modified.conf (content differs)",
+		"/etc/chmod.conf (mode 0600 instead of 0644)",
+		"/etc/deleted.conf (missing)",
+		"/etc/systemd/system/site.service.d/10-site.conf (content differs)",
+	}, modified)
+
+	_, err = ModifiedFiles(filepath.Join(root, "missing"), root)
+	assert.ErrorContains(t, err, "unable to read MCD currentconfig")
+}
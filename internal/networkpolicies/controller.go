@@ -54,6 +54,24 @@ spec:
     - Ingress
 `
 
+const webhookTmpl = `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: controller-manager-webhook
+  namespace: %s
+spec:
+  podSelector:
+    matchLabels:
+      control-plane: controller-manager
+  ingress:
+    - ports:
+      - protocol: TCP
+        port: %d
+  policyTypes:
+    - Ingress
+`
+
 const jobTmpl = `
 apiVersion: networking.k8s.io/v1
 kind: NetworkPolicy
@@ -71,8 +89,9 @@ spec:
 `
 
 type Policy struct {
-	Namespace  string
-	MetricAddr string
+	Namespace   string
+	MetricAddr  string
+	WebhookPort int
 }
 
 func parsePolicy(template string, args ...any) (*networkingv1.NetworkPolicy, error) {
@@ -94,10 +113,11 @@ func (p *Policy) InstallPolicies(cfg *rest.Config) (string, error) {
 	p0, err0 := parsePolicy(controllerTmpl, p.Namespace, parsePort(p.MetricAddr))
 	p1, err1 := parsePolicy(jobTmpl, prep.StaterootSetupJobName, p.Namespace)
 	p2, err2 := parsePolicy(jobTmpl, precache.LcaPrecacheResourceName, p.Namespace)
-	if err := cmp.Or(err0, err1, err2); err != nil {
+	p3, err3 := parsePolicy(webhookTmpl, p.Namespace, p.WebhookPort)
+	if err := cmp.Or(err0, err1, err2, err3); err != nil {
 		return "", fmt.Errorf("failed to create NetworkPolicy from template: %w", err)
 	}
-	policies := []*networkingv1.NetworkPolicy{p0, p1, p2}
+	policies := []*networkingv1.NetworkPolicy{p0, p1, p2, p3}
 
 	ownerRefs := getOwnerReference(c, p.Namespace)
 
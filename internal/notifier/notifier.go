@@ -0,0 +1,291 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+// ConfigMapName is the configmap, in the lifecycle agent namespace, configuring the sink of the CloudEvents. No events
+// are published unless it exists.
+const ConfigMapName = "lca-event-notifier"
+
+// Keys of the notifier configmap
+const (
+	// SinkKey is the type of the sink, http or kafka
+	SinkKey = "sink"
+	// URLKey is the endpoint the events are posted to, or the base URL of the Kafka REST Proxy for the kafka sink
+	URLKey = "url"
+	// TopicKey is the Kafka topic the events are produced to, for the kafka sink
+	TopicKey = "topic"
+	// SourceKey overrides the source of the events, /clusters/<cluster ID> by default
+	SourceKey = "source"
+	// TokenSecretKey names the secret, in the lifecycle agent namespace, holding the bearer token sent to the sink
+	// under its token key
+	TokenSecretKey = "tokenSecret"
+)
+
+// Types of the sink
+const (
+	SinkHTTP  = "http"
+	SinkKafka = "kafka"
+)
+
+// Types of the CloudEvents
+const (
+	EventTypeTransition = "com.openshift.lifecycle-agent.stage.transition"
+	EventTypeCompleted  = "com.openshift.lifecycle-agent.stage.completed"
+	EventTypeFailed     = "com.openshift.lifecycle-agent.stage.failed"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json"
+	kafkaRESTContentType   = "application/vnd.kafka.json.v2+json"
+	tokenSecretDataKey     = "token"
+	defaultTimeout         = 5 * time.Second
+)
+
+// CloudEvent is a CloudEvents 1.0 event in the structured JSON format
+type CloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id"`
+	Source          string         `json:"source"`
+	Type            string         `json:"type"`
+	Subject         string         `json:"subject"`
+	Time            metav1.Time    `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            StageEventData `json:"data"`
+}
+
+// StageEventData is the data of the CloudEvents, describing the condition change of the IBU
+type StageEventData struct {
+	// Stage is the desired stage of the IBU
+	Stage     string `json:"stage"`
+	Condition string `json:"condition"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message,omitempty"`
+	SeedImage string `json:"seedImage,omitempty"`
+	// TargetVersion is the OCP version of the seed image
+	TargetVersion string `json:"targetVersion,omitempty"`
+}
+
+// config is the sink configuration read from the notifier configmap
+type config struct {
+	sink   string
+	url    string
+	topic  string
+	source string
+	token  string
+}
+
+// Notifier publishes the stage transitions, failures and completions of the IBU as CloudEvents to the HTTP endpoint
+// or the Kafka topic configured by the notifier configmap. The delivery is best effort: the events that cannot be
+// delivered are dropped. All methods are no-ops on a nil Notifier.
+type Notifier struct {
+	// Client reads the notifier configuration, and the cluster ID for the default source
+	Client     client.Reader
+	Log        logr.Logger
+	HTTPClient *http.Client
+	// now is a var in order to override it in unit tests
+	now func() time.Time
+}
+
+// NewNotifier returns a Notifier reading its configuration with the client
+func NewNotifier(c client.Reader, log logr.Logger) *Notifier {
+	return &Notifier{
+		Client:     c,
+		Log:        log,
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+		now:        time.Now,
+	}
+}
+
+// NotifyConditionChanges publishes a CloudEvent for each condition added or changed between before and after
+func (n *Notifier) NotifyConditionChanges(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, before, after []metav1.Condition) error {
+	if n == nil {
+		return nil
+	}
+	var changed []metav1.Condition
+	for _, condition := range after {
+		previous := meta.FindStatusCondition(before, condition.Type)
+		if previous != nil && previous.Status == condition.Status && previous.Reason == condition.Reason {
+			continue
+		}
+		changed = append(changed, condition)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	cfg, err := n.readConfig(ctx)
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	for i, condition := range changed {
+		if err := n.publish(ctx, cfg, n.newEvent(cfg.source, ibu, condition)); err != nil {
+			// The later events are dropped as well, rather than delaying the reconcile further
+			return fmt.Errorf("failed to publish %d stage events to the %s sink: %w", len(changed)-i, cfg.sink, err)
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) readConfig(ctx context.Context) (*config, error) {
+	configmap := &corev1.ConfigMap{}
+	if err := n.Client.Get(ctx, types.NamespacedName{Name: ConfigMapName, Namespace: common.LcaNamespace}, configmap); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get the %s configmap: %w", ConfigMapName, err)
+	}
+
+	cfg := &config{
+		sink:   configmap.Data[SinkKey],
+		url:    configmap.Data[URLKey],
+		topic:  configmap.Data[TopicKey],
+		source: configmap.Data[SourceKey],
+	}
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s configmap: %w", ConfigMapName, err)
+	}
+
+	if secretName := configmap.Data[TokenSecretKey]; secretName != "" {
+		secret := &corev1.Secret{}
+		if err := n.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: common.LcaNamespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get the %s token secret: %w", secretName, err)
+		}
+		cfg.token = string(secret.Data[tokenSecretDataKey])
+	}
+
+	if cfg.source == "" {
+		clusterVersion := &configv1.ClusterVersion{}
+		if err := n.Client.Get(ctx, types.NamespacedName{Name: "version"}, clusterVersion); err != nil {
+			return nil, fmt.Errorf("failed to get the cluster ID: %w", err)
+		}
+		cfg.source = "/clusters/" + string(clusterVersion.Spec.ClusterID)
+	}
+	return cfg, nil
+}
+
+func validateConfig(cfg *config) error {
+	switch cfg.sink {
+	case SinkHTTP:
+	case SinkKafka:
+		if cfg.topic == "" {
+			return fmt.Errorf("the %s key is required with the %s sink", TopicKey, SinkKafka)
+		}
+	default:
+		return fmt.Errorf("unsupported sink %q, must be %s or %s", cfg.sink, SinkHTTP, SinkKafka)
+	}
+	if _, err := url.ParseRequestURI(cfg.url); err != nil {
+		return fmt.Errorf("invalid %s: %w", URLKey, err)
+	}
+	return nil
+}
+
+func (n *Notifier) newEvent(source string, ibu *ibuv1.ImageBasedUpgrade, condition metav1.Condition) CloudEvent {
+	eventType := EventTypeTransition
+	switch {
+	case utils.IsFailureReason(condition.Reason):
+		eventType = EventTypeFailed
+	case condition.Reason == string(utils.ConditionReasons.Completed) && condition.Status == metav1.ConditionTrue:
+		eventType = EventTypeCompleted
+	}
+
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              eventID(ibu, condition),
+		Source:          source,
+		Type:            eventType,
+		Subject:         ibu.Name,
+		Time:            metav1.NewTime(n.now().UTC()),
+		DataContentType: "application/json",
+		Data: StageEventData{
+			Stage:         string(ibu.Spec.Stage),
+			Condition:     condition.Type,
+			Status:        string(condition.Status),
+			Reason:        condition.Reason,
+			Message:       condition.Message,
+			SeedImage:     ibu.Spec.SeedImageRef.Image,
+			TargetVersion: ibu.Spec.SeedImageRef.Version,
+		},
+	}
+}
+
+// eventID identifies the condition change, so that a sink can drop the duplicates of an event published again
+func eventID(ibu *ibuv1.ImageBasedUpgrade, condition metav1.Condition) string {
+	return fmt.Sprintf("%s-%s-%d", ibu.UID, condition.Type, condition.LastTransitionTime.Unix())
+}
+
+// publish posts the event to the HTTP endpoint, or produces it to the Kafka topic through the Kafka REST Proxy, keyed
+// by its source so that the events of a cluster are kept in order
+func (n *Notifier) publish(ctx context.Context, cfg *config, event CloudEvent) error {
+	endpoint, contentType := cfg.url, cloudEventsContentType
+	var payload any = event
+	if cfg.sink == SinkKafka {
+		endpoint = cfg.url + "/topics/" + url.PathEscape(cfg.topic)
+		contentType = kafkaRESTContentType
+		payload = map[string]any{"records": []map[string]any{{"key": event.Source, "value": event}}}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if cfg.token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.token)
+	}
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event rejected by %s: %s", endpoint, resp.Status)
+	}
+	n.Log.Info("Published stage event", "type", event.Type, "condition", event.Data.Condition, "reason", event.Data.Reason)
+	return nil
+}
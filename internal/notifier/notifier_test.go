@@ -0,0 +1,204 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+type request struct {
+	path          string
+	contentType   string
+	authorization string
+	body          []byte
+}
+
+func TestNotifyConditionChanges(t *testing.T) {
+	testscheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(testscheme))
+	assert.NoError(t, configv1.AddToScheme(testscheme))
+
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization"), body})
+		if r.URL.Path == "/rejected" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	clusterVersion := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "1234"},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "sink-token", Namespace: common.LcaNamespace},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: common.LcaNamespace}, Data: data}
+	}
+
+	ibu := &ibuv1.ImageBasedUpgrade{
+		ObjectMeta: metav1.ObjectMeta{Name: "upgrade", UID: "5b6f"},
+		Spec: ibuv1.ImageBasedUpgradeSpec{
+			Stage:        ibuv1.Stages.Upgrade,
+			SeedImageRef: ibuv1.SeedImageRef{Image: "quay.io/org/seed:4.16.1", Version: "4.16.1"},
+		},
+	}
+	before := []metav1.Condition{
+		{Type: "PrepCompleted", Status: metav1.ConditionTrue, Reason: "Completed"},
+		{Type: "UpgradeInProgress", Status: metav1.ConditionTrue, Reason: "InProgress", Message: "Exporting"},
+	}
+	transitioned := metav1.NewTime(time.Date(2024, 5, 2, 9, 58, 0, 0, time.UTC))
+	after := []metav1.Condition{
+		{Type: "PrepCompleted", Status: metav1.ConditionTrue, Reason: "Completed"},
+		{Type: "UpgradeInProgress", Status: metav1.ConditionFalse, Reason: "Completed", Message: "Upgrade completed",
+			LastTransitionTime: transitioned},
+		{Type: "UpgradeCompleted", Status: metav1.ConditionTrue, Reason: "Completed", Message: "Upgrade completed",
+			LastTransitionTime: transitioned},
+	}
+	failed := []metav1.Condition{
+		{Type: "UpgradeInProgress", Status: metav1.ConditionFalse, Reason: "Failed", Message: "Upgrade failed",
+			LastTransitionTime: transitioned},
+	}
+
+	tests := []struct {
+		name          string
+		objects       []client.Object
+		after         []metav1.Condition
+		expected      []request
+		expectedTypes []string
+		expectedIDs   []string
+		expectedError string
+	}{
+		{
+			name:    "not configured",
+			objects: []client.Object{clusterVersion},
+			after:   after,
+		},
+		{
+			name: "no condition changes",
+			objects: []client.Object{clusterVersion,
+				newConfigMap(map[string]string{SinkKey: SinkHTTP, URLKey: server.URL + "/events"})},
+			after: before,
+		},
+		{
+			name: "http sink",
+			objects: []client.Object{clusterVersion, token, newConfigMap(map[string]string{
+				SinkKey: SinkHTTP, URLKey: server.URL + "/events", TokenSecretKey: "sink-token"})},
+			after: after,
+			expected: []request{
+				{path: "/events", contentType: cloudEventsContentType, authorization: "Bearer secret"},
+				{path: "/events", contentType: cloudEventsContentType, authorization: "Bearer secret"},
+			},
+			expectedTypes: []string{EventTypeTransition, EventTypeCompleted},
+			expectedIDs:   []string{"5b6f-UpgradeInProgress-1714643880", "5b6f-UpgradeCompleted-1714643880"},
+		},
+		{
+			name: "kafka sink",
+			objects: []client.Object{clusterVersion, newConfigMap(map[string]string{
+				SinkKey: SinkKafka, URLKey: server.URL, TopicKey: "upgrades", SourceKey: "/sites/site-1"})},
+			after:         failed,
+			expected:      []request{{path: "/topics/upgrades", contentType: kafkaRESTContentType}},
+			expectedTypes: []string{EventTypeFailed},
+			expectedIDs:   []string{"5b6f-UpgradeInProgress-1714643880"},
+		},
+		{
+			name: "kafka sink without topic",
+			objects: []client.Object{clusterVersion,
+				newConfigMap(map[string]string{SinkKey: SinkKafka, URLKey: server.URL})},
+			after:         failed,
+			expectedError: "invalid lca-event-notifier configmap: the topic key is required with the kafka sink",
+		},
+		{
+			name: "unsupported sink",
+			objects: []client.Object{clusterVersion,
+				newConfigMap(map[string]string{SinkKey: "amqp", URLKey: server.URL})},
+			after:         failed,
+			expectedError: `unsupported sink "amqp"`,
+		},
+		{
+			name: "rejected",
+			objects: []client.Object{clusterVersion,
+				newConfigMap(map[string]string{SinkKey: SinkHTTP, URLKey: server.URL + "/rejected"})},
+			after:         after,
+			expected:      []request{{path: "/rejected", contentType: cloudEventsContentType}},
+			expectedError: "failed to publish 2 stage events to the http sink",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			c := fake.NewClientBuilder().WithScheme(testscheme).WithObjects(tt.objects...).Build()
+			n := NewNotifier(c, logr.Discard())
+			now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+			n.now = func() time.Time { return now }
+
+			err := n.NotifyConditionChanges(context.Background(), ibu, before, tt.after)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if !assert.Len(t, requests, len(tt.expected)) {
+				return
+			}
+			for i, req := range requests {
+				assert.Equal(t, tt.expected[i].path, req.path)
+				assert.Equal(t, tt.expected[i].contentType, req.contentType)
+				assert.Equal(t, tt.expected[i].authorization, req.authorization)
+				if i >= len(tt.expectedTypes) {
+					continue
+				}
+
+				var event CloudEvent
+				if req.contentType == kafkaRESTContentType {
+					var records struct {
+						Records []struct {
+							Key   string     `json:"key"`
+							Value CloudEvent `json:"value"`
+						} `json:"records"`
+					}
+					assert.NoError(t, json.Unmarshal(req.body, &records))
+					assert.Len(t, records.Records, 1)
+					assert.Equal(t, "/sites/site-1", records.Records[0].Key)
+					event = records.Records[0].Value
+				} else {
+					assert.NoError(t, json.Unmarshal(req.body, &event))
+					assert.Equal(t, "/clusters/1234", event.Source)
+				}
+				assert.Equal(t, "1.0", event.SpecVersion)
+				assert.Equal(t, tt.expectedIDs[i], event.ID)
+				assert.Equal(t, tt.expectedTypes[i], event.Type)
+				assert.Equal(t, "upgrade", event.Subject)
+				assert.True(t, now.Equal(event.Time.Time))
+				assert.Equal(t, "Upgrade", event.Data.Stage)
+				assert.Equal(t, "quay.io/org/seed:4.16.1", event.Data.SeedImage)
+				assert.Equal(t, "4.16.1", event.Data.TargetVersion)
+			}
+		})
+	}
+
+	// A nil notifier publishes nothing
+	var n *Notifier
+	assert.NoError(t, n.NotifyConditionChanges(context.Background(), ibu, before, after))
+}
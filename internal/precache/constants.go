@@ -42,18 +42,25 @@ const (
 	EnvLcaPrecacheImage   string = "PRECACHE_WORKLOAD_IMG"
 	EnvPrecacheSpecFile   string = "PRECACHE_SPEC_FILE"
 	EnvMaxPullThreads     string = "MAX_PULL_THREADS"
+	EnvPullRetries        string = "PULL_RETRIES"
+	EnvPullTimeout        string = "PULL_TIMEOUT_SECONDS"
 	EnvPrecacheBestEffort string = "PRECACHE_BEST_EFFORT"
+	EnvPullSecretPath     string = "PULL_SECRET_PATH"
 )
 
 // Precaching job specs
 const (
-	BackoffLimit    int32 = 0 // Allow 0 automatic retry for failed pre-caching job
+	BackoffLimit    int32 = 2 // Allow automatic retries for an interrupted pre-caching job, which resumes from the persisted progress
 	DefaultMode     int32 = 420
 	RunAsUser       int64 = 0 // Run as root user
 	Privileged      bool  = true
 	HostDirPathType       = corev1.HostPathDirectory
 )
 
+// InterruptedExitCode is the exit code of the pre-caching job stopped by a SIGTERM, which is retried along with the
+// jobs disrupted by the node, e.g. by a reboot, while a job failing otherwise is not
+const InterruptedExitCode int32 = 143
+
 // Resource Limits for Job
 const (
 	RequestResourceCPU    string = "10m"
@@ -83,6 +90,8 @@ const (
 // Default precaching config values
 const (
 	DefaultMaxConcurrentPulls int = 10
+	DefaultPullRetries        int = 5
+	DefaultPullTimeoutSeconds int = 0 // no time limit
 	DefaultNicePriority       int = 0
 	DefaultIoNiceClass            = IoNiceClassBestEffort
 	DefaultIoNicePriority     int = 4
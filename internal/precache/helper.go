@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -97,6 +98,22 @@ func renderJob(config *Config, log logr.Logger, ibu *ibuv1.ImageBasedUpgrade, sc
 		numConcurrentPulls = DefaultMaxConcurrentPulls
 	}
 
+	// Process number of pull retries
+	numPullRetries := config.NumPullRetries
+	if numPullRetries < 1 {
+		log.Info("Precaching invalid configuration [numPullRetries], using default", "spec", numPullRetries,
+			"default", DefaultPullRetries)
+		numPullRetries = DefaultPullRetries
+	}
+
+	// Process pull timeout
+	pullTimeoutSeconds := config.PullTimeoutSeconds
+	if pullTimeoutSeconds < 0 {
+		log.Info("Precaching invalid configuration [pullTimeoutSeconds], using default", "spec", pullTimeoutSeconds,
+			"default", DefaultPullTimeoutSeconds)
+		pullTimeoutSeconds = DefaultPullTimeoutSeconds
+	}
+
 	// Process nice priority
 	nicePriority := config.NicePriority
 	if nicePriority < MinNicePriority || nicePriority > MaxNicePriority {
@@ -143,6 +160,27 @@ func renderJob(config *Config, log logr.Logger, ibu *ibuv1.ImageBasedUpgrade, sc
 		},
 	}...)
 
+	// Only pass the pull tuning to the workload when it differs from the workload defaults
+	if numPullRetries != DefaultPullRetries {
+		precacheEnvVars = append(precacheEnvVars, corev1.EnvVar{Name: EnvPullRetries, Value: strconv.Itoa(numPullRetries)})
+	}
+	if pullTimeoutSeconds != DefaultPullTimeoutSeconds {
+		precacheEnvVars = append(precacheEnvVars, corev1.EnvVar{Name: EnvPullTimeout, Value: strconv.Itoa(pullTimeoutSeconds)})
+	}
+	if config.PullSecretFile != "" {
+		precacheEnvVars = append(precacheEnvVars, corev1.EnvVar{Name: EnvPullSecretPath, Value: config.PullSecretFile})
+	}
+
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(RequestResourceCPU),
+			corev1.ResourceMemory: resource.MustParse(RequestResourceMemory),
+		},
+	}
+	if config.Resources != nil {
+		resources = *config.Resources.DeepCopy()
+	}
+
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      LcaPrecacheResourceName,
@@ -153,6 +191,24 @@ func renderJob(config *Config, log logr.Logger, ibu *ibuv1.ImageBasedUpgrade, sc
 		},
 		Spec: batchv1.JobSpec{
 			BackoffLimit: &backOffLimit,
+			// Only the interrupted jobs are retried, resuming from the persisted progress
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{
+					{
+						Action: batchv1.PodFailurePolicyActionIgnore,
+						OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{
+							{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue},
+						},
+					},
+					{
+						Action: batchv1.PodFailurePolicyActionFailJob,
+						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+							Operator: batchv1.PodFailurePolicyOnExitCodesOpNotIn,
+							Values:   []int32{InterruptedExitCode},
+						},
+					},
+				},
+			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
@@ -182,12 +238,7 @@ func renderJob(config *Config, log logr.Logger, ibu *ibuv1.ImageBasedUpgrade, sc
 									MountPath: PrecachingSpecFilepath,
 								},
 							},
-							Resources: corev1.ResourceRequirements{
-								Requests: corev1.ResourceList{
-									corev1.ResourceCPU:    resource.MustParse(RequestResourceCPU),
-									corev1.ResourceMemory: resource.MustParse(RequestResourceMemory),
-								},
-							},
+							Resources: resources,
 						},
 					},
 					ServiceAccountName: LcaPrecacheServiceAccount,
@@ -298,7 +349,49 @@ func GetPrecacheStatusFileContent() string {
 		if err := json.Unmarshal(data, &curP); err != nil {
 			return "could not unmarshal precache status file"
 		}
-		return fmt.Sprintf("total: %d (pulled: %d, failed: %d)", curP.Total, curP.Pulled, curP.Failed)
+		msg := fmt.Sprintf("total: %d (pulled: %d, failed: %d)", curP.Total, curP.Pulled, curP.Failed)
+		if curP.PulledBytes > 0 {
+			msg = fmt.Sprintf("%s, downloaded: %d MiB", msg, curP.PulledBytes>>20)
+		}
+		if len(curP.InProgressList) > 0 {
+			msg = fmt.Sprintf("%s, currently pulling: %d", msg, len(curP.InProgressList))
+		}
+		return msg
 	}
 	return "No precache status file to read yet."
 }
+
+// GetPrecacheStatus reads the precaching progress tracker for reporting in the IBU status.
+// It returns nil if the progress tracker is not available yet
+func GetPrecacheStatus() *ibuv1.PrecacheStatus {
+	curP, err := LoadProgress(common.PathOutsideChroot(StatusFile))
+	if err != nil {
+		return nil
+	}
+	return progressToStatus(curP)
+}
+
+func progressToStatus(p *Progress) *ibuv1.PrecacheStatus {
+	status := &ibuv1.PrecacheStatus{
+		Total:         p.Total,
+		Pulled:        p.Pulled,
+		Failed:        p.Failed,
+		PulledBytes:   p.PulledBytes,
+		CurrentImages: slices.Sorted(slices.Values(p.InProgressList)),
+	}
+
+	failedImages := slices.Sorted(slices.Values(p.FailedPullList))
+	for _, image := range failedImages {
+		status.FailedPulls = append(status.FailedPulls, ibuv1.FailedPull{
+			Image:  image,
+			Reason: p.FailureReasons[image],
+		})
+	}
+	return status
+}
+
+// IsBestEffort returns true if the precaching is set to best-effort by the PRECACHE_BEST_EFFORT environment variable of
+// the LCA deployment, the images that could not be precached then not failing the Prep stage
+func IsBestEffort() bool {
+	return os.Getenv(EnvPrecacheBestEffort) == "TRUE"
+}
@@ -128,6 +128,23 @@ func getExpectedBaseJob() *batchv1.Job {
 		},
 		Spec: batchv1.JobSpec{
 			BackoffLimit: &backOffLimit,
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{
+					{
+						Action: batchv1.PodFailurePolicyActionIgnore,
+						OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{
+							{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue},
+						},
+					},
+					{
+						Action: batchv1.PodFailurePolicyActionFailJob,
+						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+							Operator: batchv1.PodFailurePolicyOnExitCodesOpNotIn,
+							Values:   []int32{InterruptedExitCode},
+						},
+					},
+				},
+			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
@@ -201,11 +218,12 @@ func getExpectedBaseJob() *batchv1.Job {
 
 func TestRenderJob(t *testing.T) {
 	testCases := []struct {
-		name            string
-		config          *Config
-		expectedError   error
-		expectedArgs    []string
-		expectedEnvVars []corev1.EnvVar
+		name              string
+		config            *Config
+		expectedError     error
+		expectedArgs      []string
+		expectedEnvVars   []corev1.EnvVar
+		expectedResources *corev1.ResourceRequirements
 	}{
 		{
 			name:          "Fully specified, valid precaching config",
@@ -232,6 +250,75 @@ func TestRenderJob(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:          "Pull retries and timeout specified in precaching config",
+			config:        NewConfig([]string{}, []corev1.EnvVar{}, "NumPullRetries", 2, "PullTimeoutSeconds", 600),
+			expectedError: nil,
+			expectedArgs: []string{fmt.Sprintf("nice -n %d ionice -c %d -n %d lca-cli ibu-precache-workload",
+				DefaultNicePriority, DefaultIoNiceClass, DefaultIoNicePriority)},
+			expectedEnvVars: []corev1.EnvVar{
+				{
+					Name:  EnvMaxPullThreads,
+					Value: strconv.Itoa(DefaultMaxConcurrentPulls),
+				},
+				{
+					Name:  EnvPullRetries,
+					Value: "2",
+				},
+				{
+					Name:  EnvPullTimeout,
+					Value: "600",
+				},
+			},
+		},
+		{
+			name:          "Invalid pull retries and timeout in precaching config",
+			config:        NewConfig([]string{}, []corev1.EnvVar{}, "NumPullRetries", 0, "PullTimeoutSeconds", -1),
+			expectedError: nil,
+			expectedArgs: []string{fmt.Sprintf("nice -n %d ionice -c %d -n %d lca-cli ibu-precache-workload",
+				DefaultNicePriority, DefaultIoNiceClass, DefaultIoNicePriority)},
+			expectedEnvVars: []corev1.EnvVar{
+				{
+					Name:  EnvMaxPullThreads,
+					Value: strconv.Itoa(DefaultMaxConcurrentPulls),
+				},
+			},
+		},
+		{
+			name: "Resources specified in precaching config",
+			config: NewConfig([]string{}, []corev1.EnvVar{}, "Resources", &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			}),
+			expectedError: nil,
+			expectedArgs: []string{fmt.Sprintf("nice -n %d ionice -c %d -n %d lca-cli ibu-precache-workload",
+				DefaultNicePriority, DefaultIoNiceClass, DefaultIoNicePriority)},
+			expectedEnvVars: []corev1.EnvVar{
+				{
+					Name:  EnvMaxPullThreads,
+					Value: strconv.Itoa(DefaultMaxConcurrentPulls),
+				},
+			},
+			expectedResources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			},
+		},
+		{
+			name:          "Pull-secret file specified in precaching config",
+			config:        NewConfig([]string{}, []corev1.EnvVar{}, "PullSecretFile", "/var/lib/lca/workspace/precache-pull-secret"),
+			expectedError: nil,
+			expectedArgs: []string{fmt.Sprintf("nice -n %d ionice -c %d -n %d lca-cli ibu-precache-workload",
+				DefaultNicePriority, DefaultIoNiceClass, DefaultIoNicePriority)},
+			expectedEnvVars: []corev1.EnvVar{
+				{
+					Name:  EnvMaxPullThreads,
+					Value: strconv.Itoa(DefaultMaxConcurrentPulls),
+				},
+				{
+					Name:  EnvPullSecretPath,
+					Value: "/var/lib/lca/workspace/precache-pull-secret",
+				},
+			},
+		},
 		{
 			name:          "Only image list provided in precaching config",
 			config:        NewConfig([]string{}, []corev1.EnvVar{}),
@@ -269,6 +356,9 @@ func TestRenderJob(t *testing.T) {
 
 				expectedJob := getExpectedBaseJob()
 				expectedJob.Spec.Template.Spec.Containers[0].Args = tc.expectedArgs
+				if tc.expectedResources != nil {
+					expectedJob.Spec.Template.Spec.Containers[0].Resources = *tc.expectedResources
+				}
 				for _, env := range tc.expectedEnvVars {
 					expectedJob.Spec.Template.Spec.Containers[0].Env = append(expectedJob.Spec.Template.Spec.Containers[0].Env, env)
 				}
@@ -288,3 +378,28 @@ func TestRenderJob(t *testing.T) {
 		})
 	}
 }
+
+func TestProgressToStatus(t *testing.T) {
+	progress := &Progress{
+		Total:          4,
+		Pulled:         1,
+		Failed:         2,
+		PulledBytes:    2048,
+		InProgressList: []string{"quay.io/image4"},
+		FailedPullList: []string{"quay.io/image3", "quay.io/image2"},
+		FailureReasons: map[string]string{"quay.io/image2": "manifest unknown"},
+	}
+
+	expected := &ibuv1.PrecacheStatus{
+		Total:         4,
+		Pulled:        1,
+		Failed:        2,
+		PulledBytes:   2048,
+		CurrentImages: []string{"quay.io/image4"},
+		FailedPulls: []ibuv1.FailedPull{
+			{Image: "quay.io/image2", Reason: "manifest unknown"},
+			{Image: "quay.io/image3"},
+		},
+	}
+	assert.Equal(t, expected, progressToStatus(progress))
+}
@@ -49,6 +49,12 @@ type Config struct {
 	ImageList          []string
 	NumConcurrentPulls int
 
+	// Number of attempts for pulling an image before marking it as failed
+	NumPullRetries int
+
+	// Time limit in seconds for a single image pull attempt, 0 means no limit
+	PullTimeoutSeconds int
+
 	// To run pre-caching job with an adjusted niceness, which affects process scheduling.
 	// Niceness values range from -20 (most favorable to the process) to 19 (least favorable to the process).
 	NicePriority int
@@ -57,6 +63,12 @@ type Config struct {
 	IoNiceClass    int // 0: none, 1: realtime, 2: best-effort, 3: idle
 	IoNicePriority int // priority (0..7) in the specified scheduling class, only for the realtime and best-effort classes
 
+	// CPU and memory requests and limits of the pre-caching job, the default requests are used if unspecified
+	Resources *corev1.ResourceRequirements
+
+	// Path on the host of the registry credentials used to pull the images, the cluster pull-secret if unspecified
+	PullSecretFile string
+
 	// Allow for environment variables to be passed in
 	EnvVars []corev1.EnvVar
 }
@@ -65,9 +77,13 @@ type Config struct {
 // It initializes the Config with default values and updates specific fields using key-value pairs in args.
 // Supported configuration options in args:
 //   - "NumConcurrentPulls" (int): Number of concurrent pulls for pre-caching.
+//   - "NumPullRetries" (int): Number of attempts for pulling an image.
+//   - "PullTimeoutSeconds" (int): Time limit for a single image pull attempt.
 //   - "NicePriority" (int): Nice priority for pre-caching.
 //   - "IoNiceClass" (int): I/O nice class for pre-caching.
 //   - "IoNicePriority" (int): I/O nice priority for pre-caching.
+//   - "Resources" (*corev1.ResourceRequirements): CPU and memory requests and limits for pre-caching.
+//   - "PullSecretFile" (string): Path on the host of the registry credentials for pre-caching.
 //
 // Example usage:
 //
@@ -76,6 +92,8 @@ func NewConfig(imageList []string, envVars []corev1.EnvVar, args ...any) *Config
 	instance := &Config{
 		ImageList:          imageList,
 		NumConcurrentPulls: DefaultMaxConcurrentPulls,
+		NumPullRetries:     DefaultPullRetries,
+		PullTimeoutSeconds: DefaultPullTimeoutSeconds,
 		NicePriority:       DefaultNicePriority,
 		IoNiceClass:        DefaultIoNiceClass,
 		IoNicePriority:     DefaultIoNicePriority,
@@ -90,6 +108,14 @@ func NewConfig(imageList []string, envVars []corev1.EnvVar, args ...any) *Config
 			if NumConcurrentPulls, ok := value.(int); ok {
 				instance.NumConcurrentPulls = NumConcurrentPulls
 			}
+		case "NumPullRetries":
+			if NumPullRetries, ok := value.(int); ok {
+				instance.NumPullRetries = NumPullRetries
+			}
+		case "PullTimeoutSeconds":
+			if PullTimeoutSeconds, ok := value.(int); ok {
+				instance.PullTimeoutSeconds = PullTimeoutSeconds
+			}
 		case "NicePriority":
 			if NicePriority, ok := value.(int); ok {
 				instance.NicePriority = NicePriority
@@ -102,6 +128,14 @@ func NewConfig(imageList []string, envVars []corev1.EnvVar, args ...any) *Config
 			if IoNicePriority, ok := value.(int); ok {
 				instance.IoNicePriority = IoNicePriority
 			}
+		case "Resources":
+			if Resources, ok := value.(*corev1.ResourceRequirements); ok {
+				instance.Resources = Resources
+			}
+		case "PullSecretFile":
+			if PullSecretFile, ok := value.(string); ok {
+				instance.PullSecretFile = PullSecretFile
+			}
 		}
 	}
 
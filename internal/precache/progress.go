@@ -18,27 +18,82 @@ package precache
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"sync"
 
+	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
 )
 
 // Progress represents the progress tracking data for the precaching job
 type Progress struct {
-	Total          int      `json:"total"`
-	Pulled         int      `json:"pulled"`
-	Failed         int      `json:"failed"`
-	FailedPullList []string `json:"failed_pulls"`
+	Total          int               `json:"total"`
+	Pulled         int               `json:"pulled"`
+	Failed         int               `json:"failed"`
+	FailedPullList []string          `json:"failed_pulls"`
+	PulledList     []string          `json:"pulled_list,omitempty"`
+	PulledBytes    int64             `json:"pulled_bytes,omitempty"`
+	InProgressList []string          `json:"in_progress,omitempty"`
+	FailureReasons map[string]string `json:"failure_reasons,omitempty"`
 	mux            sync.Mutex
 }
 
+// maxFailureReasonLength bounds the size of each failure reason, since these are reported in the IBU status
+const maxFailureReasonLength = 256
+
+// Start marks the image as currently being pulled
+func (p *Progress) Start(image string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.InProgressList = append(p.InProgressList, image)
+}
+
+// AddPulledBytes accounts for the size of a successfully pulled image
+func (p *Progress) AddPulledBytes(size int64) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.PulledBytes += size
+}
+
+// SetFailureReason records why the image could not be pulled
+func (p *Progress) SetFailureReason(image, reason string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if len(reason) > maxFailureReasonLength {
+		reason = reason[:maxFailureReasonLength]
+	}
+	if p.FailureReasons == nil {
+		p.FailureReasons = map[string]string{}
+	}
+	p.FailureReasons[image] = reason
+}
+
+// LoadProgress reads a previously persisted progress tracker, used to resume an interrupted precaching job
+func LoadProgress(filename string) (*Progress, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read precache progress file %s: %w", filename, err)
+	}
+
+	p := &Progress{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal precache progress file %s: %w", filename, err)
+	}
+	return p, nil
+}
+
 func (p *Progress) Update(success bool, image string) {
 	p.mux.Lock()
 	defer p.mux.Unlock()
 
+	p.InProgressList = lo.Without(p.InProgressList, image)
 	if success {
 		p.Pulled++
+		p.PulledList = append(p.PulledList, image)
 	} else {
 		p.Failed++
 		if p.FailedPullList == nil {
@@ -58,7 +113,9 @@ func (p *Progress) Log() {
 }
 
 func (p *Progress) Persist(filename string) {
+	p.mux.Lock()
 	data, _ := json.Marshal(p)
+	p.mux.Unlock()
 	if err := os.WriteFile(filename, data, 0o600); err != nil {
 		logrus.Errorf("Failed to update progress file for precaching, err: %v", err)
 	}
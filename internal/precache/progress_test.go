@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this inputFilePath except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package precache
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressPersistAndLoad(t *testing.T) {
+	statusFile := filepath.Join(t.TempDir(), "precache_status.json")
+
+	progress := &Progress{Total: 3}
+	progress.Update(true, "quay.io/image1")
+	progress.Update(false, "quay.io/image2")
+	progress.Update(true, "quay.io/image3")
+	progress.Persist(statusFile)
+
+	loaded, err := LoadProgress(statusFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, loaded.Total)
+	assert.Equal(t, 2, loaded.Pulled)
+	assert.Equal(t, 1, loaded.Failed)
+	assert.Equal(t, []string{"quay.io/image1", "quay.io/image3"}, loaded.PulledList)
+	assert.Equal(t, []string{"quay.io/image2"}, loaded.FailedPullList)
+
+	_, err = LoadProgress(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestProgressTracksInProgressAndFailures(t *testing.T) {
+	progress := &Progress{Total: 2}
+	progress.Start("quay.io/image1")
+	progress.Start("quay.io/image2")
+	assert.Equal(t, []string{"quay.io/image1", "quay.io/image2"}, progress.InProgressList)
+
+	progress.AddPulledBytes(1024)
+	progress.Update(true, "quay.io/image1")
+	progress.SetFailureReason("quay.io/image2", strings.Repeat("x", maxFailureReasonLength+10))
+	progress.Update(false, "quay.io/image2")
+
+	assert.Empty(t, progress.InProgressList)
+	assert.Equal(t, int64(1024), progress.PulledBytes)
+	assert.Len(t, progress.FailureReasons["quay.io/image2"], maxFailureReasonLength)
+}
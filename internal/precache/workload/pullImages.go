@@ -29,12 +29,12 @@ import (
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 )
 
-// MaxRetries is the max number of retries for pulling an image before marking it as failed
-const MaxRetries int = 5
+// MaxRetries is the default max number of retries for pulling an image before marking it as failed
+const MaxRetries int = precache.DefaultPullRetries
 
 // Podman auth-file related constants
 const (
-	EnvAuthFile     string = "PULL_SECRET_PATH"
+	EnvAuthFile     string = precache.EnvPullSecretPath
 	DefaultAuthFile string = "/var/lib/kubelet/config.json"
 )
 
@@ -53,18 +53,37 @@ func CheckPodman() bool {
 	return true
 }
 
-// podmanImgPull pulls the specified image via podman CLI
-func podmanImgPull(image, authFile string) error {
+// podmanImgPull pulls the specified image via podman CLI. A non-zero timeout bounds the pull attempt
+func podmanImgPull(image, authFile string, timeoutSeconds int) error {
 	args := []string{"pull", image}
 	if authFile != "" {
 		args = append(args, []string{"--authfile", authFile}...)
 	}
-	if _, err := Executor.Execute("podman", args...); err != nil {
+
+	command := "podman"
+	if timeoutSeconds > 0 {
+		args = append([]string{fmt.Sprintf("%ds", timeoutSeconds), command}, args...)
+		command = "timeout"
+	}
+	if _, err := Executor.Execute(command, args...); err != nil {
 		return fmt.Errorf("failed podman pull with args %s: %w", args, err)
 	}
 	return nil
 }
 
+// podmanImgSize returns the size in bytes of the specified local image
+func podmanImgSize(image string) (int64, error) {
+	output, err := Executor.Execute("podman", "image", "inspect", "--format", "{{.Size}}", image)
+	if err != nil {
+		return 0, fmt.Errorf("failed podman image inspect for %s: %w", image, err)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse size of image %s: %w", image, err)
+	}
+	return size, nil
+}
+
 func podmanImgExists(image string) bool {
 	args := []string{"image", "exists", image}
 	_, err := Executor.Execute("podman", args...)
@@ -75,17 +94,19 @@ func podmanImgExists(image string) bool {
 }
 
 // pullImage attempts to pull an image via podman CLI
-func pullImage(image, authFile string, progress *precache.Progress) error {
+func pullImage(image, authFile string, progress *precache.Progress, config *PullConfig) error {
+	progress.Start(image)
+	progress.Persist(precache.StatusFile)
 
 	var err error
-	for i := 0; i < MaxRetries; i++ {
-		err = podmanImgPull(image, authFile)
+	for i := 0; i < config.Retries; i++ {
+		err = podmanImgPull(image, authFile, config.TimeoutSeconds)
 		if err == nil {
 			log.Infof("Successfully pulled image: %s", image)
 			break
 		} else {
 			message := fmt.Sprintf("%v", err)
-			log.Infof("Attempt %d/%d: Failed to pull %s: %s", i+1, MaxRetries, image, message)
+			log.Infof("Attempt %d/%d: Failed to pull %s: %s", i+1, config.Retries, image, message)
 			if strings.Contains(message, "manifest unknown") {
 				// no point to retry if we can reach the registry and the digest doesn't exist
 				break
@@ -93,6 +114,15 @@ func pullImage(image, authFile string, progress *precache.Progress) error {
 		}
 	}
 	// update precache progress tracker
+	if err == nil {
+		if size, sizeErr := podmanImgSize(image); sizeErr == nil {
+			progress.AddPulledBytes(size)
+		} else {
+			log.Infof("Unable to determine size of image %s: %v", image, sizeErr)
+		}
+	} else {
+		progress.SetFailureReason(image, err.Error())
+	}
 	progress.Update(err == nil, image)
 
 	// persist progress to file
@@ -118,6 +148,49 @@ func GetAuthFile() (string, error) {
 	return authFile, nil
 }
 
+// PullConfig defines the image pull tuning for the precaching job
+type PullConfig struct {
+	Threads        int
+	Retries        int
+	TimeoutSeconds int
+}
+
+// getEnvInt returns the positive integer value of the given environment variable, or the default value if unset or invalid
+func getEnvInt(name string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 1 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetPullConfig reads the image pull tuning from the environment, as set by the precaching job
+func GetPullConfig() *PullConfig {
+	return &PullConfig{
+		Threads:        getEnvInt(precache.EnvMaxPullThreads, precache.DefaultMaxConcurrentPulls),
+		Retries:        getEnvInt(precache.EnvPullRetries, MaxRetries),
+		TimeoutSeconds: getEnvInt(precache.EnvPullTimeout, precache.DefaultPullTimeoutSeconds),
+	}
+}
+
+// getPreviouslyPulledImages returns the images recorded as pulled by a previous, interrupted, run of the precaching job
+// that are still present in the local container storage
+func getPreviouslyPulledImages(statusFile string) map[string]bool {
+	previouslyPulled := map[string]bool{}
+
+	prevProgress, err := precache.LoadProgress(statusFile)
+	if err != nil {
+		return previouslyPulled
+	}
+
+	for _, image := range prevProgress.PulledList {
+		if podmanImgExists(image) {
+			previouslyPulled[image] = true
+		}
+	}
+	return previouslyPulled
+}
+
 // PullImages pulls a list of images using podman
 func PullImages(precacheSpec []string, authFile string) *precache.Progress {
 
@@ -128,19 +201,33 @@ func PullImages(precacheSpec []string, authFile string) *precache.Progress {
 		Failed: 0,
 	}
 
-	log.Infof("Will attempt to pull %d images", len(precacheSpec))
+	// Resume from the progress persisted by a previous run, if any
+	previouslyPulled := getPreviouslyPulledImages(precache.StatusFile)
+	if len(previouslyPulled) > 0 {
+		log.Infof("Resuming precaching, %d images were already pulled by a previous run", len(previouslyPulled))
+	}
+
+	log.Infof("Will attempt to pull %d images", len(precacheSpec)-len(previouslyPulled))
 
 	// Create wait group and pull images
 	var wg sync.WaitGroup
-	numThreads, err := strconv.Atoi(os.Getenv(precache.EnvMaxPullThreads))
-	if err != nil {
-		numThreads = precache.DefaultMaxConcurrentPulls
+	config := GetPullConfig()
+	threads := make(chan struct{}, config.Threads)
+	log.Infof("Configured precaching job to concurrently pull %d images, with %d attempts per image.", config.Threads, config.Retries)
+	if config.TimeoutSeconds > 0 {
+		log.Infof("Configured precaching job with a %d seconds timeout per pull attempt.", config.TimeoutSeconds)
 	}
-	threads := make(chan struct{}, numThreads)
-	log.Infof("Configured precaching job to concurrently pull %d images.", numThreads)
 
 	// Start pulling images
 	for _, image := range precacheSpec {
+		if previouslyPulled[image] {
+			if size, err := podmanImgSize(image); err == nil {
+				progress.AddPulledBytes(size)
+			}
+			progress.Update(true, image)
+			continue
+		}
+
 		threads <- struct{}{}
 		wg.Add(1)
 		go func(image string) {
@@ -148,7 +235,7 @@ func PullImages(precacheSpec []string, authFile string) *precache.Progress {
 				<-threads
 				wg.Done()
 			}()
-			err := pullImage(image, authFile, progress)
+			err := pullImage(image, authFile, progress, config)
 
 			if err != nil {
 				log.Errorf("Failed to pull image: %s, error: %v", image, err)
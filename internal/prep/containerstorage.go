@@ -0,0 +1,246 @@
+package prep
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pelletier/go-toml"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
+	"github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+const (
+	// StorageConfFile is the configuration file of the container storage
+	StorageConfFile = "/etc/containers/storage.conf"
+
+	defaultStorageDriver    = "overlay"
+	defaultStorageGraphRoot = common.ContainerStoragePath + "/storage"
+)
+
+// storageConfKeys are the keys of the [storage] table reconciled in the new stateroot
+var storageConfKeys = []string{"driver", "graphroot", "imagestore"}
+
+// ContainerStorageMigration is how the container storage configuration of the seed is migrated in the new stateroot
+type ContainerStorageMigration struct {
+	// Config is the container storage configuration of the new stateroot
+	Config seedclusterinfo.ContainerStorageConfig `json:"config"`
+
+	// RelocateFrom is the graph root of the target cluster, when it is not on the shared container storage. The
+	// precached images are relocated from it to the graph root of the new stateroot once precaching completes.
+	RelocateFrom string `json:"relocateFrom,omitempty"`
+}
+
+// ReadContainerStorageConfig reads the container storage configuration from a storage.conf file, defaulting to the
+// containers-storage defaults when the file or the settings are missing
+func ReadContainerStorageConfig(file string) (*seedclusterinfo.ContainerStorageConfig, error) {
+	config := &seedclusterinfo.ContainerStorageConfig{Driver: defaultStorageDriver, GraphRoot: defaultStorageGraphRoot}
+
+	content, err := osReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	storageConf := struct {
+		Storage struct {
+			Driver     string `toml:"driver"`
+			GraphRoot  string `toml:"graphroot"`
+			ImageStore string `toml:"imagestore"`
+		} `toml:"storage"`
+	}{}
+	if err := toml.Unmarshal(content, &storageConf); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	if storageConf.Storage.Driver != "" {
+		config.Driver = storageConf.Storage.Driver
+	}
+	if storageConf.Storage.GraphRoot != "" {
+		config.GraphRoot = filepath.Clean(storageConf.Storage.GraphRoot)
+	}
+	if storageConf.Storage.ImageStore != "" {
+		config.ImageStore = filepath.Clean(storageConf.Storage.ImageStore)
+	}
+	return config, nil
+}
+
+// PlanContainerStorageMigration returns how to migrate the container storage configuration of the new stateroot.
+// The configuration of the target cluster is kept, as the precached images are stored with it, except for a graph
+// root that is not on the shared container storage, whose images are relocated.
+func PlanContainerStorageMigration(cluster *seedclusterinfo.ContainerStorageConfig, sharedStorage string) (*ContainerStorageMigration, error) {
+	if cluster.ImageStore != "" && !isSubPath(cluster.ImageStore, sharedStorage) {
+		return nil, fmt.Errorf("the image store %s is not on the shared container storage %s", cluster.ImageStore, sharedStorage)
+	}
+
+	migration := &ContainerStorageMigration{Config: *cluster}
+	if !isSubPath(cluster.GraphRoot, sharedStorage) {
+		migration.Config.GraphRoot = filepath.Join(sharedStorage, "storage")
+		migration.RelocateFrom = cluster.GraphRoot
+	}
+	return migration, nil
+}
+
+// ContainerStorageDifferences returns the settings changed from one container storage configuration to the other
+func ContainerStorageDifferences(from, to *seedclusterinfo.ContainerStorageConfig) []string {
+	var differences []string
+	for _, setting := range []struct{ name, from, to string }{
+		{"driver", from.Driver, to.Driver},
+		{"graphroot", from.GraphRoot, to.GraphRoot},
+		{"imagestore", from.ImageStore, to.ImageStore},
+	} {
+		if setting.from != setting.to {
+			differences = append(differences, fmt.Sprintf("%s: %q -> %q", setting.name, setting.from, setting.to))
+		}
+	}
+	return differences
+}
+
+// ReconcileStorageConf sets the driver, graphroot and imagestore keys of the [storage] table of a storage.conf file,
+// leaving the rest of the file untouched. An empty setting removes the key.
+func ReconcileStorageConf(file string, config *seedclusterinfo.ContainerStorageConfig) error {
+	content, err := osReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	values := map[string]string{"driver": config.Driver, "graphroot": config.GraphRoot, "imagestore": config.ImageStore}
+	done := map[string]bool{}
+	var lines []string
+	addMissingKeys := func() {
+		for _, key := range storageConfKeys {
+			if !done[key] && values[key] != "" {
+				lines = append(lines, fmt.Sprintf("%s = %q", key, values[key]))
+				done[key] = true
+			}
+		}
+	}
+
+	inStorage, hasStorage := false, false
+	if len(content) > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "[") {
+				if inStorage {
+					addMissingKeys()
+				}
+				inStorage = trimmed == "[storage]"
+				hasStorage = hasStorage || inStorage
+				lines = append(lines, line)
+				continue
+			}
+			if key, ok := storageConfKey(trimmed); inStorage && ok {
+				if !done[key] && values[key] != "" {
+					lines = append(lines, fmt.Sprintf("%s = %q", key, values[key]))
+					done[key] = true
+				}
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+	if inStorage {
+		addMissingKeys()
+	}
+	if !hasStorage {
+		lines = append(lines, "[storage]")
+		addMissingKeys()
+	}
+
+	if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// storageConfKey returns the reconciled key set by a line of the [storage] table, if any
+func storageConfKey(line string) (string, bool) {
+	key, _, found := strings.Cut(line, "=")
+	if !found {
+		return "", false
+	}
+	key = strings.TrimSpace(key)
+	for _, reconciled := range storageConfKeys {
+		if key == reconciled {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// isSubPath returns true when path is dir or is under it
+func isSubPath(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// migrateContainerStorage reconciles the container storage configuration of the new stateroot with the one of the
+// target cluster, and records the relocation of the precached images if it is needed
+func migrateContainerStorage(log logr.Logger, deploymentDir string) error {
+	cluster, err := ReadContainerStorageConfig(common.PathOutsideChroot(StorageConfFile))
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster container storage configuration: %w", err)
+	}
+	migration, err := PlanContainerStorageMigration(cluster, common.ContainerStoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to plan the container storage migration: %w", err)
+	}
+
+	staterootStorageConf := common.PathOutsideChroot(filepath.Join(deploymentDir, StorageConfFile))
+	seed, err := ReadContainerStorageConfig(staterootStorageConf)
+	if err != nil {
+		return fmt.Errorf("failed to get the seed container storage configuration: %w", err)
+	}
+	if differences := ContainerStorageDifferences(seed, &migration.Config); len(differences) > 0 {
+		log.Info("Reconciling the container storage configuration of the new stateroot", "changes", differences)
+		if err := ReconcileStorageConf(staterootStorageConf, &migration.Config); err != nil {
+			return err
+		}
+	}
+
+	if migration.RelocateFrom != "" {
+		log.Info("The precached images will be relocated to the shared container storage",
+			"from", migration.RelocateFrom, "to", migration.Config.GraphRoot)
+		if err := utils.MarshalToFile(migration, common.PathOutsideChroot(common.ContainerStorageMigrationFile)); err != nil {
+			return fmt.Errorf("failed to record the container storage migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// RelocatePrecachedImages copies the precached images to the graph root of the new stateroot, when the graph root of
+// the target cluster is not on the shared container storage
+func RelocatePrecachedImages(log logr.Logger, executor ops.Execute) error {
+	file := common.PathOutsideChroot(common.ContainerStorageMigrationFile)
+	migration := &ContainerStorageMigration{}
+	if err := utils.ReadYamlOrJSONFile(file, migration); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read the container storage migration: %w", err)
+	}
+	if migration.RelocateFrom == "" {
+		return nil
+	}
+
+	log.Info("Relocating the precached images", "from", migration.RelocateFrom, "to", migration.Config.GraphRoot)
+	if _, err := executor.Execute("mkdir", "-p", migration.Config.GraphRoot); err != nil {
+		return fmt.Errorf("failed to create %s: %w", migration.Config.GraphRoot, err)
+	}
+	if _, err := executor.Execute("cp", "-a", "--reflink=auto", migration.RelocateFrom+"/.", migration.Config.GraphRoot); err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", migration.RelocateFrom, migration.Config.GraphRoot, err)
+	}
+
+	if err := os.Remove(file); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", file, err)
+	}
+	return nil
+}
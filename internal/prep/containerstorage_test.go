@@ -0,0 +1,152 @@
+package prep
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+const testStorageConf = `# comment kept as is
+[storage]
+driver = "overlay"
+runroot = "/run/containers/storage"
+graphroot = "/var/lib/containers/storage"
+
+[storage.options]
+additionalimagestores = []
+`
+
+func TestReadContainerStorageConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "storage.conf")
+
+	config, err := ReadContainerStorageConfig(file)
+	assert.NoError(t, err)
+	assert.Equal(t, &seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage"}, config)
+
+	assert.NoError(t, os.WriteFile(file, []byte(`[storage]
+driver = "vfs"
+graphroot = "/var/lib/containers/custom/"
+imagestore = "/var/lib/containers/images"
+`), 0o644))
+	config, err = ReadContainerStorageConfig(file)
+	assert.NoError(t, err)
+	assert.Equal(t, &seedclusterinfo.ContainerStorageConfig{
+		Driver: "vfs", GraphRoot: "/var/lib/containers/custom", ImageStore: "/var/lib/containers/images"}, config)
+
+	assert.NoError(t, os.WriteFile(file, []byte("[storage"), 0o644))
+	_, err = ReadContainerStorageConfig(file)
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestPlanContainerStorageMigration(t *testing.T) {
+	tests := []struct {
+		name           string
+		cluster        seedclusterinfo.ContainerStorageConfig
+		expectedConfig seedclusterinfo.ContainerStorageConfig
+		expectedFrom   string
+		expectedErr    string
+	}{
+		{
+			name:           "graph root on the shared container storage",
+			cluster:        seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage"},
+			expectedConfig: seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage"},
+		},
+		{
+			name:           "graph root outside of the shared container storage",
+			cluster:        seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers-local"},
+			expectedConfig: seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage"},
+			expectedFrom:   "/var/lib/containers-local",
+		},
+		{
+			name: "image store outside of the shared container storage",
+			cluster: seedclusterinfo.ContainerStorageConfig{
+				Driver: "overlay", GraphRoot: "/var/lib/containers/storage", ImageStore: "/var/images"},
+			expectedErr: "the image store /var/images is not on the shared container storage /var/lib/containers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migration, err := PlanContainerStorageMigration(&tt.cluster, "/var/lib/containers")
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedConfig, migration.Config)
+			assert.Equal(t, tt.expectedFrom, migration.RelocateFrom)
+		})
+	}
+}
+
+func TestContainerStorageDifferences(t *testing.T) {
+	seed := &seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage", ImageStore: "/var/lib/containers/images"}
+	cluster := &seedclusterinfo.ContainerStorageConfig{Driver: "vfs", GraphRoot: "/var/lib/containers/storage"}
+	assert.Empty(t, ContainerStorageDifferences(seed, seed))
+	assert.Equal(t, []string{`driver: "overlay" -> "vfs"`, `imagestore: "/var/lib/containers/images" -> ""`},
+		ContainerStorageDifferences(seed, cluster))
+}
+
+func TestReconcileStorageConf(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		config   seedclusterinfo.ContainerStorageConfig
+		expected string
+	}{
+		{
+			name:    "keys updated in place",
+			content: testStorageConf,
+			config:  seedclusterinfo.ContainerStorageConfig{Driver: "vfs", GraphRoot: "/var/lib/containers/custom"},
+			expected: `# comment kept as is
+[storage]
+driver = "vfs"
+runroot = "/run/containers/storage"
+graphroot = "/var/lib/containers/custom"
+
+[storage.options]
+additionalimagestores = []
+`,
+		},
+		{
+			name: "image store added and removed",
+			content: `[storage]
+driver = "overlay"
+imagestore = "/var/lib/containers/images"
+`,
+			config: seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage"},
+			expected: `[storage]
+driver = "overlay"
+graphroot = "/var/lib/containers/storage"
+`,
+		},
+		{
+			name:   "missing file",
+			config: seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage"},
+			expected: `[storage]
+driver = "overlay"
+graphroot = "/var/lib/containers/storage"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "storage.conf")
+			if tt.content != "" {
+				assert.NoError(t, os.WriteFile(file, []byte(tt.content), 0o644))
+			}
+			assert.NoError(t, ReconcileStorageConf(file, &tt.config))
+			content, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+
+			// The reconciled file is read back with the same configuration
+			config, err := ReadContainerStorageConfig(file)
+			assert.NoError(t, err)
+			assert.Equal(t, &tt.config, config)
+		})
+	}
+}
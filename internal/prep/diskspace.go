@@ -0,0 +1,94 @@
+package prep
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+const (
+	// DiskSpaceMinFreePercentDefault is the default percentage of each filesystem that must remain available once
+	// the space required by the Prep stage is used
+	DiskSpaceMinFreePercentDefault = 10
+
+	// SysrootPath is where the new stateroot is deployed
+	SysrootPath = "/sysroot"
+
+	// seedImageExtractionFactor accounts for the seed image archives being extracted into the new stateroot while the
+	// pulled seed image is still present in the container storage
+	seedImageExtractionFactor = 2
+)
+
+// Use a var for syscall.Statfs in order to override it in unit tests
+var syscallStatfs = syscall.Statfs
+
+// DiskSpaceRequirement is the space in bytes required by the Prep stage on the filesystem of a path
+type DiskSpaceRequirement struct {
+	Path     string
+	Required int64
+}
+
+// GetDiskSpaceRequirements computes the space required for the new stateroot and the precached images from the
+// compressed seed image size and the size of the seed cluster images recorded in the seed image
+func GetDiskSpaceRequirements(seedImageSize, containerImagesSize int64) []DiskSpaceRequirement {
+	return []DiskSpaceRequirement{
+		{Path: SysrootPath, Required: seedImageExtractionFactor * seedImageSize},
+		{Path: common.ContainerStoragePath, Required: seedImageSize + containerImagesSize},
+	}
+}
+
+// ValidateDiskSpace checks that the filesystem of each path has enough space available for the requirement, while
+// keeping the configured percentage of the filesystem available. The requirements of paths on the same filesystem
+// are added up.
+func ValidateDiskSpace(requirements []DiskSpaceRequirement, config *ibuv1.DiskSpaceValidation) error {
+	minFreePercent := DiskSpaceMinFreePercentDefault
+	if config != nil && config.MinFreePercent != 0 {
+		minFreePercent = config.MinFreePercent
+	}
+
+	type filesystem struct {
+		paths     []string
+		required  int64
+		available int64
+		size      int64
+	}
+	var filesystems []*filesystem
+	byFsid := map[syscall.Fsid]*filesystem{}
+
+	for _, requirement := range requirements {
+		var stat syscall.Statfs_t
+		if err := syscallStatfs(common.PathOutsideChroot(requirement.Path), &stat); err != nil {
+			return fmt.Errorf("statfs failed for %s: %w", requirement.Path, err)
+		}
+
+		fs, ok := byFsid[stat.Fsid]
+		if !ok {
+			// nolint: gosec
+			fs = &filesystem{
+				available: int64(stat.Bavail) * stat.Bsize,
+				size:      int64(stat.Blocks) * stat.Bsize,
+			}
+			byFsid[stat.Fsid] = fs
+			filesystems = append(filesystems, fs)
+		}
+		fs.paths = append(fs.paths, requirement.Path)
+		fs.required += requirement.Required
+	}
+
+	for _, fs := range filesystems {
+		reserved := fs.size * int64(minFreePercent) / 100
+		if fs.available-fs.required < reserved {
+			return fmt.Errorf("insufficient disk space for %s: %s required, %s available, %s (%d%%) must remain available",
+				strings.Join(fs.paths, " and "), formatBytes(fs.required), formatBytes(fs.available), formatBytes(reserved), minFreePercent)
+		}
+	}
+	return nil
+}
+
+// formatBytes formats the size in GiB, with one decimal
+func formatBytes(size int64) string {
+	return fmt.Sprintf("%.1fGiB", float64(size)/(1<<30))
+}
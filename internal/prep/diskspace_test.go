@@ -0,0 +1,75 @@
+package prep
+
+import (
+	"syscall"
+	"testing"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDiskSpace(t *testing.T) {
+	const gib = 1 << 30
+
+	// both filesystems have 100GiB blocks of 1GiB
+	sysrootFs := syscall.Statfs_t{Bsize: gib, Blocks: 100, Fsid: syscall.Fsid{X__val: [2]int32{1, 0}}}
+	containersFs := syscall.Statfs_t{Bsize: gib, Blocks: 100, Fsid: syscall.Fsid{X__val: [2]int32{2, 0}}}
+
+	tests := []struct {
+		name             string
+		sharedFilesystem bool
+		sysrootAvail     uint64
+		containersAvail  uint64
+		config           *ibuv1.DiskSpaceValidation
+		expectedErr      string
+	}{
+		{
+			name:            "enough space on separate filesystems",
+			sysrootAvail:    30,
+			containersAvail: 30,
+		},
+		{
+			name:            "not enough space in the sysroot",
+			sysrootAvail:    25,
+			containersAvail: 60,
+			expectedErr:     "insufficient disk space for /sysroot: 20.0GiB required, 25.0GiB available, 10.0GiB (10%) must remain available",
+		},
+		{
+			name:            "not enough space with a custom threshold",
+			sysrootAvail:    30,
+			containersAvail: 60,
+			config:          &ibuv1.DiskSpaceValidation{MinFreePercent: 20},
+			expectedErr:     "insufficient disk space for /sysroot: 20.0GiB required, 30.0GiB available, 20.0GiB (20%) must remain available",
+		},
+		{
+			name:             "requirements are added up on a shared filesystem",
+			sharedFilesystem: true,
+			sysrootAvail:     45,
+			expectedErr:      "insufficient disk space for /sysroot and /var/lib/containers: 40.0GiB required, 45.0GiB available, 10.0GiB (10%) must remain available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syscallStatfs = func(path string, buf *syscall.Statfs_t) error {
+				if tt.sharedFilesystem || path == common.PathOutsideChroot(SysrootPath) {
+					*buf = sysrootFs
+					buf.Bavail = tt.sysrootAvail
+				} else {
+					*buf = containersFs
+					buf.Bavail = tt.containersAvail
+				}
+				return nil
+			}
+			defer func() { syscallStatfs = syscall.Statfs }()
+
+			// 10GiB seed image and 10GiB of seed cluster images
+			err := ValidateDiskSpace(GetDiskSpaceRequirements(10*gib, 10*gib), tt.config)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package prep
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
+)
+
+const etcdPeerPort = "2380"
+
+// restoreEtcdSnapshot re-initializes the etcd data dir of the new stateroot from the etcd snapshot carried by a seed
+// image generated with the Excluded etcd content, using the etcdutl binary carried along. The etcd member keeps the
+// name and peer URL of the seed, which are replaced by recert like the rest of the seed identity.
+func restoreEtcdSnapshot(log logr.Logger, ops ops.Ops, mountpoint, staterootPath string) error {
+	snapshot := filepath.Join(mountpoint, common.EtcdSnapshotFileName)
+	if _, err := os.Stat(common.PathOutsideChroot(snapshot)); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat the etcd snapshot %s: %w", snapshot, err)
+	}
+
+	seedInfo, err := seedclusterinfo.ReadSeedClusterInfoFromFile(
+		common.PathOutsideChroot(filepath.Join(mountpoint, common.SeedClusterInfoFileName)))
+	if err != nil {
+		return fmt.Errorf("failed to read the seed cluster info: %w", err)
+	}
+	if seedInfo.SNOHostname == "" || len(seedInfo.NodeIPs) == 0 {
+		return fmt.Errorf("the seed cluster info lacks the hostname or the node IP to restore the etcd snapshot")
+	}
+
+	etcdDir := filepath.Join(staterootPath, "var/lib/etcd")
+	restoreDir := filepath.Join(etcdDir, "restore")
+	// etcdutl refuses to restore into an existing data dir, which is left by a previous failed attempt
+	if err := os.RemoveAll(common.PathOutsideChroot(restoreDir)); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", restoreDir, err)
+	}
+
+	peerURL := "https://" + net.JoinHostPort(seedInfo.NodeIPs[0], etcdPeerPort)
+	log.Info("Re-initializing the etcd data dir from the seed etcd snapshot", "etcdDir", etcdDir)
+	if _, err := ops.RunInHostNamespace(filepath.Join(mountpoint, common.EtcdutlFileName), "snapshot", "restore", snapshot,
+		"--data-dir", restoreDir,
+		"--name", seedInfo.SNOHostname,
+		"--initial-cluster", fmt.Sprintf("%s=%s", seedInfo.SNOHostname, peerURL),
+		"--initial-advertise-peer-urls", peerURL); err != nil {
+		return fmt.Errorf("failed to restore the etcd snapshot: %w", err)
+	}
+
+	if err := os.Rename(common.PathOutsideChroot(filepath.Join(restoreDir, "member")),
+		common.PathOutsideChroot(filepath.Join(etcdDir, "member"))); err != nil {
+		return fmt.Errorf("failed to move the restored etcd member dir: %w", err)
+	}
+	if err := os.RemoveAll(common.PathOutsideChroot(restoreDir)); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", restoreDir, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package prep
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+func TestRestoreEtcdSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockOps := ops.NewMockOps(ctrl)
+	mountpoint := t.TempDir()
+	stateroot := t.TempDir()
+	etcdDir := filepath.Join(stateroot, "var/lib/etcd")
+	assert.NoError(t, os.MkdirAll(etcdDir, 0o700))
+
+	// A seed image carrying the etcd data dir is left alone
+	assert.NoError(t, restoreEtcdSnapshot(logr.Discard(), mockOps, mountpoint, stateroot))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(mountpoint, common.EtcdSnapshotFileName), []byte("snapshot"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(mountpoint, common.SeedClusterInfoFileName),
+		[]byte(`{"sno_hostname":"seed","node_ips":["fd00::10"]}`), 0o600))
+	restoreDir := filepath.Join(etcdDir, "restore")
+	mockOps.EXPECT().RunInHostNamespace(filepath.Join(mountpoint, common.EtcdutlFileName), "snapshot", "restore",
+		filepath.Join(mountpoint, common.EtcdSnapshotFileName), "--data-dir", restoreDir, "--name", "seed",
+		"--initial-cluster", "seed=https://[fd00::10]:2380", "--initial-advertise-peer-urls", "https://[fd00::10]:2380").
+		DoAndReturn(func(string, ...string) (string, error) {
+			return "", os.MkdirAll(filepath.Join(restoreDir, "member", "snap"), 0o700)
+		})
+	assert.NoError(t, restoreEtcdSnapshot(logr.Discard(), mockOps, mountpoint, stateroot))
+	assert.DirExists(t, filepath.Join(etcdDir, "member", "snap"))
+	assert.NoDirExists(t, restoreDir)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(mountpoint, common.SeedClusterInfoFileName), []byte(`{}`), 0o600))
+	assert.ErrorContains(t, restoreEtcdSnapshot(logr.Discard(), mockOps, mountpoint, stateroot), "lacks the hostname")
+}
@@ -0,0 +1,71 @@
+package prep
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/containers/image/v5/docker/reference"
+
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
+)
+
+// storageImage is an image of the container storage, as listed by podman images
+type storageImage struct {
+	Names       []string
+	RepoDigests []string
+}
+
+// MissingPrecachedImages returns the images of the list that are not in the container storage with the given
+// configuration. An image referenced by digest must be stored with that digest, while an image referenced by tag must
+// be stored with that tag.
+func MissingPrecachedImages(executor ops.Execute, config *seedclusterinfo.ContainerStorageConfig, images []string) ([]string, error) {
+	args := []string{"--root", config.GraphRoot}
+	if config.Driver != "" {
+		args = append(args, "--storage-driver", config.Driver)
+	}
+	if config.ImageStore != "" {
+		args = append(args, "--imagestore", config.ImageStore)
+	}
+	args = append(args, "images", "--format", "json")
+	output, err := executor.Execute("podman", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the images of %s: %w", config.GraphRoot, err)
+	}
+
+	var stored []storageImage
+	if err := json.Unmarshal([]byte(output), &stored); err != nil {
+		return nil, fmt.Errorf("unable to parse podman images command output: %w", err)
+	}
+	present := map[string]bool{}
+	for _, image := range stored {
+		for _, name := range append(image.Names, image.RepoDigests...) {
+			present[name] = true
+		}
+	}
+
+	var missing []string
+	for _, image := range images {
+		name, err := storageName(image)
+		if err != nil {
+			return nil, err
+		}
+		if !present[name] {
+			missing = append(missing, image)
+		}
+	}
+	return missing, nil
+}
+
+// storageName returns the name an image is stored with, i.e. its repository along with its digest if it is
+// referenced by digest, or else along with its tag, latest by default
+func storageName(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image %s: %w", image, err)
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		return fmt.Sprintf("%s@%s", named.Name(), digested.Digest()), nil
+	}
+	return reference.TagNameOnly(named).String(), nil
+}
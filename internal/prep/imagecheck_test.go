@@ -0,0 +1,56 @@
+package prep
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
+)
+
+const testPodmanImages = `[
+  {
+    "Id": "1d2b3c",
+    "Names": ["quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64"],
+    "RepoDigests": ["quay.io/openshift-release-dev/ocp-release@sha256:1111111111111111111111111111111111111111111111111111111111111111"]
+  },
+  {
+    "Id": "4e5f6a",
+    "Names": ["docker.io/library/busybox:latest"],
+    "RepoDigests": ["docker.io/library/busybox@sha256:2222222222222222222222222222222222222222222222222222222222222222"]
+  }
+]`
+
+func TestMissingPrecachedImages(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+
+	config := &seedclusterinfo.ContainerStorageConfig{Driver: "overlay", GraphRoot: "/var/lib/containers/storage"}
+	mockExecutor.EXPECT().Execute("podman", "--root", "/var/lib/containers/storage", "--storage-driver", "overlay",
+		"images", "--format", "json").Return(testPodmanImages, nil).Times(2)
+
+	missing, err := MissingPrecachedImages(mockExecutor, config, []string{
+		"quay.io/openshift-release-dev/ocp-release@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64",
+		"busybox",
+		"quay.io/openshift-release-dev/ocp-release@sha256:3333333333333333333333333333333333333333333333333333333333333333",
+		"quay.io/openshift-release-dev/ocp-release:4.16.1-x86_64",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"quay.io/openshift-release-dev/ocp-release@sha256:3333333333333333333333333333333333333333333333333333333333333333",
+		"quay.io/openshift-release-dev/ocp-release:4.16.1-x86_64",
+	}, missing)
+
+	_, err = MissingPrecachedImages(mockExecutor, config, []string{"Invalid:Image"})
+	assert.ErrorContains(t, err, "failed to parse image Invalid:Image")
+
+	config.ImageStore = "/var/lib/containers/images"
+	mockExecutor.EXPECT().Execute("podman", "--root", "/var/lib/containers/storage", "--storage-driver", "overlay",
+		"--imagestore", "/var/lib/containers/images", "images", "--format", "json").Return("", assert.AnError)
+	_, err = MissingPrecachedImages(mockExecutor, config, nil)
+	assert.ErrorContains(t, err, "failed to list the images of /var/lib/containers/storage")
+}
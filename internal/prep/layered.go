@@ -0,0 +1,110 @@
+package prep
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+// A layered seed image is built on top of a base seed image. Instead of the whole ostree repo of the seed SNO, it only
+// includes an ostree static delta from the booted ostree commit of its base seed image, and records the pull-spec of
+// its base seed image both as a label and as a file. The base seed image must be a full seed image.
+
+// GetSeedBootedRef returns the booted ostree commit of the seed SNO, from the rpm-ostree.json of the mounted seed image
+func GetSeedBootedRef(mountpoint string) (string, error) {
+	seedBootedID, err := getBootedStaterootIDFromRPMOstreeJson(filepath.Join(common.PathOutsideChroot(mountpoint), "rpm-ostree.json"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get booted stateroot id: %w", err)
+	}
+	seedBootedDeployment, err := getDeploymentFromDeploymentID(seedBootedID)
+	if err != nil {
+		return "", err
+	}
+	return strings.Split(seedBootedDeployment, ".")[0], nil
+}
+
+// ParseBaseSeedImage returns the base seed image of a layered seed image from the output of podman image inspect, or
+// an empty string for a full seed image
+func ParseBaseSeedImage(inspectOutput string) (string, error) {
+	var inspect []struct {
+		Labels map[string]string `json:"Labels"`
+	}
+	if err := json.Unmarshal([]byte(inspectOutput), &inspect); err != nil {
+		return "", fmt.Errorf("failed to unmarshal seed image inspect output: %w", err)
+	}
+	if len(inspect) == 0 {
+		return "", fmt.Errorf("seed image inspect output is empty")
+	}
+	return inspect[0].Labels[common.SeedBaseImageOCILabel], nil
+}
+
+// readBaseSeedImage returns the base seed image recorded in the mounted seed image, or an empty string for a full seed
+// image
+func readBaseSeedImage(mountpoint string) (string, error) {
+	content, err := osReadFile(filepath.Join(common.PathOutsideChroot(mountpoint), common.SeedBaseImageFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", common.SeedBaseImageFileName, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// extractSeedOstreeRepo extracts the ostree repo of the mounted seed image into ostreeRepo. The ostree repo of a
+// layered seed image is assembled from the ostree repo of its base seed image, which must already be pulled, on top
+// of which the static delta of the layered seed image is applied.
+func extractSeedOstreeRepo(log logr.Logger, ops ops.Ops, mountpoint, ostreeRepo string) error {
+	baseSeedImage, err := readBaseSeedImage(mountpoint)
+	if err != nil {
+		return err
+	}
+	if baseSeedImage == "" {
+		if err := ops.ExtractTarWithSELinux(fmt.Sprintf("%s/ostree.tgz", mountpoint), ostreeRepo); err != nil {
+			return fmt.Errorf("failed to extract ostree.tgz: %w", err)
+		}
+		return nil
+	}
+
+	log.Info("Assembling the ostree repo of the layered seed image", "baseSeedImage", baseSeedImage)
+	if exists, err := ops.ImageExists(baseSeedImage); err != nil || !exists {
+		return fmt.Errorf("the base seed image %s of the layered seed image must be pulled first: %w", baseSeedImage, err)
+	}
+	defer ops.UnmountAndRemoveImage(baseSeedImage)
+
+	baseMountpoint, err := ops.MountImage(baseSeedImage)
+	if err != nil {
+		return fmt.Errorf("failed to mount base seed image: %w", err)
+	}
+	if nested, err := readBaseSeedImage(baseMountpoint); err != nil {
+		return err
+	} else if nested != "" {
+		return fmt.Errorf("the base seed image %s is itself a layered seed image, which is not supported", baseSeedImage)
+	}
+
+	if err := ops.ExtractTarWithSELinux(fmt.Sprintf("%s/ostree.tgz", baseMountpoint), ostreeRepo); err != nil {
+		return fmt.Errorf("failed to extract the ostree.tgz of the base seed image: %w", err)
+	}
+	if _, err := ops.RunInHostNamespace("ostree", "static-delta", "apply-offline", "--repo="+ostreeRepo,
+		filepath.Join(mountpoint, common.SeedOstreeDeltaFileName)); err != nil {
+		return fmt.Errorf("failed to apply the ostree static delta of the layered seed image: %w", err)
+	}
+
+	// A static delta only adds the commit of the layered seed image, reference it so that it is pulled with the repo
+	seedBootedRef, err := GetSeedBootedRef(mountpoint)
+	if err != nil {
+		return err
+	}
+	if _, err := ops.RunInHostNamespace("ostree", "refs", "--repo="+ostreeRepo, "--create=lca/seed", seedBootedRef); err != nil {
+		return fmt.Errorf("failed to reference the commit of the layered seed image: %w", err)
+	}
+	return nil
+}
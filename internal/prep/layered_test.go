@@ -0,0 +1,53 @@
+package prep
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBaseSeedImage(t *testing.T) {
+	testcases := []struct {
+		name          string
+		inspectOutput string
+		expect        string
+		expectErr     bool
+	}{
+		{
+			name:          "layered seed image",
+			inspectOutput: `[{"Id":"abc","Labels":{"com.openshift.lifecycle-agent.seed_format_version":"4","com.openshift.lifecycle-agent.seed_base_image":"quay.io/seeds/base:4.16"}}]`,
+			expect:        "quay.io/seeds/base:4.16",
+		},
+		{
+			name:          "full seed image",
+			inspectOutput: `[{"Id":"abc","Labels":{"com.openshift.lifecycle-agent.seed_format_version":"4"}}]`,
+			expect:        "",
+		},
+		{
+			name:          "no labels",
+			inspectOutput: `[{"Id":"abc"}]`,
+			expect:        "",
+		},
+		{
+			name:          "empty output",
+			inspectOutput: `[]`,
+			expectErr:     true,
+		},
+		{
+			name:          "invalid output",
+			inspectOutput: `not json`,
+			expectErr:     true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := ParseBaseSeedImage(tc.inspectOutput)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, res)
+		})
+	}
+}
@@ -130,10 +130,8 @@ func SetupStateroot(log logr.Logger, ops ops.Ops, ostreeClient ostreeclient.ICli
 		return fmt.Errorf("failed to create ostree repo directory: %w", err)
 	}
 
-	if err := ops.ExtractTarWithSELinux(
-		fmt.Sprintf("%s/ostree.tgz", mountpoint), ostreeRepo,
-	); err != nil {
-		return fmt.Errorf("failed to extract ostree.tgz: %w", err)
+	if err := extractSeedOstreeRepo(log, ops, mountpoint, ostreeRepo); err != nil {
+		return err
 	}
 
 	// example:
@@ -214,6 +212,16 @@ func SetupStateroot(log logr.Logger, ops ops.Ops, ostreeClient ostreeclient.ICli
 		return fmt.Errorf("failed to process etc.deletions: %w", err)
 	}
 
+	if err := restoreEtcdSnapshot(log, ops, mountpoint, common.GetStaterootPath(osname)); err != nil {
+		return err
+	}
+
+	if !ibi {
+		if err := migrateContainerStorage(log, deploymentDir); err != nil {
+			return fmt.Errorf("failed to migrate the container storage configuration: %w", err)
+		}
+	}
+
 	if err := common.CopyOutsideChroot(filepath.Join(mountpoint, common.ContainersListFileName), common.ContainersListFilePath); err != nil {
 		return fmt.Errorf("failed to copy image list file: %w", err)
 	}
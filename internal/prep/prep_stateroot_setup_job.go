@@ -3,6 +3,7 @@ package prep
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -10,6 +11,7 @@ import (
 	"github.com/go-logr/logr"
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/precache"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -25,6 +27,18 @@ import (
 const (
 	StaterootSetupJobName      = "lca-prep-stateroot-setup"
 	staterootSetupJobFinalizer = "lca.openshift.io/stateroot-setup-finalizer"
+
+	// SeedValidationReportFile holds the report of the seed image content validation done by the stateroot setup job,
+	// so that the failed checks can be reported in the Prep condition
+	SeedValidationReportFile = common.LCAConfigDir + "/workspace/seed-validation-report.json"
+
+	// StaterootSetupFailedExitCode is the exit code of the stateroot setup job failing a step, which fails the job
+	StaterootSetupFailedExitCode = 1
+	// StaterootSetupInterruptedExitCode is the exit code of the stateroot setup job stopped by a SIGTERM, run again
+	// from its checkpoints like a job interrupted by a node reboot
+	StaterootSetupInterruptedExitCode = 143
+	// staterootSetupBackoffLimit allows the automatic retries of an interrupted stateroot setup job
+	staterootSetupBackoffLimit int32 = 2
 )
 
 // StaterootSetupTerminationGracePeriodSeconds max time wait before the stateroot job pod gets SIGKILL from k8s. Assuming the seed image is already in the system, the stateroot job should complete within this time.
@@ -69,7 +83,13 @@ func constructJobForStaterootSetup(ctx context.Context, c client.Client, ibu *ib
 		return nil, fmt.Errorf("no 'manager' container found in deployment")
 	}
 
-	var backoffLimit int32 = 0
+	command, args := staterootSetupCommand(ibu.Spec.StaterootSetup)
+	resources := manager.Resources
+	if ibu.Spec.StaterootSetup != nil && ibu.Spec.StaterootSetup.Resources != nil {
+		resources = *ibu.Spec.StaterootSetup.Resources.DeepCopy()
+	}
+
+	backoffLimit := staterootSetupBackoffLimit
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      StaterootSetupJobName,
@@ -80,6 +100,24 @@ func constructJobForStaterootSetup(ctx context.Context, c client.Client, ibu *ib
 		},
 		Spec: batchv1.JobSpec{
 			BackoffLimit: &backoffLimit,
+			// Only the interrupted jobs are retried, resuming from the checkpoints of the steps already completed
+			PodFailurePolicy: &batchv1.PodFailurePolicy{
+				Rules: []batchv1.PodFailurePolicyRule{
+					{
+						Action: batchv1.PodFailurePolicyActionFailJob,
+						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+							Operator: batchv1.PodFailurePolicyOnExitCodesOpIn,
+							Values:   []int32{StaterootSetupFailedExitCode},
+						},
+					},
+					{
+						Action: batchv1.PodFailurePolicyActionIgnore,
+						OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{
+							{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue},
+						},
+					},
+				},
+			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
@@ -92,12 +130,13 @@ func constructJobForStaterootSetup(ctx context.Context, c client.Client, ibu *ib
 							Name:            StaterootSetupJobName,
 							Image:           manager.Image,
 							ImagePullPolicy: manager.ImagePullPolicy,
-							Command:         []string{"lca-cli", "ibu-stateroot-setup"},
+							Command:         command,
+							Args:            args,
 							Env:             manager.Env,
 							EnvFrom:         manager.EnvFrom,
 							SecurityContext: manager.SecurityContext, // this is needed for podman
 							VolumeMounts:    manager.VolumeMounts,
-							Resources:       manager.Resources,
+							Resources:       resources,
 						},
 					},
 					HostPID:                       lcaDeployment.Spec.Template.Spec.HostPID, // this is needed for rpmostree
@@ -122,6 +161,28 @@ func constructJobForStaterootSetup(ctx context.Context, c client.Client, ibu *ib
 	return job, nil
 }
 
+// staterootSetupCommand returns the command and args of the stateroot setup job container, which runs with an adjusted
+// niceness and I/O scheduling when any of them is specified
+func staterootSetupCommand(tuning *ibuv1.WorkloadResources) ([]string, []string) {
+	command := []string{"lca-cli", "ibu-stateroot-setup"}
+	if tuning == nil || tuning.NicePriority == nil && tuning.IoNiceClass == nil && tuning.IoNicePriority == nil {
+		return command, nil
+	}
+
+	nicePriority, ioNiceClass, ioNicePriority := precache.DefaultNicePriority, precache.DefaultIoNiceClass, precache.DefaultIoNicePriority
+	if tuning.NicePriority != nil {
+		nicePriority = *tuning.NicePriority
+	}
+	if tuning.IoNiceClass != nil {
+		ioNiceClass = *tuning.IoNiceClass
+	}
+	if tuning.IoNicePriority != nil {
+		ioNicePriority = *tuning.IoNicePriority
+	}
+	return []string{"sh", "-c", "--"},
+		[]string{fmt.Sprintf("nice -n %d ionice -c %d -n %d %s", nicePriority, ioNiceClass, ioNicePriority, strings.Join(command, " "))}
+}
+
 func getManagerContainer(lcaDeployment appsv1.Deployment) (corev1.Container, bool) {
 	for _, container := range lcaDeployment.Spec.Template.Spec.Containers {
 		if container.Name == "manager" {
@@ -131,8 +192,8 @@ func getManagerContainer(lcaDeployment appsv1.Deployment) (corev1.Container, boo
 	return corev1.Container{}, false
 }
 
-// DeleteStaterootSetupJob delete the stateroot setup job
-func DeleteStaterootSetupJob(ctx context.Context, c client.Client, log logr.Logger) error {
+// StopStaterootSetupJob deletes the stateroot setup job without waiting for its pod to be removed
+func StopStaterootSetupJob(ctx context.Context, c client.Client, log logr.Logger) error {
 	if err := removeStaterootSetupJobFinalizer(ctx, c, log); err != nil {
 		return fmt.Errorf("failed to remove finalizer during cleanup: %w", err)
 	}
@@ -147,6 +208,20 @@ func DeleteStaterootSetupJob(ctx context.Context, c client.Client, log logr.Logg
 			return fmt.Errorf("failed to delete stateroot setup job: %w", err)
 		}
 	}
+	return nil
+}
+
+// DeleteStaterootSetupJob delete the stateroot setup job
+func DeleteStaterootSetupJob(ctx context.Context, c client.Client, log logr.Logger) error {
+	if err := StopStaterootSetupJob(ctx, c, log); err != nil {
+		return err
+	}
+	stateroot := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      StaterootSetupJobName,
+			Namespace: common.LcaNamespace,
+		},
+	}
 
 	log.Info(fmt.Sprintf("Waiting up to additional %s to verify that job's pod no longer exists", time.Duration(StaterootSetupTerminationGracePeriodSeconds)*time.Second), "job", stateroot.GetName())
 	// todo: in most cases we expect this to just go through very quickly...but this is blocking call and can block up to StaterootSetupTerminationGracePeriodSeconds. Should look into using reconcile instead (this may affect other funcs called from Cleanup)
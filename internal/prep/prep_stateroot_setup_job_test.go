@@ -0,0 +1,48 @@
+package prep
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+)
+
+func TestStaterootSetupCommand(t *testing.T) {
+	testcases := []struct {
+		name          string
+		tuning        *ibuv1.WorkloadResources
+		expectCommand []string
+		expectArgs    []string
+	}{
+		{
+			name:          "not specified",
+			expectCommand: []string{"lca-cli", "ibu-stateroot-setup"},
+		},
+		{
+			name:          "only resources specified",
+			tuning:        &ibuv1.WorkloadResources{},
+			expectCommand: []string{"lca-cli", "ibu-stateroot-setup"},
+		},
+		{
+			name:          "niceness specified",
+			tuning:        &ibuv1.WorkloadResources{NicePriority: lo.ToPtr(10)},
+			expectCommand: []string{"sh", "-c", "--"},
+			expectArgs:    []string{"nice -n 10 ionice -c 2 -n 4 lca-cli ibu-stateroot-setup"},
+		},
+		{
+			name:          "I/O scheduling specified",
+			tuning:        &ibuv1.WorkloadResources{IoNiceClass: lo.ToPtr(3), IoNicePriority: lo.ToPtr(7)},
+			expectCommand: []string{"sh", "-c", "--"},
+			expectArgs:    []string{"nice -n 0 ionice -c 3 -n 7 lca-cli ibu-stateroot-setup"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, args := staterootSetupCommand(tc.tuning)
+			assert.Equal(t, tc.expectCommand, command)
+			assert.Equal(t, tc.expectArgs, args)
+		})
+	}
+}
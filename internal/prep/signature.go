@@ -0,0 +1,164 @@
+package prep
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+const (
+	// Keys of the secrets referenced by spec.seedImageRef.signatureVerification
+	SignaturePublicKeySecretKey = "cosign.pub"
+	FulcioCASecretKey           = "fulcio.crt"
+	RekorPublicKeySecretKey     = "rekor.pub"
+
+	// SeedDecryptionKeySecretKey is the key of the secret referenced by spec.seedImageRef.decryptionKeySecretRef
+	SeedDecryptionKeySecretKey = "seedDecryptionKey"
+
+	// SeedSignatureFailureFile records why the seed image signature verification failed, so that the reason can be
+	// reported in the Prep condition once the stateroot setup job fails
+	SeedSignatureFailureFile = common.LCAConfigDir + "/workspace/seed-signature-verification-failure"
+
+	// sigstoreRegistriesConfig enables the lookup of sigstore signatures stored as attachments in the registry
+	sigstoreRegistriesConfig = "default-docker:\n  use-sigstore-attachments: true\n"
+)
+
+// seedImageRepository returns the repository of the seed image, without the transport, tag or digest, which is used
+// as the policy scope
+func seedImageRepository(image string) string {
+	repo := image
+	if i := strings.Index(repo, "://"); i >= 0 {
+		repo = repo[i+len("://"):]
+	}
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo
+}
+
+// BuildSeedSignaturePolicy renders a containers-policy.json(5) requiring a valid sigstore signature for the seed
+// image repository. The secretData holds the content of the keys referenced by the signature verification.
+func BuildSeedSignaturePolicy(image string, verification *ibuv1.SignatureVerification, secretData map[string]string) ([]byte, error) {
+	requirement := map[string]any{
+		"type":           "sigstoreSigned",
+		"signedIdentity": map[string]string{"type": "matchRepository"},
+	}
+
+	encode := func(key string) (string, error) {
+		data, ok := secretData[key]
+		if !ok || data == "" {
+			return "", fmt.Errorf("missing %s for seed image signature verification", key)
+		}
+		return base64.StdEncoding.EncodeToString([]byte(data)), nil
+	}
+
+	switch {
+	case verification.PublicKeySecretRef != nil:
+		keyData, err := encode(SignaturePublicKeySecretKey)
+		if err != nil {
+			return nil, err
+		}
+		requirement["keyData"] = keyData
+	case verification.Keyless != nil:
+		caData, err := encode(FulcioCASecretKey)
+		if err != nil {
+			return nil, err
+		}
+		rekorData, err := encode(RekorPublicKeySecretKey)
+		if err != nil {
+			return nil, err
+		}
+		requirement["fulcio"] = map[string]string{
+			"caData":       caData,
+			"oidcIssuer":   verification.Keyless.OIDCIssuer,
+			"subjectEmail": verification.Keyless.SubjectEmail,
+		}
+		requirement["rekorPublicKeyData"] = rekorData
+	default:
+		return nil, fmt.Errorf("one of publicKeySecretRef or keyless must be set for seed image signature verification")
+	}
+
+	policy := map[string]any{
+		"default": []map[string]string{{"type": "insecureAcceptAnything"}},
+		"transports": map[string]any{
+			"docker": map[string]any{
+				seedImageRepository(image): []map[string]any{requirement},
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal seed image signature policy: %w", err)
+	}
+	return data, nil
+}
+
+// GetSeedSignaturePolicy fetches the keys referenced by the seed image signature verification and renders the
+// signature policy
+func GetSeedSignaturePolicy(ctx context.Context, c client.Client, ibu *ibuv1.ImageBasedUpgrade) ([]byte, error) {
+	verification := ibu.Spec.SeedImageRef.SignatureVerification
+	secretData := map[string]string{}
+
+	getKey := func(secretName, key string) error {
+		data, err := lcautils.GetSecretData(ctx, secretName, common.LcaNamespace, key, c)
+		if err != nil {
+			return fmt.Errorf("failed to get %s from secret %s: %w", key, secretName, err)
+		}
+		secretData[key] = data
+		return nil
+	}
+
+	if verification.PublicKeySecretRef != nil {
+		if err := getKey(verification.PublicKeySecretRef.Name, SignaturePublicKeySecretKey); err != nil {
+			return nil, err
+		}
+	}
+	if verification.Keyless != nil {
+		for _, key := range []string{FulcioCASecretKey, RekorPublicKeySecretKey} {
+			if err := getKey(verification.Keyless.TrustRootSecretRef.Name, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return BuildSeedSignaturePolicy(ibu.Spec.SeedImageRef.Image, verification, secretData)
+}
+
+// WriteSeedSignatureConfig writes the signature policy and the registries.d configuration used to pull the seed image
+// in the given directory, and returns their paths
+func WriteSeedSignatureConfig(policy []byte, dir string) (string, string, error) {
+	policyFile := filepath.Join(dir, "seed-signature-policy.json")
+	if err := os.WriteFile(common.PathOutsideChroot(policyFile), policy, 0o600); err != nil {
+		return "", "", fmt.Errorf("failed to write seed image signature policy to %s: %w", policyFile, err)
+	}
+
+	registriesDir := filepath.Join(dir, "seed-registries.d")
+	if err := os.MkdirAll(common.PathOutsideChroot(registriesDir), 0o700); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", registriesDir, err)
+	}
+	registriesFile := filepath.Join(registriesDir, "sigstore.yaml")
+	if err := os.WriteFile(common.PathOutsideChroot(registriesFile), []byte(sigstoreRegistriesConfig), 0o600); err != nil {
+		return "", "", fmt.Errorf("failed to write sigstore registries configuration to %s: %w", registriesFile, err)
+	}
+
+	return policyFile, registriesDir, nil
+}
+
+// IsSignatureRejectedError checks whether the seed image pull failed because the signature policy rejected the image
+func IsSignatureRejectedError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Source image rejected")
+}
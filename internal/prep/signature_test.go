@@ -0,0 +1,99 @@
+package prep
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedImageRepository(t *testing.T) {
+	testcases := map[string]string{
+		"quay.io/org/seed:4.16.1":                 "quay.io/org/seed",
+		"docker://quay.io/org/seed:4.16.1":        "quay.io/org/seed",
+		"quay.io/org/seed@sha256:abcdef":          "quay.io/org/seed",
+		"registry.local:5000/org/seed":            "registry.local:5000/org/seed",
+		"registry.local:5000/org/seed:4.16":       "registry.local:5000/org/seed",
+		"registry.local:5000/seed:4.16@sha256:ab": "registry.local:5000/seed",
+	}
+	for image, expected := range testcases {
+		assert.Equal(t, expected, seedImageRepository(image), image)
+	}
+}
+
+func TestBuildSeedSignaturePolicy(t *testing.T) {
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	testcases := []struct {
+		name         string
+		verification *ibuv1.SignatureVerification
+		secretData   map[string]string
+		expected     map[string]any
+		expectedErr  string
+	}{
+		{
+			name:         "public key",
+			verification: &ibuv1.SignatureVerification{PublicKeySecretRef: &ibuv1.SecretRef{Name: "seed-key"}},
+			secretData:   map[string]string{SignaturePublicKeySecretKey: "public-key"},
+			expected: map[string]any{
+				"type":           "sigstoreSigned",
+				"signedIdentity": map[string]any{"type": "matchRepository"},
+				"keyData":        b64("public-key"),
+			},
+		},
+		{
+			name: "keyless",
+			verification: &ibuv1.SignatureVerification{
+				Keyless: &ibuv1.KeylessSignatureVerification{
+					TrustRootSecretRef: ibuv1.SecretRef{Name: "sigstore-roots"},
+					OIDCIssuer:         "https://oidc.example.com",
+					SubjectEmail:       "release@example.com",
+				},
+			},
+			secretData: map[string]string{FulcioCASecretKey: "fulcio-ca", RekorPublicKeySecretKey: "rekor-key"},
+			expected: map[string]any{
+				"type":           "sigstoreSigned",
+				"signedIdentity": map[string]any{"type": "matchRepository"},
+				"fulcio": map[string]any{
+					"caData":       b64("fulcio-ca"),
+					"oidcIssuer":   "https://oidc.example.com",
+					"subjectEmail": "release@example.com",
+				},
+				"rekorPublicKeyData": b64("rekor-key"),
+			},
+		},
+		{
+			name: "keyless without rekor key",
+			verification: &ibuv1.SignatureVerification{
+				Keyless: &ibuv1.KeylessSignatureVerification{TrustRootSecretRef: ibuv1.SecretRef{Name: "sigstore-roots"}},
+			},
+			secretData:  map[string]string{FulcioCASecretKey: "fulcio-ca"},
+			expectedErr: "missing rekor.pub for seed image signature verification",
+		},
+		{
+			name:         "no verification method",
+			verification: &ibuv1.SignatureVerification{},
+			expectedErr:  "one of publicKeySecretRef or keyless must be set",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := BuildSeedSignaturePolicy("quay.io/org/seed:4.16.1", tc.verification, tc.secretData)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			var policy struct {
+				Default    []map[string]any                       `json:"default"`
+				Transports map[string]map[string][]map[string]any `json:"transports"`
+			}
+			assert.NoError(t, json.Unmarshal(data, &policy))
+			assert.Equal(t, []map[string]any{{"type": "insecureAcceptAnything"}}, policy.Default)
+			assert.Equal(t, []map[string]any{tc.expected}, policy.Transports["docker"]["quay.io/org/seed"])
+		})
+	}
+}
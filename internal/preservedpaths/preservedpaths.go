@@ -0,0 +1,251 @@
+package preservedpaths
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const (
+	// PreservedPathsPath is the directory, relative to the new stateroot /var, where the user-defined paths are
+	// archived before the pivot
+	PreservedPathsPath = "/opt/preserved-paths"
+	PreservedPathsFile = "preserved-paths.tgz"
+
+	// DefaultMaxSize is the size limit of a preserved path that does not set maxSize
+	DefaultMaxSize = "100Mi"
+	// MaxTotalSize is the size limit of all the preserved paths together
+	MaxTotalSize = "1Gi"
+)
+
+// reservedPaths are managed by the upgrade itself or regenerated on the new stateroot, and cannot be preserved
+var reservedPaths = []string{
+	"/etc/kubernetes",
+	"/var/lib/containers",
+	"/var/lib/etcd",
+	"/var/lib/kubelet",
+	common.LCAConfigDir,
+}
+
+// PreservedPath defines a user-defined /etc or /var file or directory that is backed up before the pivot and restored
+// in the new stateroot. The backup fails if the path is bigger than maxSize, a resource quantity such as 10Mi.
+type PreservedPath struct {
+	Path    string `json:"path"`
+	MaxSize string `json:"maxSize,omitempty"`
+}
+
+// SizeLimitError is returned when the user-defined paths exceed their size limits
+type SizeLimitError struct {
+	ErrMessage string
+}
+
+func (e *SizeLimitError) Error() string {
+	return e.ErrMessage
+}
+
+// IsSizeLimitError returns true if the user-defined paths exceed their size limits
+func IsSizeLimitError(err error) bool {
+	var sizeErr *SizeLimitError
+	return errors.As(err, &sizeErr)
+}
+
+func (p PreservedPath) maxSize() (int64, error) {
+	maxSize := p.MaxSize
+	if maxSize == "" {
+		maxSize = DefaultMaxSize
+	}
+	quantity, err := resource.ParseQuantity(maxSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maxSize %s: %w", maxSize, err)
+	}
+	if quantity.Sign() <= 0 {
+		return 0, fmt.Errorf("maxSize must be positive")
+	}
+	return quantity.Value(), nil
+}
+
+func (p PreservedPath) validate() error {
+	if !filepath.IsAbs(p.Path) || filepath.Clean(p.Path) != p.Path {
+		return fmt.Errorf("path %q must be absolute and clean", p.Path)
+	}
+	if !strings.HasPrefix(p.Path, "/etc/") && !strings.HasPrefix(p.Path, "/var/") {
+		return fmt.Errorf("path %s must be under /etc or /var", p.Path)
+	}
+	for _, reserved := range reservedPaths {
+		if isSubPath(p.Path, reserved) || isSubPath(reserved, p.Path) {
+			return fmt.Errorf("path %s overlaps with %s, which is managed by the upgrade", p.Path, reserved)
+		}
+	}
+	if _, err := p.maxSize(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// isSubPath returns true when path is dir itself or a path under it
+func isSubPath(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// ParsePreservedPaths extracts the user-defined preserved paths from the configmaps. Each data entry holds a yaml
+// list of PreservedPath
+func ParsePreservedPaths(configmaps []corev1.ConfigMap) ([]PreservedPath, error) {
+	var paths []PreservedPath
+	var errs []string
+
+	for _, cm := range configmaps {
+		// sort the keys to keep the paths order stable
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			var entries []PreservedPath
+			if err := yaml.UnmarshalStrict([]byte(cm.Data[key]), &entries); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to decode preserved paths in configMap %s/%s key %s: %s", cm.Namespace, cm.Name, key, err))
+				continue
+			}
+			for i, entry := range entries {
+				if err := entry.validate(); err != nil {
+					errs = append(errs, fmt.Sprintf("invalid preserved path %d in configMap %s/%s key %s: %s", i+1, cm.Namespace, cm.Name, key, err))
+					continue
+				}
+				paths = append(paths, entry)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return paths, nil
+}
+
+// ValidatePreservedPathsConfigmaps verifies that the preserved paths configmaps exist and hold valid paths
+func ValidatePreservedPathsConfigmaps(ctx context.Context, c client.Client, content []ibuv1.ConfigMapRef) error {
+	configmaps, err := common.GetConfigMaps(ctx, c, content)
+	if err != nil {
+		return fmt.Errorf("failed to get preserved paths configMaps: %w", err)
+	}
+	if _, err := ParsePreservedPaths(configmaps); err != nil {
+		return fmt.Errorf("failed to parse preserved paths configMaps: %w", err)
+	}
+	return nil
+}
+
+// pathSize returns the total size of the files under the given path, or -1 if the path does not exist
+func pathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to get file info: %w", err)
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk %s: %w", path, err)
+	}
+	return size, nil
+}
+
+// ExportPreservedPathsToDir archives the user-defined preserved paths from the configmaps to the given directory so
+// that they can be restored after the pivot. The paths missing on the host are skipped, and a SizeLimitError is
+// returned if the paths exceed their size limits.
+func ExportPreservedPathsToDir(ctx context.Context, c client.Client, hostOps ops.Ops, log logr.Logger,
+	content []ibuv1.ConfigMapRef, toDir string) error {
+	if len(content) == 0 {
+		return nil
+	}
+
+	configmaps, err := common.GetConfigMaps(ctx, c, content)
+	if err != nil {
+		return fmt.Errorf("failed to get preserved paths configMaps: %w", err)
+	}
+	paths, err := ParsePreservedPaths(configmaps)
+	if err != nil {
+		return fmt.Errorf("failed to parse preserved paths configMaps: %w", err)
+	}
+
+	maxTotalSize := resource.MustParse(MaxTotalSize)
+	var totalSize int64
+	var archived, tooBig []string
+	for _, p := range paths {
+		size, err := pathSize(common.PathOutsideChroot(p.Path))
+		if err != nil {
+			return err
+		}
+		if size < 0 {
+			log.Info("Preserved path does not exist, skipping", "path", p.Path)
+			continue
+		}
+		// the limit was validated when parsing the configmaps
+		maxSize, _ := p.maxSize()
+		if size > maxSize {
+			tooBig = append(tooBig, fmt.Sprintf("%s (%d bytes, maxSize %d bytes)", p.Path, size, maxSize))
+		}
+		totalSize += size
+		// tar paths are relative to / so that they are restored in place
+		archived = append(archived, strings.TrimPrefix(p.Path, "/"))
+	}
+
+	if len(tooBig) > 0 {
+		return &SizeLimitError{
+			ErrMessage: fmt.Sprintf("one or more preserved paths exceed their size limit: %s", strings.Join(tooBig, ", ")),
+		}
+	}
+	if totalSize > maxTotalSize.Value() {
+		return &SizeLimitError{
+			ErrMessage: fmt.Sprintf("preserved paths total %d bytes, exceeding the limit of %s", totalSize, MaxTotalSize),
+		}
+	}
+	if len(archived) == 0 {
+		log.Info("None of the preserved paths exist, nothing to archive")
+		return nil
+	}
+
+	dir := filepath.Join(toDir, PreservedPathsPath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for preserved paths in %s: %w", dir, err)
+	}
+	hostDir, err := common.PathInsideChroot(dir)
+	if err != nil {
+		return fmt.Errorf("failed to get host path of %s: %w", dir, err)
+	}
+
+	tarArgs := []string{"czf", filepath.Join(hostDir, PreservedPathsFile), "-C", "/"}
+	tarArgs = append(tarArgs, archived...)
+	tarArgs = append(tarArgs, common.TarOpts...)
+	if _, err := hostOps.RunInHostNamespace("tar", tarArgs...); err != nil {
+		return fmt.Errorf("failed to archive preserved paths: %w", err)
+	}
+
+	log.Info("Preserved paths archived", "count", len(archived), "size", totalSize)
+	return nil
+}
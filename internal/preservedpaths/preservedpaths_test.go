@@ -0,0 +1,155 @@
+package preservedpaths
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+func TestParsePreservedPaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       map[string]string
+		expected   []PreservedPath
+		wantErrMsg string
+	}{
+		{
+			name: "valid paths",
+			data: map[string]string{
+				"b-agent": `
+- path: /var/lib/agent
+  maxSize: 200Mi
+`,
+				"a-scripts": `
+- path: /etc/custom-scripts
+- path: /var/usrlocal/bin/collect.sh
+`,
+			},
+			expected: []PreservedPath{
+				{Path: "/etc/custom-scripts"},
+				{Path: "/var/usrlocal/bin/collect.sh"},
+				{Path: "/var/lib/agent", MaxSize: "200Mi"},
+			},
+		},
+		{
+			name:       "relative path",
+			data:       map[string]string{"paths": "- path: etc/custom-scripts\n"},
+			wantErrMsg: "must be absolute and clean",
+		},
+		{
+			name:       "unclean path",
+			data:       map[string]string{"paths": "- path: /etc/../root\n"},
+			wantErrMsg: "must be absolute and clean",
+		},
+		{
+			name:       "path outside of etc and var",
+			data:       map[string]string{"paths": "- path: /usr/local/bin\n"},
+			wantErrMsg: "must be under /etc or /var",
+		},
+		{
+			name:       "whole var",
+			data:       map[string]string{"paths": "- path: /var\n"},
+			wantErrMsg: "must be under /etc or /var",
+		},
+		{
+			name:       "reserved path",
+			data:       map[string]string{"paths": "- path: /var/lib/kubelet/pods\n"},
+			wantErrMsg: "overlaps with /var/lib/kubelet",
+		},
+		{
+			name:       "path containing a reserved path",
+			data:       map[string]string{"paths": "- path: /var/lib\n"},
+			wantErrMsg: "overlaps with /var/lib/containers",
+		},
+		{
+			name:       "invalid maxSize",
+			data:       map[string]string{"paths": "- path: /etc/custom-scripts\n  maxSize: big\n"},
+			wantErrMsg: "invalid maxSize big",
+		},
+		{
+			name:       "unknown field",
+			data:       map[string]string{"paths": "- path: /etc/custom-scripts\n  recursive: true\n"},
+			wantErrMsg: "failed to decode preserved paths in configMap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "paths", Namespace: "default"}, Data: tt.data}
+			paths, err := ParsePreservedPaths([]corev1.ConfigMap{cm})
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+}
+
+func TestPathSize(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a"), make([]byte, 10), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 20), 0o600))
+
+	size, err := pathSize(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), size)
+
+	size, err = pathSize(filepath.Join(dir, "a"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), size)
+
+	size, err = pathSize(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), size)
+}
+
+func TestExportPreservedPathsToDir(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockOps := ops.NewMockOps(mockController)
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	cms := []ibuv1.ConfigMapRef{{Name: "paths", Namespace: "default"}}
+	newClient := func(data string) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "paths", Namespace: "default"},
+			Data:       map[string]string{"paths": data},
+		}).Build()
+	}
+
+	// Nothing to export
+	assert.NoError(t, ExportPreservedPathsToDir(context.TODO(), nil, mockOps, logr.Discard(), nil, t.TempDir()))
+
+	toDir := t.TempDir()
+	c := newClient("- path: /etc/hosts\n- path: /etc/lca-missing-path\n")
+	mockOps.EXPECT().RunInHostNamespace("tar", "czf", filepath.Join(toDir, PreservedPathsPath, PreservedPathsFile),
+		"-C", "/", "etc/hosts", "--selinux", "--xattrs", "--xattrs-include=*", "--acls").Return("", nil)
+	assert.NoError(t, ExportPreservedPathsToDir(context.TODO(), c, mockOps, logr.Discard(), cms, toDir))
+	assert.DirExists(t, filepath.Join(toDir, PreservedPathsPath))
+
+	// Only missing paths
+	c = newClient("- path: /etc/lca-missing-path\n")
+	assert.NoError(t, ExportPreservedPathsToDir(context.TODO(), c, mockOps, logr.Discard(), cms, t.TempDir()))
+
+	// Over the size limit
+	c = newClient("- path: /etc/hosts\n  maxSize: \"1\"\n")
+	err := ExportPreservedPathsToDir(context.TODO(), c, mockOps, logr.Discard(), cms, t.TempDir())
+	assert.True(t, IsSizeLimitError(err))
+	assert.ErrorContains(t, err, "one or more preserved paths exceed their size limit: /etc/hosts")
+}
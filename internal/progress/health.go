@@ -0,0 +1,130 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// AgentState is the functional state of the agent, reported by the health endpoints
+type AgentState string
+
+const (
+	// StateIdle is when no IBU reconcile is in progress
+	StateIdle AgentState = "Idle"
+	// StateReconciling is when an IBU reconcile is in progress
+	StateReconciling AgentState = "Reconciling"
+	// StatePreparingPivot is when the Upgrade stage exports the cluster configuration to the new stateroot, before
+	// rebooting to it
+	StatePreparingPivot AgentState = "PreparingPivot"
+	// StateAwaitingReboot is when the reboot to the new stateroot, or back to the original one, is triggered. The
+	// state is kept until the agent is stopped by the reboot.
+	StateAwaitingReboot AgentState = "AwaitingReboot"
+)
+
+// hangTimeouts are how long the agent can stay in each busy state before it is considered hung. They are generous, as
+// restarting a busy agent interrupts the upgrade; the reboot is waited for one hour before it is reported as failed.
+var hangTimeouts = map[AgentState]time.Duration{
+	StateReconciling:    time.Hour,
+	StatePreparingPivot: 2 * time.Hour,
+	StateAwaitingReboot: 75 * time.Minute,
+}
+
+// AgentHealth is the functional state of the agent, telling a busy agent from a hung one
+type AgentHealth struct {
+	State AgentState `json:"state"`
+	// Since is when the agent entered the state
+	Since metav1.Time `json:"since"`
+	// Hung is true when the agent stayed in a busy state for longer than expected
+	Hung    bool   `json:"hung"`
+	Message string `json:"message,omitempty"`
+}
+
+// StartReconcile sets the state to Reconciling, unless the agent awaits the reboot
+func (r *Recorder) StartReconcile() {
+	r.setState(StateReconciling, false)
+}
+
+// EndReconcile sets the state back to Idle, unless the agent awaits the reboot
+func (r *Recorder) EndReconcile() {
+	r.setState(StateIdle, false)
+}
+
+// SetState sets the state of the agent
+func (r *Recorder) SetState(state AgentState) {
+	r.setState(state, true)
+}
+
+func (r *Recorder) setState(state AgentState, force bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !force && r.state == StateAwaitingReboot {
+		return
+	}
+	if r.state != state || r.stateSince.IsZero() {
+		r.state = state
+		r.stateSince = time.Now()
+	}
+}
+
+// Health returns the functional state of the agent
+func (r *Recorder) Health() AgentHealth {
+	if r == nil {
+		return AgentHealth{State: StateIdle}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	health := AgentHealth{State: r.state, Since: metav1.NewTime(r.stateSince)}
+	if health.State == "" {
+		health.State = StateIdle
+	}
+	if timeout, busy := hangTimeouts[health.State]; busy && time.Since(r.stateSince) > timeout {
+		health.Hung = true
+		health.Message = fmt.Sprintf("%s for more than %s", health.State, timeout)
+	}
+	return health
+}
+
+// LivenessCheck fails when the agent is hung, so that it is restarted, but not while it is busy upgrading
+func (r *Recorder) LivenessCheck() healthz.Checker {
+	return func(_ *http.Request) error {
+		if health := r.Health(); health.Hung {
+			return fmt.Errorf("agent hung: %s", health.Message)
+		}
+		return nil
+	}
+}
+
+// ReadinessCheck fails while the agent awaits the reboot, as it does not process any change until it is restarted
+func (r *Recorder) ReadinessCheck() healthz.Checker {
+	return func(_ *http.Request) error {
+		if health := r.Health(); health.State == StateAwaitingReboot {
+			return fmt.Errorf("agent awaiting the reboot since %s", health.Since.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	recorder := NewRecorder("", logr.Discard())
+	assert.Equal(t, StateIdle, recorder.Health().State)
+
+	recorder.StartReconcile()
+	assert.Equal(t, StateReconciling, recorder.Health().State)
+	recorder.SetState(StatePreparingPivot)
+	assert.Equal(t, StatePreparingPivot, recorder.Health().State)
+	recorder.EndReconcile()
+	assert.Equal(t, StateIdle, recorder.Health().State)
+
+	// The AwaitingReboot state is kept across reconciles
+	recorder.SetState(StateAwaitingReboot)
+	recorder.StartReconcile()
+	recorder.EndReconcile()
+	health := recorder.Health()
+	assert.Equal(t, StateAwaitingReboot, health.State)
+	assert.False(t, health.Hung)
+	assert.NoError(t, recorder.LivenessCheck()(nil))
+	assert.ErrorContains(t, recorder.ReadinessCheck()(nil), "awaiting the reboot")
+
+	// A busy state held for too long is reported as hung
+	recorder.stateSince = time.Now().Add(-2 * time.Hour)
+	health = recorder.Health()
+	assert.True(t, health.Hung)
+	assert.Equal(t, "AwaitingReboot for more than 1h15m0s", health.Message)
+	assert.ErrorContains(t, recorder.LivenessCheck()(nil), "agent hung")
+
+	recorder.SetState(StateIdle)
+	recorder.stateSince = time.Now().Add(-24 * time.Hour)
+	assert.False(t, recorder.Health().Hung)
+	assert.NoError(t, recorder.ReadinessCheck()(nil))
+
+	// All methods are no-ops on a nil Recorder
+	var nilRecorder *Recorder
+	nilRecorder.StartReconcile()
+	assert.Equal(t, StateIdle, nilRecorder.Health().State)
+	assert.NoError(t, nilRecorder.LivenessCheck()(nil))
+}
+
+func TestServerHealth(t *testing.T) {
+	recorder := NewRecorder("", logr.Discard())
+	server := &Server{Recorder: recorder, Log: logr.Discard()}
+
+	recorder.StartReconcile()
+	response := httptest.NewRecorder()
+	server.Handler().ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+	assert.Equal(t, http.StatusOK, response.Code)
+	health := AgentHealth{}
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &health))
+	assert.Equal(t, StateReconciling, health.State)
+	assert.False(t, health.Hung)
+
+	recorder.stateSince = time.Now().Add(-2 * time.Hour)
+	response = httptest.NewRecorder()
+	server.Handler().ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &health))
+	assert.True(t, health.Hung)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+// Names of the health checks recorded in the snapshot
+const (
+	PlatformHealthCheck = "platform"
+	CustomHealthCheck   = "custom"
+)
+
+// Snapshot is the last known upgrade progress, served by the local progress API
+type Snapshot struct {
+	// UpdatedAt is when the snapshot was last recorded
+	UpdatedAt metav1.Time `json:"updatedAt"`
+	// Stage is the desired stage of the IBU
+	Stage ibuv1.ImageBasedUpgradeStage `json:"stage,omitempty"`
+	// Status is the status of the IBU, as last reconciled
+	Status ibuv1.ImageBasedUpgradeStatus `json:"status"`
+	// HealthChecks are the results of the last run of each health check
+	HealthChecks []HealthCheckResult `json:"healthChecks,omitempty"`
+}
+
+// HealthCheckResult is the result of a run of a health check
+type HealthCheckResult struct {
+	Name    string      `json:"name"`
+	Passed  bool        `json:"passed"`
+	Message string      `json:"message,omitempty"`
+	Time    metav1.Time `json:"time"`
+}
+
+// Recorder keeps the snapshot of the upgrade progress, persisting it to the host so that the last known progress is
+// served when the LCA restarts while the API server is unavailable. All methods are no-ops on a nil Recorder.
+type Recorder struct {
+	file     string
+	log      logr.Logger
+	mu       sync.RWMutex
+	snapshot Snapshot
+
+	// state and stateSince are the functional state of the agent, which is only kept in memory as it is reset when
+	// the agent restarts
+	state      AgentState
+	stateSince time.Time
+}
+
+// NewRecorder returns a Recorder persisting the snapshot to the file, loading the previously persisted one if any
+func NewRecorder(file string, log logr.Logger) *Recorder {
+	r := &Recorder{file: file, log: log}
+	if err := utils.ReadYamlOrJSONFile(file, &r.snapshot); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error(err, "Failed to load the progress snapshot, starting afresh", "file", file)
+		r.snapshot = Snapshot{}
+	}
+	return r
+}
+
+// Record updates the snapshot with the stage and status of the IBU
+func (r *Recorder) Record(ibu *ibuv1.ImageBasedUpgrade) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.snapshot.Stage = ibu.Spec.Stage
+	r.snapshot.Status = *ibu.Status.DeepCopy()
+	r.persist()
+}
+
+// RecordHealthCheck updates the snapshot with the result of a health check, err being nil when it passed
+func (r *Recorder) RecordHealthCheck(name string, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := HealthCheckResult{Name: name, Passed: err == nil, Time: metav1.Now()}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	for i := range r.snapshot.HealthChecks {
+		if r.snapshot.HealthChecks[i].Name == name {
+			r.snapshot.HealthChecks[i] = result
+			r.persist()
+			return
+		}
+	}
+	r.snapshot.HealthChecks = append(r.snapshot.HealthChecks, result)
+	r.persist()
+}
+
+// Snapshot returns a copy of the current snapshot
+func (r *Recorder) Snapshot() Snapshot {
+	if r == nil {
+		return Snapshot{}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := r.snapshot
+	snapshot.Status = *r.snapshot.Status.DeepCopy()
+	snapshot.HealthChecks = append([]HealthCheckResult(nil), r.snapshot.HealthChecks...)
+	return snapshot
+}
+
+// persist writes the snapshot to the file. The caller must hold the lock. Failures are only logged, as the snapshot
+// is still served from memory.
+func (r *Recorder) persist() {
+	r.snapshot.UpdatedAt = metav1.Now()
+	if r.file == "" {
+		return
+	}
+	if err := utils.MarshalToFile(r.snapshot, r.file); err != nil {
+		r.log.Error(err, "Failed to persist the progress snapshot", "file", r.file)
+	}
+}
@@ -0,0 +1,132 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+func TestRecorder(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "progress.json")
+	recorder := NewRecorder(file, logr.Discard())
+	assert.Empty(t, recorder.Snapshot().Stage)
+
+	ibu := &ibuv1.ImageBasedUpgrade{
+		Spec:   ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade},
+		Status: ibuv1.ImageBasedUpgradeStatus{ValidNextStages: []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Rollback}},
+	}
+	recorder.Record(ibu)
+	recorder.RecordHealthCheck(PlatformHealthCheck, errors.New("node not ready"))
+	recorder.RecordHealthCheck(CustomHealthCheck, nil)
+	recorder.RecordHealthCheck(PlatformHealthCheck, nil)
+
+	// The snapshot is loaded back by a restarted LCA
+	snapshot := NewRecorder(file, logr.Discard()).Snapshot()
+	assert.Equal(t, ibuv1.Stages.Upgrade, snapshot.Stage)
+	assert.Equal(t, ibu.Status.ValidNextStages, snapshot.Status.ValidNextStages)
+	if assert.Len(t, snapshot.HealthChecks, 2) {
+		assert.Equal(t, PlatformHealthCheck, snapshot.HealthChecks[0].Name)
+		assert.True(t, snapshot.HealthChecks[0].Passed)
+		assert.Empty(t, snapshot.HealthChecks[0].Message)
+		assert.Equal(t, CustomHealthCheck, snapshot.HealthChecks[1].Name)
+	}
+
+	// A nil recorder is a no-op
+	var nilRecorder *Recorder
+	nilRecorder.Record(ibu)
+	nilRecorder.RecordHealthCheck(PlatformHealthCheck, nil)
+	assert.Equal(t, Snapshot{}, nilRecorder.Snapshot())
+}
+
+func TestServer(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+
+	recorder := NewRecorder("", logr.Discard())
+	recorder.Record(&ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Prep}})
+	recorder.RecordHealthCheck(PlatformHealthCheck, errors.New("node not ready"))
+	server := &Server{Recorder: recorder, Executor: mockExecutor, Log: logr.Discard()}
+
+	testcases := []struct {
+		name         string
+		method       string
+		target       string
+		journalLines string
+		expectCode   int
+		expectBody   func(t *testing.T, body []byte)
+	}{
+		{
+			name:       "progress",
+			method:     http.MethodGet,
+			target:     "/v1/progress",
+			expectCode: http.StatusOK,
+			expectBody: func(t *testing.T, body []byte) {
+				snapshot := Snapshot{}
+				assert.NoError(t, json.Unmarshal(body, &snapshot))
+				assert.Equal(t, ibuv1.Stages.Prep, snapshot.Stage)
+			},
+		},
+		{
+			name:       "health checks",
+			method:     http.MethodGet,
+			target:     "/v1/healthchecks",
+			expectCode: http.StatusOK,
+			expectBody: func(t *testing.T, body []byte) {
+				var results []HealthCheckResult
+				assert.NoError(t, json.Unmarshal(body, &results))
+				if assert.Len(t, results, 1) {
+					assert.False(t, results[0].Passed)
+					assert.Equal(t, "node not ready", results[0].Message)
+				}
+			},
+		},
+		{
+			name:         "logs",
+			method:       http.MethodGet,
+			target:       "/v1/logs?lines=20",
+			journalLines: "20",
+			expectCode:   http.StatusOK,
+			expectBody: func(t *testing.T, body []byte) {
+				assert.Equal(t, "journal\n", string(body))
+			},
+		},
+		{
+			name:       "invalid number of log lines",
+			method:     http.MethodGet,
+			target:     "/v1/logs?lines=0",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "read-only",
+			method:     http.MethodPost,
+			target:     "/v1/progress",
+			expectCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.journalLines != "" {
+				mockExecutor.EXPECT().Execute("journalctl", "--no-pager", "--output", "short-iso", "--lines", tc.journalLines,
+					"--unit", LogUnits[0], "--unit", LogUnits[1], "--unit", LogUnits[2]).Return("journal", nil)
+			}
+
+			response := httptest.NewRecorder()
+			server.Handler().ServeHTTP(response, httptest.NewRequest(tc.method, tc.target, nil))
+			assert.Equal(t, tc.expectCode, response.Code)
+			if tc.expectBody != nil {
+				tc.expectBody(t, response.Body.Bytes())
+			}
+		})
+	}
+}
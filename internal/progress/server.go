@@ -0,0 +1,165 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const (
+	defaultLogLines = 100
+	maxLogLines     = 5000
+)
+
+// LogUnits are the systemd units running the upgrade steps outside of the LCA pod, whose journal is served by the
+// logs endpoint
+var LogUnits = []string{
+	common.InstallationConfigurationService,
+	common.IBUInitMonitorService,
+	common.IPConfigurationService,
+}
+
+// Server serves the read-only local progress API on a unix socket of the node. As it does not depend on the API
+// server, it keeps serving the last known progress while the API server is unavailable around the pivot.
+type Server struct {
+	// SocketPath is the path of the unix socket to listen on
+	SocketPath string
+	Recorder   *Recorder
+	// Executor runs journalctl on the host
+	Executor ops.Execute
+	Log      logr.Logger
+}
+
+// NeedLeaderElection runs the server on every LCA instance
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the API until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.SocketPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create the directory of the progress socket: %w", err)
+	}
+	if err := os.Remove(s.SocketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove the stale progress socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.SocketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.SocketPath, err)
+	}
+	if err := os.Chmod(s.SocketPath, 0o600); err != nil {
+		return fmt.Errorf("failed to restrict access to the progress socket: %w", err)
+	}
+
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.Log.Error(err, "Failed to shut down the progress server")
+		}
+	}()
+
+	s.Log.Info("Serving the local progress API", "socket", s.SocketPath)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("progress server failed: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the handler of the API endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/progress", s.handleProgress)
+	mux.HandleFunc("GET /v1/healthchecks", s.handleHealthChecks)
+	mux.HandleFunc("GET /v1/logs", s.handleLogs)
+	mux.HandleFunc("GET /v1/health", s.handleHealth)
+	return mux
+}
+
+func (s *Server) handleProgress(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, s.Recorder.Snapshot())
+}
+
+func (s *Server) handleHealthChecks(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, s.Recorder.Snapshot().HealthChecks)
+}
+
+// handleHealth returns the functional state of the agent, with the 503 status code when it is hung
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	health := s.Recorder.Health()
+	if health.Hung {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if err := json.NewEncoder(w).Encode(health); err != nil {
+			s.Log.Error(err, "Failed to write the health response")
+		}
+		return
+	}
+	s.writeJSON(w, health)
+}
+
+// handleLogs returns the tail of the journal of the LogUnits, the number of lines being set by the lines parameter
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	lines := defaultLogLines
+	if value := r.URL.Query().Get("lines"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxLogLines {
+			http.Error(w, fmt.Sprintf("lines must be a number between 1 and %d", maxLogLines), http.StatusBadRequest)
+			return
+		}
+		lines = n
+	}
+
+	args := []string{"--no-pager", "--output", "short-iso", "--lines", strconv.Itoa(lines)}
+	for _, unit := range LogUnits {
+		args = append(args, "--unit", unit)
+	}
+	output, err := s.Executor.Execute("journalctl", args...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read the journal: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := fmt.Fprintln(w, output); err != nil {
+		s.Log.Error(err, "Failed to write the logs response")
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		s.Log.Error(err, "Failed to write the progress response")
+	}
+}
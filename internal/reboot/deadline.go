@@ -0,0 +1,63 @@
+package reboot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+// AutoRollbackDeadline is the deadline of the LCA Init Monitor watchdog, recorded by the watchdog when it starts
+type AutoRollbackDeadline struct {
+	Deadline time.Time `json:"deadline"`
+}
+
+// AutoRollbackDeadlineExtension is the extension of the deadline of the LCA Init Monitor watchdog, recorded by the
+// controller from the IBU annotation
+type AutoRollbackDeadlineExtension struct {
+	ExtensionSeconds int `json:"extension_seconds"`
+}
+
+// WriteAutoRollbackDeadline records the deadline of the LCA Init Monitor watchdog
+func WriteAutoRollbackDeadline(file string, deadline time.Time) error {
+	if err := lcautils.MarshalToFile(AutoRollbackDeadline{Deadline: deadline.UTC()}, file); err != nil {
+		return fmt.Errorf("failed to write auto-rollback deadline: %w", err)
+	}
+	return nil
+}
+
+// ReadAutoRollbackDeadline returns the deadline of the LCA Init Monitor watchdog, or the zero time if the watchdog is
+// not running
+func ReadAutoRollbackDeadline(file string) (time.Time, error) {
+	deadline := &AutoRollbackDeadline{}
+	if err := lcautils.ReadYamlOrJSONFile(file, deadline); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to read auto-rollback deadline: %w", err)
+	}
+	return deadline.Deadline, nil
+}
+
+// WriteAutoRollbackDeadlineExtension records the extension of the deadline of the LCA Init Monitor watchdog
+func WriteAutoRollbackDeadlineExtension(file string, extension time.Duration) error {
+	if err := lcautils.MarshalToFile(AutoRollbackDeadlineExtension{ExtensionSeconds: int(extension.Seconds())}, file); err != nil {
+		return fmt.Errorf("failed to write auto-rollback deadline extension: %w", err)
+	}
+	return nil
+}
+
+// ReadAutoRollbackDeadlineExtension returns the extension of the deadline of the LCA Init Monitor watchdog, or 0 if it
+// is not extended
+func ReadAutoRollbackDeadlineExtension(file string) (time.Duration, error) {
+	extension := &AutoRollbackDeadlineExtension{}
+	if err := lcautils.ReadYamlOrJSONFile(file, extension); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read auto-rollback deadline extension: %w", err)
+	}
+	return time.Duration(extension.ExtensionSeconds) * time.Second, nil
+}
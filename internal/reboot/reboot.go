@@ -89,13 +89,14 @@ func WriteIBUAutoRollbackConfigFile(log logr.Logger, ibu *ibuv1.ImageBasedUpgrad
 
 	log.Info("Auto-rollback init monitor timeout", "monitorTimeout", monitorTimeout)
 
-	// check autoRollback's InitMonitor config from annotation
-	initMonitorEnabled := true
-	if val, exists := ibu.GetAnnotations()[common.AutoRollbackOnFailureInitMonitorAnnotation]; exists {
-		if val == common.AutoRollbackDisableValue {
-			initMonitorEnabled = false
-		}
+	autoRollback := ibu.Spec.AutoRollbackOnFailure
+	if autoRollback == nil {
+		autoRollback = &ibuv1.AutoRollbackOnFailure{}
 	}
+
+	// check autoRollback's InitMonitor config from spec or annotation
+	initMonitorEnabled := common.IsAutoRollbackEnabled(autoRollback.InitMonitor, ibu.GetAnnotations(),
+		common.AutoRollbackOnFailureInitMonitorAnnotation)
 	log.Info("Auto-rollback init monitor config", "initMonitorEnabled", initMonitorEnabled)
 
 	rollbackCfg := AutoRollbackConfig{
@@ -104,13 +105,9 @@ func WriteIBUAutoRollbackConfigFile(log logr.Logger, ibu *ibuv1.ImageBasedUpgrad
 		EnabledComponents:  make(map[string]bool),
 	}
 
-	// check autoRollback's PostReboot config from annotation
-	postRebootConfigEnabled := true
-	if val, exists := ibu.GetAnnotations()[common.AutoRollbackOnFailurePostRebootConfigAnnotation]; exists {
-		if val == common.AutoRollbackDisableValue {
-			postRebootConfigEnabled = false
-		}
-	}
+	// check autoRollback's PostReboot config from spec or annotation
+	postRebootConfigEnabled := common.IsAutoRollbackEnabled(autoRollback.PostRebootConfig, ibu.GetAnnotations(),
+		common.AutoRollbackOnFailurePostRebootConfigAnnotation)
 	log.Info("Auto-rollback post reboot config", "postRebootConfigEnabled", postRebootConfigEnabled)
 
 	rollbackCfg.EnabledComponents[InstallationConfigurationComponent] = postRebootConfigEnabled
@@ -164,6 +161,13 @@ func (c *IBURebootClient) DisableInitMonitor() error {
 		return fmt.Errorf("failed to delete %s: %w", common.IBUInitMonitorServiceFile, err)
 	}
 
+	// The watchdog no longer has a deadline
+	for _, file := range []string{common.IBUAutoRollbackDeadlineFile, common.IBUAutoRollbackDeadlineExtensionFile} {
+		if err := os.Remove(common.PathOutsideChroot(file)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete %s: %w", file, err)
+		}
+	}
+
 	if _, err := c.hostCommandsExecutor.Execute("systemctl", "daemon-reload"); err != nil {
 		return fmt.Errorf("systemctl daemon-reload failed after deleting %s: %w", common.IBUInitMonitorServiceFile, err)
 	}
@@ -41,6 +41,7 @@ var (
 type RecertConfig struct {
 	DryRun               bool     `json:"dry_run,omitempty"`
 	ExtendExpiration     bool     `json:"extend_expiration,omitempty"`
+	CertValidityDays     int      `json:"cert_validity_days,omitempty"`
 	ForceExpire          bool     `json:"force_expire,omitempty"`
 	EtcdEndpoint         string   `json:"etcd_endpoint,omitempty"`
 	ClusterRename        string   `json:"cluster_rename,omitempty"`
@@ -123,12 +124,27 @@ func CreateRecertConfigFile(seedReconfig *seedreconfig.SeedReconfiguration, seed
 		config.Hostname = seedReconfig.Hostname
 	}
 
-	ipsChanged := !slices.Equal(seedClusterInfo.NodeIPs, seedReconfig.NodeIPs)
-	if ipsChanged && len(seedReconfig.NodeIPs) > 0 {
-		config.IP = seedReconfig.NodeIPs
+	// Recert only reconfigures the IPs and machine networks of the seed families, the family added by a single-stack
+	// to dual-stack conversion is rolled out once the cluster is up
+	nodeIPs := seedReconfig.NodeIPs
+	machineNetworks := seedReconfig.MachineNetworks
+	if len(seedClusterInfo.NodeIPs) == 1 && len(nodeIPs) == 2 {
+		nodeIPs = nodeIPs[:1]
+		if len(machineNetworks) == 2 {
+			machineNetworks = machineNetworks[:1]
+		}
+	}
+
+	ipsChanged := !slices.Equal(seedClusterInfo.NodeIPs, nodeIPs)
+	if ipsChanged && len(nodeIPs) > 0 {
+		config.IP = nodeIPs
 	}
 
-	config.Proxy = FormatRecertProxyFromSeedReconfigProxy(seedReconfig.Proxy, seedReconfig.StatusProxy)
+	// Recert can only rename the proxy configuration of a seed that has one, a proxy added or removed is reconfigured
+	// once the cluster is up
+	if seedClusterInfo.HasProxy {
+		config.Proxy = FormatRecertProxyFromSeedReconfigProxy(seedReconfig.Proxy, seedReconfig.StatusProxy)
+	}
 
 	if err := SetRecertTrustedCaBundleFromSeedReconfigAdditionaTrustBundle(&config, seedReconfig.AdditionalTrustBundle); err != nil {
 		return fmt.Errorf("failed to set recert trusted ca bundle from seed reconfig additional trust bundle: %w", err)
@@ -176,12 +192,18 @@ func CreateRecertConfigFile(seedReconfig *seedreconfig.SeedReconfiguration, seed
 		fmt.Sprintf("*.apps.%s,*.apps.%s", seedFullDomain, clusterFullDomain),
 	}
 
-	if len(seedClusterInfo.NodeIPs) == len(seedReconfig.NodeIPs) {
+	if len(seedClusterInfo.NodeIPs) == len(nodeIPs) {
 		for i := range seedClusterInfo.NodeIPs {
 			config.CNSanReplaceRules = append(
-				config.CNSanReplaceRules, fmt.Sprintf("%s,%s", seedClusterInfo.NodeIPs[i], seedReconfig.NodeIPs[i]),
+				config.CNSanReplaceRules, fmt.Sprintf("%s,%s", seedClusterInfo.NodeIPs[i], nodeIPs[i]),
 			)
 		}
+	} else if common.IsIPv6OnlyConversion(seedClusterInfo.NodeIPs, nodeIPs) {
+		// The IPs of all the seed families are replaced by the IPv6 IP in the certificates, config.IP making it
+		// the etcd and kube-apiserver advertise address
+		for _, seedIP := range seedClusterInfo.NodeIPs {
+			config.CNSanReplaceRules = append(config.CNSanReplaceRules, fmt.Sprintf("%s,%s", seedIP, nodeIPs[0]))
+		}
 	}
 
 	if seedReconfig.KubeconfigCryptoRetention.IngresssCrypto.IngressCertificateCN != "" {
@@ -189,8 +211,12 @@ func CreateRecertConfigFile(seedReconfig *seedreconfig.SeedReconfiguration, seed
 			fmt.Sprintf("%s,%s", seedClusterInfo.IngressCertificateCN, seedReconfig.KubeconfigCryptoRetention.IngresssCrypto.IngressCertificateCN))
 	}
 
-	if !slices.Equal(seedReconfig.MachineNetworks, seedClusterInfo.MachineNetworks) {
-		config.MachineNetworkCidr = seedReconfig.MachineNetworks
+	if !slices.Equal(machineNetworks, seedClusterInfo.MachineNetworks) {
+		config.MachineNetworkCidr = machineNetworks
+	}
+
+	if err := setCertificateRegeneration(&config, seedReconfig.CertificateRegeneration, cryptoDir); err != nil {
+		return fmt.Errorf("failed to set recert certificate regeneration: %w", err)
 	}
 
 	p := filepath.Join(recertConfigFolder, RecertConfigFile)
@@ -201,6 +227,61 @@ func CreateRecertConfigFile(seedReconfig *seedreconfig.SeedReconfiguration, seed
 	return nil
 }
 
+// setCertificateRegeneration writes the custom CAs and the preserved certificates to the crypto dir, and sets the
+// recert rules using them in place of the regenerated certificates and of the retained signers of the same CN
+func setCertificateRegeneration(config *RecertConfig, regeneration seedreconfig.CertificateRegeneration, cryptoDir string) error {
+	if regeneration.ValidityDays > 0 {
+		config.ExtendExpiration = false
+		config.CertValidityDays = regeneration.ValidityDays
+	}
+	if len(regeneration.CustomCAs) == 0 && len(regeneration.PreservedCertificates) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(cryptoDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", cryptoDir, err)
+	}
+	writeKeyPair := func(name string, keyPair seedreconfig.CertificateKeyPair) (string, string, error) {
+		certFile := filepath.Join(cryptoDir, name+".crt")
+		keyFile := filepath.Join(cryptoDir, name+".key")
+		if err := os.WriteFile(certFile, []byte(keyPair.Certificate), 0o600); err != nil {
+			return "", "", fmt.Errorf("failed to write %s: %w", certFile, err)
+		}
+		if err := os.WriteFile(keyFile, []byte(keyPair.PrivateKey), 0o600); err != nil {
+			return "", "", fmt.Errorf("failed to write %s: %w", keyFile, err)
+		}
+		return certFile, keyFile, nil
+	}
+
+	for i, customCA := range regeneration.CustomCAs {
+		certFile, keyFile, err := writeKeyPair(fmt.Sprintf("custom-ca-%d", i), customCA.CertificateKeyPair)
+		if err != nil {
+			return err
+		}
+		// The custom CA takes over the retained signer of the same CN
+		config.UseKeyRules = slices.DeleteFunc(config.UseKeyRules, func(rule string) bool {
+			return strings.HasPrefix(rule, customCA.SignerCN+" ")
+		})
+		config.UseKeyRules = append(config.UseKeyRules, fmt.Sprintf("%s %s", customCA.SignerCN, keyFile))
+		config.UseCertRules = append(config.UseCertRules, certFile)
+	}
+
+	for i, preserved := range regeneration.PreservedCertificates {
+		cn, err := utils.GetCommonNameFromCertificate([]byte(preserved.Certificate))
+		if err != nil {
+			return fmt.Errorf("failed to get the common name of preserved certificate %d: %w", i, err)
+		}
+		certFile, keyFile, err := writeKeyPair(fmt.Sprintf("preserved-certificate-%d", i), preserved)
+		if err != nil {
+			return err
+		}
+		config.UseKeyRules = append(config.UseKeyRules, fmt.Sprintf("%s %s", cn, keyFile))
+		config.UseCertRules = append(config.UseCertRules, certFile)
+	}
+
+	return nil
+}
+
 func CreateRecertConfigFileForSeedCreation(path string, withPassword bool) error {
 	config := createBaseRecertConfig()
 	config.SummaryFileClean = "/kubernetes/recert-seed-creation-summary.yaml"
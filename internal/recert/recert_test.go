@@ -1,9 +1,21 @@
 package recert
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -141,3 +153,96 @@ func TestCreateBaseRecertConfig(t *testing.T) {
 	assert.Equal(t, clusterCustomizationDirs, config.ClusterCustomizationDirs)
 	assert.Equal(t, clusterCustomizationFiles, config.ClusterCustomizationFiles)
 }
+
+func TestCreateRecertConfigFile_IPv6OnlyConversion(t *testing.T) {
+	dir := t.TempDir()
+	seedClusterInfo := &seedclusterinfo.SeedClusterInfo{
+		ClusterName:     "seed",
+		BaseDomain:      "example.com",
+		SNOHostname:     "seed-node",
+		NodeIPs:         []string{"192.168.1.10", "2001:db8::10"},
+		MachineNetworks: []string{"192.168.1.0/24", "2001:db8::/64"},
+	}
+	seedReconfig := &seedreconfig.SeedReconfiguration{
+		ClusterName:     "target",
+		BaseDomain:      "example.com",
+		Hostname:        "target-node",
+		NodeIPs:         []string{"2001:db8::2"},
+		MachineNetworks: []string{"2001:db8::/64"},
+	}
+	assert.NoError(t, CreateRecertConfigFile(seedReconfig, seedClusterInfo, filepath.Join(dir, "crypto"), dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, RecertConfigFile))
+	assert.NoError(t, err)
+	var config RecertConfig
+	assert.NoError(t, json.Unmarshal(content, &config))
+	assert.Equal(t, []string{"2001:db8::2"}, config.IP)
+	assert.Equal(t, []string{"2001:db8::/64"}, config.MachineNetworkCidr)
+	assert.Contains(t, config.CNSanReplaceRules, "192.168.1.10,2001:db8::2")
+	assert.Contains(t, config.CNSanReplaceRules, "2001:db8::10,2001:db8::2")
+}
+
+func TestCreateRecertConfigFile_CertificateRegeneration(t *testing.T) {
+	dir := t.TempDir()
+	cryptoDir := filepath.Join(dir, "crypto")
+	assert.NoError(t, os.MkdirAll(cryptoDir, 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(cryptoDir, "ingresskey-ingress-operator.key"), []byte("key"), 0o600))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "api.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	preservedCertificate := seedreconfig.PEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	seedClusterInfo := &seedclusterinfo.SeedClusterInfo{
+		ClusterName:          "seed",
+		BaseDomain:           "example.com",
+		SNOHostname:          "seed-node",
+		IngressCertificateCN: "ingress-operator@1",
+	}
+	seedReconfig := &seedreconfig.SeedReconfiguration{
+		ClusterName: "target",
+		BaseDomain:  "example.com",
+		Hostname:    "target-node",
+		CertificateRegeneration: seedreconfig.CertificateRegeneration{
+			ValidityDays: 365,
+			CustomCAs: []seedreconfig.CustomCA{{
+				SignerCN:           "kube-apiserver-lb-signer",
+				CertificateKeyPair: seedreconfig.CertificateKeyPair{Certificate: "ca-cert", PrivateKey: "ca-key"},
+			}},
+			PreservedCertificates: []seedreconfig.CertificateKeyPair{
+				{Certificate: preservedCertificate, PrivateKey: "preserved-key"},
+			},
+		},
+	}
+	assert.NoError(t, CreateRecertConfigFile(seedReconfig, seedClusterInfo, cryptoDir, dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, RecertConfigFile))
+	assert.NoError(t, err)
+	var config RecertConfig
+	assert.NoError(t, json.Unmarshal(content, &config))
+	assert.False(t, config.ExtendExpiration)
+	assert.Equal(t, 365, config.CertValidityDays)
+	assert.Equal(t, []string{
+		"kube-apiserver-localhost-signer " + cryptoDir + "/localhost-serving-signer.key",
+		"kube-apiserver-service-network-signer " + cryptoDir + "/service-network-serving-signer.key",
+		"ingress-operator@1 " + cryptoDir + "/ingresskey-ingress-operator.key",
+		"kube-apiserver-lb-signer " + cryptoDir + "/custom-ca-0.key",
+		"api.example.com " + cryptoDir + "/preserved-certificate-0.key",
+	}, config.UseKeyRules)
+	assert.Equal(t, []string{
+		filepath.Join(cryptoDir, "admin-kubeconfig-client-ca.crt"),
+		filepath.Join(cryptoDir, "custom-ca-0.crt"),
+		filepath.Join(cryptoDir, "preserved-certificate-0.crt"),
+	}, config.UseCertRules)
+
+	caKey, err := os.ReadFile(filepath.Join(cryptoDir, "custom-ca-0.key"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ca-key", string(caKey))
+}
@@ -0,0 +1,65 @@
+package seedimage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+// WriteContentManifest writes the content manifest of the seed image built from dir, listing the sha256 digest of
+// every file in the sha256sum format, and returns the digest of the manifest itself. The manifest is written to dir, so
+// that it is included in the seed image.
+func WriteContentManifest(dir string) (string, error) {
+	manifestPath := filepath.Join(dir, common.SeedContentManifestFileName)
+
+	var lines []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || path == manifestPath {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path of %s: %w", path, err)
+		}
+		digest, err := fileDigest(path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s\n", digest, rel))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute the content manifest of %s: %w", dir, err)
+	}
+	sort.Strings(lines)
+
+	manifest := strings.Join(lines, "")
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write the content manifest %s: %w", manifestPath, err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest))), nil
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to compute the digest of %s: %w", path, err)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,37 @@
+package seedimage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+func TestWriteContentManifest(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "kubeconfig-crypto"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "var.tgz"), []byte("var"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kubeconfig-crypto", "admin-kubeconfig-client-ca.crt"), []byte("ca"), 0o600))
+
+	hash, err := WriteContentManifest(dir)
+	assert.NoError(t, err)
+
+	manifest, err := os.ReadFile(filepath.Join(dir, common.SeedContentManifestFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x  kubeconfig-crypto/admin-kubeconfig-client-ca.crt\n%x  var.tgz\n",
+		sha256.Sum256([]byte("ca")), sha256.Sum256([]byte("var"))), string(manifest))
+	assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)), hash)
+
+	// The manifest is not listed in itself, so that writing it again yields the same hash
+	rewritten, err := WriteContentManifest(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, rewritten)
+
+	_, err = WriteContentManifest(filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "failed to compute the content manifest")
+}
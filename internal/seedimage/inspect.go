@@ -0,0 +1,108 @@
+package seedimage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
+)
+
+// Inspect holds the seed image metadata retrieved with skopeo inspect
+type Inspect struct {
+	Digest string `json:"Digest"`
+	// Architecture is the architecture of the seed image, the one of the host being selected from a manifest list
+	Architecture string            `json:"Architecture"`
+	Created      *time.Time        `json:"Created"`
+	Labels       map[string]string `json:"Labels"`
+	LayersData   []struct {
+		Size int64 `json:"Size"`
+	} `json:"LayersData"`
+}
+
+// InspectImage uses skopeo inspect to retrieve the metadata and layers of the seed image without downloading the
+// image itself, from the registry or from the local source of the seed image
+func InspectImage(executor ops.Execute, image, authFile string) (*Inspect, error) {
+	reference, err := transportReference(image)
+	if err != nil {
+		return nil, err
+	}
+	inspectArgs := []string{
+		"inspect",
+		"--retry-times", "10",
+		"--authfile", authFile,
+		"--format", "json",
+		reference,
+	}
+
+	inspect := &Inspect{}
+
+	// TODO: use the context when execute supports it
+	inspectRaw, err := executor.Execute("skopeo", inspectArgs...)
+	if err != nil || inspectRaw == "" {
+		return nil, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	if err := json.Unmarshal([]byte(inspectRaw), inspect); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal image inspect output: %w", err)
+	}
+	return inspect, nil
+}
+
+// Size returns the compressed size of the seed image layers
+func (i *Inspect) Size() int64 {
+	var size int64
+	for _, layer := range i.LayersData {
+		size += layer.Size
+	}
+	return size
+}
+
+// ContentHash returns the digest of the content manifest of the seed image, or empty if it is not recorded
+func (i *Inspect) ContentHash() string {
+	return i.Labels[common.SeedContentHashOCILabel]
+}
+
+// SeedClusterInfo returns the seed cluster info recorded in the labels of the seed image, or nil if it is not recorded
+func (i *Inspect) SeedClusterInfo() (*seedclusterinfo.SeedClusterInfo, error) {
+	return SeedClusterInfoFromLabels(i.Labels)
+}
+
+// Info returns the seed image metadata reported in the IBU status
+func (i *Inspect) Info(image string) (*ibuv1.SeedImageInfo, error) {
+	info := &ibuv1.SeedImageInfo{Image: image, Digest: i.Digest}
+	if i.Created != nil {
+		info.CreationTime = &metav1.Time{Time: *i.Created}
+	}
+
+	seedInfo, err := i.SeedClusterInfo()
+	if err != nil {
+		return nil, err
+	}
+	if seedInfo != nil {
+		info.OCPVersion = seedInfo.SeedClusterOCPVersion
+		info.BaseOSVersion = seedInfo.BaseOSVersion
+		info.Components = seedInfo.Components
+	}
+	return info, nil
+}
+
+// SeedClusterInfoFromLabels parses the seed cluster info recorded in the labels of a seed image, returning nil if it
+// is not recorded
+func SeedClusterInfoFromLabels(labels map[string]string) (*seedclusterinfo.SeedClusterInfo, error) {
+	seedFormatLabelValue, ok := labels[common.SeedClusterInfoOCILabel]
+	if !ok {
+		return nil, nil
+	}
+
+	var seedInfo seedclusterinfo.SeedClusterInfo
+	if err := json.Unmarshal([]byte(seedFormatLabelValue), &seedInfo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal seed cluster info: %w", err)
+	}
+
+	return &seedInfo, nil
+}
@@ -0,0 +1,62 @@
+package seedimage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const testInspectOutput = `{
+  "Digest": "sha256:0123456789abcdef",
+  "Created": "2024-05-02T10:00:00Z",
+  "Labels": {
+    "com.openshift.lifecycle-agent.seed_format_version": "3",
+    "com.openshift.lifecycle-agent.seed_content_hash": "sha256:fedcba9876543210",
+    "com.openshift.lifecycle-agent.seed_cluster_info": "{\"seed_cluster_ocp_version\":\"4.16.0\",\"base_os_version\":\"416.94.202405021000-0\",\"components\":[\"lvms-operator.v4.16.0\",\"sriov-network-operator.v4.16.0\"],\"has_proxy\":false,\"has_fips\":false}"
+  },
+  "LayersData": [{"Size": 100}, {"Size": 50}]
+}`
+
+func TestInspectImage(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+
+	mockExecutor.EXPECT().Execute("skopeo", "inspect", "--retry-times", "10", "--authfile", "/tmp/auth.json",
+		"--format", "json", "docker://quay.io/seed:4.16").Return(testInspectOutput, nil)
+	inspect, err := InspectImage(mockExecutor, "quay.io/seed:4.16", "/tmp/auth.json")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), inspect.Size())
+	assert.Equal(t, "sha256:fedcba9876543210", inspect.ContentHash())
+
+	info, err := inspect.Info("quay.io/seed:4.16")
+	assert.NoError(t, err)
+	assert.Equal(t, &ibuv1.SeedImageInfo{
+		Image:         "quay.io/seed:4.16",
+		Digest:        "sha256:0123456789abcdef",
+		CreationTime:  &metav1.Time{Time: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)},
+		OCPVersion:    "4.16.0",
+		BaseOSVersion: "416.94.202405021000-0",
+		Components:    []string{"lvms-operator.v4.16.0", "sriov-network-operator.v4.16.0"},
+	}, info)
+
+	mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).Return("", errors.New("unauthorized"))
+	_, err = InspectImage(mockExecutor, "quay.io/seed:4.16", "/tmp/auth.json")
+	assert.ErrorContains(t, err, "failed to inspect image: unauthorized")
+}
+
+func TestInfoWithoutSeedClusterInfo(t *testing.T) {
+	info, err := (&Inspect{Digest: "sha256:0123456789abcdef"}).Info("quay.io/image:latest")
+	assert.NoError(t, err)
+	assert.Equal(t, &ibuv1.SeedImageInfo{Image: "quay.io/image:latest", Digest: "sha256:0123456789abcdef"}, info)
+
+	_, err = (&Inspect{Labels: map[string]string{"com.openshift.lifecycle-agent.seed_cluster_info": "{"}}).Info("quay.io/seed:4.16")
+	assert.ErrorContains(t, err, "failed to unmarshal seed cluster info")
+}
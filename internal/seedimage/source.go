@@ -0,0 +1,105 @@
+package seedimage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+// Transports of the seed images delivered as files on the host, e.g. on portable media, for the sites that cannot
+// host a registry
+const (
+	OCILayoutTransport  = "oci"
+	OCIArchiveTransport = "oci-archive"
+)
+
+// localImageRepository is the repository the local seed images are loaded in the container storage under, tagged
+// with their digest
+const localImageRepository = "localhost/lca-seed-image"
+
+var sha256DigestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// LocalSource is a seed image referenced as <transport>:<path>[:<reference>]@<digest>, the OCI layout directory or
+// archive being on the host. The digest is required for the content of the file to be verified before it is loaded.
+type LocalSource struct {
+	Transport string
+	// Path is the absolute path of the OCI layout directory or archive, with the reference of the image in the layout
+	// if any
+	Path   string
+	Digest string
+}
+
+// ParseLocalSource returns the local source of the seed image, or nil if the seed image is pulled from a registry
+func ParseLocalSource(image string) (*LocalSource, error) {
+	transport, reference, found := strings.Cut(image, ":")
+	if !found || (transport != OCILayoutTransport && transport != OCIArchiveTransport) {
+		return nil, nil
+	}
+	// A registry host and port, e.g. oci:5000/seed, is not a local source
+	if !strings.HasPrefix(reference, "/") {
+		return nil, nil
+	}
+
+	separator := strings.LastIndex(reference, "@")
+	if separator == -1 {
+		return nil, fmt.Errorf("local seed image %s must be pinned by digest, e.g. %s@sha256:<digest>", image, image)
+	}
+	source := &LocalSource{Transport: transport, Path: reference[:separator], Digest: reference[separator+1:]}
+	if !sha256DigestRegex.MatchString(source.Digest) {
+		return nil, fmt.Errorf("invalid digest %s of local seed image %s, expecting sha256:<64 hex characters>", source.Digest, image)
+	}
+	return source, nil
+}
+
+// TransportReference returns the reference of the seed image for skopeo, without its digest
+func (s *LocalSource) TransportReference() string {
+	return s.Transport + ":" + s.Path
+}
+
+// StorageImage returns the name the seed image is loaded in the container storage under
+func (s *LocalSource) StorageImage() string {
+	return localImageRepository + ":" + strings.TrimPrefix(s.Digest, "sha256:")
+}
+
+// Load verifies the digest of the seed image manifest and copies the seed image into the container storage, skopeo
+// verifying the layers against the manifest as they are copied. The copyArgs are passed to skopeo copy, e.g. the
+// decryption key of an encrypted seed image.
+func (s *LocalSource) Load(executor ops.Execute, copyArgs []string) error {
+	digest, err := executor.Execute("skopeo", "inspect", "--format", "{{.Digest}}", s.TransportReference())
+	if err != nil {
+		return fmt.Errorf("failed to inspect local seed image %s: %w", s.TransportReference(), err)
+	}
+	if digest = strings.TrimSpace(digest); digest != s.Digest {
+		return fmt.Errorf("local seed image %s digest %s does not match the expected digest %s",
+			s.TransportReference(), digest, s.Digest)
+	}
+
+	args := append(append([]string{"copy"}, copyArgs...), s.TransportReference(), "containers-storage:"+s.StorageImage())
+	if _, err := executor.Execute("skopeo", args...); err != nil {
+		return fmt.Errorf("failed to load local seed image %s: %w", s.TransportReference(), err)
+	}
+	return nil
+}
+
+// StorageImage returns the name of the seed image in the container storage once pulled, which is the image
+// reference itself unless the seed image has a local source
+func StorageImage(image string) string {
+	if source, err := ParseLocalSource(image); err == nil && source != nil {
+		return source.StorageImage()
+	}
+	return image
+}
+
+// transportReference returns the reference of the seed image for skopeo, a registry by default
+func transportReference(image string) (string, error) {
+	source, err := ParseLocalSource(image)
+	if err != nil {
+		return "", err
+	}
+	if source != nil {
+		return source.TransportReference(), nil
+	}
+	return "docker://" + image, nil
+}
@@ -0,0 +1,101 @@
+package seedimage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseLocalSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		image         string
+		expected      *LocalSource
+		expectedError string
+	}{
+		{
+			name:  "registry image",
+			image: "quay.io/seed:4.16",
+		},
+		{
+			name:  "registry host named oci",
+			image: "oci:5000/seed:4.16",
+		},
+		{
+			name:     "OCI layout directory",
+			image:    "oci:/mnt/usb/seed:4.16@" + testDigest,
+			expected: &LocalSource{Transport: OCILayoutTransport, Path: "/mnt/usb/seed:4.16", Digest: testDigest},
+		},
+		{
+			name:     "OCI archive",
+			image:    "oci-archive:/mnt/usb/seed.tar@" + testDigest,
+			expected: &LocalSource{Transport: OCIArchiveTransport, Path: "/mnt/usb/seed.tar", Digest: testDigest},
+		},
+		{
+			name:          "missing digest",
+			image:         "oci-archive:/mnt/usb/seed.tar",
+			expectedError: "must be pinned by digest",
+		},
+		{
+			name:          "invalid digest",
+			image:         "oci:/mnt/usb/seed@sha256:0123",
+			expectedError: "invalid digest sha256:0123",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := ParseLocalSource(tt.image)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, source)
+		})
+	}
+
+	assert.Equal(t, "quay.io/seed:4.16", StorageImage("quay.io/seed:4.16"))
+	assert.Equal(t, "localhost/lca-seed-image:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		StorageImage("oci:/mnt/usb/seed@"+testDigest))
+}
+
+func TestLocalSourceLoad(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+	source := &LocalSource{Transport: OCIArchiveTransport, Path: "/mnt/usb/seed.tar", Digest: testDigest}
+
+	mockExecutor.EXPECT().Execute("skopeo", "inspect", "--format", "{{.Digest}}", "oci-archive:/mnt/usb/seed.tar").
+		Return(testDigest+"\n", nil)
+	mockExecutor.EXPECT().Execute("skopeo", "copy", "--authfile", "/tmp/auth.json", "oci-archive:/mnt/usb/seed.tar",
+		"containers-storage:"+source.StorageImage()).Return("", nil)
+	assert.NoError(t, source.Load(mockExecutor, []string{"--authfile", "/tmp/auth.json"}))
+
+	mockExecutor.EXPECT().Execute("skopeo", "inspect", "--format", "{{.Digest}}", "oci-archive:/mnt/usb/seed.tar").
+		Return("sha256:fedcba", nil)
+	assert.ErrorContains(t, source.Load(mockExecutor, nil), "does not match the expected digest")
+
+	mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).Return("", errors.New("no such file or directory"))
+	assert.ErrorContains(t, source.Load(mockExecutor, nil), "failed to inspect local seed image")
+}
+
+func TestInspectLocalImage(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+
+	mockExecutor.EXPECT().Execute("skopeo", "inspect", "--retry-times", "10", "--authfile", "/tmp/auth.json",
+		"--format", "json", "oci:/mnt/usb/seed").Return(testInspectOutput, nil)
+	inspect, err := InspectImage(mockExecutor, "oci:/mnt/usb/seed@"+testDigest, "/tmp/auth.json")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), inspect.Size())
+
+	_, err = InspectImage(mockExecutor, "oci:/mnt/usb/seed", "/tmp/auth.json")
+	assert.ErrorContains(t, err, "must be pinned by digest")
+}
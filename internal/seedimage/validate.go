@@ -0,0 +1,389 @@
+package seedimage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
+)
+
+// Checks of the seed image content validation
+const (
+	CheckMetadata     = "Metadata"
+	CheckContent      = "Content"
+	CheckOstree       = "Ostree"
+	CheckCertificates = "Certificates"
+	CheckVersion      = "Version"
+)
+
+// seedMetadataFiles are the files every seed image holds, the ostree repo being either a full archive or a delta from
+// the base seed image of a layered seed image
+var seedMetadataFiles = []string{
+	common.SeedClusterInfoFileName,
+	common.ContainersListFileName,
+	"rpm-ostree.json",
+	"mco-currentconfig.json",
+	"etc.tgz",
+	"var.tgz",
+	"etc.deletions",
+}
+
+// seedCertificatesDir is the directory of the etc.tgz archive holding the certificates of the seed cluster
+const seedCertificatesDir = "etc/kubernetes/"
+
+// CheckResult is the result of one of the seed image content validation checks
+type CheckResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the result of the seed image content validation
+type Report struct {
+	Image  string        `json:"image"`
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// ValidationOptions defines what the content of the seed image is validated against
+type ValidationOptions struct {
+	// Labels are the labels of the seed image
+	Labels map[string]string
+	// Version is the version the seed image is expected to have. It is not checked if empty.
+	Version string
+	// ClusterVersion is the version of the cluster to upgrade, which the seed version must be higher than. It is not
+	// checked if empty.
+	ClusterVersion string
+	// WorkDir is the directory the certificates of the seed image are extracted to
+	WorkDir string
+	// Now is the time the certificate expirations are checked at
+	Now time.Time
+}
+
+// ValidateContent validates the content of the seed image mounted at dir, a host path, running every check so that
+// the report lists all the failures at once
+func ValidateContent(executor ops.Execute, image, dir string, opts ValidationOptions) *Report {
+	report := &Report{Image: image, Passed: true}
+	for _, check := range []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{CheckMetadata, func() (string, error) { return checkMetadata(dir) }},
+		{CheckContent, func() (string, error) { return checkContent(dir, opts.Labels) }},
+		{CheckOstree, func() (string, error) { return checkOstree(executor, dir) }},
+		{CheckCertificates, func() (string, error) { return checkCertificates(executor, dir, opts.WorkDir, opts.Now) }},
+		{CheckVersion, func() (string, error) { return checkVersion(dir, opts) }},
+	} {
+		msg, err := check.run()
+		result := CheckResult{Name: check.name, Passed: err == nil, Message: msg}
+		if err != nil {
+			result.Message = err.Error()
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// ValidateImage validates the content of the seed image once pulled in the container storage, mounting it for the
+// validation. The labels of the seed image are read from the container storage. The caller is responsible for
+// unmounting and removing the seed image.
+func ValidateImage(o ops.Ops, executor ops.Execute, image string, opts ValidationOptions) (*Report, error) {
+	storageImage := StorageImage(image)
+	inspectRaw, err := executor.Execute("podman", "image", "inspect", "--format", "json", storageImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect seed image %s: %w", storageImage, err)
+	}
+	var inspect []struct {
+		Labels map[string]string `json:"Labels"`
+	}
+	if err := json.Unmarshal([]byte(inspectRaw), &inspect); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal seed image inspect output: %w", err)
+	}
+	if len(inspect) == 0 {
+		return nil, fmt.Errorf("seed image inspect output is empty")
+	}
+	opts.Labels = inspect[0].Labels
+
+	mountpoint, err := o.MountImage(storageImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mount seed image %s: %w", storageImage, err)
+	}
+
+	workDir, err := os.MkdirTemp(common.PathOutsideChroot("/var/tmp"), "seed-validation-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the validation directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	if opts.WorkDir, err = common.PathInsideChroot(workDir); err != nil {
+		return nil, fmt.Errorf("failed to get the validation directory path on the host: %w", err)
+	}
+
+	return ValidateContent(executor, image, mountpoint, opts), nil
+}
+
+// String returns the pass/fail report of the validation, one line per check
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, check := range r.Checks {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "%s %s", status, check.Name)
+		if check.Message != "" {
+			fmt.Fprintf(&b, ": %s", check.Message)
+		}
+		b.WriteString("\n")
+	}
+	result := "PASSED"
+	if !r.Passed {
+		result = "FAILED"
+	}
+	fmt.Fprintf(&b, "Seed image %s validation %s\n", r.Image, result)
+	return b.String()
+}
+
+// Failures returns the messages of the failed checks
+func (r *Report) Failures() string {
+	var failures []string
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failures = append(failures, fmt.Sprintf("%s: %s", check.Name, check.Message))
+		}
+	}
+	return strings.Join(failures, "; ")
+}
+
+func checkMetadata(dir string) (string, error) {
+	files := append([]string{}, seedMetadataFiles...)
+	if isLayered(dir) {
+		files = append(files, common.SeedOstreeDeltaFileName)
+	} else {
+		files = append(files, "ostree.tgz")
+	}
+	var missing []string
+	for _, file := range files {
+		if _, err := os.Stat(common.PathOutsideChroot(filepath.Join(dir, file))); err != nil {
+			missing = append(missing, file)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	if _, err := readSeedClusterInfo(dir); err != nil {
+		return "", err
+	}
+	if _, err := bootedDeployment(dir); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// checkContent verifies the files of the seed image against its content manifest, and the manifest against the
+// content hash label
+func checkContent(dir string, labels map[string]string) (string, error) {
+	manifestPath := common.PathOutsideChroot(filepath.Join(dir, common.SeedContentManifestFileName))
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "seed image has no content manifest, its content is not verified", nil
+		}
+		return "", fmt.Errorf("failed to read the content manifest: %w", err)
+	}
+	if contentHash, ok := labels[common.SeedContentHashOCILabel]; ok {
+		if digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)); digest != contentHash {
+			return "", fmt.Errorf("content manifest digest %s does not match the %s label %s", digest,
+				common.SeedContentHashOCILabel, contentHash)
+		}
+	}
+
+	var mismatches []string
+	files := 0
+	scanner := bufio.NewScanner(strings.NewReader(string(manifest)))
+	for scanner.Scan() {
+		expected, rel, found := strings.Cut(scanner.Text(), "  ")
+		if !found {
+			return "", fmt.Errorf("invalid content manifest line %q", scanner.Text())
+		}
+		files++
+		digest, err := fileDigest(common.PathOutsideChroot(filepath.Join(dir, rel)))
+		if err != nil || digest != expected {
+			mismatches = append(mismatches, rel)
+		}
+	}
+	if len(mismatches) > 0 {
+		return "", fmt.Errorf("content of %s does not match the content manifest", strings.Join(mismatches, ", "))
+	}
+	return fmt.Sprintf("%d files verified", files), nil
+}
+
+// checkOstree verifies that the ostree repo archive can be read to the end and holds the booted commit of the seed
+func checkOstree(executor ops.Execute, dir string) (string, error) {
+	if isLayered(dir) {
+		return "layered seed image, the ostree delta is verified when applied to the base seed image", nil
+	}
+	deployment, err := bootedDeployment(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(deployment.Checksum) < 3 {
+		return "", fmt.Errorf("invalid booted ostree commit %q", deployment.Checksum)
+	}
+
+	commit := fmt.Sprintf("./objects/%s/%s.commit", deployment.Checksum[:2], deployment.Checksum[2:])
+	if _, err := executor.Execute("tar", "-tf", filepath.Join(dir, "ostree.tgz"), commit); err != nil {
+		return "", fmt.Errorf("ostree.tgz is corrupted or does not hold the booted commit %s: %w", deployment.Checksum, err)
+	}
+	return fmt.Sprintf("booted commit %s found", deployment.Checksum), nil
+}
+
+// checkCertificates verifies that none of the certificates of the seed cluster has expired, as recert keeps their
+// expiration when regenerating them
+func checkCertificates(executor ops.Execute, dir, workDir string, now time.Time) (string, error) {
+	archive := filepath.Join(dir, "etc.tgz")
+	listing, err := executor.Execute("tar", "-tf", archive)
+	if err != nil {
+		return "", fmt.Errorf("failed to list etc.tgz: %w", err)
+	}
+	var members []string
+	for _, member := range strings.Split(listing, "\n") {
+		if strings.HasPrefix(member, seedCertificatesDir) && (strings.HasSuffix(member, ".crt") || strings.HasSuffix(member, ".pem")) {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		return "no certificate found", nil
+	}
+	if _, err := executor.Execute("tar", append([]string{"-xf", archive, "-C", workDir}, members...)...); err != nil {
+		return "", fmt.Errorf("failed to extract the certificates from etc.tgz: %w", err)
+	}
+
+	var expired []string
+	var earliest *x509.Certificate
+	earliestFile := ""
+	for _, member := range members {
+		certs, err := readCertificates(common.PathOutsideChroot(filepath.Join(workDir, member)))
+		if err != nil {
+			return "", err
+		}
+		for _, cert := range certs {
+			if cert.NotAfter.Before(now) {
+				expired = append(expired, fmt.Sprintf("%s (%s expired on %s)", member, cert.Subject.CommonName,
+					cert.NotAfter.UTC().Format(time.RFC3339)))
+			}
+			if earliest == nil || cert.NotAfter.Before(earliest.NotAfter) {
+				earliest, earliestFile = cert, member
+			}
+		}
+	}
+	if len(expired) > 0 {
+		return "", fmt.Errorf("expired certificates: %s", strings.Join(expired, ", "))
+	}
+	if earliest == nil {
+		return "no certificate found", nil
+	}
+	return fmt.Sprintf("earliest certificate expiration on %s (%s)", earliest.NotAfter.UTC().Format(time.RFC3339),
+		earliestFile), nil
+}
+
+// checkVersion verifies the seed format version, the seed version and that the seed version is higher than the version
+// of the cluster to upgrade
+func checkVersion(dir string, opts ValidationOptions) (string, error) {
+	if format := opts.Labels[common.SeedFormatOCILabel]; format != fmt.Sprintf("%d", common.SeedFormatVersion) {
+		return "", fmt.Errorf("seed image format version mismatch: expected %d, got %q", common.SeedFormatVersion, format)
+	}
+	seedInfo, err := readSeedClusterInfo(dir)
+	if err != nil {
+		return "", err
+	}
+	seedVersion := seedInfo.SeedClusterOCPVersion
+	if opts.Version != "" && opts.Version != seedVersion {
+		return "", fmt.Errorf("seed version %s does not match the expected version %s", seedVersion, opts.Version)
+	}
+	if opts.ClusterVersion != "" {
+		seedSemVer, err := semver.NewVersion(seedVersion)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse seed version %s: %w", seedVersion, err)
+		}
+		clusterSemVer, err := semver.NewVersion(opts.ClusterVersion)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse cluster version %s: %w", opts.ClusterVersion, err)
+		}
+		if seedSemVer.Compare(*clusterSemVer) <= 0 {
+			return "", fmt.Errorf("seed version %s must be higher than the cluster version %s", seedVersion, opts.ClusterVersion)
+		}
+	}
+	return fmt.Sprintf("seed version %s", seedVersion), nil
+}
+
+func isLayered(dir string) bool {
+	_, err := os.Stat(common.PathOutsideChroot(filepath.Join(dir, common.SeedBaseImageFileName)))
+	return err == nil
+}
+
+func readSeedClusterInfo(dir string) (*seedclusterinfo.SeedClusterInfo, error) {
+	data, err := os.ReadFile(common.PathOutsideChroot(filepath.Join(dir, common.SeedClusterInfoFileName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", common.SeedClusterInfoFileName, err)
+	}
+	seedInfo := &seedclusterinfo.SeedClusterInfo{}
+	if err := json.Unmarshal(data, seedInfo); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", common.SeedClusterInfoFileName, err)
+	}
+	if seedInfo.SeedClusterOCPVersion == "" {
+		return nil, fmt.Errorf("%s does not record the seed version", common.SeedClusterInfoFileName)
+	}
+	return seedInfo, nil
+}
+
+func bootedDeployment(dir string) (*rpmostreeclient.Deployment, error) {
+	data, err := os.ReadFile(common.PathOutsideChroot(filepath.Join(dir, "rpm-ostree.json")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rpm-ostree.json: %w", err)
+	}
+	var status rpmostreeclient.Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode rpm-ostree.json: %w", err)
+	}
+	for i := range status.Deployments {
+		if status.Deployments[i].Booted {
+			return &status.Deployments[i], nil
+		}
+	}
+	return nil, fmt.Errorf("rpm-ostree.json has no booted deployment")
+}
+
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse a certificate of %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
@@ -0,0 +1,184 @@
+package seedimage
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const testBootedCommit = "abcdef0123456789"
+
+func writeTestCertificate(t *testing.T, path string, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kube-apiserver-lb-signer"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+}
+
+// newTestSeedDir returns the content of a seed image, along with its labels
+func newTestSeedDir(t *testing.T) (string, map[string]string) {
+	dir := t.TempDir()
+	files := map[string]string{
+		common.SeedClusterInfoFileName: `{"seed_cluster_ocp_version":"4.16.1"}`,
+		common.ContainersListFileName:  "quay.io/openshift-release-dev/ocp-release@sha256:0123\n",
+		"rpm-ostree.json":              `{"deployments":[{"id":"rhcos-0","checksum":"` + testBootedCommit + `","booted":true}]}`,
+		"mco-currentconfig.json":       "{}",
+		"etc.tgz":                      "etc",
+		"var.tgz":                      "var",
+		"etc.deletions":                "",
+		"ostree.tgz":                   "ostree",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	contentHash, err := WriteContentManifest(dir)
+	assert.NoError(t, err)
+	return dir, map[string]string{
+		common.SeedFormatOCILabel:      "4",
+		common.SeedContentHashOCILabel: contentHash,
+	}
+}
+
+func TestValidateContent(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	certificate := "etc/kubernetes/static-pod-resources/kube-apiserver-certs/secrets/lb-signer/tls.crt"
+
+	tests := []struct {
+		name             string
+		prepare          func(t *testing.T, dir string, labels map[string]string)
+		certExpiration   time.Time
+		clusterVersion   string
+		ostreeErr        error
+		expectedFailures map[string]string
+	}{
+		{
+			name:           "valid seed image",
+			certExpiration: now.Add(24 * time.Hour),
+			clusterVersion: "4.15.20",
+		},
+		{
+			name: "missing metadata file",
+			prepare: func(t *testing.T, dir string, _ map[string]string) {
+				assert.NoError(t, os.Remove(filepath.Join(dir, "mco-currentconfig.json")))
+			},
+			certExpiration: now.Add(24 * time.Hour),
+			expectedFailures: map[string]string{
+				CheckMetadata: "missing mco-currentconfig.json",
+				CheckContent:  "content of mco-currentconfig.json does not match",
+			},
+		},
+		{
+			name: "tampered content",
+			prepare: func(t *testing.T, dir string, _ map[string]string) {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, "var.tgz"), []byte("tampered"), 0o600))
+			},
+			certExpiration:   now.Add(24 * time.Hour),
+			expectedFailures: map[string]string{CheckContent: "content of var.tgz does not match"},
+		},
+		{
+			name: "content manifest not matching the label",
+			prepare: func(t *testing.T, _ string, labels map[string]string) {
+				labels[common.SeedContentHashOCILabel] = "sha256:0123"
+			},
+			certExpiration:   now.Add(24 * time.Hour),
+			expectedFailures: map[string]string{CheckContent: "does not match the " + common.SeedContentHashOCILabel},
+		},
+		{
+			name:             "corrupted ostree repo",
+			certExpiration:   now.Add(24 * time.Hour),
+			ostreeErr:        assert.AnError,
+			expectedFailures: map[string]string{CheckOstree: "does not hold the booted commit " + testBootedCommit},
+		},
+		{
+			name:             "expired certificate",
+			certExpiration:   now.Add(-time.Hour),
+			expectedFailures: map[string]string{CheckCertificates: "expired certificates: " + certificate},
+		},
+		{
+			name: "unsupported seed format",
+			prepare: func(t *testing.T, _ string, labels map[string]string) {
+				labels[common.SeedFormatOCILabel] = "3"
+			},
+			certExpiration:   now.Add(24 * time.Hour),
+			expectedFailures: map[string]string{CheckVersion: "seed image format version mismatch"},
+		},
+		{
+			name:             "seed version not higher than the cluster version",
+			certExpiration:   now.Add(24 * time.Hour),
+			clusterVersion:   "4.16.1",
+			expectedFailures: map[string]string{CheckVersion: "must be higher than the cluster version 4.16.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			mockExecutor := ops.NewMockExecute(mockController)
+
+			dir, labels := newTestSeedDir(t)
+			workDir := t.TempDir()
+			if tt.prepare != nil {
+				tt.prepare(t, dir, labels)
+			}
+			mockExecutor.EXPECT().Execute("tar", "-tf", filepath.Join(dir, "ostree.tgz"),
+				"./objects/ab/cdef0123456789.commit").Return("", tt.ostreeErr)
+			mockExecutor.EXPECT().Execute("tar", "-tf", filepath.Join(dir, "etc.tgz")).
+				Return("etc/hosts\n"+certificate+"\n", nil)
+			mockExecutor.EXPECT().Execute("tar", "-xf", filepath.Join(dir, "etc.tgz"), "-C", workDir, certificate).
+				DoAndReturn(func(string, ...string) (string, error) {
+					writeTestCertificate(t, filepath.Join(workDir, certificate), tt.certExpiration)
+					return "", nil
+				})
+
+			report := ValidateContent(mockExecutor, "quay.io/seed:4.16.1", dir, ValidationOptions{
+				Labels:         labels,
+				Version:        "4.16.1",
+				ClusterVersion: tt.clusterVersion,
+				WorkDir:        workDir,
+				Now:            now,
+			})
+			assert.Equal(t, len(tt.expectedFailures) == 0, report.Passed, report.String())
+			assert.Len(t, report.Checks, 5)
+			for _, check := range report.Checks {
+				expected, failed := tt.expectedFailures[check.Name]
+				assert.Equal(t, !failed, check.Passed, check.Name)
+				if failed {
+					assert.Contains(t, check.Message, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	report := &Report{Image: "quay.io/seed:4.16.1", Checks: []CheckResult{
+		{Name: CheckMetadata, Passed: true},
+		{Name: CheckVersion, Message: "seed version 4.16.0 does not match the expected version 4.16.1"},
+	}}
+	assert.Equal(t, "PASS Metadata\n"+
+		"FAIL Version: seed version 4.16.0 does not match the expected version 4.16.1\n"+
+		"Seed image quay.io/seed:4.16.1 validation FAILED\n", report.String())
+	assert.Equal(t, "Version: seed version 4.16.0 does not match the expected version 4.16.1", report.Failures())
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package seedregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/go-logr/logr"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const (
+	// DefaultDir is the directory of the node where the imported seed images are stored, one OCI layout per repository
+	DefaultDir = common.LCAConfigDir + "/seed-registry"
+	// DefaultAddress is the address the seed registry listens on
+	DefaultAddress = ":5050"
+
+	// refNameAnnotation holds the tag of a manifest in the index of an OCI layout
+	refNameAnnotation = "org.opencontainers.image.ref.name"
+	indexFile         = "index.json"
+	blobsDir          = "blobs"
+)
+
+var (
+	digestRegexp = regexp.MustCompile(`^(sha256:[a-f0-9]{64}|sha512:[a-f0-9]{128})$`)
+	// repositoryRegexp matches the repository paths of the distribution API, whose components cannot be . nor ..
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+)
+
+// descriptor is the subset of an OCI content descriptor used to serve the manifests
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// index is the subset of the OCI image index of a layout used to serve the manifests
+type index struct {
+	Manifests []descriptor `json:"manifests"`
+}
+
+// RepositoryTag returns the repository path of the image, without its registry, and its tag. The image is pulled from
+// the seed registry as <seed registry address>/<repository>:<tag>.
+func RepositoryTag(image string) (string, string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid image %s: %w", image, err)
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return "", "", fmt.Errorf("image %s must be referenced by tag", image)
+	}
+	tagged := reference.TagNameOnly(named).(reference.NamedTagged)
+	return reference.Path(named), tagged.Tag(), nil
+}
+
+// ImportImage copies a seed image from a portable medium, such as an oci-archive or a docker-archive produced by
+// skopeo copy, into the OCI layout of its repository under dir. The source without a transport is read as an
+// oci-archive. It returns the <repository>:<tag> the image is served as.
+func ImportImage(executor ops.Execute, source, image, dir string) (string, error) {
+	repository, tag, err := RepositoryTag(image)
+	if err != nil {
+		return "", err
+	}
+	layout := filepath.Join(dir, repository)
+	if !strings.Contains(source, ":") {
+		source = "oci-archive:" + source
+	}
+	if err := os.MkdirAll(layout, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create the seed registry directory %s: %w", layout, err)
+	}
+
+	if _, err := executor.Execute("skopeo", "copy", "--retry-times", "3", source, fmt.Sprintf("oci:%s:%s", layout, tag)); err != nil {
+		return "", fmt.Errorf("failed to import %s into the seed registry: %w", source, err)
+	}
+	return fmt.Sprintf("%s:%s", repository, tag), nil
+}
+
+// Server serves the seed images stored in Dir over a read-only registry endpoint implementing the pull side of the OCI
+// distribution API, so that the seed image can be pulled on the node, or from a peer node, without a mirror registry.
+type Server struct {
+	// Address is the address to listen on
+	Address string
+	// Dir holds the OCI layouts of the served images
+	Dir string
+	// CertFile and KeyFile enable TLS when set
+	CertFile string
+	KeyFile  string
+	Log      logr.Logger
+}
+
+// Start serves the seed registry until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Address, err)
+	}
+
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.Log.Error(err, "Failed to shut down the seed registry")
+		}
+	}()
+
+	s.Log.Info("Serving the seed registry", "address", s.Address, "dir", s.Dir, "tls", s.CertFile != "")
+	if s.CertFile != "" {
+		err = server.ServeTLS(listener, s.CertFile, s.KeyFile)
+	} else {
+		err = server.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("seed registry failed: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the handler of the registry endpoints
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(s.handle)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "the seed registry is read-only")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	if path == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	if path == "" {
+		s.writeJSON(w, struct{}{})
+		return
+	}
+
+	for _, endpoint := range []string{"/manifests/", "/blobs/", "/tags/"} {
+		i := strings.LastIndex(path, endpoint)
+		if i <= 0 {
+			continue
+		}
+		name, ref := path[:i], path[i+len(endpoint):]
+		layout, ok := s.layout(name)
+		if !ok {
+			s.writeError(w, http.StatusNotFound, "NAME_UNKNOWN", fmt.Sprintf("repository %s is not known to the seed registry", name))
+			return
+		}
+		switch endpoint {
+		case "/manifests/":
+			s.serveManifest(w, r, layout, ref)
+		case "/blobs/":
+			s.serveBlob(w, r, layout, ref)
+		default:
+			if ref != "list" {
+				http.NotFound(w, r)
+				return
+			}
+			s.serveTags(w, name, layout)
+		}
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// layout returns the OCI layout of the repository, if the repository is a valid name stored in the seed registry
+func (s *Server) layout(name string) (string, bool) {
+	if !repositoryRegexp.MatchString(name) {
+		return "", false
+	}
+	layout := filepath.Join(s.Dir, name)
+	if _, err := os.Stat(filepath.Join(layout, indexFile)); err != nil {
+		return "", false
+	}
+	return layout, true
+}
+
+func readIndex(layout string) (*index, error) {
+	raw, err := os.ReadFile(filepath.Join(layout, indexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the index of %s: %w", layout, err)
+	}
+	idx := &index{}
+	if err := json.Unmarshal(raw, idx); err != nil {
+		return nil, fmt.Errorf("failed to decode the index of %s: %w", layout, err)
+	}
+	return idx, nil
+}
+
+func blobPath(layout, digest string) string {
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+	return filepath.Join(layout, blobsDir, algorithm, encoded)
+}
+
+// serveManifest serves the manifest referenced by tag, as recorded in the index of the layout, or by digest
+func (s *Server) serveManifest(w http.ResponseWriter, r *http.Request, layout, ref string) {
+	idx, err := readIndex(layout)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "UNKNOWN", err.Error())
+		return
+	}
+
+	var manifest *descriptor
+	for i, desc := range idx.Manifests {
+		if desc.Digest == ref || desc.Annotations[refNameAnnotation] == ref {
+			manifest = &idx.Manifests[i]
+			break
+		}
+	}
+	if manifest == nil && digestRegexp.MatchString(ref) {
+		// a manifest referenced by a manifest list, only stored as a blob
+		manifest = &descriptor{Digest: ref}
+	}
+	if manifest == nil || !digestRegexp.MatchString(manifest.Digest) {
+		s.writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", fmt.Sprintf("manifest %s is not known to the seed registry", ref))
+		return
+	}
+
+	content, err := os.ReadFile(blobPath(layout, manifest.Digest))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", fmt.Sprintf("manifest %s is not known to the seed registry", ref))
+		return
+	}
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		var m struct {
+			MediaType string `json:"mediaType"`
+		}
+		_ = json.Unmarshal(content, &m)
+		mediaType = m.MediaType
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Docker-Content-Digest", manifest.Digest)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+}
+
+// serveBlob serves a layer or config blob of the layout
+func (s *Server) serveBlob(w http.ResponseWriter, r *http.Request, layout, digest string) {
+	if !digestRegexp.MatchString(digest) {
+		s.writeError(w, http.StatusBadRequest, "DIGEST_INVALID", fmt.Sprintf("invalid digest %s", digest))
+		return
+	}
+	f, err := os.Open(blobPath(layout, digest))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "BLOB_UNKNOWN", fmt.Sprintf("blob %s is not known to the seed registry", digest))
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", digest)
+	http.ServeContent(w, r, "", time.Time{}, f)
+}
+
+func (s *Server) serveTags(w http.ResponseWriter, name, layout string) {
+	idx, err := readIndex(layout)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "UNKNOWN", err.Error())
+		return
+	}
+	tags := []string{}
+	for _, desc := range idx.Manifests {
+		if tag, ok := desc.Annotations[refNameAnnotation]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	s.writeJSON(w, map[string]any{"name": name, "tags": tags})
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	body := map[string]any{"errors": []map[string]string{{"code": code, "message": message}}}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.Log.Error(err, "Failed to write the seed registry error")
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		s.Log.Error(err, "Failed to write the seed registry response")
+	}
+}
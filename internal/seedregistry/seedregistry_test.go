@@ -0,0 +1,134 @@
+package seedregistry
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+// writeBlob stores content in the blobs of the layout and returns its digest
+func writeBlob(t *testing.T, layout string, content []byte) string {
+	encoded := fmt.Sprintf("%x", sha256.Sum256(content))
+	assert.NoError(t, os.MkdirAll(filepath.Join(layout, "blobs", "sha256"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(layout, "blobs", "sha256", encoded), content, 0o600))
+	return "sha256:" + encoded
+}
+
+func TestRepositoryTag(t *testing.T) {
+	repository, tag, err := RepositoryTag("quay.io/org/seed:4.16")
+	assert.NoError(t, err)
+	assert.Equal(t, "org/seed", repository)
+	assert.Equal(t, "4.16", tag)
+
+	repository, tag, err = RepositoryTag("seed")
+	assert.NoError(t, err)
+	assert.Equal(t, "library/seed", repository)
+	assert.Equal(t, "latest", tag)
+
+	_, _, err = RepositoryTag("quay.io/org/seed@sha256:" + fmt.Sprintf("%064d", 0))
+	assert.ErrorContains(t, err, "must be referenced by tag")
+}
+
+func TestImportImage(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockExecutor := ops.NewMockExecute(mockController)
+	dir := t.TempDir()
+
+	mockExecutor.EXPECT().Execute("skopeo", "copy", "--retry-times", "3", "oci-archive:/mnt/usb/seed.tar",
+		"oci:"+filepath.Join(dir, "org/seed")+":4.16").Return("", nil)
+	served, err := ImportImage(mockExecutor, "/mnt/usb/seed.tar", "quay.io/org/seed:4.16", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "org/seed:4.16", served)
+	assert.DirExists(t, filepath.Join(dir, "org/seed"))
+
+	mockExecutor.EXPECT().Execute("skopeo", "copy", "--retry-times", "3", "docker-archive:/mnt/usb/seed.tar",
+		gomock.Any()).Return("", fmt.Errorf("no such file"))
+	_, err = ImportImage(mockExecutor, "docker-archive:/mnt/usb/seed.tar", "quay.io/org/seed:4.16", dir)
+	assert.ErrorContains(t, err, "failed to import docker-archive:/mnt/usb/seed.tar into the seed registry: no such file")
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	layout := filepath.Join(dir, "org", "seed")
+	layer := []byte("seed layer")
+	layerDigest := writeBlob(t, layout, layer)
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"layers":[{"digest":%q}]}`, manifestMediaType, layerDigest))
+	manifestDigest := writeBlob(t, layout, manifest)
+	idx, _ := json.Marshal(index{Manifests: []descriptor{{
+		MediaType:   manifestMediaType,
+		Digest:      manifestDigest,
+		Annotations: map[string]string{refNameAnnotation: "4.16"},
+	}}})
+	assert.NoError(t, os.WriteFile(filepath.Join(layout, "index.json"), idx, 0o600))
+
+	server := httptest.NewServer((&Server{Dir: dir, Log: logr.Discard()}).Handler())
+	defer server.Close()
+
+	get := func(method, path string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, _ := get(http.MethodGet, "/v2/")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "registry/2.0", resp.Header.Get("Docker-Distribution-API-Version"))
+
+	for _, ref := range []string{"4.16", manifestDigest} {
+		resp, body := get(http.MethodGet, "/v2/org/seed/manifests/"+ref)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, manifestMediaType, resp.Header.Get("Content-Type"))
+		assert.Equal(t, manifestDigest, resp.Header.Get("Docker-Content-Digest"))
+		assert.Equal(t, string(manifest), body)
+	}
+
+	resp, body := get(http.MethodGet, "/v2/org/seed/blobs/"+layerDigest)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, string(layer), body)
+
+	resp, _ = get(http.MethodHead, "/v2/org/seed/blobs/"+layerDigest)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fmt.Sprint(len(layer)), resp.Header.Get("Content-Length"))
+
+	resp, body = get(http.MethodGet, "/v2/org/seed/tags/list")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"name":"org/seed","tags":["4.16"]}`, body)
+
+	resp, body = get(http.MethodGet, "/v2/org/seed/manifests/4.17")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, body, "MANIFEST_UNKNOWN")
+
+	resp, body = get(http.MethodGet, "/v2/org/seed/blobs/sha256:"+fmt.Sprintf("%064d", 0))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, body, "BLOB_UNKNOWN")
+
+	resp, body = get(http.MethodGet, "/v2/org/seed/blobs/../../index.json")
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, body, manifestDigest)
+
+	resp, body = get(http.MethodGet, "/v2/org/other/manifests/4.16")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, body, "NAME_UNKNOWN")
+
+	resp, body = get(http.MethodPut, "/v2/org/seed/manifests/4.16")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Contains(t, body, "UNSUPPORTED")
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook validates the edits of the LCA custom resources
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+)
+
+// The webhook is ignored on failure, as the LCA pod is restarted during the upgrade, and the CRD validation rules still
+// apply. It reports the reason of a rejected edit more precisely than these rules.
+//+kubebuilder:webhook:path=/validate-lca-openshift-io-v1-imagebasedupgrade,mutating=false,failurePolicy=ignore,sideEffects=None,groups=lca.openshift.io,resources=imagebasedupgrades,verbs=update,versions=v1,name=vimagebasedupgrade.lca.openshift.io,admissionReviewVersions=v1
+
+// lockedFields are the spec fields that can only be changed while the IBU is Idle, or along with a transition to Idle
+// The maintenanceWindow is not locked, so that a transition held until the window opens can be rescheduled. It is
+// only evaluated when a transition into the Upgrade or Rollback stage is requested.
+var lockedFields = []struct {
+	name  string
+	value func(spec *ibuv1.ImageBasedUpgradeSpec) any
+}{
+	{"seedImageRef", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.SeedImageRef }},
+	{"oadpContent", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.OADPContent }},
+	{"oadpConfig", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.OADPConfig }},
+	{"extraManifests", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.ExtraManifests }},
+	{"autoRollbackOnFailure", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.AutoRollbackOnFailure }},
+	{"precache", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.Precache }},
+	{"validateOnly", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.ValidateOnly }},
+	{"healthChecks", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.HealthChecks }},
+	{"healthCheckConfig", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.HealthCheckConfig }},
+	{"mirrorRegistryConfig", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.MirrorRegistryConfig }},
+	{"diskSpaceValidation", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.DiskSpaceValidation }},
+	{"staterootSetup", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.StaterootSetup }},
+	{"staterootRetention", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.StaterootRetention }},
+	{"nodeMetadata", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.NodeMetadata }},
+	{"preservedPaths", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.PreservedPaths }},
+	{"rollbackRetentionHours", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.RollbackRetentionHours }},
+	{"hooks", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.Hooks }},
+	{"stallDetection", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.StallDetection }},
+	{"validateSeedContent", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.ValidateSeedContent }},
+	{"certificateRegeneration", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.CertificateRegeneration }},
+	{"additionalTrustedCABundles", func(spec *ibuv1.ImageBasedUpgradeSpec) any { return spec.AdditionalTrustedCABundles }},
+}
+
+// ImageBasedUpgradeValidator rejects the IBU spec edits that the controller would not act on
+type ImageBasedUpgradeValidator struct{}
+
+// SetupImageBasedUpgradeWebhookWithManager registers the IBU validating webhook with the manager
+func SetupImageBasedUpgradeWebhookWithManager(mgr ctrl.Manager) error {
+	//nolint:wrapcheck
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&ibuv1.ImageBasedUpgrade{}).
+		WithValidator(&ImageBasedUpgradeValidator{}).
+		Complete()
+}
+
+// ValidateCreate accepts any IBU, as the CRD validation rules check the only constraint on creation
+func (v *ImageBasedUpgradeValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate rejects the stage transitions that are not part of status.validNextStages, and the changes to the
+// spec fields that are locked while an upgrade is in progress
+func (v *ImageBasedUpgradeValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldIbu, ok := oldObj.(*ibuv1.ImageBasedUpgrade)
+	if !ok {
+		return nil, fmt.Errorf("expected an ImageBasedUpgrade but got %T", oldObj)
+	}
+	newIbu, ok := newObj.(*ibuv1.ImageBasedUpgrade)
+	if !ok {
+		return nil, fmt.Errorf("expected an ImageBasedUpgrade but got %T", newObj)
+	}
+
+	// The status is not reported yet, e.g. when the CR is restored after a pivot
+	if len(oldIbu.Status.Conditions) == 0 {
+		return nil, nil
+	}
+
+	if err := validateStageTransition(oldIbu, newIbu); err != nil {
+		return nil, err
+	}
+	return nil, validateLockedFields(oldIbu, newIbu)
+}
+
+// ValidateDelete accepts any deletion
+func (v *ImageBasedUpgradeValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateStageTransition(oldIbu, newIbu *ibuv1.ImageBasedUpgrade) error {
+	from, to := oldIbu.Spec.Stage, newIbu.Spec.Stage
+	if from == to || lo.Contains(oldIbu.Status.ValidNextStages, to) {
+		return nil
+	}
+
+	if len(oldIbu.Status.ValidNextStages) == 0 {
+		return fmt.Errorf("the stage transition from %s to %s is not permitted: no transitions are currently allowed, "+
+			"the %s stage must complete first", from, to, from)
+	}
+	return fmt.Errorf("the stage transition from %s to %s is not permitted: the valid next stages are %v",
+		from, to, oldIbu.Status.ValidNextStages)
+}
+
+func validateLockedFields(oldIbu, newIbu *ibuv1.ImageBasedUpgrade) error {
+	if newIbu.Spec.Stage == ibuv1.Stages.Idle || isIdle(oldIbu) {
+		return nil
+	}
+
+	for _, field := range lockedFields {
+		if !reflect.DeepEqual(field.value(&oldIbu.Spec), field.value(&newIbu.Spec)) {
+			return fmt.Errorf("spec.%s can not be changed while the ibu is in the %s stage, "+
+				"it can only be changed when the ibu is Idle or along with a transition to Idle", field.name, oldIbu.Spec.Stage)
+		}
+	}
+	return nil
+}
+
+func isIdle(ibu *ibuv1.ImageBasedUpgrade) bool {
+	return meta.IsStatusConditionPresentAndEqual(ibu.Status.Conditions, string(utils.ConditionTypes.Idle), metav1.ConditionTrue)
+}
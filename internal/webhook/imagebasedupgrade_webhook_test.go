@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+)
+
+func newIBU(stage ibuv1.ImageBasedUpgradeStage, validNextStages ...ibuv1.ImageBasedUpgradeStage) *ibuv1.ImageBasedUpgrade {
+	ibu := &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{
+		Stage:        stage,
+		SeedImageRef: ibuv1.SeedImageRef{Image: "quay.io/seed:4.16.0", Version: "4.16.0"},
+		OADPContent:  []ibuv1.ConfigMapRef{{Name: "oadp", Namespace: "openshift-adp"}},
+	}}
+	switch stage {
+	case ibuv1.Stages.Idle:
+		utils.SetStatusCondition(&ibu.Status.Conditions, utils.ConditionTypes.Idle, utils.ConditionReasons.Idle, metav1.ConditionTrue, "Idle", ibu.Generation)
+	case ibuv1.Stages.Prep:
+		utils.SetPrepStatusInProgress(ibu, "Prep in progress")
+	case ibuv1.Stages.Upgrade:
+		utils.SetUpgradeStatusInProgress(ibu, "Upgrade in progress")
+	}
+	ibu.Status.ValidNextStages = validNextStages
+	return ibu
+}
+
+func TestValidateUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		old         *ibuv1.ImageBasedUpgrade
+		update      func(ibu *ibuv1.ImageBasedUpgrade)
+		expectedErr string
+	}{
+		{
+			name:   "valid transition",
+			old:    newIBU(ibuv1.Stages.Idle, ibuv1.Stages.Prep),
+			update: func(ibu *ibuv1.ImageBasedUpgrade) { ibu.Spec.Stage = ibuv1.Stages.Prep },
+		},
+		{
+			name:        "invalid transition",
+			old:         newIBU(ibuv1.Stages.Idle, ibuv1.Stages.Prep),
+			update:      func(ibu *ibuv1.ImageBasedUpgrade) { ibu.Spec.Stage = ibuv1.Stages.Rollback },
+			expectedErr: "the stage transition from Idle to Rollback is not permitted: the valid next stages are [Prep]",
+		},
+		{
+			name:        "no transition allowed",
+			old:         newIBU(ibuv1.Stages.Upgrade),
+			update:      func(ibu *ibuv1.ImageBasedUpgrade) { ibu.Spec.Stage = ibuv1.Stages.Idle },
+			expectedErr: "the stage transition from Upgrade to Idle is not permitted: no transitions are currently allowed, the Upgrade stage must complete first",
+		},
+		{
+			name:   "seedImageRef changed while Idle",
+			old:    newIBU(ibuv1.Stages.Idle, ibuv1.Stages.Prep),
+			update: func(ibu *ibuv1.ImageBasedUpgrade) { ibu.Spec.SeedImageRef.Version = "4.16.1" },
+		},
+		{
+			name:        "seedImageRef changed while in progress",
+			old:         newIBU(ibuv1.Stages.Prep, ibuv1.Stages.Idle),
+			update:      func(ibu *ibuv1.ImageBasedUpgrade) { ibu.Spec.SeedImageRef.Version = "4.16.1" },
+			expectedErr: "spec.seedImageRef can not be changed while the ibu is in the Prep stage, it can only be changed when the ibu is Idle or along with a transition to Idle",
+		},
+		{
+			name:        "oadpContent changed while in progress",
+			old:         newIBU(ibuv1.Stages.Upgrade, ibuv1.Stages.Rollback),
+			update:      func(ibu *ibuv1.ImageBasedUpgrade) { ibu.Spec.OADPContent = nil },
+			expectedErr: "spec.oadpContent can not be changed while the ibu is in the Upgrade stage",
+		},
+		{
+			name: "maintenanceWindow changed while in progress",
+			old:  newIBU(ibuv1.Stages.Prep, ibuv1.Stages.Idle, ibuv1.Stages.Upgrade),
+			update: func(ibu *ibuv1.ImageBasedUpgrade) {
+				ibu.Spec.MaintenanceWindow = &ibuv1.MaintenanceWindow{Duration: metav1.Duration{Duration: 4 * time.Hour}}
+			},
+		},
+		{
+			name: "seedImageRef changed along with abort",
+			old:  newIBU(ibuv1.Stages.Prep, ibuv1.Stages.Idle),
+			update: func(ibu *ibuv1.ImageBasedUpgrade) {
+				ibu.Spec.Stage = ibuv1.Stages.Idle
+				ibu.Spec.SeedImageRef.Version = "4.16.1"
+			},
+		},
+		{
+			name: "no status",
+			old:  &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{Stage: ibuv1.Stages.Upgrade}},
+			update: func(ibu *ibuv1.ImageBasedUpgrade) {
+				ibu.Spec.Stage = ibuv1.Stages.Rollback
+				ibu.Spec.SeedImageRef.Version = "4.16.1"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := tt.old.DeepCopy()
+			tt.update(updated)
+
+			_, err := (&ImageBasedUpgradeValidator{}).ValidateUpdate(context.Background(), tt.old, updated)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
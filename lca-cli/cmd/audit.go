@@ -0,0 +1,93 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/audit"
+)
+
+var (
+	// auditExportOutput is the file the audit events are exported to, stdout if empty
+	auditExportOutput string
+	// auditExportSince only exports the audit events recorded since the given time
+	auditExportSince string
+)
+
+// auditCmd groups the commands operating on the audit log
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit log commands",
+}
+
+// auditExportCmd represents the audit export command
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the audit trail of the upgrade actions performed on the node.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := exportAudit(cmd); err != nil {
+			log.Fatalf("Error executing audit export command: %v", err)
+		}
+	},
+}
+
+func init() {
+
+	// Add audit command and its subcommands
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditExportCmd)
+
+	// Add flags to audit export command
+	auditExportCmd.Flags().StringVarP(&auditExportOutput, "output", "o", "", "The file the audit events are exported to. Defaults to stdout.")
+	auditExportCmd.Flags().StringVar(&auditExportSince, "since", "", "Only export the audit events recorded since the given RFC 3339 time.")
+}
+
+func exportAudit(cmd *cobra.Command) error {
+	var since time.Time
+	if auditExportSince != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, auditExportSince); err != nil {
+			return fmt.Errorf("invalid --since time %s: %w", auditExportSince, err)
+		}
+	}
+
+	events, err := audit.ReadEvents(auditLog.File(), since)
+	if err != nil {
+		return fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	output, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the audit events: %w", err)
+	}
+
+	if auditExportOutput == "" {
+		fmt.Fprintln(cmd.OutOrStdout(), string(output))
+		return nil
+	}
+	if err := os.WriteFile(auditExportOutput, append(output, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write the audit events to %s: %w", auditExportOutput, err)
+	}
+	log.Infof("Exported %d audit events to %s", len(events), auditExportOutput)
+	return nil
+}
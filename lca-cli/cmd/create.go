@@ -34,6 +34,7 @@ import (
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	ostree "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedcreator"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedrestoration"
 )
@@ -52,6 +53,32 @@ var (
 	recertSkipValidation bool
 
 	skipCleanup bool
+
+	// encryptionKey is the ocicrypt encryption key used to encrypt the layers of the OCI image, such as jwe:/path/to/key.pem
+	encryptionKey string
+
+	// baseSeedImage is the seed image on top of which a layered OCI image is built
+	baseSeedImage string
+
+	// digestFile is the file to which the digest of the pushed OCI image is written
+	digestFile string
+
+	// manifestList is the manifest list to which the pushed OCI image is added, replacing the image of the same
+	// architecture
+	manifestList string
+
+	// compression and compressionLevel are the compression algorithm and level of the OCI image archives
+	compression      string
+	compressionLevel int
+
+	// etcdContent is the etcd content of the OCI image, Full, Trimmed or Excluded
+	etcdContent string
+
+	// excludePaths, excludeSecrets and excludeNamespaces are the content excluded from the OCI image, which is
+	// recorded in the seed metadata
+	excludePaths      []string
+	excludeSecrets    []string
+	excludeNamespaces []string
 )
 
 func init() {
@@ -82,6 +109,16 @@ func init() {
 
 	// Add flags to create command
 	addCommonFlags(createCmd)
+	createCmd.Flags().StringArrayVar(&excludePaths, "exclude-path", nil, "A /var or /etc path to exclude from the OCI image (repeatable).")
+	createCmd.Flags().StringArrayVar(&excludeSecrets, "exclude-secret", nil, "A <namespace>/<name pattern> of the secrets excluded from the OCI image (repeatable).")
+	createCmd.Flags().StringArrayVar(&excludeNamespaces, "exclude-namespace", nil, "A namespace excluded from the OCI image (repeatable).")
+	createCmd.Flags().StringVarP(&encryptionKey, "encryption-key", "", "", "The key used to encrypt the layers of the OCI image, in the ocicrypt format (e.g. jwe:/path/to/public-key.pem).")
+	createCmd.Flags().StringVarP(&baseSeedImage, "base-seed-image", "", "", "A full seed image on top of which a layered OCI image is built, only including the ostree changes since.")
+	createCmd.Flags().StringVarP(&digestFile, "digestfile", "", "", "A file to which the digest of the pushed OCI image is written.")
+	createCmd.Flags().StringVarP(&manifestList, "manifest-list", "", "", "A manifest list to which the pushed OCI image is added, replacing the image of the same architecture.")
+	createCmd.Flags().StringVarP(&compression, "compression", "", common.SeedCompressionGzip, "The compression algorithm of the OCI image archives (gzip or zstd).")
+	createCmd.Flags().IntVarP(&compressionLevel, "compression-level", "", 0, "The compression level of the OCI image archives. Defaults to the default level of the compression algorithm.")
+	createCmd.Flags().StringVarP(&etcdContent, "etcd-content", "", common.SeedEtcdFull, "The etcd content of the OCI image (Full, Trimmed or Excluded).")
 }
 
 func create() error {
@@ -113,8 +150,24 @@ func create() error {
 		return fmt.Errorf("failed to create runtime client: %w", err)
 	}
 
+	exclusions := &seedclusterinfo.SeedExclusions{
+		Paths:              excludePaths,
+		SecretNamePatterns: excludeSecrets,
+		Namespaces:         excludeNamespaces,
+	}
+
+	if compression != common.SeedCompressionGzip && compression != common.SeedCompressionZstd {
+		return fmt.Errorf("unsupported compression %s, must be %s or %s", compression, common.SeedCompressionGzip, common.SeedCompressionZstd)
+	}
+
+	if etcdContent != common.SeedEtcdFull && etcdContent != common.SeedEtcdTrimmed && etcdContent != common.SeedEtcdExcluded {
+		return fmt.Errorf("unsupported etcd content %s, must be %s, %s or %s", etcdContent, common.SeedEtcdFull,
+			common.SeedEtcdTrimmed, common.SeedEtcdExcluded)
+	}
+
 	seedCreator := seedcreator.NewSeedCreator(client, log, op, rpmOstreeClient, common.BackupDir, common.KubeconfigFile,
-		containerRegistry, authFile, recertContainerImage, recertSkipValidation)
+		containerRegistry, authFile, recertContainerImage, recertSkipValidation, encryptionKey, baseSeedImage, digestFile, exclusions,
+		seedcreator.Compression{Algorithm: compression, Level: compressionLevel}, etcdContent, manifestList)
 	if err = seedCreator.CreateSeedImage(); err != nil {
 		err = fmt.Errorf("failed to create seed image: %w", err)
 		log.Error(err)
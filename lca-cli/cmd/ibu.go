@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	intOstree "github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ibu"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	rpmOstree "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+)
+
+var (
+	ibuRollbackReason   string
+	ibuRollbackNoReboot bool
+)
+
+var ibuCmd = &cobra.Command{
+	Use:   "ibu",
+	Short: "Image based upgrade host-side operations",
+}
+
+var ibuRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back an image based upgrade to the original stateroot from the node",
+	Long: `Roll back an image based upgrade to the original stateroot from the node, without the API server.
+The original stateroot is set as the default deployment and the node is rebooted. The rollback is
+completed and reported by the Lifecycle Agent once the cluster is back.`,
+	Annotations: map[string]string{auditAnnotation: string(ibuv1.Stages.Rollback)},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runIBURollback(); err != nil {
+			log.Fatalf("Error executing ibu rollback: %v", err)
+		}
+	},
+}
+
+func init() {
+	ibuRollbackCmd.Flags().StringVar(&ibuRollbackReason, "reason", "", "Reason for the rollback, reported by the Lifecycle Agent")
+	ibuRollbackCmd.Flags().BoolVar(&ibuRollbackNoReboot, "no-reboot", false, "Do not reboot the node once the rollback is set up")
+
+	ibuCmd.AddCommand(ibuRollbackCmd)
+	rootCmd.AddCommand(ibuCmd)
+}
+
+func runIBURollback() error {
+	var hostCommandsExecutor ops.Execute
+	if _, err := os.Stat(common.Host); err == nil {
+		hostCommandsExecutor = ops.NewChrootExecutor(log, true, common.Host)
+	} else {
+		hostCommandsExecutor = ops.NewRegularExecutor(log, true)
+	}
+	opsInterface := ops.NewOps(log, hostCommandsExecutor)
+	rpmClient := rpmOstree.NewClient("lca-cli-ibu-rollback", hostCommandsExecutor)
+	ostreeClient := intOstree.NewClient(hostCommandsExecutor, false)
+
+	if err := ibu.NewRollbackHandler(log, opsInterface, ostreeClient, rpmClient).Run(ibuRollbackReason); err != nil {
+		return fmt.Errorf("ibu rollback handler failed: %w", err)
+	}
+
+	if ibuRollbackNoReboot {
+		log.Info("Skipping reboot, the original stateroot is booted on the next reboot")
+		return nil
+	}
+
+	rb := reboot.NewIBURebootClient(&logr.Logger{}, hostCommandsExecutor, rpmClient, ostreeClient, opsInterface)
+	if err := rb.RebootToNewStateRoot("ibu rollback"); err != nil {
+		return fmt.Errorf("failed to reboot: %w", err)
+	}
+
+	return nil
+}
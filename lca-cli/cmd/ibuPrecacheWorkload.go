@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"syscall"
 
@@ -55,6 +56,19 @@ func terminateOnError(err error) {
 	os.Exit(Failure)
 }
 
+// initPrecacheSigHandler terminates the pre-caching job with the interrupted exit code on SIGTERM, so that the job is
+// retried and resumes from the persisted progress
+func initPrecacheSigHandler() {
+	signalChannel := make(chan os.Signal, 1)
+	signal.Notify(signalChannel, syscall.SIGTERM)
+
+	go func() {
+		<-signalChannel
+		log.Error("unexpected SIGTERM received to stop pre-caching, terminating pre-caching job")
+		os.Exit(int(precache.InterruptedExitCode))
+	}()
+}
+
 // readPrecacheSpecFile returns the list of images to be precached as specified in the precache spec file
 func readPrecacheSpecFile() (precacheSpec []string, err error) {
 	precacheSpecFile := os.Getenv(precache.EnvPrecacheSpecFile)
@@ -88,11 +102,10 @@ func readPrecacheSpecFile() (precacheSpec []string, err error) {
 
 func ibuPrecacheWorkloadRun() {
 	log.Info("Starting to execute pre-cache workload")
+	initPrecacheSigHandler()
 
-	bestEffort := false
-	str := os.Getenv(precache.EnvPrecacheBestEffort)
-	if str == "TRUE" {
-		bestEffort = true
+	bestEffort := precache.IsBestEffort()
+	if bestEffort {
 		log.Info("pre-caching set to 'best-effort'")
 	}
 
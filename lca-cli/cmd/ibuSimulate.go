@@ -0,0 +1,155 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	lvmv1alpha1 "github.com/openshift/lvm-operator/api/v1alpha1"
+	"github.com/spf13/cobra"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/clusterconfig"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ibu"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+var (
+	// ibuSimulateFile is an IBU CR manifest to simulate instead of the IBU CR of the cluster
+	ibuSimulateFile string
+	// ibuSimulateSandbox is the directory the content of the new stateroot is written to
+	ibuSimulateSandbox string
+	// ibuSimulateOutput is the file the simulation report is written to, stdout if empty
+	ibuSimulateOutput string
+	// ibuSimulateAuthFile is the path to the registry credentials used to inspect the seed image
+	ibuSimulateAuthFile string
+)
+
+var ibuSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate the Prep and Upgrade stages of an image based upgrade",
+	Long: `Simulate the Prep and Upgrade stages of an image based upgrade, without touching ostree nor rebooting the node.
+The seed image is inspected, the extra manifests are rendered, the cluster reconfiguration files are templated and the
+backup and restore plans are computed as the Upgrade stage does, the content of the new stateroot being written to the
+sandbox directory. The report lists everything the upgrade would change, along with the steps that would fail.
+The sandbox holds cluster secrets, such as the SSH host keys, and must be removed once inspected.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runIBUSimulate(cmd); err != nil {
+			log.Fatalf("Error executing ibu simulate: %v", err)
+		}
+	},
+}
+
+func init() {
+	utilruntime.Must(velerov1.AddToScheme(scheme))
+	utilruntime.Must(policiesv1.AddToScheme(scheme))
+	utilruntime.Must(policyv1.AddToScheme(scheme))
+	utilruntime.Must(lvmv1alpha1.AddToScheme(scheme))
+
+	ibuSimulateCmd.Flags().StringVarP(&ibuSimulateFile, "file", "f", "", "An IBU CR manifest to simulate. Defaults to the IBU CR of the cluster.")
+	ibuSimulateCmd.Flags().StringVar(&ibuSimulateSandbox, "sandbox", "", "The directory the content of the new stateroot is written to. Defaults to a temporary directory.")
+	ibuSimulateCmd.Flags().StringVarP(&ibuSimulateOutput, "output", "o", "", "The file the simulation report is written to. Defaults to stdout.")
+	ibuSimulateCmd.Flags().StringVarP(&ibuSimulateAuthFile, "authfile", "a", common.ImageRegistryAuthFile, "The path to the authentication file of the container registry.")
+
+	ibuCmd.AddCommand(ibuSimulateCmd)
+}
+
+func runIBUSimulate(cmd *cobra.Command) error {
+	ctx := context.Background()
+	loggerOpt := zap.Options{Development: true}
+	logger := zap.New(zap.UseFlagOptions(&loggerOpt)).WithName("ibu-simulate")
+
+	cfg := config.GetConfigOrDie()
+	cfg.Wrap(lcautils.RetryMiddleware(logger))
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	ibuCR := &ibuv1.ImageBasedUpgrade{}
+	if ibuSimulateFile != "" {
+		data, err := os.ReadFile(ibuSimulateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", ibuSimulateFile, err)
+		}
+		if err := yaml.Unmarshal(data, ibuCR); err != nil {
+			return fmt.Errorf("failed to decode the IBU CR of %s: %w", ibuSimulateFile, err)
+		}
+	} else if err := c.Get(ctx, client.ObjectKey{Name: utils.IBUName}, ibuCR); err != nil {
+		return fmt.Errorf("failed to get the IBU CR: %w", err)
+	}
+
+	sandbox := ibuSimulateSandbox
+	if sandbox == "" {
+		if sandbox, err = os.MkdirTemp("", "ibu-simulate-"); err != nil {
+			return fmt.Errorf("failed to create the sandbox directory: %w", err)
+		}
+	}
+
+	simulator := &ibu.Simulator{
+		Client:             c,
+		ClusterConfig:      &clusterconfig.UpgradeClusterConfigGather{Client: c, Scheme: scheme, Log: logger.WithName("ClusterConfig")},
+		ExtraManifest:      &extramanifest.EMHandler{Client: c, DynamicClient: dynamicClient, Log: logger.WithName("ExtraManifest")},
+		BackupRestore:      &backuprestore.BRHandler{Client: c, DynamicClient: dynamicClient, Log: logger.WithName("BackupRestore")},
+		Executor:           ops.NewRegularExecutor(log, verbose),
+		AuthFile:           ibuSimulateAuthFile,
+		StageDurationsFile: common.PathOutsideChroot(utils.StageDurationsFilePath),
+		Log:                log,
+	}
+	report, err := simulator.Run(ctx, ibuCR, sandbox)
+	if err != nil {
+		return fmt.Errorf("failed to simulate the upgrade: %w", err)
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the simulation report: %w", err)
+	}
+	if ibuSimulateOutput == "" {
+		fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	} else if err := os.WriteFile(ibuSimulateOutput, append(output, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write the simulation report to %s: %w", ibuSimulateOutput, err)
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("the upgrade would fail: %d simulated steps failed", len(report.Errors))
+	}
+	log.Infof("Simulation completed, the content of the new stateroot is in %s", sandbox)
+	return nil
+}
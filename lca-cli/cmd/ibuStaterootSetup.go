@@ -19,9 +19,11 @@ import (
 	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/openshift-kni/lifecycle-agent/internal/checkpoint"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/prep"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
 	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -32,14 +34,15 @@ import (
 
 // ibuStaterootSetupCmd represents the ibuStaterootSetup command
 var ibuStaterootSetupCmd = &cobra.Command{
-	Use:     "ibuStaterootSetup",
-	Aliases: []string{"ibu-stateroot-setup"},
-	Short:   "Setup a new stateroot during IBU",
-	Long:    `Setup stateroot during IBU. This is meant to be used as k8s job!`,
+	Use:         "ibuStaterootSetup",
+	Aliases:     []string{"ibu-stateroot-setup"},
+	Short:       "Setup a new stateroot during IBU",
+	Long:        `Setup stateroot during IBU. This is meant to be used as k8s job!`,
+	Annotations: map[string]string{auditAnnotation: string(ibuv1.Stages.Prep)},
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := ibuStaterootSetupRun(); err != nil {
 			log.Error(err)
-			os.Exit(1)
+			os.Exit(prep.StaterootSetupFailedExitCode)
 		}
 	},
 }
@@ -49,6 +52,9 @@ func init() {
 }
 
 // ibuStaterootSetupRun main function to do stateroot setup
+// stepSetupStateroot is the checkpoint of the deployment of the new stateroot
+const stepSetupStateroot = "setup_stateroot"
+
 func ibuStaterootSetupRun() error {
 	var (
 		hostCommandsExecutor = ops.NewChrootExecutor(log, true, common.Host)
@@ -77,26 +83,70 @@ func ibuStaterootSetupRun() error {
 		return fmt.Errorf("failed get IBU cr: %w", err)
 	}
 
+	// A local seed image is loaded in the container storage under a name of its own
+	seedImage := seedimage.StorageImage(ibu.Spec.SeedImageRef.Image)
+
 	logger.Info("Starting signal handler")
-	initStaterootSetupSigHandler(logger, opsClient, ibu.Spec.SeedImageRef.Image)
+	initStaterootSetupSigHandler(logger, opsClient, seedImage)
+
+	// The job is run again when interrupted, e.g. by a node reboot, resuming after the last completed step
+	stateroot := common.GetDesiredStaterootName(ibu)
+	tracker := checkpoint.NewStore(common.PathOutsideChroot(common.CheckpointFile), logger).For(string(ibuv1.Stages.Prep), stateroot)
+	if step := tracker.LastCompleted(); step != "" {
+		logger.Info("Resuming the stateroot setup", "after", step)
+	}
 
-	logger.Info("Pulling seed image")
-	if err := controllers.GetSeedImage(c, ctx, ibu, logger, hostCommandsExecutor); err != nil {
-		return fmt.Errorf("failed to pull seed image: %w", err)
+	// The seed image is removed once the stateroot is set up, so it is only pulled and validated until then
+	if !tracker.Completed(stepSetupStateroot) {
+		logger.Info("Pulling seed image")
+		if err := controllers.GetSeedImage(c, ctx, ibu, logger, hostCommandsExecutor); err != nil {
+			return fmt.Errorf("failed to pull seed image: %w", err)
+		}
+
+		if ibu.Spec.ValidateSeedContent {
+			logger.Info("Validating seed image content")
+			if err := validateSeedContent(opsClient, hostCommandsExecutor, ibu); err != nil {
+				// The seed image failing the validation is of no use, and must not be left in the container storage
+				logger.Info("Removing the invalid seed image")
+				if err := opsClient.UnmountAndRemoveImage(seedImage); err != nil {
+					logger.Error(err, "failed to remove the invalid seed image")
+				}
+				return err
+			}
+			if ibu.Spec.ValidateOnly {
+				logger.Info("Removing the validated seed image")
+				if err := opsClient.UnmountAndRemoveImage(seedImage); err != nil {
+					return fmt.Errorf("failed to remove the validated seed image: %w", err)
+				}
+				return nil
+			}
+		}
 	}
 
 	logger.Info("Setting up stateroot")
-	if err := prep.SetupStateroot(logger, opsClient, ostreeClient, rpmOstreeClient, ibu.Spec.SeedImageRef.Image, ibu.Spec.SeedImageRef.Version, false); err != nil {
+	if err := tracker.RunWithUnwind(stepSetupStateroot,
+		func() error {
+			return prep.SetupStateroot(logger, opsClient, ostreeClient, rpmOstreeClient, seedImage, ibu.Spec.SeedImageRef.Version, false)
+		},
+		// The deployment of an interrupted setup is partial, so the stateroot is set up again from scratch
+		func() error {
+			return controllers.RemoveUnbootedStateroot(stateroot, opsClient, ostreeClient, rpmOstreeClient)
+		},
+	); err != nil {
 		return fmt.Errorf("failed to complete stateroot setup: %w", err)
 	}
 
 	logger.Info("Writing IBU AutoRollbackConfig file")
-	if err := reboot.WriteIBUAutoRollbackConfigFile(logger, ibu); err != nil {
+	if err := tracker.Run("write_autorollback_config", func() error {
+		return reboot.WriteIBUAutoRollbackConfigFile(logger, ibu)
+	}); err != nil {
 		return fmt.Errorf("failed to write auto-rollback config: %w", err)
 	}
 
 	logger.Info("Backing up kubeconfig crypto")
-	if err := lcautils.BackupKubeconfigCrypto(ctx, c, common.GetStaterootCertsDir(ibu)); err != nil {
+	if err := tracker.Run("backup_kubeconfig_crypto", func() error {
+		return lcautils.BackupKubeconfigCrypto(ctx, c, common.GetStaterootCertsDir(ibu))
+	}); err != nil {
 		return fmt.Errorf("failed to backup cerificaties: %w", err)
 	}
 
@@ -104,6 +154,25 @@ func ibuStaterootSetupRun() error {
 	return nil
 }
 
+// validateSeedContent validates the content of the pulled seed image, recording the report for the failed checks to
+// be reported in the Prep condition
+func validateSeedContent(opsClient ops.Ops, executor ops.Execute, ibu *ibuv1.ImageBasedUpgrade) error {
+	report, err := seedimage.ValidateImage(opsClient, executor, ibu.Spec.SeedImageRef.Image, seedimage.ValidationOptions{
+		Version: ibu.Spec.SeedImageRef.Version,
+		Now:     time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to validate seed image content: %w", err)
+	}
+	if err := lcautils.MarshalToFile(report, common.PathOutsideChroot(prep.SeedValidationReportFile)); err != nil {
+		return fmt.Errorf("failed to write the seed image validation report: %w", err)
+	}
+	if !report.Passed {
+		return fmt.Errorf("seed image content validation failed: %s", report.Failures())
+	}
+	return nil
+}
+
 // initStaterootSetupSigHandler handling signals here
 func initStaterootSetupSigHandler(logger logr.Logger, opsClient ops.Ops, seedImage string) {
 	signalChannel := make(chan os.Signal, 1)
@@ -124,7 +193,7 @@ func initStaterootSetupSigHandler(logger logr.Logger, opsClient ops.Ops, seedIma
 			}
 
 			logger.Error(fmt.Errorf("proceeding to shutdown stateroot setup job"), "")
-			os.Exit(1)
+			os.Exit(prep.StaterootSetupInterruptedExitCode)
 		default:
 			// nolint: staticcheck
 			logger.Error(fmt.Errorf("unknown signal: %s", s.String()), "Unknown signal") // this is not expected to be hit
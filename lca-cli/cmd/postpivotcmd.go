@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
@@ -33,8 +34,9 @@ import (
 
 // createCmd represents the create command
 var postPivotCmd = &cobra.Command{
-	Use:   "post-pivot",
-	Short: "post pivot configuration",
+	Use:         "post-pivot",
+	Short:       "post pivot configuration",
+	Annotations: map[string]string{auditAnnotation: string(ibuv1.Stages.Upgrade)},
 	Run: func(cmd *cobra.Command, args []string) {
 		postPivot()
 	},
@@ -23,6 +23,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-kni/lifecycle-agent/internal/audit"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	ipconfigcmd "github.com/openshift-kni/lifecycle-agent/lca-cli/cmd/ipconfig"
 )
@@ -42,6 +43,25 @@ var noColor bool
 // version is an optional command that will display the current release version
 var releaseVersion string
 
+// auditAnnotation marks the commands performing upgrade actions on the node, which are recorded in the audit log. Its
+// value is the IBU stage the command is run for.
+const auditAnnotation = "lca.openshift.io/audit-stage"
+
+// auditLog is the audit trail of the upgrade actions on the node
+var auditLog = audit.NewLog(common.PathOutsideChroot(common.AuditLogFile), audit.SourceCLI)
+
+// recordCommand appends the progress of an audited command to the audit log. The audit trail must not block the
+// command, so any error is only logged.
+func recordCommand(cmd *cobra.Command, progress string) {
+	stage, audited := cmd.Annotations[auditAnnotation]
+	if !audited {
+		return
+	}
+	if err := auditLog.Record(audit.Command, stage, fmt.Sprintf("%s %s", cmd.CommandPath(), progress)); err != nil {
+		log.Warnf("Failed to record the command in the audit log: %v", err)
+	}
+}
+
 func addCommonFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&authFile, "authfile", "a", common.ImageRegistryAuthFile, "The path to the authentication file of the container registry.")
 	cmd.Flags().StringVarP(&containerRegistry, "image", "i", "", "The full image name with the container registry to push the OCI image.")
@@ -71,6 +91,11 @@ var (
 			} else {
 				log.SetLevel(logrus.InfoLevel)
 			}
+			recordCommand(cmd, "started")
+		},
+		// Only run when the command succeeds, a started command not recorded as completed has failed
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			recordCommand(cmd, "completed")
 		},
 
 		Long: `lca-cli assists LCA in Image Based Install (IBI), Image Based Upgrade (IBU) and IP Configuration (IPC) workflows.
@@ -0,0 +1,252 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedregistry"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+var (
+	// inspectAuthFile is the path to the registry credentials used to inspect the seed image
+	inspectAuthFile string
+	// seedRegistryDir is the directory the seed images are imported to and served from
+	seedRegistryDir string
+	// seedRegistryAddress is the address the seed registry listens on
+	seedRegistryAddress string
+	// seedRegistryCertFile and seedRegistryKeyFile enable TLS on the seed registry
+	seedRegistryCertFile string
+	seedRegistryKeyFile  string
+	// seedValidateVersion and seedValidateClusterVersion are the versions the seed image is validated against
+	seedValidateVersion        string
+	seedValidateClusterVersion string
+	// seedValidateKeep keeps the seed image in the container storage once validated
+	seedValidateKeep bool
+	// seedValidateOutput is the format of the validation report
+	seedValidateOutput string
+)
+
+// seedCmd groups the commands operating on seed images
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Seed image commands",
+}
+
+// seedInspectCmd represents the seed inspect command
+var seedInspectCmd = &cobra.Command{
+	Use:   "inspect <image>",
+	Short: "Print the metadata of a seed image without pulling it.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := inspectSeed(cmd, args[0]); err != nil {
+			log.Fatalf("Error executing seed inspect command: %v", err)
+		}
+	},
+}
+
+// seedValidateCmd represents the seed validate command
+var seedValidateCmd = &cobra.Command{
+	Use:   "validate <image>",
+	Short: "Pull a seed image and validate its content.",
+	Long: `Pull a seed image and validate its content, printing a pass/fail report of the following checks:
+  Metadata      the metadata files required by the Prep stage are present and readable
+  Content       the files match the content manifest of the seed image, when recorded
+  Ostree        the ostree repo archive can be read and holds the booted commit of the seed
+  Certificates  none of the certificates of the seed cluster has expired
+  Version       the seed format version is supported and the seed version matches the expected versions
+The image is removed from the container storage once validated, unless --keep is set. The command exits
+with an error if any of the checks fails.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateSeed(cmd, args[0]); err != nil {
+			log.Fatalf("Error executing seed validate command: %v", err)
+		}
+	},
+}
+
+// seedImportCmd represents the seed import command
+var seedImportCmd = &cobra.Command{
+	Use:   "import <archive> <image>",
+	Short: "Import a seed image from a portable medium into the local seed registry.",
+	Long: `Import a seed image, copied to a portable medium with skopeo copy, into the local seed registry.
+The archive is read as an oci-archive unless prefixed by its transport, e.g. docker-archive:/mnt/usb/seed.tar.
+The image is then served by lca-cli seed serve as <address>/<repository>:<tag>, the repository being the
+path of the image without its registry.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := importSeed(cmd, args[0], args[1]); err != nil {
+			log.Fatalf("Error executing seed import command: %v", err)
+		}
+	},
+}
+
+// seedServeCmd represents the seed serve command
+var seedServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the imported seed images over a local read-only registry endpoint.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := serveSeeds(); err != nil {
+			log.Fatalf("Error executing seed serve command: %v", err)
+		}
+	},
+}
+
+func init() {
+
+	// Add seed command and its subcommands
+	rootCmd.AddCommand(seedCmd)
+	seedCmd.AddCommand(seedInspectCmd)
+	seedCmd.AddCommand(seedValidateCmd)
+	seedCmd.AddCommand(seedImportCmd)
+	seedCmd.AddCommand(seedServeCmd)
+
+	// Add flags to seed inspect command
+	seedInspectCmd.Flags().StringVarP(&inspectAuthFile, "authfile", "a", common.ImageRegistryAuthFile, "The path to the authentication file of the container registry.")
+
+	// Add flags to seed validate command
+	seedValidateCmd.Flags().StringVarP(&inspectAuthFile, "authfile", "a", common.ImageRegistryAuthFile, "The path to the authentication file of the container registry.")
+	seedValidateCmd.Flags().StringVar(&seedValidateVersion, "version", "", "The version the seed image is expected to have, e.g. the spec.seedImageRef.version of the IBU CR.")
+	seedValidateCmd.Flags().StringVar(&seedValidateClusterVersion, "cluster-version", "", "The version of the cluster to upgrade, which the seed version must be higher than.")
+	seedValidateCmd.Flags().BoolVar(&seedValidateKeep, "keep", false, "Keep the seed image in the container storage once validated.")
+	seedValidateCmd.Flags().StringVarP(&seedValidateOutput, "output", "o", "text", "The format of the validation report, text or json.")
+
+	// Add flags to seed import and serve commands
+	for _, c := range []*cobra.Command{seedImportCmd, seedServeCmd} {
+		c.Flags().StringVarP(&seedRegistryDir, "dir", "d", seedregistry.DefaultDir, "The directory of the local seed registry.")
+	}
+	seedServeCmd.Flags().StringVar(&seedRegistryAddress, "address", seedregistry.DefaultAddress, "The address the seed registry listens on.")
+	seedServeCmd.Flags().StringVar(&seedRegistryCertFile, "tls-cert", "", "The TLS certificate of the seed registry. Serves plain HTTP if empty.")
+	seedServeCmd.Flags().StringVar(&seedRegistryKeyFile, "tls-key", "", "The TLS key of the seed registry.")
+	seedServeCmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+}
+
+func importSeed(cmd *cobra.Command, archive, image string) error {
+	served, err := seedregistry.ImportImage(ops.NewRegularExecutor(log, verbose), archive, image, seedRegistryDir)
+	if err != nil {
+		return fmt.Errorf("failed to import seed image %s: %w", image, err)
+	}
+	log.Infof("Imported %s from %s", image, archive)
+	fmt.Fprintln(cmd.OutOrStdout(), served)
+	return nil
+}
+
+func serveSeeds() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := zap.Options{Development: true}
+	server := &seedregistry.Server{
+		Address:  seedRegistryAddress,
+		Dir:      seedRegistryDir,
+		CertFile: seedRegistryCertFile,
+		KeyFile:  seedRegistryKeyFile,
+		Log:      zap.New(zap.UseFlagOptions(&opts)).WithName("seed-registry"),
+	}
+	if err := server.Start(ctx); err != nil {
+		return fmt.Errorf("failed to serve the seed registry: %w", err)
+	}
+	return nil
+}
+
+func inspectSeed(cmd *cobra.Command, image string) error {
+	seedImage, err := seedimage.InspectImage(ops.NewRegularExecutor(log, verbose), image, inspectAuthFile)
+	if err != nil {
+		return fmt.Errorf("failed to inspect seed image %s: %w", image, err)
+	}
+	info, err := seedImage.Info(image)
+	if err != nil {
+		return fmt.Errorf("failed to get seed image info: %w", err)
+	}
+	if info.OCPVersion == "" {
+		log.Warnf("%s does not record the seed cluster information, it may not be a seed image", image)
+	}
+
+	output, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal seed image info: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	return nil
+}
+
+func validateSeed(cmd *cobra.Command, image string) error {
+	if seedValidateOutput != "text" && seedValidateOutput != "json" {
+		return fmt.Errorf("invalid output format %q, expecting text or json", seedValidateOutput)
+	}
+
+	executor := ops.NewRegularExecutor(log, verbose)
+	opsClient := ops.NewOps(log, executor)
+	storageImage := seedimage.StorageImage(image)
+
+	source, err := seedimage.ParseLocalSource(image)
+	if err != nil {
+		return fmt.Errorf("invalid seed image: %w", err)
+	}
+	log.Infof("Pulling seed image %s", image)
+	if source != nil {
+		err = source.Load(executor, []string{"--authfile", inspectAuthFile})
+	} else {
+		_, err = executor.Execute("podman", "pull", "--authfile", inspectAuthFile, image)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to pull seed image %s: %w", image, err)
+	}
+	if !seedValidateKeep {
+		defer func() {
+			if err := opsClient.UnmountAndRemoveImage(storageImage); err != nil {
+				log.Warnf("failed to remove seed image %s: %v", storageImage, err)
+			}
+		}()
+	}
+
+	report, err := seedimage.ValidateImage(opsClient, executor, image, seedimage.ValidationOptions{
+		Version:        seedValidateVersion,
+		ClusterVersion: seedValidateClusterVersion,
+		Now:            time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to validate seed image %s: %w", image, err)
+	}
+
+	if seedValidateOutput == "json" {
+		output, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the validation report: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	} else {
+		fmt.Fprint(cmd.OutOrStdout(), report.String())
+	}
+	if !report.Passed {
+		return fmt.Errorf("seed image %s failed the validation: %s", image, report.Failures())
+	}
+	return nil
+}
@@ -60,6 +60,9 @@ func (i *IBIPrepare) Run() error {
 	if _, err := i.ops.RunInHostNamespace("podman", "pull", "--authfile", common.IBIPullSecretFilePath, i.config.SeedImage); err != nil {
 		return fmt.Errorf("failed to pull image: %w", err)
 	}
+	if err := i.pullBaseSeedImage(); err != nil {
+		return err
+	}
 
 	// TODO: change to logrus after refactoring the code in controllers and moving to logrus
 	log := logr.Logger{}
@@ -256,3 +259,24 @@ func mirrorRegistrySourceRegistries(registriesConfFile string) ([]string, error)
 
 	return sources, nil
 }
+
+// pullBaseSeedImage pulls the base seed image of a layered seed image
+func (i *IBIPrepare) pullBaseSeedImage() error {
+	inspectRaw, err := i.ops.RunInHostNamespace("podman", "image", "inspect", "--format", "json", i.config.SeedImage)
+	if err != nil {
+		return fmt.Errorf("failed to inspect seed image: %w", err)
+	}
+	baseSeedImage, err := prep.ParseBaseSeedImage(inspectRaw)
+	if err != nil {
+		return fmt.Errorf("failed to get the base seed image: %w", err)
+	}
+	if baseSeedImage == "" {
+		return nil
+	}
+
+	i.log.Infof("Pulling the base seed image %s", baseSeedImage)
+	if _, err := i.ops.RunInHostNamespace("podman", "pull", "--authfile", common.IBIPullSecretFilePath, baseSeedImage); err != nil {
+		return fmt.Errorf("failed to pull the base seed image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	intOstree "github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	rpmOstree "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+// RollbackRecordFile records the manual rollback in the original stateroot, for the controller to report it once the
+// cluster is back
+const RollbackRecordFile = common.LCAConfigDir + "/manual-rollback.json"
+
+// RollbackRecord describes a manual rollback initiated from the node
+type RollbackRecord struct {
+	Time      metav1.Time `json:"time"`
+	Reason    string      `json:"reason,omitempty"`
+	Stateroot string      `json:"stateroot"`
+}
+
+type RollbackHandler struct {
+	log    *logrus.Logger
+	ops    ops.Ops
+	ostree intOstree.IClient
+	rpm    rpmOstree.IClient
+}
+
+// NewRollbackHandler constructs a RollbackHandler to roll back an upgrade from the node, without the API server
+func NewRollbackHandler(log *logrus.Logger, ops ops.Ops, ostree intOstree.IClient, rpm rpmOstree.IClient) *RollbackHandler {
+	return &RollbackHandler{log: log, ops: ops, ostree: ostree, rpm: rpm}
+}
+
+// Run sets the original stateroot as the default deployment. The IBU CR saved in the original stateroot is set to the
+// Rollback stage, so that the controller completes the rollback once the original stateroot is booted, and the
+// rollback is recorded alongside it. The original stateroot has its own /etc and /var, which were left untouched by
+// the upgrade, so its state is restored as-is by booting it.
+func (h *RollbackHandler) Run(reason string) error {
+	if !h.ostree.IsOstreeAdminSetDefaultFeatureEnabled() {
+		return fmt.Errorf("rollback not supported in this release, the default deployment must be set manually")
+	}
+
+	bootedStateroot, err := h.rpm.GetCurrentStaterootName()
+	if err != nil {
+		return fmt.Errorf("failed to get booted stateroot: %w", err)
+	}
+	origStateroot, err := h.rpm.GetUnbootedStaterootName()
+	if err != nil {
+		return fmt.Errorf("failed to get original stateroot: %w", err)
+	}
+	h.log.Infof("IBU rollback started from stateroot %s to stateroot %s", bootedStateroot, origStateroot)
+
+	if err := h.ops.RemountSysroot(); err != nil {
+		return fmt.Errorf("failed to remount sysroot: %w", err)
+	}
+
+	origStaterootPath := common.PathOutsideChroot(common.GetStaterootPath(origStateroot))
+	ibuFile := filepath.Join(origStaterootPath, utils.IBUFilePath)
+	savedIbu := &ibuv1.ImageBasedUpgrade{}
+	if err := lcautils.ReadYamlOrJSONFile(ibuFile, savedIbu); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no IBU CR saved in stateroot %s, it was not upgraded from", origStateroot)
+		}
+		return fmt.Errorf("failed to read saved IBU CR from %s: %w", ibuFile, err)
+	}
+
+	if savedIbu.Spec.Stage != ibuv1.Stages.Upgrade {
+		return fmt.Errorf("the IBU CR saved in stateroot %s is in the %s stage, expected %s",
+			origStateroot, savedIbu.Spec.Stage, ibuv1.Stages.Upgrade)
+	}
+	if upgradeStateroot := common.GetStaterootName(savedIbu.Spec.SeedImageRef.Version); bootedStateroot != upgradeStateroot {
+		return fmt.Errorf("booted stateroot %s is not the upgrade stateroot %s", bootedStateroot, upgradeStateroot)
+	}
+
+	h.log.Info("Saving the IBU CR with the Rollback stage in the original stateroot")
+	savedIbu.Spec.Stage = ibuv1.Stages.Rollback
+	savedIbu.Status.RollbackAvailabilityExpiration.Reset()
+	utils.SetRollbackStatusInProgress(savedIbu, "Completing rollback initiated from the node")
+	utils.SetStageProgress(savedIbu, "Completing rollback", 40)
+	if err := lcautils.MarshalToFile(savedIbu, ibuFile); err != nil {
+		return fmt.Errorf("failed to save IBU CR to %s: %w", ibuFile, err)
+	}
+
+	record := RollbackRecord{Time: metav1.Now(), Reason: reason, Stateroot: bootedStateroot}
+	recordFile := filepath.Join(origStaterootPath, RollbackRecordFile)
+	if err := lcautils.MarshalToFile(record, recordFile); err != nil {
+		return fmt.Errorf("failed to record the rollback to %s: %w", recordFile, err)
+	}
+
+	idx, err := h.rpm.GetUnbootedDeploymentIndex()
+	if err != nil {
+		return fmt.Errorf("failed to get deployment index of stateroot %s: %w", origStateroot, err)
+	}
+	if err := h.ostree.SetDefaultDeployment(idx); err != nil {
+		return fmt.Errorf("failed to set default deployment: %w", err)
+	}
+
+	h.log.Info("IBU rollback done successfully, the original stateroot is booted on the next reboot")
+	return nil
+}
+
+// ReadRollbackRecord returns the manual rollback recorded in the booted stateroot, or nil if there is none
+func ReadRollbackRecord() (*RollbackRecord, error) {
+	record := &RollbackRecord{}
+	if err := lcautils.ReadYamlOrJSONFile(common.PathOutsideChroot(RollbackRecordFile), record); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read manual rollback record: %w", err)
+	}
+	return record, nil
+}
+
+// RemoveRollbackRecord removes the manual rollback recorded in the booted stateroot
+func RemoveRollbackRecord() error {
+	if err := os.Remove(common.PathOutsideChroot(RollbackRecordFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove manual rollback record: %w", err)
+	}
+	return nil
+}
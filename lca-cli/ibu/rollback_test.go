@@ -0,0 +1,158 @@
+package ibu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	ostreemock "github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
+	opsmock "github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
+	lcautils "github.com/openshift-kni/lifecycle-agent/utils"
+)
+
+const (
+	origStateroot    = "rhcos"
+	seedVersion      = "4.16.0"
+	upgradeStateroot = "rhcos_4.16.0"
+)
+
+func newRollbackHandler(t *testing.T) (*RollbackHandler, *opsmock.MockOps, *ostreemock.MockIClient, *rpmostreeclient.MockIClient) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockOps := opsmock.NewMockOps(ctrl)
+	mockOstree := ostreemock.NewMockIClient(ctrl)
+	mockRPM := rpmostreeclient.NewMockIClient(ctrl)
+
+	return NewRollbackHandler(logrus.New(), mockOps, mockOstree, mockRPM), mockOps, mockOstree, mockRPM
+}
+
+// saveIBU writes the IBU CR saved in the original stateroot before the pivot, and returns its path
+func saveIBU(t *testing.T, stage ibuv1.ImageBasedUpgradeStage) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	origPrefix := common.OstreeDeployPathPrefix
+	common.OstreeDeployPathPrefix = tmpDir
+	t.Cleanup(func() { common.OstreeDeployPathPrefix = origPrefix })
+
+	ibuFile := filepath.Join(common.GetStaterootPath(origStateroot), utils.IBUFilePath)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(ibuFile), 0o700))
+	if stage != "" {
+		ibu := &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{
+			Stage:        stage,
+			SeedImageRef: ibuv1.SeedImageRef{Version: seedVersion},
+		}}
+		utils.SetUpgradeStatusInProgress(ibu, "Upgrade in progress")
+		assert.NoError(t, lcautils.MarshalToFile(ibu, ibuFile))
+	}
+	return ibuFile
+}
+
+func TestRollbackRunSuccess(t *testing.T) {
+	handler, ops, ostree, rpm := newRollbackHandler(t)
+	ibuFile := saveIBU(t, ibuv1.Stages.Upgrade)
+
+	ostree.EXPECT().IsOstreeAdminSetDefaultFeatureEnabled().Return(true)
+	rpm.EXPECT().GetCurrentStaterootName().Return(upgradeStateroot, nil)
+	rpm.EXPECT().GetUnbootedStaterootName().Return(origStateroot, nil)
+	ops.EXPECT().RemountSysroot().Return(nil)
+	rpm.EXPECT().GetUnbootedDeploymentIndex().Return(1, nil)
+	ostree.EXPECT().SetDefaultDeployment(1).Return(nil)
+
+	assert.NoError(t, handler.Run("API server unavailable"))
+
+	savedIbu := &ibuv1.ImageBasedUpgrade{}
+	assert.NoError(t, lcautils.ReadYamlOrJSONFile(ibuFile, savedIbu))
+	assert.Equal(t, ibuv1.Stages.Rollback, savedIbu.Spec.Stage)
+	assert.NotNil(t, utils.GetInProgressCondition(savedIbu, ibuv1.Stages.Rollback))
+
+	record := &RollbackRecord{}
+	assert.NoError(t, lcautils.ReadYamlOrJSONFile(filepath.Join(common.GetStaterootPath(origStateroot), RollbackRecordFile), record))
+	assert.Equal(t, "API server unavailable", record.Reason)
+	assert.Equal(t, upgradeStateroot, record.Stateroot)
+	assert.False(t, record.Time.IsZero())
+}
+
+func TestRollbackRunFeatureDisabled(t *testing.T) {
+	handler, ops, ostree, rpm := newRollbackHandler(t)
+
+	ostree.EXPECT().IsOstreeAdminSetDefaultFeatureEnabled().Return(false)
+	rpm.EXPECT().GetCurrentStaterootName().Times(0)
+	ops.EXPECT().RemountSysroot().Times(0)
+	ostree.EXPECT().SetDefaultDeployment(gomock.Any()).Times(0)
+
+	err := handler.Run("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "default deployment must be set manually")
+}
+
+func TestRollbackRunInvalidSavedIBU(t *testing.T) {
+	tests := []struct {
+		name            string
+		stage           ibuv1.ImageBasedUpgradeStage
+		bootedStateroot string
+		expectedErr     string
+	}{
+		{
+			name:            "no saved IBU",
+			bootedStateroot: upgradeStateroot,
+			expectedErr:     "no IBU CR saved in stateroot rhcos",
+		},
+		{
+			name:            "not upgrading",
+			stage:           ibuv1.Stages.Prep,
+			bootedStateroot: upgradeStateroot,
+			expectedErr:     "is in the Prep stage, expected Upgrade",
+		},
+		{
+			name:            "original stateroot booted",
+			stage:           ibuv1.Stages.Upgrade,
+			bootedStateroot: "rhcos_4.15.0",
+			expectedErr:     "booted stateroot rhcos_4.15.0 is not the upgrade stateroot rhcos_4.16.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, ops, ostree, rpm := newRollbackHandler(t)
+			saveIBU(t, tt.stage)
+
+			ostree.EXPECT().IsOstreeAdminSetDefaultFeatureEnabled().Return(true)
+			rpm.EXPECT().GetCurrentStaterootName().Return(tt.bootedStateroot, nil)
+			rpm.EXPECT().GetUnbootedStaterootName().Return(origStateroot, nil)
+			ops.EXPECT().RemountSysroot().Return(nil)
+			ostree.EXPECT().SetDefaultDeployment(gomock.Any()).Times(0)
+
+			err := handler.Run("")
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestRollbackRunSetDefaultDeploymentError(t *testing.T) {
+	handler, ops, ostree, rpm := newRollbackHandler(t)
+	saveIBU(t, ibuv1.Stages.Upgrade)
+
+	ostree.EXPECT().IsOstreeAdminSetDefaultFeatureEnabled().Return(true)
+	rpm.EXPECT().GetCurrentStaterootName().Return(upgradeStateroot, nil)
+	rpm.EXPECT().GetUnbootedStaterootName().Return(origStateroot, nil)
+	ops.EXPECT().RemountSysroot().Return(nil)
+	rpm.EXPECT().GetUnbootedDeploymentIndex().Return(1, nil)
+	ostree.EXPECT().SetDefaultDeployment(1).Return(errors.New("set-fail"))
+
+	err := handler.Run("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to set default deployment")
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibu
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/clusterconfig"
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
+	"github.com/openshift-kni/lifecycle-agent/internal/healthcheck"
+	"github.com/openshift-kni/lifecycle-agent/internal/preservedpaths"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+// SimulationReport describes everything the Prep and Upgrade stages of the IBU would do
+type SimulationReport struct {
+	// SeedImage is the metadata of the seed image, inspected without pulling it
+	SeedImage *ibuv1.SeedImageInfo `json:"seedImage,omitempty"`
+	// SeedImageSize is the compressed size of the seed image layers pulled by the Prep stage
+	SeedImageSize int64 `json:"seedImageSize,omitempty"`
+	// Stateroot is the new stateroot deployed by the Prep stage
+	Stateroot string `json:"stateroot"`
+	// SandboxDir holds the files that would be written to the /var of the new stateroot
+	SandboxDir string `json:"sandboxDir"`
+	// Files are the files that would be written to the /var of the new stateroot, relative to the SandboxDir
+	Files []string `json:"files,omitempty"`
+	// Backups are the OADP backups taken before the pivot, as namespace/name, grouped by apply-wave
+	Backups [][]string `json:"backups,omitempty"`
+	// PreservedPaths are the user-defined paths carried over to the new stateroot
+	PreservedPaths []string `json:"preservedPaths,omitempty"`
+	// Estimate is the expected duration of the stages, from their durations in the previous upgrades
+	Estimate map[ibuv1.ImageBasedUpgradeStage]string `json:"estimate,omitempty"`
+	// HostActions are the changes to the node that were skipped by the simulation
+	HostActions []string `json:"hostActions"`
+	// Errors are the steps that would fail the upgrade
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Simulator walks the Prep and Upgrade stages of an IBU without touching ostree nor rebooting the node. The content
+// exported to the new stateroot is written to a sandbox directory instead.
+type Simulator struct {
+	Client        client.Client
+	ClusterConfig clusterconfig.UpgradeClusterConfigGatherer
+	ExtraManifest extramanifest.EManifestHandler
+	BackupRestore backuprestore.BackuperRestorer
+	// Executor runs skopeo to inspect the seed image
+	Executor ops.Execute
+	// AuthFile holds the credentials of the seed image registry
+	AuthFile string
+	// StageDurationsFile holds the durations of the stages in the previous upgrades
+	StageDurationsFile string
+	Log                *logrus.Logger
+}
+
+// Run simulates the Prep and Upgrade stages of the IBU, writing the content of the new stateroot to the sandbox
+// directory. Every step is simulated, even when a previous one failed, so that the report lists all the errors.
+func (s *Simulator) Run(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, sandboxDir string) (*SimulationReport, error) {
+	if ibu.Spec.SeedImageRef.Image == "" || ibu.Spec.SeedImageRef.Version == "" {
+		return nil, fmt.Errorf("the seed image and version of the IBU must be set")
+	}
+	if err := os.MkdirAll(sandboxDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the sandbox directory %s: %w", sandboxDir, err)
+	}
+
+	stateroot := common.GetStaterootName(ibu.Spec.SeedImageRef.Version)
+	report := &SimulationReport{
+		Stateroot:  stateroot,
+		SandboxDir: sandboxDir,
+		HostActions: []string{
+			fmt.Sprintf("Pull the seed image %s", ibu.Spec.SeedImageRef.Image),
+			"Precache the images of the seed cluster",
+			fmt.Sprintf("Deploy the %s stateroot from the seed image", stateroot),
+			fmt.Sprintf("Set the %s stateroot as the default deployment", stateroot),
+			"Reboot the node into the new stateroot",
+		},
+	}
+	step := func(name string, err error) {
+		if err != nil {
+			s.Log.Warnf("Simulated step %s failed: %v", name, err)
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", name, err))
+			return
+		}
+		s.Log.Infof("Simulated step %s", name)
+	}
+
+	step("inspect seed image", s.inspectSeedImage(ibu, report))
+
+	if len(ibu.Spec.OADPContent) > 0 {
+		step("compute backup plan", s.computeBackups(ctx, ibu, report))
+		step("export OADP configuration", s.BackupRestore.ExportOadpConfigurationToDir(ctx, sandboxDir, backuprestore.OadpNs))
+		step("export restores", s.BackupRestore.ExportRestoresToDir(ctx, ibu.Spec.OADPContent, sandboxDir))
+	}
+
+	step("render extra manifests", s.renderExtraManifests(ctx, ibu, sandboxDir))
+	step("export user-defined health checks",
+		healthcheck.ExportCustomHealthChecksToDir(ctx, s.Client, ibu.Spec.HealthChecks, sandboxDir))
+	step("compute preserved paths", s.computePreservedPaths(ctx, ibu, report))
+	step("template cluster configuration",
+		s.ClusterConfig.FetchClusterConfig(ctx, sandboxDir, &ibu.Spec))
+	step("template LVM configuration", s.ClusterConfig.FetchLvmConfig(ctx, sandboxDir))
+	step("estimate durations", s.estimateDurations(report))
+
+	files, err := listFiles(sandboxDir)
+	if err != nil {
+		return nil, err
+	}
+	report.Files = files
+	return report, nil
+}
+
+func (s *Simulator) inspectSeedImage(ibu *ibuv1.ImageBasedUpgrade, report *SimulationReport) error {
+	inspect, err := seedimage.InspectImage(s.Executor, ibu.Spec.SeedImageRef.Image, s.AuthFile)
+	if err != nil {
+		return err
+	}
+	info, err := inspect.Info(ibu.Spec.SeedImageRef.Image)
+	if err != nil {
+		return err
+	}
+	report.SeedImage = info
+	report.SeedImageSize = inspect.Size()
+	if info.OCPVersion != "" && info.OCPVersion != ibu.Spec.SeedImageRef.Version {
+		return fmt.Errorf("the seed image version %s does not match the IBU seed version %s", info.OCPVersion, ibu.Spec.SeedImageRef.Version)
+	}
+	return nil
+}
+
+func (s *Simulator) computeBackups(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, report *SimulationReport) error {
+	groups, err := s.BackupRestore.GetSortedBackupsFromConfigmap(ctx, ibu.Spec.OADPContent)
+	if err != nil {
+		return fmt.Errorf("failed to get the backups: %w", err)
+	}
+	for _, group := range groups {
+		var names []string
+		for _, backup := range group {
+			names = append(names, fmt.Sprintf("%s/%s", backup.Namespace, backup.Name))
+		}
+		report.Backups = append(report.Backups, names)
+	}
+	return nil
+}
+
+// renderExtraManifests extracts the extra manifests from the policies and configmaps, as the Upgrade stage does
+func (s *Simulator) renderExtraManifests(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, sandboxDir string) error {
+	validationAnns := map[string]string{}
+	if count, exists := ibu.GetAnnotations()[extramanifest.TargetOcpVersionManifestCountAnnotation]; exists {
+		validationAnns[extramanifest.TargetOcpVersionManifestCountAnnotation] = count
+	}
+	versions, err := extramanifest.GetMatchingTargetOcpVersionLabelVersions(ibu.Spec.SeedImageRef.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get the target OCP versions: %w", err)
+	}
+	labels := map[string]string{extramanifest.TargetOcpVersionLabel: strings.Join(versions, ",")}
+	if err := s.ExtraManifest.ExtractAndExportManifestFromPoliciesToDir(ctx, nil, labels, validationAnns, sandboxDir); err != nil {
+		return fmt.Errorf("failed to export manifests from policies: %w", err)
+	}
+	if err := s.ExtraManifest.ExportExtraManifestToDir(ctx, ibu.Spec.ExtraManifests, sandboxDir); err != nil {
+		return fmt.Errorf("failed to export manifests from configmaps: %w", err)
+	}
+	return nil
+}
+
+func (s *Simulator) computePreservedPaths(ctx context.Context, ibu *ibuv1.ImageBasedUpgrade, report *SimulationReport) error {
+	if len(ibu.Spec.PreservedPaths) == 0 {
+		return nil
+	}
+	configmaps, err := common.GetConfigMaps(ctx, s.Client, ibu.Spec.PreservedPaths)
+	if err != nil {
+		return fmt.Errorf("failed to get preserved paths configMaps: %w", err)
+	}
+	paths, err := preservedpaths.ParsePreservedPaths(configmaps)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		report.PreservedPaths = append(report.PreservedPaths, p.Path)
+	}
+	return nil
+}
+
+func (s *Simulator) estimateDurations(report *SimulationReport) error {
+	durations, err := utils.ReadStageDurations(s.StageDurationsFile)
+	if err != nil {
+		return err
+	}
+	for _, stage := range []ibuv1.ImageBasedUpgradeStage{ibuv1.Stages.Prep, ibuv1.Stages.Upgrade} {
+		if average := durations.Average(stage); average > 0 {
+			if report.Estimate == nil {
+				report.Estimate = map[ibuv1.ImageBasedUpgradeStage]string{}
+			}
+			report.Estimate[stage] = average.Round(time.Second).String()
+		}
+	}
+	return nil
+}
+
+// listFiles returns the files under dir, relative to it
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path of %s: %w", path, err)
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files of the sandbox %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,110 @@
+package ibu
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
+	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	brmock "github.com/openshift-kni/lifecycle-agent/internal/backuprestore/mocks"
+	ccmock "github.com/openshift-kni/lifecycle-agent/internal/clusterconfig/mocks"
+	emmock "github.com/openshift-kni/lifecycle-agent/internal/extramanifest/mocks"
+	opsmock "github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+const simulateInspectOutput = `{
+  "Digest": "sha256:0123456789abcdef",
+  "Labels": {
+    "com.openshift.lifecycle-agent.seed_cluster_info": "{\"seed_cluster_ocp_version\":\"4.16.0\"}"
+  },
+  "LayersData": [{"Size": 100}, {"Size": 50}]
+}`
+
+func TestSimulate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockExecutor := opsmock.NewMockExecute(ctrl)
+	mockBR := brmock.NewMockBackuperRestorer(ctrl)
+	mockEM := emmock.NewMockEManifestHandler(ctrl)
+	mockCC := ccmock.NewMockUpgradeClusterConfigGatherer(ctrl)
+
+	durationsFile := filepath.Join(t.TempDir(), "durations.json")
+	assert.NoError(t, utils.WriteStageDurations(durationsFile, utils.StageDurations{
+		ibuv1.Stages.Prep: {{CompletionTime: metav1.Now(), DurationSeconds: 600}},
+	}))
+
+	simulator := &Simulator{
+		Client:             fake.NewClientBuilder().Build(),
+		ClusterConfig:      mockCC,
+		ExtraManifest:      mockEM,
+		BackupRestore:      mockBR,
+		Executor:           mockExecutor,
+		AuthFile:           "/tmp/auth.json",
+		StageDurationsFile: durationsFile,
+		Log:                logrus.New(),
+	}
+	ibu := &ibuv1.ImageBasedUpgrade{Spec: ibuv1.ImageBasedUpgradeSpec{
+		SeedImageRef: ibuv1.SeedImageRef{Image: "quay.io/seed:4.16", Version: seedVersion},
+		OADPContent:  []ibuv1.ConfigMapRef{{Name: "oadp", Namespace: "openshift-adp"}},
+	}}
+	sandbox := filepath.Join(t.TempDir(), "sandbox")
+
+	mockExecutor.EXPECT().Execute("skopeo", "inspect", "--retry-times", "10", "--authfile", "/tmp/auth.json",
+		"--format", "json", "docker://quay.io/seed:4.16").Return(simulateInspectOutput, nil)
+	mockBR.EXPECT().GetSortedBackupsFromConfigmap(gomock.Any(), ibu.Spec.OADPContent).Return([][]*velerov1.Backup{
+		{{ObjectMeta: metav1.ObjectMeta{Name: "acm-klusterlet", Namespace: "openshift-adp"}}},
+		{{ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "openshift-adp"}}},
+	}, nil)
+	mockBR.EXPECT().ExportOadpConfigurationToDir(gomock.Any(), sandbox, backuprestore.OadpNs).Return(nil)
+	mockBR.EXPECT().ExportRestoresToDir(gomock.Any(), ibu.Spec.OADPContent, sandbox).
+		DoAndReturn(func(_ context.Context, _ []ibuv1.ConfigMapRef, dir string) error {
+			restoreDir := filepath.Join(dir, "OADP", "restore", "1_default")
+			assert.NoError(t, os.MkdirAll(restoreDir, 0o700))
+			return os.WriteFile(filepath.Join(restoreDir, "restore.yaml"), []byte("kind: Restore\n"), 0o600)
+		})
+	mockEM.EXPECT().ExtractAndExportManifestFromPoliciesToDir(gomock.Any(), nil, gomock.Any(), gomock.Any(), sandbox).Return(nil)
+	mockEM.EXPECT().ExportExtraManifestToDir(gomock.Any(), ibu.Spec.ExtraManifests, sandbox).
+		Return(errors.New("configmap not found"))
+	mockCC.EXPECT().FetchClusterConfig(gomock.Any(), sandbox, &ibu.Spec).Return(nil)
+	mockCC.EXPECT().FetchLvmConfig(gomock.Any(), sandbox).Return(nil)
+
+	report, err := simulator.Run(context.Background(), ibu, sandbox)
+	assert.NoError(t, err)
+	assert.Equal(t, upgradeStateroot, report.Stateroot)
+	assert.Equal(t, "4.16.0", report.SeedImage.OCPVersion)
+	assert.Equal(t, int64(150), report.SeedImageSize)
+	assert.Equal(t, [][]string{{"openshift-adp/acm-klusterlet"}, {"openshift-adp/apps"}}, report.Backups)
+	assert.Equal(t, []string{filepath.Join("OADP", "restore", "1_default", "restore.yaml")}, report.Files)
+	assert.Equal(t, map[ibuv1.ImageBasedUpgradeStage]string{ibuv1.Stages.Prep: (10 * time.Minute).String()}, report.Estimate)
+	assert.NotEmpty(t, report.HostActions)
+	// The steps after the failed one are still simulated
+	assert.Equal(t, []string{"render extra manifests: failed to export manifests from configmaps: configmap not found"}, report.Errors)
+
+	// The seed image must match the seed version of the IBU
+	ibu.Spec.OADPContent = nil
+	ibu.Spec.SeedImageRef.Version = "4.17.0"
+	mockExecutor.EXPECT().Execute("skopeo", gomock.Any()).Return(simulateInspectOutput, nil)
+	mockEM.EXPECT().ExtractAndExportManifestFromPoliciesToDir(gomock.Any(), nil, gomock.Any(), gomock.Any(), sandbox).Return(nil)
+	mockEM.EXPECT().ExportExtraManifestToDir(gomock.Any(), nil, sandbox).Return(nil)
+	mockCC.EXPECT().FetchClusterConfig(gomock.Any(), sandbox, &ibu.Spec).Return(nil)
+	mockCC.EXPECT().FetchLvmConfig(gomock.Any(), sandbox).Return(nil)
+	report, err = simulator.Run(context.Background(), ibu, sandbox)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Backups)
+	assert.Equal(t, []string{"inspect seed image: the seed image version 4.16.0 does not match the IBU seed version 4.17.0"}, report.Errors)
+
+	_, err = simulator.Run(context.Background(), &ibuv1.ImageBasedUpgrade{}, sandbox)
+	assert.ErrorContains(t, err, "the seed image and version of the IBU must be set")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
@@ -17,6 +18,9 @@ import (
 	"github.com/go-logr/logr"
 )
 
+// deadlinePollInterval is how often the watchdog checks whether its deadline was extended
+var deadlinePollInterval = 30 * time.Second
+
 type InitMonitor struct {
 	scheme               *runtime.Scheme
 	log                  *logrus.Logger
@@ -81,11 +85,11 @@ func (m *InitMonitor) RunInitMonitor() error {
 	}
 	m.log.Infof("Launching LCA Init Monitor timeout. Automatic rollback will occur in %s if %s is not completed successfully within that time", timeout, contextText)
 
-	time.Sleep(timeout)
+	extension := m.waitForDeadline(time.Now().Add(timeout))
 
 	// If we reach this point, the init monitor was not shut down by the handler, so trigger rollback
 
-	msg := fmt.Sprintf("Rollback due to LCA Init Monitor timeout, after %s", timeout)
+	msg := fmt.Sprintf("Rollback due to LCA Init Monitor timeout, after %s", timeout+extension)
 	m.log.Info(msg)
 
 	if err := m.rebootClient.InitiateRollback(msg); err != nil {
@@ -95,6 +99,39 @@ func (m *InitMonitor) RunInitMonitor() error {
 	return nil
 }
 
+// waitForDeadline waits until the deadline of the watchdog, and returns the extension of the deadline it honored. In
+// ibu mode, the deadline is recorded for the controller to report it, and extended by the controller at runtime from
+// the IBU annotation.
+func (m *InitMonitor) waitForDeadline(deadline time.Time) time.Duration {
+	if m.mode == "ipconfig" {
+		time.Sleep(time.Until(deadline))
+		return 0
+	}
+
+	deadlineFile := common.PathOutsideChroot(common.IBUAutoRollbackDeadlineFile)
+	if err := reboot.WriteAutoRollbackDeadline(deadlineFile, deadline); err != nil {
+		m.log.Errorf("Unable to record the LCA Init Monitor deadline: %s", err)
+	}
+
+	var extension time.Duration
+	for {
+		current, err := reboot.ReadAutoRollbackDeadlineExtension(common.PathOutsideChroot(common.IBUAutoRollbackDeadlineExtensionFile))
+		if err != nil {
+			m.log.Errorf("Unable to read the LCA Init Monitor deadline extension: %s", err)
+		} else if current != extension {
+			extension = current
+			m.log.Infof("LCA Init Monitor deadline extension set to %s, automatic rollback will occur at %s",
+				extension, deadline.Add(extension).UTC().Format(time.RFC3339))
+		}
+
+		remaining := time.Until(deadline.Add(extension))
+		if remaining <= 0 {
+			return extension
+		}
+		time.Sleep(min(remaining, deadlinePollInterval))
+	}
+}
+
 func (m *InitMonitor) checkSvcUnitRollbackNeeded() bool {
 	rollbackCfg, err := m.rebootClient.ReadAutoRollbackConfigFile()
 	if err != nil {
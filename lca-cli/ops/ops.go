@@ -340,7 +340,8 @@ func (o *ops) ExtractTarWithSELinux(srcPath, destPath string) error {
 		return fmt.Errorf("failed to get path for file %s inside chroot: %w", tarExec, err)
 	}
 
-	tarArgs := []string{"xzf", srcPath, "-C", destPath}
+	// The compression of the archive is detected by tar, as the seed image archives are compressed with gzip or zstd
+	tarArgs := []string{"xf", srcPath, "-C", destPath}
 	tarArgs = append(tarArgs, common.TarOpts...)
 
 	if _, err = o.hostCommandsExecutor.Execute(tarExecInsideChroot, tarArgs...); err != nil {
@@ -8,10 +8,12 @@ import (
 	"io"
 	"io/fs"
 	"net"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	clusterconfig_api "github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
 	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/preservedpaths"
 	"github.com/openshift-kni/lifecycle-agent/internal/recert"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
@@ -37,6 +40,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/dynamic"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
 
 	cp "github.com/otiai10/copy"
 	"github.com/sirupsen/logrus"
@@ -45,6 +50,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -73,6 +79,9 @@ var (
 	nmConnectionFolder = common.NMConnectionFolder
 	nodePrimaryIPFile  = "/run/nodeip-configuration/primary-ip"
 	nodeIPHintFile     = "/etc/default/nodeip-configuration"
+
+	// chronyTimeSourceDirectives are the chrony directives defining the time sources replaced by the NTP servers
+	chronyTimeSourceDirectives = []string{"server", "pool", "peer"}
 )
 
 const (
@@ -89,6 +98,15 @@ const (
 
 	localhost         = "localhost"
 	kubeletConfigFile = "/etc/systemd/system/kubelet.service.d/20-nodenet.conf"
+
+	// defaultChronyConfig is the default /etc/chrony.conf of RHCOS, without its time sources
+	defaultChronyConfig = `driftfile /var/lib/chrony/drift
+makestep 1.0 3
+rtcsync
+keyfile /etc/chrony.keys
+leapsectz right/UTC
+logdir /var/log/chrony
+`
 )
 
 // seedClusterInfoNodeIPs Handles backward compatibility with the old seed cluster info file,
@@ -142,6 +160,11 @@ func (p *PostPivot) PostPivotConfiguration(ctx context.Context) error {
 	seedReconfiguration.NodeIPs = seedReconfigurationNodeIPs(seedReconfiguration)
 	p.log.Infof("Seed reconfiguration node IPs: %v", seedReconfiguration.NodeIPs)
 
+	// The hostname is only set along with the network configuration, fail before any change is applied
+	if err := validateHostname(seedReconfiguration.Hostname); err != nil {
+		return err
+	}
+
 	if err := utils.RunOnce("setSSHKey", p.workingDir, p.log, p.setSSHKey,
 		seedReconfiguration.SSHKey, sshKeyEarlyAccessFile); err != nil {
 		return fmt.Errorf("failed to run once setSSHKey for post pivot: %w", err)
@@ -174,6 +197,16 @@ func (p *PostPivot) PostPivotConfiguration(ctx context.Context) error {
 		return fmt.Errorf("failed copy cluster config files: %w", err)
 	}
 
+	if len(seedReconfiguration.NTPServers) > 0 {
+		p.log.Infof("Setting the NTP servers %v in the chrony config", seedReconfiguration.NTPServers)
+		seedReconfiguration.ChronyConfig = chronyConfigWithNTPServers(seedReconfiguration.ChronyConfig,
+			seedReconfiguration.NTPServers)
+	}
+
+	if err := utils.RunOnce("set_timezone", p.workingDir, p.log, p.setTimezone, seedReconfiguration.Timezone); err != nil {
+		return fmt.Errorf("failed to run once set_timezone for post pivot: %w", err)
+	}
+
 	if err := utils.RunOnce("recert", p.workingDir, p.log, p.recert, ctx, seedReconfiguration, seedClusterInfo); err != nil {
 		return fmt.Errorf("failed to run once recert for post pivot: %w", err)
 	}
@@ -186,6 +219,11 @@ func (p *PostPivot) PostPivotConfiguration(ctx context.Context) error {
 		return fmt.Errorf("failed to apply server ssh keys: %w", err)
 	}
 
+	if err := utils.RunOnce("restore_preserved_paths", p.workingDir, p.log, p.restorePreservedPaths,
+		filepath.Join(common.VarFolder, preservedpaths.PreservedPathsPath, preservedpaths.PreservedPathsFile)); err != nil {
+		return fmt.Errorf("failed to run once restore_preserved_paths for post pivot: %w", err)
+	}
+
 	client, err := utils.CreateKubeClient(p.scheme, p.kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to create k8s client, err: %w", err)
@@ -204,6 +242,12 @@ func (p *PostPivot) PostPivotConfiguration(ctx context.Context) error {
 	}
 	utils.WaitForApi(ctx, client, p.log)
 
+	if len(seedReconfiguration.WorkerNodes) > 0 {
+		if err := utils.RunOnce("pause_worker_nodes_rollout", p.workingDir, p.log, p.pauseWorkerNodesRollout, ctx, client, seedReconfiguration.WorkerNodes); err != nil {
+			return fmt.Errorf("failed to run once pause_worker_nodes_rollout for post pivot: %w", err)
+		}
+	}
+
 	if err := p.deleteAllOldMirrorResources(ctx, client); err != nil {
 		return fmt.Errorf("failed to all old mirror resources: %w", err)
 	}
@@ -232,6 +276,26 @@ func (p *PostPivot) PostPivotConfiguration(ctx context.Context) error {
 		return fmt.Errorf("failed to restore OADP DataProtectionApplication: %w", err)
 	}
 
+	if isDualStackConversion(seedClusterInfo, seedReconfiguration) {
+		if err := utils.RunOnce("convert_to_dual_stack", p.workingDir, p.log, p.convertToDualStack, ctx, client, seedReconfiguration); err != nil {
+			return fmt.Errorf("failed to run once convert_to_dual_stack for post pivot: %w", err)
+		}
+	}
+
+	if isIPv6OnlyConversion(seedClusterInfo, seedReconfiguration) {
+		if err := utils.RunOnce("convert_to_ipv6_only", p.workingDir, p.log, p.convertToIPv6Only, ctx, client, seedReconfiguration); err != nil {
+			return fmt.Errorf("failed to run once convert_to_ipv6_only for post pivot: %w", err)
+		}
+	}
+
+	if err := utils.RunOnce("reconfigure_proxy", p.workingDir, p.log, p.reconfigureProxy, ctx, client, seedReconfiguration, seedClusterInfo); err != nil {
+		return fmt.Errorf("failed to run once reconfigure_proxy for post pivot: %w", err)
+	}
+
+	if err := utils.RunOnce("add_additional_ca_bundles", p.workingDir, p.log, p.addAdditionalCABundles, ctx, client, seedReconfiguration); err != nil {
+		return fmt.Errorf("failed to run once add_additional_ca_bundles for post pivot: %w", err)
+	}
+
 	if err := utils.RunOnce("set_cluster_id", p.workingDir, p.log, p.setNewClusterID, ctx, client, seedReconfiguration); err != nil {
 		return fmt.Errorf("failed to run once set_cluster_id for post pivot: %w", err)
 	}
@@ -240,13 +304,21 @@ func (p *PostPivot) PostPivotConfiguration(ctx context.Context) error {
 		return fmt.Errorf("failed to run once set_cluster_id for post pivot: %w", err)
 	}
 
+	if err := utils.RunOnce("set_node_annotations", p.workingDir, p.log, p.setNodeAnnotations, ctx, client, seedReconfiguration.NodeAnnotations, 5*time.Minute); err != nil {
+		return fmt.Errorf("failed to run once set_node_annotations for post pivot: %w", err)
+	}
+
+	if err := utils.RunOnce("set_node_taints", p.workingDir, p.log, p.setNodeTaints, ctx, client, seedReconfiguration.NodeTaints, 5*time.Minute); err != nil {
+		return fmt.Errorf("failed to run once set_node_taints for post pivot: %w", err)
+	}
+
 	// Restore lvm devices
 	if err := utils.RunOnce("recover_lvm_devices", p.workingDir, p.log, p.recoverLvmDevices); err != nil {
 		return fmt.Errorf("failed to run once recover_lvm_devices for post pivot: %w", err)
 	}
 
-	if _, err = p.ops.SystemctlAction("disable", "installation-configuration.service"); err != nil {
-		return fmt.Errorf("failed to disable installation-configuration.service, err: %w", err)
+	if _, err = p.ops.SystemctlAction("disable", common.InstallationConfigurationService); err != nil {
+		return fmt.Errorf("failed to disable %s, err: %w", common.InstallationConfigurationService, err)
 	}
 
 	return p.cleanup()
@@ -319,6 +391,29 @@ func (p *PostPivot) applyServerSSHKeys(serverSSHKeys []clusterconfig_api.ServerS
 	return nil
 }
 
+// restorePreservedPaths extracts the user-defined paths archived before the pivot in place, then removes the archive
+func (p *PostPivot) restorePreservedPaths(archive string) error {
+	if _, err := os.Stat(archive); err != nil {
+		if os.IsNotExist(err) {
+			p.log.Info("No preserved paths were archived, skipping")
+			return nil
+		}
+		return fmt.Errorf("failed to stat preserved paths archive %s: %w", archive, err)
+	}
+
+	p.log.Infof("Restoring preserved paths from %s", archive)
+	tarArgs := []string{"xzf", archive, "-C", "/"}
+	tarArgs = append(tarArgs, common.TarOpts...)
+	if _, err := p.ops.RunInHostNamespace("tar", tarArgs...); err != nil {
+		return fmt.Errorf("failed to extract preserved paths: %w", err)
+	}
+
+	if err := os.Remove(archive); err != nil {
+		return fmt.Errorf("failed to remove preserved paths archive %s: %w", archive, err)
+	}
+	return nil
+}
+
 func removeOldServerSSHKeys() error {
 	files, err := filepath.Glob("/etc/ssh/ssh_host_*_key*")
 	if err != nil {
@@ -360,7 +455,7 @@ func createServerSSHKeyFile(serverSSHKey clusterconfig_api.ServerSSHKey) error {
 
 func (p *PostPivot) setProxyAndProxyStatus(seedReconfig *clusterconfig_api.SeedReconfiguration, seedClusterInfo *seedclusterinfo.SeedClusterInfo) error {
 	if seedReconfig.Proxy != nil != seedClusterInfo.HasProxy {
-		return fmt.Errorf("seedReconfig and seedClusterInfo proxy configuration mismatch")
+		p.log.Info("The seed and the cluster proxy configurations differ, the proxy is reconfigured once the cluster is up")
 	}
 
 	// In case of no proxy, we don't need to set it
@@ -428,6 +523,40 @@ func (p *PostPivot) restartChronydService(chronyConfig string) error {
 	return nil
 }
 
+// chronyConfigWithNTPServers returns the chrony config with its time sources replaced by the NTP servers, the default
+// chrony config of RHCOS being used if the chrony config is empty
+func chronyConfigWithNTPServers(chronyConfig string, ntpServers []string) string {
+	if chronyConfig == "" {
+		chronyConfig = defaultChronyConfig
+	}
+
+	lines := make([]string, 0, len(ntpServers))
+	for _, server := range ntpServers {
+		lines = append(lines, fmt.Sprintf("server %s iburst", server))
+	}
+	for _, line := range strings.Split(chronyConfig, "\n") {
+		if directive, _, _ := strings.Cut(strings.TrimSpace(line), " "); lo.Contains(chronyTimeSourceDirectives, directive) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setTimezone sets the system timezone of the node, timedatectl validating it against the time zone database
+func (p *PostPivot) setTimezone(timezone string) error {
+	if timezone == "" {
+		p.log.Info("No timezone was provided, keeping the timezone of the seed")
+		return nil
+	}
+
+	p.log.Infof("Setting the timezone to %s", timezone)
+	if _, err := p.ops.RunInHostNamespace("timedatectl", "set-timezone", timezone); err != nil {
+		return fmt.Errorf("failed to set the timezone to %s: %w", timezone, err)
+	}
+	return nil
+}
+
 func (p *PostPivot) applyManifests(ctx context.Context, mPath string, dynamicClient dynamic.Interface, restMapper meta.RESTMapper) error {
 	p.log.Infof("Applying manifests from %s", mPath)
 	mFiles, err := os.ReadDir(mPath)
@@ -574,6 +703,15 @@ func (p *PostPivot) establishEarlyCertificateTrust(seedReconfiguration *clusterc
 		}
 	}
 
+	// The additional CA bundles get their own anchor, so that the images can be pulled before they are added to the
+	// proxy trustedCA configmap, which the MCO writes to the user ca bundle anchor
+	if len(seedReconfiguration.AdditionalCABundles) > 0 {
+		bundle := mergeCABundles("", seedReconfiguration.AdditionalCABundles)
+		if err := os.WriteFile(common.AdditionalCABundlesFilePath, []byte(bundle), 0o600); err != nil {
+			return fmt.Errorf("failed to write additional ca bundles to %s: %w", common.AdditionalCABundlesFilePath, err)
+		}
+	}
+
 	_, err := p.ops.RunBashInHostNamespace("update-ca-trust")
 	if err != nil {
 		return fmt.Errorf("failed to run update-ca-trust after early certificate trust: %w", err)
@@ -701,6 +839,211 @@ func (p *PostPivot) setNewClusterID(ctx context.Context, client runtimeclient.Cl
 	return nil
 }
 
+// pauseWorkerNodesRollout pauses the worker MachineConfigPool so that the additional worker nodes attached to the SNO
+// are not rebooted into the new release while the control plane node is still upgrading. The worker nodes are recorded
+// on the pool, which is resumed by LCA once the upgrade of the control plane node has completed.
+func (p *PostPivot) pauseWorkerNodesRollout(ctx context.Context, client runtimeclient.Client, workerNodes []string) error {
+	p.log.Infof("Pausing the rollout of the worker nodes %s", strings.Join(workerNodes, ", "))
+	mcp := &mcfgv1.MachineConfigPool{}
+	if err := client.Get(ctx, types.NamespacedName{Name: common.WorkerMachineConfigPool}, mcp); err != nil {
+		return fmt.Errorf("failed to get %s machineConfigPool: %w", common.WorkerMachineConfigPool, err)
+	}
+
+	mcp.Spec.Paused = true
+	if mcp.Annotations == nil {
+		mcp.Annotations = map[string]string{}
+	}
+	mcp.Annotations[common.UpgradeWorkerNodesAnnotation] = strings.Join(workerNodes, ",")
+	if err := client.Update(ctx, mcp); err != nil {
+		return fmt.Errorf("failed to pause %s machineConfigPool: %w", common.WorkerMachineConfigPool, err)
+	}
+	return nil
+}
+
+// redactProxyURL returns the proxy URL with its password, if any, masked so that it can be logged
+func redactProxyURL(proxyURL string) string {
+	if proxyURL == "" {
+		return ""
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return u.Redacted()
+}
+
+// reconfigureProxy sets the cluster-wide proxy when it is added or removed by the seed reconfiguration. Recert only
+// renames an existing proxy configuration, so the Proxy CR is updated instead, along with its trustedCA configmap, and
+// the Cluster Network Operator and the Machine Config Operator roll out the new configuration.
+func (p *PostPivot) reconfigureProxy(ctx context.Context, client runtimeclient.Client,
+	seedReconfiguration *clusterconfig_api.SeedReconfiguration, seedClusterInfo *seedclusterinfo.SeedClusterInfo) error {
+	if seedClusterInfo.HasProxy == (seedReconfiguration.Proxy != nil) {
+		return nil
+	}
+
+	proxy := &v1.Proxy{}
+	if err := client.Get(ctx, types.NamespacedName{Name: common.OpenshiftProxyCRName}, proxy); err != nil {
+		return fmt.Errorf("failed to get proxy: %w", err)
+	}
+
+	proxy.Spec.HTTPProxy, proxy.Spec.HTTPSProxy, proxy.Spec.NoProxy = "", "", ""
+	if seedReconfiguration.Proxy != nil {
+		proxy.Spec.HTTPProxy = seedReconfiguration.Proxy.HTTPProxy
+		proxy.Spec.HTTPSProxy = seedReconfiguration.Proxy.HTTPSProxy
+		proxy.Spec.NoProxy = seedReconfiguration.Proxy.NoProxy
+	}
+
+	trustBundle := seedReconfiguration.AdditionalTrustBundle
+	proxy.Spec.TrustedCA.Name = trustBundle.ProxyConfigmapName
+	if trustBundle.ProxyConfigmapName != "" && trustBundle.ProxyConfigmapName != common.ClusterAdditionalTrustBundleName {
+		configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      trustBundle.ProxyConfigmapName,
+			Namespace: common.OpenshiftConfigNamespace,
+		}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, client, configmap, func() error {
+			configmap.Data = map[string]string{common.CaBundleDataKey: trustBundle.ProxyConfigmapBundle}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to set proxy trustedCA configmap %s: %w", trustBundle.ProxyConfigmapName, err)
+		}
+	}
+
+	p.log.Infof("Reconfiguring the cluster proxy, httpProxy: %q, httpsProxy: %q, noProxy: %q, trustedCA: %q",
+		redactProxyURL(proxy.Spec.HTTPProxy), redactProxyURL(proxy.Spec.HTTPSProxy), proxy.Spec.NoProxy, proxy.Spec.TrustedCA.Name)
+	if err := client.Update(ctx, proxy); err != nil {
+		return fmt.Errorf("failed to update proxy: %w", err)
+	}
+	return nil
+}
+
+// addAdditionalCABundles adds the additional CA bundles of the seed reconfiguration to the trustedCA configmap of the
+// Proxy CR, which is set to a dedicated configmap if the Proxy CR has none. The Machine Config Operator then rolls out
+// the merged bundle to the host trust store, and the cluster operators trust it along with the system CAs.
+func (p *PostPivot) addAdditionalCABundles(ctx context.Context, client runtimeclient.Client,
+	seedReconfiguration *clusterconfig_api.SeedReconfiguration) error {
+	if len(seedReconfiguration.AdditionalCABundles) == 0 {
+		return nil
+	}
+
+	proxy := &v1.Proxy{}
+	if err := client.Get(ctx, types.NamespacedName{Name: common.OpenshiftProxyCRName}, proxy); err != nil {
+		return fmt.Errorf("failed to get proxy: %w", err)
+	}
+	configmapName := proxy.Spec.TrustedCA.Name
+	if configmapName == "" {
+		configmapName = common.AdditionalCABundlesConfigmapName
+	}
+
+	p.log.Infof("Adding %d additional CA bundles to the proxy trustedCA configmap %s",
+		len(seedReconfiguration.AdditionalCABundles), configmapName)
+	configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      configmapName,
+		Namespace: common.OpenshiftConfigNamespace,
+	}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, configmap, func() error {
+		if configmap.Data == nil {
+			configmap.Data = map[string]string{}
+		}
+		configmap.Data[common.CaBundleDataKey] = mergeCABundles(configmap.Data[common.CaBundleDataKey],
+			seedReconfiguration.AdditionalCABundles)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to add additional ca bundles to configmap %s: %w", configmapName, err)
+	}
+
+	if proxy.Spec.TrustedCA.Name == configmapName {
+		return nil
+	}
+	proxy.Spec.TrustedCA.Name = configmapName
+	if err := client.Update(ctx, proxy); err != nil {
+		return fmt.Errorf("failed to set proxy trustedCA: %w", err)
+	}
+	return nil
+}
+
+// mergeCABundles appends the CA bundles that are not already part of the bundle
+func mergeCABundles(bundle string, additional []clusterconfig_api.PEM) string {
+	for _, ca := range additional {
+		ca := strings.TrimSpace(string(ca))
+		if strings.Contains(bundle, ca) {
+			continue
+		}
+		if bundle != "" && !strings.HasSuffix(bundle, "\n") {
+			bundle += "\n"
+		}
+		bundle += ca + "\n"
+	}
+	return bundle
+}
+
+// convertToDualStack adds the cluster and service networks of the IP family added by the seed reconfiguration to the
+// cluster network configuration. The seed networks are reconfigured by recert, while the Cluster Network Operator rolls
+// out the added networks.
+func (p *PostPivot) convertToDualStack(ctx context.Context, client runtimeclient.Client,
+	seedReconfiguration *clusterconfig_api.SeedReconfiguration) error {
+	addedFam, err := ipFamilyFromIP(seedReconfiguration.NodeIPs[1])
+	if err != nil {
+		return err
+	}
+
+	network := &v1.Network{}
+	if err := client.Get(ctx, types.NamespacedName{Name: common.OpenshiftInfraCRName}, network); err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	for _, entry := range clusterNetworksOfFamily(seedReconfiguration.ClusterNetworks, addedFam) {
+		if lo.ContainsBy(network.Spec.ClusterNetwork, func(e v1.ClusterNetworkEntry) bool { return e.CIDR == entry.CIDR }) {
+			continue
+		}
+		hostPrefix := entry.HostPrefix
+		if hostPrefix == 0 {
+			hostPrefix = lo.Ternary[uint32](addedFam == "ipv6", 64, 23)
+		}
+		network.Spec.ClusterNetwork = append(network.Spec.ClusterNetwork, v1.ClusterNetworkEntry{CIDR: entry.CIDR, HostPrefix: hostPrefix})
+	}
+	for _, cidr := range cidrsOfFamily(seedReconfiguration.ServiceNetworks, addedFam) {
+		if !lo.Contains(network.Spec.ServiceNetwork, cidr) {
+			network.Spec.ServiceNetwork = append(network.Spec.ServiceNetwork, cidr)
+		}
+	}
+
+	p.log.Infof("Converting the cluster to dual-stack, cluster networks: %v, service networks: %v",
+		network.Spec.ClusterNetwork, network.Spec.ServiceNetwork)
+	if err := client.Update(ctx, network); err != nil {
+		return fmt.Errorf("failed to update network: %w", err)
+	}
+	return nil
+}
+
+// convertToIPv6Only replaces the cluster and service networks of the seed with the IPv6 ones of the seed
+// reconfiguration in the cluster network configuration. The node IPs, etcd advertise addresses and machine networks
+// are reconfigured by recert, while the Cluster Network Operator and the operators rendering the kube-apiserver and
+// ingress configurations roll out the IPv6-only networks.
+func (p *PostPivot) convertToIPv6Only(ctx context.Context, client runtimeclient.Client,
+	seedReconfiguration *clusterconfig_api.SeedReconfiguration) error {
+	network := &v1.Network{}
+	if err := client.Get(ctx, types.NamespacedName{Name: common.OpenshiftInfraCRName}, network); err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	network.Spec.ClusterNetwork = []v1.ClusterNetworkEntry{}
+	for _, entry := range clusterNetworksOfFamily(seedReconfiguration.ClusterNetworks, common.IPv6FamilyName) {
+		hostPrefix := entry.HostPrefix
+		if hostPrefix == 0 {
+			hostPrefix = 64
+		}
+		network.Spec.ClusterNetwork = append(network.Spec.ClusterNetwork, v1.ClusterNetworkEntry{CIDR: entry.CIDR, HostPrefix: hostPrefix})
+	}
+	network.Spec.ServiceNetwork = cidrsOfFamily(seedReconfiguration.ServiceNetworks, common.IPv6FamilyName)
+
+	p.log.Infof("Converting the cluster to IPv6-only, cluster networks: %v, service networks: %v",
+		network.Spec.ClusterNetwork, network.Spec.ServiceNetwork)
+	if err := client.Update(ctx, network); err != nil {
+		return fmt.Errorf("failed to update network: %w", err)
+	}
+	return nil
+}
+
 // setDnsMasqConfiguration sets new configuration for dnsmasq and forcedns dispatcher script.
 // It points them to new ip, cluster name and domain.
 // For new configuration to apply we must restart NM and dnsmasq
@@ -960,6 +1303,18 @@ func (p *PostPivot) setupConfigurationFolder(deviceName, mountFolder, configFold
 	return nil
 }
 
+// validateHostname returns an error in case the provided hostname is not a valid node name.
+// The node certificates, kubeconfigs and etcd member of the seed hostname are renamed by recert.
+func validateHostname(hostname string) error {
+	if hostname == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return fmt.Errorf("provided hostname %s is invalid, it must be a valid node name: %s", hostname, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 // setHostname set provided hostname in case it was provided, in case it was not provided we will get hostname from kernel
 // retuning error in case hostname is localhost
 func (p *PostPivot) setHostname(hostname string) (string, error) {
@@ -1124,6 +1479,72 @@ func (p *PostPivot) setNodeLabels(ctx context.Context, client runtimeclient.Clie
 	return nil
 }
 
+// setNodeAnnotations sets the annotations preserved from the upgraded cluster on the node
+func (p *PostPivot) setNodeAnnotations(ctx context.Context, client runtimeclient.Client, nodeAnnotations map[string]string, timeout time.Duration) error {
+	if len(nodeAnnotations) == 0 {
+		p.log.Infof("No node annotations were provided, skipping")
+		return nil
+	}
+
+	annotationsAsString, err := json.Marshal(nodeAnnotations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node annotations %w", err)
+	}
+	p.log.Infof("Patching node with annotations %s", annotationsAsString)
+	data := []byte(`{"metadata": {"annotations": ` + string(annotationsAsString) + `}}`)
+	err = wait.PollUntilContextTimeout(ctx, 1*time.Second, timeout, false, func(ctx context.Context) (done bool, err error) {
+		node, err := utils.GetSNOMasterNode(ctx, client)
+		if err != nil {
+			p.log.Warnf("failed to get node, will retry, err: %v", err)
+			return false, nil
+		}
+		if err := client.Patch(ctx, node, runtimeclient.RawPatch(types.MergePatchType, data)); err != nil {
+			p.log.Warnf("failed to patch node with annotations, will retry, err: %v", err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to patch node with annotations %w", err)
+	}
+	return nil
+}
+
+// setNodeTaints adds the taints preserved from the upgraded cluster to the node, replacing the value of a taint with
+// the same key and effect
+func (p *PostPivot) setNodeTaints(ctx context.Context, client runtimeclient.Client, nodeTaints []clusterconfig_api.NodeTaint, timeout time.Duration) error {
+	if len(nodeTaints) == 0 {
+		p.log.Infof("No node taints were provided, skipping")
+		return nil
+	}
+
+	p.log.Infof("Adding node taints %v", nodeTaints)
+	err := wait.PollUntilContextTimeout(ctx, 1*time.Second, timeout, false, func(ctx context.Context) (done bool, err error) {
+		node, err := utils.GetSNOMasterNode(ctx, client)
+		if err != nil {
+			p.log.Warnf("failed to get node, will retry, err: %v", err)
+			return false, nil
+		}
+		for _, nodeTaint := range nodeTaints {
+			taint := corev1.Taint{Key: nodeTaint.Key, Value: nodeTaint.Value, Effect: corev1.TaintEffect(nodeTaint.Effect)}
+			if i := slices.IndexFunc(node.Spec.Taints, func(t corev1.Taint) bool { return t.MatchTaint(&taint) }); i >= 0 {
+				node.Spec.Taints[i].Value = taint.Value
+			} else {
+				node.Spec.Taints = append(node.Spec.Taints, taint)
+			}
+		}
+		if err := client.Update(ctx, node); err != nil {
+			p.log.Warnf("failed to update node taints, will retry, err: %v", err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node taints %w", err)
+	}
+	return nil
+}
+
 // getMachineNetworksFromSeedReconfig returns machine networks with backward compatibility
 func getMachineNetworksFromSeedReconfig(seedReconfig *clusterconfig_api.SeedReconfiguration) []string {
 	// Prefer the new list field if it's populated
@@ -1137,11 +1558,47 @@ func getMachineNetworksFromSeedReconfig(seedReconfig *clusterconfig_api.SeedReco
 	return []string{}
 }
 
+// isDualStackConversion reports whether a single-stack seed is converted to dual-stack, i.e. the seed reconfiguration
+// has a node IP of a second family
+func isDualStackConversion(seedClusterInfo *seedclusterinfo.SeedClusterInfo, seedReconfiguration *clusterconfig_api.SeedReconfiguration) bool {
+	return len(seedClusterInfo.NodeIPs) == 1 && len(seedReconfiguration.NodeIPs) == 2
+}
+
+// isIPv6OnlyConversion reports whether an IPv4 or dual-stack seed is converted to IPv6-only, i.e. the seed has an IPv4
+// node IP while the seed reconfiguration only has an IPv6 one
+func isIPv6OnlyConversion(seedClusterInfo *seedclusterinfo.SeedClusterInfo, seedReconfiguration *clusterconfig_api.SeedReconfiguration) bool {
+	return common.IsIPv6OnlyConversion(seedClusterInfo.NodeIPs, seedReconfiguration.NodeIPs)
+}
+
+// validateNMStateConfigForIPv6Only verifies that the nmstate configuration of an IPv6-only conversion does not
+// configure static IPv4 addresses, which would be left over from the IPv4 site data of the seed
+func validateNMStateConfigForIPv6Only(rawNMStateConfig string) error {
+	if rawNMStateConfig == "" {
+		return nil
+	}
+	var state utils.NmState
+	if err := yaml.Unmarshal([]byte(rawNMStateConfig), &state); err != nil {
+		return fmt.Errorf("failed to parse nmstate config: %w", err)
+	}
+	for _, iface := range state.Interfaces {
+		if iface.IPv4.Enabled && len(iface.IPv4.Address) > 0 {
+			return fmt.Errorf("IPv6-only conversion requires the nmstate config to not set IPv4 addresses, interface %s has %s",
+				iface.Name, iface.IPv4.Address[0].IP)
+		}
+	}
+	return nil
+}
+
 // validateIPAndMachineNetworkConsistency validates the amount and family order of node IPs and machine networks
 // across seed cluster info and seed reconfiguration, according to the following rules:
-// 1) seedClusterInfo.NodeIPs and seedReconfiguration.NodeIPs must have the same length, and at each index the IP family must match
+// 1) seedClusterInfo.NodeIPs and seedReconfiguration.NodeIPs must have the same length, and at each index the IP family must match.
+// A single-stack seed can be converted to dual-stack, in which case seedReconfiguration.NodeIPs has a second IP of the other family
 // 2) seedReconfiguration.MachineNetworks length must equal seedReconfiguration.NodeIPs length, and at each index the family must match
-// 3) If seedClusterInfo.MachineNetworks is non-empty, it must have the same length as seedReconfiguration.MachineNetworks, and at each index the family must match
+// 3) If seedClusterInfo.MachineNetworks is non-empty, it must have the same length as seedReconfiguration.MachineNetworks, and at each index the family must match,
+// apart from the machine network of the family added by a dual-stack conversion
+// 4) In case of a dual-stack conversion, seedReconfiguration.ClusterNetworks and ServiceNetworks must include a CIDR of the added family
+// 5) An IPv4 or dual-stack seed can be converted to IPv6-only, in which case rules 1 and 3 do not apply and
+// seedReconfiguration.ClusterNetworks, ServiceNetworks and the nmstate config must hold IPv6 site data
 func validateIPAndMachineNetworkConsistency(seedClusterInfo *seedclusterinfo.SeedClusterInfo, seedReconfiguration *clusterconfig_api.SeedReconfiguration) error {
 	seedIPs := seedClusterInfo.NodeIPs
 	reconfigIPs := seedReconfiguration.NodeIPs
@@ -1150,21 +1607,58 @@ func validateIPAndMachineNetworkConsistency(seedClusterInfo *seedclusterinfo.See
 		return fmt.Errorf("node IPs must be provided in both seedClusterInfo and seedReconfiguration")
 	}
 
-	if len(seedIPs) != len(reconfigIPs) {
+	dualStackConversion := isDualStackConversion(seedClusterInfo, seedReconfiguration)
+	ipv6OnlyConversion := isIPv6OnlyConversion(seedClusterInfo, seedReconfiguration)
+	if len(seedIPs) != len(reconfigIPs) && !dualStackConversion && !ipv6OnlyConversion {
 		return fmt.Errorf("node IPs count mismatch: seed has %d, reconfiguration has %d", len(seedIPs), len(reconfigIPs))
 	}
 
-	for i := range seedIPs {
-		seedFam, err := ipFamilyFromIP(seedIPs[i])
+	if dualStackConversion {
+		primaryFam, err := ipFamilyFromIP(reconfigIPs[0])
 		if err != nil {
-			return fmt.Errorf("invalid seed node IP at index %d: %w", i, err)
+			return fmt.Errorf("invalid reconfiguration node IP at index 0: %w", err)
 		}
-		reconfFam, err := ipFamilyFromIP(reconfigIPs[i])
+		addedFam, err := ipFamilyFromIP(reconfigIPs[1])
 		if err != nil {
-			return fmt.Errorf("invalid reconfiguration node IP at index %d: %w", i, err)
+			return fmt.Errorf("invalid reconfiguration node IP at index 1: %w", err)
+		}
+		if primaryFam == addedFam {
+			return fmt.Errorf("dual-stack conversion requires node IPs of both families, reconfiguration has two %s IPs", addedFam)
+		}
+		if len(clusterNetworksOfFamily(seedReconfiguration.ClusterNetworks, addedFam)) == 0 {
+			return fmt.Errorf("dual-stack conversion requires a %s cluster network in the reconfiguration", addedFam)
+		}
+		if len(cidrsOfFamily(seedReconfiguration.ServiceNetworks, addedFam)) == 0 {
+			return fmt.Errorf("dual-stack conversion requires a %s service network in the reconfiguration", addedFam)
 		}
-		if seedFam != reconfFam {
-			return fmt.Errorf("node IP family mismatch at index %d: seed has %s, reconfiguration has %s", i, seedFam, reconfFam)
+	}
+
+	if ipv6OnlyConversion {
+		if len(clusterNetworksOfFamily(seedReconfiguration.ClusterNetworks, common.IPv6FamilyName)) == 0 {
+			return fmt.Errorf("IPv6-only conversion requires an ipv6 cluster network in the reconfiguration")
+		}
+		if len(cidrsOfFamily(seedReconfiguration.ServiceNetworks, common.IPv6FamilyName)) == 0 {
+			return fmt.Errorf("IPv6-only conversion requires an ipv6 service network in the reconfiguration")
+		}
+		if err := validateNMStateConfigForIPv6Only(seedReconfiguration.RawNMStateConfig); err != nil {
+			return err
+		}
+	}
+
+	// An IPv6-only conversion replaces the IPs of all the seed families
+	if !ipv6OnlyConversion {
+		for i := range seedIPs {
+			seedFam, err := ipFamilyFromIP(seedIPs[i])
+			if err != nil {
+				return fmt.Errorf("invalid seed node IP at index %d: %w", i, err)
+			}
+			reconfFam, err := ipFamilyFromIP(reconfigIPs[i])
+			if err != nil {
+				return fmt.Errorf("invalid reconfiguration node IP at index %d: %w", i, err)
+			}
+			if seedFam != reconfFam {
+				return fmt.Errorf("node IP family mismatch at index %d: seed has %s, reconfiguration has %s", i, seedFam, reconfFam)
+			}
 		}
 	}
 
@@ -1185,7 +1679,10 @@ func validateIPAndMachineNetworkConsistency(seedClusterInfo *seedclusterinfo.See
 	}
 
 	// Rule 3: if seedClusterInfo has machine networks, validate count and family order against reconfiguration
-	if len(seedClusterInfo.MachineNetworks) > 0 {
+	if len(seedClusterInfo.MachineNetworks) > 0 && !ipv6OnlyConversion {
+		if dualStackConversion {
+			reconfigMNs = reconfigMNs[:len(seedIPs)]
+		}
 		if len(seedClusterInfo.MachineNetworks) != len(reconfigMNs) {
 			return fmt.Errorf("seed machineNetworks count (%d) must equal reconfiguration machineNetworks count (%d)", len(seedClusterInfo.MachineNetworks), len(reconfigMNs))
 		}
@@ -1207,6 +1704,20 @@ func validateIPAndMachineNetworkConsistency(seedClusterInfo *seedclusterinfo.See
 	return nil
 }
 
+func cidrsOfFamily(cidrs []string, family string) []string {
+	return lo.Filter(cidrs, func(cidr string, _ int) bool {
+		fam, err := ipFamilyFromCIDR(cidr)
+		return err == nil && fam == family
+	})
+}
+
+func clusterNetworksOfFamily(entries []clusterconfig_api.ClusterNetworkEntry, family string) []clusterconfig_api.ClusterNetworkEntry {
+	return lo.Filter(entries, func(entry clusterconfig_api.ClusterNetworkEntry, _ int) bool {
+		fam, err := ipFamilyFromCIDR(entry.CIDR)
+		return err == nil && fam == family
+	})
+}
+
 func ipFamilyFromIP(ipStr string) (string, error) {
 	ip := net.ParseIP(ipStr)
 	if ip == nil {
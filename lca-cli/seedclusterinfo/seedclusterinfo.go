@@ -102,6 +102,66 @@ type SeedClusterInfo struct {
 	// For single stack ocp clusters, this will be a single subnet.
 	// For dual-stack ocp clusters, this will be a list of two subnets.
 	MachineNetworks []string `json:"machine_networks,omitempty"`
+
+	// The content that was stripped from the seed image, as requested in the
+	// SeedGenerator spec.exclusions. Recorded so that the users of the seed
+	// know which site-specific or sensitive data it does not carry.
+	Exclusions *SeedExclusions `json:"exclusions,omitempty"`
+
+	// The etcd content of the seed image, as requested in the SeedGenerator
+	// spec.etcd, when not the full etcd data dir. With the Excluded content,
+	// the etcd data dir is re-initialized from the snapshot carried by the
+	// seed image when the new stateroot is set up.
+	EtcdContent string `json:"etcd_content,omitempty"`
+
+	// The total size in bytes of the container images of the seed cluster,
+	// which are precached during the Prep stage of an IBU. Used to validate
+	// the disk space available for the precached images before the Prep
+	// stage sets up the new stateroot.
+	ContainerImagesSize int64 `json:"container_images_size,omitempty"`
+
+	// The container storage configuration of the seed cluster, from
+	// /etc/containers/storage.conf. The Prep stage of an IBU reconciles it
+	// with the configuration of the target cluster in the new stateroot, as
+	// this is where the precached images are stored.
+	ContainerStorage *ContainerStorageConfig `json:"container_storage,omitempty"`
+
+	// The RHCOS version of the booted deployment of the seed cluster. Like
+	// the components below, it is only informative: it is reported by the
+	// seed image inspection so that the users can verify they target the
+	// intended seed before the Prep stage.
+	BaseOSVersion string `json:"base_os_version,omitempty"`
+
+	// The ClusterServiceVersions of the operators installed on the seed
+	// cluster.
+	Components []string `json:"components,omitempty"`
+}
+
+type ContainerStorageConfig struct {
+	// The storage driver, e.g. overlay
+	Driver string `json:"driver,omitempty"`
+
+	// The location of the read-write container storage
+	GraphRoot string `json:"graphroot,omitempty"`
+
+	// The location of the separate image store, if set
+	ImageStore string `json:"imagestore,omitempty"`
+}
+
+type SeedExclusions struct {
+	// The /var and /etc paths left out of the seed archives
+	Paths []string `json:"paths,omitempty"`
+
+	// The patterns of the secret names deleted before the seed creation
+	SecretNamePatterns []string `json:"secretNamePatterns,omitempty"`
+
+	// The namespaces deleted before the seed creation
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// IsEmpty returns true when no content is excluded from the seed
+func (e *SeedExclusions) IsEmpty() bool {
+	return e == nil || len(e.Paths) == 0 && len(e.SecretNamePatterns) == 0 && len(e.Namespaces) == 0
 }
 
 type AdditionalTrustBundle struct {
@@ -137,3 +137,11 @@ func TestSeedClusterInfo_IPv6Only(t *testing.T) {
 	assert.Equal(t, []string{"fd01::/48"}, info.ClusterNetworks)
 	assert.Equal(t, []string{"fd02::/112"}, info.ServiceNetworks)
 }
+
+func TestSeedExclusions_IsEmpty(t *testing.T) {
+	var nilExclusions *SeedExclusions
+	assert.True(t, nilExclusions.IsEmpty())
+	assert.True(t, (&SeedExclusions{}).IsEmpty())
+	assert.False(t, (&SeedExclusions{Paths: []string{"/var/opt/site"}}).IsEmpty())
+	assert.False(t, (&SeedExclusions{Namespaces: []string{"site-monitoring"}}).IsEmpty())
+}
@@ -0,0 +1,104 @@
+package seedcreator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+)
+
+const (
+	etcdDataDir = "/var/lib/etcd"
+	// etcdStoreDir is where the etcd data dir is mounted in the unauthenticated etcd server container
+	etcdStoreDir = "/store"
+	// etcdEventsPrefix is the etcd key prefix of the events, which only relate to the seed cluster
+	etcdEventsPrefix = "/kubernetes.io/events/"
+	etcdutlPath      = "/usr/bin/etcdutl"
+)
+
+// etcdTrimmed returns true if the etcd content of the seed is trimmed, rather than the full etcd data dir
+func (s *SeedCreator) etcdTrimmed() bool {
+	return s.etcdContent == common.SeedEtcdTrimmed || s.etcdContent == common.SeedEtcdExcluded
+}
+
+// trimEtcd deletes the events from the etcd database of the seed, then compacts and defragments it. When the etcd
+// data dir is excluded from the seed image, a snapshot of the trimmed database is added to the seed image instead,
+// along with the etcdutl binary restoring it.
+func (s *SeedCreator) trimEtcd() error {
+	if err := s.ops.RunUnauthenticatedEtcdServer(s.authFile, common.EtcdContainerName); err != nil {
+		return fmt.Errorf("failed to run etcd: %w", err)
+	}
+	defer s.ops.StopEtcdServer(s.authFile, common.EtcdContainerName)
+
+	s.log.Info("Deleting the events from etcd")
+	if _, err := s.etcdctl("del", "--prefix", etcdEventsPrefix); err != nil {
+		return fmt.Errorf("failed to delete the events from etcd: %w", err)
+	}
+
+	output, err := s.etcdctl("endpoint", "status", "-w", "json")
+	if err != nil {
+		return fmt.Errorf("failed to get the etcd endpoint status: %w", err)
+	}
+	revision, err := parseEtcdRevision(output)
+	if err != nil {
+		return err
+	}
+
+	s.log.Infof("Compacting and defragmenting etcd at revision %d", revision)
+	if _, err := s.etcdctl("compact", strconv.FormatInt(revision, 10), "--physical"); err != nil {
+		return fmt.Errorf("failed to compact etcd: %w", err)
+	}
+	if _, err := s.etcdctl("defrag"); err != nil {
+		return fmt.Errorf("failed to defragment etcd: %w", err)
+	}
+
+	if s.etcdContent != common.SeedEtcdExcluded {
+		return nil
+	}
+
+	s.log.Info("Saving a snapshot of etcd into the seed image")
+	if _, err := s.etcdctl("snapshot", "save", path.Join(etcdStoreDir, common.EtcdSnapshotFileName)); err != nil {
+		return fmt.Errorf("failed to save the etcd snapshot: %w", err)
+	}
+	// The snapshot is saved in the etcd data dir, mounted in the etcd server container
+	snapshot := path.Join(s.backupDir, common.EtcdSnapshotFileName)
+	if err := os.Rename(path.Join(etcdDataDir, common.EtcdSnapshotFileName), snapshot); err != nil {
+		return fmt.Errorf("failed to move the etcd snapshot to %s: %w", snapshot, err)
+	}
+	if _, err := s.ops.RunInHostNamespace("podman", "cp", common.EtcdContainerName+":"+etcdutlPath,
+		path.Join(s.backupDir, common.EtcdutlFileName)); err != nil {
+		return fmt.Errorf("failed to copy the etcdutl binary: %w", err)
+	}
+	return nil
+}
+
+// etcdctl runs etcdctl against the unauthenticated etcd server
+func (s *SeedCreator) etcdctl(args ...string) (string, error) {
+	cmdArgs := append([]string{"exec", common.EtcdContainerName, "etcdctl", "--endpoints", "http://" + common.EtcdDefaultEndpoint}, args...)
+	output, err := s.ops.RunInHostNamespace("podman", cmdArgs...)
+	if err != nil {
+		return "", fmt.Errorf("failed to run etcdctl %v: %w", args, err)
+	}
+	return output, nil
+}
+
+// parseEtcdRevision returns the revision of the etcd database from the JSON output of etcdctl endpoint status
+func parseEtcdRevision(output string) (int64, error) {
+	var statuses []struct {
+		Status struct {
+			Header struct {
+				Revision int64 `json:"revision"`
+			} `json:"header"`
+		} `json:"Status"`
+	}
+	if err := json.Unmarshal([]byte(output), &statuses); err != nil {
+		return 0, fmt.Errorf("failed to parse the etcd endpoint status: %w", err)
+	}
+	if len(statuses) == 0 || statuses[0].Status.Header.Revision == 0 {
+		return 0, fmt.Errorf("no revision found in the etcd endpoint status: %s", output)
+	}
+	return statuses[0].Status.Header.Revision, nil
+}
@@ -0,0 +1,88 @@
+package seedcreator
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/containers/image/v5/docker/reference"
+)
+
+// manifestListEntry is an image of a remote manifest list, as listed by skopeo inspect --raw
+type manifestListEntry struct {
+	Digest   string `json:"digest"`
+	Platform struct {
+		Architecture string `json:"architecture"`
+	} `json:"platform"`
+}
+
+// remoteManifestListEntries returns the images of the remote manifest list, or none if the manifest list does not exist
+// yet or is a single image, being replaced by the manifest list
+func (s *SeedCreator) remoteManifestListEntries() ([]manifestListEntry, error) {
+	raw, err := s.ops.RunInHostNamespace("skopeo", "inspect", "--raw", "--retry-times", "3", "--authfile", s.authFile,
+		"docker://"+s.manifestList)
+	if err != nil {
+		if strings.Contains(err.Error(), "manifest unknown") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to inspect the manifest list %s: %w", s.manifestList, err)
+	}
+
+	manifest := struct {
+		Manifests []manifestListEntry `json:"manifests"`
+	}{}
+	if err := json.Unmarshal([]byte(raw), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest list %s: %w", s.manifestList, err)
+	}
+	return manifest.Manifests, nil
+}
+
+// pushManifestList adds the pushed seed image to the manifest list, replacing the image of the same architecture if
+// any, so that the seed images generated from seed SNOs of different architectures share the same pull-spec
+func (s *SeedCreator) pushManifestList() error {
+	s.log.Infof("Adding the %s seed image to the manifest list %s", runtime.GOARCH, s.manifestList)
+	named, err := reference.ParseNormalizedNamed(s.manifestList)
+	if err != nil {
+		return fmt.Errorf("failed to parse the manifest list %s: %w", s.manifestList, err)
+	}
+	entries, err := s.remoteManifestListEntries()
+	if err != nil {
+		return err
+	}
+
+	// A list left over by a previous generation is replaced
+	if _, err := s.ops.RunInHostNamespace("podman", "rmi", "--ignore", s.manifestList); err != nil {
+		return fmt.Errorf("failed to remove the local manifest list %s: %w", s.manifestList, err)
+	}
+	if _, err := s.ops.RunInHostNamespace("podman", "manifest", "create", s.manifestList); err != nil {
+		return fmt.Errorf("failed to create the manifest list %s: %w", s.manifestList, err)
+	}
+	defer func() {
+		if _, err := s.ops.RunInHostNamespace("podman", "manifest", "rm", s.manifestList); err != nil {
+			s.log.Warnf("Failed to remove the local manifest list %s: %v", s.manifestList, err)
+		}
+	}()
+
+	for _, entry := range entries {
+		if entry.Platform.Architecture == runtime.GOARCH {
+			continue
+		}
+		s.log.Infof("Keeping the %s seed image %s of the manifest list", entry.Platform.Architecture, entry.Digest)
+		if _, err := s.ops.RunInHostNamespace("podman", "manifest", "add", "--authfile", s.authFile, s.manifestList,
+			fmt.Sprintf("docker://%s@%s", named.Name(), entry.Digest)); err != nil {
+			return fmt.Errorf("failed to add the %s seed image to the manifest list: %w", entry.Platform.Architecture, err)
+		}
+	}
+	// The seed image is added as pushed, its layers being encrypted if an encryption key is provided
+	if _, err := s.ops.RunInHostNamespace("podman", "manifest", "add", "--authfile", s.authFile, s.manifestList,
+		"docker://"+s.containerRegistry); err != nil {
+		return fmt.Errorf("failed to add the seed image to the manifest list: %w", err)
+	}
+
+	if _, err := s.ops.RunInHostNamespace("podman", "manifest", "push", "--all", "--authfile", s.authFile, s.manifestList,
+		"docker://"+s.manifestList); err != nil {
+		return fmt.Errorf("failed to push the manifest list %s: %w", s.manifestList, err)
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	ignconfig "github.com/coreos/ignition/v2/config"
@@ -21,6 +23,8 @@ import (
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/prep"
+	"github.com/openshift-kni/lifecycle-agent/internal/seedimage"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	ostree "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/seedclusterinfo"
@@ -45,11 +49,27 @@ type SeedCreator struct {
 	authFile             string
 	recertContainerImage string
 	recertSkipValidation bool
+	encryptionKey        string
+	baseSeedImage        string
+	digestFile           string
+	exclusions           *seedclusterinfo.SeedExclusions
+	compression          Compression
+	etcdContent          string
+	manifestList         string
+}
+
+// Compression is the compression of the archives of the seed image
+type Compression struct {
+	// Algorithm is gzip or zstd, defaulting to gzip
+	Algorithm string
+	// Level is the compression level, the default level of the algorithm being used if zero
+	Level int
 }
 
 // NewSeedCreator is a constructor function for SeedCreator
 func NewSeedCreator(client runtime.Client, log *logrus.Logger, ops ops.Ops, ostreeClient *ostree.Client, backupDir,
-	kubeconfig, containerRegistry, authFile, recertContainerImage string, recertSkipValidation bool) *SeedCreator {
+	kubeconfig, containerRegistry, authFile, recertContainerImage string, recertSkipValidation bool, encryptionKey, baseSeedImage,
+	digestFile string, exclusions *seedclusterinfo.SeedExclusions, compression Compression, etcdContent, manifestList string) *SeedCreator {
 
 	return &SeedCreator{
 		client:               client,
@@ -62,6 +82,13 @@ func NewSeedCreator(client runtime.Client, log *logrus.Logger, ops ops.Ops, ostr
 		authFile:             authFile,
 		recertContainerImage: recertContainerImage,
 		recertSkipValidation: recertSkipValidation,
+		encryptionKey:        encryptionKey,
+		baseSeedImage:        baseSeedImage,
+		digestFile:           digestFile,
+		exclusions:           exclusions,
+		compression:          compression,
+		etcdContent:          etcdContent,
+		manifestList:         manifestList,
 	}
 }
 
@@ -134,6 +161,12 @@ func (s *SeedCreator) CreateSeedImage() error {
 		return fmt.Errorf("failed remove all OVN certs folders: %w", err)
 	}
 
+	if s.etcdTrimmed() {
+		if err := utils.RunOnce("trim_etcd", common.BackupChecksDir, s.log, s.trimEtcd); err != nil {
+			return fmt.Errorf("failed to run once trim_etcd: %w", err)
+		}
+	}
+
 	if err := utils.RunOnce("backup_var", common.BackupChecksDir, s.log, s.backupVar); err != nil {
 		return fmt.Errorf("failed to run once backup_var: %w", err)
 	}
@@ -240,6 +273,29 @@ func (s *SeedCreator) gatherClusterInfo(ctx context.Context) error {
 		containerStorageMountpointTarget,
 		clusterInfo.IngressCertificateCN,
 	)
+	if !s.exclusions.IsEmpty() {
+		seedClusterInfo.Exclusions = s.exclusions
+	}
+	if s.etcdTrimmed() {
+		seedClusterInfo.EtcdContent = s.etcdContent
+	}
+
+	if seedClusterInfo.ContainerStorage, err = prep.ReadContainerStorageConfig(prep.StorageConfFile); err != nil {
+		return fmt.Errorf("failed to get container storage configuration: %w", err)
+	}
+
+	containerImagesSize, err := s.getContainerImagesSize()
+	if err != nil {
+		return fmt.Errorf("failed to get container images size: %w", err)
+	}
+	seedClusterInfo.ContainerImagesSize = containerImagesSize
+
+	if seedClusterInfo.BaseOSVersion, err = s.getBaseOSVersion(); err != nil {
+		return fmt.Errorf("failed to get base OS version: %w", err)
+	}
+	if seedClusterInfo.Components, err = s.getComponents(ctx); err != nil {
+		return fmt.Errorf("failed to get installed components: %w", err)
+	}
 
 	if err := os.MkdirAll(common.SeedDataDir, os.ModePerm); err != nil {
 		return fmt.Errorf("error creating SeedDataDir %s: %w", common.SeedDataDir, err)
@@ -261,6 +317,67 @@ func (s *SeedCreator) gatherClusterInfo(ctx context.Context) error {
 	return nil
 }
 
+// getContainerImagesSize returns the total size of the container images of the seed cluster, as reported by crictl
+func (s *SeedCreator) getContainerImagesSize() (int64, error) {
+	output, err := s.ops.RunBashInHostNamespace("crictl", "images", "-o", "json")
+	if err != nil {
+		return 0, fmt.Errorf("failed to run crictl images: %w", err)
+	}
+
+	var images struct {
+		Images []struct {
+			Size string `json:"size"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal([]byte(output), &images); err != nil {
+		return 0, fmt.Errorf("failed to parse crictl images output: %w", err)
+	}
+
+	var total int64
+	for _, image := range images.Images {
+		size, err := strconv.ParseInt(image.Size, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse image size %q: %w", image.Size, err)
+		}
+		total += size
+	}
+	s.log.Infof("Container images of the seed cluster use %d bytes", total)
+	return total, nil
+}
+
+// getBaseOSVersion returns the RHCOS version of the booted deployment of the seed cluster
+func (s *SeedCreator) getBaseOSVersion() (string, error) {
+	statusRpmOstree, err := s.ostreeClient.QueryStatus()
+	if err != nil {
+		return "", fmt.Errorf("failed to query ostree status: %w", err)
+	}
+	seedDeployment, found := lo.Find(statusRpmOstree.Deployments, func(d ostree.Deployment) bool { return d.Booted })
+	if !found {
+		return "", fmt.Errorf("failed to find the booted ostree deployment")
+	}
+	return seedDeployment.Version, nil
+}
+
+// getComponents returns the sorted names of the ClusterServiceVersions installed on the seed cluster, leaving out the
+// copies OLM makes in the namespaces watched by the operators
+func (s *SeedCreator) getComponents(ctx context.Context) ([]string, error) {
+	clusterServiceVersionList := operatorsv1alpha1.ClusterServiceVersionList{}
+	if err := s.client.List(ctx, &clusterServiceVersionList); err != nil {
+		return nil, fmt.Errorf("failed to get csv list: %w", err)
+	}
+
+	var components []string
+	for _, csv := range clusterServiceVersionList.Items {
+		if csv.Status.Reason == operatorsv1alpha1.CSVReasonCopied {
+			continue
+		}
+		components = append(components, csv.Name)
+	}
+	components = lo.Uniq(components)
+	sort.Strings(components)
+	return components, nil
+}
+
 func (s *SeedCreator) getCsvRelatedImages(ctx context.Context) (imageList []string, rc error) {
 	clusterServiceVersionList := operatorsv1alpha1.ClusterServiceVersionList{}
 	err := s.client.List(ctx, &clusterServiceVersionList)
@@ -387,9 +504,14 @@ func (s *SeedCreator) backupVar() error {
 		"/var/lib/kubelet/pods/*",
 		common.OvnIcEtcFolder + "/*",
 	}
+	excludePatterns = append(excludePatterns, s.excludedPaths(common.VarFolder)...)
+	if s.etcdContent == common.SeedEtcdExcluded {
+		// The etcd data dir is re-initialized from the snapshot carried by the seed image
+		excludePatterns = append(excludePatterns, etcdDataDir+"/member")
+	}
 
 	// Build the tar command
-	tarArgs := []string{"czf", varTarFile}
+	tarArgs := s.tarCreateArgs(varTarFile)
 
 	// Ensure all MCD-managed files in /var/lib are explicitly included, to avoid accidental exclusion
 	if managedfiles, err := s.getVarLibFilelist(); err != nil {
@@ -428,7 +550,8 @@ func (s *SeedCreator) backupEtc() error {
 		"/etc/openvswitch/.conf.db.~lock~",
 		"/etc/hostname",
 	}
-	tarArgs := []string{"tar", "czf", path.Join(s.backupDir + "/etc.tgz")}
+	excludePatterns = append(excludePatterns, s.excludedPaths("/etc")...)
+	tarArgs := append([]string{"tar"}, s.tarCreateArgs(path.Join(s.backupDir+"/etc.tgz"))...)
 	for _, pattern := range excludePatterns {
 		// We're handling the excluded patterns in bash, we need to single quote them to prevent expansion
 		tarArgs = append(tarArgs, "--exclude", fmt.Sprintf("'%s'", pattern))
@@ -456,12 +579,44 @@ func (s *SeedCreator) backupEtc() error {
 	return nil
 }
 
+// tarCreateArgs returns the tar arguments creating the archive with the configured compression. The archives keep
+// their .tgz name whatever the compression, which is detected by tar when they are extracted.
+func (s *SeedCreator) tarCreateArgs(archive string) []string {
+	switch {
+	case s.compression.Algorithm == common.SeedCompressionZstd:
+		program := "zstd -T0"
+		if s.compression.Level > 0 {
+			program = fmt.Sprintf("%s -%d", program, s.compression.Level)
+		}
+		// The tar arguments are run in bash, so the program is single quoted to be passed as one argument
+		return []string{"cf", archive, "--use-compress-program", fmt.Sprintf("'%s'", program)}
+	case s.compression.Level > 0:
+		return []string{"cf", archive, "--use-compress-program", fmt.Sprintf("'gzip -%d'", s.compression.Level)}
+	default:
+		return []string{"czf", archive}
+	}
+}
+
+// excludedPaths returns the user-defined excluded paths under the given folder
+func (s *SeedCreator) excludedPaths(folder string) []string {
+	if s.exclusions == nil {
+		return nil
+	}
+	return lo.Filter(s.exclusions.Paths, func(p string, _ int) bool {
+		return strings.HasPrefix(p, folder+"/")
+	})
+}
+
 func (s *SeedCreator) backupOstree() error {
+	if s.baseSeedImage != "" {
+		return s.backupOstreeDelta()
+	}
+
 	s.log.Info("Backing up ostree")
 	ostreeTar := s.backupDir + "/ostree.tgz"
 
 	// Execute 'tar' command and backup /etc
-	args := []string{"czf", ostreeTar, "-C", "/ostree/repo", "."}
+	args := append(s.tarCreateArgs(ostreeTar), "-C", "/ostree/repo", ".")
 	args = append(args, common.TarOpts...)
 	if _, err := s.ops.RunBashInHostNamespace("tar", args...); err != nil {
 		return fmt.Errorf("failed backing ostree with args %s: %w", args, err)
@@ -470,6 +625,66 @@ func (s *SeedCreator) backupOstree() error {
 	return nil
 }
 
+// backupOstreeDelta backs up the ostree repo of a layered seed image, as an ostree static delta from the booted ostree
+// commit of the base seed image to the booted ostree commit of the seed SNO
+func (s *SeedCreator) backupOstreeDelta() error {
+	s.log.Infof("Backing up ostree as a static delta from the base seed image %s", s.baseSeedImage)
+
+	if _, err := s.ops.RunInHostNamespace("podman", "pull", "--authfile", s.authFile, s.baseSeedImage); err != nil {
+		return fmt.Errorf("failed to pull the base seed image: %w", err)
+	}
+	defer s.ops.UnmountAndRemoveImage(s.baseSeedImage)
+
+	baseMountpoint, err := s.ops.MountImage(s.baseSeedImage)
+	if err != nil {
+		return fmt.Errorf("failed to mount the base seed image: %w", err)
+	}
+	if _, err := os.Stat(common.PathOutsideChroot(filepath.Join(baseMountpoint, "ostree.tgz"))); err != nil {
+		return fmt.Errorf("the base seed image must be a full seed image, including ostree.tgz: %w", err)
+	}
+	baseRef, err := prep.GetSeedBootedRef(baseMountpoint)
+	if err != nil {
+		return fmt.Errorf("failed to get the booted ostree commit of the base seed image: %w", err)
+	}
+
+	statusRpmOstree, err := s.ostreeClient.QueryStatus()
+	if err != nil {
+		return fmt.Errorf("failed to query ostree status: %w", err)
+	}
+	seedDeployment, found := lo.Find(statusRpmOstree.Deployments, func(d ostree.Deployment) bool { return d.Booted })
+	if !found {
+		return fmt.Errorf("failed to find the booted ostree deployment")
+	}
+
+	// Assemble a temporary repo including both commits, from which the static delta is generated
+	ostreeRepo, err := os.MkdirTemp("/var/tmp", "ostree-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary ostree repo directory: %w", err)
+	}
+	defer os.RemoveAll(ostreeRepo)
+
+	if err := s.ops.ExtractTarWithSELinux(filepath.Join(baseMountpoint, "ostree.tgz"), ostreeRepo); err != nil {
+		return fmt.Errorf("failed to extract the ostree.tgz of the base seed image: %w", err)
+	}
+	if _, err := s.ops.RunInHostNamespace("ostree", "pull-local", "--repo="+ostreeRepo, "/ostree/repo",
+		seedDeployment.Checksum); err != nil {
+		return fmt.Errorf("failed to pull the booted ostree commit into the temporary ostree repo: %w", err)
+	}
+
+	deltaFile := filepath.Join(s.backupDir, common.SeedOstreeDeltaFileName)
+	args := []string{"static-delta", "generate", "--repo=" + ostreeRepo, "--from=" + baseRef,
+		"--to=" + seedDeployment.Checksum, "--inline", "--min-fallback-size=0", "--filename=" + deltaFile}
+	if _, err := s.ops.RunInHostNamespace("ostree", args...); err != nil {
+		return fmt.Errorf("failed to generate ostree static delta with args %s: %w", args, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(s.backupDir, common.SeedBaseImageFileName), []byte(s.baseSeedImage), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", common.SeedBaseImageFileName, err)
+	}
+	s.log.Info("Backup of ostree static delta created successfully.")
+	return nil
+}
+
 func (s *SeedCreator) backupRPMOstree() error {
 	rpmJSON := s.backupDir + "/rpm-ostree.json"
 	args := append([]string{"status", "-v", "--json"}, ">", rpmJSON)
@@ -517,6 +732,13 @@ func (s *SeedCreator) createAndPushSeedImage(clusterInfo string) error {
 	}
 	_ = tmpfile.Close() // Close the temporary file
 
+	// The content manifest lets the consumers of the seed image know what it includes without pulling it
+	contentHash, err := seedimage.WriteContentManifest(s.backupDir)
+	if err != nil {
+		return err
+	}
+	s.log.Infof("Seed image content manifest written, with digest %s", contentHash)
+
 	// Build the single OCI image (note: We could include --squash-all option, as well)
 	podmanBuildArgs := []string{
 		"build",
@@ -524,21 +746,40 @@ func (s *SeedCreator) createAndPushSeedImage(clusterInfo string) error {
 		"--tag", s.containerRegistry,
 		"--label", fmt.Sprintf("%s=%d", common.SeedFormatOCILabel, common.SeedFormatVersion),
 		"--label", fmt.Sprintf("%s=%s", common.SeedClusterInfoOCILabel, clusterInfo),
-		s.backupDir,
+		"--label", fmt.Sprintf("%s=%s", common.SeedContentHashOCILabel, contentHash),
+	}
+	if s.baseSeedImage != "" {
+		podmanBuildArgs = append(podmanBuildArgs, "--label", fmt.Sprintf("%s=%s", common.SeedBaseImageOCILabel, s.baseSeedImage))
 	}
+	podmanBuildArgs = append(podmanBuildArgs, s.backupDir)
 	_, err = s.ops.RunInHostNamespace(
 		"podman", podmanBuildArgs...)
 	if err != nil {
 		return fmt.Errorf("failed to build seed image: %w", err)
 	}
 
-	// Push the created OCI image to user's repository
+	// Push the created OCI image to user's repository, encrypting its layers if an encryption key is provided
+	podmanPushArgs := []string{"push", "--authfile", s.authFile}
+	if s.encryptionKey != "" {
+		s.log.Info("Encrypting seed image layers")
+		podmanPushArgs = append(podmanPushArgs, "--encryption-key", s.encryptionKey)
+	}
+	if s.digestFile != "" {
+		podmanPushArgs = append(podmanPushArgs, "--digestfile", s.digestFile)
+	}
+	podmanPushArgs = append(podmanPushArgs, s.containerRegistry)
 	_, err = s.ops.RunInHostNamespace(
-		"podman", []string{"push", "--authfile", s.authFile, s.containerRegistry}...)
+		"podman", podmanPushArgs...)
 	if err != nil {
 		return fmt.Errorf("failed to push seed image: %w", err)
 	}
 
+	if s.manifestList != "" {
+		if err := s.pushManifestList(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,135 @@
+package seedcreator
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
+)
+
+func TestTarCreateArgs(t *testing.T) {
+	testcases := []struct {
+		name        string
+		compression Compression
+		expect      []string
+	}{
+		{
+			name:   "default gzip",
+			expect: []string{"czf", "/var/tmp/backup/var.tgz"},
+		},
+		{
+			name:        "gzip with level",
+			compression: Compression{Algorithm: "gzip", Level: 9},
+			expect:      []string{"cf", "/var/tmp/backup/var.tgz", "--use-compress-program", "'gzip -9'"},
+		},
+		{
+			name:        "zstd",
+			compression: Compression{Algorithm: "zstd"},
+			expect:      []string{"cf", "/var/tmp/backup/var.tgz", "--use-compress-program", "'zstd -T0'"},
+		},
+		{
+			name:        "zstd with level",
+			compression: Compression{Algorithm: "zstd", Level: 19},
+			expect:      []string{"cf", "/var/tmp/backup/var.tgz", "--use-compress-program", "'zstd -T0 -19'"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &SeedCreator{compression: tc.compression}
+			assert.Equal(t, tc.expect, s.tarCreateArgs("/var/tmp/backup/var.tgz"))
+		})
+	}
+}
+
+func TestParseEtcdRevision(t *testing.T) {
+	revision, err := parseEtcdRevision(`[{"Endpoint":"http://localhost:2379","Status":{"header":{"cluster_id":14841639068965178418,"revision":52841},"version":"3.5.14","dbSize":104857600}}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(52841), revision)
+
+	_, err = parseEtcdRevision(`[]`)
+	assert.ErrorContains(t, err, "no revision found")
+	_, err = parseEtcdRevision(`Error: context deadline exceeded`)
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestTrimEtcd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockOps := ops.NewMockOps(ctrl)
+	s := &SeedCreator{log: logrus.New(), ops: mockOps, authFile: "/var/tmp/auth.json", etcdContent: common.SeedEtcdTrimmed}
+	etcdctl := []any{"exec", common.EtcdContainerName, "etcdctl", "--endpoints", "http://" + common.EtcdDefaultEndpoint}
+
+	gomock.InOrder(
+		mockOps.EXPECT().RunUnauthenticatedEtcdServer("/var/tmp/auth.json", common.EtcdContainerName).Return(nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", append(etcdctl, "del", "--prefix", "/kubernetes.io/events/")...).Return("1200", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", append(etcdctl, "endpoint", "status", "-w", "json")...).
+			Return(`[{"Status":{"header":{"revision":4321}}}]`, nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", append(etcdctl, "compact", "4321", "--physical")...).Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", append(etcdctl, "defrag")...).Return("", nil),
+		mockOps.EXPECT().StopEtcdServer("/var/tmp/auth.json", common.EtcdContainerName).Return(nil),
+	)
+	assert.NoError(t, s.trimEtcd())
+
+	// The etcd server is stopped when the trimming fails
+	gomock.InOrder(
+		mockOps.EXPECT().RunUnauthenticatedEtcdServer("/var/tmp/auth.json", common.EtcdContainerName).Return(nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", append(etcdctl, "del", "--prefix", "/kubernetes.io/events/")...).
+			Return("", fmt.Errorf("etcdserver: request timed out")),
+		mockOps.EXPECT().StopEtcdServer("/var/tmp/auth.json", common.EtcdContainerName).Return(nil),
+	)
+	assert.ErrorContains(t, s.trimEtcd(), "failed to delete the events")
+}
+
+func TestPushManifestList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockOps := ops.NewMockOps(ctrl)
+	variant := "quay.io/org/seed:4.16-" + runtime.GOARCH
+	s := &SeedCreator{log: logrus.New(), ops: mockOps, authFile: "/var/tmp/auth.json",
+		containerRegistry: variant, manifestList: "quay.io/org/seed:4.16"}
+	otherArch := "arm64"
+	if runtime.GOARCH == otherArch {
+		otherArch = "amd64"
+	}
+
+	gomock.InOrder(
+		mockOps.EXPECT().RunInHostNamespace("skopeo", "inspect", "--raw", "--retry-times", "3", "--authfile", "/var/tmp/auth.json",
+			"docker://quay.io/org/seed:4.16").Return(fmt.Sprintf(`{"manifests": [
+  {"digest": "sha256:1111", "platform": {"architecture": "%s", "os": "linux"}},
+  {"digest": "sha256:2222", "platform": {"architecture": "%s", "os": "linux"}}
+]}`, runtime.GOARCH, otherArch), nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "rmi", "--ignore", "quay.io/org/seed:4.16").Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "create", "quay.io/org/seed:4.16").Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "add", "--authfile", "/var/tmp/auth.json", "quay.io/org/seed:4.16",
+			"docker://quay.io/org/seed@sha256:2222").Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "add", "--authfile", "/var/tmp/auth.json", "quay.io/org/seed:4.16",
+			"docker://"+variant).Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "push", "--all", "--authfile", "/var/tmp/auth.json",
+			"quay.io/org/seed:4.16", "docker://quay.io/org/seed:4.16").Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "rm", "quay.io/org/seed:4.16").Return("", nil),
+	)
+	assert.NoError(t, s.pushManifestList())
+
+	// The manifest list is created on the first generation
+	gomock.InOrder(
+		mockOps.EXPECT().RunInHostNamespace("skopeo", "inspect", "--raw", "--retry-times", "3", "--authfile", "/var/tmp/auth.json",
+			"docker://quay.io/org/seed:4.16").Return("", fmt.Errorf("reading manifest 4.16 in quay.io/org/seed: manifest unknown")),
+		mockOps.EXPECT().RunInHostNamespace("podman", "rmi", "--ignore", "quay.io/org/seed:4.16").Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "create", "quay.io/org/seed:4.16").Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "add", "--authfile", "/var/tmp/auth.json", "quay.io/org/seed:4.16",
+			"docker://"+variant).Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "push", "--all", "--authfile", "/var/tmp/auth.json",
+			"quay.io/org/seed:4.16", "docker://quay.io/org/seed:4.16").Return("", nil),
+		mockOps.EXPECT().RunInHostNamespace("podman", "manifest", "rm", "quay.io/org/seed:4.16").Return("", nil),
+	)
+	assert.NoError(t, s.pushManifestList())
+
+	// The other architectures are not dropped when the manifest list can not be inspected
+	mockOps.EXPECT().RunInHostNamespace("skopeo", "inspect", "--raw", "--retry-times", "3", "--authfile", "/var/tmp/auth.json",
+		"docker://quay.io/org/seed:4.16").Return("", fmt.Errorf("unauthorized"))
+	assert.ErrorContains(t, s.pushManifestList(), "failed to inspect the manifest list quay.io/org/seed:4.16")
+}
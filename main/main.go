@@ -22,6 +22,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -30,10 +31,9 @@ import (
 	"github.com/openshift-kni/lifecycle-agent/internal/extramanifest"
 	"github.com/openshift-kni/lifecycle-agent/internal/imagemgmt"
 	"github.com/openshift-kni/lifecycle-agent/internal/networkpolicies"
-	kbatchv1 "k8s.io/api/batch/v1"
+	lcawebhook "github.com/openshift-kni/lifecycle-agent/internal/webhook"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,6 +41,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -66,10 +67,15 @@ import (
 	ibuv1 "github.com/openshift-kni/lifecycle-agent/api/imagebasedupgrade/v1"
 	"github.com/openshift-kni/lifecycle-agent/controllers"
 	"github.com/openshift-kni/lifecycle-agent/controllers/utils"
+	"github.com/openshift-kni/lifecycle-agent/internal/audit"
 	"github.com/openshift-kni/lifecycle-agent/internal/backuprestore"
+	"github.com/openshift-kni/lifecycle-agent/internal/checkpoint"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
+	"github.com/openshift-kni/lifecycle-agent/internal/diagnostics"
+	"github.com/openshift-kni/lifecycle-agent/internal/notifier"
 	"github.com/openshift-kni/lifecycle-agent/internal/ostreeclient"
 	"github.com/openshift-kni/lifecycle-agent/internal/precache"
+	"github.com/openshift-kni/lifecycle-agent/internal/progress"
 	"github.com/openshift-kni/lifecycle-agent/internal/reboot"
 	"github.com/openshift-kni/lifecycle-agent/lca-cli/ops"
 	rpmostreeclient "github.com/openshift-kni/lifecycle-agent/lca-cli/ostreeclient"
@@ -123,10 +129,18 @@ func main() {
 	var metricsCertDir string
 	var enableLeaderElection bool
 	var probeAddr string
+	var webhookPort int
+	var webhookCertDir string
+	var progressSocket string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&metricsCertDir, "metrics-tls-cert-dir", "",
 		"The directory containing the tls.crt and tls.key.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server binds to.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+		"The directory containing the tls.crt and tls.key of the webhook server. The webhooks are disabled without them.")
+	flag.StringVar(&progressSocket, "progress-socket", common.ProgressSocketFile,
+		"The unix socket of the node the local progress API is served on. The API is disabled when empty.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -156,14 +170,27 @@ func main() {
 		},
 	}
 
+	tuning, err := controllers.TuningFromEnv()
+	if err != nil {
+		setupLog.Error(err, "invalid reconcile tuning")
+		os.Exit(1)
+	}
+	controllers.SetTuning(tuning)
+
 	cfg := ctrl.GetConfigOrDie()
+	tuning.ApplyClientRateLimits(cfg)
 	cfg.Wrap(lcautils.RetryMiddleware(log.WithName("ibu-manager-client"))) // allow all client calls to be retriable
 
 	// OLM installs the default-deny, operator API egress NetworkPolicies
 	// Operator installs policies for the jobs, for metrics
+	// The serving certificate of the webhooks is provided by OLM
+	_, err = os.Stat(filepath.Join(webhookCertDir, "tls.crt"))
+	enableWebhooks := err == nil
+
 	np := networkpolicies.Policy{
-		Namespace:  common.LcaNamespace,
-		MetricAddr: metricsAddr,
+		Namespace:   common.LcaNamespace,
+		MetricAddr:  metricsAddr,
+		WebhookPort: webhookPort,
 	}
 	msg, err := np.InstallPolicies(cfg)
 	if err != nil {
@@ -192,14 +219,12 @@ func main() {
 			TLSOpts:        tlsOpts,
 			FilterProvider: filters.WithAuthenticationAndAuthorization,
 		},
-		Cache: cache.Options{ // https://github.com/kubernetes-sigs/controller-runtime/blob/main/designs/cache_options.md
-			ByObject: map[client.Object]cache.ByObject{
-				&kbatchv1.Job{}: { // cache all job resources in LCA ns
-					Namespaces: map[string]cache.Config{
-						common.LcaNamespace: {}},
-				},
-			},
-		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+			TLSOpts: tlsOpts,
+		}),
+		Cache: utils.ManagerCacheOptions(),
 	})
 
 	if err != nil {
@@ -247,6 +272,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	progressRecorder := progress.NewRecorder(common.PathOutsideChroot(common.ProgressSnapshotFile), log.WithName("Progress"))
+	if progressSocket != "" {
+		if err := mgr.Add(&progress.Server{
+			SocketPath: common.PathOutsideChroot(progressSocket),
+			Recorder:   progressRecorder,
+			Executor:   chrootExecutor,
+			Log:        log.WithName("ProgressServer"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the local progress API")
+			os.Exit(1)
+		}
+	}
+
+	auditLog := audit.NewLog(common.PathOutsideChroot(common.AuditLogFile), audit.SourceManager)
+	checkpoints := checkpoint.NewStore(common.PathOutsideChroot(common.CheckpointFile), log.WithName("Checkpoints"))
+
 	backupRestore := &backuprestore.BRHandler{
 		Client: mgr.GetClient(), DynamicClient: dynamicClient, Log: log.WithName("BackupRestore")}
 	extraManifest := &extramanifest.EMHandler{
@@ -266,6 +307,7 @@ func main() {
 		setupLog.Error(err, "Failed to get InClusterConfig")
 		os.Exit(1)
 	}
+	tuning.ApplyClientRateLimits(config)
 	config.Wrap(lcautils.RetryMiddleware(log.WithName("ibu-clientset"))) // allow all client calls to be retriable
 	// creates the clientset
 	clientset, err := kubernetes.NewForConfig(config)
@@ -274,7 +316,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.ImageBasedUpgradeReconciler{
+	ibuReconciler := &controllers.ImageBasedUpgradeReconciler{
 		Client:          mgr.GetClient(),
 		NoncachedClient: mgr.GetAPIReader(),
 		Log:             log,
@@ -287,6 +329,17 @@ func main() {
 		RebootClient:    ibuRebootClient,
 		BackupRestore:   backupRestore,
 		ExtraManifest:   extraManifest,
+		Progress:        progressRecorder,
+		Audit:           auditLog,
+		Notifier:        notifier.NewNotifier(mgr.GetAPIReader(), log.WithName("Notifier")),
+		Checkpoints:     checkpoints,
+		Diagnostics: &diagnostics.Collector{
+			Client:       mgr.GetClient(),
+			Executor:     chrootExecutor,
+			Log:          log.WithName("Diagnostics"),
+			Dir:          common.PathOutsideChroot(diagnostics.Dir),
+			AuditLogFile: common.PathOutsideChroot(common.AuditLogFile),
+		},
 		UpgradeHandler: &controllers.UpgHandler{
 			Client:          mgr.GetClient(),
 			NoncachedClient: mgr.GetAPIReader(),
@@ -300,6 +353,9 @@ func main() {
 			RPMOstreeClient: rpmOstreeClient,
 			OstreeClient:    ostreeClient,
 			RebootClient:    ibuRebootClient,
+			Progress:        progressRecorder,
+			Audit:           auditLog,
+			Checkpoints:     checkpoints,
 		},
 		Mux:       mux,
 		Clientset: clientset,
@@ -308,10 +364,38 @@ func main() {
 
 		// Cluster data retrieved once during init
 		ContainerStorageMountpointTarget: containerStorageMountpointTarget,
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if err = ibuReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ImageBasedUpgrade")
 		os.Exit(1)
 	}
+
+	if err = (&controllers.StaterootGCReconciler{
+		Client:          mgr.GetClient(),
+		NoncachedClient: mgr.GetAPIReader(),
+		Log:             ctrl.Log.WithName("controllers").WithName("StaterootGC"),
+		Mux:             mux,
+		Ops:             chrootOp,
+		OstreeClient:    ostreeClient,
+		RPMOstreeClient: rpmOstreeClient,
+		ImageMgmtClient: imageMgmtClient,
+		Recorder:        mgr.GetEventRecorderFor("ImageBasedUpgrade"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "StaterootGC")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ImageBasedUpgradePreflightReconciler{
+		Client:          mgr.GetClient(),
+		NoncachedClient: mgr.GetAPIReader(),
+		Log:             ctrl.Log.WithName("controllers").WithName("ImageBasedUpgradePreflight"),
+		Scheme:          mgr.GetScheme(),
+		Mux:             mux,
+		Checks:          ibuReconciler,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ImageBasedUpgradePreflight")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err = (&controllers.IPConfigReconciler{
@@ -379,14 +463,31 @@ func main() {
 	}
 	//+kubebuilder:scaffold:builder
 
+	if enableWebhooks {
+		if err := lcawebhook.SetupImageBasedUpgradeWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ImageBasedUpgrade")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("Webhooks disabled, no serving certificate found", "dir", webhookCertDir)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
+	if err := mgr.AddHealthzCheck("upgrade", progressRecorder.LivenessCheck()); err != nil {
+		setupLog.Error(err, "unable to set up upgrade health check")
+		os.Exit(1)
+	}
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("upgrade", progressRecorder.ReadinessCheck()); err != nil {
+		setupLog.Error(err, "unable to set up upgrade ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
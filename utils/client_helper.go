@@ -87,10 +87,14 @@ type ClusterInfo struct {
 	Hostname                 string
 	MirrorRegistryConfigured bool
 	ClusterNetworks          []string
+	ClusterNetworkEntries    []ocp_config_v1.ClusterNetworkEntry
 	ServiceNetworks          []string
 	MachineNetworks          []string
 	NodeLabels               map[string]string
+	NodeAnnotations          map[string]string
+	NodeTaints               []corev1.Taint
 	IngressCertificateCN     string
+	WorkerNodes              []string
 }
 
 func GetClusterInfo(ctx context.Context, client runtimeclient.Client) (*ClusterInfo, error) {
@@ -141,16 +145,25 @@ func GetClusterInfo(ctx context.Context, client runtimeclient.Client) (*ClusterI
 		return nil, err
 	}
 
-	clusterNetworks, serviceNetworks, err := getClusterNetworks(ctx, client)
+	clusterNetworkEntries, serviceNetworks, err := getClusterNetworks(ctx, client)
 	if err != nil {
 		return nil, err
 	}
+	var clusterNetworks []string
+	for _, cNet := range clusterNetworkEntries {
+		clusterNetworks = append(clusterNetworks, cNet.CIDR)
+	}
 
 	ingressCN, err := GetIngressCertificateCN(ctx, client)
 	if err != nil {
 		return nil, err
 	}
 
+	workerNodes, err := GetWorkerNodes(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ClusterInfo{
 		ClusterName:              clusterName,
 		BaseDomain:               clusterBaseDomain,
@@ -161,10 +174,14 @@ func GetClusterInfo(ctx context.Context, client runtimeclient.Client) (*ClusterI
 		Hostname:                 hostname,
 		MirrorRegistryConfigured: len(mirrorRegistrySources) > 0,
 		ClusterNetworks:          clusterNetworks,
+		ClusterNetworkEntries:    clusterNetworkEntries,
 		ServiceNetworks:          serviceNetworks,
 		MachineNetworks:          machineNetworks,
 		NodeLabels:               nodeLabels,
+		NodeAnnotations:          node.GetAnnotations(),
+		NodeTaints:               node.Spec.Taints,
 		IngressCertificateCN:     ingressCN,
+		WorkerNodes:              lo.Map(workerNodes, func(node corev1.Node, _ int) string { return node.Name }),
 	}, nil
 }
 
@@ -331,15 +348,14 @@ func HasProxy(ctx context.Context, client runtimeclient.Client) (bool, error) {
 }
 
 func HasFIPS(ctx context.Context, client runtimeclient.Client) (bool, error) {
-	nodes := &corev1.NodeList{}
-	if err := client.List(ctx, nodes); err != nil {
-		return false, fmt.Errorf("failed to list nodes: %w", err)
+	nodeName, err := GetLocalNodeName(ctx, client)
+	if err != nil {
+		return false, err
 	}
-
-	if len(nodes.Items) != 1 {
-		return false, fmt.Errorf("expected exactly one node, got %d", len(nodes.Items))
+	node := &corev1.Node{}
+	if err := client.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		return false, fmt.Errorf("failed to get node %s: %w", nodeName, err)
 	}
-	node := nodes.Items[0]
 
 	currentConfig := node.Annotations["machineconfiguration.openshift.io/currentConfig"]
 	if currentConfig == "" {
@@ -431,10 +447,11 @@ func GetIngressCertificateCN(ctx context.Context, client runtimeclient.Client) (
 	if err != nil {
 		return "", err
 	}
-	return getCommonNameFromCertificate([]byte(ingressOperatorCrt))
+	return GetCommonNameFromCertificate([]byte(ingressOperatorCrt))
 }
 
-func getCommonNameFromCertificate(certPEM []byte) (string, error) {
+// GetCommonNameFromCertificate returns the subject common name of the first certificate of the PEM
+func GetCommonNameFromCertificate(certPEM []byte) (string, error) {
 	block, _ := pem.Decode(certPEM)
 	if block == nil {
 		return "", fmt.Errorf("failed to decode PEM block")
@@ -448,7 +465,7 @@ func getCommonNameFromCertificate(certPEM []byte) (string, error) {
 	return cert.Subject.CommonName, nil
 }
 
-func getClusterNetworks(ctx context.Context, client runtimeclient.Client) ([]string, []string, error) {
+func getClusterNetworks(ctx context.Context, client runtimeclient.Client) ([]ocp_config_v1.ClusterNetworkEntry, []string, error) {
 	// oc get network cluster -o yaml
 	network := &ocp_config_v1.Network{}
 	if err := client.Get(ctx,
@@ -458,12 +475,7 @@ func getClusterNetworks(ctx context.Context, client runtimeclient.Client) ([]str
 		return nil, nil, fmt.Errorf("failed to get network CR: %w", err)
 	}
 
-	var clusterNetworks []string
-	for _, cNet := range network.Status.ClusterNetwork {
-		clusterNetworks = append(clusterNetworks, cNet.CIDR)
-	}
-
-	return clusterNetworks, network.Status.ServiceNetwork, nil
+	return network.Status.ClusterNetwork, network.Status.ServiceNetwork, nil
 }
 
 func GetInstallConfig(ctx context.Context, client runtimeclient.Reader) (string, error) {
@@ -486,17 +498,38 @@ func GetInstallConfig(ctx context.Context, client runtimeclient.Reader) (string,
 }
 
 // getLocalNodeName returns the current node's name from the hostname.
+// GetLocalNodeName returns the name of the SNO node, which is the only control plane node when additional workers are
+// attached to the SNO
 func GetLocalNodeName(ctx context.Context, client client.Reader) (string, error) {
 	nodeList := &corev1.NodeList{}
 	if err := client.List(ctx, nodeList); err != nil {
 		return "", fmt.Errorf("failed to list nodes: %w", err)
 	}
 
-	if len(nodeList.Items) != 1 {
-		return "", fmt.Errorf("expected exactly one node, got %d", len(nodeList.Items))
+	if len(nodeList.Items) == 1 {
+		return nodeList.Items[0].Name, nil
+	}
+
+	controlPlaneNodes := lo.Filter(nodeList.Items, func(node corev1.Node, _ int) bool { return !IsWorkerNode(&node) })
+	if len(controlPlaneNodes) != 1 {
+		return "", fmt.Errorf("expected exactly one control plane node, got %d among %d nodes", len(controlPlaneNodes), len(nodeList.Items))
+	}
+
+	return controlPlaneNodes[0].Name, nil
+}
+
+// GetNodeArchitecture returns the architecture of the SNO node, as reported by its kubelet (e.g. amd64)
+func GetNodeArchitecture(ctx context.Context, client client.Reader) (string, error) {
+	nodeName, err := GetLocalNodeName(ctx, client)
+	if err != nil {
+		return "", err
 	}
 
-	return nodeList.Items[0].Name, nil
+	node := &corev1.Node{}
+	if err := client.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		return "", fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+	return node.Status.NodeInfo.Architecture, nil
 }
 
 func GetNodeInternalIPs(ctx context.Context, client client.Reader) ([]string, error) {
@@ -12,6 +12,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"text/template"
 
 	"github.com/samber/lo"
@@ -77,11 +79,43 @@ func RenderTemplate(templateData string, params any) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// The role labels of the nodes. The control plane node of the SNO has all of them, while the additional workers only
+// have the worker role.
+const (
+	masterNodeRoleLabel       = "node-role.kubernetes.io/master"
+	controlPlaneNodeRoleLabel = "node-role.kubernetes.io/control-plane"
+	workerNodeRoleLabel       = "node-role.kubernetes.io/worker"
+)
+
+// IsWorkerNode returns true if the node is an additional worker attached to the SNO, which only has the worker role
+func IsWorkerNode(node *corev1.Node) bool {
+	labels := node.GetLabels()
+	_, isWorker := labels[workerNodeRoleLabel]
+	_, isMaster := labels[masterNodeRoleLabel]
+	_, isControlPlane := labels[controlPlaneNodeRoleLabel]
+	return isWorker && !isMaster && !isControlPlane
+}
+
+// GetWorkerNodes returns the additional worker nodes attached to the SNO, if any
+func GetWorkerNodes(ctx context.Context, client runtimeclient.Reader) ([]corev1.Node, error) {
+	nodesList := &corev1.NodeList{}
+	if err := client.List(ctx, nodesList); err != nil {
+		return nil, fmt.Errorf("failed list nodes: %w", err)
+	}
+	var workers []corev1.Node
+	for _, node := range nodesList.Items {
+		if IsWorkerNode(&node) {
+			workers = append(workers, node)
+		}
+	}
+	return workers, nil
+}
+
 func GetSNOMasterNode(ctx context.Context, client runtimeclient.Client) (*corev1.Node, error) {
 	nodesList := &corev1.NodeList{}
 	err := client.List(ctx, nodesList, &runtimeclient.ListOptions{LabelSelector: labels.SelectorFromSet(
 		labels.Set{
-			"node-role.kubernetes.io/master": "",
+			masterNodeRoleLabel: "",
 		},
 	)})
 	if err != nil {
@@ -465,6 +499,8 @@ func LoadGroupedManifestsFromPath(basePath string, log *logr.Logger) ([][]*unstr
 		return nil, fmt.Errorf("failed to read manifest groups subdirs in %s: %w", basePath, err)
 	}
 
+	// The groups and manifests are numbered, so they are applied in numeric order
+	sortDirEntriesNumerically(groupSubDirs)
 	for _, groupSubDir := range groupSubDirs {
 		if !groupSubDir.IsDir() {
 			log.Info("Unexpected file found, skipping...", "file",
@@ -472,12 +508,12 @@ func LoadGroupedManifestsFromPath(basePath string, log *logr.Logger) ([][]*unstr
 			continue
 		}
 
-		// The returned list of entries are sorted by name alphabetically
 		manifestDirPath := filepath.Join(basePath, groupSubDir.Name())
 		manifestYamls, err := os.ReadDir(filepath.Clean(manifestDirPath))
 		if err != nil {
 			return nil, fmt.Errorf("failed get manifest yamls in %s: %w", manifestYamls, err)
 		}
+		sortDirEntriesNumerically(manifestYamls)
 
 		var manifests []*unstructured.Unstructured
 		for _, yamlFile := range manifestYamls {
@@ -502,6 +538,37 @@ func LoadGroupedManifestsFromPath(basePath string, log *logr.Logger) ([][]*unstr
 	return sortedManifests, nil
 }
 
+// sortDirEntriesNumerically sorts the entries by name, comparing the numbers in the names by value, e.g. group2
+// before group10
+func sortDirEntriesNumerically(entries []os.DirEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return numericLess(entries[i].Name(), entries[j].Name())
+	})
+}
+
+func numericLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits := len(a) - len(strings.TrimLeft(a, "0123456789"))
+		bDigits := len(b) - len(strings.TrimLeft(b, "0123456789"))
+		if aDigits > 0 && bDigits > 0 {
+			aNum, bNum := strings.TrimLeft(a[:aDigits], "0"), strings.TrimLeft(b[:bDigits], "0")
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			if aNum != bNum {
+				return aNum < bNum
+			}
+			a, b = a[aDigits:], b[bDigits:]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
 // BuildKernelArguementsFromMCOFile reads the kernel arguments from MCO file
 // and builds the string arguments that ostree admin deploy requires
 func BuildKernelArgumentsFromMCOFile(path string) ([]string, error) {
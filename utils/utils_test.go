@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"github.com/openshift-kni/lifecycle-agent/api/seedreconfig"
 	"github.com/openshift-kni/lifecycle-agent/internal/common"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -346,3 +348,48 @@ func TestInitIPConfig_AlreadyExists(t *testing.T) {
 	assert.NoError(t, getErr)
 	assert.Equal(t, ipcv1.IPStages.Config, ipc.Spec.Stage)
 }
+
+func TestLoadGroupedManifestsFromPathNumericOrder(t *testing.T) {
+	baseDir := t.TempDir()
+	for _, group := range []string{"group1", "group2", "group10"} {
+		for _, file := range []string{"1_ConfigMap", "2_ConfigMap", "10_ConfigMap"} {
+			dir := filepath.Join(baseDir, group)
+			assert.NoError(t, os.MkdirAll(dir, 0o700))
+			content := fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s-%s\n", group, file)
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, file+".yaml"), []byte(content), 0o600))
+		}
+	}
+
+	manifests, err := LoadGroupedManifestsFromPath(baseDir, &logr.Logger{})
+	assert.NoError(t, err)
+
+	var names []string
+	for _, group := range manifests {
+		for _, manifest := range group {
+			names = append(names, manifest.GetName())
+		}
+	}
+	assert.Equal(t, []string{
+		"group1-1_ConfigMap", "group1-2_ConfigMap", "group1-10_ConfigMap",
+		"group2-1_ConfigMap", "group2-2_ConfigMap", "group2-10_ConfigMap",
+		"group10-1_ConfigMap", "group10-2_ConfigMap", "group10-10_ConfigMap",
+	}, names)
+}
+
+func TestIsWorkerNode(t *testing.T) {
+	testcases := []struct {
+		name     string
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "control plane node", labels: map[string]string{masterNodeRoleLabel: "", controlPlaneNodeRoleLabel: "", workerNodeRoleLabel: ""}},
+		{name: "control plane label only", labels: map[string]string{controlPlaneNodeRoleLabel: "", workerNodeRoleLabel: ""}},
+		{name: "no role", labels: map[string]string{}},
+		{name: "worker node", labels: map[string]string{workerNodeRoleLabel: ""}, expected: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsWorkerNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: tc.labels}}))
+		})
+	}
+}